@@ -185,22 +185,49 @@ func generateRequestID() string {
 
 var sensitiveKeyTokens = []string{
 	"password",
+	"passphrase",
 	"token",
 	"authorization",
 	"cookie",
 	"secret",
 }
 
+var cleanupRuleMetadataAllowlist = map[string]struct{}{
+	"ruleId":     {},
+	"pathPrefix": {},
+	"maxAgeDays": {},
+	"pattern":    {},
+	"action":     {},
+	"enabled":    {},
+}
+
+var organizeRuleMetadataAllowlist = map[string]struct{}{
+	"ruleId":            {},
+	"ruleName":          {},
+	"watchPath":         {},
+	"includeSubfolders": {},
+	"actions":           {},
+	"enabled":           {},
+}
+
 var metadataAllowlistByAction = map[string]map[string]struct{}{
 	"file.upload": {
-		"filename":       {},
-		"size":           {},
-		"status":         {},
-		"conflictPolicy": {},
+		"filename":         {},
+		"size":             {},
+		"status":           {},
+		"conflictPolicy":   {},
+		"reason":           {},
+		"algorithm":        {},
+		"sha256":           {},
+		"checksumVerified": {},
+		"violation":        {},
+		"signature":        {},
+		"quarantineId":     {},
 	},
 	"file.rename": {
 		"path":    {},
 		"newName": {},
+		"reason":  {},
 	},
 	"file.delete": {
 		"path":        {},
@@ -218,6 +245,9 @@ var metadataAllowlistByAction = map[string]map[string]struct{}{
 		"skipped":     {},
 		"fromSpaceId": {},
 		"toSpaceId":   {},
+		"jobId":       {},
+		"async":       {},
+		"failedItems": {},
 	},
 	"file.copy": {
 		"sourceCount": {},
@@ -226,6 +256,43 @@ var metadataAllowlistByAction = map[string]map[string]struct{}{
 		"skipped":     {},
 		"fromSpaceId": {},
 		"toSpaceId":   {},
+		"jobId":       {},
+		"async":       {},
+		"failedItems": {},
+	},
+	"file.extract": {
+		"jobId":       {},
+		"path":        {},
+		"destination": {},
+		"format":      {},
+		"extracted":   {},
+		"skipped":     {},
+		"failed":      {},
+		"failedItems": {},
+	},
+	"file.compress": {
+		"jobId":       {},
+		"destination": {},
+		"format":      {},
+		"sourceCount": {},
+		"entries":     {},
+		"size":        {},
+	},
+	"file.duplicates.scan": {
+		"jobId":          {},
+		"spaceCount":     {},
+		"scannedFiles":   {},
+		"groups":         {},
+		"duplicateFiles": {},
+		"wastedBytes":    {},
+		"skippedFiles":   {},
+	},
+	"file.duplicates.trash": {
+		"jobId":      {},
+		"total":      {},
+		"succeeded":  {},
+		"failed":     {},
+		"freedBytes": {},
 	},
 	"file.mkdir": {
 		"path": {},
@@ -240,13 +307,16 @@ var metadataAllowlistByAction = map[string]map[string]struct{}{
 		"status":      {},
 	},
 	"file.download-ticket": {
-		"path":     {},
-		"filename": {},
-		"size":     {},
-		"format":   {},
-		"status":   {},
+		"path":      {},
+		"filename":  {},
+		"size":      {},
+		"format":    {},
+		"status":    {},
+		"expiresAt": {},
+		"ipBound":   {},
 	},
 	"file.download-multiple": {
+		"encrypted":   {},
 		"sourceCount": {},
 		"filename":    {},
 		"format":      {},
@@ -260,9 +330,11 @@ var metadataAllowlistByAction = map[string]map[string]struct{}{
 		"status":      {},
 	},
 	"file.archive-download": {
+		"encrypted":            {},
 		"jobId":                {},
 		"sourceCount":          {},
 		"filename":             {},
+		"format":               {},
 		"size":                 {},
 		"status":               {},
 		"reason":               {},
@@ -272,10 +344,76 @@ var metadataAllowlistByAction = map[string]map[string]struct{}{
 		"processedSourceBytes": {},
 	},
 	"file.archive-download-ticket": {
-		"jobId":    {},
-		"filename": {},
-		"size":     {},
-		"status":   {},
+		"jobId":     {},
+		"filename":  {},
+		"size":      {},
+		"status":    {},
+		"expiresAt": {},
+		"ipBound":   {},
+	},
+	"space.webdav.update": {
+		"archiveBrowsing": {},
+	},
+	"space.upload-policy.update": {
+		"allowedExtensions": {},
+		"blockedExtensions": {},
+		"allowedTypes":      {},
+		"blockedTypes":      {},
+		"maxFileBytes":      {},
+	},
+	"space.malware-scan.update": {
+		"mode": {},
+	},
+	"space.retention.update": {
+		"path":                {},
+		"retainUntil":         {},
+		"legalHold":           {},
+		"previousRetainUntil": {},
+		"previousLegalHold":   {},
+	},
+	"space.retention.delete": {
+		"path":        {},
+		"retainUntil": {},
+	},
+	"space.cleanup-rule.create": cleanupRuleMetadataAllowlist,
+	"space.cleanup-rule.update": {
+		"ruleId":             {},
+		"pathPrefix":         {},
+		"maxAgeDays":         {},
+		"pattern":            {},
+		"action":             {},
+		"enabled":            {},
+		"previousPathPrefix": {},
+		"previousMaxAgeDays": {},
+		"previousAction":     {},
+		"previousEnabled":    {},
+	},
+	"space.cleanup-rule.delete":  cleanupRuleMetadataAllowlist,
+	"space.organize-rule.create": organizeRuleMetadataAllowlist,
+	"space.organize-rule.update": {
+		"ruleId":            {},
+		"ruleName":          {},
+		"watchPath":         {},
+		"includeSubfolders": {},
+		"actions":           {},
+		"enabled":           {},
+		"previousName":      {},
+		"previousWatchPath": {},
+		"previousActions":   {},
+		"previousEnabled":   {},
+	},
+	"space.organize-rule.delete": organizeRuleMetadataAllowlist,
+	"space.quota.recalculate": {
+		"previousBytes": {},
+		"usedBytes":     {},
+		"driftBytes":    {},
+	},
+	"space.quota.threshold": {
+		"metric":           {},
+		"thresholdPercent": {},
+		"previousPercent":  {},
+		"used":             {},
+		"quota":            {},
 	},
 	"account.create": {
 		"userId":        {},
@@ -299,6 +437,10 @@ var metadataAllowlistByAction = map[string]map[string]struct{}{
 		"spaceIds":    {},
 		"permissions": {},
 	},
+	"account.storage-quota.update": {
+		"quotaBytes": {},
+		"spaceCount": {},
+	},
 	"role.create": {
 		"name": {},
 	},
@@ -325,6 +467,75 @@ var metadataAllowlistByAction = map[string]map[string]struct{}{
 		"deletedCount":  {},
 		"cutoff":        {},
 	},
+	"job.cancel": {
+		"jobId":   {},
+		"jobType": {},
+	},
+	"file.quarantine": {
+		"path":         {},
+		"signature":    {},
+		"scanner":      {},
+		"mode":         {},
+		"source":       {},
+		"size":         {},
+		"quarantineId": {},
+	},
+	"file.cleanup": {
+		"ruleId":       {},
+		"pathPrefix":   {},
+		"action":       {},
+		"removedCount": {},
+		"removedBytes": {},
+		"removedPaths": {},
+		"skippedCount": {},
+		"failedCount":  {},
+		"failedPaths":  {},
+		"truncated":    {},
+	},
+	"file.organize": {
+		"ruleId":      {},
+		"ruleName":    {},
+		"operation":   {},
+		"path":        {},
+		"destination": {},
+		"tags":        {},
+		"message":     {},
+		"uploader":    {},
+		"source":      {},
+	},
+	"file.retention.denied": {
+		"path":        {},
+		"lockPath":    {},
+		"operation":   {},
+		"source":      {},
+		"legalHold":   {},
+		"retainUntil": {},
+	},
+	"file.malware-scan": {
+		"path":    {},
+		"scanner": {},
+		"mode":    {},
+		"source":  {},
+		"reason":  {},
+	},
+	"quarantine.release": {
+		"quarantineId": {},
+		"path":         {},
+		"signature":    {},
+		"uploadedBy":   {},
+	},
+	"quarantine.delete": {
+		"quarantineId": {},
+		"path":         {},
+		"signature":    {},
+		"uploadedBy":   {},
+	},
+	"download.signing-key.rotate": {
+		"keyId": {},
+	},
+	"download.signing-key.retire": {
+		"keyId": {},
+	},
 }
 
 var commonMetadataAllowlist = map[string]struct{}{
@@ -43,8 +43,9 @@ func TestService_RecordBestEffort_RedactsSensitiveMetadata(t *testing.T) {
 		RequestID:  "req_test",
 		Metadata: map[string]any{
 			"before": map[string]any{
-				"port":     "3000",
-				"password": "should-not-be-stored",
+				"port":              "3000",
+				"password":          "should-not-be-stored",
+				"archivePassphrase": "should-not-be-stored",
 			},
 			"after": map[string]any{
 				"port": "38080",
@@ -75,6 +76,9 @@ func TestService_RecordBestEffort_RedactsSensitiveMetadata(t *testing.T) {
 	if _, exists := before["password"]; exists {
 		t.Fatal("expected password to be redacted from metadata")
 	}
+	if _, exists := before["archivePassphrase"]; exists {
+		t.Fatal("expected passphrase to be redacted from metadata")
+	}
 
 	after, ok := item.Metadata["after"].(map[string]any)
 	if !ok {
@@ -18,6 +18,23 @@ var publicAPIPaths = map[string]struct{}{
 	"/api/setup/admin":    {},
 }
 
+// publicAPIPrefixes는 쿠키 없이 접근하는 경로입니다. 서명 다운로드 URL은 토큰 자체로 인가합니다.
+var publicAPIPrefixes = []string{
+	"/api/downloads/signed/",
+}
+
+func isPublicAPIPath(path string) bool {
+	if _, ok := publicAPIPaths[path]; ok {
+		return true
+	}
+	for _, prefix := range publicAPIPrefixes {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
 func (s *Service) Middleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		deniedRule, shouldAuditDenied := deniedAuditRuleForRequest(r)
@@ -32,7 +49,7 @@ func (s *Service) Middleware(next http.Handler) http.Handler {
 			return
 		}
 
-		if _, ok := publicAPIPaths[r.URL.Path]; ok {
+		if isPublicAPIPath(r.URL.Path) {
 			next.ServeHTTP(w, r)
 			return
 		}
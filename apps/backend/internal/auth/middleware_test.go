@@ -70,7 +70,7 @@ func TestMiddleware_AllowsPublicAPIWithoutToken(t *testing.T) {
 	authSvc, _, db := setupAuthTestService(t)
 	defer db.Close()
 
-	for _, path := range []string{"/api/health", "/api/system/version"} {
+	for _, path := range []string{"/api/health", "/api/system/version", "/api/downloads/signed/v1.key.payload.sig"} {
 		called := false
 		req := httptest.NewRequest(http.MethodGet, path, nil)
 		rec := executeMiddlewareRequest(t, authSvc, req, func(w http.ResponseWriter, _ *http.Request) {
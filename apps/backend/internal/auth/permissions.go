@@ -20,6 +20,8 @@ const (
 	PermissionSpaceWrite   = "space.write"
 	PermissionFileRead     = "file.read"
 	PermissionFileWrite    = "file.write"
+	// PermissionRetentionManage는 보존 잠금과 법적 보존을 걸고 늘리고 지우는 전용 권한입니다.
+	PermissionRetentionManage = "retention.manage"
 )
 
 type spacePermissionRequirement struct {
@@ -69,9 +71,24 @@ func requiredPermissionForRequest(r *http.Request) (string, bool) {
 	if path == "/api/search/files" && method == http.MethodGet {
 		return PermissionFileRead, true
 	}
+	if path == "/api/duplicates" || strings.HasPrefix(path, "/api/duplicates/") {
+		if method == http.MethodPost && strings.HasSuffix(path, "/trash") {
+			return PermissionFileWrite, true
+		}
+		return PermissionFileRead, true
+	}
 	if path == "/api/auth/me" && method == http.MethodPatch {
 		return PermissionProfileWrite, true
 	}
+	if path == "/api/storage/me" && method == http.MethodGet {
+		return PermissionProfileRead, true
+	}
+	if path == "/api/storage/users" || strings.HasPrefix(path, "/api/storage/users/") {
+		if method == http.MethodGet {
+			return PermissionAccountRead, true
+		}
+		return PermissionAccountWrite, true
+	}
 	if path == "/api/spaces/usage" && method == http.MethodGet {
 		return PermissionSpaceRead, true
 	}
@@ -85,6 +102,18 @@ func requiredPermissionForRequest(r *http.Request) (string, bool) {
 		}
 	}
 
+	if strings.HasPrefix(path, "/api/spaces/") && strings.HasSuffix(path, "/retention-locks") {
+		if method == http.MethodGet {
+			return PermissionSpaceRead, true
+		}
+		return PermissionRetentionManage, true
+	}
+	if isSpaceCleanupRuleRoute(path) || isSpaceOrganizeRuleRoute(path) {
+		if method == http.MethodGet {
+			return PermissionSpaceRead, true
+		}
+		return PermissionSpaceWrite, true
+	}
 	if strings.HasPrefix(path, "/api/spaces/") && method == http.MethodDelete {
 		return PermissionSpaceWrite, true
 	}
@@ -94,6 +123,39 @@ func requiredPermissionForRequest(r *http.Request) (string, bool) {
 	if strings.HasPrefix(path, "/api/spaces/") && strings.HasSuffix(path, "/quota") && method == http.MethodPatch {
 		return PermissionSpaceWrite, true
 	}
+	if strings.HasPrefix(path, "/api/spaces/") && strings.HasSuffix(path, "/quota/recalculate") && method == http.MethodPost {
+		return PermissionSpaceWrite, true
+	}
+	if strings.HasPrefix(path, "/api/spaces/") && strings.HasSuffix(path, "/state") && method == http.MethodPatch {
+		return PermissionSpaceWrite, true
+	}
+	if strings.HasPrefix(path, "/api/spaces/") && strings.HasSuffix(path, "/webdav") && method == http.MethodPatch {
+		return PermissionSpaceWrite, true
+	}
+	if strings.HasPrefix(path, "/api/spaces/") && strings.HasSuffix(path, "/upload-policy") {
+		if method == http.MethodGet {
+			return PermissionSpaceRead, true
+		}
+		return PermissionSpaceWrite, true
+	}
+	if strings.HasPrefix(path, "/api/spaces/") && strings.HasSuffix(path, "/malware-scan") {
+		if method == http.MethodGet {
+			return PermissionSpaceRead, true
+		}
+		return PermissionSpaceWrite, true
+	}
+	if strings.HasPrefix(path, "/api/spaces/") && strings.HasSuffix(path, "/relocation") {
+		return PermissionSpaceWrite, true
+	}
+	if isSpaceAnalyticsRoute(path) && method == http.MethodGet {
+		return PermissionSpaceRead, true
+	}
+	if isSpaceFileTagRoute(path) && method == http.MethodGet {
+		return PermissionSpaceRead, true
+	}
+	if strings.HasPrefix(path, "/api/spaces/") && strings.HasSuffix(path, "/deletion") {
+		return PermissionSpaceWrite, true
+	}
 	if strings.HasPrefix(path, "/api/spaces/") && strings.HasSuffix(path, "/members") {
 		if method == http.MethodGet {
 			return PermissionAccountRead, true
@@ -106,15 +168,27 @@ func requiredPermissionForRequest(r *http.Request) (string, bool) {
 	if strings.HasPrefix(path, "/api/spaces/") {
 		action, ok := extractSpaceFileAction(path)
 		if ok {
-			if action == "download" || action == "download-ticket" || action == "download-multiple" || action == "download-multiple-ticket" || action == "archive-downloads" || action == "archive-download-ticket" {
+			if isReadOnlySpaceFileAction(action) {
 				return PermissionFileRead, true
 			}
 			return PermissionFileWrite, true
 		}
 	}
+	if path == "/api/downloads/signing-keys" || strings.HasPrefix(path, "/api/downloads/signing-keys/") {
+		if method == http.MethodGet {
+			return PermissionServerRead, true
+		}
+		return PermissionServerWrite, true
+	}
 	if strings.HasPrefix(path, "/api/downloads/") {
 		return PermissionFileRead, true
 	}
+	if path == "/api/quarantine" || strings.HasPrefix(path, "/api/quarantine/") {
+		if method == http.MethodGet {
+			return PermissionServerRead, true
+		}
+		return PermissionServerWrite, true
+	}
 
 	if strings.HasPrefix(path, "/api/accounts") {
 		if method == http.MethodGet {
@@ -189,6 +263,63 @@ func requiredSpacePermissionForRequest(r *http.Request) (*spacePermissionRequire
 			required: account.PermissionWrite,
 		}, true
 	}
+	if strings.HasSuffix(path, "/quota/recalculate") && r.Method == http.MethodPost {
+		return &spacePermissionRequirement{
+			spaceID:  spaceID,
+			required: account.PermissionWrite,
+		}, true
+	}
+	if strings.HasSuffix(path, "/state") && r.Method == http.MethodPatch {
+		return &spacePermissionRequirement{
+			spaceID:  spaceID,
+			required: account.PermissionWrite,
+		}, true
+	}
+	if strings.HasSuffix(path, "/webdav") && r.Method == http.MethodPatch {
+		return &spacePermissionRequirement{
+			spaceID:  spaceID,
+			required: account.PermissionWrite,
+		}, true
+	}
+	if strings.HasSuffix(path, "/upload-policy") || strings.HasSuffix(path, "/malware-scan") {
+		required := account.PermissionRead
+		if r.Method != http.MethodGet {
+			required = account.PermissionWrite
+		}
+		return &spacePermissionRequirement{
+			spaceID:  spaceID,
+			required: required,
+		}, true
+	}
+	if isSpaceCleanupRuleRoute(path) || isSpaceOrganizeRuleRoute(path) {
+		required := account.PermissionRead
+		if r.Method != http.MethodGet {
+			required = account.PermissionWrite
+		}
+		return &spacePermissionRequirement{
+			spaceID:  spaceID,
+			required: required,
+		}, true
+	}
+	if strings.HasSuffix(path, "/retention-locks") {
+		// 잠금 변경은 전역 retention.manage 권한으로 판단하므로 Space 관리자라도 그것 없이는 바꿀 수 없습니다.
+		return &spacePermissionRequirement{
+			spaceID:  spaceID,
+			required: account.PermissionRead,
+		}, true
+	}
+	if strings.HasSuffix(path, "/relocation") {
+		return &spacePermissionRequirement{
+			spaceID:  spaceID,
+			required: account.PermissionWrite,
+		}, true
+	}
+	if isSpaceAnalyticsRoute(path) || isSpaceFileTagRoute(path) {
+		return &spacePermissionRequirement{
+			spaceID:  spaceID,
+			required: account.PermissionRead,
+		}, true
+	}
 	if strings.HasSuffix(path, "/members") {
 		required := account.PermissionRead
 		if r.Method == http.MethodPut {
@@ -203,7 +334,7 @@ func requiredSpacePermissionForRequest(r *http.Request) (*spacePermissionRequire
 	action, hasAction := extractSpaceFileAction(path)
 	if hasAction {
 		required := account.PermissionWrite
-		if action == "download" || action == "download-ticket" || action == "download-multiple" || action == "download-multiple-ticket" || action == "archive-downloads" || action == "archive-download-ticket" {
+		if isReadOnlySpaceFileAction(action) {
 			required = account.PermissionRead
 		}
 		return &spacePermissionRequirement{
@@ -241,6 +372,54 @@ func extractSpaceFileAction(path string) (string, bool) {
 	return parts[2], true
 }
 
+// isReadOnlySpaceFileAction은 Space 읽기 권한만으로 허용하는 파일 액션인지 확인합니다.
+// 그 밖의 파일 액션은 모두 쓰기 권한이 필요합니다.
+func isReadOnlySpaceFileAction(action string) bool {
+	switch action {
+	case "download", "download-ticket", "download-multiple", "download-multiple-ticket",
+		"archive-downloads", "archive-download-ticket", "checksum", "checksums":
+		return true
+	default:
+		return false
+	}
+}
+
+// isSpaceAnalyticsRoute는 /api/spaces/{id}/analytics 와 그 하위 경로인지 확인합니다.
+func isSpaceAnalyticsRoute(path string) bool {
+	if !strings.HasPrefix(path, "/api/spaces/") {
+		return false
+	}
+	parts := strings.Split(strings.TrimPrefix(path, "/api/spaces/"), "/")
+	return len(parts) >= 2 && parts[1] == "analytics"
+}
+
+// isSpaceCleanupRuleRoute는 /api/spaces/{id}/cleanup-rules 와 그 하위 경로인지 확인합니다.
+func isSpaceCleanupRuleRoute(path string) bool {
+	if !strings.HasPrefix(path, "/api/spaces/") {
+		return false
+	}
+	parts := strings.Split(strings.TrimPrefix(path, "/api/spaces/"), "/")
+	return len(parts) >= 2 && parts[1] == "cleanup-rules"
+}
+
+// isSpaceOrganizeRuleRoute는 /api/spaces/{id}/organize-rules 와 그 하위 경로인지 확인합니다.
+func isSpaceOrganizeRuleRoute(path string) bool {
+	if !strings.HasPrefix(path, "/api/spaces/") {
+		return false
+	}
+	parts := strings.Split(strings.TrimPrefix(path, "/api/spaces/"), "/")
+	return len(parts) >= 2 && parts[1] == "organize-rules"
+}
+
+// isSpaceFileTagRoute는 /api/spaces/{id}/file-tags 경로인지 확인합니다.
+func isSpaceFileTagRoute(path string) bool {
+	if !strings.HasPrefix(path, "/api/spaces/") {
+		return false
+	}
+	parts := strings.Split(strings.TrimPrefix(path, "/api/spaces/"), "/")
+	return len(parts) == 2 && parts[1] == "file-tags"
+}
+
 func isDirectSpaceRoute(path string) bool {
 	trimmed := strings.TrimPrefix(path, "/api/spaces/")
 	parts := strings.Split(trimmed, "/")
@@ -333,6 +512,49 @@ func deniedAuditRuleForRequest(r *http.Request) (deniedAuditRule, bool) {
 	if path == "/api/spaces" && method == http.MethodPost {
 		return deniedAuditRule{Action: "space.create", AllowUnauthorized: true}, true
 	}
+	if strings.HasPrefix(path, "/api/spaces/") && strings.HasSuffix(path, "/relocation") && (method == http.MethodPost || method == http.MethodDelete) {
+		if _, ok := extractSpaceID(path); ok {
+			return deniedAuditRule{Action: "space.relocate", AllowUnauthorized: true}, true
+		}
+	}
+	if strings.HasPrefix(path, "/api/spaces/") && strings.HasSuffix(path, "/deletion") && method == http.MethodDelete {
+		if _, ok := extractSpaceID(path); ok {
+			return deniedAuditRule{Action: "space.delete.cancel", AllowUnauthorized: true}, true
+		}
+	}
+	if strings.HasPrefix(path, "/api/spaces/") && strings.HasSuffix(path, "/retention-locks") && (method == http.MethodPut || method == http.MethodDelete) {
+		if _, ok := extractSpaceID(path); ok {
+			action := "space.retention.update"
+			if method == http.MethodDelete {
+				action = "space.retention.delete"
+			}
+			return deniedAuditRule{Action: action, AllowUnauthorized: true}, true
+		}
+	}
+	if isSpaceCleanupRuleRoute(path) && method != http.MethodGet {
+		if _, ok := extractSpaceID(path); ok {
+			action := "space.cleanup-rule.update"
+			switch method {
+			case http.MethodPost:
+				action = "space.cleanup-rule.create"
+			case http.MethodDelete:
+				action = "space.cleanup-rule.delete"
+			}
+			return deniedAuditRule{Action: action, AllowUnauthorized: true}, true
+		}
+	}
+	if isSpaceOrganizeRuleRoute(path) && method != http.MethodGet {
+		if _, ok := extractSpaceID(path); ok {
+			action := "space.organize-rule.update"
+			switch method {
+			case http.MethodPost:
+				action = "space.organize-rule.create"
+			case http.MethodDelete:
+				action = "space.organize-rule.delete"
+			}
+			return deniedAuditRule{Action: action, AllowUnauthorized: true}, true
+		}
+	}
 	if strings.HasPrefix(path, "/api/spaces/") && method == http.MethodDelete {
 		if _, ok := extractSpaceID(path); ok {
 			return deniedAuditRule{Action: "space.delete", AllowUnauthorized: true}, true
@@ -348,6 +570,31 @@ func deniedAuditRuleForRequest(r *http.Request) (deniedAuditRule, bool) {
 			return deniedAuditRule{Action: "space.quota.update", AllowUnauthorized: true}, true
 		}
 	}
+	if strings.HasPrefix(path, "/api/spaces/") && strings.HasSuffix(path, "/quota/recalculate") && method == http.MethodPost {
+		if _, ok := extractSpaceID(path); ok {
+			return deniedAuditRule{Action: "space.quota.recalculate", AllowUnauthorized: true}, true
+		}
+	}
+	if strings.HasPrefix(path, "/api/spaces/") && strings.HasSuffix(path, "/state") && method == http.MethodPatch {
+		if _, ok := extractSpaceID(path); ok {
+			return deniedAuditRule{Action: "space.state.update", AllowUnauthorized: true}, true
+		}
+	}
+	if strings.HasPrefix(path, "/api/spaces/") && strings.HasSuffix(path, "/webdav") && method == http.MethodPatch {
+		if _, ok := extractSpaceID(path); ok {
+			return deniedAuditRule{Action: "space.webdav.update", AllowUnauthorized: true}, true
+		}
+	}
+	if strings.HasPrefix(path, "/api/spaces/") && strings.HasSuffix(path, "/upload-policy") && method == http.MethodPut {
+		if _, ok := extractSpaceID(path); ok {
+			return deniedAuditRule{Action: "space.upload-policy.update", AllowUnauthorized: true}, true
+		}
+	}
+	if strings.HasPrefix(path, "/api/spaces/") && strings.HasSuffix(path, "/malware-scan") && method == http.MethodPut {
+		if _, ok := extractSpaceID(path); ok {
+			return deniedAuditRule{Action: "space.malware-scan.update", AllowUnauthorized: true}, true
+		}
+	}
 	if strings.HasPrefix(path, "/api/spaces/") && strings.HasSuffix(path, "/members") && method == http.MethodPut {
 		if _, ok := extractSpaceID(path); ok {
 			return deniedAuditRule{Action: "space.members.replace", AllowUnauthorized: true}, true
@@ -362,6 +609,27 @@ func deniedAuditRuleForRequest(r *http.Request) (deniedAuditRule, bool) {
 			}
 		}
 	}
+	if strings.HasPrefix(path, "/api/duplicates/") && strings.HasSuffix(path, "/trash") && method == http.MethodPost {
+		return deniedAuditRule{Action: "file.duplicates.trash", AllowUnauthorized: true}, true
+	}
+	if strings.HasPrefix(path, "/api/storage/users/") && strings.HasSuffix(path, "/quotas") && method == http.MethodPut {
+		return deniedAuditRule{Action: "account.storage-quota.update", AllowUnauthorized: true}, true
+	}
+	if path == "/api/downloads/signing-keys" && method == http.MethodPost {
+		return deniedAuditRule{Action: "download.signing-key.rotate", AllowUnauthorized: true}, true
+	}
+	if strings.HasPrefix(path, "/api/downloads/signing-keys/") && method == http.MethodDelete {
+		return deniedAuditRule{Action: "download.signing-key.retire", AllowUnauthorized: true}, true
+	}
+	if path == "/api/downloads/revoke" && method == http.MethodPost {
+		return deniedAuditRule{Action: "download.links.revoke", AllowUnauthorized: true}, true
+	}
+	if strings.HasPrefix(path, "/api/quarantine/") && strings.HasSuffix(path, "/release") && method == http.MethodPost {
+		return deniedAuditRule{Action: "quarantine.release", AllowUnauthorized: true}, true
+	}
+	if strings.HasPrefix(path, "/api/quarantine/") && method == http.MethodDelete {
+		return deniedAuditRule{Action: "quarantine.delete", AllowUnauthorized: true}, true
+	}
 	if strings.HasPrefix(path, "/api/downloads/") && method == http.MethodGet {
 		return deniedAuditRule{Action: "file.download-ticket", AllowUnauthorized: true}, true
 	}
@@ -390,6 +658,10 @@ func DeniedAuditActionForSpaceFileAction(action string) (string, bool) {
 		return "file.move", true
 	case "copy":
 		return "file.copy", true
+	case "extract":
+		return "file.extract", true
+	case "compress":
+		return "file.compress", true
 	case "download-multiple":
 		return "file.download-multiple", true
 	case "download-multiple-ticket":
@@ -54,6 +54,66 @@ func TestRequiredPermissionForRequest_SearchEndpoint(t *testing.T) {
 	}
 }
 
+func TestRequiredPermissionForRequest_DuplicateEndpoints(t *testing.T) {
+	tests := []struct {
+		name     string
+		method   string
+		path     string
+		expected string
+	}{
+		{name: "start scan", method: http.MethodPost, path: "/api/duplicates", expected: PermissionFileRead},
+		{name: "read report", method: http.MethodGet, path: "/api/duplicates/abc", expected: PermissionFileRead},
+		{name: "trash duplicates", method: http.MethodPost, path: "/api/duplicates/abc/trash", expected: PermissionFileWrite},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			req := &http.Request{
+				Method: tc.method,
+				URL:    &url.URL{Path: tc.path},
+			}
+			got, ok := requiredPermissionForRequest(req)
+			if !ok {
+				t.Fatalf("expected permission mapping for %s %s", tc.method, tc.path)
+			}
+			if got != tc.expected {
+				t.Fatalf("expected %q, got %q", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestRequiredPermissionForRequest_UserStorageEndpoints(t *testing.T) {
+	tests := []struct {
+		name     string
+		method   string
+		path     string
+		expected string
+	}{
+		{name: "my usage", method: http.MethodGet, path: "/api/storage/me", expected: PermissionProfileRead},
+		{name: "usage report", method: http.MethodGet, path: "/api/storage/users", expected: PermissionAccountRead},
+		{name: "replace user quotas", method: http.MethodPut, path: "/api/storage/users/alice/quotas", expected: PermissionAccountWrite},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			req := &http.Request{
+				Method: tc.method,
+				URL:    &url.URL{Path: tc.path},
+			}
+			got, ok := requiredPermissionForRequest(req)
+			if !ok {
+				t.Fatalf("expected permission mapping for %s %s", tc.method, tc.path)
+			}
+			if got != tc.expected {
+				t.Fatalf("expected %q, got %q", tc.expected, got)
+			}
+		})
+	}
+}
+
 func TestRequiredPermissionForRequest_ProfileUpdateEndpoint(t *testing.T) {
 	req := &http.Request{
 		Method: http.MethodPatch,
@@ -173,6 +233,18 @@ func TestRequiredPermissionForRequest_SpaceUsageAndQuota(t *testing.T) {
 			path:     "/api/spaces/usage",
 			expected: PermissionSpaceRead,
 		},
+		{
+			name:     "space analytics",
+			method:   http.MethodGet,
+			path:     "/api/spaces/7/analytics",
+			expected: PermissionSpaceRead,
+		},
+		{
+			name:     "space usage history",
+			method:   http.MethodGet,
+			path:     "/api/spaces/7/analytics/history",
+			expected: PermissionSpaceRead,
+		},
 		{
 			name:     "space rename patch",
 			method:   http.MethodPatch,
@@ -185,12 +257,120 @@ func TestRequiredPermissionForRequest_SpaceUsageAndQuota(t *testing.T) {
 			path:     "/api/spaces/1/quota",
 			expected: PermissionSpaceWrite,
 		},
+		{
+			name:     "space quota recalculate",
+			method:   http.MethodPost,
+			path:     "/api/spaces/1/quota/recalculate",
+			expected: PermissionSpaceWrite,
+		},
+		{
+			name:     "space upload policy get",
+			method:   http.MethodGet,
+			path:     "/api/spaces/1/upload-policy",
+			expected: PermissionSpaceRead,
+		},
+		{
+			name:     "space upload policy put",
+			method:   http.MethodPut,
+			path:     "/api/spaces/1/upload-policy",
+			expected: PermissionSpaceWrite,
+		},
+		{
+			name:     "space malware scan get",
+			method:   http.MethodGet,
+			path:     "/api/spaces/1/malware-scan",
+			expected: PermissionSpaceRead,
+		},
+		{
+			name:     "space malware scan put",
+			method:   http.MethodPut,
+			path:     "/api/spaces/1/malware-scan",
+			expected: PermissionSpaceWrite,
+		},
+		{
+			name:     "space retention locks get",
+			method:   http.MethodGet,
+			path:     "/api/spaces/1/retention-locks",
+			expected: PermissionSpaceRead,
+		},
+		{
+			name:     "space retention locks put",
+			method:   http.MethodPut,
+			path:     "/api/spaces/1/retention-locks",
+			expected: PermissionRetentionManage,
+		},
+		{
+			name:     "space retention locks delete",
+			method:   http.MethodDelete,
+			path:     "/api/spaces/1/retention-locks",
+			expected: PermissionRetentionManage,
+		},
+		{
+			name:     "space cleanup rule dry run",
+			method:   http.MethodGet,
+			path:     "/api/spaces/1/cleanup-rules/3/dry-run",
+			expected: PermissionSpaceRead,
+		},
+		{
+			name:     "space cleanup rule delete",
+			method:   http.MethodDelete,
+			path:     "/api/spaces/1/cleanup-rules/3",
+			expected: PermissionSpaceWrite,
+		},
+		{
+			name:     "space organize rules list",
+			method:   http.MethodGet,
+			path:     "/api/spaces/1/organize-rules",
+			expected: PermissionSpaceRead,
+		},
+		{
+			name:     "space organize rule update",
+			method:   http.MethodPut,
+			path:     "/api/spaces/1/organize-rules/3",
+			expected: PermissionSpaceWrite,
+		},
+		{
+			name:     "space file tags",
+			method:   http.MethodGet,
+			path:     "/api/spaces/1/file-tags",
+			expected: PermissionSpaceRead,
+		},
 		{
 			name:     "space root validation",
 			method:   http.MethodPost,
 			path:     "/api/spaces/validate-root",
 			expected: PermissionSpaceWrite,
 		},
+		{
+			name:     "space relocation start",
+			method:   http.MethodPost,
+			path:     "/api/spaces/1/relocation",
+			expected: PermissionSpaceWrite,
+		},
+		{
+			name:     "space relocation status",
+			method:   http.MethodGet,
+			path:     "/api/spaces/1/relocation",
+			expected: PermissionSpaceWrite,
+		},
+		{
+			name:     "space state patch",
+			method:   http.MethodPatch,
+			path:     "/api/spaces/1/state",
+			expected: PermissionSpaceWrite,
+		},
+		{
+			name:     "space webdav settings patch",
+			method:   http.MethodPatch,
+			path:     "/api/spaces/1/webdav",
+			expected: PermissionSpaceWrite,
+		},
+		{
+			name:     "space deletion status",
+			method:   http.MethodGet,
+			path:     "/api/spaces/1/deletion",
+			expected: PermissionSpaceWrite,
+		},
 	}
 
 	for _, tc := range tests {
@@ -286,6 +466,74 @@ func TestRequiredPermissionForRequest_SystemUpdateEndpoints(t *testing.T) {
 	}
 }
 
+func TestRequiredPermissionForRequest_DownloadLinkEndpoints(t *testing.T) {
+	tests := []struct {
+		name     string
+		method   string
+		path     string
+		expected string
+	}{
+		{
+			name:     "list download signing keys",
+			method:   http.MethodGet,
+			path:     "/api/downloads/signing-keys",
+			expected: PermissionServerRead,
+		},
+		{
+			name:     "rotate download signing key",
+			method:   http.MethodPost,
+			path:     "/api/downloads/signing-keys",
+			expected: PermissionServerWrite,
+		},
+		{
+			name:     "retire download signing key",
+			method:   http.MethodDelete,
+			path:     "/api/downloads/signing-keys/abcd",
+			expected: PermissionServerWrite,
+		},
+		{
+			name:     "list quarantined files",
+			method:   http.MethodGet,
+			path:     "/api/quarantine",
+			expected: PermissionServerRead,
+		},
+		{
+			name:     "release quarantined file",
+			method:   http.MethodPost,
+			path:     "/api/quarantine/3/release",
+			expected: PermissionServerWrite,
+		},
+		{
+			name:     "delete quarantined file",
+			method:   http.MethodDelete,
+			path:     "/api/quarantine/3",
+			expected: PermissionServerWrite,
+		},
+		{
+			name:     "revoke own download links",
+			method:   http.MethodPost,
+			path:     "/api/downloads/revoke",
+			expected: PermissionFileRead,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req := &http.Request{
+				Method: tc.method,
+				URL:    &url.URL{Path: tc.path},
+			}
+			got, ok := requiredPermissionForRequest(req)
+			if !ok {
+				t.Fatalf("expected permission mapping for %s %s", tc.method, tc.path)
+			}
+			if got != tc.expected {
+				t.Fatalf("expected %q, got %q", tc.expected, got)
+			}
+		})
+	}
+}
+
 func TestRequiredSpacePermissionForRequest_SpaceQuotaPatch(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -308,6 +556,76 @@ func TestRequiredSpacePermissionForRequest_SpaceQuotaPatch(t *testing.T) {
 			expectedSpace:  7,
 			expectedAccess: account.PermissionWrite,
 		},
+		{
+			name:           "space quota recalculate",
+			method:         http.MethodPost,
+			path:           "/api/spaces/7/quota/recalculate",
+			expectedSpace:  7,
+			expectedAccess: account.PermissionWrite,
+		},
+		{
+			name:           "space upload policy get",
+			method:         http.MethodGet,
+			path:           "/api/spaces/7/upload-policy",
+			expectedSpace:  7,
+			expectedAccess: account.PermissionRead,
+		},
+		{
+			name:           "space upload policy put",
+			method:         http.MethodPut,
+			path:           "/api/spaces/7/upload-policy",
+			expectedSpace:  7,
+			expectedAccess: account.PermissionWrite,
+		},
+		{
+			name:           "space malware scan put",
+			method:         http.MethodPut,
+			path:           "/api/spaces/7/malware-scan",
+			expectedSpace:  7,
+			expectedAccess: account.PermissionWrite,
+		},
+		{
+			name:           "space retention locks put",
+			method:         http.MethodPut,
+			path:           "/api/spaces/7/retention-locks",
+			expectedSpace:  7,
+			expectedAccess: account.PermissionRead,
+		},
+		{
+			name:           "space cleanup rule dry run",
+			method:         http.MethodGet,
+			path:           "/api/spaces/7/cleanup-rules/3/dry-run",
+			expectedSpace:  7,
+			expectedAccess: account.PermissionRead,
+		},
+		{
+			name:           "space cleanup rule create",
+			method:         http.MethodPost,
+			path:           "/api/spaces/7/cleanup-rules",
+			expectedSpace:  7,
+			expectedAccess: account.PermissionWrite,
+		},
+		{
+			name:           "space organize rule get",
+			method:         http.MethodGet,
+			path:           "/api/spaces/7/organize-rules/3",
+			expectedSpace:  7,
+			expectedAccess: account.PermissionRead,
+		},
+		{
+			name:           "space organize rule delete",
+			method:         http.MethodDelete,
+			path:           "/api/spaces/7/organize-rules/3",
+			expectedSpace:  7,
+			expectedAccess: account.PermissionWrite,
+		},
+		{
+			name:           "space file tags",
+			method:         http.MethodGet,
+			path:           "/api/spaces/7/file-tags",
+			expectedSpace:  7,
+			expectedAccess: account.PermissionRead,
+		},
 		{
 			name:           "space members list",
 			method:         http.MethodGet,
@@ -322,6 +640,54 @@ func TestRequiredSpacePermissionForRequest_SpaceQuotaPatch(t *testing.T) {
 			expectedSpace:  7,
 			expectedAccess: account.PermissionWrite,
 		},
+		{
+			name:           "space analytics",
+			method:         http.MethodGet,
+			path:           "/api/spaces/7/analytics",
+			expectedSpace:  7,
+			expectedAccess: account.PermissionRead,
+		},
+		{
+			name:           "space usage history",
+			method:         http.MethodGet,
+			path:           "/api/spaces/7/analytics/history",
+			expectedSpace:  7,
+			expectedAccess: account.PermissionRead,
+		},
+		{
+			name:           "space relocation start",
+			method:         http.MethodPost,
+			path:           "/api/spaces/7/relocation",
+			expectedSpace:  7,
+			expectedAccess: account.PermissionWrite,
+		},
+		{
+			name:           "space state patch",
+			method:         http.MethodPatch,
+			path:           "/api/spaces/7/state",
+			expectedSpace:  7,
+			expectedAccess: account.PermissionWrite,
+		},
+		{
+			name:           "space webdav settings patch",
+			method:         http.MethodPatch,
+			path:           "/api/spaces/7/webdav",
+			expectedSpace:  7,
+			expectedAccess: account.PermissionWrite,
+		}, {
+			name:           "file checksum",
+			method:         http.MethodGet,
+			path:           "/api/spaces/7/files/checksum",
+			expectedSpace:  7,
+			expectedAccess: account.PermissionRead,
+		},
+		{
+			name:           "folder checksums",
+			method:         http.MethodGet,
+			path:           "/api/spaces/7/files/checksums",
+			expectedSpace:  7,
+			expectedAccess: account.PermissionRead,
+		},
 	}
 
 	for _, tc := range tests {
@@ -413,6 +779,84 @@ func TestDeniedAuditRuleForRequest_IncludedEndpoints(t *testing.T) {
 			path:           "/api/spaces/7",
 			expectedAction: "space.update",
 		},
+		{
+			name:           "space state update",
+			method:         http.MethodPatch,
+			path:           "/api/spaces/7/state",
+			expectedAction: "space.state.update",
+		},
+		{
+			name:           "space webdav settings update",
+			method:         http.MethodPatch,
+			path:           "/api/spaces/7/webdav",
+			expectedAction: "space.webdav.update",
+		},
+		{
+			name:           "space upload policy update",
+			method:         http.MethodPut,
+			path:           "/api/spaces/7/upload-policy",
+			expectedAction: "space.upload-policy.update",
+		},
+		{
+			name:           "space malware scan update",
+			method:         http.MethodPut,
+			path:           "/api/spaces/7/malware-scan",
+			expectedAction: "space.malware-scan.update",
+		},
+		{
+			name:           "space retention lock update",
+			method:         http.MethodPut,
+			path:           "/api/spaces/7/retention-locks",
+			expectedAction: "space.retention.update",
+		},
+		{
+			name:           "space retention lock delete",
+			method:         http.MethodDelete,
+			path:           "/api/spaces/7/retention-locks",
+			expectedAction: "space.retention.delete",
+		},
+		{
+			name:           "space cleanup rule create",
+			method:         http.MethodPost,
+			path:           "/api/spaces/7/cleanup-rules",
+			expectedAction: "space.cleanup-rule.create",
+		},
+		{
+			name:           "space cleanup rule delete",
+			method:         http.MethodDelete,
+			path:           "/api/spaces/7/cleanup-rules/3",
+			expectedAction: "space.cleanup-rule.delete",
+		},
+		{
+			name:           "space organize rule create",
+			method:         http.MethodPost,
+			path:           "/api/spaces/7/organize-rules",
+			expectedAction: "space.organize-rule.create",
+		},
+		{
+			name:           "space organize rule update",
+			method:         http.MethodPut,
+			path:           "/api/spaces/7/organize-rules/3",
+			expectedAction: "space.organize-rule.update",
+		},
+		{
+			name:           "space delete",
+			method:         http.MethodDelete,
+			path:           "/api/spaces/7",
+			expectedAction: "space.delete",
+		},
+		{
+			name:           "space deletion cancel",
+			method:         http.MethodDelete,
+			path:           "/api/spaces/7/deletion",
+			expectedAction: "space.delete.cancel",
+		},
+		{
+			name:           "space relocation cancel",
+			method:         http.MethodDelete,
+			path:           "/api/spaces/7/relocation",
+			expectedAction: "space.relocate",
+		},
 		{
 			name:           "space members replace",
 			method:         http.MethodPut,
@@ -425,6 +869,48 @@ func TestDeniedAuditRuleForRequest_IncludedEndpoints(t *testing.T) {
 			path:           "/api/downloads/abc",
 			expectedAction: "file.download-ticket",
 		},
+		{
+			name:           "rotate download signing key",
+			method:         http.MethodPost,
+			path:           "/api/downloads/signing-keys",
+			expectedAction: "download.signing-key.rotate",
+		},
+		{
+			name:           "retire download signing key",
+			method:         http.MethodDelete,
+			path:           "/api/downloads/signing-keys/abcd",
+			expectedAction: "download.signing-key.retire",
+		},
+		{
+			name:           "revoke own download links",
+			method:         http.MethodPost,
+			path:           "/api/downloads/revoke",
+			expectedAction: "download.links.revoke",
+		},
+		{
+			name:           "release quarantined file",
+			method:         http.MethodPost,
+			path:           "/api/quarantine/3/release",
+			expectedAction: "quarantine.release",
+		},
+		{
+			name:           "delete quarantined file",
+			method:         http.MethodDelete,
+			path:           "/api/quarantine/3",
+			expectedAction: "quarantine.delete",
+		},
+		{
+			name:           "trash duplicate files",
+			method:         http.MethodPost,
+			path:           "/api/duplicates/abc/trash",
+			expectedAction: "file.duplicates.trash",
+		},
+		{
+			name:           "replace user storage quotas",
+			method:         http.MethodPut,
+			path:           "/api/storage/users/alice/quotas",
+			expectedAction: "account.storage-quota.update",
+		},
 		{
 			name:           "profile update",
 			method:         http.MethodPatch,
@@ -470,6 +956,8 @@ func TestDeniedAuditActionForSpaceFileAction_Mapping(t *testing.T) {
 		{action: "upload", expected: "file.upload", expectedMapped: true},
 		{action: "move", expected: "file.move", expectedMapped: true},
 		{action: "copy", expected: "file.copy", expectedMapped: true},
+		{action: "extract", expected: "file.extract", expectedMapped: true},
+		{action: "compress", expected: "file.compress", expectedMapped: true},
 		{action: "download-multiple", expected: "file.download-multiple", expectedMapped: true},
 		{action: "download-multiple-ticket", expected: "file.download-multiple-ticket", expectedMapped: true},
 		{action: "trash", expectedMapped: false},
@@ -22,6 +22,8 @@ type FileInfo struct {
 	IsDir   bool      `json:"isDir"`
 	Size    int64     `json:"size"`
 	ModTime time.Time `json:"modTime"`
+	// ReadOnly는 압축 파일 안처럼 변경할 수 없는 항목입니다.
+	ReadOnly bool `json:"readOnly,omitempty"`
 }
 
 type Service struct {
@@ -6,6 +6,8 @@ type Config struct {
 	Server                Server     `mapstructure:"server" json:"server" yaml:"server"`
 	AuditLogRetentionDays int        `mapstructure:"audit_log_retention_days" json:"auditLogRetentionDays" yaml:"audit_log_retention_days"`
 	Datasource            Datasource `mapstructure:"database" json:"database" yaml:"database"`
+	// MalwareScan은 설정 파일로만 바꿀 수 있다. 실행할 명령을 API로 고치지 못하게 하기 위해서다.
+	MalwareScan MalwareScan `mapstructure:"malware_scan" json:"-" yaml:"malware_scan,omitempty"`
 }
 
 type Server struct {
@@ -20,3 +22,17 @@ type Server struct {
 type Datasource struct {
 	URL string `mapstructure:"url" json:"url" yaml:"url"`
 }
+
+// MalwareScan은 업로드 파일 검사기 설정이다. Engine이 비어 있으면 어떤 Space에서도 검사를 켤 수 없다.
+type MalwareScan struct {
+	// Engine은 "clamd" 또는 "command"다.
+	Engine string `mapstructure:"engine" yaml:"engine,omitempty"`
+	// ClamdAddress는 "unix:///run/clamav/clamd.ctl" 또는 "tcp://127.0.0.1:3310" 형식이다.
+	ClamdAddress string `mapstructure:"clamd_address" yaml:"clamd_address,omitempty"`
+	// Command와 Args는 셸 없이 실행한다. Args의 {path}가 파일 경로로 바뀌며, 없으면 마지막 인자로 붙는다.
+	Command        string   `mapstructure:"command" yaml:"command,omitempty"`
+	Args           []string `mapstructure:"args" yaml:"args,omitempty"`
+	TimeoutSeconds int      `mapstructure:"timeout_seconds" yaml:"timeout_seconds,omitempty"`
+	// QuarantineDir은 격리 파일을 둘 디렉터리다. 비어 있으면 실행 디렉터리 아래 quarantine이다.
+	QuarantineDir string `mapstructure:"quarantine_dir" yaml:"quarantine_dir,omitempty"`
+}
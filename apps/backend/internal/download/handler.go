@@ -0,0 +1,129 @@
+package download
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"taeu.kr/cohesion/internal/audit"
+	"taeu.kr/cohesion/internal/platform/web"
+)
+
+// Handler는 서명 키 관리와 사용자별 서명 URL 폐기 API를 제공합니다.
+// 서명 URL 자체의 다운로드(GET /api/downloads/signed/{token})는 Space 핸들러가 처리합니다.
+type Handler struct {
+	signer        *Signer
+	actorResolver func(*http.Request) string
+	auditRecorder audit.Recorder
+}
+
+func NewHandler(signer *Signer) *Handler {
+	return &Handler{signer: signer}
+}
+
+// SetActorResolver는 요청에서 사용자명을 꺼내는 함수를 설정합니다.
+func (h *Handler) SetActorResolver(resolver func(*http.Request) string) {
+	h.actorResolver = resolver
+}
+
+func (h *Handler) SetAuditRecorder(recorder audit.Recorder) {
+	h.auditRecorder = recorder
+}
+
+func (h *Handler) RegisterRoutes(mux *http.ServeMux) {
+	mux.Handle("GET /api/downloads/signing-keys", web.Handler(h.handleListKeys))
+	mux.Handle("POST /api/downloads/signing-keys", web.Handler(h.handleRotateKey))
+	mux.Handle("DELETE /api/downloads/signing-keys/", web.Handler(h.handleRetireKey))
+	mux.Handle("POST /api/downloads/revoke", web.Handler(h.handleRevokeOwnLinks))
+}
+
+type keyListResponse struct {
+	Items []*Key `json:"items"`
+}
+
+func (h *Handler) handleListKeys(w http.ResponseWriter, r *http.Request) *web.Error {
+	items, err := h.signer.Keys(r.Context())
+	if err != nil {
+		return &web.Error{Code: http.StatusInternalServerError, Message: "Failed to list signing keys", Err: err}
+	}
+	return writeJSON(w, http.StatusOK, keyListResponse{Items: items})
+}
+
+// handleRotateKey: POST /api/downloads/signing-keys
+// 새 키로 서명을 시작합니다. 기존 키로 서명한 URL은 만료될 때까지 유효합니다.
+func (h *Handler) handleRotateKey(w http.ResponseWriter, r *http.Request) *web.Error {
+	key, err := h.signer.Rotate(r.Context())
+	if err != nil {
+		h.recordAudit(r, "download.signing-key.rotate", audit.ResultFailure, "", nil)
+		return &web.Error{Code: http.StatusInternalServerError, Message: "Failed to rotate signing key", Err: err}
+	}
+	h.recordAudit(r, "download.signing-key.rotate", audit.ResultSuccess, key.ID, map[string]any{"keyId": key.ID})
+	return writeJSON(w, http.StatusCreated, key)
+}
+
+// handleRetireKey: DELETE /api/downloads/signing-keys/{id}
+// 키를 폐기하면 그 키로 서명한 URL이 즉시 무효가 됩니다.
+func (h *Handler) handleRetireKey(w http.ResponseWriter, r *http.Request) *web.Error {
+	id := strings.TrimSpace(strings.TrimPrefix(r.URL.Path, "/api/downloads/signing-keys/"))
+	if id == "" || strings.Contains(id, "/") {
+		return &web.Error{Code: http.StatusBadRequest, Message: "Invalid signing key id"}
+	}
+
+	key, err := h.signer.Retire(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, ErrKeyNotFound) {
+			return &web.Error{Code: http.StatusNotFound, Message: "Signing key not found", Err: err}
+		}
+		h.recordAudit(r, "download.signing-key.retire", audit.ResultFailure, id, map[string]any{"keyId": id})
+		return &web.Error{Code: http.StatusInternalServerError, Message: "Failed to retire signing key", Err: err}
+	}
+	h.recordAudit(r, "download.signing-key.retire", audit.ResultSuccess, id, map[string]any{"keyId": id})
+	return writeJSON(w, http.StatusOK, key)
+}
+
+// handleRevokeOwnLinks: POST /api/downloads/revoke
+// 요청자의 nonce를 바꿔 지금까지 발급받은 서명 URL을 모두 무효화합니다.
+func (h *Handler) handleRevokeOwnLinks(w http.ResponseWriter, r *http.Request) *web.Error {
+	actor := h.resolveActor(r)
+	if actor == "" {
+		return &web.Error{Code: http.StatusUnauthorized, Message: "Unauthorized"}
+	}
+	if err := h.signer.RevokeOwner(r.Context(), actor); err != nil {
+		h.recordAudit(r, "download.links.revoke", audit.ResultFailure, actor, nil)
+		return &web.Error{Code: http.StatusInternalServerError, Message: "Failed to revoke download links", Err: err}
+	}
+	h.recordAudit(r, "download.links.revoke", audit.ResultSuccess, actor, nil)
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+func (h *Handler) resolveActor(r *http.Request) string {
+	if h.actorResolver == nil {
+		return ""
+	}
+	return strings.TrimSpace(h.actorResolver(r))
+}
+
+func (h *Handler) recordAudit(r *http.Request, action string, result audit.Result, target string, metadata map[string]any) {
+	if h.auditRecorder == nil {
+		return
+	}
+	h.auditRecorder.RecordBestEffort(audit.Event{
+		Actor:     h.resolveActor(r),
+		Action:    action,
+		Result:    result,
+		Target:    target,
+		RequestID: strings.TrimSpace(r.Header.Get("X-Request-Id")),
+		Metadata:  metadata,
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, payload any) *web.Error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(payload); err != nil {
+		return &web.Error{Code: http.StatusInternalServerError, Message: "Failed to encode response", Err: err}
+	}
+	return nil
+}
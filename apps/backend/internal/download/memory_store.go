@@ -0,0 +1,69 @@
+package download
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// MemoryStore는 프로세스 메모리에만 서명 키와 nonce를 보관하는 Storer입니다.
+// DB 없이 Signer를 쓰는 테스트나 기본 핸들러 구성에서 사용하며, 재시작하면 발급한 URL이 모두 무효가 됩니다.
+type MemoryStore struct {
+	mu     sync.Mutex
+	keys   map[string]*Key
+	nonces map[string]string
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		keys:   make(map[string]*Key),
+		nonces: make(map[string]string),
+	}
+}
+
+func (s *MemoryStore) ListKeys(ctx context.Context) ([]*Key, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	items := make([]*Key, 0, len(s.keys))
+	for _, key := range s.keys {
+		items = append(items, key.clone())
+	}
+	return items, nil
+}
+
+func (s *MemoryStore) CreateKey(ctx context.Context, key *Key) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, exists := s.keys[key.ID]; exists {
+		return fmt.Errorf("signing key already exists: %s", key.ID)
+	}
+	s.keys[key.ID] = key.clone()
+	return nil
+}
+
+func (s *MemoryStore) RetireKey(ctx context.Context, id string, retiredAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key, ok := s.keys[id]
+	if !ok {
+		return fmt.Errorf("signing key not found: %s", id)
+	}
+	key.RetiredAt = &retiredAt
+	return nil
+}
+
+func (s *MemoryStore) GetNonce(ctx context.Context, owner string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.nonces[owner], nil
+}
+
+func (s *MemoryStore) SetNonce(ctx context.Context, owner string, nonce string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.nonces[owner] = nonce
+	return nil
+}
+
+var _ Storer = (*MemoryStore)(nil)
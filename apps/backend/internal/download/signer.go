@@ -0,0 +1,366 @@
+package download
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	tokenVersion = "v1"
+
+	// DefaultTTL는 만료 시간을 지정하지 않았을 때 서명 URL의 유효 기간입니다.
+	DefaultTTL = time.Hour
+	// MaxTTL는 서명 URL에 허용하는 최대 유효 기간입니다.
+	MaxTTL = 24 * time.Hour
+)
+
+var (
+	ErrMalformedToken   = errors.New("malformed download token")
+	ErrInvalidSignature = errors.New("invalid download token signature")
+	ErrExpired          = errors.New("download token expired")
+	ErrRevoked          = errors.New("download token revoked")
+	ErrAddressMismatch  = errors.New("download token bound to another address")
+	ErrKeyNotFound      = errors.New("signing key not found")
+)
+
+// Claims는 서명 URL에 담기는 내용입니다. 토큰 자체에 모든 정보가 들어 있어 서버가 재시작되어도 유효합니다.
+type Claims struct {
+	SpaceID int64 `json:"sid"`
+	// Path는 Space 기준 상대 경로입니다. JobID가 있으면 비어 있습니다.
+	Path string `json:"p,omitempty"`
+	// JobID는 아카이브 작업 결과물을 내려받는 링크일 때 설정됩니다.
+	JobID string `json:"j,omitempty"`
+	Owner string `json:"o"`
+	// ClientIP가 있으면 해당 주소에서 온 요청만 허용합니다.
+	ClientIP  string `json:"ip,omitempty"`
+	Nonce     string `json:"n"`
+	ExpiresAt int64  `json:"exp"`
+}
+
+func (c *Claims) Expiry() time.Time {
+	return time.Unix(c.ExpiresAt, 0)
+}
+
+// Key는 서명 키입니다. 가장 최근에 만든 활성 키로 서명하고, 폐기되지 않은 키는 모두 검증에 씁니다.
+type Key struct {
+	ID        string     `json:"id"`
+	Secret    []byte     `json:"-"`
+	CreatedAt time.Time  `json:"createdAt"`
+	RetiredAt *time.Time `json:"retiredAt,omitempty"`
+}
+
+func (k *Key) Active() bool {
+	return k.RetiredAt == nil
+}
+
+func (k *Key) clone() *Key {
+	copied := *k
+	copied.Secret = append([]byte(nil), k.Secret...)
+	if k.RetiredAt != nil {
+		retiredAt := *k.RetiredAt
+		copied.RetiredAt = &retiredAt
+	}
+	return &copied
+}
+
+// Storer는 서명 키와 사용자별 nonce 저장소입니다.
+type Storer interface {
+	ListKeys(ctx context.Context) ([]*Key, error)
+	CreateKey(ctx context.Context, key *Key) error
+	RetireKey(ctx context.Context, id string, retiredAt time.Time) error
+	// GetNonce는 사용자의 현재 nonce를 반환합니다. 아직 없으면 빈 문자열을 반환합니다.
+	GetNonce(ctx context.Context, owner string) (string, error)
+	SetNonce(ctx context.Context, owner string, nonce string) error
+}
+
+// Signer는 HMAC-SHA256으로 다운로드 URL 토큰을 서명하고 검증합니다.
+// 토큰 형식: v1.<키 ID>.<base64url(JSON claims)>.<base64url(서명)>
+// 키와 nonce는 저장소에 두고, 매 요청마다 DB를 읽지 않도록 메모리에 캐시합니다.
+type Signer struct {
+	store Storer
+	now   func() time.Time
+
+	mu         sync.Mutex
+	keys       map[string]*Key
+	signingKey *Key
+	nonces     map[string]string
+}
+
+func NewSigner(store Storer) *Signer {
+	return &Signer{
+		store:  store,
+		now:    time.Now,
+		nonces: make(map[string]string),
+	}
+}
+
+// Sign은 claims에 nonce와 만료 시각을 채워 서명한 토큰을 반환합니다.
+// ttl이 0 이하면 DefaultTTL, MaxTTL보다 길면 MaxTTL을 사용합니다.
+func (s *Signer) Sign(ctx context.Context, claims Claims, ttl time.Duration) (string, time.Time, error) {
+	if strings.TrimSpace(claims.Owner) == "" {
+		return "", time.Time{}, errors.New("download token owner is required")
+	}
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+	if ttl > MaxTTL {
+		ttl = MaxTTL
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key, err := s.signingKeyLocked(ctx)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	nonce, err := s.nonceLocked(ctx, claims.Owner, true)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	expiresAt := s.now().Add(ttl).Truncate(time.Second)
+	claims.Nonce = nonce
+	claims.ExpiresAt = expiresAt.Unix()
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to encode download claims: %w", err)
+	}
+
+	signed := tokenVersion + "." + key.ID + "." + base64.RawURLEncoding.EncodeToString(payload)
+	return signed + "." + base64.RawURLEncoding.EncodeToString(sign(key.Secret, signed)), expiresAt, nil
+}
+
+// Verify는 토큰의 서명, 만료, IP 바인딩, 사용자 nonce를 확인하고 claims를 반환합니다.
+// 만료나 폐기처럼 서명은 맞는 경우에는 claims도 함께 반환합니다.
+func (s *Signer) Verify(ctx context.Context, token string, clientIP string) (*Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 4 || parts[0] != tokenVersion {
+		return nil, ErrMalformedToken
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[3])
+	if err != nil {
+		return nil, ErrMalformedToken
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.loadKeysLocked(ctx); err != nil {
+		return nil, err
+	}
+	key, ok := s.keys[parts[1]]
+	if !ok || !key.Active() {
+		return nil, ErrInvalidSignature
+	}
+	if !hmac.Equal(signature, sign(key.Secret, strings.Join(parts[:3], "."))) {
+		return nil, ErrInvalidSignature
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, ErrMalformedToken
+	}
+	var claims Claims
+	if err := json.Unmarshal(payload, &claims); err != nil || claims.Owner == "" {
+		return nil, ErrMalformedToken
+	}
+
+	if !s.now().Before(claims.Expiry()) {
+		return &claims, ErrExpired
+	}
+	if claims.ClientIP != "" && claims.ClientIP != clientIP {
+		return &claims, ErrAddressMismatch
+	}
+	nonce, err := s.nonceLocked(ctx, claims.Owner, false)
+	if err != nil {
+		return nil, err
+	}
+	if nonce == "" || !hmac.Equal([]byte(nonce), []byte(claims.Nonce)) {
+		return &claims, ErrRevoked
+	}
+	return &claims, nil
+}
+
+// RevokeOwner는 사용자 nonce를 바꿔 그 사용자에게 발급된 서명 URL을 모두 무효화합니다.
+func (s *Signer) RevokeOwner(ctx context.Context, owner string) error {
+	nonce, err := randomHex(16)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.store.SetNonce(ctx, owner, nonce); err != nil {
+		return err
+	}
+	s.nonces[owner] = nonce
+	return nil
+}
+
+// Keys는 서명 키 목록을 최신순으로 반환합니다. 비밀 값은 JSON으로 노출되지 않습니다.
+func (s *Signer) Keys(ctx context.Context) ([]*Key, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.loadKeysLocked(ctx); err != nil {
+		return nil, err
+	}
+	items := make([]*Key, 0, len(s.keys))
+	for _, key := range s.keys {
+		items = append(items, key.clone())
+	}
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].CreatedAt.After(items[j].CreatedAt)
+	})
+	return items, nil
+}
+
+// Rotate는 새 서명 키를 만들어 이후 서명에 사용합니다.
+// 이전 키는 폐기하지 않으므로 이미 발급된 URL은 만료될 때까지 계속 동작합니다.
+func (s *Signer) Rotate(ctx context.Context) (*Key, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.loadKeysLocked(ctx); err != nil {
+		return nil, err
+	}
+	key, err := s.createKeyLocked(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return key.clone(), nil
+}
+
+// Retire는 키를 폐기합니다. 그 키로 서명한 URL은 즉시 무효가 됩니다.
+// 서명에 쓰던 키를 폐기하면 다음 서명 때 새 키를 만듭니다.
+func (s *Signer) Retire(ctx context.Context, id string) (*Key, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.loadKeysLocked(ctx); err != nil {
+		return nil, err
+	}
+	key, ok := s.keys[id]
+	if !ok {
+		return nil, ErrKeyNotFound
+	}
+	if !key.Active() {
+		return key.clone(), nil
+	}
+
+	retiredAt := s.now()
+	if err := s.store.RetireKey(ctx, id, retiredAt); err != nil {
+		return nil, err
+	}
+	key.RetiredAt = &retiredAt
+	if s.signingKey != nil && s.signingKey.ID == id {
+		s.signingKey = newestActiveKey(s.keys)
+	}
+	return key.clone(), nil
+}
+
+func (s *Signer) loadKeysLocked(ctx context.Context) error {
+	if s.keys != nil {
+		return nil
+	}
+	items, err := s.store.ListKeys(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load signing keys: %w", err)
+	}
+	s.keys = make(map[string]*Key, len(items))
+	for _, item := range items {
+		s.keys[item.ID] = item.clone()
+	}
+	s.signingKey = newestActiveKey(s.keys)
+	return nil
+}
+
+func (s *Signer) signingKeyLocked(ctx context.Context) (*Key, error) {
+	if err := s.loadKeysLocked(ctx); err != nil {
+		return nil, err
+	}
+	if s.signingKey != nil {
+		return s.signingKey, nil
+	}
+	return s.createKeyLocked(ctx)
+}
+
+func (s *Signer) createKeyLocked(ctx context.Context) (*Key, error) {
+	id, err := randomHex(8)
+	if err != nil {
+		return nil, err
+	}
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, err
+	}
+
+	key := &Key{ID: id, Secret: secret, CreatedAt: s.now()}
+	if err := s.store.CreateKey(ctx, key); err != nil {
+		return nil, fmt.Errorf("failed to store signing key: %w", err)
+	}
+	s.keys[key.ID] = key
+	s.signingKey = key
+	return key, nil
+}
+
+func (s *Signer) nonceLocked(ctx context.Context, owner string, create bool) (string, error) {
+	if nonce, ok := s.nonces[owner]; ok {
+		return nonce, nil
+	}
+	nonce, err := s.store.GetNonce(ctx, owner)
+	if err != nil {
+		return "", fmt.Errorf("failed to load download nonce: %w", err)
+	}
+	if nonce == "" && create {
+		if nonce, err = randomHex(16); err != nil {
+			return "", err
+		}
+		if err := s.store.SetNonce(ctx, owner, nonce); err != nil {
+			return "", fmt.Errorf("failed to store download nonce: %w", err)
+		}
+	}
+	if nonce != "" {
+		s.nonces[owner] = nonce
+	}
+	return nonce, nil
+}
+
+func newestActiveKey(keys map[string]*Key) *Key {
+	var newest *Key
+	for _, key := range keys {
+		if !key.Active() {
+			continue
+		}
+		if newest == nil || key.CreatedAt.After(newest.CreatedAt) {
+			newest = key
+		}
+	}
+	return newest
+}
+
+func sign(secret []byte, message string) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(message))
+	return mac.Sum(nil)
+}
+
+func randomHex(size int) (string, error) {
+	buf := make([]byte, size)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
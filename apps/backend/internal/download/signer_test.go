@@ -0,0 +1,116 @@
+package download
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSigner_VerifiesAcrossRestartUntilExpiry(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	signer := NewSigner(store)
+
+	token, expiresAt, err := signer.Sign(ctx, Claims{SpaceID: 3, Path: "docs/report.pdf", Owner: "alice", ClientIP: "10.0.0.5"}, 10*time.Minute)
+	if err != nil {
+		t.Fatalf("failed to sign: %v", err)
+	}
+
+	// 같은 저장소를 쓰는 새 Signer는 재시작된 서버와 같다.
+	restarted := NewSigner(store)
+	claims, err := restarted.Verify(ctx, token, "10.0.0.5")
+	if err != nil {
+		t.Fatalf("expected token to survive restart, got %v", err)
+	}
+	if claims.SpaceID != 3 || claims.Path != "docs/report.pdf" || claims.Owner != "alice" || !claims.Expiry().Equal(expiresAt) {
+		t.Fatalf("unexpected claims: %+v", claims)
+	}
+
+	if _, err := restarted.Verify(ctx, token, "10.0.0.6"); !errors.Is(err, ErrAddressMismatch) {
+		t.Fatalf("expected ErrAddressMismatch, got %v", err)
+	}
+
+	parts := strings.Split(token, ".")
+	parts[2] = parts[2][:len(parts[2])-2] + "AA"
+	if _, err := restarted.Verify(ctx, strings.Join(parts, "."), "10.0.0.5"); !errors.Is(err, ErrInvalidSignature) {
+		t.Fatalf("expected ErrInvalidSignature for tampered payload, got %v", err)
+	}
+
+	restarted.now = func() time.Time { return expiresAt }
+	if _, err := restarted.Verify(ctx, token, "10.0.0.5"); !errors.Is(err, ErrExpired) {
+		t.Fatalf("expected ErrExpired, got %v", err)
+	}
+}
+
+func TestSigner_RevokeOwnerInvalidatesOnlyThatOwner(t *testing.T) {
+	ctx := context.Background()
+	signer := NewSigner(NewMemoryStore())
+
+	aliceToken, _, err := signer.Sign(ctx, Claims{SpaceID: 1, Path: "a.txt", Owner: "alice"}, 0)
+	if err != nil {
+		t.Fatalf("failed to sign: %v", err)
+	}
+	bobToken, _, err := signer.Sign(ctx, Claims{SpaceID: 1, Path: "b.txt", Owner: "bob"}, 0)
+	if err != nil {
+		t.Fatalf("failed to sign: %v", err)
+	}
+
+	if err := signer.RevokeOwner(ctx, "alice"); err != nil {
+		t.Fatalf("failed to revoke: %v", err)
+	}
+	if _, err := signer.Verify(ctx, aliceToken, ""); !errors.Is(err, ErrRevoked) {
+		t.Fatalf("expected ErrRevoked, got %v", err)
+	}
+	if _, err := signer.Verify(ctx, bobToken, ""); err != nil {
+		t.Fatalf("expected other owner's token to stay valid, got %v", err)
+	}
+
+	fresh, _, err := signer.Sign(ctx, Claims{SpaceID: 1, Path: "a.txt", Owner: "alice"}, 0)
+	if err != nil {
+		t.Fatalf("failed to sign: %v", err)
+	}
+	if _, err := signer.Verify(ctx, fresh, ""); err != nil {
+		t.Fatalf("expected token issued after revoke to be valid, got %v", err)
+	}
+}
+
+func TestSigner_RotateKeepsOldKeyUntilRetired(t *testing.T) {
+	ctx := context.Background()
+	signer := NewSigner(NewMemoryStore())
+
+	oldToken, _, err := signer.Sign(ctx, Claims{SpaceID: 1, Path: "a.txt", Owner: "alice"}, 0)
+	if err != nil {
+		t.Fatalf("failed to sign: %v", err)
+	}
+	oldKeyID := strings.Split(oldToken, ".")[1]
+
+	rotated, err := signer.Rotate(ctx)
+	if err != nil {
+		t.Fatalf("failed to rotate: %v", err)
+	}
+	newToken, _, err := signer.Sign(ctx, Claims{SpaceID: 1, Path: "a.txt", Owner: "alice"}, 0)
+	if err != nil {
+		t.Fatalf("failed to sign: %v", err)
+	}
+	if keyID := strings.Split(newToken, ".")[1]; keyID != rotated.ID {
+		t.Fatalf("expected new token to use rotated key %s, got %s", rotated.ID, keyID)
+	}
+	if _, err := signer.Verify(ctx, oldToken, ""); err != nil {
+		t.Fatalf("expected old token to stay valid after rotation, got %v", err)
+	}
+
+	if _, err := signer.Retire(ctx, oldKeyID); err != nil {
+		t.Fatalf("failed to retire: %v", err)
+	}
+	if _, err := signer.Verify(ctx, oldToken, ""); !errors.Is(err, ErrInvalidSignature) {
+		t.Fatalf("expected retired key to be rejected, got %v", err)
+	}
+	if _, err := signer.Verify(ctx, newToken, ""); err != nil {
+		t.Fatalf("expected token from active key to stay valid, got %v", err)
+	}
+	if _, err := signer.Retire(ctx, "missing"); !errors.Is(err, ErrKeyNotFound) {
+		t.Fatalf("expected ErrKeyNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,139 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"taeu.kr/cohesion/internal/download"
+)
+
+type Store struct {
+	db *sql.DB
+	qb sq.StatementBuilderType
+}
+
+func NewStore(db *sql.DB) *Store {
+	return &Store{
+		db: db,
+		qb: sq.StatementBuilder.PlaceholderFormat(sq.Question),
+	}
+}
+
+func (s *Store) ListKeys(ctx context.Context) ([]*download.Key, error) {
+	sqlQuery, args, err := s.qb.
+		Select("id", "secret", "created_at", "retired_at").
+		From("download_signing_keys").
+		OrderBy("created_at DESC").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build SQL query for ListKeys: %w", err)
+	}
+
+	rows, err := s.db.QueryContext(ctx, sqlQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query signing keys: %w", err)
+	}
+	defer rows.Close()
+
+	items := make([]*download.Key, 0)
+	for rows.Next() {
+		var (
+			item      download.Key
+			retiredAt sql.NullTime
+		)
+		if err := rows.Scan(&item.ID, &item.Secret, &item.CreatedAt, &retiredAt); err != nil {
+			return nil, fmt.Errorf("failed to scan signing key row: %w", err)
+		}
+		if retiredAt.Valid {
+			item.RetiredAt = &retiredAt.Time
+		}
+		items = append(items, &item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error in signing key list: %w", err)
+	}
+	return items, nil
+}
+
+func (s *Store) CreateKey(ctx context.Context, key *download.Key) error {
+	sqlQuery, args, err := s.qb.
+		Insert("download_signing_keys").
+		Columns("id", "secret", "created_at").
+		Values(key.ID, key.Secret, key.CreatedAt.UTC()).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build SQL query for CreateKey: %w", err)
+	}
+
+	if _, err := s.db.ExecContext(ctx, sqlQuery, args...); err != nil {
+		return fmt.Errorf("failed to insert signing key: %w", err)
+	}
+	return nil
+}
+
+func (s *Store) RetireKey(ctx context.Context, id string, retiredAt time.Time) error {
+	sqlQuery, args, err := s.qb.
+		Update("download_signing_keys").
+		Set("retired_at", retiredAt.UTC()).
+		Where(sq.Eq{"id": id}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build SQL query for RetireKey: %w", err)
+	}
+
+	result, err := s.db.ExecContext(ctx, sqlQuery, args...)
+	if err != nil {
+		return fmt.Errorf("failed to retire signing key: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("signing key with id %s not found", id)
+	}
+	return nil
+}
+
+func (s *Store) GetNonce(ctx context.Context, owner string) (string, error) {
+	sqlQuery, args, err := s.qb.
+		Select("nonce").
+		From("download_link_nonces").
+		Where(sq.Eq{"username": owner}).
+		Limit(1).
+		ToSql()
+	if err != nil {
+		return "", fmt.Errorf("failed to build SQL query for GetNonce: %w", err)
+	}
+
+	var nonce string
+	if err := s.db.QueryRowContext(ctx, sqlQuery, args...).Scan(&nonce); err != nil {
+		if err == sql.ErrNoRows {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to query download nonce: %w", err)
+	}
+	return nonce, nil
+}
+
+func (s *Store) SetNonce(ctx context.Context, owner string, nonce string) error {
+	sqlQuery, args, err := s.qb.
+		Insert("download_link_nonces").
+		Columns("username", "nonce", "updated_at").
+		Values(owner, nonce, time.Now().UTC()).
+		Suffix("ON CONFLICT(username) DO UPDATE SET nonce = excluded.nonce, updated_at = excluded.updated_at").
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build SQL query for SetNonce: %w", err)
+	}
+
+	if _, err := s.db.ExecContext(ctx, sqlQuery, args...); err != nil {
+		return fmt.Errorf("failed to store download nonce: %w", err)
+	}
+	return nil
+}
+
+var _ download.Storer = (*Store)(nil)
@@ -1,6 +1,7 @@
 package ftp
 
 import (
+	"bufio"
 	"context"
 	"errors"
 	"io"
@@ -11,19 +12,35 @@ import (
 	"time"
 
 	goftp "github.com/goftp/server"
+	"github.com/rs/zerolog/log"
 	"taeu.kr/cohesion/internal/account"
 	"taeu.kr/cohesion/internal/space"
 )
 
+// errQuotaExceeded는 Space(용량·파일 수) 또는 사용자 할당량을 넘는 업로드다.
+var errQuotaExceeded = errors.New("storage quota exceeded")
+
 type driverFactory struct {
 	spaceService   *space.Service
 	accountService *account.Service
+	usage          space.UsageTracker
+	userStorage    space.UserStorageTracker
+	uploadPolicy   space.UploadPolicyEnforcer
+	malwareScan    space.MalwareScanHook
+	retention      space.RetentionGuard
+	fileEvents     space.FileEventPublisher
 }
 
 func (f *driverFactory) NewDriver() (goftp.Driver, error) {
 	return &spaceDriver{
 		spaceService:   f.spaceService,
 		accountService: f.accountService,
+		usage:          f.usage,
+		userStorage:    f.userStorage,
+		uploadPolicy:   f.uploadPolicy,
+		malwareScan:    f.malwareScan,
+		retention:      f.retention,
+		fileEvents:     f.fileEvents,
 		perm:           goftp.NewSimplePerm("cohesion", "cohesion"),
 	}, nil
 }
@@ -31,6 +48,12 @@ func (f *driverFactory) NewDriver() (goftp.Driver, error) {
 type spaceDriver struct {
 	spaceService   *space.Service
 	accountService *account.Service
+	usage          space.UsageTracker
+	userStorage    space.UserStorageTracker
+	uploadPolicy   space.UploadPolicyEnforcer
+	malwareScan    space.MalwareScanHook
+	retention      space.RetentionGuard
+	fileEvents     space.FileEventPublisher
 	perm           goftp.Perm
 	conn           *goftp.Conn
 }
@@ -57,7 +80,7 @@ func (d *spaceDriver) Stat(virtualPath string) (goftp.FileInfo, error) {
 
 	name := info.Name()
 	if relPath == "" {
-		name = spaceObj.SpaceName
+		name = spaceObj.ProtocolName()
 	}
 	return d.wrapFileInfo(cleanPath, info, name)
 }
@@ -113,10 +136,11 @@ func (d *spaceDriver) DeleteDir(virtualPath string) error {
 		return os.ErrPermission
 	}
 
-	_, absPath, relPath, err := d.resolvePath(cleanPath, account.PermissionWrite)
+	spaceObj, absPath, relPath, release, err := d.resolveWritePath(cleanPath)
 	if err != nil {
 		return err
 	}
+	defer release()
 	if relPath == "" {
 		return os.ErrPermission
 	}
@@ -128,16 +152,20 @@ func (d *spaceDriver) DeleteDir(virtualPath string) error {
 	if !info.IsDir() {
 		return errors.New("not a directory")
 	}
+	if err := d.ensureMutable(spaceObj, absPath, "delete"); err != nil {
+		return err
+	}
 
 	return os.Remove(absPath)
 }
 
 func (d *spaceDriver) DeleteFile(virtualPath string) error {
 	cleanPath := normalizeVirtualPath(virtualPath)
-	_, absPath, relPath, err := d.resolvePath(cleanPath, account.PermissionWrite)
+	spaceObj, absPath, relPath, release, err := d.resolveWritePath(cleanPath)
 	if err != nil {
 		return err
 	}
+	defer release()
 	if relPath == "" {
 		return os.ErrPermission
 	}
@@ -149,8 +177,20 @@ func (d *spaceDriver) DeleteFile(virtualPath string) error {
 	if info.IsDir() {
 		return errors.New("not a file")
 	}
+	if err := d.ensureMutable(spaceObj, absPath, "delete"); err != nil {
+		return err
+	}
 
-	return os.Remove(absPath)
+	if err := os.Remove(absPath); err != nil {
+		return err
+	}
+	d.adjustUsage(spaceObj.ID, -info.Size(), -1)
+	if d.userStorage != nil {
+		if err := d.userStorage.DeleteOwners(context.Background(), spaceObj.ID, spaceObj.SpacePath, absPath); err != nil {
+			log.Warn().Err(err).Str("path", absPath).Msg("failed to delete FTP file owners")
+		}
+	}
+	return nil
 }
 
 func (d *spaceDriver) Rename(fromPath string, toPath string) error {
@@ -172,26 +212,66 @@ func (d *spaceDriver) Rename(fromPath string, toPath string) error {
 	if _, err := d.resolveSpaceByName(fromSpace, account.PermissionWrite); err != nil {
 		return err
 	}
-	spaceObj, err := d.resolveSpaceByName(toSpace, account.PermissionWrite)
+	spaceObj, release, err := d.resolveWritableSpace(toSpace)
 	if err != nil {
 		return err
 	}
+	defer release()
 
 	absFrom := filepath.Join(spaceObj.SpacePath, filepath.FromSlash(fromRel))
 	absTo := filepath.Join(spaceObj.SpacePath, filepath.FromSlash(toRel))
 	if !isPathWithinSpace(absFrom, spaceObj.SpacePath) || !isPathWithinSpace(absTo, spaceObj.SpacePath) {
 		return os.ErrPermission
 	}
+	if absFrom == absTo {
+		return os.Rename(absFrom, absTo)
+	}
+	if err := d.ensureMutable(spaceObj, absFrom, "move"); err != nil {
+		return err
+	}
+	if err := d.ensureMutable(spaceObj, absTo, "overwrite"); err != nil {
+		return err
+	}
+	if err := d.checkRenamePolicy(spaceObj.ID, absFrom, absTo); err != nil {
+		return err
+	}
 
-	return os.Rename(absFrom, absTo)
+	// 같은 Space 안의 이동이므로 사용량은 덮어쓴 대상 크기만큼만 줄어든다.
+	replaced, measureErr := space.MeasurePathUsage(context.Background(), absTo)
+	if err := os.Rename(absFrom, absTo); err != nil {
+		return err
+	}
+	if measureErr != nil {
+		d.invalidateUsage(spaceObj.ID)
+	} else {
+		d.adjustUsage(spaceObj.ID, -replaced.Bytes, -replaced.Files)
+	}
+	if d.userStorage != nil {
+		if err := d.userStorage.MoveOwners(context.Background(), spaceObj.ID, spaceObj.SpacePath, absFrom, spaceObj.ID, spaceObj.SpacePath, absTo); err != nil {
+			log.Warn().Err(err).Str("path", absFrom).Msg("failed to move FTP file owners")
+		}
+	}
+	return nil
+}
+
+// checkRenamePolicy는 파일 확장자를 바꾸는 이름 변경이면 새 이름을 업로드 정책으로 확인한다.
+func (d *spaceDriver) checkRenamePolicy(spaceID int64, absFrom, absTo string) error {
+	if d.uploadPolicy == nil || strings.EqualFold(filepath.Ext(absFrom), filepath.Ext(absTo)) {
+		return nil
+	}
+	if info, err := os.Stat(absFrom); err != nil || info.IsDir() {
+		return nil
+	}
+	return d.uploadPolicy.CheckName(context.Background(), spaceID, filepath.Base(absTo))
 }
 
 func (d *spaceDriver) MakeDir(virtualPath string) error {
 	cleanPath := normalizeVirtualPath(virtualPath)
-	spaceObj, absPath, relPath, err := d.resolvePath(cleanPath, account.PermissionWrite)
+	spaceObj, absPath, relPath, release, err := d.resolveWritePath(cleanPath)
 	if err != nil {
 		return err
 	}
+	defer release()
 	if relPath == "" || !isPathWithinSpace(absPath, spaceObj.SpacePath) {
 		return os.ErrPermission
 	}
@@ -233,10 +313,11 @@ func (d *spaceDriver) GetFile(virtualPath string, offset int64) (int64, io.ReadC
 
 func (d *spaceDriver) PutFile(virtualPath string, data io.Reader, appendData bool) (int64, error) {
 	cleanPath := normalizeVirtualPath(virtualPath)
-	spaceObj, absPath, relPath, err := d.resolvePath(cleanPath, account.PermissionWrite)
+	spaceObj, absPath, relPath, release, err := d.resolveWritePath(cleanPath)
 	if err != nil {
 		return 0, err
 	}
+	defer release()
 	if relPath == "" {
 		return 0, os.ErrPermission
 	}
@@ -256,19 +337,197 @@ func (d *spaceDriver) PutFile(virtualPath string, data io.Reader, appendData boo
 		flags |= os.O_TRUNC
 	}
 
+	if err := d.ensureMutable(spaceObj, absPath, "write"); err != nil {
+		return 0, err
+	}
+
+	remaining, err := d.writeAllowance(spaceObj.ID, absPath, appendData)
+	if err != nil {
+		return 0, err
+	}
+	if remaining == 0 {
+		return 0, errQuotaExceeded
+	}
+	if err := space.EnsureCanCreateFile(context.Background(), d.usage, spaceObj.ID, absPath); err != nil {
+		var fileQuotaErr *space.FileQuotaExceededError
+		if errors.As(err, &fileQuotaErr) {
+			return 0, errQuotaExceeded
+		}
+		return 0, err
+	}
+
+	// 정책에 걸리는 내용이면 기존 파일을 비우기 전에 거절하도록 앞부분을 먼저 읽어 확인한다.
+	var offset int64
+	if appendData {
+		if info, statErr := os.Stat(absPath); statErr == nil {
+			offset = info.Size()
+		}
+	}
+	guard, err := d.beginUpload(spaceObj.ID, absPath)
+	if err != nil {
+		return 0, err
+	}
+	if guard != nil {
+		buffered := bufio.NewReaderSize(data, space.UploadPolicySniffBytes)
+		head, _ := buffered.Peek(space.UploadPolicySniffBytes)
+		if err := guard.Check(offset, head); err != nil {
+			return 0, err
+		}
+		data = buffered
+	}
+
+	commit := space.TrackUsage(context.Background(), d.usage, spaceObj.ID, absPath)
 	file, err := os.OpenFile(absPath, flags, 0644)
 	if err != nil {
 		return 0, err
 	}
-	defer file.Close()
 
-	written, err := io.Copy(file, data)
+	var writer io.Writer = file
+	if remaining > 0 {
+		writer = &quotaWriter{writer: file, remaining: remaining}
+	}
+	if guard != nil {
+		writer = &policyWriter{writer: writer, guard: guard, offset: offset}
+	}
+	written, err := io.Copy(writer, data)
+	// 실패해도 일부가 써졌을 수 있으므로 닫은 뒤 실제 크기로 반영한다.
+	_ = file.Close()
+	commit()
+	d.recordOwner(spaceObj, absPath)
 	if err != nil {
 		return 0, err
 	}
+	// 사용량을 반영한 뒤 검사해야 격리할 때 사용량도 함께 돌려받는다.
+	if written > 0 {
+		if err := d.scanWrittenFile(spaceObj, absPath); err != nil {
+			return 0, err
+		}
+		d.publishFileCreated(spaceObj, absPath)
+	}
 	return written, nil
 }
 
+// scanWrittenFile은 다 받은 파일을 Space 검사 설정에 따라 검사한다. block 모드에서 감염이면 오류를 반환한다.
+func (d *spaceDriver) scanWrittenFile(spaceObj *space.Space, absPath string) error {
+	if d.malwareScan == nil {
+		return nil
+	}
+	return d.malwareScan.ScanWrittenFile(context.Background(), space.MalwareScanTarget{
+		SpaceID:   spaceObj.ID,
+		SpaceRoot: spaceObj.SpacePath,
+		FilePath:  absPath,
+		Actor:     d.username(),
+		Source:    "ftp",
+	})
+}
+
+// publishFileCreated는 검사를 통과한 파일의 이벤트를 낸다.
+func (d *spaceDriver) publishFileCreated(spaceObj *space.Space, absPath string) {
+	if d.fileEvents == nil {
+		return
+	}
+	d.fileEvents.PublishFileEvent(context.Background(), space.FileEvent{
+		Kind:      space.FileEventCreated,
+		SpaceID:   spaceObj.ID,
+		SpaceRoot: spaceObj.SpacePath,
+		AbsPath:   absPath,
+		Actor:     d.username(),
+		Source:    "ftp",
+		At:        time.Now().UTC(),
+	})
+}
+
+// ensureMutable은 이미 있는 absPath를 바꾸기 전에 보존 잠금을 확인한다.
+func (d *spaceDriver) ensureMutable(spaceObj *space.Space, absPath, operation string) error {
+	if d.retention == nil {
+		return nil
+	}
+	return d.retention.EnsureMutable(context.Background(), space.RetentionTarget{
+		SpaceID:   spaceObj.ID,
+		SpaceRoot: spaceObj.SpacePath,
+		AbsPath:   absPath,
+		Operation: operation,
+		Actor:     d.username(),
+		Source:    "ftp",
+	})
+}
+
+// writeAllowance는 이번 업로드로 쓸 수 있는 바이트 수다. 덮어쓰면 기존 파일 크기를 돌려받는다. 제한이 없으면 -1이다.
+func (d *spaceDriver) writeAllowance(spaceID int64, absPath string, appendData bool) (int64, error) {
+	if d.userStorage == nil {
+		return -1, nil
+	}
+	allowance, err := d.userStorage.WriteAllowance(context.Background(), d.username(), spaceID)
+	if err != nil || allowance < 0 || appendData {
+		return allowance, err
+	}
+	existing, err := space.MeasurePaths(context.Background(), absPath)
+	if err != nil {
+		return 0, err
+	}
+	return allowance + existing, nil
+}
+
+func (d *spaceDriver) recordOwner(spaceObj *space.Space, absPath string) {
+	if d.userStorage == nil {
+		return
+	}
+	if err := d.userStorage.RecordOwner(context.Background(), d.username(), spaceObj.ID, spaceObj.SpacePath, absPath); err != nil {
+		log.Warn().Err(err).Str("path", absPath).Msg("failed to record FTP file owner")
+	}
+}
+
+// quotaWriter는 remaining바이트를 넘겨 쓰려 하면 errQuotaExceeded를 반환한다.
+type quotaWriter struct {
+	writer    io.Writer
+	remaining int64
+}
+
+func (w *quotaWriter) Write(p []byte) (int, error) {
+	if int64(len(p)) > w.remaining {
+		return 0, errQuotaExceeded
+	}
+	n, err := w.writer.Write(p)
+	w.remaining -= int64(n)
+	return n, err
+}
+
+// beginUpload는 absPath 이름을 업로드 정책으로 확인하고 올리는 내용을 검사할 guard를 반환한다.
+func (d *spaceDriver) beginUpload(spaceID int64, absPath string) (*space.UploadWriteGuard, error) {
+	if d.uploadPolicy == nil {
+		return nil, nil
+	}
+	return d.uploadPolicy.BeginUpload(context.Background(), spaceID, filepath.Base(absPath))
+}
+
+// policyWriter는 offset부터 이어 쓰는 내용을 업로드 정책의 최대 크기로 확인한다.
+type policyWriter struct {
+	writer io.Writer
+	guard  *space.UploadWriteGuard
+	offset int64
+}
+
+func (w *policyWriter) Write(p []byte) (int, error) {
+	if err := w.guard.Check(w.offset, p); err != nil {
+		return 0, err
+	}
+	n, err := w.writer.Write(p)
+	w.offset += int64(n)
+	return n, err
+}
+
+func (d *spaceDriver) adjustUsage(spaceID int64, deltaBytes int64, deltaFiles int64) {
+	if d.usage != nil {
+		d.usage.AdjustUsage(context.Background(), spaceID, deltaBytes, deltaFiles)
+	}
+}
+
+func (d *spaceDriver) invalidateUsage(spaceID int64) {
+	if d.usage != nil {
+		d.usage.Invalidate(spaceID)
+	}
+}
+
 func (d *spaceDriver) listAccessibleSpaces(callback func(goftp.FileInfo) error) error {
 	spaces, err := d.spaceService.GetAllSpaces(context.Background())
 	if err != nil {
@@ -277,6 +536,9 @@ func (d *spaceDriver) listAccessibleSpaces(callback func(goftp.FileInfo) error)
 
 	username := d.username()
 	for _, sp := range spaces {
+		if !sp.IsListed() {
+			continue
+		}
 		allowed, err := d.accountService.CanAccessSpaceByID(context.Background(), username, sp.ID, account.PermissionRead)
 		if err != nil {
 			return err
@@ -289,7 +551,7 @@ func (d *spaceDriver) listAccessibleSpaces(callback func(goftp.FileInfo) error)
 		if stat, err := os.Stat(sp.SpacePath); err == nil {
 			mod = stat.ModTime()
 		}
-		if err := callback(newVirtualDirInfo(sp.SpaceName, "cohesion", "cohesion", mod)); err != nil {
+		if err := callback(newVirtualDirInfo(sp.ProtocolName(), "cohesion", "cohesion", mod)); err != nil {
 			return err
 		}
 	}
@@ -306,19 +568,56 @@ func (d *spaceDriver) resolvePath(cleanPath string, required account.Permission)
 	if err != nil {
 		return nil, "", "", err
 	}
+	absPath, err := joinSpacePath(spaceObj, relPath)
+	if err != nil {
+		return nil, "", "", err
+	}
+	return spaceObj, absPath, relPath, nil
+}
 
+// resolveWritePath는 resolvePath와 같지만 쓰기를 진행 중으로 기록하고, 쓰기가 끝나면 호출할 함수를 함께 반환한다.
+func (d *spaceDriver) resolveWritePath(cleanPath string) (*space.Space, string, string, func(), error) {
+	spaceName, relPath, err := splitVirtualPath(cleanPath)
+	if err != nil {
+		return nil, "", "", nil, err
+	}
+
+	spaceObj, release, err := d.resolveWritableSpace(spaceName)
+	if err != nil {
+		return nil, "", "", nil, err
+	}
+	absPath, err := joinSpacePath(spaceObj, relPath)
+	if err != nil {
+		release()
+		return nil, "", "", nil, err
+	}
+	return spaceObj, absPath, relPath, release, nil
+}
+
+// resolveWritableSpace는 쓰기 권한을 확인하고 저장소 이전이 root를 바꾸지 못하도록 쓰기를 진행 중으로 기록한다.
+// 경로는 반환한 Space의 root로 정해야 한다.
+func (d *spaceDriver) resolveWritableSpace(spaceName string) (*space.Space, func(), error) {
+	spaceObj, err := d.resolveSpaceByName(spaceName, account.PermissionWrite)
+	if err != nil {
+		return nil, nil, err
+	}
+	return d.spaceService.BeginWrite(context.Background(), spaceObj.ID)
+}
+
+func joinSpacePath(spaceObj *space.Space, relPath string) (string, error) {
 	absPath := spaceObj.SpacePath
 	if relPath != "" {
 		absPath = filepath.Join(spaceObj.SpacePath, filepath.FromSlash(relPath))
 	}
 	if !isPathWithinSpace(absPath, spaceObj.SpacePath) {
-		return nil, "", "", os.ErrPermission
+		return "", os.ErrPermission
 	}
-	return spaceObj, absPath, relPath, nil
+	return absPath, nil
 }
 
 func (d *spaceDriver) resolveSpaceByName(spaceName string, required account.Permission) (*space.Space, error) {
-	spaceObj, err := d.spaceService.GetSpaceByName(context.Background(), spaceName)
+	// 이름 변경 전 경로(alias)도 투명하게 같은 Space로 해석한다.
+	spaceObj, _, err := d.spaceService.ResolveSpaceByProtocolName(context.Background(), spaceName)
 	if err != nil {
 		return nil, os.ErrNotExist
 	}
@@ -330,6 +629,18 @@ func (d *spaceDriver) resolveSpaceByName(spaceName string, required account.Perm
 	if !allowed {
 		return nil, os.ErrPermission
 	}
+	if err := d.spaceService.EnsureOnline(spaceObj.ID); err != nil {
+		return nil, err
+	}
+	if spaceObj.State() == space.SpaceStateMaintenance {
+		isAdmin, err := d.accountService.IsAdmin(context.Background(), d.username())
+		if err != nil {
+			return nil, err
+		}
+		if err := spaceObj.CheckReadable(isAdmin); err != nil {
+			return nil, err
+		}
+	}
 
 	return spaceObj, nil
 }
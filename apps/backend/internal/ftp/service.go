@@ -30,6 +30,18 @@ type Service struct {
 	port           int
 	running        bool
 	mu             sync.RWMutex
+	// usage가 있으면 업로드/삭제로 바뀐 크기를 Space 사용량 카운터에 알린다.
+	usage space.UsageTracker
+	// userStorage가 있으면 할당량을 넘는 업로드를 막고 올린 파일의 소유자를 기록한다.
+	userStorage space.UserStorageTracker
+	// uploadPolicy가 있으면 Space 업로드 정책에 맞지 않는 파일을 올리지 못하게 한다.
+	uploadPolicy space.UploadPolicyEnforcer
+	// malwareScan이 있으면 다 받은 파일을 Space 검사 설정에 따라 검사한다.
+	malwareScan space.MalwareScanHook
+	// fileEvents가 있으면 다 받은 파일의 이벤트를 내 자동 정리 규칙 같은 후속 처리를 맡긴다.
+	fileEvents space.FileEventPublisher
+	// retention이 있으면 보존 잠금이 걸린 폴더의 기존 파일을 덮어쓰거나 옮기거나 지우지 못하게 한다.
+	retention space.RetentionGuard
 }
 
 func NewService(spaceService *space.Service, accountService *account.Service, enabled bool, port int) *Service {
@@ -45,6 +57,36 @@ func NewService(spaceService *space.Service, accountService *account.Service, en
 	}
 }
 
+// SetUsageTracker는 FTP로 바뀐 파일 크기를 Space 사용량 카운터에 반영하도록 설정한다.
+func (s *Service) SetUsageTracker(tracker space.UsageTracker) {
+	s.usage = tracker
+}
+
+// SetUserStorageTracker는 FTP 업로드에 Space·사용자 할당량을 적용하고 파일 소유자를 기록하도록 설정한다.
+func (s *Service) SetUserStorageTracker(tracker space.UserStorageTracker) {
+	s.userStorage = tracker
+}
+
+// SetUploadPolicyEnforcer는 FTP 업로드와 이름 변경에 Space 업로드 정책을 적용하도록 설정한다.
+func (s *Service) SetUploadPolicyEnforcer(enforcer space.UploadPolicyEnforcer) {
+	s.uploadPolicy = enforcer
+}
+
+// SetMalwareScanHook은 FTP로 받은 파일을 Space 검사 설정에 따라 검사하도록 설정한다.
+func (s *Service) SetMalwareScanHook(hook space.MalwareScanHook) {
+	s.malwareScan = hook
+}
+
+// SetFileEventPublisher는 FTP로 받은 파일의 이벤트를 publisher로 내도록 설정한다.
+func (s *Service) SetFileEventPublisher(publisher space.FileEventPublisher) {
+	s.fileEvents = publisher
+}
+
+// SetRetentionGuard는 FTP 업로드·이름 변경·삭제에 폴더별 보존 잠금을 적용하도록 설정한다.
+func (s *Service) SetRetentionGuard(guard space.RetentionGuard) {
+	s.retention = guard
+}
+
 func (s *Service) Start() error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -58,7 +100,7 @@ func (s *Service) Start() error {
 	}
 
 	opts := &goftp.ServerOpts{
-		Factory:        &driverFactory{spaceService: s.spaceService, accountService: s.accountService},
+		Factory:        &driverFactory{spaceService: s.spaceService, accountService: s.accountService, usage: s.usage, userStorage: s.userStorage, uploadPolicy: s.uploadPolicy, malwareScan: s.malwareScan, retention: s.retention, fileEvents: s.fileEvents},
 		Port:           s.port,
 		Hostname:       "0.0.0.0",
 		Name:           "Cohesion FTP",
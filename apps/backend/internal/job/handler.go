@@ -0,0 +1,192 @@
+package job
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"taeu.kr/cohesion/internal/audit"
+	"taeu.kr/cohesion/internal/platform/web"
+)
+
+const (
+	defaultListLimit = 50
+	maxListLimit     = 200
+)
+
+type Handler struct {
+	manager       *Manager
+	ownerResolver func(*http.Request) string
+	auditRecorder audit.Recorder
+}
+
+func NewHandler(manager *Manager) *Handler {
+	return &Handler{manager: manager}
+}
+
+// SetOwnerResolver는 요청에서 작업 소유자(사용자명)를 꺼내는 함수를 설정합니다.
+func (h *Handler) SetOwnerResolver(resolver func(*http.Request) string) {
+	h.ownerResolver = resolver
+}
+
+func (h *Handler) SetAuditRecorder(recorder audit.Recorder) {
+	h.auditRecorder = recorder
+}
+
+func (h *Handler) RegisterRoutes(mux *http.ServeMux) {
+	mux.Handle("GET /api/jobs", web.Handler(h.handleListJobs))
+	mux.Handle("GET /api/jobs/", web.Handler(h.handleGetJob))
+	mux.Handle("DELETE /api/jobs/", web.Handler(h.handleCancelJob))
+}
+
+type listResponse struct {
+	Items []*Job `json:"items"`
+}
+
+func (h *Handler) handleListJobs(w http.ResponseWriter, r *http.Request) *web.Error {
+	owner := h.resolveOwner(r)
+	if owner == "" {
+		return &web.Error{Code: http.StatusUnauthorized, Message: "Unauthorized"}
+	}
+
+	filter, err := parseListFilter(r)
+	if err != nil {
+		return &web.Error{Code: http.StatusBadRequest, Message: "Invalid job query", Err: err}
+	}
+	filter.Owner = owner
+
+	items, err := h.manager.List(r.Context(), filter)
+	if err != nil {
+		return &web.Error{Code: http.StatusInternalServerError, Message: "Failed to list jobs", Err: err}
+	}
+	return writeJSON(w, http.StatusOK, listResponse{Items: items})
+}
+
+func (h *Handler) handleGetJob(w http.ResponseWriter, r *http.Request) *web.Error {
+	found, webErr := h.ownedJobFromPath(r)
+	if webErr != nil {
+		return webErr
+	}
+	return writeJSON(w, http.StatusOK, found)
+}
+
+func (h *Handler) handleCancelJob(w http.ResponseWriter, r *http.Request) *web.Error {
+	found, webErr := h.ownedJobFromPath(r)
+	if webErr != nil {
+		return webErr
+	}
+
+	canceled, err := h.manager.Cancel(r.Context(), found.ID)
+	if err != nil {
+		if errors.Is(err, ErrNotCancelable) {
+			return &web.Error{Code: http.StatusConflict, Message: "Job can no longer be canceled", Err: err}
+		}
+		if errors.Is(err, ErrNotFound) {
+			return &web.Error{Code: http.StatusNotFound, Message: "Job not found", Err: err}
+		}
+		h.recordCancelAudit(r, found, audit.ResultFailure)
+		return &web.Error{Code: http.StatusInternalServerError, Message: "Failed to cancel job", Err: err}
+	}
+
+	h.recordCancelAudit(r, canceled, audit.ResultSuccess)
+	return writeJSON(w, http.StatusOK, canceled)
+}
+
+func (h *Handler) ownedJobFromPath(r *http.Request) (*Job, *web.Error) {
+	owner := h.resolveOwner(r)
+	if owner == "" {
+		return nil, &web.Error{Code: http.StatusUnauthorized, Message: "Unauthorized"}
+	}
+
+	id := strings.TrimSpace(strings.TrimPrefix(r.URL.Path, "/api/jobs/"))
+	if id == "" || strings.Contains(id, "/") {
+		return nil, &web.Error{Code: http.StatusBadRequest, Message: "Invalid job id"}
+	}
+
+	found, err := h.manager.GetForOwner(r.Context(), id, owner)
+	if err != nil {
+		// 다른 사용자의 작업은 존재 여부도 드러내지 않습니다.
+		if errors.Is(err, ErrNotFound) || errors.Is(err, ErrForbidden) {
+			return nil, &web.Error{Code: http.StatusNotFound, Message: "Job not found", Err: err}
+		}
+		return nil, &web.Error{Code: http.StatusInternalServerError, Message: "Failed to get job", Err: err}
+	}
+	return found, nil
+}
+
+func (h *Handler) resolveOwner(r *http.Request) string {
+	if h.ownerResolver == nil {
+		return ""
+	}
+	return strings.TrimSpace(h.ownerResolver(r))
+}
+
+func (h *Handler) recordCancelAudit(r *http.Request, item *Job, result audit.Result) {
+	if h.auditRecorder == nil {
+		return
+	}
+	h.auditRecorder.RecordBestEffort(audit.Event{
+		Actor:     h.resolveOwner(r),
+		Action:    "job.cancel",
+		Result:    result,
+		Target:    item.ID,
+		RequestID: strings.TrimSpace(r.Header.Get("X-Request-Id")),
+		SpaceID:   item.SpaceID,
+		Metadata: map[string]any{
+			"jobId":   item.ID,
+			"jobType": item.Type,
+			"status":  string(item.Status),
+		},
+	})
+}
+
+func parseListFilter(r *http.Request) (ListFilter, error) {
+	query := r.URL.Query()
+	filter := ListFilter{
+		Type:  strings.TrimSpace(query.Get("type")),
+		Limit: defaultListLimit,
+	}
+
+	if statusRaw := strings.TrimSpace(query.Get("status")); statusRaw != "" {
+		for _, part := range strings.Split(statusRaw, ",") {
+			status := Status(strings.TrimSpace(part))
+			if !status.IsValid() {
+				return ListFilter{}, fmt.Errorf("invalid status: %s", part)
+			}
+			filter.Statuses = append(filter.Statuses, status)
+		}
+	}
+
+	if spaceRaw := strings.TrimSpace(query.Get("spaceId")); spaceRaw != "" {
+		spaceID, err := strconv.ParseInt(spaceRaw, 10, 64)
+		if err != nil || spaceID <= 0 {
+			return ListFilter{}, fmt.Errorf("spaceId must be a positive integer")
+		}
+		filter.SpaceID = &spaceID
+	}
+
+	if limitRaw := strings.TrimSpace(query.Get("limit")); limitRaw != "" {
+		limit, err := strconv.Atoi(limitRaw)
+		if err != nil || limit <= 0 {
+			return ListFilter{}, fmt.Errorf("limit must be a positive integer")
+		}
+		if limit > maxListLimit {
+			limit = maxListLimit
+		}
+		filter.Limit = limit
+	}
+
+	return filter, nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, payload any) *web.Error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(payload); err != nil {
+		return &web.Error{Code: http.StatusInternalServerError, Message: "Failed to encode response", Err: err}
+	}
+	return nil
+}
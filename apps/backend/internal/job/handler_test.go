@@ -0,0 +1,98 @@
+package job_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"taeu.kr/cohesion/internal/job"
+)
+
+func newJobHandlerForTest(t *testing.T) (*job.Manager, *http.ServeMux) {
+	t.Helper()
+
+	manager := job.NewManager(job.NewMemoryStore())
+	t.Cleanup(manager.Stop)
+	manager.Register("test.wait", job.TypeConfig{
+		Run: func(ctx context.Context, run *job.Run) error {
+			<-ctx.Done()
+			return ctx.Err()
+		},
+	})
+
+	handler := job.NewHandler(manager)
+	handler.SetOwnerResolver(func(r *http.Request) string {
+		return r.Header.Get("X-Test-User")
+	})
+	mux := http.NewServeMux()
+	handler.RegisterRoutes(mux)
+	return manager, mux
+}
+
+func serveJobRequest(mux *http.ServeMux, method string, target string, user string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, nil)
+	if user != "" {
+		req.Header.Set("X-Test-User", user)
+	}
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestJobHandler_ScopesJobsToOwner(t *testing.T) {
+	manager, mux := newJobHandlerForTest(t)
+	ctx := context.Background()
+
+	spaceID := int64(3)
+	aliceJob, err := manager.Enqueue(ctx, job.EnqueueRequest{Type: "test.wait", Owner: "alice", SpaceID: &spaceID})
+	if err != nil {
+		t.Fatalf("enqueue alice job: %v", err)
+	}
+	if _, err := manager.Enqueue(ctx, job.EnqueueRequest{Type: "test.wait", Owner: "bob"}); err != nil {
+		t.Fatalf("enqueue bob job: %v", err)
+	}
+
+	rec := serveJobRequest(mux, http.MethodGet, "/api/jobs?spaceId=3", "alice")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var listed struct {
+		Items []*job.Job `json:"items"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&listed); err != nil {
+		t.Fatalf("decode list: %v", err)
+	}
+	if len(listed.Items) != 1 || listed.Items[0].ID != aliceJob.ID {
+		t.Fatalf("expected only alice's job, got %+v", listed.Items)
+	}
+
+	if rec := serveJobRequest(mux, http.MethodGet, "/api/jobs/"+aliceJob.ID, "bob"); rec.Code != http.StatusNotFound {
+		t.Fatalf("expected other owner lookup to return 404, got %d", rec.Code)
+	}
+	if rec := serveJobRequest(mux, http.MethodDelete, "/api/jobs/"+aliceJob.ID, "bob"); rec.Code != http.StatusNotFound {
+		t.Fatalf("expected other owner cancel to return 404, got %d", rec.Code)
+	}
+	if rec := serveJobRequest(mux, http.MethodGet, "/api/jobs", ""); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("expected anonymous list to return 401, got %d", rec.Code)
+	}
+	if rec := serveJobRequest(mux, http.MethodGet, "/api/jobs?status=bogus", "alice"); rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected invalid status filter to return 400, got %d", rec.Code)
+	}
+
+	rec = serveJobRequest(mux, http.MethodDelete, "/api/jobs/"+aliceJob.ID, "alice")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected cancel to succeed, got %d: %s", rec.Code, rec.Body.String())
+	}
+	waitForStatus(t, manager, aliceJob.ID, job.StatusCanceled)
+
+	rec = serveJobRequest(mux, http.MethodGet, "/api/jobs/"+aliceJob.ID, "alice")
+	var fetched job.Job
+	if err := json.NewDecoder(rec.Body).Decode(&fetched); err != nil {
+		t.Fatalf("decode job: %v", err)
+	}
+	if fetched.Status != job.StatusCanceled {
+		t.Fatalf("expected canceled job, got %+v", fetched)
+	}
+}
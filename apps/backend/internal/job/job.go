@@ -0,0 +1,220 @@
+package job
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// Status는 작업의 진행 상태입니다.
+type Status string
+
+const (
+	StatusQueued    Status = "queued"
+	StatusRunning   Status = "running"
+	StatusCompleted Status = "completed"
+	StatusFailed    Status = "failed"
+	StatusCanceled  Status = "canceled"
+	// StatusExpired는 종료 후 보존 기간이 지나 산출물이 정리된 상태입니다.
+	StatusExpired Status = "expired"
+)
+
+var (
+	ErrNotFound      = errors.New("job not found")
+	ErrForbidden     = errors.New("job access denied")
+	ErrNotCancelable = errors.New("job can no longer be canceled")
+	ErrUnknownType   = errors.New("unknown job type")
+)
+
+func (s Status) IsValid() bool {
+	switch s {
+	case StatusQueued, StatusRunning, StatusCompleted, StatusFailed, StatusCanceled, StatusExpired:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsTerminal은 더 이상 실행되지 않는 상태인지 확인합니다.
+func (s Status) IsTerminal() bool {
+	switch s {
+	case StatusCompleted, StatusFailed, StatusCanceled, StatusExpired:
+		return true
+	default:
+		return false
+	}
+}
+
+type Progress struct {
+	TotalItems     int   `json:"totalItems"`
+	ProcessedItems int   `json:"processedItems"`
+	TotalBytes     int64 `json:"totalBytes"`
+	ProcessedBytes int64 `json:"processedBytes"`
+}
+
+// Job은 SQLite에 저장되는 백그라운드 작업 한 건입니다.
+// Payload는 작업 유형별 입력, Result는 유형별 출력이며 모두 JSON으로 저장합니다.
+type Job struct {
+	ID            string          `json:"id"`
+	Type          string          `json:"type"`
+	Owner         string          `json:"owner"`
+	SpaceID       *int64          `json:"spaceId,omitempty"`
+	RequestID     string          `json:"-"`
+	Status        Status          `json:"status"`
+	Payload       json.RawMessage `json:"-"`
+	Result        json.RawMessage `json:"result,omitempty"`
+	Progress      Progress        `json:"progress"`
+	FailureReason string          `json:"failureReason,omitempty"`
+	Attempts      int             `json:"attempts"`
+	MaxAttempts   int             `json:"maxAttempts"`
+	ArtifactPath  string          `json:"-"`
+	ArtifactSize  int64           `json:"artifactSize,omitempty"`
+	RunAfter      time.Time       `json:"-"`
+	CreatedAt     time.Time       `json:"createdAt"`
+	UpdatedAt     time.Time       `json:"updatedAt"`
+	StartedAt     *time.Time      `json:"startedAt,omitempty"`
+	FinishedAt    *time.Time      `json:"finishedAt,omitempty"`
+	ExpiresAt     *time.Time      `json:"expiresAt,omitempty"`
+}
+
+// Clone은 호출자가 자유롭게 수정할 수 있는 복사본을 반환합니다.
+func (j *Job) Clone() *Job {
+	if j == nil {
+		return nil
+	}
+	clone := *j
+	clone.Payload = append(json.RawMessage(nil), j.Payload...)
+	clone.Result = append(json.RawMessage(nil), j.Result...)
+	if j.SpaceID != nil {
+		spaceID := *j.SpaceID
+		clone.SpaceID = &spaceID
+	}
+	clone.StartedAt = cloneTime(j.StartedAt)
+	clone.FinishedAt = cloneTime(j.FinishedAt)
+	clone.ExpiresAt = cloneTime(j.ExpiresAt)
+	return &clone
+}
+
+// DecodePayload는 작업 입력을 v로 디코딩합니다.
+func (j *Job) DecodePayload(v any) error {
+	if len(j.Payload) == 0 {
+		return nil
+	}
+	return json.Unmarshal(j.Payload, v)
+}
+
+// DecodeResult는 작업 출력을 v로 디코딩합니다.
+func (j *Job) DecodeResult(v any) error {
+	if len(j.Result) == 0 {
+		return nil
+	}
+	return json.Unmarshal(j.Result, v)
+}
+
+func cloneTime(value *time.Time) *time.Time {
+	if value == nil {
+		return nil
+	}
+	copied := *value
+	return &copied
+}
+
+// EnqueueRequest는 새 작업 등록 요청입니다.
+type EnqueueRequest struct {
+	Type      string
+	Owner     string
+	SpaceID   *int64
+	RequestID string
+	Payload   any
+}
+
+// ListFilter는 작업 목록 조회 조건입니다. 비어 있는 필드는 조건에서 제외합니다.
+type ListFilter struct {
+	Owner    string
+	Type     string
+	SpaceID  *int64
+	Statuses []Status
+	Limit    int
+	// OldestFirst가 false이면 최근 작업부터 반환합니다.
+	OldestFirst bool
+}
+
+// Storer는 작업 기록 저장소입니다. Get/Update/Delete는 없는 작업이면 ErrNotFound를 감싼 오류를 반환합니다.
+type Storer interface {
+	Create(ctx context.Context, job *Job) error
+	Get(ctx context.Context, id string) (*Job, error)
+	Update(ctx context.Context, job *Job) error
+	List(ctx context.Context, filter ListFilter) ([]*Job, error)
+	Delete(ctx context.Context, id string) error
+}
+
+// RunFunc는 작업 유형별 실행 함수입니다. ctx는 취소나 서버 종료 시 취소됩니다.
+type RunFunc func(ctx context.Context, run *Run) error
+
+// TypeConfig는 작업 유형별 실행 정책입니다.
+type TypeConfig struct {
+	// Concurrency는 이 유형의 동시 실행 수입니다. 기본값은 1입니다.
+	Concurrency int
+	// MaxAttempts는 재시도를 포함한 최대 실행 횟수입니다. 기본값은 1입니다.
+	MaxAttempts int
+	// RetryDelay는 재시도 전 대기 시간이며 시도 횟수만큼 늘어납니다.
+	RetryDelay time.Duration
+	// ArtifactTTL은 종료 후 산출물을 보존하는 시간입니다. 지나면 expired가 됩니다.
+	ArtifactTTL time.Duration
+	// Retention은 expired 이후 작업 기록을 보존하는 시간입니다.
+	Retention time.Duration
+	Run       RunFunc
+	// OnFinish는 실행이 completed/failed/canceled로 끝난 뒤 호출됩니다.
+	// 서버 재시작 시 시도 횟수를 다 써서 실패로 끝난 작업에도 호출됩니다.
+	// 실행 전에 취소된 작업에는 호출되지 않습니다.
+	OnFinish func(job *Job)
+	// OnExpire는 보존 기간이 지나 expired로 바뀐 뒤 호출됩니다.
+	OnExpire func(job *Job)
+}
+
+const (
+	defaultRetryDelay  = 5 * time.Second
+	defaultArtifactTTL = 10 * time.Minute
+	defaultRetention   = 24 * time.Hour
+)
+
+func (cfg TypeConfig) withDefaults() TypeConfig {
+	if cfg.Concurrency < 1 {
+		cfg.Concurrency = 1
+	}
+	if cfg.MaxAttempts < 1 {
+		cfg.MaxAttempts = 1
+	}
+	if cfg.RetryDelay <= 0 {
+		cfg.RetryDelay = defaultRetryDelay
+	}
+	if cfg.ArtifactTTL <= 0 {
+		cfg.ArtifactTTL = defaultArtifactTTL
+	}
+	if cfg.Retention <= 0 {
+		cfg.Retention = defaultRetention
+	}
+	return cfg
+}
+
+// permanentError는 재시도해도 성공할 수 없는 실패입니다.
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent는 err를 재시도하지 않을 실패로 표시합니다.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+func isPermanent(err error) bool {
+	var target *permanentError
+	return errors.As(err, &target)
+}
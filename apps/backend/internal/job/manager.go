@@ -0,0 +1,878 @@
+package job
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"taeu.kr/cohesion/internal/platform/logging"
+)
+
+const (
+	maintenanceInterval   = 30 * time.Second
+	progressFlushInterval = time.Second
+	restartFailureReason  = "interrupted by server restart"
+)
+
+type registeredType struct {
+	config  TypeConfig
+	running int
+}
+
+type runningJob struct {
+	job      *Job
+	cancel   context.CancelFunc
+	canceled bool
+	shutdown bool
+	// committed는 PreventCancel 이후 되돌릴 수 없는 단계에 들어섰음을 나타냅니다.
+	committed bool
+	lastFlush time.Time
+	// version은 잠금 안에서 상태를 바꿀 때마다 늘어나고, persisted는 저장까지 마친 version입니다.
+	// 저장은 잠금 밖에서 하므로 persistMu로 순서를 맞추고 이미 저장한 것보다 오래된 상태는 버립니다.
+	version   uint64
+	persistMu sync.Mutex
+	persisted uint64
+}
+
+// Manager는 작업 큐를 관리합니다. 작업은 Store에 저장되므로 서버를 다시 시작해도
+// 대기 중인 작업이 이어서 실행되고, 실행 중이던 작업은 다시 대기열로 돌아갑니다.
+type Manager struct {
+	store Storer
+	now   func() time.Time
+
+	mu      sync.Mutex
+	types   map[string]*registeredType
+	running map[string]*runningJob
+	closed  bool
+	wg      sync.WaitGroup
+	// generation은 저장된 작업 상태를 바꿀 때마다 늘어납니다. dispatch는 잠금 밖에서 읽은 대기 목록이
+	// 그 사이 낡았는지 이 값으로 확인합니다.
+	generation uint64
+	// wakeTimer는 RunAfter가 남은 대기 작업을 위해 다음 dispatch를 예약합니다.
+	wakeTimer *time.Timer
+	// settling은 잠금 밖에서 저장하거나 산출물을 지우는 중인 작업입니다. 값은 그동안 조회에 보여 줄 상태이며
+	// 아직 저장소에서 읽기 전이면 nil입니다. 이 작업은 다시 시작하거나 다른 곳에서 바꾸지 않습니다.
+	settling map[string]*Job
+	// settled는 settling에서 작업이 빠질 때 기다리던 쪽을 깨웁니다.
+	settled *sync.Cond
+
+	startOnce sync.Once
+	stopOnce  sync.Once
+	stopCh    chan struct{}
+	loopDone  chan struct{}
+}
+
+func NewManager(store Storer) *Manager {
+	m := &Manager{
+		store:    store,
+		now:      func() time.Time { return time.Now().UTC() },
+		types:    make(map[string]*registeredType),
+		running:  make(map[string]*runningJob),
+		settling: make(map[string]*Job),
+		stopCh:   make(chan struct{}),
+		loopDone: make(chan struct{}),
+	}
+	m.settled = sync.NewCond(&m.mu)
+	return m
+}
+
+// Register는 작업 유형과 실행 정책을 등록합니다. 같은 유형을 다시 등록하면 정책을 교체합니다.
+func (m *Manager) Register(jobType string, cfg TypeConfig) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	cfg = cfg.withDefaults()
+	if existing, ok := m.types[jobType]; ok {
+		existing.config = cfg
+		return
+	}
+	m.types[jobType] = &registeredType{config: cfg}
+}
+
+// Start는 이전 프로세스에서 실행 중이던 작업을 대기열로 되돌리고, 주기적인 정리/재시도 루프를 시작합니다.
+func (m *Manager) Start(ctx context.Context) error {
+	var startErr error
+	m.startOnce.Do(func() {
+		if err := m.recoverInterrupted(ctx); err != nil {
+			startErr = err
+		}
+		go m.maintenanceLoop()
+		m.dispatch()
+	})
+	return startErr
+}
+
+// Stop은 새 작업 실행을 멈추고 실행 중인 작업을 취소한 뒤 끝날 때까지 기다립니다.
+// 중단된 작업은 다음 Start에서 다시 실행되도록 대기열로 돌아갑니다.
+func (m *Manager) Stop() {
+	m.stopOnce.Do(func() {
+		close(m.stopCh)
+
+		m.mu.Lock()
+		m.closed = true
+		if m.wakeTimer != nil {
+			m.wakeTimer.Stop()
+		}
+		for _, item := range m.running {
+			item.shutdown = true
+			item.cancel()
+		}
+		m.mu.Unlock()
+
+		// 시작되지 않았다면 루프 종료를 기다리지 않습니다.
+		m.startOnce.Do(func() {
+			close(m.loopDone)
+		})
+	})
+	<-m.loopDone
+	m.wg.Wait()
+}
+
+// Enqueue는 작업을 저장하고 실행 가능한 슬롯이 있으면 바로 시작합니다.
+func (m *Manager) Enqueue(ctx context.Context, req EnqueueRequest) (*Job, error) {
+	m.mu.Lock()
+	registered, ok := m.types[req.Type]
+	m.mu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownType, req.Type)
+	}
+
+	payload, err := json.Marshal(req.Payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode job payload: %w", err)
+	}
+	jobID, err := newJobID()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate job id: %w", err)
+	}
+
+	now := m.now()
+	created := &Job{
+		ID:          jobID,
+		Type:        req.Type,
+		Owner:       req.Owner,
+		SpaceID:     req.SpaceID,
+		RequestID:   strings.TrimSpace(req.RequestID),
+		Status:      StatusQueued,
+		Payload:     payload,
+		MaxAttempts: registered.config.MaxAttempts,
+		RunAfter:    now,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if err := m.store.Create(ctx, created); err != nil {
+		return nil, err
+	}
+
+	m.dispatch()
+	return created.Clone(), nil
+}
+
+// Get은 작업을 조회합니다. 실행 중이면 저장 전의 최신 진행률을 반환합니다.
+func (m *Manager) Get(ctx context.Context, id string) (*Job, error) {
+	m.mu.Lock()
+	if item, ok := m.running[id]; ok {
+		snapshot := item.job.Clone()
+		m.mu.Unlock()
+		return snapshot, nil
+	}
+	if pending := m.settling[id]; pending != nil {
+		snapshot := pending.Clone()
+		m.mu.Unlock()
+		return snapshot, nil
+	}
+	m.mu.Unlock()
+
+	found, err := m.store.Get(ctx, id)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return found, nil
+}
+
+// GetForOwner는 owner 본인의 작업만 반환합니다.
+func (m *Manager) GetForOwner(ctx context.Context, id string, owner string) (*Job, error) {
+	found, err := m.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if found.Owner != owner {
+		return nil, ErrForbidden
+	}
+	return found, nil
+}
+
+// List는 조건에 맞는 작업을 반환합니다. 실행 중인 작업은 최신 진행률로 덮어씁니다.
+func (m *Manager) List(ctx context.Context, filter ListFilter) ([]*Job, error) {
+	items, err := m.store.List(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i, item := range items {
+		if live, ok := m.running[item.ID]; ok {
+			items[i] = live.job.Clone()
+		} else if pending := m.settling[item.ID]; pending != nil {
+			items[i] = pending.Clone()
+		}
+	}
+	return items, nil
+}
+
+// Cancel은 작업을 취소합니다. 완료된 작업을 취소하면 산출물을 지웁니다.
+// 실행 중인 작업의 산출물은 실행 함수가 끝난 뒤 finish가 지웁니다.
+func (m *Manager) Cancel(ctx context.Context, id string) (*Job, error) {
+	m.mu.Lock()
+	m.waitSettledLocked(id)
+	now := m.now()
+	if item, ok := m.running[id]; ok {
+		defer m.mu.Unlock()
+		if item.committed {
+			return nil, ErrNotCancelable
+		}
+		// 실행 함수가 끝나기 전에도 조회 결과는 바로 취소 상태가 되도록 먼저 반영합니다.
+		item.canceled = true
+		item.cancel()
+		item.job.Status = StatusCanceled
+		item.job.FailureReason = ""
+		m.markFinishedLocked(item.job, now)
+		m.generation++
+		return item.job.Clone(), nil
+	}
+	m.settling[id] = nil
+	m.mu.Unlock()
+	defer m.settle(id)
+
+	found, err := m.store.Get(ctx, id)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	switch found.Status {
+	case StatusCanceled:
+		return found, nil
+	case StatusFailed, StatusExpired:
+		return nil, ErrNotCancelable
+	}
+
+	artifactPath := clearArtifact(found)
+	found.Status = StatusCanceled
+	found.FailureReason = ""
+	m.mu.Lock()
+	m.markFinishedLocked(found, now)
+	m.settling[id] = found.Clone()
+	m.mu.Unlock()
+
+	removeArtifactFile(artifactPath)
+	if err := m.store.Update(ctx, found); err != nil {
+		return nil, err
+	}
+	return found, nil
+}
+
+// CleanupExpired는 보존 기간이 지난 작업의 산출물을 지워 expired로 바꾸고,
+// expired 이후 Retention이 지난 작업 기록을 삭제합니다. expired로 바뀐 작업을 반환합니다.
+func (m *Manager) CleanupExpired(ctx context.Context) ([]*Job, error) {
+	now := m.now()
+	items, err := m.store.List(ctx, ListFilter{
+		Statuses:    []Status{StatusCompleted, StatusFailed, StatusCanceled, StatusExpired},
+		OldestFirst: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	expired := make([]*Job, 0)
+	hooks := make([]func(*Job), 0)
+	var cleanupErr error
+	for _, item := range items {
+		if item.ExpiresAt == nil || !now.After(*item.ExpiresAt) {
+			continue
+		}
+
+		m.mu.Lock()
+		_, running := m.running[item.ID]
+		_, busy := m.settling[item.ID]
+		if running || busy {
+			m.mu.Unlock()
+			continue
+		}
+		m.settling[item.ID] = nil
+		retention := m.retentionLocked(item.Type)
+		var hook func(*Job)
+		if registered, ok := m.types[item.Type]; ok {
+			hook = registered.config.OnExpire
+		}
+		m.mu.Unlock()
+
+		changed, err := m.expire(ctx, item.ID, now, retention)
+		m.settle(item.ID)
+		if err != nil {
+			cleanupErr = err
+			break
+		}
+		if changed != nil {
+			expired = append(expired, changed)
+			hooks = append(hooks, hook)
+		}
+	}
+
+	for i, item := range expired {
+		if hooks[i] != nil {
+			hooks[i](item.Clone())
+		}
+	}
+	return expired, cleanupErr
+}
+
+// expire는 작업 하나를 만료 처리합니다. 목록을 읽은 뒤 바뀌었을 수 있으므로 다시 읽어 확인하고,
+// expired로 바꿨으면 그 작업을 반환합니다.
+func (m *Manager) expire(ctx context.Context, id string, now time.Time, retention time.Duration) (*Job, error) {
+	item, err := m.store.Get(ctx, id)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if item.ExpiresAt == nil || !now.After(*item.ExpiresAt) {
+		return nil, nil
+	}
+
+	switch item.Status {
+	case StatusExpired:
+		if now.After(item.ExpiresAt.Add(retention)) {
+			if err := m.store.Delete(ctx, id); err != nil && !errors.Is(err, ErrNotFound) {
+				return nil, err
+			}
+		}
+		return nil, nil
+	case StatusCompleted, StatusFailed, StatusCanceled:
+	default:
+		return nil, nil
+	}
+
+	removeArtifact(item)
+	item.Status = StatusExpired
+	item.UpdatedAt = now
+	if err := m.store.Update(ctx, item); err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+// DiscardSpaceJobs는 Space에 속한 작업을 모두 취소하고 산출물과 기록을 지웁니다.
+// keepTypes에 든 유형의 작업은 남겨 둡니다. Space 삭제 작업이 자기 기록을 지우지 않도록 할 때 씁니다.
+func (m *Manager) DiscardSpaceJobs(ctx context.Context, spaceID int64, keepTypes ...string) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	items, err := m.store.List(ctx, ListFilter{SpaceID: &spaceID})
+	if err != nil {
+		return 0, err
+	}
+	m.generation++
+	discarded := 0
+	for _, item := range items {
+		if slices.Contains(keepTypes, item.Type) {
+			continue
+		}
+		m.waitSettledLocked(item.ID)
+		if live, ok := m.running[item.ID]; ok {
+			// 실행 중인 작업의 산출물은 실행 함수가 끝난 뒤 finish가 지웁니다.
+			live.canceled = true
+			live.cancel()
+		} else {
+			removeArtifact(item)
+		}
+		if err := m.store.Delete(ctx, item.ID); err != nil {
+			return 0, err
+		}
+		discarded++
+	}
+	return discarded, nil
+}
+
+// recoverInterrupted는 이전 프로세스에서 실행 중이던 작업을 대기열로 되돌립니다.
+// 시도 횟수를 다 쓴 작업은 실패로 끝내고, 남긴 산출물을 정리할 수 있도록 OnFinish를 호출합니다.
+func (m *Manager) recoverInterrupted(ctx context.Context) error {
+	m.mu.Lock()
+	items, err := m.store.List(ctx, ListFilter{Statuses: []Status{StatusRunning}, OldestFirst: true})
+	if err != nil {
+		m.mu.Unlock()
+		return err
+	}
+	now := m.now()
+	m.generation++
+	var (
+		failed []*Job
+		hooks  []func(*Job)
+	)
+	for _, item := range items {
+		if _, live := m.running[item.ID]; live {
+			continue
+		}
+		removeArtifact(item)
+		if item.Attempts < item.MaxAttempts {
+			item.Status = StatusQueued
+			item.RunAfter = now
+			item.UpdatedAt = now
+		} else {
+			item.Status = StatusFailed
+			item.FailureReason = restartFailureReason
+			m.markFinishedLocked(item, now)
+		}
+		if err := m.store.Update(ctx, item); err != nil {
+			m.mu.Unlock()
+			return err
+		}
+		if item.Status == StatusFailed {
+			if registered, ok := m.types[item.Type]; ok && registered.config.OnFinish != nil {
+				failed = append(failed, item.Clone())
+				hooks = append(hooks, registered.config.OnFinish)
+			}
+		}
+	}
+	m.mu.Unlock()
+
+	for i, item := range failed {
+		hooks[i](item)
+	}
+	return nil
+}
+
+func (m *Manager) maintenanceLoop() {
+	defer close(m.loopDone)
+
+	ticker := time.NewTicker(maintenanceInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-m.stopCh:
+			return
+		case <-ticker.C:
+			if _, err := m.CleanupExpired(context.Background()); err != nil {
+				logging.Event(log.Warn(), logging.ComponentStorage, "warn.job.cleanup_failed").
+					Err(err).
+					Msg("failed to clean up expired jobs")
+			}
+			m.dispatch()
+		}
+	}
+}
+
+// dispatch는 유형별 동시 실행 한도 안에서 실행 시각이 된 대기 작업을 시작합니다.
+// 대기 목록은 잠금 밖에서 읽고, 읽는 사이 다른 곳에서 작업 상태를 바꿨으면 다시 읽습니다.
+func (m *Manager) dispatch() {
+	for {
+		m.mu.Lock()
+		if m.closed {
+			m.mu.Unlock()
+			return
+		}
+		generation := m.generation
+		jobTypes := make([]string, 0, len(m.types))
+		for jobType, registered := range m.types {
+			if registered.config.Concurrency > registered.running {
+				jobTypes = append(jobTypes, jobType)
+			}
+		}
+		m.mu.Unlock()
+
+		queued := make(map[string][]*Job, len(jobTypes))
+		for _, jobType := range jobTypes {
+			items, err := m.store.List(context.Background(), ListFilter{Type: jobType, Statuses: []Status{StatusQueued}, OldestFirst: true})
+			if err != nil {
+				logging.Event(log.Warn(), logging.ComponentStorage, "warn.job.dispatch_failed").
+					Str("job_type", jobType).
+					Err(err).
+					Msg("failed to load queued jobs")
+				continue
+			}
+			queued[jobType] = items
+		}
+
+		m.mu.Lock()
+		if m.generation != generation {
+			m.mu.Unlock()
+			continue
+		}
+		m.startQueuedLocked(queued)
+		m.mu.Unlock()
+		return
+	}
+}
+
+// startQueuedLocked는 dispatch가 읽은 대기 목록에서 빈 슬롯만큼 작업을 시작하고,
+// 아직 실행 시각이 안 된 작업이 있으면 가장 이른 시각에 다시 dispatch하도록 wakeTimer를 맞춥니다.
+func (m *Manager) startQueuedLocked(queued map[string][]*Job) {
+	if m.closed {
+		return
+	}
+	now := m.now()
+	var nextWake time.Time
+	for jobType, items := range queued {
+		registered, ok := m.types[jobType]
+		if !ok {
+			continue
+		}
+		free := registered.config.Concurrency - registered.running
+		for _, item := range items {
+			if free <= 0 {
+				break
+			}
+			if _, live := m.running[item.ID]; live {
+				continue
+			}
+			if _, busy := m.settling[item.ID]; busy {
+				continue
+			}
+			if item.RunAfter.After(now) {
+				if nextWake.IsZero() || item.RunAfter.Before(nextWake) {
+					nextWake = item.RunAfter
+				}
+				continue
+			}
+			if err := m.startLocked(item, registered, now); err != nil {
+				logging.Event(log.Warn(), logging.ComponentStorage, "warn.job.start_failed").
+					Str("job_id", item.ID).
+					Str("job_type", jobType).
+					Err(err).
+					Msg("failed to start job")
+				continue
+			}
+			free--
+		}
+	}
+
+	if nextWake.IsZero() {
+		return
+	}
+	if m.wakeTimer == nil {
+		m.wakeTimer = time.AfterFunc(nextWake.Sub(now), m.dispatch)
+		return
+	}
+	m.wakeTimer.Reset(nextWake.Sub(now))
+}
+
+func (m *Manager) startLocked(item *Job, registered *registeredType, now time.Time) error {
+	item.Status = StatusRunning
+	item.Attempts++
+	item.FailureReason = ""
+	item.StartedAt = &now
+	item.UpdatedAt = now
+	if err := m.store.Update(context.Background(), item); err != nil {
+		return err
+	}
+	m.generation++
+
+	runCtx, cancel := context.WithCancel(context.Background())
+	live := &runningJob{job: item.Clone(), cancel: cancel, lastFlush: now}
+	m.running[item.ID] = live
+	registered.running++
+
+	run := &Run{manager: m, jobID: item.ID}
+	runFn := registered.config.Run
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		defer cancel()
+		err := runJob(runCtx, runFn, run)
+		m.finish(item.ID, err)
+	}()
+	return nil
+}
+
+// runJob은 실행 함수의 panic을 실패로 바꿉니다.
+func runJob(ctx context.Context, runFn RunFunc, run *Run) (err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = Permanent(fmt.Errorf("job panicked: %v", recovered))
+		}
+	}()
+	if runFn == nil {
+		return Permanent(errors.New("job type has no runner"))
+	}
+	return runFn(ctx, run)
+}
+
+// finish는 실행 함수가 돌아온 뒤 결과를 반영합니다. 상태는 잠금 안에서 정하고,
+// 산출물 삭제와 저장은 잠금 밖에서 합니다.
+func (m *Manager) finish(id string, runErr error) {
+	m.mu.Lock()
+	live, ok := m.running[id]
+	if !ok {
+		m.mu.Unlock()
+		return
+	}
+	delete(m.running, id)
+	m.generation++
+	registered := m.types[live.job.Type]
+	if registered != nil {
+		registered.running--
+	}
+
+	item := live.job
+	now := m.now()
+	item.UpdatedAt = now
+	var (
+		hook         func(*Job)
+		artifactPath string
+	)
+
+	switch {
+	case live.canceled:
+		artifactPath = clearArtifact(item)
+		item.Status = StatusCanceled
+		item.FailureReason = ""
+		m.markFinishedLocked(item, now)
+		if registered != nil {
+			hook = registered.config.OnFinish
+		}
+	case live.shutdown:
+		// 서버 종료로 중단된 작업은 시도 횟수를 되돌려 다음 시작 시 다시 실행합니다.
+		artifactPath = clearArtifact(item)
+		item.Status = StatusQueued
+		item.Attempts--
+		item.RunAfter = now
+	case runErr == nil:
+		item.Status = StatusCompleted
+		item.FailureReason = ""
+		m.markFinishedLocked(item, now)
+		if registered != nil {
+			hook = registered.config.OnFinish
+		}
+	case !isPermanent(runErr) && item.Attempts < item.MaxAttempts && registered != nil:
+		artifactPath = clearArtifact(item)
+		item.Status = StatusQueued
+		item.FailureReason = runErr.Error()
+		item.Progress.ProcessedItems = 0
+		item.Progress.ProcessedBytes = 0
+		item.RunAfter = now.Add(registered.config.RetryDelay * time.Duration(item.Attempts))
+	default:
+		artifactPath = clearArtifact(item)
+		item.Status = StatusFailed
+		item.FailureReason = runErr.Error()
+		m.markFinishedLocked(item, now)
+		if registered != nil {
+			hook = registered.config.OnFinish
+		}
+	}
+
+	live.version++
+	version := live.version
+	snapshot := item.Clone()
+	m.settling[id] = snapshot
+	m.mu.Unlock()
+
+	removeArtifactFile(artifactPath)
+	if err := m.persist(live, snapshot, version); err != nil {
+		logging.Event(log.Warn(), logging.ComponentStorage, "warn.job.persist_failed").
+			Str("job_id", item.ID).
+			Str("job_type", item.Type).
+			Err(err).
+			Msg("failed to persist job result")
+	}
+	m.settle(id)
+
+	if hook != nil {
+		hook(snapshot.Clone())
+	}
+	m.dispatch()
+}
+
+// persist는 실행 중인 작업의 상태를 잠금 밖에서 저장합니다. 이미 더 새로운 상태를 저장했으면 건너뜁니다.
+func (m *Manager) persist(live *runningJob, snapshot *Job, version uint64) error {
+	live.persistMu.Lock()
+	defer live.persistMu.Unlock()
+	if version <= live.persisted {
+		return nil
+	}
+	live.persisted = version
+	return m.store.Update(context.Background(), snapshot)
+}
+
+// waitSettledLocked는 id 작업을 잠금 밖에서 정리하는 중이면 끝날 때까지 기다립니다.
+func (m *Manager) waitSettledLocked(id string) {
+	for {
+		if _, busy := m.settling[id]; !busy {
+			return
+		}
+		m.settled.Wait()
+	}
+}
+
+// settle은 잠금 밖 정리가 끝난 작업을 settling에서 빼고 기다리던 쪽을 깨웁니다.
+func (m *Manager) settle(id string) {
+	m.mu.Lock()
+	delete(m.settling, id)
+	m.generation++
+	m.settled.Broadcast()
+	m.mu.Unlock()
+}
+
+func (m *Manager) markFinishedLocked(item *Job, now time.Time) {
+	finishedAt := now
+	expiresAt := now.Add(m.artifactTTLLocked(item.Type))
+	item.FinishedAt = &finishedAt
+	item.ExpiresAt = &expiresAt
+	item.UpdatedAt = now
+}
+
+func (m *Manager) artifactTTLLocked(jobType string) time.Duration {
+	if registered, ok := m.types[jobType]; ok {
+		return registered.config.ArtifactTTL
+	}
+	return defaultArtifactTTL
+}
+
+func (m *Manager) retentionLocked(jobType string) time.Duration {
+	if registered, ok := m.types[jobType]; ok {
+		return registered.config.Retention
+	}
+	return defaultRetention
+}
+
+func removeArtifact(item *Job) {
+	removeArtifactFile(clearArtifact(item))
+}
+
+// clearArtifact는 작업에서 산출물 기록을 떼어 내고 지울 파일 경로를 반환합니다.
+func clearArtifact(item *Job) string {
+	artifactPath := item.ArtifactPath
+	item.ArtifactPath = ""
+	item.ArtifactSize = 0
+	return artifactPath
+}
+
+func removeArtifactFile(artifactPath string) {
+	if strings.TrimSpace(artifactPath) != "" {
+		_ = os.Remove(artifactPath)
+	}
+}
+
+func newJobID() (string, error) {
+	randomBytes := make([]byte, 16)
+	if _, err := rand.Read(randomBytes); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(randomBytes), nil
+}
+
+// Run은 실행 중인 작업 한 건에 대한 진행률/결과 보고 창구입니다.
+type Run struct {
+	manager *Manager
+	jobID   string
+}
+
+// Job은 현재 작업 상태의 복사본을 반환합니다.
+func (r *Run) Job() *Job {
+	r.manager.mu.Lock()
+	defer r.manager.mu.Unlock()
+	if live, ok := r.manager.running[r.jobID]; ok {
+		return live.job.Clone()
+	}
+	return nil
+}
+
+// SetTotals는 전체 작업량을 기록하고 처리량을 0으로 되돌립니다.
+func (r *Run) SetTotals(items int, bytes int64) {
+	r.update(true, func(item *Job) {
+		item.Progress = Progress{TotalItems: items, TotalBytes: bytes}
+	})
+}
+
+// Advance는 처리량을 늘립니다. 저장은 일정 간격으로만 합니다.
+func (r *Run) Advance(items int, bytes int64) {
+	r.update(false, func(item *Job) {
+		item.Progress.ProcessedItems += items
+		item.Progress.ProcessedBytes += bytes
+	})
+}
+
+// SetResult는 작업 출력을 JSON으로 기록합니다.
+func (r *Run) SetResult(v any) error {
+	encoded, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	r.update(true, func(item *Job) {
+		item.Result = encoded
+	})
+	return nil
+}
+
+// PreventCancel은 이후 Cancel이 이 작업을 취소하지 못하게 합니다. 되돌릴 수 없는 단계 직전에 호출합니다.
+// 이미 취소된 작업이면 false를 반환하며, 이때는 그 단계로 넘어가지 말아야 합니다.
+// 서버 종료로 인한 중단은 막지 않으므로 그 단계도 다시 실행될 수 있어야 합니다.
+func (r *Run) PreventCancel() bool {
+	m := r.manager
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	live, ok := m.running[r.jobID]
+	if !ok || live.canceled {
+		return false
+	}
+	live.committed = true
+	return true
+}
+
+// SetArtifact는 다운로드 등에 쓸 산출물 파일을 기록합니다. 만료/취소/실패 시 Manager가 지웁니다.
+func (r *Run) SetArtifact(path string, size int64) {
+	r.update(true, func(item *Job) {
+		item.ArtifactPath = path
+		item.ArtifactSize = size
+	})
+}
+
+func (r *Run) update(flush bool, apply func(item *Job)) {
+	m := r.manager
+	m.mu.Lock()
+	live, ok := m.running[r.jobID]
+	if !ok {
+		m.mu.Unlock()
+		return
+	}
+	apply(live.job)
+	now := m.now()
+	live.job.UpdatedAt = now
+	if !flush && now.Sub(live.lastFlush) < progressFlushInterval {
+		m.mu.Unlock()
+		return
+	}
+	live.lastFlush = now
+	if live.canceled {
+		m.mu.Unlock()
+		return
+	}
+	live.version++
+	version := live.version
+	snapshot := live.job.Clone()
+	m.mu.Unlock()
+
+	if err := m.persist(live, snapshot, version); err != nil {
+		logging.Event(log.Warn(), logging.ComponentStorage, "warn.job.persist_failed").
+			Str("job_id", snapshot.ID).
+			Str("job_type", snapshot.Type).
+			Err(err).
+			Msg("failed to persist job progress")
+	}
+}
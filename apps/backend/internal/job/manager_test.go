@@ -0,0 +1,620 @@
+package job_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	_ "github.com/ncruces/go-sqlite3/driver"
+	_ "github.com/ncruces/go-sqlite3/embed"
+	"taeu.kr/cohesion/internal/job"
+	jobstore "taeu.kr/cohesion/internal/job/store"
+	"taeu.kr/cohesion/internal/platform/database"
+)
+
+func setupJobStore(t *testing.T) *jobstore.Store {
+	t.Helper()
+
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	// :memory: DB는 연결마다 따로 생기므로 한 연결만 사용한다.
+	db.SetMaxOpenConns(1)
+	if err := database.Migrate(context.Background(), db); err != nil {
+		t.Fatalf("migrate db: %v", err)
+	}
+	return jobstore.NewStore(db)
+}
+
+func waitForStatus(t *testing.T, manager *job.Manager, id string, want job.Status) *job.Job {
+	t.Helper()
+
+	deadline := time.Now().Add(3 * time.Second)
+	for {
+		current, err := manager.Get(context.Background(), id)
+		if err != nil {
+			t.Fatalf("get job: %v", err)
+		}
+		if current.Status == want {
+			return current
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("job %s did not reach %s: %+v", id, want, current)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestManager_LimitsConcurrencyPerType(t *testing.T) {
+	manager := job.NewManager(job.NewMemoryStore())
+	defer manager.Stop()
+
+	release := make(chan struct{})
+	var running, peak int32
+	manager.Register("test.slow", job.TypeConfig{
+		Concurrency: 1,
+		Run: func(ctx context.Context, run *job.Run) error {
+			current := atomic.AddInt32(&running, 1)
+			for {
+				observed := atomic.LoadInt32(&peak)
+				if current <= observed || atomic.CompareAndSwapInt32(&peak, observed, current) {
+					break
+				}
+			}
+			defer atomic.AddInt32(&running, -1)
+			<-release
+			return nil
+		},
+	})
+
+	ids := make([]string, 0, 3)
+	for i := 0; i < 3; i++ {
+		created, err := manager.Enqueue(context.Background(), job.EnqueueRequest{Type: "test.slow", Owner: "alice"})
+		if err != nil {
+			t.Fatalf("enqueue: %v", err)
+		}
+		ids = append(ids, created.ID)
+	}
+
+	waitForStatus(t, manager, ids[0], job.StatusRunning)
+	if current, _ := manager.Get(context.Background(), ids[1]); current.Status != job.StatusQueued {
+		t.Fatalf("expected second job to wait for a slot, got %s", current.Status)
+	}
+
+	close(release)
+	for _, id := range ids {
+		waitForStatus(t, manager, id, job.StatusCompleted)
+	}
+	if peak != 1 {
+		t.Fatalf("expected at most 1 concurrent run, got %d", peak)
+	}
+}
+
+func TestManager_RetriesTransientFailuresButNotPermanentOnes(t *testing.T) {
+	manager := job.NewManager(job.NewMemoryStore())
+	defer manager.Stop()
+
+	var attempts int32
+	manager.Register("test.flaky", job.TypeConfig{
+		MaxAttempts: 3,
+		RetryDelay:  10 * time.Millisecond,
+		Run: func(ctx context.Context, run *job.Run) error {
+			if atomic.AddInt32(&attempts, 1) < 2 {
+				return errors.New("temporary failure")
+			}
+			return run.SetResult(map[string]int{"copied": 2})
+		},
+	})
+	manager.Register("test.broken", job.TypeConfig{
+		MaxAttempts: 3,
+		Run: func(ctx context.Context, run *job.Run) error {
+			return job.Permanent(errors.New("source missing"))
+		},
+	})
+
+	flaky, err := manager.Enqueue(context.Background(), job.EnqueueRequest{Type: "test.flaky", Owner: "alice"})
+	if err != nil {
+		t.Fatalf("enqueue flaky: %v", err)
+	}
+	completed := waitForStatus(t, manager, flaky.ID, job.StatusCompleted)
+	if completed.Attempts != 2 || completed.FailureReason != "" {
+		t.Fatalf("expected completion on second attempt, got %+v", completed)
+	}
+	var result map[string]int
+	if err := completed.DecodeResult(&result); err != nil || result["copied"] != 2 {
+		t.Fatalf("expected result to be stored, got %v (err=%v)", result, err)
+	}
+
+	broken, err := manager.Enqueue(context.Background(), job.EnqueueRequest{Type: "test.broken", Owner: "alice"})
+	if err != nil {
+		t.Fatalf("enqueue broken: %v", err)
+	}
+	failed := waitForStatus(t, manager, broken.ID, job.StatusFailed)
+	if failed.Attempts != 1 || failed.FailureReason != "source missing" {
+		t.Fatalf("expected permanent failure without retry, got %+v", failed)
+	}
+
+	if _, err := manager.Enqueue(context.Background(), job.EnqueueRequest{Type: "test.unknown"}); !errors.Is(err, job.ErrUnknownType) {
+		t.Fatalf("expected unknown type error, got %v", err)
+	}
+}
+
+func TestManager_CancelStopsRunningJobAndRemovesArtifacts(t *testing.T) {
+	manager := job.NewManager(job.NewMemoryStore())
+	defer manager.Stop()
+
+	artifactDir := t.TempDir()
+	release := make(chan struct{})
+	wrote := make(chan error, 1)
+	manager.Register("test.export", job.TypeConfig{
+		Run: func(ctx context.Context, run *job.Run) error {
+			artifactPath := filepath.Join(artifactDir, run.Job().ID+".bin")
+			if err := os.WriteFile(artifactPath, []byte("data"), 0o644); err != nil {
+				return err
+			}
+			run.SetArtifact(artifactPath, 4)
+			var payload struct {
+				Block bool `json:"block"`
+			}
+			if err := run.Job().DecodePayload(&payload); err != nil {
+				return err
+			}
+			if payload.Block {
+				<-ctx.Done()
+				// 취소를 알아챈 뒤에도 돌아오기 전까지는 산출물을 계속 쓸 수 있어야 합니다.
+				<-release
+				file, err := os.OpenFile(artifactPath, os.O_WRONLY|os.O_APPEND, 0)
+				if err == nil {
+					_, err = file.WriteString("more")
+					_ = file.Close()
+				}
+				wrote <- err
+				return ctx.Err()
+			}
+			return nil
+		},
+	})
+
+	blocking, err := manager.Enqueue(context.Background(), job.EnqueueRequest{Type: "test.export", Owner: "alice", Payload: map[string]bool{"block": true}})
+	if err != nil {
+		t.Fatalf("enqueue blocking: %v", err)
+	}
+	waitForStatus(t, manager, blocking.ID, job.StatusRunning)
+	if _, err := manager.Cancel(context.Background(), blocking.ID); err != nil {
+		t.Fatalf("cancel running: %v", err)
+	}
+	if current := waitForStatus(t, manager, blocking.ID, job.StatusCanceled); current.FinishedAt == nil {
+		t.Fatalf("expected canceled job to be finished, got %+v", current)
+	}
+	close(release)
+	if err := <-wrote; err != nil {
+		t.Fatalf("expected running artifact to stay until the job returned: %v", err)
+	}
+	blockingArtifact := filepath.Join(artifactDir, blocking.ID+".bin")
+	deadline := time.Now().Add(3 * time.Second)
+	for {
+		canceled, err := manager.Get(context.Background(), blocking.ID)
+		if err != nil {
+			t.Fatalf("get canceled: %v", err)
+		}
+		_, statErr := os.Stat(blockingArtifact)
+		if canceled.ArtifactPath == "" && os.IsNotExist(statErr) {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected artifact to be removed after the job returned, got %+v (stat err=%v)", canceled, statErr)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	finished, err := manager.Enqueue(context.Background(), job.EnqueueRequest{Type: "test.export", Owner: "alice"})
+	if err != nil {
+		t.Fatalf("enqueue finished: %v", err)
+	}
+	completed := waitForStatus(t, manager, finished.ID, job.StatusCompleted)
+	if _, err := os.Stat(completed.ArtifactPath); err != nil {
+		t.Fatalf("expected completed artifact to exist: %v", err)
+	}
+	if _, err := manager.Cancel(context.Background(), finished.ID); err != nil {
+		t.Fatalf("cancel completed: %v", err)
+	}
+	if _, err := os.Stat(completed.ArtifactPath); !os.IsNotExist(err) {
+		t.Fatalf("expected completed artifact to be removed on cancel, err=%v", err)
+	}
+	if _, err := manager.Cancel(context.Background(), finished.ID); err != nil {
+		t.Fatalf("expected repeated cancel to be idempotent, got %v", err)
+	}
+}
+
+func TestManager_PreventCancelRejectsLaterCancel(t *testing.T) {
+	manager := job.NewManager(job.NewMemoryStore())
+	defer manager.Stop()
+
+	committed := make(chan bool, 1)
+	proceed := make(chan struct{})
+	manager.Register("test.switch", job.TypeConfig{
+		Run: func(ctx context.Context, run *job.Run) error {
+			<-proceed
+			ok := run.PreventCancel()
+			committed <- ok
+			if !ok {
+				return ctx.Err()
+			}
+			<-proceed
+			return nil
+		},
+	})
+
+	// 취소된 뒤에는 되돌릴 수 없는 단계로 넘어가지 못한다.
+	canceled, err := manager.Enqueue(context.Background(), job.EnqueueRequest{Type: "test.switch", Owner: "alice"})
+	if err != nil {
+		t.Fatalf("enqueue canceled: %v", err)
+	}
+	waitForStatus(t, manager, canceled.ID, job.StatusRunning)
+	if _, err := manager.Cancel(context.Background(), canceled.ID); err != nil {
+		t.Fatalf("cancel before commit: %v", err)
+	}
+	proceed <- struct{}{}
+	if <-committed {
+		t.Fatal("expected PreventCancel to report the earlier cancel")
+	}
+	waitForStatus(t, manager, canceled.ID, job.StatusCanceled)
+
+	switching, err := manager.Enqueue(context.Background(), job.EnqueueRequest{Type: "test.switch", Owner: "alice"})
+	if err != nil {
+		t.Fatalf("enqueue switching: %v", err)
+	}
+	waitForStatus(t, manager, switching.ID, job.StatusRunning)
+	proceed <- struct{}{}
+	if !<-committed {
+		t.Fatal("expected PreventCancel to succeed on a live job")
+	}
+	if _, err := manager.Cancel(context.Background(), switching.ID); !errors.Is(err, job.ErrNotCancelable) {
+		t.Fatalf("expected committed job to reject cancel, got %v", err)
+	}
+	close(proceed)
+	waitForStatus(t, manager, switching.ID, job.StatusCompleted)
+}
+
+// blockingListStore는 block이 켜져 있으면 List 결과를 읽은 뒤 release가 닫힐 때까지 붙잡습니다.
+type blockingListStore struct {
+	job.Storer
+	block   atomic.Bool
+	entered chan struct{}
+	release chan struct{}
+}
+
+func (s *blockingListStore) List(ctx context.Context, filter job.ListFilter) ([]*job.Job, error) {
+	items, err := s.Storer.List(ctx, filter)
+	if s.block.Load() {
+		select {
+		case s.entered <- struct{}{}:
+		default:
+		}
+		<-s.release
+	}
+	return items, err
+}
+
+func TestManager_DispatchLoadsQueueWithoutHoldingLock(t *testing.T) {
+	store := &blockingListStore{Storer: job.NewMemoryStore(), entered: make(chan struct{}, 1), release: make(chan struct{})}
+	manager := job.NewManager(store)
+	defer manager.Stop()
+
+	var started sync.Map
+	manager.Register("test.hold", job.TypeConfig{
+		Concurrency: 1,
+		Run: func(ctx context.Context, run *job.Run) error {
+			started.Store(run.Job().ID, true)
+			<-ctx.Done()
+			return ctx.Err()
+		},
+	})
+
+	holding, err := manager.Enqueue(context.Background(), job.EnqueueRequest{Type: "test.hold", Owner: "alice"})
+	if err != nil {
+		t.Fatalf("enqueue holding: %v", err)
+	}
+	waitForStatus(t, manager, holding.ID, job.StatusRunning)
+	waiting, err := manager.Enqueue(context.Background(), job.EnqueueRequest{Type: "test.hold", Owner: "alice"})
+	if err != nil {
+		t.Fatalf("enqueue waiting: %v", err)
+	}
+
+	// 슬롯이 비면 dispatch가 대기 목록을 읽다가 멈춥니다. 그동안에도 조회와 취소는 막히지 않아야 합니다.
+	store.block.Store(true)
+	if _, err := manager.Cancel(context.Background(), holding.ID); err != nil {
+		t.Fatalf("cancel holding: %v", err)
+	}
+	select {
+	case <-store.entered:
+	case <-time.After(3 * time.Second):
+		t.Fatal("expected dispatch to load queued jobs after the slot was freed")
+	}
+	done := make(chan error, 1)
+	go func() {
+		_, err := manager.Cancel(context.Background(), waiting.ID)
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("cancel waiting: %v", err)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("cancel blocked while dispatch was loading queued jobs")
+	}
+
+	// 읽어 둔 목록은 취소 전 것이므로 dispatch는 다시 읽고 취소된 작업을 시작하지 않아야 합니다.
+	store.block.Store(false)
+	close(store.release)
+	waitForStatus(t, manager, holding.ID, job.StatusCanceled)
+	time.Sleep(50 * time.Millisecond)
+	if _, ok := started.Load(waiting.ID); ok {
+		t.Fatal("canceled job was started from a stale queue snapshot")
+	}
+	if current := waitForStatus(t, manager, waiting.ID, job.StatusCanceled); current.Attempts != 0 {
+		t.Fatalf("expected canceled job to stay unattempted, got %+v", current)
+	}
+}
+
+// blockingUpdateStore는 blockID 작업을 저장할 때 release가 닫힐 때까지 붙잡습니다.
+type blockingUpdateStore struct {
+	job.Storer
+	blockID atomic.Value
+	entered chan struct{}
+	release chan struct{}
+}
+
+func (s *blockingUpdateStore) Update(ctx context.Context, item *job.Job) error {
+	if id, _ := s.blockID.Load().(string); id != "" && id == item.ID {
+		select {
+		case s.entered <- struct{}{}:
+		default:
+		}
+		<-s.release
+	}
+	return s.Storer.Update(ctx, item)
+}
+
+func TestManager_PersistsProgressWithoutHoldingLock(t *testing.T) {
+	store := &blockingUpdateStore{Storer: job.NewMemoryStore(), entered: make(chan struct{}, 1), release: make(chan struct{})}
+	manager := job.NewManager(store)
+	defer manager.Stop()
+
+	proceed := make(chan struct{})
+	manager.Register("test.report", job.TypeConfig{
+		Run: func(ctx context.Context, run *job.Run) error {
+			<-proceed
+			return run.SetResult(map[string]int{"step": 1})
+		},
+	})
+
+	created, err := manager.Enqueue(context.Background(), job.EnqueueRequest{Type: "test.report", Owner: "alice"})
+	if err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+	waitForStatus(t, manager, created.ID, job.StatusRunning)
+	store.blockID.Store(created.ID)
+	close(proceed)
+	select {
+	case <-store.entered:
+	case <-time.After(3 * time.Second):
+		t.Fatal("expected SetResult to persist the result")
+	}
+
+	// 저장이 늦어져도 같은 작업의 조회는 막히지 않아야 합니다.
+	done := make(chan error, 1)
+	go func() {
+		_, err := manager.Get(context.Background(), created.ID)
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("get while persisting: %v", err)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("get blocked while the job result was being persisted")
+	}
+
+	store.blockID.Store("")
+	close(store.release)
+	if completed := waitForStatus(t, manager, created.ID, job.StatusCompleted); string(completed.Result) != `{"step":1}` {
+		t.Fatalf("expected persisted result, got %s", completed.Result)
+	}
+}
+
+func TestManager_CleanupExpiredRemovesArtifactsThenRecords(t *testing.T) {
+	manager := job.NewManager(job.NewMemoryStore())
+	defer manager.Stop()
+
+	artifactPath := filepath.Join(t.TempDir(), "artifact.zip")
+	var expiredMu sync.Mutex
+	expiredIDs := make([]string, 0)
+	manager.Register("test.zip", job.TypeConfig{
+		ArtifactTTL: time.Millisecond,
+		Retention:   time.Millisecond,
+		Run: func(ctx context.Context, run *job.Run) error {
+			if err := os.WriteFile(artifactPath, []byte("zip"), 0o644); err != nil {
+				return err
+			}
+			run.SetArtifact(artifactPath, 3)
+			return nil
+		},
+		OnExpire: func(expired *job.Job) {
+			expiredMu.Lock()
+			defer expiredMu.Unlock()
+			expiredIDs = append(expiredIDs, expired.ID)
+		},
+	})
+
+	created, err := manager.Enqueue(context.Background(), job.EnqueueRequest{Type: "test.zip", Owner: "alice"})
+	if err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+	waitForStatus(t, manager, created.ID, job.StatusCompleted)
+	time.Sleep(5 * time.Millisecond)
+
+	expired, err := manager.CleanupExpired(context.Background())
+	if err != nil {
+		t.Fatalf("cleanup: %v", err)
+	}
+	if len(expired) != 1 || expired[0].Status != job.StatusExpired {
+		t.Fatalf("expected one expired job, got %+v", expired)
+	}
+	if _, err := os.Stat(artifactPath); !os.IsNotExist(err) {
+		t.Fatalf("expected artifact to be removed, err=%v", err)
+	}
+	expiredMu.Lock()
+	if len(expiredIDs) != 1 || expiredIDs[0] != created.ID {
+		t.Fatalf("expected OnExpire to be called once, got %v", expiredIDs)
+	}
+	expiredMu.Unlock()
+
+	time.Sleep(5 * time.Millisecond)
+	if _, err := manager.CleanupExpired(context.Background()); err != nil {
+		t.Fatalf("second cleanup: %v", err)
+	}
+	if _, err := manager.Get(context.Background(), created.ID); !errors.Is(err, job.ErrNotFound) {
+		t.Fatalf("expected expired job record to be deleted, got %v", err)
+	}
+}
+
+func TestManager_PersistsJobsAcrossRestart(t *testing.T) {
+	store := setupJobStore(t)
+	ctx := context.Background()
+
+	started := make(chan struct{}, 1)
+	first := job.NewManager(store)
+	first.Register("test.copy", job.TypeConfig{
+		MaxAttempts: 1,
+		Run: func(ctx context.Context, run *job.Run) error {
+			run.SetTotals(10, 1000)
+			run.Advance(4, 400)
+			started <- struct{}{}
+			<-ctx.Done()
+			return ctx.Err()
+		},
+	})
+	if err := first.Start(ctx); err != nil {
+		t.Fatalf("start first manager: %v", err)
+	}
+
+	spaceID := int64(7)
+	created, err := first.Enqueue(ctx, job.EnqueueRequest{
+		Type:      "test.copy",
+		Owner:     "alice",
+		SpaceID:   &spaceID,
+		RequestID: "req_1",
+		Payload:   map[string]string{"source": "docs"},
+	})
+	if err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+	<-started
+	first.Stop()
+
+	// 서버 종료로 중단된 작업은 시도 횟수를 쓰지 않고 대기열로 돌아간다.
+	interrupted, err := store.Get(ctx, created.ID)
+	if err != nil {
+		t.Fatalf("load interrupted job: %v", err)
+	}
+	if interrupted.Status != job.StatusQueued || interrupted.Attempts != 0 {
+		t.Fatalf("expected interrupted job to be requeued, got %+v", interrupted)
+	}
+
+	// 프로세스가 비정상 종료되어 running으로 남은 작업도 다시 시작 시 처리한다.
+	crashedAt := time.Now().UTC()
+	crashed := &job.Job{
+		ID:          "crashed",
+		Type:        "test.copy",
+		Owner:       "bob",
+		Status:      job.StatusRunning,
+		Attempts:    1,
+		MaxAttempts: 1,
+		RunAfter:    crashedAt,
+		CreatedAt:   crashedAt,
+		UpdatedAt:   crashedAt,
+		StartedAt:   &crashedAt,
+	}
+	if err := store.Create(ctx, crashed); err != nil {
+		t.Fatalf("seed crashed job: %v", err)
+	}
+
+	second := job.NewManager(store)
+	defer second.Stop()
+	var finishedOnRestart []string
+	second.Register("test.copy", job.TypeConfig{
+		OnFinish: func(finished *job.Job) {
+			if finished.FailureReason != "" {
+				finishedOnRestart = append(finishedOnRestart, finished.ID)
+			}
+		},
+		Run: func(ctx context.Context, run *job.Run) error {
+			var payload map[string]string
+			if err := run.Job().DecodePayload(&payload); err != nil {
+				return err
+			}
+			if payload["source"] != "docs" {
+				return job.Permanent(errors.New("payload lost"))
+			}
+			run.SetTotals(1, 10)
+			run.Advance(1, 10)
+			return nil
+		},
+	})
+	if err := second.Start(ctx); err != nil {
+		t.Fatalf("start second manager: %v", err)
+	}
+
+	completed := waitForStatus(t, second, created.ID, job.StatusCompleted)
+	if completed.Attempts != 1 || completed.SpaceID == nil || *completed.SpaceID != spaceID || completed.RequestID != "req_1" {
+		t.Fatalf("unexpected resumed job: %+v", completed)
+	}
+	if completed.Progress.ProcessedItems != 1 || completed.Progress.TotalBytes != 10 {
+		t.Fatalf("expected progress to be persisted, got %+v", completed.Progress)
+	}
+
+	failed, err := second.Get(ctx, "crashed")
+	if err != nil {
+		t.Fatalf("get crashed job: %v", err)
+	}
+	if failed.Status != job.StatusFailed || failed.FailureReason == "" {
+		t.Fatalf("expected exhausted crashed job to fail, got %+v", failed)
+	}
+	// 재시작에서 실패로 끝난 작업도 정리할 기회를 얻는다.
+	if len(finishedOnRestart) != 1 || finishedOnRestart[0] != "crashed" {
+		t.Fatalf("expected OnFinish for the exhausted crashed job, got %v", finishedOnRestart)
+	}
+
+	owned, err := second.List(ctx, job.ListFilter{Owner: "alice"})
+	if err != nil {
+		t.Fatalf("list jobs: %v", err)
+	}
+	if len(owned) != 1 || owned[0].ID != created.ID {
+		t.Fatalf("expected owner-scoped list, got %+v", owned)
+	}
+
+	if kept, err := second.DiscardSpaceJobs(ctx, spaceID, "test.copy"); err != nil || kept != 0 {
+		t.Fatalf("expected kept type to survive discard, got %d (err=%v)", kept, err)
+	}
+	discarded, err := second.DiscardSpaceJobs(ctx, spaceID)
+	if err != nil || discarded != 1 {
+		t.Fatalf("expected one discarded job, got %d (err=%v)", discarded, err)
+	}
+	if _, err := second.Get(ctx, created.ID); !errors.Is(err, job.ErrNotFound) {
+		t.Fatalf("expected discarded job to be removed, got %v", err)
+	}
+	if err := store.Delete(ctx, created.ID); !errors.Is(err, job.ErrNotFound) {
+		t.Fatalf("expected store to report missing job as ErrNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,112 @@
+package job
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// MemoryStore는 프로세스 메모리에만 작업을 보관하는 Storer입니다.
+// DB 없이 Manager를 쓰는 테스트나 기본 핸들러 구성에서 사용합니다.
+type MemoryStore struct {
+	mu   sync.Mutex
+	jobs map[string]*Job
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{jobs: make(map[string]*Job)}
+}
+
+func (s *MemoryStore) Create(ctx context.Context, job *Job) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, exists := s.jobs[job.ID]; exists {
+		return fmt.Errorf("job already exists: %s", job.ID)
+	}
+	s.jobs[job.ID] = job.Clone()
+	return nil
+}
+
+func (s *MemoryStore) Get(ctx context.Context, id string) (*Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	found, ok := s.jobs[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+	return found.Clone(), nil
+}
+
+func (s *MemoryStore) Update(ctx context.Context, job *Job) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.jobs[job.ID]; !ok {
+		return fmt.Errorf("%w: %s", ErrNotFound, job.ID)
+	}
+	s.jobs[job.ID] = job.Clone()
+	return nil
+}
+
+func (s *MemoryStore) List(ctx context.Context, filter ListFilter) ([]*Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	items := make([]*Job, 0, len(s.jobs))
+	for _, item := range s.jobs {
+		if !filter.matches(item) {
+			continue
+		}
+		items = append(items, item.Clone())
+	}
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].CreatedAt.Equal(items[j].CreatedAt) {
+			return items[i].ID < items[j].ID
+		}
+		if filter.OldestFirst {
+			return items[i].CreatedAt.Before(items[j].CreatedAt)
+		}
+		return items[i].CreatedAt.After(items[j].CreatedAt)
+	})
+	if filter.Limit > 0 && len(items) > filter.Limit {
+		items = items[:filter.Limit]
+	}
+	return items, nil
+}
+
+func (s *MemoryStore) Delete(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.jobs[id]; !ok {
+		return fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+	delete(s.jobs, id)
+	return nil
+}
+
+func (f ListFilter) matches(item *Job) bool {
+	if f.Owner != "" && item.Owner != f.Owner {
+		return false
+	}
+	if f.Type != "" && item.Type != f.Type {
+		return false
+	}
+	if f.SpaceID != nil && (item.SpaceID == nil || *item.SpaceID != *f.SpaceID) {
+		return false
+	}
+	if len(f.Statuses) > 0 {
+		matched := false
+		for _, status := range f.Statuses {
+			if item.Status == status {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	return true
+}
+
+var _ Storer = (*MemoryStore)(nil)
@@ -0,0 +1,302 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"taeu.kr/cohesion/internal/job"
+)
+
+var jobColumns = []string{
+	"id",
+	"job_type",
+	"owner",
+	"space_id",
+	"request_id",
+	"status",
+	"payload_json",
+	"result_json",
+	"total_items",
+	"processed_items",
+	"total_bytes",
+	"processed_bytes",
+	"failure_reason",
+	"attempts",
+	"max_attempts",
+	"artifact_path",
+	"artifact_size",
+	"run_after",
+	"created_at",
+	"updated_at",
+	"started_at",
+	"finished_at",
+	"expires_at",
+}
+
+type Store struct {
+	db *sql.DB
+	qb sq.StatementBuilderType
+}
+
+func NewStore(db *sql.DB) *Store {
+	return &Store{
+		db: db,
+		qb: sq.StatementBuilder.PlaceholderFormat(sq.Question),
+	}
+}
+
+func (s *Store) Create(ctx context.Context, item *job.Job) error {
+	sqlQuery, args, err := s.qb.
+		Insert("jobs").
+		Columns(jobColumns...).
+		Values(jobValues(item)...).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build SQL query for Create job: %w", err)
+	}
+
+	if _, err := s.db.ExecContext(ctx, sqlQuery, args...); err != nil {
+		return fmt.Errorf("failed to insert job: %w", err)
+	}
+	return nil
+}
+
+func (s *Store) Get(ctx context.Context, id string) (*job.Job, error) {
+	sqlQuery, args, err := s.qb.
+		Select(jobColumns...).
+		From("jobs").
+		Where(sq.Eq{"id": id}).
+		Limit(1).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build SQL query for Get job: %w", err)
+	}
+
+	item, err := scanJob(s.db.QueryRowContext(ctx, sqlQuery, args...))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("%w: id %s", job.ErrNotFound, id)
+		}
+		return nil, fmt.Errorf("failed to scan job row: %w", err)
+	}
+	return item, nil
+}
+
+func (s *Store) Update(ctx context.Context, item *job.Job) error {
+	values := jobValues(item)
+	setMap := make(map[string]interface{}, len(jobColumns)-1)
+	for i, column := range jobColumns {
+		if column == "id" {
+			continue
+		}
+		setMap[column] = values[i]
+	}
+
+	sqlQuery, args, err := s.qb.
+		Update("jobs").
+		SetMap(setMap).
+		Where(sq.Eq{"id": item.ID}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build SQL query for Update job: %w", err)
+	}
+
+	result, err := s.db.ExecContext(ctx, sqlQuery, args...)
+	if err != nil {
+		return fmt.Errorf("failed to update job: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("%w: id %s", job.ErrNotFound, item.ID)
+	}
+	return nil
+}
+
+func (s *Store) List(ctx context.Context, filter job.ListFilter) ([]*job.Job, error) {
+	queryBuilder := s.qb.Select(jobColumns...).From("jobs")
+	if filter.Owner != "" {
+		queryBuilder = queryBuilder.Where(sq.Eq{"owner": filter.Owner})
+	}
+	if filter.Type != "" {
+		queryBuilder = queryBuilder.Where(sq.Eq{"job_type": filter.Type})
+	}
+	if filter.SpaceID != nil {
+		queryBuilder = queryBuilder.Where(sq.Eq{"space_id": *filter.SpaceID})
+	}
+	if len(filter.Statuses) > 0 {
+		statuses := make([]string, 0, len(filter.Statuses))
+		for _, status := range filter.Statuses {
+			statuses = append(statuses, string(status))
+		}
+		queryBuilder = queryBuilder.Where(sq.Eq{"status": statuses})
+	}
+	if filter.OldestFirst {
+		queryBuilder = queryBuilder.OrderBy("created_at ASC", "id ASC")
+	} else {
+		queryBuilder = queryBuilder.OrderBy("created_at DESC", "id DESC")
+	}
+	if filter.Limit > 0 {
+		queryBuilder = queryBuilder.Limit(uint64(filter.Limit))
+	}
+
+	sqlQuery, args, err := queryBuilder.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build SQL query for List jobs: %w", err)
+	}
+
+	rows, err := s.db.QueryContext(ctx, sqlQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query jobs: %w", err)
+	}
+	defer rows.Close()
+
+	items := make([]*job.Job, 0)
+	for rows.Next() {
+		item, scanErr := scanJob(rows)
+		if scanErr != nil {
+			return nil, fmt.Errorf("failed to scan job row: %w", scanErr)
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error in job list: %w", err)
+	}
+	return items, nil
+}
+
+func (s *Store) Delete(ctx context.Context, id string) error {
+	sqlQuery, args, err := s.qb.
+		Delete("jobs").
+		Where(sq.Eq{"id": id}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build SQL query for Delete job: %w", err)
+	}
+
+	result, err := s.db.ExecContext(ctx, sqlQuery, args...)
+	if err != nil {
+		return fmt.Errorf("failed to delete job: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("%w: id %s", job.ErrNotFound, id)
+	}
+	return nil
+}
+
+func jobValues(item *job.Job) []interface{} {
+	var spaceID interface{}
+	if item.SpaceID != nil {
+		spaceID = *item.SpaceID
+	}
+	payload := string(item.Payload)
+	if payload == "" {
+		payload = "{}"
+	}
+	return []interface{}{
+		item.ID,
+		item.Type,
+		item.Owner,
+		spaceID,
+		item.RequestID,
+		string(item.Status),
+		payload,
+		string(item.Result),
+		item.Progress.TotalItems,
+		item.Progress.ProcessedItems,
+		item.Progress.TotalBytes,
+		item.Progress.ProcessedBytes,
+		item.FailureReason,
+		item.Attempts,
+		item.MaxAttempts,
+		item.ArtifactPath,
+		item.ArtifactSize,
+		item.RunAfter.UTC(),
+		item.CreatedAt.UTC(),
+		item.UpdatedAt.UTC(),
+		nullableTime(item.StartedAt),
+		nullableTime(item.FinishedAt),
+		nullableTime(item.ExpiresAt),
+	}
+}
+
+func nullableTime(value *time.Time) interface{} {
+	if value == nil {
+		return nil
+	}
+	return value.UTC()
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanJob(row rowScanner) (*job.Job, error) {
+	var (
+		item       job.Job
+		spaceID    sql.NullInt64
+		status     string
+		payload    string
+		result     string
+		startedAt  sql.NullTime
+		finishedAt sql.NullTime
+		expiresAt  sql.NullTime
+	)
+	if err := row.Scan(
+		&item.ID,
+		&item.Type,
+		&item.Owner,
+		&spaceID,
+		&item.RequestID,
+		&status,
+		&payload,
+		&result,
+		&item.Progress.TotalItems,
+		&item.Progress.ProcessedItems,
+		&item.Progress.TotalBytes,
+		&item.Progress.ProcessedBytes,
+		&item.FailureReason,
+		&item.Attempts,
+		&item.MaxAttempts,
+		&item.ArtifactPath,
+		&item.ArtifactSize,
+		&item.RunAfter,
+		&item.CreatedAt,
+		&item.UpdatedAt,
+		&startedAt,
+		&finishedAt,
+		&expiresAt,
+	); err != nil {
+		return nil, err
+	}
+
+	item.Status = job.Status(status)
+	if spaceID.Valid {
+		item.SpaceID = &spaceID.Int64
+	}
+	item.Payload = []byte(payload)
+	if result != "" {
+		item.Result = []byte(result)
+	}
+	if startedAt.Valid {
+		item.StartedAt = &startedAt.Time
+	}
+	if finishedAt.Valid {
+		item.FinishedAt = &finishedAt.Time
+	}
+	if expiresAt.Valid {
+		item.ExpiresAt = &expiresAt.Time
+	}
+	return &item, nil
+}
+
+var _ job.Storer = (*Store)(nil)
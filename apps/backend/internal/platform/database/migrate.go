@@ -23,6 +23,21 @@ func Migrate(ctx context.Context, db *sql.DB) error {
 	if err := migrateSpaceDescriptionColumn(ctx, db); err != nil {
 		return err
 	}
+	if err := migrateSpaceSlugColumn(ctx, db); err != nil {
+		return err
+	}
+	if err := migrateSpaceStateColumn(ctx, db); err != nil {
+		return err
+	}
+	if err := migrateSpaceRootMarkerColumn(ctx, db); err != nil {
+		return err
+	}
+	if err := migrateSpaceWebDAVArchiveBrowsingColumn(ctx, db); err != nil {
+		return err
+	}
+	if err := migrateSpaceFileQuotaColumns(ctx, db); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -204,6 +219,74 @@ func migrateSpaceDescriptionColumn(ctx context.Context, db *sql.DB) error {
 	return tx.Commit()
 }
 
+// migrateSpaceSlugColumn은 프로토콜 경로용 slug 컬럼과 유일 인덱스를 보장합니다.
+// 기존 Space의 slug 값은 애플리케이션 시작 시 space.Service가 채웁니다.
+func migrateSpaceSlugColumn(ctx context.Context, db *sql.DB) error {
+	hasSlugColumn, err := tableHasColumn(ctx, db, "space", "space_slug")
+	if err != nil {
+		return err
+	}
+	if !hasSlugColumn {
+		if _, err := db.ExecContext(ctx, "ALTER TABLE space ADD COLUMN space_slug TEXT"); err != nil {
+			return err
+		}
+	}
+
+	_, err = db.ExecContext(ctx, "CREATE UNIQUE INDEX IF NOT EXISTS idx_space_slug ON space(space_slug)")
+	return err
+}
+
+// migrateSpaceStateColumn은 Space 운영 상태(active/read_only/maintenance/archived) 컬럼을 보장합니다.
+func migrateSpaceStateColumn(ctx context.Context, db *sql.DB) error {
+	hasStateColumn, err := tableHasColumn(ctx, db, "space", "space_state")
+	if err != nil || hasStateColumn {
+		return err
+	}
+	_, err = db.ExecContext(ctx, "ALTER TABLE space ADD COLUMN space_state TEXT NOT NULL DEFAULT 'active'")
+	return err
+}
+
+// migrateSpaceRootMarkerColumn은 root 상태 점검에 쓰는 마커 ID 컬럼을 보장합니다.
+// 기존 Space의 값은 첫 점검 시 RootHealthMonitor가 채웁니다.
+func migrateSpaceRootMarkerColumn(ctx context.Context, db *sql.DB) error {
+	hasMarkerColumn, err := tableHasColumn(ctx, db, "space", "root_marker")
+	if err != nil || hasMarkerColumn {
+		return err
+	}
+	_, err = db.ExecContext(ctx, "ALTER TABLE space ADD COLUMN root_marker TEXT")
+	return err
+}
+
+// migrateSpaceWebDAVArchiveBrowsingColumn은 WebDAV에서 압축 파일을 폴더로 보여 줄지 정하는 컬럼을 보장합니다.
+func migrateSpaceWebDAVArchiveBrowsingColumn(ctx context.Context, db *sql.DB) error {
+	hasColumn, err := tableHasColumn(ctx, db, "space", "webdav_archive_browsing")
+	if err != nil || hasColumn {
+		return err
+	}
+	_, err = db.ExecContext(ctx, "ALTER TABLE space ADD COLUMN webdav_archive_browsing INTEGER NOT NULL DEFAULT 0")
+	return err
+}
+
+// migrateSpaceFileQuotaColumns는 파일 수 할당량과 경고 비율 컬럼을 보장합니다.
+func migrateSpaceFileQuotaColumns(ctx context.Context, db *sql.DB) error {
+	for column, ddl := range map[string]string{
+		"quota_files":            "ALTER TABLE space ADD COLUMN quota_files INTEGER",
+		"quota_warning_percents": "ALTER TABLE space ADD COLUMN quota_warning_percents TEXT",
+	} {
+		hasColumn, err := tableHasColumn(ctx, db, "space", column)
+		if err != nil {
+			return err
+		}
+		if hasColumn {
+			continue
+		}
+		if _, err := db.ExecContext(ctx, ddl); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func tableHasColumn(ctx context.Context, db *sql.DB, tableName string, columnName string) (bool, error) {
 	rows, err := db.QueryContext(ctx, "PRAGMA table_info("+tableName+")")
 	if err != nil {
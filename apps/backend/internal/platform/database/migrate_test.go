@@ -131,3 +131,108 @@ func TestMigrate_RemovesSpaceDescriptionColumnAndPreservesData(t *testing.T) {
 		t.Fatalf("expected migrated related rows to be preserved, got trash=%d audit=%d permissions=%d", trashCount, auditCount, accessCount)
 	}
 }
+
+func TestMigrate_AddsUniqueSpaceSlugColumn(t *testing.T) {
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+	legacySpace := `CREATE TABLE space (
+		id              INTEGER PRIMARY KEY AUTOINCREMENT,
+		space_name      TEXT NOT NULL,
+		space_path      TEXT NOT NULL,
+		icon            TEXT,
+		space_category  TEXT,
+		quota_bytes     INTEGER,
+		created_at      TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		created_user_id TEXT,
+		updated_at      TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		updated_user_id TEXT
+	)`
+	if _, err := db.ExecContext(ctx, legacySpace); err != nil {
+		t.Fatalf("create legacy space table: %v", err)
+	}
+	if _, err := db.ExecContext(ctx, `INSERT INTO space(id, space_name, space_path) VALUES (1, 'alpha', '/tmp/alpha'), (2, 'beta', '/tmp/beta')`); err != nil {
+		t.Fatalf("insert legacy spaces: %v", err)
+	}
+
+	if err := Migrate(ctx, db); err != nil {
+		t.Fatalf("migrate db: %v", err)
+	}
+	// 재실행해도 안전해야 한다.
+	if err := Migrate(ctx, db); err != nil {
+		t.Fatalf("re-run migrate db: %v", err)
+	}
+
+	hasSlugColumn, err := tableHasColumn(ctx, db, "space", "space_slug")
+	if err != nil {
+		t.Fatalf("check space_slug column: %v", err)
+	}
+	if !hasSlugColumn {
+		t.Fatal("expected space_slug column to be added")
+	}
+
+	if _, err := db.ExecContext(ctx, "UPDATE space SET space_slug = 'alpha' WHERE id = 1"); err != nil {
+		t.Fatalf("assign slug: %v", err)
+	}
+	if _, err := db.ExecContext(ctx, "UPDATE space SET space_slug = 'alpha' WHERE id = 2"); err == nil {
+		t.Fatal("expected duplicate slug to violate unique index")
+	}
+	if _, err := db.ExecContext(ctx, "INSERT INTO space_aliases(alias, space_id) VALUES ('old-alpha', 1)"); err != nil {
+		t.Fatalf("insert space alias: %v", err)
+	}
+}
+
+func TestMigrate_AddsSpaceStateColumnWithActiveDefault(t *testing.T) {
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+	legacySpace := `CREATE TABLE space (
+		id              INTEGER PRIMARY KEY AUTOINCREMENT,
+		space_name      TEXT NOT NULL,
+		space_path      TEXT NOT NULL,
+		icon            TEXT,
+		space_category  TEXT,
+		quota_bytes     INTEGER,
+		created_at      TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		created_user_id TEXT,
+		updated_at      TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		updated_user_id TEXT
+	)`
+	if _, err := db.ExecContext(ctx, legacySpace); err != nil {
+		t.Fatalf("create legacy space table: %v", err)
+	}
+	if _, err := db.ExecContext(ctx, `INSERT INTO space(id, space_name, space_path) VALUES (1, 'alpha', '/tmp/alpha')`); err != nil {
+		t.Fatalf("insert legacy space: %v", err)
+	}
+
+	if err := Migrate(ctx, db); err != nil {
+		t.Fatalf("migrate db: %v", err)
+	}
+	if err := Migrate(ctx, db); err != nil {
+		t.Fatalf("re-run migrate db: %v", err)
+	}
+
+	var state string
+	if err := db.QueryRowContext(ctx, "SELECT space_state FROM space WHERE id = 1").Scan(&state); err != nil {
+		t.Fatalf("read space_state: %v", err)
+	}
+	if state != "active" {
+		t.Fatalf("expected legacy space to be active, got %q", state)
+	}
+
+	var marker sql.NullString
+	if err := db.QueryRowContext(ctx, "SELECT root_marker FROM space WHERE id = 1").Scan(&marker); err != nil {
+		t.Fatalf("read root_marker: %v", err)
+	}
+	if marker.Valid {
+		t.Fatalf("expected legacy space root marker to be empty, got %q", marker.String)
+	}
+}
@@ -1,16 +1,32 @@
 CREATE TABLE IF NOT EXISTS space (
     id              INTEGER PRIMARY KEY AUTOINCREMENT,
     space_name      TEXT NOT NULL,
+    space_slug      TEXT,
     space_path      TEXT NOT NULL,
     icon            TEXT,
     space_category  TEXT,
     quota_bytes     INTEGER,
+    quota_files     INTEGER,
+    quota_warning_percents TEXT,
+    space_state     TEXT NOT NULL DEFAULT 'active',
+    root_marker     TEXT,
+    webdav_archive_browsing INTEGER NOT NULL DEFAULT 0,
     created_at      TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     created_user_id TEXT,
     updated_at      TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     updated_user_id TEXT
 );
 
+CREATE TABLE IF NOT EXISTS space_aliases (
+    alias       TEXT PRIMARY KEY,
+    space_id    INTEGER NOT NULL,
+    created_at  TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (space_id) REFERENCES space(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_space_aliases_space_id
+    ON space_aliases(space_id);
+
 CREATE TABLE IF NOT EXISTS trash_items (
     id            INTEGER PRIMARY KEY AUTOINCREMENT,
     space_id      INTEGER NOT NULL,
@@ -96,6 +112,207 @@ CREATE TABLE IF NOT EXISTS file_search_index_state (
     FOREIGN KEY (space_id) REFERENCES space(id) ON DELETE CASCADE
 );
 
+CREATE TABLE IF NOT EXISTS jobs (
+    id              TEXT PRIMARY KEY,
+    job_type        TEXT NOT NULL,
+    owner           TEXT NOT NULL,
+    space_id        INTEGER,
+    request_id      TEXT NOT NULL DEFAULT '',
+    status          TEXT NOT NULL,
+    payload_json    TEXT NOT NULL DEFAULT '{}',
+    result_json     TEXT NOT NULL DEFAULT '',
+    total_items     INTEGER NOT NULL DEFAULT 0,
+    processed_items INTEGER NOT NULL DEFAULT 0,
+    total_bytes     INTEGER NOT NULL DEFAULT 0,
+    processed_bytes INTEGER NOT NULL DEFAULT 0,
+    failure_reason  TEXT NOT NULL DEFAULT '',
+    attempts        INTEGER NOT NULL DEFAULT 0,
+    max_attempts    INTEGER NOT NULL DEFAULT 1,
+    artifact_path   TEXT NOT NULL DEFAULT '',
+    artifact_size   INTEGER NOT NULL DEFAULT 0,
+    run_after       TIMESTAMP NOT NULL,
+    created_at      TIMESTAMP NOT NULL,
+    updated_at      TIMESTAMP NOT NULL,
+    started_at      TIMESTAMP,
+    finished_at     TIMESTAMP,
+    expires_at      TIMESTAMP,
+    CHECK (status IN ('queued', 'running', 'completed', 'failed', 'canceled', 'expired'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_jobs_dispatch
+    ON jobs(status, job_type, run_after);
+
+CREATE INDEX IF NOT EXISTS idx_jobs_owner_created
+    ON jobs(owner, created_at DESC);
+
+CREATE TABLE IF NOT EXISTS download_signing_keys (
+    id          TEXT PRIMARY KEY,
+    secret      BLOB NOT NULL,
+    created_at  TIMESTAMP NOT NULL,
+    retired_at  TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS download_link_nonces (
+    username    TEXT PRIMARY KEY,
+    nonce       TEXT NOT NULL,
+    updated_at  TIMESTAMP NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS file_checksums (
+    path         TEXT NOT NULL,
+    algorithm    TEXT NOT NULL,
+    size         INTEGER NOT NULL,
+    mod_time_ns  INTEGER NOT NULL,
+    digest       TEXT NOT NULL,
+    computed_at  TIMESTAMP NOT NULL,
+    PRIMARY KEY (path, algorithm)
+);
+
+CREATE TABLE IF NOT EXISTS space_usage_counters (
+    space_id       INTEGER PRIMARY KEY,
+    used_bytes     INTEGER NOT NULL,
+    used_files     INTEGER NOT NULL DEFAULT 0,
+    reconciled_at  TIMESTAMP NOT NULL,
+    updated_at     TIMESTAMP NOT NULL,
+    FOREIGN KEY (space_id) REFERENCES space(id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS space_quota_alerts (
+    space_id          INTEGER NOT NULL,
+    metric            TEXT NOT NULL,
+    threshold_percent INTEGER NOT NULL,
+    notified_at       TIMESTAMP NOT NULL,
+    PRIMARY KEY (space_id, metric),
+    FOREIGN KEY (space_id) REFERENCES space(id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS space_upload_policies (
+    space_id           INTEGER PRIMARY KEY,
+    allowed_extensions TEXT NOT NULL DEFAULT '',
+    blocked_extensions TEXT NOT NULL DEFAULT '',
+    allowed_types      TEXT NOT NULL DEFAULT '',
+    blocked_types      TEXT NOT NULL DEFAULT '',
+    max_file_bytes     INTEGER,
+    updated_at         TIMESTAMP NOT NULL,
+    FOREIGN KEY (space_id) REFERENCES space(id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS space_malware_scan_settings (
+    space_id   INTEGER PRIMARY KEY,
+    mode       TEXT NOT NULL DEFAULT 'off',
+    updated_at TIMESTAMP NOT NULL,
+    FOREIGN KEY (space_id) REFERENCES space(id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS quarantine_items (
+    id             INTEGER PRIMARY KEY AUTOINCREMENT,
+    space_id       INTEGER NOT NULL,
+    original_path  TEXT NOT NULL,
+    storage_path   TEXT NOT NULL UNIQUE,
+    item_name      TEXT NOT NULL,
+    item_size      INTEGER NOT NULL DEFAULT 0,
+    signature      TEXT NOT NULL DEFAULT '',
+    scanner        TEXT NOT NULL DEFAULT '',
+    mode           TEXT NOT NULL DEFAULT '',
+    source         TEXT NOT NULL DEFAULT '',
+    uploaded_by    TEXT NOT NULL DEFAULT '',
+    quarantined_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_quarantine_items_space_quarantined_at
+    ON quarantine_items(space_id, quarantined_at DESC);
+
+CREATE TABLE IF NOT EXISTS space_retention_locks (
+    space_id     INTEGER NOT NULL,
+    path         TEXT NOT NULL,
+    retain_until TIMESTAMP,
+    legal_hold   INTEGER NOT NULL DEFAULT 0,
+    reason       TEXT NOT NULL DEFAULT '',
+    created_by   TEXT NOT NULL DEFAULT '',
+    created_at   TIMESTAMP NOT NULL,
+    updated_by   TEXT NOT NULL DEFAULT '',
+    updated_at   TIMESTAMP NOT NULL,
+    PRIMARY KEY (space_id, path),
+    FOREIGN KEY (space_id) REFERENCES space(id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS space_cleanup_rules (
+    id           INTEGER PRIMARY KEY AUTOINCREMENT,
+    space_id     INTEGER NOT NULL,
+    path_prefix  TEXT NOT NULL,
+    max_age_days INTEGER NOT NULL,
+    pattern      TEXT NOT NULL DEFAULT '',
+    action       TEXT NOT NULL DEFAULT 'trash',
+    enabled      INTEGER NOT NULL DEFAULT 1,
+    created_by   TEXT NOT NULL DEFAULT '',
+    created_at   TIMESTAMP NOT NULL,
+    updated_at   TIMESTAMP NOT NULL,
+    last_run_at  TIMESTAMP,
+    FOREIGN KEY (space_id) REFERENCES space(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_space_cleanup_rules_space_id ON space_cleanup_rules(space_id);
+
+CREATE TABLE IF NOT EXISTS space_organize_rules (
+    id                 INTEGER PRIMARY KEY AUTOINCREMENT,
+    space_id           INTEGER NOT NULL,
+    name               TEXT NOT NULL,
+    watch_path         TEXT NOT NULL DEFAULT '',
+    include_subfolders INTEGER NOT NULL DEFAULT 0,
+    conditions         TEXT NOT NULL DEFAULT '{}',
+    actions            TEXT NOT NULL DEFAULT '[]',
+    enabled            INTEGER NOT NULL DEFAULT 1,
+    created_by         TEXT NOT NULL DEFAULT '',
+    created_at         TIMESTAMP NOT NULL,
+    updated_at         TIMESTAMP NOT NULL,
+    FOREIGN KEY (space_id) REFERENCES space(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_space_organize_rules_space_id ON space_organize_rules(space_id);
+
+CREATE TABLE IF NOT EXISTS space_usage_snapshots (
+    space_id     INTEGER NOT NULL,
+    day          TEXT NOT NULL,
+    used_bytes   INTEGER NOT NULL,
+    quota_bytes  INTEGER,
+    recorded_at  TIMESTAMP NOT NULL,
+    PRIMARY KEY (space_id, day),
+    FOREIGN KEY (space_id) REFERENCES space(id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS file_owners (
+    space_id    INTEGER NOT NULL,
+    path        TEXT NOT NULL,
+    username    TEXT NOT NULL,
+    size        INTEGER NOT NULL,
+    updated_at  TIMESTAMP NOT NULL,
+    PRIMARY KEY (space_id, path),
+    FOREIGN KEY (space_id) REFERENCES space(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_file_owners_username ON file_owners(username, space_id);
+
+CREATE TABLE IF NOT EXISTS file_tags (
+    space_id    INTEGER NOT NULL,
+    path        TEXT NOT NULL,
+    tag         TEXT NOT NULL,
+    created_by  TEXT NOT NULL DEFAULT '',
+    created_at  TIMESTAMP NOT NULL,
+    PRIMARY KEY (space_id, path, tag),
+    FOREIGN KEY (space_id) REFERENCES space(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_file_tags_tag ON file_tags(space_id, tag);
+
+CREATE TABLE IF NOT EXISTS user_storage_quotas (
+    username     TEXT NOT NULL,
+    space_id     INTEGER NOT NULL DEFAULT 0,
+    quota_bytes  INTEGER NOT NULL,
+    updated_at   TIMESTAMP NOT NULL,
+    PRIMARY KEY (username, space_id),
+    FOREIGN KEY (username) REFERENCES users(username) ON DELETE CASCADE
+);
+
 CREATE TABLE IF NOT EXISTS roles (
     name         TEXT PRIMARY KEY,
     description  TEXT,
@@ -135,7 +352,8 @@ INSERT OR IGNORE INTO permissions(key, description) VALUES
 ('space.read', 'Space 조회'),
 ('space.write', 'Space 생성/삭제'),
 ('file.read', '파일 조회/다운로드'),
-('file.write', '파일 업로드/수정/이동/삭제');
+('file.write', '파일 업로드/수정/이동/삭제'),
+('retention.manage', '보존 잠금/법적 보존 설정');
 
 INSERT OR IGNORE INTO role_permissions(role_name, permission_key) VALUES
 ('admin', 'account.read'),
@@ -148,6 +366,7 @@ INSERT OR IGNORE INTO role_permissions(role_name, permission_key) VALUES
 ('admin', 'space.write'),
 ('admin', 'file.read'),
 ('admin', 'file.write'),
+('admin', 'retention.manage'),
 ('user', 'profile.read'),
 ('user', 'profile.write'),
 ('user', 'space.read'),
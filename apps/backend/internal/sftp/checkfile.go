@@ -0,0 +1,321 @@
+package sftp
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"taeu.kr/cohesion/internal/account"
+	"taeu.kr/cohesion/internal/space/checksum"
+)
+
+// pkg/sftp는 모르는 확장 요청을 거부하므로 check-file-name(draft-ietf-secsh-filexfer-extensions)은
+// 세션과 요청 서버 사이에서 패킷 단위로 가로채 직접 응답한다.
+const (
+	sshFxpVersion       = 2
+	sshFxpStatus        = 101
+	sshFxpExtended      = 200
+	sshFxpExtendedReply = 201
+
+	sshFxNoSuchFile       = 2
+	sshFxPermissionDenied = 3
+	sshFxFailure          = 4
+	sshFxOpUnsupported    = 8
+
+	checkFileNameRequest = "check-file-name"
+	checkFileExtension   = "check-file"
+
+	// maxForwardPacketLength는 pkg/sftp가 받는 최대 패킷 길이(256KiB)에 여유를 둔 값이다.
+	maxForwardPacketLength = 256*1024 + 1024
+	// checkFileWait는 check-file 요청이 해시 계산을 기다리는 최대 시간이다.
+	checkFileWait = 30 * time.Minute
+)
+
+// checkFileFunc는 파일 이름과 클라이언트가 보낸 알고리즘 목록으로 전체 파일 해시를 계산한다.
+type checkFileFunc func(ctx context.Context, filename string, algorithms []string) (checksum.Algorithm, []byte, error)
+
+var errCheckFileUnsupported = errors.New("unsupported check-file request")
+
+// checkFileChannel은 SSH 세션을 감싸 check-file-name 확장 요청만 처리하고 나머지 패킷은 pkg/sftp로 넘긴다.
+// pkg/sftp는 패킷 하나를 헤더와 본문 두 번에 나눠 쓰므로, 확장 응답이 끼어들지 않도록
+// 나가는 바이트를 패킷 단위로 모아 한 번에 쓴다.
+type checkFileChannel struct {
+	conn      io.ReadWriteCloser
+	ctx       context.Context
+	checkFile checkFileFunc
+
+	incoming *io.PipeReader
+	forward  *io.PipeWriter
+
+	writeMu     sync.Mutex
+	outgoing    []byte
+	versionSent bool
+}
+
+func newCheckFileChannel(ctx context.Context, conn io.ReadWriteCloser, checkFile checkFileFunc) *checkFileChannel {
+	incoming, forward := io.Pipe()
+	channel := &checkFileChannel{
+		conn:      conn,
+		ctx:       ctx,
+		checkFile: checkFile,
+		incoming:  incoming,
+		forward:   forward,
+	}
+	go channel.readLoop()
+	return channel
+}
+
+func (c *checkFileChannel) Read(p []byte) (int, error) {
+	return c.incoming.Read(p)
+}
+
+func (c *checkFileChannel) Write(p []byte) (int, error) {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+
+	c.outgoing = append(c.outgoing, p...)
+	for len(c.outgoing) >= 4 {
+		length := int(binary.BigEndian.Uint32(c.outgoing[:4]))
+		if len(c.outgoing) < 4+length {
+			break
+		}
+		packet := c.outgoing[:4+length]
+		if !c.versionSent && length > 0 && packet[4] == sshFxpVersion {
+			packet = appendCheckFileVersionExtension(packet)
+		}
+		c.versionSent = true
+		if _, err := c.conn.Write(packet); err != nil {
+			return 0, err
+		}
+		c.outgoing = c.outgoing[4+length:]
+	}
+	return len(p), nil
+}
+
+func (c *checkFileChannel) Close() error {
+	_ = c.incoming.Close()
+	return c.conn.Close()
+}
+
+func (c *checkFileChannel) writePacket(packet []byte) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	_, err := c.conn.Write(packet)
+	return err
+}
+
+func (c *checkFileChannel) readLoop() {
+	for {
+		header := make([]byte, 4)
+		if _, err := io.ReadFull(c.conn, header); err != nil {
+			c.closeForward(err)
+			return
+		}
+		length := binary.BigEndian.Uint32(header)
+		if length == 0 || length > maxForwardPacketLength {
+			c.closeForward(fmt.Errorf("sftp packet length %d out of range", length))
+			return
+		}
+		body := make([]byte, length)
+		if _, err := io.ReadFull(c.conn, body); err != nil {
+			c.closeForward(err)
+			return
+		}
+
+		if id, request, ok := parseCheckFileRequest(body); ok {
+			go c.handleCheckFile(id, request)
+			continue
+		}
+		if _, err := c.forward.Write(append(header, body...)); err != nil {
+			return
+		}
+	}
+}
+
+func (c *checkFileChannel) closeForward(err error) {
+	if errors.Is(err, io.EOF) {
+		_ = c.forward.Close()
+		return
+	}
+	_ = c.forward.CloseWithError(err)
+}
+
+func (c *checkFileChannel) handleCheckFile(id uint32, request []byte) {
+	filename, algorithms, err := parseCheckFileArguments(request)
+	var (
+		algo   checksum.Algorithm
+		digest []byte
+	)
+	if err == nil {
+		algo, digest, err = c.checkFile(c.ctx, filename, algorithms)
+	}
+
+	var packet []byte
+	if err != nil {
+		code := checkFileStatusCode(err)
+		packet = marshalStatusPacket(id, code, checkFileStatusMessage(code, err))
+	} else {
+		payload := binary.BigEndian.AppendUint32([]byte{sshFxpExtendedReply}, id)
+		payload = appendString(payload, checkFileExtension)
+		payload = appendString(payload, string(algo))
+		payload = append(payload, digest...)
+		packet = framePacket(payload)
+	}
+	if err := c.writePacket(packet); err != nil {
+		log.Warn().Err(err).Msg("[SFTP] failed to send check-file reply")
+	}
+}
+
+// checkFile은 Space 경로의 파일 전체 해시를 공용 해시 캐시에서 가져오거나 계산한다.
+func (h *spaceHandlers) checkFile(checksums *checksum.Service) checkFileFunc {
+	return func(ctx context.Context, filename string, algorithms []string) (checksum.Algorithm, []byte, error) {
+		algo, ok := negotiateCheckFileAlgorithm(algorithms)
+		if !ok {
+			return "", nil, errCheckFileUnsupported
+		}
+		_, absPath, relPath, err := h.resolvePath(normalizeVirtualPath(filename), account.PermissionRead)
+		if err != nil {
+			return "", nil, err
+		}
+		if relPath == "" {
+			return "", nil, os.ErrPermission
+		}
+
+		entry, err := checksums.Sum(ctx, absPath, algo, checkFileWait)
+		if err != nil {
+			return "", nil, err
+		}
+		digest, err := hex.DecodeString(entry.Digest)
+		if err != nil {
+			return "", nil, err
+		}
+		return algo, digest, nil
+	}
+}
+
+// negotiateCheckFileAlgorithm은 클라이언트가 보낸 순서대로 처음 지원하는 알고리즘을 고른다.
+func negotiateCheckFileAlgorithm(algorithms []string) (checksum.Algorithm, bool) {
+	for _, name := range algorithms {
+		if strings.TrimSpace(name) == "" {
+			continue
+		}
+		if algo, err := checksum.ParseAlgorithm(name); err == nil {
+			return algo, true
+		}
+	}
+	return "", false
+}
+
+func checkFileStatusCode(err error) uint32 {
+	switch {
+	case errors.Is(err, errCheckFileUnsupported):
+		return sshFxOpUnsupported
+	case os.IsNotExist(err):
+		return sshFxNoSuchFile
+	case os.IsPermission(err):
+		return sshFxPermissionDenied
+	default:
+		return sshFxFailure
+	}
+}
+
+// checkFileStatusMessage는 응답에 서버 파일 시스템 경로가 드러나지 않도록 상태 코드별 고정 문구를 쓴다.
+func checkFileStatusMessage(code uint32, err error) string {
+	switch code {
+	case sshFxOpUnsupported:
+		return "unsupported check-file request"
+	case sshFxNoSuchFile:
+		return "no such file"
+	case sshFxPermissionDenied:
+		return "permission denied"
+	}
+	if errors.Is(err, checksum.ErrPending) {
+		return "checksum is still being computed"
+	}
+	if errors.Is(err, checksum.ErrNotRegularFile) {
+		return "not a regular file"
+	}
+	return "failed to compute checksum"
+}
+
+// parseCheckFileRequest는 SSH_FXP_EXTENDED check-file-name 요청이면 id와 확장 인자 부분을 반환한다.
+func parseCheckFileRequest(body []byte) (uint32, []byte, bool) {
+	if len(body) < 5 || body[0] != sshFxpExtended {
+		return 0, nil, false
+	}
+	id := binary.BigEndian.Uint32(body[1:5])
+	name, rest, ok := readString(body[5:])
+	if !ok || name != checkFileNameRequest {
+		return 0, nil, false
+	}
+	return id, rest, true
+}
+
+// parseCheckFileArguments는 filename, hash-algorithm-list, start-offset, length, block-size를 읽는다.
+// 파일 전체를 한 번에 해시하는 요청(start=0, length=0, block-size=0)만 지원한다.
+func parseCheckFileArguments(request []byte) (string, []string, error) {
+	filename, rest, ok := readString(request)
+	if !ok {
+		return "", nil, errCheckFileUnsupported
+	}
+	algorithmList, rest, ok := readString(rest)
+	if !ok || len(rest) < 20 {
+		return "", nil, errCheckFileUnsupported
+	}
+	start := binary.BigEndian.Uint64(rest[0:8])
+	length := binary.BigEndian.Uint64(rest[8:16])
+	blockSize := binary.BigEndian.Uint32(rest[16:20])
+	if start != 0 || length != 0 || blockSize != 0 {
+		return "", nil, errCheckFileUnsupported
+	}
+	return filename, strings.Split(algorithmList, ","), nil
+}
+
+// appendCheckFileVersionExtension은 SSH_FXP_VERSION 응답에 check-file 확장과 지원 알고리즘을 덧붙인다.
+func appendCheckFileVersionExtension(packet []byte) []byte {
+	names := make([]string, 0, len(checksum.Algorithms))
+	for _, algo := range checksum.Algorithms {
+		names = append(names, string(algo))
+	}
+	payload := append([]byte{}, packet[4:]...)
+	payload = appendString(payload, checkFileExtension)
+	payload = appendString(payload, strings.Join(names, ","))
+	return framePacket(payload)
+}
+
+func marshalStatusPacket(id uint32, code uint32, message string) []byte {
+	payload := binary.BigEndian.AppendUint32([]byte{sshFxpStatus}, id)
+	payload = binary.BigEndian.AppendUint32(payload, code)
+	payload = appendString(payload, message)
+	payload = appendString(payload, "")
+	return framePacket(payload)
+}
+
+func framePacket(payload []byte) []byte {
+	packet := binary.BigEndian.AppendUint32(make([]byte, 0, 4+len(payload)), uint32(len(payload)))
+	return append(packet, payload...)
+}
+
+func appendString(buf []byte, value string) []byte {
+	buf = binary.BigEndian.AppendUint32(buf, uint32(len(value)))
+	return append(buf, value...)
+}
+
+func readString(buf []byte) (string, []byte, bool) {
+	if len(buf) < 4 {
+		return "", nil, false
+	}
+	length := binary.BigEndian.Uint32(buf[:4])
+	if uint64(len(buf)-4) < uint64(length) {
+		return "", nil, false
+	}
+	return string(buf[4 : 4+length]), buf[4+length:], true
+}
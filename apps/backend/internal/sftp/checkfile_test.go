@@ -0,0 +1,101 @@
+package sftp
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"io"
+	"net"
+	"os"
+	"testing"
+
+	"taeu.kr/cohesion/internal/space/checksum"
+)
+
+func readTestPacket(t *testing.T, r io.Reader) []byte {
+	t.Helper()
+
+	header := make([]byte, 4)
+	if _, err := io.ReadFull(r, header); err != nil {
+		t.Fatalf("failed to read packet header: %v", err)
+	}
+	body := make([]byte, binary.BigEndian.Uint32(header))
+	if _, err := io.ReadFull(r, body); err != nil {
+		t.Fatalf("failed to read packet body: %v", err)
+	}
+	return body
+}
+
+func checkFileRequestPacket(id uint32, filename string, algorithms string, blockSize uint32) []byte {
+	payload := binary.BigEndian.AppendUint32([]byte{sshFxpExtended}, id)
+	payload = appendString(payload, checkFileNameRequest)
+	payload = appendString(payload, filename)
+	payload = appendString(payload, algorithms)
+	payload = binary.BigEndian.AppendUint64(payload, 0)
+	payload = binary.BigEndian.AppendUint64(payload, 0)
+	payload = binary.BigEndian.AppendUint32(payload, blockSize)
+	return framePacket(payload)
+}
+
+func TestCheckFileChannel_AnswersCheckFileAndForwardsOtherPackets(t *testing.T) {
+	client, server := net.Pipe()
+	defer client.Close()
+
+	digest, _ := hex.DecodeString("5d41402abc4b2a76b9719d911017c592")
+	channel := newCheckFileChannel(context.Background(), server, func(ctx context.Context, filename string, algorithms []string) (checksum.Algorithm, []byte, error) {
+		if filename != "/Docs/hello.txt" {
+			return "", nil, os.ErrNotExist
+		}
+		algo, ok := negotiateCheckFileAlgorithm(algorithms)
+		if !ok {
+			return "", nil, errCheckFileUnsupported
+		}
+		return algo, digest, nil
+	})
+	defer channel.Close()
+
+	// pkg/sftp처럼 VERSION 패킷을 헤더와 본문으로 나눠 써도 check-file 확장이 붙어 한 패킷으로 나가야 한다.
+	version := framePacket(binary.BigEndian.AppendUint32([]byte{sshFxpVersion}, 3))
+	go func() {
+		_, _ = channel.Write(version[:5])
+		_, _ = channel.Write(version[5:])
+	}()
+	body := readTestPacket(t, client)
+	if body[0] != sshFxpVersion || !bytes.Contains(body, []byte("check-file")) || !bytes.Contains(body, []byte("sha256,sha1,md5,blake3")) {
+		t.Fatalf("expected VERSION with check-file extension, got %q", body)
+	}
+
+	go func() { _, _ = client.Write(checkFileRequestPacket(7, "/Docs/hello.txt", "crc32,md5", 0)) }()
+	body = readTestPacket(t, client)
+	expected := binary.BigEndian.AppendUint32([]byte{sshFxpExtendedReply}, 7)
+	expected = appendString(expected, checkFileExtension)
+	expected = appendString(expected, "md5")
+	expected = append(expected, digest...)
+	if !bytes.Equal(body, expected) {
+		t.Fatalf("unexpected check-file reply: %x", body)
+	}
+
+	go func() { _, _ = client.Write(checkFileRequestPacket(8, "/Docs/hello.txt", "sha256", 4096)) }()
+	body = readTestPacket(t, client)
+	if body[0] != sshFxpStatus || binary.BigEndian.Uint32(body[1:5]) != 8 || binary.BigEndian.Uint32(body[5:9]) != sshFxOpUnsupported {
+		t.Fatalf("expected OP_UNSUPPORTED status for block hashing, got %x", body)
+	}
+
+	go func() { _, _ = client.Write(checkFileRequestPacket(9, "/Docs/missing.txt", "sha256", 0)) }()
+	body = readTestPacket(t, client)
+	if body[0] != sshFxpStatus || binary.BigEndian.Uint32(body[5:9]) != sshFxNoSuchFile {
+		t.Fatalf("expected NO_SUCH_FILE status, got %x", body)
+	}
+
+	// 다른 요청은 그대로 pkg/sftp 쪽으로 넘어간다.
+	stat := framePacket(appendString(binary.BigEndian.AppendUint32([]byte{17}, 10), "/Docs"))
+	go func() { _, _ = client.Write(stat) }()
+	forwarded := make([]byte, len(stat))
+	if _, err := io.ReadFull(channel, forwarded); err != nil {
+		t.Fatalf("failed to read forwarded packet: %v", err)
+	}
+	if !bytes.Equal(forwarded, stat) {
+		t.Fatalf("unexpected forwarded packet: %x", forwarded)
+	}
+}
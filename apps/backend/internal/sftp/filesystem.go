@@ -11,14 +11,24 @@ import (
 	"time"
 
 	pkgsftp "github.com/pkg/sftp"
+	"github.com/rs/zerolog/log"
 	"taeu.kr/cohesion/internal/account"
 	"taeu.kr/cohesion/internal/space"
 )
 
+// errQuotaExceeded는 Space(용량·파일 수) 또는 사용자 할당량을 넘는 쓰기다.
+var errQuotaExceeded = errors.New("storage quota exceeded")
+
 type spaceHandlers struct {
 	spaceService   *space.Service
 	accountService *account.Service
 	username       string
+	usage          space.UsageTracker
+	userStorage    space.UserStorageTracker
+	uploadPolicy   space.UploadPolicyEnforcer
+	malwareScan    space.MalwareScanHook
+	retention      space.RetentionGuard
+	fileEvents     space.FileEventPublisher
 }
 
 func newSpaceHandlers(spaceService *space.Service, accountService *account.Service, username string) *spaceHandlers {
@@ -59,10 +69,17 @@ func (h *spaceHandlers) Fileread(req *pkgsftp.Request) (io.ReaderAt, error) {
 
 func (h *spaceHandlers) Filewrite(req *pkgsftp.Request) (io.WriterAt, error) {
 	cleanPath := normalizeVirtualPath(req.Filepath)
-	spaceObj, absPath, relPath, err := h.resolvePath(cleanPath, account.PermissionWrite)
+	spaceObj, absPath, relPath, release, err := h.resolveWritePath(cleanPath)
 	if err != nil {
 		return nil, err
 	}
+	// 핸들을 닫을 때까지 쓰기를 잡아 두고, 파일을 열지 못하면 바로 놓는다.
+	opened := false
+	defer func() {
+		if !opened {
+			release()
+		}
+	}()
 	if relPath == "" {
 		return nil, os.ErrPermission
 	}
@@ -94,12 +111,182 @@ func (h *spaceHandlers) Filewrite(req *pkgsftp.Request) (io.WriterAt, error) {
 		flags |= os.O_EXCL
 	}
 
+	if err := h.ensureMutable(spaceObj, absPath, "write"); err != nil {
+		return nil, err
+	}
+
+	maxSize, err := h.maxWriteSize(spaceObj.ID, absPath)
+	if err != nil {
+		return nil, err
+	}
+	if maxSize == 0 {
+		return nil, errQuotaExceeded
+	}
+	if err := space.EnsureCanCreateFile(context.Background(), h.usage, spaceObj.ID, absPath); err != nil {
+		var fileQuotaErr *space.FileQuotaExceededError
+		if errors.As(err, &fileQuotaErr) {
+			return nil, errQuotaExceeded
+		}
+		return nil, err
+	}
+
+	guard, err := h.beginUpload(spaceObj.ID, absPath)
+	if err != nil {
+		return nil, err
+	}
+	_, statErr := os.Stat(absPath)
+
+	commit := space.TrackUsage(context.Background(), h.usage, spaceObj.ID, absPath)
 	file, err := os.OpenFile(absPath, flags, 0644)
 	if err != nil {
 		return nil, err
 	}
 
-	return file, nil
+	opened = true
+	return &usageTrackedFile{
+		File:    file,
+		maxSize: maxSize,
+		guard:   guard,
+		created: os.IsNotExist(statErr),
+		release: release,
+		commit: func() {
+			commit()
+			h.recordOwner(spaceObj, absPath)
+		},
+		scan: func() error {
+			if err := h.scanWrittenFile(spaceObj, absPath); err != nil {
+				return err
+			}
+			h.publishFileCreated(spaceObj, absPath)
+			return nil
+		},
+	}, nil
+}
+
+// scanWrittenFile은 다 쓴 파일을 Space 검사 설정에 따라 검사한다. block 모드에서 감염이면 오류를 반환한다.
+func (h *spaceHandlers) scanWrittenFile(spaceObj *space.Space, absPath string) error {
+	if h.malwareScan == nil {
+		return nil
+	}
+	return h.malwareScan.ScanWrittenFile(context.Background(), space.MalwareScanTarget{
+		SpaceID:   spaceObj.ID,
+		SpaceRoot: spaceObj.SpacePath,
+		FilePath:  absPath,
+		Actor:     h.username,
+		Source:    "sftp",
+	})
+}
+
+// publishFileCreated는 검사를 통과한 파일의 이벤트를 낸다.
+func (h *spaceHandlers) publishFileCreated(spaceObj *space.Space, absPath string) {
+	if h.fileEvents == nil {
+		return
+	}
+	h.fileEvents.PublishFileEvent(context.Background(), space.FileEvent{
+		Kind:      space.FileEventCreated,
+		SpaceID:   spaceObj.ID,
+		SpaceRoot: spaceObj.SpacePath,
+		AbsPath:   absPath,
+		Actor:     h.username,
+		Source:    "sftp",
+		At:        time.Now().UTC(),
+	})
+}
+
+// ensureMutable은 이미 있는 absPath를 바꾸기 전에 보존 잠금을 확인한다.
+func (h *spaceHandlers) ensureMutable(spaceObj *space.Space, absPath, operation string) error {
+	if h.retention == nil {
+		return nil
+	}
+	return h.retention.EnsureMutable(context.Background(), space.RetentionTarget{
+		SpaceID:   spaceObj.ID,
+		SpaceRoot: spaceObj.SpacePath,
+		AbsPath:   absPath,
+		Operation: operation,
+		Actor:     h.username,
+		Source:    "sftp",
+	})
+}
+
+// beginUpload는 absPath 이름을 업로드 정책으로 확인하고 쓰는 내용을 검사할 guard를 반환한다.
+func (h *spaceHandlers) beginUpload(spaceID int64, absPath string) (*space.UploadWriteGuard, error) {
+	if h.uploadPolicy == nil {
+		return nil, nil
+	}
+	return h.uploadPolicy.BeginUpload(context.Background(), spaceID, filepath.Base(absPath))
+}
+
+// maxWriteSize는 Space·사용자 할당량 안에서 absPath가 가질 수 있는 최대 크기다.
+// 기존 파일 크기는 덮어쓰면 돌려받으므로 더한다. 제한이 없으면 -1이다.
+func (h *spaceHandlers) maxWriteSize(spaceID int64, absPath string) (int64, error) {
+	if h.userStorage == nil {
+		return -1, nil
+	}
+	allowance, err := h.userStorage.WriteAllowance(context.Background(), h.username, spaceID)
+	if err != nil || allowance < 0 {
+		return allowance, err
+	}
+	existing, err := space.MeasurePaths(context.Background(), absPath)
+	if err != nil {
+		return 0, err
+	}
+	return allowance + existing, nil
+}
+
+// usageTrackedFile은 닫힐 때 파일 크기를 다시 재어 연 시점과의 차이를 사용량에 알린다.
+// pkg/sftp는 WriterAt이 io.Closer이면 핸들을 닫을 때 Close를 호출한다.
+// maxSize가 0 이상이면 그 크기를 넘는 위치에는 쓰지 못한다.
+// guard가 있으면 업로드 정책에 맞지 않는 내용은 쓰지 못하고, 그렇게 새로 만든 파일은 닫을 때 지운다.
+// 내용을 쓴 파일은 사용량을 반영한 뒤 scan으로 악성 코드 검사를 요청한다.
+// release는 이 모든 처리가 끝난 뒤 Space 쓰기 기록을 놓는다.
+type usageTrackedFile struct {
+	*os.File
+	maxSize int64
+	guard   *space.UploadWriteGuard
+	created bool
+	written bool
+	commit  func()
+	scan    func() error
+	release func()
+}
+
+func (f *usageTrackedFile) WriteAt(p []byte, off int64) (int, error) {
+	if f.maxSize >= 0 && off+int64(len(p)) > f.maxSize {
+		return 0, errQuotaExceeded
+	}
+	if err := f.guard.Check(off, p); err != nil {
+		return 0, err
+	}
+	n, err := f.File.WriteAt(p, off)
+	if n > 0 {
+		f.written = true
+	}
+	return n, err
+}
+
+func (f *usageTrackedFile) Close() error {
+	if f.release != nil {
+		defer func() {
+			f.release()
+			f.release = nil
+		}()
+	}
+	err := f.File.Close()
+	if f.created && f.guard.Rejected() {
+		if removeErr := os.Remove(f.File.Name()); removeErr != nil && !os.IsNotExist(removeErr) {
+			log.Warn().Err(removeErr).Str("path", f.File.Name()).Msg("failed to remove SFTP file rejected by upload policy")
+		}
+	}
+	if f.commit != nil {
+		f.commit()
+		f.commit = nil
+	}
+	if err == nil && f.written && !f.guard.Rejected() && f.scan != nil {
+		scan := f.scan
+		f.scan = nil
+		return scan()
+	}
+	return err
 }
 
 func (h *spaceHandlers) Filecmd(req *pkgsftp.Request) error {
@@ -180,7 +367,7 @@ func (h *spaceHandlers) Filelist(req *pkgsftp.Request) (pkgsftp.ListerAt, error)
 
 		name := info.Name()
 		if relPath == "" {
-			name = spaceObj.SpaceName
+			name = spaceObj.ProtocolName()
 		}
 
 		return &fileInfoLister{entries: []os.FileInfo{&namedFileInfo{
@@ -211,10 +398,11 @@ func (h *spaceHandlers) rename(fromPath, toPath string) error {
 		return os.ErrPermission
 	}
 
-	spaceObj, err := h.resolveSpaceByName(fromSpace, account.PermissionWrite)
+	spaceObj, release, err := h.resolveWritableSpace(fromSpace)
 	if err != nil {
 		return err
 	}
+	defer release()
 
 	absFrom := filepath.Join(spaceObj.SpacePath, filepath.FromSlash(fromRel))
 	absTo := filepath.Join(spaceObj.SpacePath, filepath.FromSlash(toRel))
@@ -222,7 +410,46 @@ func (h *spaceHandlers) rename(fromPath, toPath string) error {
 		return os.ErrPermission
 	}
 
-	return os.Rename(absFrom, absTo)
+	if absFrom == absTo {
+		return os.Rename(absFrom, absTo)
+	}
+	if err := h.ensureMutable(spaceObj, absFrom, "move"); err != nil {
+		return err
+	}
+	if err := h.ensureMutable(spaceObj, absTo, "overwrite"); err != nil {
+		return err
+	}
+	if err := h.checkRenamePolicy(spaceObj.ID, absFrom, absTo); err != nil {
+		return err
+	}
+
+	// 같은 Space 안의 이동이므로 사용량은 덮어쓴 대상 크기만큼만 줄어든다.
+	replaced, measureErr := space.MeasurePathUsage(context.Background(), absTo)
+	if err := os.Rename(absFrom, absTo); err != nil {
+		return err
+	}
+	if measureErr != nil {
+		h.invalidateUsage(spaceObj.ID)
+	} else {
+		h.adjustUsage(spaceObj.ID, -replaced.Bytes, -replaced.Files)
+	}
+	if h.userStorage != nil {
+		if err := h.userStorage.MoveOwners(context.Background(), spaceObj.ID, spaceObj.SpacePath, absFrom, spaceObj.ID, spaceObj.SpacePath, absTo); err != nil {
+			log.Warn().Err(err).Str("path", absFrom).Msg("failed to move SFTP file owners")
+		}
+	}
+	return nil
+}
+
+// checkRenamePolicy는 파일 확장자를 바꾸는 이름 변경이면 새 이름을 업로드 정책으로 확인한다.
+func (h *spaceHandlers) checkRenamePolicy(spaceID int64, absFrom, absTo string) error {
+	if h.uploadPolicy == nil || strings.EqualFold(filepath.Ext(absFrom), filepath.Ext(absTo)) {
+		return nil
+	}
+	if info, err := os.Stat(absFrom); err != nil || info.IsDir() {
+		return nil
+	}
+	return h.uploadPolicy.CheckName(context.Background(), spaceID, filepath.Base(absTo))
 }
 
 func (h *spaceHandlers) deleteDir(virtualPath string) error {
@@ -231,10 +458,11 @@ func (h *spaceHandlers) deleteDir(virtualPath string) error {
 		return os.ErrPermission
 	}
 
-	_, absPath, relPath, err := h.resolvePath(cleanPath, account.PermissionWrite)
+	spaceObj, absPath, relPath, release, err := h.resolveWritePath(cleanPath)
 	if err != nil {
 		return err
 	}
+	defer release()
 	if relPath == "" {
 		return os.ErrPermission
 	}
@@ -246,16 +474,20 @@ func (h *spaceHandlers) deleteDir(virtualPath string) error {
 	if !info.IsDir() {
 		return errors.New("not a directory")
 	}
+	if err := h.ensureMutable(spaceObj, absPath, "delete"); err != nil {
+		return err
+	}
 
 	return os.Remove(absPath)
 }
 
 func (h *spaceHandlers) deleteFile(virtualPath string) error {
 	cleanPath := normalizeVirtualPath(virtualPath)
-	_, absPath, relPath, err := h.resolvePath(cleanPath, account.PermissionWrite)
+	spaceObj, absPath, relPath, release, err := h.resolveWritePath(cleanPath)
 	if err != nil {
 		return err
 	}
+	defer release()
 	if relPath == "" {
 		return os.ErrPermission
 	}
@@ -267,16 +499,50 @@ func (h *spaceHandlers) deleteFile(virtualPath string) error {
 	if info.IsDir() {
 		return errors.New("not a file")
 	}
+	if err := h.ensureMutable(spaceObj, absPath, "delete"); err != nil {
+		return err
+	}
 
-	return os.Remove(absPath)
+	if err := os.Remove(absPath); err != nil {
+		return err
+	}
+	h.adjustUsage(spaceObj.ID, -info.Size(), -1)
+	if h.userStorage != nil {
+		if err := h.userStorage.DeleteOwners(context.Background(), spaceObj.ID, spaceObj.SpacePath, absPath); err != nil {
+			log.Warn().Err(err).Str("path", absPath).Msg("failed to delete SFTP file owners")
+		}
+	}
+	return nil
+}
+
+func (h *spaceHandlers) recordOwner(spaceObj *space.Space, absPath string) {
+	if h.userStorage == nil {
+		return
+	}
+	if err := h.userStorage.RecordOwner(context.Background(), h.username, spaceObj.ID, spaceObj.SpacePath, absPath); err != nil {
+		log.Warn().Err(err).Str("path", absPath).Msg("failed to record SFTP file owner")
+	}
+}
+
+func (h *spaceHandlers) adjustUsage(spaceID int64, deltaBytes int64, deltaFiles int64) {
+	if h.usage != nil {
+		h.usage.AdjustUsage(context.Background(), spaceID, deltaBytes, deltaFiles)
+	}
+}
+
+func (h *spaceHandlers) invalidateUsage(spaceID int64) {
+	if h.usage != nil {
+		h.usage.Invalidate(spaceID)
+	}
 }
 
 func (h *spaceHandlers) makeDir(virtualPath string) error {
 	cleanPath := normalizeVirtualPath(virtualPath)
-	spaceObj, absPath, relPath, err := h.resolvePath(cleanPath, account.PermissionWrite)
+	spaceObj, absPath, relPath, release, err := h.resolveWritePath(cleanPath)
 	if err != nil {
 		return err
 	}
+	defer release()
 	if relPath == "" || !isPathWithinSpace(absPath, spaceObj.SpacePath) {
 		return os.ErrPermission
 	}
@@ -291,6 +557,9 @@ func (h *spaceHandlers) listAccessibleSpaces() ([]os.FileInfo, error) {
 
 	entries := make([]os.FileInfo, 0, len(spaces))
 	for _, sp := range spaces {
+		if !sp.IsListed() {
+			continue
+		}
 		allowed, err := h.accountService.CanAccessSpaceByID(context.Background(), h.username, sp.ID, account.PermissionRead)
 		if err != nil {
 			return nil, err
@@ -303,7 +572,7 @@ func (h *spaceHandlers) listAccessibleSpaces() ([]os.FileInfo, error) {
 		if stat, err := os.Stat(sp.SpacePath); err == nil {
 			modTime = stat.ModTime()
 		}
-		entries = append(entries, newVirtualDirInfo(sp.SpaceName, modTime))
+		entries = append(entries, newVirtualDirInfo(sp.ProtocolName(), modTime))
 	}
 
 	return entries, nil
@@ -319,20 +588,56 @@ func (h *spaceHandlers) resolvePath(cleanPath string, required account.Permissio
 	if err != nil {
 		return nil, "", "", err
 	}
+	absPath, err := joinSpacePath(spaceObj, relPath)
+	if err != nil {
+		return nil, "", "", err
+	}
+	return spaceObj, absPath, relPath, nil
+}
+
+// resolveWritePath는 resolvePath와 같지만 쓰기를 진행 중으로 기록하고, 쓰기가 끝나면 호출할 함수를 함께 반환한다.
+func (h *spaceHandlers) resolveWritePath(cleanPath string) (*space.Space, string, string, func(), error) {
+	spaceName, relPath, err := splitVirtualPath(cleanPath)
+	if err != nil {
+		return nil, "", "", nil, err
+	}
+
+	spaceObj, release, err := h.resolveWritableSpace(spaceName)
+	if err != nil {
+		return nil, "", "", nil, err
+	}
+	absPath, err := joinSpacePath(spaceObj, relPath)
+	if err != nil {
+		release()
+		return nil, "", "", nil, err
+	}
+	return spaceObj, absPath, relPath, release, nil
+}
+
+// resolveWritableSpace는 쓰기 권한을 확인하고 저장소 이전이 root를 바꾸지 못하도록 쓰기를 진행 중으로 기록한다.
+// 경로는 반환한 Space의 root로 정해야 한다.
+func (h *spaceHandlers) resolveWritableSpace(spaceName string) (*space.Space, func(), error) {
+	spaceObj, err := h.resolveSpaceByName(spaceName, account.PermissionWrite)
+	if err != nil {
+		return nil, nil, err
+	}
+	return h.spaceService.BeginWrite(context.Background(), spaceObj.ID)
+}
 
+func joinSpacePath(spaceObj *space.Space, relPath string) (string, error) {
 	absPath := spaceObj.SpacePath
 	if relPath != "" {
 		absPath = filepath.Join(spaceObj.SpacePath, filepath.FromSlash(relPath))
 	}
 	if !isPathWithinSpace(absPath, spaceObj.SpacePath) {
-		return nil, "", "", os.ErrPermission
+		return "", os.ErrPermission
 	}
-
-	return spaceObj, absPath, relPath, nil
+	return absPath, nil
 }
 
 func (h *spaceHandlers) resolveSpaceByName(spaceName string, required account.Permission) (*space.Space, error) {
-	spaceObj, err := h.spaceService.GetSpaceByName(context.Background(), spaceName)
+	// 이름 변경 전 경로(alias)도 투명하게 같은 Space로 해석한다.
+	spaceObj, _, err := h.spaceService.ResolveSpaceByProtocolName(context.Background(), spaceName)
 	if err != nil {
 		return nil, os.ErrNotExist
 	}
@@ -344,6 +649,18 @@ func (h *spaceHandlers) resolveSpaceByName(spaceName string, required account.Pe
 	if !allowed {
 		return nil, os.ErrPermission
 	}
+	if err := h.spaceService.EnsureOnline(spaceObj.ID); err != nil {
+		return nil, err
+	}
+	if spaceObj.State() == space.SpaceStateMaintenance {
+		isAdmin, err := h.accountService.IsAdmin(context.Background(), h.username)
+		if err != nil {
+			return nil, err
+		}
+		if err := spaceObj.CheckReadable(isAdmin); err != nil {
+			return nil, err
+		}
+	}
 
 	return spaceObj, nil
 }
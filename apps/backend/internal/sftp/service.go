@@ -24,6 +24,7 @@ import (
 	"taeu.kr/cohesion/internal/account"
 	"taeu.kr/cohesion/internal/config"
 	"taeu.kr/cohesion/internal/space"
+	"taeu.kr/cohesion/internal/space/checksum"
 )
 
 const (
@@ -43,6 +44,20 @@ type Service struct {
 	port           int
 	running        bool
 	mu             sync.RWMutex
+	// checksums가 있으면 check-file-name 확장 요청에 공용 해시 캐시로 응답한다.
+	checksums *checksum.Service
+	// usage가 있으면 업로드/삭제로 바뀐 크기를 Space 사용량 카운터에 알린다.
+	usage space.UsageTracker
+	// userStorage가 있으면 할당량을 넘는 쓰기를 막고 쓴 파일의 소유자를 기록한다.
+	userStorage space.UserStorageTracker
+	// uploadPolicy가 있으면 Space 업로드 정책에 맞지 않는 파일을 쓰지 못하게 한다.
+	uploadPolicy space.UploadPolicyEnforcer
+	// malwareScan이 있으면 다 쓴 파일을 Space 검사 설정에 따라 검사한다.
+	malwareScan space.MalwareScanHook
+	// fileEvents가 있으면 다 쓴 파일의 이벤트를 내 자동 정리 규칙 같은 후속 처리를 맡긴다.
+	fileEvents space.FileEventPublisher
+	// retention이 있으면 보존 잠금이 걸린 폴더의 기존 파일을 덮어쓰거나 옮기거나 지우지 못하게 한다.
+	retention space.RetentionGuard
 }
 
 type HostKeyPrewarmResult struct {
@@ -63,6 +78,41 @@ func NewService(spaceService *space.Service, accountService *account.Service, en
 	}
 }
 
+// SetChecksumService는 SFTP check-file 확장이 REST/WebDAV와 같은 해시 캐시를 쓰도록 설정한다.
+func (s *Service) SetChecksumService(checksums *checksum.Service) {
+	s.checksums = checksums
+}
+
+// SetUsageTracker는 SFTP로 바뀐 파일 크기를 Space 사용량 카운터에 반영하도록 설정한다.
+func (s *Service) SetUsageTracker(tracker space.UsageTracker) {
+	s.usage = tracker
+}
+
+// SetUserStorageTracker는 SFTP 쓰기에 Space·사용자 할당량을 적용하고 파일 소유자를 기록하도록 설정한다.
+func (s *Service) SetUserStorageTracker(tracker space.UserStorageTracker) {
+	s.userStorage = tracker
+}
+
+// SetUploadPolicyEnforcer는 SFTP 쓰기와 이름 변경에 Space 업로드 정책을 적용하도록 설정한다.
+func (s *Service) SetUploadPolicyEnforcer(enforcer space.UploadPolicyEnforcer) {
+	s.uploadPolicy = enforcer
+}
+
+// SetMalwareScanHook은 SFTP로 다 쓴 파일을 Space 검사 설정에 따라 검사하도록 설정한다.
+func (s *Service) SetMalwareScanHook(hook space.MalwareScanHook) {
+	s.malwareScan = hook
+}
+
+// SetFileEventPublisher는 SFTP로 다 쓴 파일의 이벤트를 publisher로 내도록 설정한다.
+func (s *Service) SetFileEventPublisher(publisher space.FileEventPublisher) {
+	s.fileEvents = publisher
+}
+
+// SetRetentionGuard는 SFTP 쓰기·이름 변경·삭제에 폴더별 보존 잠금을 적용하도록 설정한다.
+func (s *Service) SetRetentionGuard(guard space.RetentionGuard) {
+	s.retention = guard
+}
+
 func (s *Service) Start() error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -159,7 +209,17 @@ func (s *Service) passwordHandler(ctx gliderssh.Context, password string) bool {
 
 func (s *Service) handleSFTPSubsystem(session gliderssh.Session) {
 	handlers := newSpaceHandlers(s.spaceService, s.accountService, session.User())
-	requestServer := pkgsftp.NewRequestServer(session, pkgsftp.Handlers{
+	handlers.usage = s.usage
+	handlers.userStorage = s.userStorage
+	handlers.uploadPolicy = s.uploadPolicy
+	handlers.malwareScan = s.malwareScan
+	handlers.retention = s.retention
+	handlers.fileEvents = s.fileEvents
+	var channel io.ReadWriteCloser = session
+	if s.checksums != nil {
+		channel = newCheckFileChannel(session.Context(), session, handlers.checkFile(s.checksums))
+	}
+	requestServer := pkgsftp.NewRequestServer(channel, pkgsftp.Handlers{
 		FileGet:  handlers,
 		FilePut:  handlers,
 		FileCmd:  handlers,
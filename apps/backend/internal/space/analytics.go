@@ -0,0 +1,226 @@
+package space
+
+import (
+	"mime"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultAnalyticsTop = 20
+	MaxAnalyticsTop     = 100
+)
+
+// 파일 분류. 내장 확장자 목록에 있으면 그 분류를, 없으면 MIME 상위 타입으로 정합니다.
+const (
+	FileClassImage    = "image"
+	FileClassVideo    = "video"
+	FileClassAudio    = "audio"
+	FileClassDocument = "document"
+	FileClassArchive  = "archive"
+	FileClassCode     = "code"
+	FileClassText     = "text"
+	FileClassOther    = "other"
+)
+
+var fileClassByExtension = map[string]string{
+	".pdf": FileClassDocument, ".doc": FileClassDocument, ".docx": FileClassDocument,
+	".xls": FileClassDocument, ".xlsx": FileClassDocument, ".ppt": FileClassDocument,
+	".pptx": FileClassDocument, ".odt": FileClassDocument, ".ods": FileClassDocument,
+	".odp": FileClassDocument, ".hwp": FileClassDocument, ".hwpx": FileClassDocument,
+	".rtf": FileClassDocument, ".epub": FileClassDocument,
+	".zip": FileClassArchive, ".tar": FileClassArchive, ".gz": FileClassArchive,
+	".tgz": FileClassArchive, ".bz2": FileClassArchive, ".xz": FileClassArchive,
+	".7z": FileClassArchive, ".rar": FileClassArchive, ".zst": FileClassArchive,
+	".iso": FileClassArchive, ".dmg": FileClassArchive,
+	".go": FileClassCode, ".js": FileClassCode, ".ts": FileClassCode, ".tsx": FileClassCode,
+	".jsx": FileClassCode, ".vue": FileClassCode, ".py": FileClassCode, ".java": FileClassCode,
+	".kt": FileClassCode, ".c": FileClassCode, ".h": FileClassCode, ".cpp": FileClassCode,
+	".rs": FileClassCode, ".rb": FileClassCode, ".php": FileClassCode, ".sh": FileClassCode,
+	".sql": FileClassCode, ".swift": FileClassCode, ".cs": FileClassCode,
+	".mkv": FileClassVideo, ".heic": FileClassImage, ".raw": FileClassImage, ".flac": FileClassAudio,
+}
+
+// 나이 구간. 수정 시각 기준이며 구간의 상한(미포함)이 작은 순서입니다.
+var analyticsAgeBuckets = []struct {
+	name   string
+	maxAge time.Duration
+}{
+	{name: "week", maxAge: 7 * 24 * time.Hour},
+	{name: "month", maxAge: 30 * 24 * time.Hour},
+	{name: "quarter", maxAge: 90 * 24 * time.Hour},
+	{name: "year", maxAge: 365 * 24 * time.Hour},
+	{name: "older"},
+}
+
+// StorageAnalytics는 Space 하나의 용량 분석 결과입니다.
+type StorageAnalytics struct {
+	SpaceID        int64                `json:"spaceId"`
+	TotalBytes     int64                `json:"totalBytes"`
+	FileCount      int64                `json:"fileCount"`
+	DirectoryCount int64                `json:"directoryCount"`
+	LargestFiles   []AnalyticsItem      `json:"largestFiles"`
+	LargestFolders []AnalyticsItem      `json:"largestFolders"`
+	ByExtension    []AnalyticsBreakdown `json:"byExtension"`
+	ByClass        []AnalyticsBreakdown `json:"byClass"`
+	ByAge          []AnalyticsBreakdown `json:"byAge"`
+}
+
+type AnalyticsItem struct {
+	Path  string `json:"path"`
+	Size  int64  `json:"size"`
+	Files int64  `json:"files,omitempty"`
+	// ModTime은 파일 항목에만 있습니다.
+	ModTime *time.Time `json:"modTime,omitempty"`
+}
+
+type AnalyticsBreakdown struct {
+	Key   string `json:"key"`
+	Bytes int64  `json:"bytes"`
+	Count int64  `json:"count"`
+}
+
+// ClassifyFile은 파일 이름으로 분류를 정합니다.
+func ClassifyFile(name string) string {
+	ext := strings.ToLower(path.Ext(name))
+	if ext == "" {
+		return FileClassOther
+	}
+	if class, ok := fileClassByExtension[ext]; ok {
+		return class
+	}
+	mediaType := mime.TypeByExtension(ext)
+	if mediaType == "" {
+		return FileClassOther
+	}
+	switch major, _, _ := strings.Cut(mediaType, "/"); major {
+	case "image":
+		return FileClassImage
+	case "video":
+		return FileClassVideo
+	case "audio":
+		return FileClassAudio
+	case "text":
+		return FileClassText
+	}
+	return FileClassOther
+}
+
+// BuildStorageAnalytics는 색인 항목(또는 직접 스캔 결과)으로 용량 분석을 만듭니다.
+// 폴더 크기는 하위 파일 크기의 합이며, top은 가장 큰 파일·폴더·확장자 목록의 길이입니다.
+func BuildStorageAnalytics(spaceID int64, entries []SearchIndexEntry, now time.Time, top int) *StorageAnalytics {
+	if top <= 0 {
+		top = DefaultAnalyticsTop
+	}
+	if top > MaxAnalyticsTop {
+		top = MaxAnalyticsTop
+	}
+
+	result := &StorageAnalytics{SpaceID: spaceID}
+	files := make([]AnalyticsItem, 0, len(entries))
+	folders := make(map[string]*AnalyticsItem)
+	byExtension := make(map[string]*AnalyticsBreakdown)
+	byClass := make(map[string]*AnalyticsBreakdown)
+	byAge := make([]AnalyticsBreakdown, len(analyticsAgeBuckets))
+	for i, bucket := range analyticsAgeBuckets {
+		byAge[i].Key = bucket.name
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir {
+			result.DirectoryCount++
+			continue
+		}
+		result.FileCount++
+		result.TotalBytes += entry.Size
+		modTime := entry.ModTime
+		files = append(files, AnalyticsItem{Path: entry.Path, Size: entry.Size, ModTime: &modTime})
+
+		for parent := entry.ParentPath; parent != "" && parent != "."; parent = path.Dir(parent) {
+			folder, ok := folders[parent]
+			if !ok {
+				folder = &AnalyticsItem{Path: parent}
+				folders[parent] = folder
+			}
+			folder.Size += entry.Size
+			folder.Files++
+		}
+
+		ext := strings.ToLower(path.Ext(entry.Name))
+		if ext == "" {
+			ext = "(none)"
+		}
+		addBreakdown(byExtension, ext, entry.Size)
+		addBreakdown(byClass, ClassifyFile(entry.Name), entry.Size)
+
+		age := now.Sub(entry.ModTime)
+		for i, bucket := range analyticsAgeBuckets {
+			if bucket.maxAge == 0 || age < bucket.maxAge {
+				byAge[i].Bytes += entry.Size
+				byAge[i].Count++
+				break
+			}
+		}
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		if files[i].Size != files[j].Size {
+			return files[i].Size > files[j].Size
+		}
+		return files[i].Path < files[j].Path
+	})
+	result.LargestFiles = truncateAnalyticsItems(files, top)
+
+	folderList := make([]AnalyticsItem, 0, len(folders))
+	for _, folder := range folders {
+		folderList = append(folderList, *folder)
+	}
+	sort.Slice(folderList, func(i, j int) bool {
+		if folderList[i].Size != folderList[j].Size {
+			return folderList[i].Size > folderList[j].Size
+		}
+		return folderList[i].Path < folderList[j].Path
+	})
+	result.LargestFolders = truncateAnalyticsItems(folderList, top)
+
+	result.ByExtension = sortedBreakdown(byExtension)
+	if len(result.ByExtension) > top {
+		result.ByExtension = result.ByExtension[:top]
+	}
+	result.ByClass = sortedBreakdown(byClass)
+	result.ByAge = byAge
+	return result
+}
+
+func addBreakdown(target map[string]*AnalyticsBreakdown, key string, size int64) {
+	item, ok := target[key]
+	if !ok {
+		item = &AnalyticsBreakdown{Key: key}
+		target[key] = item
+	}
+	item.Bytes += size
+	item.Count++
+}
+
+func sortedBreakdown(source map[string]*AnalyticsBreakdown) []AnalyticsBreakdown {
+	items := make([]AnalyticsBreakdown, 0, len(source))
+	for _, item := range source {
+		items = append(items, *item)
+	}
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].Bytes != items[j].Bytes {
+			return items[i].Bytes > items[j].Bytes
+		}
+		return items[i].Key < items[j].Key
+	})
+	return items
+}
+
+func truncateAnalyticsItems(items []AnalyticsItem, top int) []AnalyticsItem {
+	if len(items) > top {
+		return items[:top]
+	}
+	return items
+}
@@ -0,0 +1,81 @@
+package space_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"taeu.kr/cohesion/internal/space"
+)
+
+func TestStorageAnalytics_FromSearchIndexEntries(t *testing.T) {
+	manager, _, db := setupSearchIndexManager(t)
+	defer db.Close()
+
+	root := t.TempDir()
+	now := time.Now()
+	files := map[string]struct {
+		size int
+		age  time.Duration
+	}{
+		"photos/2024/a.jpg":  {size: 400, age: 2 * 24 * time.Hour},
+		"photos/2024/b.JPG":  {size: 300, age: 40 * 24 * time.Hour},
+		"docs/report.pdf":    {size: 200, age: 200 * 24 * time.Hour},
+		"docs/notes.txt":     {size: 50, age: 500 * 24 * time.Hour},
+		"Makefile":           {size: 10, age: time.Hour},
+		".cohesion_trash/x":  {size: 9000, age: time.Hour},
+		"photos/.thumbs/a.c": {size: 9000, age: time.Hour},
+	}
+	for name, spec := range files {
+		absPath := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(absPath), 0o755); err != nil {
+			t.Fatalf("create dir: %v", err)
+		}
+		if err := os.WriteFile(absPath, make([]byte, spec.size), 0o644); err != nil {
+			t.Fatalf("create %s: %v", name, err)
+		}
+		modTime := now.Add(-spec.age)
+		if err := os.Chtimes(absPath, modTime, modTime); err != nil {
+			t.Fatalf("touch %s: %v", name, err)
+		}
+	}
+	spaceID := insertSearchSpace(t, db, "Media", root)
+
+	// 색인이 dirty여도 SpaceEntries가 먼저 다시 만든다.
+	entries, err := manager.SpaceEntries(context.Background(), spaceID)
+	if err != nil {
+		t.Fatalf("list index entries: %v", err)
+	}
+
+	analytics := space.BuildStorageAnalytics(spaceID, entries, now, 2)
+	if analytics.TotalBytes != 960 || analytics.FileCount != 5 || analytics.DirectoryCount != 3 {
+		t.Fatalf("unexpected totals: %+v", analytics)
+	}
+	if len(analytics.LargestFiles) != 2 || analytics.LargestFiles[0].Path != "photos/2024/a.jpg" || analytics.LargestFiles[1].Path != "photos/2024/b.JPG" {
+		t.Fatalf("unexpected largest files: %+v", analytics.LargestFiles)
+	}
+	// photos와 photos/2024는 같은 700바이트이므로 경로 순서로 정렬된다.
+	if len(analytics.LargestFolders) != 2 || analytics.LargestFolders[0].Path != "photos" || analytics.LargestFolders[0].Size != 700 || analytics.LargestFolders[0].Files != 2 {
+		t.Fatalf("unexpected largest folders: %+v", analytics.LargestFolders)
+	}
+	if len(analytics.ByExtension) != 2 || analytics.ByExtension[0].Key != ".jpg" || analytics.ByExtension[0].Count != 2 {
+		t.Fatalf("unexpected extension breakdown: %+v", analytics.ByExtension)
+	}
+
+	byClass := make(map[string]int64)
+	for _, item := range analytics.ByClass {
+		byClass[item.Key] = item.Bytes
+	}
+	if byClass[space.FileClassImage] != 700 || byClass[space.FileClassDocument] != 200 || byClass[space.FileClassText] != 50 || byClass[space.FileClassOther] != 10 {
+		t.Fatalf("unexpected class breakdown: %+v", analytics.ByClass)
+	}
+
+	expectedAge := map[string]int64{"week": 410, "month": 0, "quarter": 300, "year": 200, "older": 50}
+	for _, bucket := range analytics.ByAge {
+		if bucket.Bytes != expectedAge[bucket.Key] {
+			t.Fatalf("unexpected age bucket %s: %+v", bucket.Key, analytics.ByAge)
+		}
+	}
+}
@@ -0,0 +1,400 @@
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultMaxEntries는 압축 파일 하나를 폴더처럼 탐색할 때 허용하는 항목 수입니다.
+const DefaultMaxEntries = 20000
+
+var (
+	ErrTooManyEntries = errors.New("archive has too many entries to browse")
+	ErrNotDirectory   = errors.New("archive entry is not a directory")
+	ErrIsDirectory    = errors.New("archive entry is a directory")
+)
+
+// Index는 압축 파일 항목을 폴더 구조로 정리한 읽기 전용 목록입니다.
+// 항목 이름은 "."(압축 파일 자신)을 기준으로 한 슬래시 구분 경로입니다.
+type Index struct {
+	Format   Format
+	root     Member
+	members  map[string]Member
+	children map[string][]string
+}
+
+// BuildIndex는 압축 파일 전체를 훑어 Index를 만듭니다.
+// 경로가 밖으로 벗어나거나 풀 수 없는 항목은 목록에 넣지 않습니다.
+// 항목 수가 maxEntries를 넘으면 ErrTooManyEntries입니다.
+func BuildIndex(ctx context.Context, absPath string, format Format, maxEntries int) (*Index, error) {
+	info, err := os.Stat(absPath)
+	if err != nil {
+		return nil, err
+	}
+	if maxEntries <= 0 {
+		maxEntries = DefaultMaxEntries
+	}
+
+	index := &Index{
+		Format:   format,
+		root:     Member{Name: ".", IsDir: true, Mode: 0o555, ModTime: info.ModTime()},
+		members:  make(map[string]Member),
+		children: make(map[string][]string),
+	}
+	err = Walk(ctx, absPath, format, func(member Member, _ OpenFunc) error {
+		if member.Unsafe || member.Unsupported {
+			return nil
+		}
+		if len(index.members) >= maxEntries {
+			return ErrTooManyEntries
+		}
+		index.add(member)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(index.members) > maxEntries {
+		return nil, ErrTooManyEntries
+	}
+
+	for dir := range index.children {
+		names := index.children[dir]
+		sort.Slice(names, func(i, j int) bool {
+			left, right := index.members[names[i]], index.members[names[j]]
+			if left.IsDir != right.IsDir {
+				return left.IsDir
+			}
+			return names[i] < names[j]
+		})
+	}
+	return index, nil
+}
+
+// add는 항목과, 목록에 따로 없는 상위 폴더를 함께 등록합니다. 같은 이름은 나중 항목이 이깁니다.
+func (idx *Index) add(member Member) {
+	if _, exists := idx.members[member.Name]; !exists {
+		parent := path.Dir(member.Name)
+		idx.children[parent] = append(idx.children[parent], member.Name)
+		if parent != "." {
+			if _, ok := idx.members[parent]; !ok {
+				idx.add(Member{Name: parent, IsDir: true, Mode: 0o555, ModTime: idx.root.ModTime})
+			}
+		}
+	}
+	idx.members[member.Name] = member
+}
+
+// Len은 폴더를 포함한 항목 수입니다.
+func (idx *Index) Len() int {
+	return len(idx.members)
+}
+
+// Lookup은 항목을 찾습니다. 빈 이름이나 "."은 압축 파일 자신(루트 폴더)입니다.
+func (idx *Index) Lookup(name string) (Member, bool) {
+	name = cleanInnerPath(name)
+	if name == "." {
+		return idx.root, true
+	}
+	member, ok := idx.members[name]
+	return member, ok
+}
+
+// List는 폴더 바로 아래 항목을 폴더 먼저, 이름순으로 반환합니다.
+func (idx *Index) List(dir string) ([]Member, error) {
+	member, ok := idx.Lookup(dir)
+	if !ok {
+		return nil, os.ErrNotExist
+	}
+	if !member.IsDir {
+		return nil, ErrNotDirectory
+	}
+	names := idx.children[cleanInnerPath(dir)]
+	members := make([]Member, 0, len(names))
+	for _, name := range names {
+		members = append(members, idx.members[name])
+	}
+	return members, nil
+}
+
+func cleanInnerPath(name string) string {
+	return path.Clean(strings.Trim(strings.ReplaceAll(name, "\\", "/"), "/"))
+}
+
+// Open은 항목 하나의 내용을 엽니다. tar 계열은 앞에서부터 읽어 해당 항목까지 건너뜁니다.
+func Open(absPath string, format Format, name string) (io.ReadCloser, error) {
+	name = cleanInnerPath(name)
+	if format == FormatZip {
+		return openZipMember(absPath, name)
+	}
+
+	reader, closeStream, err := openTarStream(absPath, format)
+	if err != nil {
+		return nil, err
+	}
+	tarReader := tar.NewReader(reader)
+	for {
+		header, err := tarReader.Next()
+		if errors.Is(err, io.EOF) {
+			closeStream()
+			return nil, os.ErrNotExist
+		}
+		if err != nil {
+			closeStream()
+			return nil, fmt.Errorf("invalid tar archive: %w", err)
+		}
+		memberName, err := SanitizeMemberName(decodeLegacyName(header.Name))
+		if err != nil || memberName != name {
+			continue
+		}
+		if header.Typeflag == tar.TypeDir {
+			closeStream()
+			return nil, ErrIsDirectory
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		return &memberReader{Reader: io.LimitReader(tarReader, header.Size), close: closeStream}, nil
+	}
+}
+
+func openZipMember(absPath string, name string) (io.ReadCloser, error) {
+	zipReader, err := zip.OpenReader(absPath)
+	if err != nil {
+		return nil, fmt.Errorf("invalid zip archive: %w", err)
+	}
+	// 같은 이름이 여러 번 있으면 목록(Index)과 같게 마지막 항목을 엽니다.
+	var found *zip.File
+	for _, file := range zipReader.File {
+		memberName, err := SanitizeMemberName(decodeZipEntryName(file))
+		if err == nil && memberName == name {
+			found = file
+		}
+	}
+	if found == nil {
+		zipReader.Close()
+		return nil, os.ErrNotExist
+	}
+	if found.FileInfo().IsDir() {
+		zipReader.Close()
+		return nil, ErrIsDirectory
+	}
+	content, err := found.Open()
+	if err != nil {
+		zipReader.Close()
+		return nil, err
+	}
+	return &memberReader{Reader: content, close: func() {
+		content.Close()
+		zipReader.Close()
+	}}, nil
+}
+
+type memberReader struct {
+	io.Reader
+	close func()
+}
+
+func (r *memberReader) Close() error {
+	r.close()
+	return nil
+}
+
+// SplitPath는 root 기준 상대 경로에서 압축 파일까지의 경로와 그 안의 경로를 나눕니다.
+// 예: "deliverables/v1.zip/docs/a.txt" → (".../deliverables/v1.zip", "docs/a.txt").
+// 압축 파일 자신을 가리키면 inner는 "."입니다. 경로 중간에 압축 파일이 없으면 ok=false입니다.
+func SplitPath(root string, relPath string) (archivePath string, inner string, format Format, ok bool) {
+	segments := strings.Split(strings.Trim(filepath.ToSlash(relPath), "/"), "/")
+	current := root
+	for i, segment := range segments {
+		if segment == "" || segment == "." {
+			continue
+		}
+		current = filepath.Join(current, segment)
+		detected, isArchive := DetectFormat(segment)
+		if !isArchive {
+			continue
+		}
+		info, err := os.Stat(current)
+		if err != nil {
+			return "", "", "", false
+		}
+		if !info.Mode().IsRegular() {
+			continue
+		}
+		return current, cleanInnerPath(strings.Join(segments[i+1:], "/")), detected, true
+	}
+	return "", "", "", false
+}
+
+// Cache는 최근에 연 압축 파일의 Index를 보관합니다.
+// 압축 파일의 크기나 수정 시각이 바뀌면 다시 읽습니다.
+type Cache struct {
+	mu         sync.Mutex
+	capacity   int
+	maxEntries int
+	items      map[string]*cachedIndex
+	order      []string
+}
+
+type cachedIndex struct {
+	size    int64
+	modTime time.Time
+	index   *Index
+}
+
+// NewCache는 Index를 capacity개까지 보관하는 Cache를 만듭니다.
+func NewCache(capacity int, maxEntries int) *Cache {
+	if capacity <= 0 {
+		capacity = 16
+	}
+	return &Cache{
+		capacity:   capacity,
+		maxEntries: maxEntries,
+		items:      make(map[string]*cachedIndex),
+	}
+}
+
+// Index는 보관 중인 Index를 반환하고, 없거나 오래되었으면 새로 만듭니다.
+func (c *Cache) Index(ctx context.Context, absPath string, format Format) (*Index, error) {
+	info, err := os.Stat(absPath)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	cached, ok := c.items[absPath]
+	c.mu.Unlock()
+	if ok && cached.size == info.Size() && cached.modTime.Equal(info.ModTime()) && cached.index.Format == format {
+		return cached.index, nil
+	}
+
+	index, err := BuildIndex(ctx, absPath, format, c.maxEntries)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, exists := c.items[absPath]; !exists {
+		c.order = append(c.order, absPath)
+	}
+	c.items[absPath] = &cachedIndex{size: info.Size(), modTime: info.ModTime(), index: index}
+	for len(c.order) > c.capacity {
+		delete(c.items, c.order[0])
+		c.order = c.order[1:]
+	}
+	return index, nil
+}
+
+// MemberFile은 항목 내용을 io.ReadSeeker로 제공합니다.
+// 압축 스트림은 되감을 수 없으므로 뒤로 이동하면 항목을 처음부터 다시 엽니다.
+type MemberFile struct {
+	absPath      string
+	format       Format
+	member       Member
+	offset       int64
+	reader       io.ReadCloser
+	readerOffset int64
+}
+
+// NewMemberFile은 항목을 실제로 읽을 때 여는 MemberFile을 만듭니다.
+func NewMemberFile(absPath string, format Format, member Member) *MemberFile {
+	return &MemberFile{absPath: absPath, format: format, member: member}
+}
+
+func (f *MemberFile) Read(p []byte) (int, error) {
+	if f.offset >= f.member.Size {
+		return 0, io.EOF
+	}
+	if f.reader == nil || f.readerOffset > f.offset {
+		if err := f.reopen(); err != nil {
+			return 0, err
+		}
+	}
+	if f.readerOffset < f.offset {
+		skipped, err := io.CopyN(io.Discard, f.reader, f.offset-f.readerOffset)
+		f.readerOffset += skipped
+		if err != nil {
+			return 0, err
+		}
+	}
+	n, err := f.reader.Read(p)
+	f.offset += int64(n)
+	f.readerOffset += int64(n)
+	return n, err
+}
+
+func (f *MemberFile) Seek(offset int64, whence int) (int64, error) {
+	var next int64
+	switch whence {
+	case io.SeekStart:
+		next = offset
+	case io.SeekCurrent:
+		next = f.offset + offset
+	case io.SeekEnd:
+		next = f.member.Size + offset
+	default:
+		return 0, errors.New("invalid whence")
+	}
+	if next < 0 {
+		return 0, errors.New("negative position")
+	}
+	f.offset = next
+	return next, nil
+}
+
+func (f *MemberFile) Close() error {
+	if f.reader == nil {
+		return nil
+	}
+	err := f.reader.Close()
+	f.reader = nil
+	return err
+}
+
+func (f *MemberFile) reopen() error {
+	if err := f.Close(); err != nil {
+		return err
+	}
+	reader, err := Open(f.absPath, f.format, f.member.Name)
+	if err != nil {
+		return err
+	}
+	f.reader = reader
+	f.readerOffset = 0
+	return nil
+}
+
+// Info는 항목을 os.FileInfo로 나타냅니다. 압축 파일 안의 항목은 모두 읽기 전용입니다.
+func (m Member) Info() os.FileInfo {
+	return memberInfo{member: m}
+}
+
+type memberInfo struct {
+	member Member
+}
+
+func (i memberInfo) Name() string       { return path.Base(i.member.Name) }
+func (i memberInfo) Size() int64        { return i.member.Size }
+func (i memberInfo) ModTime() time.Time { return i.member.ModTime }
+func (i memberInfo) IsDir() bool        { return i.member.IsDir }
+func (i memberInfo) Sys() any           { return nil }
+
+func (i memberInfo) Mode() os.FileMode {
+	if i.member.IsDir {
+		return os.ModeDir | 0o555
+	}
+	return 0o444
+}
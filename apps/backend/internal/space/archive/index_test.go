@@ -0,0 +1,91 @@
+package archive
+
+import (
+	"archive/tar"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeTar(t *testing.T, path string, files map[string]string) {
+	t.Helper()
+
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("failed to create tar: %v", err)
+	}
+	defer file.Close()
+	writer := tar.NewWriter(file)
+	for name, content := range files {
+		header := &tar.Header{Name: name, Mode: 0o644, Size: int64(len(content)), Typeflag: tar.TypeReg, Format: tar.FormatGNU}
+		if err := writer.WriteHeader(header); err != nil {
+			t.Fatalf("failed to write header: %v", err)
+		}
+		if _, err := io.WriteString(writer, content); err != nil {
+			t.Fatalf("failed to write content: %v", err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("failed to close tar: %v", err)
+	}
+}
+
+func TestBuildIndex_DecodesCP949TarNamesAndAddsImplicitFolders(t *testing.T) {
+	archivePath := filepath.Join(t.TempDir(), "legacy.tar")
+	writeTar(t, archivePath, map[string]string{
+		"\xb9\xae\xbc\xad/a.txt": "a",
+		"b.txt":                  "b",
+	})
+
+	index, err := BuildIndex(context.Background(), archivePath, FormatTar, 0)
+	if err != nil {
+		t.Fatalf("failed to build index: %v", err)
+	}
+	folder, ok := index.Lookup("문서")
+	if !ok || !folder.IsDir {
+		t.Fatalf("expected implicit folder for decoded name, got %+v ok=%v", folder, ok)
+	}
+	members, err := index.List(".")
+	if err != nil {
+		t.Fatalf("failed to list root: %v", err)
+	}
+	if len(members) != 2 || members[0].Name != "문서" || members[1].Name != "b.txt" {
+		t.Fatalf("unexpected root members: %+v", members)
+	}
+	if _, err := index.List("b.txt"); !errors.Is(err, ErrNotDirectory) {
+		t.Fatalf("expected ErrNotDirectory, got %v", err)
+	}
+
+	if _, err := BuildIndex(context.Background(), archivePath, FormatTar, 1); !errors.Is(err, ErrTooManyEntries) {
+		t.Fatalf("expected ErrTooManyEntries, got %v", err)
+	}
+}
+
+func TestMemberFile_ReopensWhenSeekingBackwards(t *testing.T) {
+	archivePath := filepath.Join(t.TempDir(), "data.tar")
+	writeTar(t, archivePath, map[string]string{"data.txt": "0123456789"})
+
+	member := Member{Name: "data.txt", Size: 10}
+	file := NewMemberFile(archivePath, FormatTar, member)
+	defer file.Close()
+
+	buf := make([]byte, 4)
+	if _, err := file.Seek(6, io.SeekStart); err != nil {
+		t.Fatalf("failed to seek: %v", err)
+	}
+	if n, _ := io.ReadFull(file, buf); string(buf[:n]) != "6789" {
+		t.Fatalf("unexpected read after forward seek: %q", buf[:n])
+	}
+	if _, err := file.Seek(1, io.SeekStart); err != nil {
+		t.Fatalf("failed to seek: %v", err)
+	}
+	if n, _ := io.ReadFull(file, buf); string(buf[:n]) != "1234" {
+		t.Fatalf("unexpected read after backward seek: %q", buf[:n])
+	}
+	if size, _ := file.Seek(0, io.SeekEnd); size != 10 {
+		t.Fatalf("expected size 10 from SeekEnd, got %d", size)
+	}
+}
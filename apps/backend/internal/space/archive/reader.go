@@ -0,0 +1,249 @@
+// Package archive는 zip/tar 계열 압축 파일을 풀지 않고 읽는 기능을 제공합니다.
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/klauspost/compress/zstd"
+	"golang.org/x/text/encoding/korean"
+)
+
+// Format은 압축 형식입니다.
+type Format string
+
+const (
+	FormatZip     Format = "zip"
+	FormatTar     Format = "tar"
+	FormatTarGzip Format = "tar.gz"
+	FormatTarZstd Format = "tar.zst"
+)
+
+var ErrUnsafeMemberPath = errors.New("unsafe archive entry path")
+
+// Member는 압축 파일 안의 항목 하나입니다. Name은 슬래시 구분 상대 경로입니다.
+type Member struct {
+	Name    string
+	IsDir   bool
+	Size    int64
+	Mode    os.FileMode
+	ModTime time.Time
+	// Unsafe는 경로가 압축 해제 위치를 벗어나는 항목(zip-slip)입니다.
+	Unsafe bool
+	// Unsupported는 심볼릭 링크나 장치 파일처럼 풀지 않는 항목입니다.
+	Unsupported bool
+}
+
+// OpenFunc는 항목 내용을 엽니다. 콜백이 끝나면 더 이상 유효하지 않습니다.
+type OpenFunc func() (io.ReadCloser, error)
+
+// DetectFormat은 파일 이름의 확장자로 압축 형식을 판단합니다.
+func DetectFormat(name string) (Format, bool) {
+	lower := strings.ToLower(name)
+	switch {
+	case strings.HasSuffix(lower, ".zip"):
+		return FormatZip, true
+	case strings.HasSuffix(lower, ".tar.gz"), strings.HasSuffix(lower, ".tgz"):
+		return FormatTarGzip, true
+	case strings.HasSuffix(lower, ".tar.zst"), strings.HasSuffix(lower, ".tzst"):
+		return FormatTarZstd, true
+	case strings.HasSuffix(lower, ".tar"):
+		return FormatTar, true
+	default:
+		return "", false
+	}
+}
+
+// TrimExtension은 압축 확장자를 뗀 이름을 반환합니다.
+func TrimExtension(name string) string {
+	lower := strings.ToLower(name)
+	for _, ext := range []string{".tar.gz", ".tar.zst", ".tgz", ".tzst", ".zip", ".tar"} {
+		if strings.HasSuffix(lower, ext) && len(name) > len(ext) {
+			return name[:len(name)-len(ext)]
+		}
+	}
+	return name
+}
+
+// SanitizeMemberName은 항목 경로를 정규화합니다. 절대 경로나 상위 경로로
+// 벗어나는 경로는 ErrUnsafeMemberPath입니다. 루트 자신("./")은 "."입니다.
+func SanitizeMemberName(raw string) (string, error) {
+	name := strings.ReplaceAll(raw, "\\", "/")
+	if strings.HasPrefix(name, "/") || (len(name) >= 2 && name[1] == ':') || strings.ContainsRune(name, 0) {
+		return "", ErrUnsafeMemberPath
+	}
+	cleaned := path.Clean(name)
+	if cleaned == ".." || strings.HasPrefix(cleaned, "../") {
+		return "", ErrUnsafeMemberPath
+	}
+	return cleaned, nil
+}
+
+// decodeZipEntryName은 UTF-8 플래그가 없는 ZIP 항목 이름을 CP949로 해석합니다.
+// 한국어 Windows에서 만든 ZIP은 파일 이름을 CP949로 저장합니다.
+func decodeZipEntryName(file *zip.File) string {
+	if !file.NonUTF8 {
+		return file.Name
+	}
+	return decodeLegacyName(file.Name)
+}
+
+// decodeLegacyName은 UTF-8이 아닌 이름을 CP949로 해석합니다. 해석할 수 없으면 그대로 둡니다.
+// tar 헤더에는 인코딩 표시가 없으므로 UTF-8로 읽히지 않는 이름에만 적용합니다.
+func decodeLegacyName(name string) string {
+	if utf8.ValidString(name) {
+		return name
+	}
+	decoded, err := korean.EUCKR.NewDecoder().String(name)
+	if err != nil {
+		return name
+	}
+	return decoded
+}
+
+func newMember(rawName string, isDir bool, size int64, mode os.FileMode, modTime time.Time) Member {
+	member := Member{
+		Name:    rawName,
+		IsDir:   isDir,
+		Size:    size,
+		Mode:    mode,
+		ModTime: modTime,
+	}
+	name, err := SanitizeMemberName(rawName)
+	if err != nil {
+		member.Unsafe = true
+		return member
+	}
+	member.Name = name
+	return member
+}
+
+func isRootMember(member Member) bool {
+	return member.IsDir && member.Name == "."
+}
+
+// Walk는 압축 파일의 항목을 순서대로 fn에 전달합니다.
+// tar 계열은 순차 읽기이므로 open은 해당 콜백 안에서만 사용할 수 있습니다.
+func Walk(ctx context.Context, absPath string, format Format, fn func(member Member, open OpenFunc) error) error {
+	if format == FormatZip {
+		return walkZip(ctx, absPath, fn)
+	}
+
+	reader, closeStream, err := openTarStream(absPath, format)
+	if err != nil {
+		return err
+	}
+	defer closeStream()
+	return walkTar(ctx, reader, fn)
+}
+
+// openTarStream은 tar 계열 압축 파일을 열고 압축을 푼 tar 스트림을 반환합니다.
+func openTarStream(absPath string, format Format) (io.Reader, func(), error) {
+	file, err := os.Open(absPath)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	switch format {
+	case FormatTarGzip:
+		gzipReader, err := gzip.NewReader(file)
+		if err != nil {
+			file.Close()
+			return nil, nil, fmt.Errorf("invalid gzip stream: %w", err)
+		}
+		return gzipReader, func() {
+			gzipReader.Close()
+			file.Close()
+		}, nil
+	case FormatTarZstd:
+		zstdReader, err := zstd.NewReader(file)
+		if err != nil {
+			file.Close()
+			return nil, nil, fmt.Errorf("invalid zstd stream: %w", err)
+		}
+		return zstdReader, func() {
+			zstdReader.Close()
+			file.Close()
+		}, nil
+	case FormatTar:
+		return file, func() { file.Close() }, nil
+	default:
+		file.Close()
+		return nil, nil, fmt.Errorf("unsupported archive format: %s", format)
+	}
+}
+
+func walkZip(ctx context.Context, absPath string, fn func(member Member, open OpenFunc) error) error {
+	reader, err := zip.OpenReader(absPath)
+	if err != nil {
+		return fmt.Errorf("invalid zip archive: %w", err)
+	}
+	defer reader.Close()
+
+	for _, file := range reader.File {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		info := file.FileInfo()
+		member := newMember(decodeZipEntryName(file), info.IsDir(), int64(file.UncompressedSize64), info.Mode().Perm(), file.Modified)
+		if info.Mode()&(os.ModeSymlink|os.ModeDevice|os.ModeNamedPipe|os.ModeSocket) != 0 {
+			member.Unsupported = true
+		}
+		if isRootMember(member) {
+			continue
+		}
+		if err := fn(member, file.Open); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func walkTar(ctx context.Context, reader io.Reader, fn func(member Member, open OpenFunc) error) error {
+	tarReader := tar.NewReader(reader)
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		header, err := tarReader.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("invalid tar archive: %w", err)
+		}
+
+		name := decodeLegacyName(header.Name)
+		var member Member
+		switch header.Typeflag {
+		case tar.TypeDir:
+			member = newMember(name, true, 0, os.FileMode(header.Mode).Perm(), header.ModTime)
+		case tar.TypeReg:
+			member = newMember(name, false, header.Size, os.FileMode(header.Mode).Perm(), header.ModTime)
+		case tar.TypeXGlobalHeader:
+			continue
+		default:
+			member = newMember(name, false, 0, os.FileMode(header.Mode).Perm(), header.ModTime)
+			member.Unsupported = true
+		}
+		if isRootMember(member) {
+			continue
+		}
+		open := func() (io.ReadCloser, error) {
+			return io.NopCloser(tarReader), nil
+		}
+		if err := fn(member, open); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,298 @@
+package checksum
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"lukechampine.com/blake3"
+)
+
+// Algorithm은 지원하는 해시 알고리즘 이름입니다.
+type Algorithm string
+
+const (
+	SHA256 Algorithm = "sha256"
+	SHA1   Algorithm = "sha1"
+	MD5    Algorithm = "md5"
+	BLAKE3 Algorithm = "blake3"
+)
+
+// Algorithms는 지원하는 알고리즘 목록입니다. SFTP check-file 협상 순서이기도 합니다.
+var Algorithms = []Algorithm{SHA256, SHA1, MD5, BLAKE3}
+
+// DefaultConcurrency는 동시에 해시를 계산하는 파일 수 기본값입니다.
+const DefaultConcurrency = 2
+
+var (
+	ErrUnsupportedAlgorithm = errors.New("unsupported checksum algorithm")
+	ErrPending              = errors.New("checksum is being computed")
+	ErrNotRegularFile       = errors.New("checksum target is not a regular file")
+	ErrFileChanged          = errors.New("file changed while computing checksum")
+)
+
+// ParseAlgorithm은 요청 값을 Algorithm으로 바꿉니다. 빈 값이면 sha256입니다.
+func ParseAlgorithm(value string) (Algorithm, error) {
+	normalized := Algorithm(strings.ToLower(strings.TrimSpace(value)))
+	if normalized == "" {
+		return SHA256, nil
+	}
+	for _, algo := range Algorithms {
+		if algo == normalized {
+			return algo, nil
+		}
+	}
+	return "", fmt.Errorf("%w: %s", ErrUnsupportedAlgorithm, value)
+}
+
+// NewHash는 알고리즘의 새 hash.Hash를 반환합니다.
+func (a Algorithm) NewHash() hash.Hash {
+	switch a {
+	case SHA1:
+		return sha1.New()
+	case MD5:
+		return md5.New()
+	case BLAKE3:
+		return blake3.New(32, nil)
+	default:
+		return sha256.New()
+	}
+}
+
+// Entry는 파일 하나의 계산된 해시입니다. Size와 ModTime이 현재 파일과 같을 때만 유효합니다.
+type Entry struct {
+	Path       string    `json:"-"`
+	Algorithm  Algorithm `json:"algorithm"`
+	Size       int64     `json:"size"`
+	ModTime    time.Time `json:"modTime"`
+	Digest     string    `json:"digest"`
+	ComputedAt time.Time `json:"computedAt"`
+}
+
+// Matches는 캐시된 해시가 info가 가리키는 파일 상태와 같은지 확인합니다.
+func (e *Entry) Matches(info os.FileInfo) bool {
+	return e != nil && e.Size == info.Size() && e.ModTime.UnixNano() == info.ModTime().UnixNano()
+}
+
+// Storer는 계산된 해시 캐시 저장소입니다. 키는 (절대 경로, 알고리즘)입니다.
+type Storer interface {
+	// Get은 캐시된 항목을 반환합니다. 없으면 nil, nil입니다.
+	Get(ctx context.Context, path string, algo Algorithm) (*Entry, error)
+	Put(ctx context.Context, entry *Entry) error
+}
+
+type computeKey struct {
+	path string
+	algo Algorithm
+}
+
+type computation struct {
+	done  chan struct{}
+	entry *Entry
+	err   error
+}
+
+// Service는 해시를 백그라운드에서 계산하고 (경로, 크기, 수정 시각) 기준으로 캐시합니다.
+// 동시에 계산하는 파일 수는 concurrency로 제한하고, 같은 파일 요청은 하나의 계산을 공유합니다.
+type Service struct {
+	store Storer
+	slots chan struct{}
+
+	mu       sync.Mutex
+	inflight map[computeKey]*computation
+	// failures는 기다리는 사람 없이 끝난 계산의 실패입니다. 다음 조회에서 한 번 돌려줍니다.
+	failures map[computeKey]error
+}
+
+func NewService(store Storer, concurrency int) *Service {
+	if concurrency <= 0 {
+		concurrency = DefaultConcurrency
+	}
+	return &Service{
+		store:    store,
+		slots:    make(chan struct{}, concurrency),
+		inflight: make(map[computeKey]*computation),
+		failures: make(map[computeKey]error),
+	}
+}
+
+// Cached는 현재 파일 상태와 맞는 캐시 항목만 반환합니다. 계산은 시작하지 않습니다.
+func (s *Service) Cached(ctx context.Context, absPath string, algo Algorithm) (*Entry, error) {
+	info, err := os.Stat(absPath)
+	if err != nil {
+		return nil, err
+	}
+	if !info.Mode().IsRegular() {
+		return nil, ErrNotRegularFile
+	}
+	return s.Lookup(ctx, absPath, algo, info)
+}
+
+// Lookup은 Cached와 같지만 호출자가 이미 가진 FileInfo로 캐시 유효성을 확인합니다.
+func (s *Service) Lookup(ctx context.Context, absPath string, algo Algorithm, info os.FileInfo) (*Entry, error) {
+	entry, err := s.store.Get(ctx, absPath, algo)
+	if err != nil {
+		return nil, err
+	}
+	if !entry.Matches(info) {
+		return nil, nil
+	}
+	return entry, nil
+}
+
+// Record는 업로드 검증처럼 다른 경로로 이미 계산한 해시를 캐시에 넣습니다.
+// 현재 파일의 크기와 수정 시각을 함께 기록하므로 이후 파일이 바뀌면 자동으로 무효가 됩니다.
+func (s *Service) Record(ctx context.Context, absPath string, algo Algorithm, digest string) error {
+	info, err := os.Stat(absPath)
+	if err != nil {
+		return err
+	}
+	if !info.Mode().IsRegular() {
+		return ErrNotRegularFile
+	}
+	return s.store.Put(ctx, &Entry{
+		Path:       absPath,
+		Algorithm:  algo,
+		Size:       info.Size(),
+		ModTime:    info.ModTime(),
+		Digest:     digest,
+		ComputedAt: time.Now().UTC(),
+	})
+}
+
+// Request는 캐시된 해시를 반환하거나, 없으면 백그라운드 계산을 시작하고 ErrPending을 반환합니다.
+// 이전 백그라운드 계산이 실패했다면 그 오류를 한 번 돌려줍니다.
+func (s *Service) Request(ctx context.Context, absPath string, algo Algorithm) (*Entry, error) {
+	entry, _, err := s.request(ctx, absPath, algo)
+	return entry, err
+}
+
+// Sum은 Request와 같지만 계산이 끝나기를 최대 wait만큼 기다립니다.
+// 그 안에 끝나지 않으면 ErrPending이며 계산은 계속 진행됩니다.
+func (s *Service) Sum(ctx context.Context, absPath string, algo Algorithm, wait time.Duration) (*Entry, error) {
+	entry, running, err := s.request(ctx, absPath, algo)
+	if running == nil {
+		return entry, err
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-running.done:
+		if running.err != nil {
+			s.forgetFailure(computeKey{path: absPath, algo: algo})
+		}
+		return running.entry, running.err
+	case <-timer.C:
+		return nil, ErrPending
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (s *Service) request(ctx context.Context, absPath string, algo Algorithm) (*Entry, *computation, error) {
+	info, err := os.Stat(absPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !info.Mode().IsRegular() {
+		return nil, nil, ErrNotRegularFile
+	}
+	entry, err := s.Lookup(ctx, absPath, algo, info)
+	if err != nil || entry != nil {
+		return entry, nil, err
+	}
+
+	key := computeKey{path: absPath, algo: algo}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if running, ok := s.inflight[key]; ok {
+		return nil, running, ErrPending
+	}
+	if failure, ok := s.failures[key]; ok {
+		delete(s.failures, key)
+		return nil, nil, failure
+	}
+
+	running := &computation{done: make(chan struct{})}
+	s.inflight[key] = running
+	go s.compute(key, running)
+	return nil, running, ErrPending
+}
+
+// compute는 요청이 끝나도 계산을 이어가도록 요청 컨텍스트와 분리해 실행합니다.
+func (s *Service) compute(key computeKey, running *computation) {
+	s.slots <- struct{}{}
+	entry, err := hashFile(key.path, key.algo)
+	<-s.slots
+
+	if err == nil {
+		err = s.store.Put(context.Background(), entry)
+	}
+
+	s.mu.Lock()
+	delete(s.inflight, key)
+	if err != nil {
+		s.failures[key] = err
+	}
+	s.mu.Unlock()
+
+	running.entry, running.err = entry, err
+	close(running.done)
+}
+
+// forgetFailure는 기다리던 요청이 이미 받아 간 실패를 지웁니다.
+func (s *Service) forgetFailure(key computeKey) {
+	s.mu.Lock()
+	delete(s.failures, key)
+	s.mu.Unlock()
+}
+
+func hashFile(absPath string, algo Algorithm) (*Entry, error) {
+	file, err := os.Open(absPath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	before, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if !before.Mode().IsRegular() {
+		return nil, ErrNotRegularFile
+	}
+
+	hasher := algo.NewHash()
+	if _, err := io.Copy(hasher, file); err != nil {
+		return nil, err
+	}
+
+	// 계산 도중 파일이 바뀌었으면 어느 상태의 해시인지 알 수 없으므로 캐시하지 않습니다.
+	after, err := os.Stat(absPath)
+	if err != nil {
+		return nil, err
+	}
+	if after.Size() != before.Size() || !after.ModTime().Equal(before.ModTime()) {
+		return nil, ErrFileChanged
+	}
+
+	return &Entry{
+		Path:       absPath,
+		Algorithm:  algo,
+		Size:       before.Size(),
+		ModTime:    before.ModTime(),
+		Digest:     hex.EncodeToString(hasher.Sum(nil)),
+		ComputedAt: time.Now().UTC(),
+	}, nil
+}
@@ -0,0 +1,96 @@
+package checksum
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestService_SumCachesBySizeAndModTime(t *testing.T) {
+	ctx := context.Background()
+	filePath := filepath.Join(t.TempDir(), "hello.txt")
+	if err := os.WriteFile(filePath, []byte("hello"), 0o644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	service := NewService(NewMemoryStore(), 1)
+	expected := map[Algorithm]string{
+		SHA256: "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824",
+		SHA1:   "aaf4c61ddcc5e8a2dabede0f3b482cd9aea9434d",
+		MD5:    "5d41402abc4b2a76b9719d911017c592",
+		BLAKE3: "ea8f163db38682925e4491c5e58d4bb3506ef8c14eb78a86e908c5624a67200f",
+	}
+	for algo, digest := range expected {
+		entry, err := service.Sum(ctx, filePath, algo, 5*time.Second)
+		if err != nil {
+			t.Fatalf("failed to compute %s: %v", algo, err)
+		}
+		if entry.Digest != digest {
+			t.Fatalf("unexpected %s digest: %s", algo, entry.Digest)
+		}
+	}
+
+	cached, err := service.Cached(ctx, filePath, SHA256)
+	if err != nil || cached == nil {
+		t.Fatalf("expected cached sha256, got %+v %v", cached, err)
+	}
+
+	// 내용이 바뀌어 크기와 수정 시각이 달라지면 캐시는 더 이상 유효하지 않다.
+	if err := os.WriteFile(filePath, []byte("hello, world"), 0o644); err != nil {
+		t.Fatalf("failed to rewrite file: %v", err)
+	}
+	if err := os.Chtimes(filePath, time.Now(), time.Now().Add(time.Minute)); err != nil {
+		t.Fatalf("failed to touch file: %v", err)
+	}
+	cached, err = service.Cached(ctx, filePath, SHA256)
+	if err != nil || cached != nil {
+		t.Fatalf("expected stale cache to be ignored, got %+v %v", cached, err)
+	}
+	entry, err := service.Sum(ctx, filePath, SHA256, 5*time.Second)
+	if err != nil {
+		t.Fatalf("failed to recompute: %v", err)
+	}
+	if entry.Digest != "09ca7e4eaa6e8ae9c7d261167129184883644d07dfba7cbfbc4c8a2e08360d5b" {
+		t.Fatalf("unexpected recomputed digest: %s", entry.Digest)
+	}
+}
+
+func TestService_RequestStartsBackgroundComputation(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	filePath := filepath.Join(dir, "data.bin")
+	if err := os.WriteFile(filePath, []byte("data"), 0o644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	service := NewService(NewMemoryStore(), 1)
+	entry, err := service.Request(ctx, filePath, MD5)
+	if !errors.Is(err, ErrPending) || entry != nil {
+		t.Fatalf("expected pending on first request, got %+v %v", entry, err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		entry, err = service.Request(ctx, filePath, MD5)
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, ErrPending) || time.Now().After(deadline) {
+			t.Fatalf("expected computation to finish, got %v", err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if entry.Digest != "8d777f385d3dfec8815d20f7496026dc" {
+		t.Fatalf("unexpected digest: %s", entry.Digest)
+	}
+
+	if _, err := service.Request(ctx, dir, MD5); !errors.Is(err, ErrNotRegularFile) {
+		t.Fatalf("expected ErrNotRegularFile for directory, got %v", err)
+	}
+	if _, err := ParseAlgorithm("crc32"); !errors.Is(err, ErrUnsupportedAlgorithm) {
+		t.Fatalf("expected ErrUnsupportedAlgorithm, got %v", err)
+	}
+}
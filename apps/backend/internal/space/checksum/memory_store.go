@@ -0,0 +1,50 @@
+package checksum
+
+import (
+	"context"
+	"sync"
+)
+
+// DefaultMemoryEntries는 MemoryStore가 보관하는 최대 항목 수입니다.
+const DefaultMemoryEntries = 100000
+
+// MemoryStore는 프로세스 메모리에만 해시를 보관하는 Storer입니다.
+// DB 없이 Service를 쓰는 테스트나 기본 핸들러 구성에서 사용하며, 가득 차면 임의의 항목을 버립니다.
+type MemoryStore struct {
+	mu         sync.Mutex
+	maxEntries int
+	entries    map[computeKey]Entry
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		maxEntries: DefaultMemoryEntries,
+		entries:    make(map[computeKey]Entry),
+	}
+}
+
+func (s *MemoryStore) Get(ctx context.Context, path string, algo Algorithm) (*Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entry, ok := s.entries[computeKey{path: path, algo: algo}]
+	if !ok {
+		return nil, nil
+	}
+	return &entry, nil
+}
+
+func (s *MemoryStore) Put(ctx context.Context, entry *Entry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := computeKey{path: entry.Path, algo: entry.Algorithm}
+	if _, exists := s.entries[key]; !exists && len(s.entries) >= s.maxEntries {
+		for evicted := range s.entries {
+			delete(s.entries, evicted)
+			break
+		}
+	}
+	s.entries[key] = *entry
+	return nil
+}
+
+var _ Storer = (*MemoryStore)(nil)
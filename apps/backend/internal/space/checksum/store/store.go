@@ -0,0 +1,67 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"taeu.kr/cohesion/internal/space/checksum"
+)
+
+type Store struct {
+	db *sql.DB
+	qb sq.StatementBuilderType
+}
+
+func NewStore(db *sql.DB) *Store {
+	return &Store{
+		db: db,
+		qb: sq.StatementBuilder.PlaceholderFormat(sq.Question),
+	}
+}
+
+func (s *Store) Get(ctx context.Context, path string, algo checksum.Algorithm) (*checksum.Entry, error) {
+	sqlQuery, args, err := s.qb.
+		Select("size", "mod_time_ns", "digest", "computed_at").
+		From("file_checksums").
+		Where(sq.Eq{"path": path, "algorithm": string(algo)}).
+		Limit(1).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build SQL query for Get: %w", err)
+	}
+
+	var (
+		entry     = checksum.Entry{Path: path, Algorithm: algo}
+		modTimeNs int64
+	)
+	if err := s.db.QueryRowContext(ctx, sqlQuery, args...).Scan(&entry.Size, &modTimeNs, &entry.Digest, &entry.ComputedAt); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to query file checksum: %w", err)
+	}
+	entry.ModTime = time.Unix(0, modTimeNs)
+	return &entry, nil
+}
+
+func (s *Store) Put(ctx context.Context, entry *checksum.Entry) error {
+	sqlQuery, args, err := s.qb.
+		Insert("file_checksums").
+		Columns("path", "algorithm", "size", "mod_time_ns", "digest", "computed_at").
+		Values(entry.Path, string(entry.Algorithm), entry.Size, entry.ModTime.UnixNano(), entry.Digest, entry.ComputedAt.UTC()).
+		Suffix("ON CONFLICT(path, algorithm) DO UPDATE SET size = excluded.size, mod_time_ns = excluded.mod_time_ns, digest = excluded.digest, computed_at = excluded.computed_at").
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build SQL query for Put: %w", err)
+	}
+
+	if _, err := s.db.ExecContext(ctx, sqlQuery, args...); err != nil {
+		return fmt.Errorf("failed to store file checksum: %w", err)
+	}
+	return nil
+}
+
+var _ checksum.Storer = (*Store)(nil)
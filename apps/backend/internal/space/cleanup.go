@@ -0,0 +1,699 @@
+package space
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"taeu.kr/cohesion/internal/platform/logging"
+)
+
+const (
+	// DefaultCleanupInterval은 자동 정리 규칙을 평가하는 주기입니다.
+	DefaultCleanupInterval = time.Hour
+	// MaxCleanupMaxAgeDays는 규칙에 둘 수 있는 가장 긴 보관 기간입니다.
+	MaxCleanupMaxAgeDays = 36500
+	// MaxCleanupReportItems는 보고서의 목록마다 담는 최대 항목 수입니다. 개수와 합계는 목록과 상관없이 모두 셉니다.
+	MaxCleanupReportItems = 1000
+	// CleanupActor는 정리 작업이 남기는 휴지통 항목과 감사 기록의 행위자입니다.
+	CleanupActor = "system"
+	// CleanupSkipRetentionLocked는 보존 잠금 때문에 건너뛴 항목의 code입니다.
+	CleanupSkipRetentionLocked = "retention_locked"
+)
+
+// CleanupAction은 기간이 지난 파일을 어떻게 치울지입니다.
+type CleanupAction string
+
+const (
+	// CleanupActionTrash는 휴지통으로 옮깁니다. 휴지통 보관 기간 동안은 되살릴 수 있습니다.
+	CleanupActionTrash CleanupAction = "trash"
+	// CleanupActionDelete는 바로 영구 삭제합니다.
+	CleanupActionDelete CleanupAction = "delete"
+)
+
+var (
+	// ErrInvalidCleanupRule은 규칙 값이 잘못된 경우입니다.
+	ErrInvalidCleanupRule = errors.New("invalid cleanup rule")
+	// ErrCleanupRuleNotFound는 없는 규칙이거나 다른 Space의 규칙입니다.
+	ErrCleanupRuleNotFound = errors.New("cleanup rule not found")
+	// ErrCleanupUnavailable은 파일을 치울 remover가 설정되지 않은 경우입니다.
+	ErrCleanupUnavailable = errors.New("cleanup remover is not configured")
+)
+
+// CleanupRule은 Space 안 폴더 하나에서 오래된 파일을 치우는 규칙입니다.
+// PathPrefix 아래(하위 폴더 포함) 일반 파일 중 수정한 지 MaxAgeDays일이 지나고 Pattern에 맞는 파일이 대상입니다.
+// 폴더는 비어도 남겨 둡니다.
+type CleanupRule struct {
+	ID         int64  `json:"id"`
+	SpaceID    int64  `json:"spaceId"`
+	PathPrefix string `json:"pathPrefix"`
+	MaxAgeDays int    `json:"maxAgeDays"`
+	// Pattern은 path.Match 형식의 glob입니다. "/"가 없으면 파일 이름에, 있으면 PathPrefix 기준 경로에 맞춥니다.
+	Pattern   string        `json:"pattern"`
+	Action    CleanupAction `json:"action"`
+	Enabled   bool          `json:"enabled"`
+	CreatedBy string        `json:"createdBy"`
+	CreatedAt time.Time     `json:"createdAt"`
+	UpdatedAt time.Time     `json:"updatedAt"`
+	LastRunAt *time.Time    `json:"lastRunAt,omitempty"`
+}
+
+// CleanupRuleRequest는 규칙을 만들거나 통째로 바꾸는 요청입니다. Action이 비면 trash, Enabled가 없으면 켭니다.
+type CleanupRuleRequest struct {
+	PathPrefix string        `json:"pathPrefix"`
+	MaxAgeDays int           `json:"maxAgeDays"`
+	Pattern    string        `json:"pattern"`
+	Action     CleanupAction `json:"action"`
+	Enabled    *bool         `json:"enabled"`
+}
+
+// CleanupRuleStorer는 자동 정리 규칙 저장소입니다. spaceID가 0이면 모든 Space의 규칙을 나열합니다.
+type CleanupRuleStorer interface {
+	ListCleanupRules(ctx context.Context, spaceID int64) ([]*CleanupRule, error)
+	GetCleanupRule(ctx context.Context, id int64) (*CleanupRule, error)
+	CreateCleanupRule(ctx context.Context, rule *CleanupRule) (*CleanupRule, error)
+	UpdateCleanupRule(ctx context.Context, rule *CleanupRule) error
+	DeleteCleanupRule(ctx context.Context, id int64) error
+	MarkCleanupRuleRun(ctx context.Context, id int64, at time.Time) error
+}
+
+// CleanupFile은 규칙에 걸린 파일 하나입니다. Path는 Space 루트 기준 경로입니다.
+type CleanupFile struct {
+	Path       string    `json:"path"`
+	Size       int64     `json:"size"`
+	ModifiedAt time.Time `json:"modifiedAt"`
+}
+
+// CleanupSkippedFile은 치우지 않았거나 치우지 못한 파일과 그 사유입니다.
+type CleanupSkippedFile struct {
+	Path   string `json:"path"`
+	Reason string `json:"reason"`
+	Code   string `json:"code,omitempty"`
+}
+
+// CleanupReport는 규칙 하나를 평가하거나 실행한 결과입니다.
+type CleanupReport struct {
+	RuleID      int64         `json:"ruleId"`
+	SpaceID     int64         `json:"spaceId"`
+	PathPrefix  string        `json:"pathPrefix"`
+	Action      CleanupAction `json:"action"`
+	DryRun      bool          `json:"dryRun"`
+	Cutoff      time.Time     `json:"cutoff"`
+	EvaluatedAt time.Time     `json:"evaluatedAt"`
+	// Files는 dry run이면 치울 파일, 실행이면 치운 파일입니다.
+	Files        []CleanupFile        `json:"files"`
+	FileCount    int                  `json:"fileCount"`
+	TotalBytes   int64                `json:"totalBytes"`
+	Skipped      []CleanupSkippedFile `json:"skipped"`
+	SkippedCount int                  `json:"skippedCount"`
+	Failed       []CleanupSkippedFile `json:"failed"`
+	FailedCount  int                  `json:"failedCount"`
+	// Truncated는 목록 중 하나라도 MaxCleanupReportItems에서 잘렸는지입니다.
+	Truncated bool `json:"truncated"`
+}
+
+func newCleanupReport(rule *CleanupRule, now time.Time, dryRun bool) *CleanupReport {
+	return &CleanupReport{
+		RuleID:      rule.ID,
+		SpaceID:     rule.SpaceID,
+		PathPrefix:  rule.PathPrefix,
+		Action:      rule.Action,
+		DryRun:      dryRun,
+		Cutoff:      rule.cutoff(now),
+		EvaluatedAt: now,
+		Files:       make([]CleanupFile, 0),
+		Skipped:     make([]CleanupSkippedFile, 0),
+		Failed:      make([]CleanupSkippedFile, 0),
+	}
+}
+
+func (r *CleanupReport) addFile(file CleanupFile) {
+	r.FileCount++
+	r.TotalBytes += file.Size
+	if len(r.Files) < MaxCleanupReportItems {
+		r.Files = append(r.Files, file)
+	} else {
+		r.Truncated = true
+	}
+}
+
+func (r *CleanupReport) addSkipped(filePath string, err error) {
+	r.SkippedCount++
+	if len(r.Skipped) < MaxCleanupReportItems {
+		r.Skipped = append(r.Skipped, cleanupSkippedFile(filePath, err))
+	} else {
+		r.Truncated = true
+	}
+}
+
+func (r *CleanupReport) addFailed(filePath string, err error) {
+	r.FailedCount++
+	if len(r.Failed) < MaxCleanupReportItems {
+		r.Failed = append(r.Failed, cleanupSkippedFile(filePath, err))
+	} else {
+		r.Truncated = true
+	}
+}
+
+func cleanupSkippedFile(filePath string, err error) CleanupSkippedFile {
+	var lockedErr *RetentionLockedError
+	if errors.As(err, &lockedErr) {
+		return CleanupSkippedFile{Path: filePath, Reason: lockedErr.Reason(), Code: CleanupSkipRetentionLocked}
+	}
+	return CleanupSkippedFile{Path: filePath, Reason: err.Error()}
+}
+
+// CleanupRemover는 기간이 지난 파일 하나를 휴지통으로 옮기거나 영구 삭제합니다.
+// 휴지통과 사용량 기록을 함께 다뤄야 해서 handler가 구현합니다.
+type CleanupRemover interface {
+	RemoveExpiredFile(ctx context.Context, spaceObj *Space, relPath string, action CleanupAction) error
+}
+
+// CleanupRunEvent는 감사 기록으로 남길 정리 실행 결과입니다. Err가 있으면 규칙을 평가하지 못한 것입니다.
+type CleanupRunEvent struct {
+	Rule   CleanupRule
+	Report *CleanupReport
+	Err    error
+}
+
+// CleanupNotifier는 정리 실행 결과를 알리는 통로입니다.
+type CleanupNotifier interface {
+	NotifyCleanupRun(ctx context.Context, event CleanupRunEvent)
+}
+
+// CleanupNotifierFunc는 함수를 CleanupNotifier로 씁니다.
+type CleanupNotifierFunc func(ctx context.Context, event CleanupRunEvent)
+
+func (f CleanupNotifierFunc) NotifyCleanupRun(ctx context.Context, event CleanupRunEvent) {
+	f(ctx, event)
+}
+
+// CleanupService는 자동 정리 규칙을 관리하고 평가합니다.
+// 보존 잠금에 걸린 파일은 dry run과 실행 모두 건너뜁니다.
+type CleanupService struct {
+	spaceService *Service
+	store        CleanupRuleStorer
+	remover      CleanupRemover
+	retention    *RetentionService
+	notifier     CleanupNotifier
+	now          func() time.Time
+}
+
+// NewCleanupService는 규칙을 메모리에 두는 서비스를 만듭니다. 저장소와 remover는 Set* 로 바꿉니다.
+func NewCleanupService(spaceService *Service) *CleanupService {
+	return &CleanupService{
+		spaceService: spaceService,
+		store:        NewMemoryCleanupRuleStore(),
+		now:          time.Now,
+	}
+}
+
+func (s *CleanupService) SetStore(store CleanupRuleStorer) {
+	if store != nil {
+		s.store = store
+	}
+}
+
+func (s *CleanupService) SetRemover(remover CleanupRemover) {
+	s.remover = remover
+}
+
+// SetRetentionService는 정리 대상에서 뺄 보존 잠금을 확인할 서비스를 설정합니다.
+func (s *CleanupService) SetRetentionService(retention *RetentionService) {
+	s.retention = retention
+}
+
+// SetNotifier는 실제로 파일을 치우거나 실패한 실행을 알릴 대상을 설정합니다.
+func (s *CleanupService) SetNotifier(notifier CleanupNotifier) {
+	s.notifier = notifier
+}
+
+func (s *CleanupService) ListRules(ctx context.Context, spaceID int64) ([]*CleanupRule, error) {
+	return s.store.ListCleanupRules(ctx, spaceID)
+}
+
+// GetRule은 spaceID에 속한 규칙을 찾습니다. 다른 Space의 규칙이면 ErrCleanupRuleNotFound입니다.
+func (s *CleanupService) GetRule(ctx context.Context, spaceID, ruleID int64) (*CleanupRule, error) {
+	rule, err := s.store.GetCleanupRule(ctx, ruleID)
+	if err != nil {
+		return nil, err
+	}
+	if rule.SpaceID != spaceID {
+		return nil, ErrCleanupRuleNotFound
+	}
+	return rule, nil
+}
+
+func (s *CleanupService) CreateRule(ctx context.Context, spaceID int64, req CleanupRuleRequest, actor string) (*CleanupRule, error) {
+	rule, err := req.toRule()
+	if err != nil {
+		return nil, err
+	}
+	now := s.now().UTC()
+	rule.SpaceID = spaceID
+	rule.CreatedBy = actor
+	rule.CreatedAt = now
+	rule.UpdatedAt = now
+	return s.store.CreateCleanupRule(ctx, rule)
+}
+
+// UpdateRule은 규칙을 req로 통째로 바꾸고 바꾸기 전 규칙도 함께 반환합니다.
+func (s *CleanupService) UpdateRule(ctx context.Context, spaceID, ruleID int64, req CleanupRuleRequest) (rule *CleanupRule, previous *CleanupRule, err error) {
+	previous, err = s.GetRule(ctx, spaceID, ruleID)
+	if err != nil {
+		return nil, nil, err
+	}
+	rule, err = req.toRule()
+	if err != nil {
+		return nil, nil, err
+	}
+	rule.ID = previous.ID
+	rule.SpaceID = previous.SpaceID
+	rule.CreatedBy = previous.CreatedBy
+	rule.CreatedAt = previous.CreatedAt
+	rule.LastRunAt = previous.LastRunAt
+	rule.UpdatedAt = s.now().UTC()
+	if err := s.store.UpdateCleanupRule(ctx, rule); err != nil {
+		return nil, nil, err
+	}
+	return rule, previous, nil
+}
+
+func (s *CleanupService) DeleteRule(ctx context.Context, spaceID, ruleID int64) (*CleanupRule, error) {
+	rule, err := s.GetRule(ctx, spaceID, ruleID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.store.DeleteCleanupRule(ctx, ruleID); err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+// DryRun은 지금 실행하면 치울 파일과 보존 잠금으로 건너뛸 파일을 보고합니다. 파일은 건드리지 않습니다.
+func (s *CleanupService) DryRun(ctx context.Context, spaceObj *Space, rule *CleanupRule) (*CleanupReport, error) {
+	now := s.now().UTC()
+	report := newCleanupReport(rule, now, true)
+	files, err := collectCleanupFiles(ctx, spaceObj.SpacePath, rule, report.Cutoff)
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range files {
+		if err := s.retention.CheckMutable(ctx, s.retentionTarget(spaceObj, file.Path)); err != nil {
+			report.addSkipped(file.Path, err)
+			continue
+		}
+		report.addFile(file)
+	}
+	return report, nil
+}
+
+// Run은 규칙을 실행해 기간이 지난 파일을 치웁니다. 평가한 뒤 다시 수정된 파일은 건너뜁니다.
+// 한 파일의 실패는 보고서에 남기고 계속합니다.
+func (s *CleanupService) Run(ctx context.Context, spaceObj *Space, rule *CleanupRule) (*CleanupReport, error) {
+	if s.remover == nil {
+		return nil, ErrCleanupUnavailable
+	}
+	now := s.now().UTC()
+	report := newCleanupReport(rule, now, false)
+	files, err := collectCleanupFiles(ctx, spaceObj.SpacePath, rule, report.Cutoff)
+	if err != nil {
+		s.notify(ctx, CleanupRunEvent{Rule: *rule, Err: err})
+		return nil, err
+	}
+	for _, file := range files {
+		if err := ctx.Err(); err != nil {
+			s.notify(ctx, CleanupRunEvent{Rule: *rule, Report: report})
+			return report, err
+		}
+		if err := s.retention.EnsureMutable(ctx, s.retentionTarget(spaceObj, file.Path)); err != nil {
+			report.addSkipped(file.Path, err)
+			continue
+		}
+		absPath := filepath.Join(spaceObj.SpacePath, filepath.FromSlash(file.Path))
+		info, err := os.Lstat(absPath)
+		if err != nil || !info.Mode().IsRegular() || !info.ModTime().Before(report.Cutoff) {
+			continue
+		}
+		if err := s.remover.RemoveExpiredFile(ctx, spaceObj, file.Path, rule.Action); err != nil {
+			report.addFailed(file.Path, err)
+			continue
+		}
+		report.addFile(file)
+	}
+
+	if err := s.store.MarkCleanupRuleRun(ctx, rule.ID, now); err != nil {
+		logging.Event(log.Warn(), logging.ComponentStorage, "warn.space.cleanup_mark_failed").
+			Err(err).
+			Int64("space_id", rule.SpaceID).
+			Int64("rule_id", rule.ID).
+			Msg("failed to record cleanup run time")
+	}
+	if report.FileCount > 0 || report.FailedCount > 0 {
+		s.notify(ctx, CleanupRunEvent{Rule: *rule, Report: report})
+	}
+	return report, nil
+}
+
+// RunAll은 켜진 규칙을 모두 실행합니다. 쓸 수 없는 Space(offline, 읽기 전용, 동결 중)의 규칙은 건너뛰고,
+// 한 규칙의 실패는 기록만 하고 계속합니다.
+func (s *CleanupService) RunAll(ctx context.Context) error {
+	rules, err := s.store.ListCleanupRules(ctx, 0)
+	if err != nil {
+		return err
+	}
+	for _, rule := range rules {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if !rule.Enabled {
+			continue
+		}
+		spaceObj, release, err := s.spaceService.BeginWrite(ctx, rule.SpaceID)
+		if err != nil {
+			continue
+		}
+		_, err = s.Run(ctx, spaceObj, rule)
+		release()
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			logging.Event(log.Warn(), logging.ComponentStorage, "warn.space.cleanup_failed").
+				Err(err).
+				Int64("space_id", rule.SpaceID).
+				Int64("rule_id", rule.ID).
+				Msg("failed to run cleanup rule")
+		}
+	}
+	return nil
+}
+
+func (s *CleanupService) retentionTarget(spaceObj *Space, relPath string) RetentionTarget {
+	return RetentionTarget{
+		SpaceID:   spaceObj.ID,
+		SpaceRoot: spaceObj.SpacePath,
+		AbsPath:   filepath.Join(spaceObj.SpacePath, filepath.FromSlash(relPath)),
+		Operation: "delete",
+		Actor:     CleanupActor,
+		Source:    "cleanup",
+	}
+}
+
+func (s *CleanupService) notify(ctx context.Context, event CleanupRunEvent) {
+	if s.notifier != nil {
+		s.notifier.NotifyCleanupRun(context.WithoutCancel(ctx), event)
+	}
+}
+
+func (req CleanupRuleRequest) toRule() (*CleanupRule, error) {
+	prefix := strings.TrimSpace(strings.ReplaceAll(req.PathPrefix, `\`, "/"))
+	prefix = strings.TrimPrefix(path.Clean("/"+prefix), "/")
+	if prefix == "" {
+		return nil, fmt.Errorf("%w: pathPrefix must be a folder inside the Space", ErrInvalidCleanupRule)
+	}
+	if strings.HasPrefix(prefix, ".cohesion") {
+		return nil, fmt.Errorf("%w: pathPrefix must not point to internal folders", ErrInvalidCleanupRule)
+	}
+	if req.MaxAgeDays < 1 || req.MaxAgeDays > MaxCleanupMaxAgeDays {
+		return nil, fmt.Errorf("%w: maxAgeDays must be between 1 and %d", ErrInvalidCleanupRule, MaxCleanupMaxAgeDays)
+	}
+	pattern := strings.TrimSpace(req.Pattern)
+	if _, err := path.Match(pattern, ""); err != nil {
+		return nil, fmt.Errorf("%w: invalid pattern %q", ErrInvalidCleanupRule, req.Pattern)
+	}
+	action := req.Action
+	switch action {
+	case "":
+		action = CleanupActionTrash
+	case CleanupActionTrash, CleanupActionDelete:
+	default:
+		return nil, fmt.Errorf("%w: action must be trash or delete", ErrInvalidCleanupRule)
+	}
+	enabled := true
+	if req.Enabled != nil {
+		enabled = *req.Enabled
+	}
+	return &CleanupRule{
+		PathPrefix: prefix,
+		MaxAgeDays: req.MaxAgeDays,
+		Pattern:    pattern,
+		Action:     action,
+		Enabled:    enabled,
+	}, nil
+}
+
+func (r *CleanupRule) cutoff(now time.Time) time.Time {
+	return now.Add(-time.Duration(r.MaxAgeDays) * 24 * time.Hour)
+}
+
+// matches는 PathPrefix 기준 경로 rel이 Pattern에 맞는지 확인합니다.
+func (r *CleanupRule) matches(rel string) bool {
+	if r.Pattern == "" {
+		return true
+	}
+	name := path.Base(rel)
+	if strings.Contains(r.Pattern, "/") {
+		name = rel
+	}
+	matched, err := path.Match(r.Pattern, name)
+	return err == nil && matched
+}
+
+// collectCleanupFiles는 규칙 폴더 아래에서 cutoff 전에 수정한 일반 파일을 경로 순으로 모읍니다.
+// 심볼릭 링크는 따라가지 않고, 폴더가 없거나 Space 밖을 가리키면 빈 목록입니다.
+// 읽을 수 없는 하위 폴더는 건너뜁니다.
+func collectCleanupFiles(ctx context.Context, spaceRoot string, rule *CleanupRule, cutoff time.Time) ([]CleanupFile, error) {
+	base := filepath.Join(spaceRoot, filepath.FromSlash(rule.PathPrefix))
+	info, err := os.Lstat(base)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if !info.IsDir() || !cleanupBaseInsideRoot(spaceRoot, base) {
+		return nil, nil
+	}
+
+	files := make([]CleanupFile, 0)
+	err = filepath.WalkDir(base, func(current string, entry fs.DirEntry, walkErr error) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if walkErr != nil {
+			if current == base {
+				return walkErr
+			}
+			if entry != nil && entry.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if current != base && strings.HasPrefix(entry.Name(), ".cohesion") {
+			if entry.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+		fileInfo, err := entry.Info()
+		if err != nil || !fileInfo.ModTime().Before(cutoff) {
+			return nil
+		}
+		rel, err := filepath.Rel(base, current)
+		if err != nil {
+			return nil
+		}
+		rel = filepath.ToSlash(rel)
+		if !rule.matches(rel) {
+			return nil
+		}
+		files = append(files, CleanupFile{
+			Path:       path.Join(rule.PathPrefix, rel),
+			Size:       fileInfo.Size(),
+			ModifiedAt: fileInfo.ModTime().UTC(),
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].Path < files[j].Path })
+	return files, nil
+}
+
+// cleanupBaseInsideRoot는 중간 경로의 심볼릭 링크를 풀어도 base가 Space 안인지 확인합니다.
+func cleanupBaseInsideRoot(spaceRoot, base string) bool {
+	resolvedRoot, err := filepath.EvalSymlinks(spaceRoot)
+	if err != nil {
+		return false
+	}
+	resolvedBase, err := filepath.EvalSymlinks(base)
+	if err != nil {
+		return false
+	}
+	rel, err := filepath.Rel(resolvedRoot, resolvedBase)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// CleanupScheduler는 주기적으로 켜진 자동 정리 규칙을 모두 실행합니다.
+type CleanupScheduler struct {
+	cleanup  *CleanupService
+	interval time.Duration
+
+	startOnce sync.Once
+	stopOnce  sync.Once
+	stopCh    chan struct{}
+	doneCh    chan struct{}
+}
+
+func NewCleanupScheduler(cleanup *CleanupService, interval time.Duration) *CleanupScheduler {
+	if interval <= 0 {
+		interval = DefaultCleanupInterval
+	}
+	return &CleanupScheduler{
+		cleanup:  cleanup,
+		interval: interval,
+		stopCh:   make(chan struct{}),
+		doneCh:   make(chan struct{}),
+	}
+}
+
+// Start는 즉시 한 번 실행한 뒤 interval마다 다시 실행합니다.
+func (c *CleanupScheduler) Start() {
+	c.startOnce.Do(func() {
+		go c.run()
+	})
+}
+
+// Stop은 실행 루프를 멈추고 종료를 기다립니다. Start 전에 호출해도 안전합니다.
+func (c *CleanupScheduler) Stop() {
+	c.stopOnce.Do(func() {
+		close(c.stopCh)
+	})
+	c.startOnce.Do(func() {
+		close(c.doneCh)
+	})
+	<-c.doneCh
+}
+
+func (c *CleanupScheduler) run() {
+	defer close(c.doneCh)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-c.stopCh:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+	for {
+		if err := c.cleanup.RunAll(ctx); err != nil && ctx.Err() == nil {
+			logging.Event(log.Warn(), logging.ComponentStorage, "warn.space.cleanup_failed").
+				Err(err).
+				Msg("failed to run cleanup rules")
+		}
+		select {
+		case <-c.stopCh:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// MemoryCleanupRuleStore는 프로세스 메모리에만 규칙을 두는 저장소입니다.
+type MemoryCleanupRuleStore struct {
+	mu     sync.RWMutex
+	nextID int64
+	rules  map[int64]CleanupRule
+}
+
+func NewMemoryCleanupRuleStore() *MemoryCleanupRuleStore {
+	return &MemoryCleanupRuleStore{rules: make(map[int64]CleanupRule)}
+}
+
+func (s *MemoryCleanupRuleStore) ListCleanupRules(ctx context.Context, spaceID int64) ([]*CleanupRule, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	rules := make([]*CleanupRule, 0, len(s.rules))
+	for _, rule := range s.rules {
+		if spaceID != 0 && rule.SpaceID != spaceID {
+			continue
+		}
+		rule := rule
+		rules = append(rules, &rule)
+	}
+	sort.Slice(rules, func(i, j int) bool { return rules[i].ID < rules[j].ID })
+	return rules, nil
+}
+
+func (s *MemoryCleanupRuleStore) GetCleanupRule(ctx context.Context, id int64) (*CleanupRule, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	rule, ok := s.rules[id]
+	if !ok {
+		return nil, ErrCleanupRuleNotFound
+	}
+	return &rule, nil
+}
+
+func (s *MemoryCleanupRuleStore) CreateCleanupRule(ctx context.Context, rule *CleanupRule) (*CleanupRule, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.nextID++
+	created := *rule
+	created.ID = s.nextID
+	s.rules[created.ID] = created
+	return &created, nil
+}
+
+func (s *MemoryCleanupRuleStore) UpdateCleanupRule(ctx context.Context, rule *CleanupRule) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.rules[rule.ID]; !ok {
+		return ErrCleanupRuleNotFound
+	}
+	s.rules[rule.ID] = *rule
+	return nil
+}
+
+func (s *MemoryCleanupRuleStore) DeleteCleanupRule(ctx context.Context, id int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.rules[id]; !ok {
+		return ErrCleanupRuleNotFound
+	}
+	delete(s.rules, id)
+	return nil
+}
+
+func (s *MemoryCleanupRuleStore) MarkCleanupRuleRun(ctx context.Context, id int64, at time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rule, ok := s.rules[id]
+	if !ok {
+		return ErrCleanupRuleNotFound
+	}
+	rule.LastRunAt = &at
+	s.rules[id] = rule
+	return nil
+}
+
+var _ CleanupRuleStorer = (*MemoryCleanupRuleStore)(nil)
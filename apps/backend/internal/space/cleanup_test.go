@@ -0,0 +1,126 @@
+package space_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"taeu.kr/cohesion/internal/space"
+	spaceStore "taeu.kr/cohesion/internal/space/store"
+)
+
+type recordingCleanupRemover struct {
+	removed []string
+}
+
+func (r *recordingCleanupRemover) RemoveExpiredFile(ctx context.Context, spaceObj *space.Space, relPath string, action space.CleanupAction) error {
+	r.removed = append(r.removed, relPath)
+	return os.Remove(filepath.Join(spaceObj.SpacePath, filepath.FromSlash(relPath)))
+}
+
+func TestCleanupService_RemovesOnlyExpiredMatchingFilesOutsideRetentionLocks(t *testing.T) {
+	service, db := setupSlugSpaceService(t)
+	ctx := context.Background()
+	root := t.TempDir()
+	created, err := service.CreateSpace(ctx, &space.CreateSpaceRequest{SpaceName: "Scratch", SpacePath: root})
+	if err != nil {
+		t.Fatalf("create space: %v", err)
+	}
+
+	old := time.Now().Add(-10 * 24 * time.Hour)
+	writeFile := func(rel string, modTime time.Time) {
+		t.Helper()
+		absPath := filepath.Join(root, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(absPath), 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(absPath, []byte(rel), 0o644); err != nil {
+			t.Fatalf("write file: %v", err)
+		}
+		if err := os.Chtimes(absPath, modTime, modTime); err != nil {
+			t.Fatalf("chtimes: %v", err)
+		}
+	}
+	writeFile("Temp/old.tmp", old)
+	writeFile("Temp/nested/older.tmp", old)
+	writeFile("Temp/keep.txt", old)
+	writeFile("Temp/fresh.tmp", time.Now())
+	writeFile("Temp/held/evidence.tmp", old)
+	writeFile("Other/old.tmp", old)
+
+	retention := space.NewRetentionService(nil)
+	if _, _, err := retention.SaveLock(ctx, created.ID, root, space.RetentionLockRequest{Path: "Temp/held", LegalHold: true}, "alice"); err != nil {
+		t.Fatalf("save lock: %v", err)
+	}
+	cleanup := space.NewCleanupService(service)
+	cleanup.SetStore(spaceStore.NewCleanupRuleStore(db))
+	cleanup.SetRetentionService(retention)
+	remover := &recordingCleanupRemover{}
+	cleanup.SetRemover(remover)
+	var events []space.CleanupRunEvent
+	cleanup.SetNotifier(space.CleanupNotifierFunc(func(ctx context.Context, event space.CleanupRunEvent) {
+		events = append(events, event)
+	}))
+
+	if _, err := cleanup.CreateRule(ctx, created.ID, space.CleanupRuleRequest{PathPrefix: "/", MaxAgeDays: 7}, "alice"); !errors.Is(err, space.ErrInvalidCleanupRule) {
+		t.Fatalf("expected Space root prefix to be rejected, got %v", err)
+	}
+	if _, err := cleanup.CreateRule(ctx, created.ID, space.CleanupRuleRequest{PathPrefix: "Temp", MaxAgeDays: 7, Pattern: "[.tmp"}, "alice"); !errors.Is(err, space.ErrInvalidCleanupRule) {
+		t.Fatalf("expected bad pattern to be rejected, got %v", err)
+	}
+	rule, err := cleanup.CreateRule(ctx, created.ID, space.CleanupRuleRequest{PathPrefix: "/Temp/", MaxAgeDays: 7, Pattern: "*.tmp"}, "alice")
+	if err != nil {
+		t.Fatalf("create rule: %v", err)
+	}
+	if rule.PathPrefix != "Temp" || rule.Action != space.CleanupActionTrash || !rule.Enabled || rule.CreatedBy != "alice" {
+		t.Fatalf("unexpected rule: %+v", rule)
+	}
+
+	report, err := cleanup.DryRun(ctx, created, rule)
+	if err != nil {
+		t.Fatalf("dry run: %v", err)
+	}
+	if !report.DryRun || report.FileCount != 2 || report.Files[0].Path != "Temp/nested/older.tmp" || report.Files[1].Path != "Temp/old.tmp" {
+		t.Fatalf("unexpected dry run files: %+v", report.Files)
+	}
+	if report.SkippedCount != 1 || report.Skipped[0].Path != "Temp/held/evidence.tmp" || report.Skipped[0].Code != space.CleanupSkipRetentionLocked {
+		t.Fatalf("unexpected dry run skipped: %+v", report.Skipped)
+	}
+	if len(remover.removed) != 0 || len(events) != 0 {
+		t.Fatalf("dry run must not remove files, removed=%v events=%d", remover.removed, len(events))
+	}
+
+	if err := cleanup.RunAll(ctx); err != nil {
+		t.Fatalf("run all: %v", err)
+	}
+	if len(remover.removed) != 2 {
+		t.Fatalf("expected two files to be removed, got %v", remover.removed)
+	}
+	for _, rel := range []string{"Temp/keep.txt", "Temp/fresh.tmp", "Temp/held/evidence.tmp", "Other/old.tmp"} {
+		if _, err := os.Stat(filepath.Join(root, filepath.FromSlash(rel))); err != nil {
+			t.Fatalf("%s should remain, err=%v", rel, err)
+		}
+	}
+	if len(events) != 1 || events[0].Report.FileCount != 2 || events[0].Report.SkippedCount != 1 {
+		t.Fatalf("unexpected run events: %+v", events)
+	}
+	stored, err := cleanup.GetRule(ctx, created.ID, rule.ID)
+	if err != nil || stored.LastRunAt == nil {
+		t.Fatalf("expected last run time to be recorded, got %+v (%v)", stored, err)
+	}
+
+	// 치울 파일이 없는 실행은 알리지 않는다.
+	if err := cleanup.RunAll(ctx); err != nil {
+		t.Fatalf("second run: %v", err)
+	}
+	if len(events) != 1 {
+		t.Fatalf("expected no event for empty run, got %d", len(events))
+	}
+
+	if _, err := cleanup.GetRule(ctx, created.ID+1, rule.ID); !errors.Is(err, space.ErrCleanupRuleNotFound) {
+		t.Fatalf("expected rule of another Space to be hidden, got %v", err)
+	}
+}
@@ -0,0 +1,578 @@
+package space
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const maxDeletionRemovedEntries = 100
+
+var (
+	// ErrDeletionConfirmationMismatch는 delete_data 확인 입력이 Space slug와 다른 경우입니다.
+	ErrDeletionConfirmationMismatch = errors.New("confirmation must match the space slug")
+	// ErrNestedSpaceRoot는 지울 root 아래에(또는 같은 경로에) 다른 Space의 root가 있는 경우입니다.
+	ErrNestedSpaceRoot = errors.New("space root contains another space root")
+)
+
+// DeleteSpaceMode는 Space 삭제 시 root 데이터 처리 방식입니다.
+type DeleteSpaceMode string
+
+const (
+	// DeleteSpaceModeDetach는 DB 등록만 해제하고 데이터는 그대로 둡니다.
+	DeleteSpaceModeDetach DeleteSpaceMode = "detach"
+	// DeleteSpaceModeArchive는 root를 tar.gz로 보관한 뒤 데이터를 제거합니다.
+	DeleteSpaceModeArchive DeleteSpaceMode = "archive"
+	// DeleteSpaceModeDeleteData는 root 데이터를 영구 삭제합니다. slug 확인 입력이 필요합니다.
+	DeleteSpaceModeDeleteData DeleteSpaceMode = "delete_data"
+)
+
+type DeletionState string
+
+const (
+	DeletionStateQueued    DeletionState = "queued"
+	DeletionStateArchiving DeletionState = "archiving"
+	DeletionStateDetaching DeletionState = "detaching"
+	DeletionStateRemoving  DeletionState = "removing"
+	DeletionStateCompleted DeletionState = "completed"
+	DeletionStateFailed    DeletionState = "failed"
+	DeletionStateCanceled  DeletionState = "canceled"
+)
+
+// DeleteSpaceRequest는 Space 삭제 요청 데이터를 정의합니다.
+type DeleteSpaceRequest struct {
+	Mode         DeleteSpaceMode `json:"mode"`
+	ArchivePath  string          `json:"archive_path,omitempty"`
+	Confirmation string          `json:"confirmation,omitempty"`
+}
+
+func (req *DeleteSpaceRequest) Validate() error {
+	if req == nil {
+		return errors.New("request is required")
+	}
+	req.Mode = DeleteSpaceMode(strings.ToLower(strings.TrimSpace(string(req.Mode))))
+	if req.Mode == "" {
+		req.Mode = DeleteSpaceModeDetach
+	}
+	req.ArchivePath = strings.TrimSpace(req.ArchivePath)
+	req.Confirmation = strings.TrimSpace(req.Confirmation)
+
+	switch req.Mode {
+	case DeleteSpaceModeDetach, DeleteSpaceModeDeleteData:
+		return nil
+	case DeleteSpaceModeArchive:
+		if req.ArchivePath == "" {
+			return errors.New("archive_path is required for archive mode")
+		}
+		if !filepath.IsAbs(req.ArchivePath) {
+			return errors.New("archive_path must be an absolute path")
+		}
+		req.ArchivePath = filepath.Clean(req.ArchivePath)
+		return nil
+	default:
+		return fmt.Errorf("mode must be one of %s, %s, %s", DeleteSpaceModeDetach, DeleteSpaceModeArchive, DeleteSpaceModeDeleteData)
+	}
+}
+
+// DeletionJob은 Space 삭제(보관/데이터 삭제) 작업의 진행 상태와 제거 결과입니다.
+// ProcessedItems/ProcessedBytes는 현재 단계(archiving 또는 removing)의 진행률입니다.
+type DeletionJob struct {
+	ID                string          `json:"jobId"`
+	SpaceID           int64           `json:"spaceId"`
+	SpaceName         string          `json:"spaceName"`
+	SpaceSlug         string          `json:"spaceSlug"`
+	Owner             string          `json:"-"`
+	RequestID         string          `json:"-"`
+	Mode              DeleteSpaceMode `json:"mode"`
+	SourcePath        string          `json:"sourcePath"`
+	ArchivePath       string          `json:"archivePath,omitempty"`
+	Status            DeletionState   `json:"status"`
+	FailureReason     string          `json:"failureReason,omitempty"`
+	TotalItems        int             `json:"totalItems"`
+	ProcessedItems    int             `json:"processedItems"`
+	TotalBytes        int64           `json:"totalBytes"`
+	ProcessedBytes    int64           `json:"processedBytes"`
+	ArchiveSize       int64           `json:"archiveSize,omitempty"`
+	Detached          bool            `json:"detached"`
+	RemovedFiles      int             `json:"removedFiles"`
+	RemovedDirs       int             `json:"removedDirs"`
+	RemovedBytes      int64           `json:"removedBytes"`
+	RemovedEntries    []string        `json:"removedEntries,omitempty"`
+	RootRemoved       bool            `json:"rootRemoved"`
+	TrashItemsRemoved int             `json:"trashItemsRemoved"`
+	CreatedAt         time.Time       `json:"createdAt"`
+	UpdatedAt         time.Time       `json:"updatedAt"`
+	FinishedAt        *time.Time      `json:"finishedAt,omitempty"`
+}
+
+func (j *DeletionJob) IsFinished() bool {
+	switch j.Status {
+	case DeletionStateCompleted, DeletionStateFailed, DeletionStateCanceled:
+		return true
+	default:
+		return false
+	}
+}
+
+func (j *DeletionJob) clone() DeletionJob {
+	job := *j
+	job.RemovedEntries = append([]string(nil), j.RemovedEntries...)
+	return job
+}
+
+type deletionTrashPurger interface {
+	ListTrashItems(ctx context.Context, spaceID int64) ([]*TrashItem, error)
+	DeleteTrashItemsBySpace(ctx context.Context, spaceID int64) error
+}
+
+// DeletionManager는 Space 등록 해제와 root 데이터 보관/삭제를 수행합니다.
+// 작업 중에는 Space 쓰기를 동결하며, DB 등록 해제 이후에는 취소할 수 없습니다.
+// 작업 기록과 재시도는 호출자의 작업 큐가 맡고, 여기서는 한 번의 실행만 수행합니다.
+type DeletionManager struct {
+	spaceService *Service
+	trash        deletionTrashPurger
+}
+
+func NewDeletionManager(spaceService *Service) *DeletionManager {
+	return &DeletionManager{spaceService: spaceService}
+}
+
+func (m *DeletionManager) SetTrashPurger(purger deletionTrashPurger) {
+	m.trash = purger
+}
+
+// Prepare는 삭제 방식을 검증하고 queued 상태의 작업 계획을 만듭니다. 실제 처리는 Run이 수행합니다.
+// detach 방식은 작업 없이 동기적으로 처리하므로 여기서 받지 않습니다.
+func (m *DeletionManager) Prepare(ctx context.Context, spaceID int64, req *DeleteSpaceRequest) (*DeletionJob, error) {
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrSpaceValidation, err)
+	}
+	if req.Mode == DeleteSpaceModeDetach {
+		return nil, fmt.Errorf("%w: detach mode does not run as a job", ErrSpaceValidation)
+	}
+
+	spaceObj, err := m.spaceService.GetSpaceByID(ctx, spaceID)
+	if err != nil {
+		return nil, err
+	}
+	archivePath := ""
+	switch req.Mode {
+	case DeleteSpaceModeArchive:
+		archivePath, err = resolveDeletionArchivePath(spaceObj, req.ArchivePath, time.Now())
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrSpaceValidation, err)
+		}
+	case DeleteSpaceModeDeleteData:
+		if req.Confirmation != spaceObj.ProtocolName() {
+			return nil, fmt.Errorf("%w: %w", ErrSpaceValidation, ErrDeletionConfirmationMismatch)
+		}
+	}
+	if err := m.checkNestedSpaceRoots(ctx, spaceID, spaceObj.SpacePath); err != nil {
+		return nil, err
+	}
+
+	return &DeletionJob{
+		SpaceID:     spaceID,
+		SpaceName:   spaceObj.SpaceName,
+		SpaceSlug:   spaceObj.ProtocolName(),
+		Mode:        req.Mode,
+		SourcePath:  filepath.Clean(spaceObj.SpacePath),
+		ArchivePath: archivePath,
+		Status:      DeletionStateQueued,
+	}, nil
+}
+
+// Run은 삭제를 한 번 수행하고 단계마다 상태를 run에 남깁니다. job은 지난 실행이 남긴 상태입니다.
+// 재시도나 서버 재시작으로 다시 호출되면 이미 만든 보관 파일은 그대로 쓰고,
+// 등록 해제 단계에 들어선 작업은 남은 데이터 제거를 이어서 합니다.
+// 요청 뒤에 root가 바뀌었으면 ErrRelocationSourceChanged를, root 아래에 다른 Space의 root가 있으면
+// ErrNestedSpaceRoot를 반환하고 아무 것도 지우지 않습니다.
+func (m *DeletionManager) Run(ctx context.Context, job *DeletionJob, run SpaceJobRun) error {
+	state := job.clone()
+	committed := state.Detached || state.Status == DeletionStateDetaching || state.Status == DeletionStateRemoving
+
+	drainCtx, cancelDrain := context.WithTimeout(ctx, writeDrainTimeout)
+	release, err := m.spaceService.FreezeWrites(drainCtx, state.SpaceID)
+	cancelDrain()
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	if !committed {
+		spaceObj, err := m.spaceService.GetSpaceByID(ctx, state.SpaceID)
+		if err != nil {
+			return err
+		}
+		// 그 사이 이전 작업이 root를 바꿨다면 옛 경로를 지우고 새 root를 버려두게 된다.
+		if filepath.Clean(spaceObj.SpacePath) != filepath.Clean(state.SourcePath) {
+			return fmt.Errorf("%w: expected %s, found %s", ErrRelocationSourceChanged, state.SourcePath, spaceObj.SpacePath)
+		}
+		if err := m.checkNestedSpaceRoots(ctx, state.SpaceID, state.SourcePath); err != nil {
+			return err
+		}
+		totalItems, totalBytes, err := scanRelocationSource(ctx, state.SourcePath)
+		if err != nil {
+			return err
+		}
+		state.TotalItems = totalItems
+		state.TotalBytes = totalBytes
+		run.SetTotals(totalItems, totalBytes)
+
+		if state.Mode == DeleteSpaceModeArchive {
+			if err := m.archive(ctx, &state, run); err != nil {
+				return err
+			}
+		}
+	}
+
+	// 여기서부터는 취소하지 않는다. 등록 해제 후 데이터를 남겨두면 어느 Space에도 속하지 않는 파일이 된다.
+	if !run.PreventCancel() {
+		return context.Canceled
+	}
+	detachCtx := context.Background()
+	state.Status = DeletionStateDetaching
+	_ = run.SetResult(state)
+
+	var followUpErrors []string
+	if !state.Detached {
+		// FK cascade가 켜진 연결에서는 Space 삭제와 함께 휴지통 행도 지워지므로 개수는 미리 센다.
+		var trashItems []*TrashItem
+		if m.trash != nil {
+			items, err := m.trash.ListTrashItems(detachCtx, state.SpaceID)
+			if err != nil {
+				followUpErrors = append(followUpErrors, fmt.Sprintf("trash items: %v", err))
+			}
+			trashItems = items
+		}
+
+		// 지난 실행이 등록 해제까지 마치고 멈췄으면 Space가 이미 없다.
+		if err := m.spaceService.DeleteSpace(detachCtx, state.SpaceID); err != nil && !errors.Is(err, ErrSpaceNotFound) {
+			return fmt.Errorf("failed to detach space: %w", err)
+		}
+		state.Detached = true
+		_ = run.SetResult(state)
+
+		if m.trash != nil {
+			if err := m.trash.DeleteTrashItemsBySpace(detachCtx, state.SpaceID); err != nil {
+				followUpErrors = append(followUpErrors, fmt.Sprintf("trash items: %v", err))
+			} else {
+				state.TrashItemsRemoved = len(trashItems)
+			}
+		}
+	}
+
+	// 등록 해제 뒤에 다른 Space가 이 경로 아래에 만들어졌을 수도 있으므로 지우기 직전에 다시 확인한다.
+	if err := m.checkNestedSpaceRoots(detachCtx, state.SpaceID, state.SourcePath); err != nil {
+		return err
+	}
+	state.Status = DeletionStateRemoving
+	_ = run.SetResult(state)
+	run.SetTotals(state.TotalItems, state.TotalBytes)
+	removeErr := removeSpaceData(state.SourcePath, func(relPath string, isDir bool, size int64) {
+		run.Advance(1, size)
+		if isDir {
+			state.RemovedDirs++
+		} else {
+			state.RemovedFiles++
+			state.RemovedBytes += size
+		}
+		if !strings.Contains(relPath, string(filepath.Separator)) && len(state.RemovedEntries) < maxDeletionRemovedEntries {
+			state.RemovedEntries = append(state.RemovedEntries, filepath.ToSlash(relPath))
+		}
+	})
+	if removeErr != nil {
+		_ = run.SetResult(state)
+		return fmt.Errorf("failed to remove space data: %w", removeErr)
+	}
+
+	// root가 마운트 지점이면 제거되지 않을 수 있다. 내용은 모두 지웠으므로 실패로 보지 않는다.
+	state.RootRemoved = os.Remove(state.SourcePath) == nil
+	state.Status = DeletionStateCompleted
+	if len(followUpErrors) > 0 {
+		state.FailureReason = "deleted with follow-up errors: " + strings.Join(followUpErrors, "; ")
+	}
+	return run.SetResult(state)
+}
+
+// checkNestedSpaceRoots는 root와 같거나 그 아래에 있는 다른 Space의 root가 없는지 확인합니다.
+// Space root끼리의 포함 관계는 등록 때 막지 않으므로, 지우기 전에 직접 확인해야 다른 Space의 데이터를 지우지 않습니다.
+func (m *DeletionManager) checkNestedSpaceRoots(ctx context.Context, spaceID int64, root string) error {
+	spaces, err := m.spaceService.GetAllSpaces(ctx)
+	if err != nil {
+		return err
+	}
+	root = filepath.Clean(root)
+	for _, other := range spaces {
+		otherRoot := filepath.Clean(other.SpacePath)
+		if other.ID != spaceID && (otherRoot == root || isNestedPath(root, otherRoot)) {
+			return fmt.Errorf("%w: %q uses %s", ErrNestedSpaceRoot, other.SpaceName, otherRoot)
+		}
+	}
+	return nil
+}
+
+// archive는 root를 보관 파일로 기록합니다. 지난 실행이 보관 파일을 완성한 뒤 멈췄으면 다시 만들지 않습니다.
+func (m *DeletionManager) archive(ctx context.Context, state *DeletionJob, run SpaceJobRun) error {
+	if state.Status == DeletionStateArchiving {
+		if info, err := os.Stat(state.ArchivePath); err == nil {
+			if err := removePartialSpaceArchives(state.ArchivePath); err != nil {
+				return err
+			}
+			state.ArchiveSize = info.Size()
+			return run.SetResult(*state)
+		}
+	}
+
+	state.Status = DeletionStateArchiving
+	_ = run.SetResult(*state)
+	archiveSize, err := writeSpaceArchive(ctx, state.SourcePath, state.ArchivePath, state.SpaceSlug, run.Advance)
+	if err != nil {
+		return fmt.Errorf("failed to archive space data: %w", err)
+	}
+	state.ArchiveSize = archiveSize
+	return run.SetResult(*state)
+}
+
+// Discard는 실패/취소로 끝난 작업이 남긴 보관 임시 파일을 지웁니다.
+// 서버 재시작으로 Run이 정리하지 못하고 끝난 경우를 위한 것입니다.
+func (m *DeletionManager) Discard(job *DeletionJob) error {
+	if job.Mode != DeleteSpaceModeArchive || job.ArchivePath == "" {
+		return nil
+	}
+	return removePartialSpaceArchives(job.ArchivePath)
+}
+
+// resolveDeletionArchivePath는 보관 파일 경로를 확정합니다.
+// 기존 디렉터리를 지정하면 그 안에 {slug}-{시각}.tar.gz 이름으로 만듭니다.
+func resolveDeletionArchivePath(spaceObj *Space, archivePath string, now time.Time) (string, error) {
+	resolved := archivePath
+	info, err := os.Stat(archivePath)
+	switch {
+	case err == nil && info.IsDir():
+		resolved = filepath.Join(archivePath, fmt.Sprintf("%s-%s.tar.gz", spaceObj.ProtocolName(), now.Format("20060102-150405")))
+		if _, err := os.Lstat(resolved); err == nil {
+			return "", errors.New("archive_path already exists")
+		}
+	case err == nil:
+		return "", errors.New("archive_path already exists")
+	case os.IsNotExist(err):
+		lower := strings.ToLower(archivePath)
+		if !strings.HasSuffix(lower, ".tar.gz") && !strings.HasSuffix(lower, ".tgz") {
+			return "", errors.New("archive_path must end with .tar.gz or .tgz")
+		}
+		parent, err := os.Stat(filepath.Dir(archivePath))
+		if err != nil || !parent.IsDir() {
+			return "", errors.New("archive_path parent directory must exist")
+		}
+	default:
+		return "", fmt.Errorf("failed to inspect archive_path: %w", err)
+	}
+
+	root := filepath.Clean(spaceObj.SpacePath)
+	if resolved == root || isNestedPath(root, resolved) {
+		return "", errors.New("archive_path must be outside the space root")
+	}
+	return resolved, nil
+}
+
+// writeSpaceArchive는 root 전체(휴지통 포함)를 tar.gz로 기록합니다.
+// 임시 파일에 쓴 뒤 완료 시점에만 최종 경로로 옮기며, 실패/취소 시 임시 파일을 지웁니다.
+func writeSpaceArchive(ctx context.Context, sourceRoot string, archivePath string, rootName string, progress func(items int, bytes int64)) (int64, error) {
+	// 서버가 도중에 멈췄던 이전 시도의 임시 파일은 지우고 새로 쓴다.
+	if err := removePartialSpaceArchives(archivePath); err != nil {
+		return 0, err
+	}
+	temp, err := os.CreateTemp(filepath.Dir(archivePath), "."+filepath.Base(archivePath)+".partial-*")
+	if err != nil {
+		return 0, err
+	}
+	tempPath := temp.Name()
+	committed := false
+	defer func() {
+		if !committed {
+			_ = os.Remove(tempPath)
+		}
+	}()
+
+	gzipWriter := gzip.NewWriter(temp)
+	tarWriter := tar.NewWriter(gzipWriter)
+	walkErr := filepath.WalkDir(sourceRoot, func(filePath string, entry fs.DirEntry, walkErr error) error {
+		if walkErr != nil {
+			return walkErr
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		relPath, err := filepath.Rel(sourceRoot, filePath)
+		if err != nil {
+			return err
+		}
+		name := rootName
+		if relPath != "." {
+			name = path.Join(rootName, filepath.ToSlash(relPath))
+		}
+		written, err := writeSpaceArchiveEntry(ctx, tarWriter, filePath, name, entry)
+		if err != nil {
+			return fmt.Errorf("failed to archive %s: %w", filepath.ToSlash(relPath), err)
+		}
+		if relPath != "." {
+			progress(1, written)
+		}
+		return nil
+	})
+	if walkErr != nil {
+		_ = temp.Close()
+		return 0, walkErr
+	}
+	if err := tarWriter.Close(); err != nil {
+		_ = temp.Close()
+		return 0, err
+	}
+	if err := gzipWriter.Close(); err != nil {
+		_ = temp.Close()
+		return 0, err
+	}
+	if err := temp.Sync(); err != nil {
+		_ = temp.Close()
+		return 0, err
+	}
+	if err := temp.Close(); err != nil {
+		return 0, err
+	}
+
+	info, err := os.Stat(tempPath)
+	if err != nil {
+		return 0, err
+	}
+	if _, err := os.Lstat(archivePath); err == nil {
+		return 0, errors.New("archive_path already exists")
+	}
+	if err := os.Rename(tempPath, archivePath); err != nil {
+		return 0, err
+	}
+	committed = true
+	return info.Size(), nil
+}
+
+// removePartialSpaceArchives는 archivePath를 위해 만든 임시 파일을 모두 지웁니다.
+func removePartialSpaceArchives(archivePath string) error {
+	pattern := filepath.Join(filepath.Dir(archivePath), "."+filepath.Base(archivePath)+".partial-*")
+	matches, err := filepath.Glob(pattern)
+	if err != nil {
+		return err
+	}
+	for _, match := range matches {
+		if err := os.Remove(match); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeSpaceArchiveEntry(ctx context.Context, tarWriter *tar.Writer, filePath string, name string, entry fs.DirEntry) (int64, error) {
+	info, err := entry.Info()
+	if err != nil {
+		return 0, err
+	}
+
+	linkTarget := ""
+	switch {
+	case entry.IsDir():
+		name += "/"
+	case entry.Type()&fs.ModeSymlink != 0:
+		linkTarget, err = os.Readlink(filePath)
+		if err != nil {
+			return 0, err
+		}
+	case entry.Type().IsRegular():
+	default:
+		// 소켓/장치 파일 등은 보관 대상이 아니다.
+		return 0, nil
+	}
+
+	header, err := tar.FileInfoHeader(info, linkTarget)
+	if err != nil {
+		return 0, err
+	}
+	header.Name = name
+	header.Format = tar.FormatPAX
+	if err := tarWriter.WriteHeader(header); err != nil {
+		return 0, err
+	}
+	if !entry.Type().IsRegular() {
+		return 0, nil
+	}
+
+	file, err := os.Open(filePath)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	written, err := copyRelocationStream(ctx, tarWriter, io.LimitReader(file, header.Size), nil)
+	if err != nil {
+		return written, err
+	}
+	if written != header.Size {
+		return written, fmt.Errorf("file changed while archiving: expected %d bytes, got %d", header.Size, written)
+	}
+	return written, nil
+}
+
+// removeSpaceData는 root 내부 항목을 하위부터 제거하고 제거한 항목마다 progress를 호출합니다.
+// root 디렉터리 자체는 호출자가 정리합니다.
+func removeSpaceData(sourceRoot string, progress func(relPath string, isDir bool, size int64)) error {
+	type removalTarget struct {
+		path  string
+		isDir bool
+		size  int64
+	}
+
+	var targets []removalTarget
+	err := filepath.WalkDir(sourceRoot, func(filePath string, entry fs.DirEntry, walkErr error) error {
+		if walkErr != nil {
+			if os.IsNotExist(walkErr) {
+				return nil
+			}
+			return walkErr
+		}
+		if filePath == sourceRoot {
+			return nil
+		}
+		var size int64
+		if entry.Type().IsRegular() {
+			if info, err := entry.Info(); err == nil {
+				size = info.Size()
+			}
+		}
+		targets = append(targets, removalTarget{path: filePath, isDir: entry.IsDir(), size: size})
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// WalkDir는 부모를 자식보다 먼저 방문하므로 역순으로 지우면 디렉터리가 항상 비어 있다.
+	for i := len(targets) - 1; i >= 0; i-- {
+		target := targets[i]
+		if err := os.Remove(target.path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		relPath, err := filepath.Rel(sourceRoot, target.path)
+		if err != nil {
+			return err
+		}
+		progress(relPath, target.isDir, target.size)
+	}
+	return nil
+}
@@ -0,0 +1,364 @@
+package space_test
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"taeu.kr/cohesion/internal/space"
+	spaceStore "taeu.kr/cohesion/internal/space/store"
+)
+
+func writeDeletionFixture(t *testing.T, root string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Join(root, "docs"), 0o755); err != nil {
+		t.Fatalf("mkdir docs: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "docs", "a.txt"), []byte("alpha"), 0o644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Join(root, ".cohesion_trash"), 0o755); err != nil {
+		t.Fatalf("mkdir trash: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(root, ".cohesion_trash", "1-old.txt"), []byte("old"), 0o644); err != nil {
+		t.Fatalf("write trash file: %v", err)
+	}
+}
+
+func TestDeletionManager_ArchivesThenRemovesData(t *testing.T) {
+	service, db := setupSlugSpaceService(t)
+	ctx := context.Background()
+
+	root := filepath.Join(t.TempDir(), "media")
+	if err := os.Mkdir(root, 0o755); err != nil {
+		t.Fatalf("mkdir root: %v", err)
+	}
+	writeDeletionFixture(t, root)
+
+	created, err := service.CreateSpace(ctx, &space.CreateSpaceRequest{SpaceName: "Media", SpacePath: root})
+	if err != nil {
+		t.Fatalf("create space: %v", err)
+	}
+	trashService := space.NewTrashService(spaceStore.NewTrashStore(db))
+	if _, err := trashService.CreateTrashItem(ctx, &space.CreateTrashItemRequest{
+		SpaceID:      created.ID,
+		OriginalPath: "old.txt",
+		StoragePath:  ".cohesion_trash/1-old.txt",
+		ItemName:     "old.txt",
+		DeletedBy:    "admin",
+	}); err != nil {
+		t.Fatalf("create trash item: %v", err)
+	}
+
+	manager := space.NewDeletionManager(service)
+	manager.SetTrashPurger(trashService)
+
+	archiveDir := t.TempDir()
+	plan, err := manager.Prepare(ctx, created.ID, &space.DeleteSpaceRequest{
+		Mode:        space.DeleteSpaceModeArchive,
+		ArchivePath: archiveDir,
+	})
+	if err != nil {
+		t.Fatalf("prepare deletion: %v", err)
+	}
+	if !strings.HasPrefix(filepath.Base(plan.ArchivePath), "media-") || !strings.HasSuffix(plan.ArchivePath, ".tar.gz") {
+		t.Fatalf("unexpected archive path %q", plan.ArchivePath)
+	}
+
+	run := &recordingJobRun{}
+	if err := manager.Run(ctx, plan, run); err != nil {
+		t.Fatalf("run deletion: %v", err)
+	}
+	var finished space.DeletionJob
+	run.decode(t, &finished)
+	if finished.Status != space.DeletionStateCompleted {
+		t.Fatalf("expected completed deletion, got %s (%s)", finished.Status, finished.FailureReason)
+	}
+	if !finished.Detached || !finished.RootRemoved {
+		t.Fatalf("expected detached space and removed root, got %+v", finished)
+	}
+	if finished.RemovedFiles != 2 || finished.RemovedDirs != 2 || finished.RemovedBytes != int64(len("alpha")+len("old")) {
+		t.Fatalf("unexpected removal summary: %+v", finished)
+	}
+	if finished.TrashItemsRemoved != 1 {
+		t.Fatalf("expected one trash row removed, got %d", finished.TrashItemsRemoved)
+	}
+	sort.Strings(finished.RemovedEntries)
+	if strings.Join(finished.RemovedEntries, ",") != ".cohesion_trash,docs" {
+		t.Fatalf("unexpected removed entries %v", finished.RemovedEntries)
+	}
+
+	if _, err := os.Stat(root); !os.IsNotExist(err) {
+		t.Fatalf("expected space root to be removed, got %v", err)
+	}
+	if _, err := service.GetSpaceByID(ctx, created.ID); err == nil {
+		t.Fatal("expected space row to be deleted")
+	}
+	items, err := trashService.ListTrashItems(ctx, created.ID)
+	if err != nil || len(items) != 0 {
+		t.Fatalf("expected no trash rows, got %d (%v)", len(items), err)
+	}
+
+	archiveFile, err := os.Open(finished.ArchivePath)
+	if err != nil {
+		t.Fatalf("open archive: %v", err)
+	}
+	defer archiveFile.Close()
+	gzipReader, err := gzip.NewReader(archiveFile)
+	if err != nil {
+		t.Fatalf("open gzip: %v", err)
+	}
+	tarReader := tar.NewReader(gzipReader)
+	contents := map[string]string{}
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("read tar: %v", err)
+		}
+		if header.Typeflag == tar.TypeReg {
+			data, _ := io.ReadAll(tarReader)
+			contents[header.Name] = string(data)
+		}
+	}
+	if contents["media/docs/a.txt"] != "alpha" || contents["media/.cohesion_trash/1-old.txt"] != "old" {
+		t.Fatalf("unexpected archive contents %v", contents)
+	}
+}
+
+func TestDeletionManager_DeleteDataRequiresTypedConfirmation(t *testing.T) {
+	service, _ := setupSlugSpaceService(t)
+	ctx := context.Background()
+
+	root := t.TempDir()
+	writeDeletionFixture(t, root)
+	created, err := service.CreateSpace(ctx, &space.CreateSpaceRequest{SpaceName: "Team Docs", SpacePath: root})
+	if err != nil {
+		t.Fatalf("create space: %v", err)
+	}
+
+	manager := space.NewDeletionManager(service)
+	for _, confirmation := range []string{"", "Team Docs", "team"} {
+		_, err := manager.Prepare(ctx, created.ID, &space.DeleteSpaceRequest{Mode: space.DeleteSpaceModeDeleteData, Confirmation: confirmation})
+		if err == nil || !strings.Contains(err.Error(), "confirmation") {
+			t.Fatalf("expected confirmation error for %q, got %v", confirmation, err)
+		}
+	}
+
+	plan, err := manager.Prepare(ctx, created.ID, &space.DeleteSpaceRequest{Mode: space.DeleteSpaceModeDeleteData, Confirmation: "team-docs"})
+	if err != nil {
+		t.Fatalf("prepare deletion: %v", err)
+	}
+	if err := manager.Run(ctx, plan, &recordingJobRun{}); err != nil {
+		t.Fatalf("run deletion: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "docs", "a.txt")); !os.IsNotExist(err) {
+		t.Fatalf("expected data to be removed, got %v", err)
+	}
+}
+
+func TestDeletionManager_CancelKeepsSpaceAndData(t *testing.T) {
+	service, _ := setupSlugSpaceService(t)
+	ctx := context.Background()
+
+	root := t.TempDir()
+	writeDeletionFixture(t, root)
+	created, err := service.CreateSpace(ctx, &space.CreateSpaceRequest{SpaceName: "Media", SpacePath: root})
+	if err != nil {
+		t.Fatalf("create space: %v", err)
+	}
+
+	archivePath := filepath.Join(t.TempDir(), "media.tar.gz")
+	manager := space.NewDeletionManager(service)
+	plan, err := manager.Prepare(ctx, created.ID, &space.DeleteSpaceRequest{Mode: space.DeleteSpaceModeArchive, ArchivePath: archivePath})
+	if err != nil {
+		t.Fatalf("prepare deletion: %v", err)
+	}
+	if err := service.EnsureWritable(ctx, created.ID); err != nil {
+		t.Fatalf("expected queued deletion not to freeze writes yet, got %v", err)
+	}
+
+	canceledCtx, cancel := context.WithCancel(ctx)
+	cancel()
+	run := &recordingJobRun{}
+	if err := manager.Run(canceledCtx, plan, run); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected canceled deletion, got %v", err)
+	}
+	if run.committed {
+		t.Fatal("expected canceled deletion not to reach the detach step")
+	}
+	if _, err := os.Stat(archivePath); !os.IsNotExist(err) {
+		t.Fatalf("expected no archive after cancel, got %v", err)
+	}
+	if _, err := service.GetSpaceByID(ctx, created.ID); err != nil {
+		t.Fatalf("expected space to remain, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "docs", "a.txt")); err != nil {
+		t.Fatalf("expected data to remain, got %v", err)
+	}
+	if err := service.EnsureWritable(ctx, created.ID); err != nil {
+		t.Fatalf("expected writes after cancel, got %v", err)
+	}
+}
+
+func TestDeletionManager_ResumesInterruptedRun(t *testing.T) {
+	service, _ := setupSlugSpaceService(t)
+	ctx := context.Background()
+	manager := space.NewDeletionManager(service)
+
+	// 보관 파일을 완성한 뒤 멈췄다면 다시 만들지 않고 남은 임시 파일만 지운다.
+	archivedRoot := t.TempDir()
+	writeDeletionFixture(t, archivedRoot)
+	archived, err := service.CreateSpace(ctx, &space.CreateSpaceRequest{SpaceName: "Media", SpacePath: archivedRoot})
+	if err != nil {
+		t.Fatalf("create space: %v", err)
+	}
+	archivePath := filepath.Join(t.TempDir(), "media.tar.gz")
+	plan, err := manager.Prepare(ctx, archived.ID, &space.DeleteSpaceRequest{Mode: space.DeleteSpaceModeArchive, ArchivePath: archivePath})
+	if err != nil {
+		t.Fatalf("prepare deletion: %v", err)
+	}
+	partialPath := filepath.Join(filepath.Dir(archivePath), ".media.tar.gz.partial-123")
+	if err := os.WriteFile(partialPath, []byte("partial"), 0o644); err != nil {
+		t.Fatalf("write partial archive: %v", err)
+	}
+	if err := os.WriteFile(archivePath, []byte("finished archive"), 0o644); err != nil {
+		t.Fatalf("write archive: %v", err)
+	}
+	plan.Status = space.DeletionStateArchiving
+	run := &recordingJobRun{}
+	if err := manager.Run(ctx, plan, run); err != nil {
+		t.Fatalf("resume archived deletion: %v", err)
+	}
+	var finished space.DeletionJob
+	run.decode(t, &finished)
+	if finished.Status != space.DeletionStateCompleted || finished.ArchiveSize != int64(len("finished archive")) {
+		t.Fatalf("expected completed deletion reusing the archive, got %+v", finished)
+	}
+	if _, err := os.Stat(partialPath); !os.IsNotExist(err) {
+		t.Fatalf("expected partial archive to be removed, got %v", err)
+	}
+
+	// 등록 해제 뒤에 멈췄다면 Space 없이도 남은 데이터를 지운다.
+	detachedRoot := t.TempDir()
+	writeDeletionFixture(t, detachedRoot)
+	detached, err := service.CreateSpace(ctx, &space.CreateSpaceRequest{SpaceName: "Team Docs", SpacePath: detachedRoot})
+	if err != nil {
+		t.Fatalf("create space: %v", err)
+	}
+	plan, err = manager.Prepare(ctx, detached.ID, &space.DeleteSpaceRequest{Mode: space.DeleteSpaceModeDeleteData, Confirmation: "team-docs"})
+	if err != nil {
+		t.Fatalf("prepare deletion: %v", err)
+	}
+	if err := service.DeleteSpace(ctx, detached.ID); err != nil {
+		t.Fatalf("detach space: %v", err)
+	}
+	plan.Status = space.DeletionStateDetaching
+	run = &recordingJobRun{}
+	if err := manager.Run(ctx, plan, run); err != nil {
+		t.Fatalf("resume detached deletion: %v", err)
+	}
+	run.decode(t, &finished)
+	if finished.Status != space.DeletionStateCompleted || !finished.Detached || finished.RemovedFiles != 2 {
+		t.Fatalf("expected completed removal after detach, got %+v", finished)
+	}
+	if _, err := os.Stat(filepath.Join(detachedRoot, "docs", "a.txt")); !os.IsNotExist(err) {
+		t.Fatalf("expected data to be removed, got %v", err)
+	}
+}
+
+func TestDeletionManager_RejectsInvalidArchivePaths(t *testing.T) {
+	service, _ := setupSlugSpaceService(t)
+	ctx := context.Background()
+
+	root := t.TempDir()
+	created, err := service.CreateSpace(ctx, &space.CreateSpaceRequest{SpaceName: "Media", SpacePath: root})
+	if err != nil {
+		t.Fatalf("create space: %v", err)
+	}
+	existing := filepath.Join(t.TempDir(), "existing.tar.gz")
+	if err := os.WriteFile(existing, []byte("x"), 0o644); err != nil {
+		t.Fatalf("write existing archive: %v", err)
+	}
+
+	manager := space.NewDeletionManager(service)
+	for _, archivePath := range []string{
+		"",
+		"relative.tar.gz",
+		existing,
+		filepath.Join(root, "inside.tar.gz"),
+		filepath.Join(t.TempDir(), "archive.zip"),
+		filepath.Join(t.TempDir(), "missing", "archive.tar.gz"),
+	} {
+		_, err := manager.Prepare(ctx, created.ID, &space.DeleteSpaceRequest{Mode: space.DeleteSpaceModeArchive, ArchivePath: archivePath})
+		if err == nil || !strings.Contains(err.Error(), "validation failed") {
+			t.Fatalf("expected validation failure for %q, got %v", archivePath, err)
+		}
+	}
+}
+
+func TestDeletionManager_RefusesChangedRootAndNestedSpaces(t *testing.T) {
+	service, _ := setupSlugSpaceService(t)
+	ctx := context.Background()
+
+	root := t.TempDir()
+	writeDeletionFixture(t, root)
+	created, err := service.CreateSpace(ctx, &space.CreateSpaceRequest{SpaceName: "Parent", SpacePath: root})
+	if err != nil {
+		t.Fatalf("create space: %v", err)
+	}
+	manager := space.NewDeletionManager(service)
+	deleteData := &space.DeleteSpaceRequest{Mode: space.DeleteSpaceModeDeleteData, Confirmation: "parent"}
+
+	// 요청 뒤에 이전 작업이 root를 바꿨으면 옛 경로를 지우지 않고 Space도 남긴다.
+	plan, err := manager.Prepare(ctx, created.ID, deleteData)
+	if err != nil {
+		t.Fatalf("prepare deletion: %v", err)
+	}
+	movedRoot := t.TempDir()
+	if _, err := service.UpdateSpaceRoot(ctx, created.ID, movedRoot); err != nil {
+		t.Fatalf("move space root: %v", err)
+	}
+	if err := manager.Run(ctx, plan, &recordingJobRun{}); !errors.Is(err, space.ErrRelocationSourceChanged) {
+		t.Fatalf("expected source changed error, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "docs", "a.txt")); err != nil {
+		t.Fatalf("expected old root to be left alone, got %v", err)
+	}
+	if _, err := service.GetSpaceByID(ctx, created.ID); err != nil {
+		t.Fatalf("expected space to stay registered, got %v", err)
+	}
+	if _, err := service.UpdateSpaceRoot(ctx, created.ID, root); err != nil {
+		t.Fatalf("restore space root: %v", err)
+	}
+
+	// 요청 뒤에 root 아래에 다른 Space가 생겼으면 그 데이터까지 지우지 않는다.
+	plan, err = manager.Prepare(ctx, created.ID, deleteData)
+	if err != nil {
+		t.Fatalf("prepare deletion: %v", err)
+	}
+	nestedRoot := filepath.Join(root, "docs")
+	if _, err := service.CreateSpace(ctx, &space.CreateSpaceRequest{SpaceName: "Child", SpacePath: nestedRoot}); err != nil {
+		t.Fatalf("create nested space: %v", err)
+	}
+	if err := manager.Run(ctx, plan, &recordingJobRun{}); !errors.Is(err, space.ErrNestedSpaceRoot) {
+		t.Fatalf("expected nested space root error, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(nestedRoot, "a.txt")); err != nil {
+		t.Fatalf("expected nested space data to be kept, got %v", err)
+	}
+
+	// 이미 포함 관계가 있으면 요청부터 거절한다.
+	if _, err := manager.Prepare(ctx, created.ID, deleteData); !errors.Is(err, space.ErrNestedSpaceRoot) {
+		t.Fatalf("expected prepare to reject nested space root, got %v", err)
+	}
+}
@@ -0,0 +1,178 @@
+// Package exif는 사진 파일의 EXIF에서 촬영 시각만 읽습니다.
+// JPEG(APP1 Exif)와 TIFF 기반 파일(TIFF, DNG 등)을 지원하며 다른 형식은 촬영 시각이 없는 것으로 봅니다.
+package exif
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// MaxHeaderBytes는 촬영 시각을 찾으려고 파일 앞에서 읽는 최대 길이입니다.
+const MaxHeaderBytes = 256 << 10
+
+const (
+	tagDateTime          = 0x0132
+	tagExifIFD           = 0x8769
+	tagDateTimeOriginal  = 0x9003
+	tagDateTimeDigitized = 0x9004
+
+	typeASCII = 2
+	typeLong  = 4
+
+	dateTimeLayout = "2006:01:02 15:04:05"
+)
+
+// errNoExif는 EXIF가 없거나 읽을 수 없는 경우입니다. 호출자에게는 ok=false로만 알립니다.
+var errNoExif = errors.New("no exif data")
+
+// DateTaken은 path의 촬영 시각을 반환합니다. DateTimeOriginal, DateTimeDigitized, DateTime 순서로 찾습니다.
+// EXIF 시각에는 시간대가 없으므로 적힌 값을 그대로 UTC로 둡니다. 찾지 못하면 ok가 false입니다.
+// error는 파일을 읽지 못한 경우에만 반환합니다.
+func DateTaken(path string) (taken time.Time, ok bool, err error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	defer file.Close()
+
+	header, err := io.ReadAll(io.LimitReader(file, MaxHeaderBytes))
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	taken, err = Parse(header)
+	if err != nil {
+		return time.Time{}, false, nil
+	}
+	return taken, true, nil
+}
+
+// Parse는 파일 앞부분 data에서 촬영 시각을 찾습니다.
+func Parse(data []byte) (time.Time, error) {
+	switch {
+	case len(data) >= 2 && data[0] == 0xFF && data[1] == 0xD8:
+		tiff, err := jpegExifSegment(data)
+		if err != nil {
+			return time.Time{}, err
+		}
+		return parseTIFF(tiff)
+	case bytes.HasPrefix(data, []byte("II*\x00")) || bytes.HasPrefix(data, []byte("MM\x00*")):
+		return parseTIFF(data)
+	default:
+		return time.Time{}, errNoExif
+	}
+}
+
+// jpegExifSegment는 JPEG 마커를 따라가며 "Exif\0\0"로 시작하는 APP1 세그먼트의 TIFF 부분을 찾습니다.
+func jpegExifSegment(data []byte) ([]byte, error) {
+	offset := 2
+	for offset+4 <= len(data) {
+		if data[offset] != 0xFF {
+			return nil, errNoExif
+		}
+		marker := data[offset+1]
+		if marker == 0xFF {
+			offset++
+			continue
+		}
+		// SOS 뒤에는 영상 데이터만 있습니다.
+		if marker == 0xDA || marker == 0xD9 {
+			return nil, errNoExif
+		}
+		length := int(binary.BigEndian.Uint16(data[offset+2:]))
+		if length < 2 || offset+2+length > len(data) {
+			return nil, errNoExif
+		}
+		segment := data[offset+4 : offset+2+length]
+		if marker == 0xE1 && bytes.HasPrefix(segment, []byte("Exif\x00\x00")) {
+			return segment[6:], nil
+		}
+		offset += 2 + length
+	}
+	return nil, errNoExif
+}
+
+func parseTIFF(data []byte) (time.Time, error) {
+	if len(data) < 8 {
+		return time.Time{}, errNoExif
+	}
+	var order binary.ByteOrder
+	switch string(data[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return time.Time{}, errNoExif
+	}
+	if order.Uint16(data[2:]) != 42 {
+		return time.Time{}, errNoExif
+	}
+
+	ifd0 := readIFD(data, order, order.Uint32(data[4:]))
+	candidates := make([]string, 0, 3)
+	if exifOffset, ok := ifd0.long(order, tagExifIFD); ok {
+		exifIFD := readIFD(data, order, exifOffset)
+		candidates = append(candidates, exifIFD.ascii(data, order, tagDateTimeOriginal), exifIFD.ascii(data, order, tagDateTimeDigitized))
+	}
+	candidates = append(candidates, ifd0.ascii(data, order, tagDateTime))
+	for _, candidate := range candidates {
+		if taken, err := time.Parse(dateTimeLayout, candidate); err == nil {
+			return taken, nil
+		}
+	}
+	return time.Time{}, errNoExif
+}
+
+// ifdEntry는 IFD 항목 하나의 원본 12바이트입니다.
+type ifdEntry []byte
+
+type ifd map[uint16]ifdEntry
+
+func readIFD(data []byte, order binary.ByteOrder, offset uint32) ifd {
+	entries := make(ifd)
+	start := int(offset)
+	if offset == 0 || start+2 > len(data) {
+		return entries
+	}
+	count := int(order.Uint16(data[start:]))
+	for i := 0; i < count; i++ {
+		at := start + 2 + i*12
+		if at+12 > len(data) {
+			break
+		}
+		entries[order.Uint16(data[at:])] = ifdEntry(data[at : at+12])
+	}
+	return entries
+}
+
+func (d ifd) long(order binary.ByteOrder, tag uint16) (uint32, bool) {
+	entry, ok := d[tag]
+	if !ok || order.Uint16(entry[2:]) != typeLong {
+		return 0, false
+	}
+	return order.Uint32(entry[8:]), true
+}
+
+func (d ifd) ascii(data []byte, order binary.ByteOrder, tag uint16) string {
+	entry, ok := d[tag]
+	if !ok || order.Uint16(entry[2:]) != typeASCII {
+		return ""
+	}
+	count := int(order.Uint32(entry[4:]))
+	var value []byte
+	if count <= 4 {
+		value = entry[8 : 8+count]
+	} else {
+		start := int(order.Uint32(entry[8:]))
+		if start < 0 || start+count > len(data) {
+			return ""
+		}
+		value = data[start : start+count]
+	}
+	return strings.TrimSpace(strings.TrimRight(string(value), "\x00"))
+}
@@ -0,0 +1,89 @@
+package exif_test
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"taeu.kr/cohesion/internal/space/exif"
+)
+
+// buildTIFF는 IFD0에서 Exif IFD를 가리키고 그 안에 DateTimeOriginal만 둔 최소 TIFF를 만듭니다.
+func buildTIFF(order binary.ByteOrder, taken string) []byte {
+	data := make([]byte, 44)
+	if order == binary.LittleEndian {
+		copy(data, "II")
+	} else {
+		copy(data, "MM")
+	}
+	order.PutUint16(data[2:], 42)
+	order.PutUint32(data[4:], 8)
+
+	// IFD0: ExifIFD 포인터 하나
+	order.PutUint16(data[8:], 1)
+	order.PutUint16(data[10:], 0x8769)
+	order.PutUint16(data[12:], 4)
+	order.PutUint32(data[14:], 1)
+	order.PutUint32(data[18:], 26)
+
+	// Exif IFD: DateTimeOriginal 하나
+	value := append([]byte(taken), 0)
+	order.PutUint16(data[26:], 1)
+	order.PutUint16(data[28:], 0x9003)
+	order.PutUint16(data[30:], 2)
+	order.PutUint32(data[32:], uint32(len(value)))
+	order.PutUint32(data[36:], 44)
+	return append(data, value...)
+}
+
+func buildJPEG(tiff []byte) []byte {
+	segment := append([]byte("Exif\x00\x00"), tiff...)
+	data := []byte{0xFF, 0xD8, 0xFF, 0xE0, 0x00, 0x04, 0x00, 0x00, 0xFF, 0xE1}
+	data = binary.BigEndian.AppendUint16(data, uint16(len(segment)+2))
+	data = append(data, segment...)
+	return append(data, 0xFF, 0xDA, 0x00, 0x02, 0xFF, 0xD9)
+}
+
+func TestParseReadsDateTimeOriginal(t *testing.T) {
+	want := time.Date(2023, time.July, 14, 9, 30, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{name: "jpeg big endian", data: buildJPEG(buildTIFF(binary.BigEndian, "2023:07:14 09:30:00"))},
+		{name: "tiff little endian", data: buildTIFF(binary.LittleEndian, "2023:07:14 09:30:00")},
+	}
+	for _, tt := range tests {
+		got, err := exif.Parse(tt.data)
+		if err != nil {
+			t.Fatalf("%s: parse: %v", tt.name, err)
+		}
+		if !got.Equal(want) {
+			t.Fatalf("%s: got %v, want %v", tt.name, got, want)
+		}
+	}
+}
+
+func TestDateTakenReportsMissingExifWithoutError(t *testing.T) {
+	dir := t.TempDir()
+	cases := map[string][]byte{
+		"plain.txt":   []byte("hello"),
+		"noexif.jpg":  {0xFF, 0xD8, 0xFF, 0xDA, 0x00, 0x02, 0xFF, 0xD9},
+		"baddate.jpg": buildJPEG(buildTIFF(binary.BigEndian, "not a date")),
+	}
+	for name, data := range cases {
+		absPath := filepath.Join(dir, name)
+		if err := os.WriteFile(absPath, data, 0o644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+		if _, ok, err := exif.DateTaken(absPath); err != nil || ok {
+			t.Fatalf("%s: expected ok=false without error, got ok=%v err=%v", name, ok, err)
+		}
+	}
+
+	if _, _, err := exif.DateTaken(filepath.Join(dir, "missing.jpg")); !os.IsNotExist(err) {
+		t.Fatalf("expected not-exist error for missing file, got %v", err)
+	}
+}
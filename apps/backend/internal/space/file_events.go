@@ -0,0 +1,169 @@
+package space
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"taeu.kr/cohesion/internal/platform/logging"
+)
+
+const (
+	// DefaultFileEventQueueSize는 처리하지 못하고 쌓아 둘 수 있는 파일 이벤트 수입니다. 넘치면 버립니다.
+	DefaultFileEventQueueSize = 1024
+	// DefaultFileEventWorkers는 파일 이벤트를 동시에 처리하는 작업자 수입니다.
+	DefaultFileEventWorkers = 2
+)
+
+// FileEventKind는 파일 이벤트 종류입니다.
+type FileEventKind string
+
+const (
+	// FileEventCreated는 업로드나 프로토콜 쓰기로 파일 내용이 새로 놓였을 때입니다. 덮어쓰기도 포함합니다.
+	FileEventCreated FileEventKind = "created"
+)
+
+// FileEvent는 Space 안 파일 하나에 일어난 변화입니다.
+type FileEvent struct {
+	Kind      FileEventKind
+	SpaceID   int64
+	SpaceRoot string
+	AbsPath   string
+	Actor     string
+	// Source는 변화를 일으킨 통로(upload, webdav, sftp, ftp)입니다.
+	Source string
+	At     time.Time
+}
+
+// RelPath는 Space 루트 기준 경로입니다.
+func (e FileEvent) RelPath() string {
+	return quarantineRelPath(e.SpaceRoot, e.AbsPath)
+}
+
+// FileEventPublisher는 REST와 WebDAV/SFTP/FTP가 파일을 다 쓴 뒤 이벤트를 내보낼 때 쓰는 기능입니다.
+// 내보내기는 막히지 않아야 하므로 처리는 구독자가 뒤이어 합니다.
+type FileEventPublisher interface {
+	PublishFileEvent(ctx context.Context, event FileEvent)
+}
+
+// FileEventSubscriber는 파일 이벤트를 받아 처리합니다.
+type FileEventSubscriber interface {
+	HandleFileEvent(ctx context.Context, event FileEvent)
+}
+
+// FileEventSubscriberFunc는 함수를 FileEventSubscriber로 씁니다.
+type FileEventSubscriberFunc func(ctx context.Context, event FileEvent)
+
+func (f FileEventSubscriberFunc) HandleFileEvent(ctx context.Context, event FileEvent) {
+	f(ctx, event)
+}
+
+// FileEventPipeline은 파일 이벤트를 큐에 모아 작업자가 구독자에게 차례로 전달합니다.
+// 큐가 가득 차면 이벤트를 버리고 경고만 남깁니다. 업로드를 늦추지 않는 것이 우선입니다.
+type FileEventPipeline struct {
+	queue   chan FileEvent
+	workers int
+
+	mu          sync.RWMutex
+	subscribers []FileEventSubscriber
+	closed      bool
+
+	startOnce sync.Once
+	stopOnce  sync.Once
+	wg        sync.WaitGroup
+}
+
+func NewFileEventPipeline(queueSize, workers int) *FileEventPipeline {
+	if queueSize <= 0 {
+		queueSize = DefaultFileEventQueueSize
+	}
+	if workers <= 0 {
+		workers = DefaultFileEventWorkers
+	}
+	return &FileEventPipeline{
+		queue:   make(chan FileEvent, queueSize),
+		workers: workers,
+	}
+}
+
+// Subscribe는 이벤트를 받을 구독자를 더합니다.
+func (p *FileEventPipeline) Subscribe(subscriber FileEventSubscriber) {
+	if subscriber == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.subscribers = append(p.subscribers, subscriber)
+}
+
+// PublishFileEvent는 이벤트를 큐에 넣습니다. Stop 뒤이거나 큐가 가득 차면 버립니다.
+func (p *FileEventPipeline) PublishFileEvent(ctx context.Context, event FileEvent) {
+	if event.At.IsZero() {
+		event.At = time.Now().UTC()
+	}
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.closed {
+		return
+	}
+	select {
+	case p.queue <- event:
+	default:
+		logging.Event(log.Warn(), logging.ComponentStorage, "warn.space.file_event_dropped").
+			Int64("space_id", event.SpaceID).
+			Str("kind", string(event.Kind)).
+			Str("source", event.Source).
+			Msg("file event queue is full; dropping event")
+	}
+}
+
+// Start는 작업자를 띄웁니다. Start 전에 들어온 이벤트는 큐에 남아 있다가 처리됩니다.
+func (p *FileEventPipeline) Start() {
+	p.startOnce.Do(func() {
+		for i := 0; i < p.workers; i++ {
+			p.wg.Add(1)
+			go p.run()
+		}
+	})
+}
+
+// Stop은 새 이벤트를 받지 않고, 큐에 남은 이벤트를 모두 처리한 뒤 반환합니다.
+// Start 전에 호출하면 남은 이벤트는 버립니다.
+func (p *FileEventPipeline) Stop() {
+	p.stopOnce.Do(func() {
+		p.mu.Lock()
+		p.closed = true
+		close(p.queue)
+		p.mu.Unlock()
+	})
+	p.startOnce.Do(func() {})
+	p.wg.Wait()
+}
+
+func (p *FileEventPipeline) run() {
+	defer p.wg.Done()
+	for event := range p.queue {
+		p.mu.RLock()
+		subscribers := append([]FileEventSubscriber(nil), p.subscribers...)
+		p.mu.RUnlock()
+		for _, subscriber := range subscribers {
+			p.dispatch(subscriber, event)
+		}
+	}
+}
+
+// dispatch는 구독자 하나의 panic이 작업자를 멈추지 않도록 막습니다.
+func (p *FileEventPipeline) dispatch(subscriber FileEventSubscriber, event FileEvent) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			logging.Event(log.Error(), logging.ComponentStorage, "error.space.file_event_panic").
+				Int64("space_id", event.SpaceID).
+				Interface("panic", recovered).
+				Msg("file event subscriber panicked")
+		}
+	}()
+	subscriber.HandleFileEvent(context.Background(), event)
+}
+
+var _ FileEventPublisher = (*FileEventPipeline)(nil)
@@ -0,0 +1,135 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"syscall"
+
+	"taeu.kr/cohesion/internal/audit"
+	"taeu.kr/cohesion/internal/browse"
+	"taeu.kr/cohesion/internal/platform/web"
+	"taeu.kr/cohesion/internal/space/archive"
+)
+
+// archiveEntryTarget은 Space 안 압축 파일 내부를 가리키는 경로입니다.
+type archiveEntryTarget struct {
+	// ArchiveRelPath는 Space 기준 압축 파일 경로(슬래시 구분)입니다.
+	ArchiveRelPath string
+	ArchiveAbsPath string
+	Format         archiveFormat
+	Index          *archive.Index
+	Member         archiveMember
+}
+
+// lookupArchiveEntry는 상대 경로가 압축 파일 안을 가리키면 해당 항목을 찾습니다.
+// 실제 파일/폴더가 있으면 기존 처리에 맡기도록 nil을 반환합니다.
+// archiveAsFolder가 true이면 압축 파일 자신도 폴더(루트 항목)로 취급합니다.
+func (h *Handler) lookupArchiveEntry(ctx context.Context, spaceRoot string, relativePath string, archiveAsFolder bool) (*archiveEntryTarget, *web.Error) {
+	info, err := os.Stat(filepath.Join(spaceRoot, relativePath))
+	switch {
+	case err == nil && info.IsDir():
+		return nil, nil
+	case err == nil:
+		if !archiveAsFolder || !info.Mode().IsRegular() {
+			return nil, nil
+		}
+		if _, ok := detectArchiveFormat(info.Name()); !ok {
+			return nil, nil
+		}
+	case !os.IsNotExist(err) && !errors.Is(err, syscall.ENOTDIR):
+		return nil, nil
+	}
+
+	archivePath, inner, format, ok := archive.SplitPath(spaceRoot, relativePath)
+	if !ok {
+		return nil, nil
+	}
+	archiveRel, err := filepath.Rel(spaceRoot, archivePath)
+	if err != nil {
+		return nil, &web.Error{Code: http.StatusForbidden, Message: "Access denied: invalid path", Err: err}
+	}
+	index, err := h.archiveIndexes.Index(ctx, archivePath, format)
+	if err != nil {
+		return nil, archiveBrowseWebError(err)
+	}
+	member, found := index.Lookup(inner)
+	if !found {
+		return nil, &web.Error{Code: http.StatusNotFound, Message: "File not found"}
+	}
+	return &archiveEntryTarget{
+		ArchiveRelPath: filepath.ToSlash(archiveRel),
+		ArchiveAbsPath: archivePath,
+		Format:         format,
+		Index:          index,
+		Member:         member,
+	}, nil
+}
+
+func archiveBrowseWebError(err error) *web.Error {
+	switch {
+	case errors.Is(err, archive.ErrTooManyEntries):
+		return &web.Error{Code: http.StatusUnprocessableEntity, Message: "Archive has too many entries to browse", Err: err}
+	case os.IsNotExist(err):
+		return &web.Error{Code: http.StatusNotFound, Message: "File not found", Err: err}
+	case browse.IsPermissionError(err):
+		return &web.Error{Code: http.StatusForbidden, Message: "Permission denied", Err: err}
+	default:
+		return &web.Error{Code: http.StatusUnprocessableEntity, Message: "Failed to read archive", Err: err}
+	}
+}
+
+// listArchiveEntries는 압축 파일 안 폴더의 항목을 browse 응답 형식으로 반환합니다.
+// Path는 "압축 파일 경로/내부 경로"이므로 그대로 다시 browse/download에 쓸 수 있습니다.
+func listArchiveEntries(target *archiveEntryTarget) ([]browse.FileInfo, *web.Error) {
+	members, err := target.Index.List(target.Member.Name)
+	if errors.Is(err, archive.ErrNotDirectory) {
+		return nil, &web.Error{Code: http.StatusBadRequest, Message: "Path is not a directory", Err: err}
+	}
+	if err != nil {
+		return nil, archiveBrowseWebError(err)
+	}
+
+	files := make([]browse.FileInfo, 0, len(members))
+	for _, member := range members {
+		files = append(files, browse.FileInfo{
+			Name:     path.Base(member.Name),
+			Path:     path.Join(target.ArchiveRelPath, member.Name),
+			IsDir:    member.IsDir,
+			Size:     member.Size,
+			ModTime:  member.ModTime,
+			ReadOnly: true,
+		})
+	}
+	return files, nil
+}
+
+// downloadArchiveEntry는 압축을 풀지 않고 항목 하나를 내려줍니다.
+func (h *Handler) downloadArchiveEntry(w http.ResponseWriter, r *http.Request, spaceID int64, relativePath string, target *archiveEntryTarget) *web.Error {
+	if target.Member.IsDir {
+		return &web.Error{Code: http.StatusBadRequest, Message: "Folders inside an archive cannot be downloaded"}
+	}
+
+	content := archive.NewMemberFile(target.ArchiveAbsPath, target.Format, target.Member)
+	defer content.Close()
+
+	info := target.Member.Info()
+	serveAttachmentContent(w, r, content, info, info.Name(), "")
+	h.recordSpaceAudit(r, audit.Event{
+		Action: "file.download",
+		Result: audit.ResultSuccess,
+		Target: relativePath,
+		Metadata: map[string]any{
+			"path":        relativePath,
+			"filename":    info.Name(),
+			"size":        info.Size(),
+			"format":      "archive-entry",
+			"sourceCount": 1,
+			"status":      "downloaded",
+		},
+	}, spaceID)
+	return nil
+}
@@ -0,0 +1,141 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"taeu.kr/cohesion/internal/browse"
+	"taeu.kr/cohesion/internal/space"
+	"taeu.kr/cohesion/internal/space/archive"
+)
+
+func newArchiveBrowseTestHandler(t *testing.T) *Handler {
+	t.Helper()
+
+	spaceRoot := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(spaceRoot, "deliverables"), 0o755); err != nil {
+		t.Fatalf("failed to create folder: %v", err)
+	}
+	writeTestZip(t, filepath.Join(spaceRoot, "deliverables", "v1.zip"), []testArchiveEntry{
+		{name: "docs", dir: true},
+		{name: "docs/readme.txt", content: "readme"},
+		{name: "docs/nested/report.txt", content: "report"},
+		{name: "\xb9\xae\xbc\xad.txt", content: "korean", nonUTF8: true},
+		{name: "../escape.txt", content: "escape"},
+	})
+	writeTestTar(t, filepath.Join(spaceRoot, "deliverables", "v2.tar.gz"), archiveFormatTarGzip, []testArchiveEntry{
+		{name: "a/b.txt", content: "from tar"},
+	})
+
+	store := &fakeTransferSpaceStore{
+		spacesByID: map[int64]*space.Space{
+			1: {ID: 1, SpaceName: "Archive", SpacePath: spaceRoot},
+		},
+	}
+	return NewHandler(space.NewService(store), nil, nil)
+}
+
+func browseSpacePath(t *testing.T, handler *Handler, relativePath string) []browse.FileInfo {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/spaces/1/browse", nil)
+	query := req.URL.Query()
+	query.Set("path", relativePath)
+	req.URL.RawQuery = query.Encode()
+	rec := httptest.NewRecorder()
+	if webErr := handler.handleSpaceBrowse(rec, req, 1); webErr != nil {
+		t.Fatalf("unexpected browse error for %q: %+v", relativePath, webErr)
+	}
+	var files []browse.FileInfo
+	if err := json.NewDecoder(rec.Body).Decode(&files); err != nil {
+		t.Fatalf("failed to decode browse response: %v", err)
+	}
+	return files
+}
+
+func TestHandleSpaceBrowse_ListsArchiveAsReadOnlyFolder(t *testing.T) {
+	handler := newArchiveBrowseTestHandler(t)
+
+	root := browseSpacePath(t, handler, "deliverables/v1.zip")
+	if len(root) != 2 {
+		t.Fatalf("expected folder and korean file at archive root, got %+v", root)
+	}
+	if root[0].Name != "docs" || !root[0].IsDir || root[0].Path != "deliverables/v1.zip/docs" || !root[0].ReadOnly {
+		t.Fatalf("unexpected folder entry: %+v", root[0])
+	}
+	if root[1].Name != "문서.txt" || root[1].Size != int64(len("korean")) {
+		t.Fatalf("expected CP949 name to be decoded, got %+v", root[1])
+	}
+
+	nested := browseSpacePath(t, handler, "deliverables/v1.zip/docs")
+	if len(nested) != 2 || nested[0].Name != "nested" || !nested[0].IsDir || nested[1].Name != "readme.txt" {
+		t.Fatalf("unexpected nested entries (implicit folder first): %+v", nested)
+	}
+
+	tarEntries := browseSpacePath(t, handler, "deliverables/v2.tar.gz/a")
+	if len(tarEntries) != 1 || tarEntries[0].Path != "deliverables/v2.tar.gz/a/b.txt" {
+		t.Fatalf("unexpected tar entries: %+v", tarEntries)
+	}
+}
+
+func TestHandleSpaceBrowse_RejectsArchiveOverEntryLimit(t *testing.T) {
+	handler := newArchiveBrowseTestHandler(t)
+	handler.archiveIndexes = archive.NewCache(16, 2)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/spaces/1/browse?path=deliverables/v1.zip", nil)
+	webErr := handler.handleSpaceBrowse(httptest.NewRecorder(), req, 1)
+	if webErr == nil || webErr.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected 422 for archive over entry limit, got %+v", webErr)
+	}
+}
+
+func TestHandleFileDownload_StreamsSingleArchiveEntry(t *testing.T) {
+	handler := newArchiveBrowseTestHandler(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/spaces/1/files/download?path=deliverables/v1.zip/docs/nested/report.txt", nil)
+	rec := httptest.NewRecorder()
+	if webErr := handler.handleFileDownload(rec, req, 1); webErr != nil {
+		t.Fatalf("unexpected download error: %+v", webErr)
+	}
+	if body := rec.Body.String(); body != "report" {
+		t.Fatalf("unexpected entry content: %q", body)
+	}
+	if disposition := rec.Header().Get("Content-Disposition"); disposition != `attachment; filename="report.txt"` {
+		t.Fatalf("unexpected content disposition: %q", disposition)
+	}
+
+	rangeReq := httptest.NewRequest(http.MethodGet, "/api/spaces/1/files/download?path=deliverables/v2.tar.gz/a/b.txt", nil)
+	rangeReq.Header.Set("Range", "bytes=5-")
+	rangeRec := httptest.NewRecorder()
+	if webErr := handler.handleFileDownload(rangeRec, rangeReq, 1); webErr != nil {
+		t.Fatalf("unexpected range download error: %+v", webErr)
+	}
+	if rangeRec.Code != http.StatusPartialContent || rangeRec.Body.String() != "tar" {
+		t.Fatalf("unexpected range response %d %q", rangeRec.Code, rangeRec.Body.String())
+	}
+}
+
+func TestHandleFileDownload_ArchiveEntryErrors(t *testing.T) {
+	handler := newArchiveBrowseTestHandler(t)
+
+	tests := []struct {
+		path string
+		code int
+	}{
+		{path: "deliverables/v1.zip/docs", code: http.StatusBadRequest},
+		{path: "deliverables/v1.zip/missing.txt", code: http.StatusNotFound},
+		{path: "deliverables/v1.zip/escape.txt", code: http.StatusNotFound},
+		{path: "deliverables/missing.zip/a.txt", code: http.StatusNotFound},
+	}
+	for _, tc := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/api/spaces/1/files/download?path="+tc.path, nil)
+		webErr := handler.handleFileDownload(httptest.NewRecorder(), req, 1)
+		if webErr == nil || webErr.Code != tc.code {
+			t.Fatalf("expected %d for %s, got %+v", tc.code, tc.path, webErr)
+		}
+	}
+}
@@ -9,21 +9,25 @@ import (
 	"io"
 	"net/http"
 	"os"
-	"path/filepath"
 	"strings"
-	"sync"
 	"time"
 
 	"github.com/rs/zerolog/log"
 	"taeu.kr/cohesion/internal/audit"
 	"taeu.kr/cohesion/internal/auth"
+	"taeu.kr/cohesion/internal/download"
+	"taeu.kr/cohesion/internal/job"
 	"taeu.kr/cohesion/internal/platform/logging"
 	"taeu.kr/cohesion/internal/platform/web"
 )
 
 const (
+	// ArchiveDownloadJobType은 여러 파일/폴더를 ZIP 또는 tar/tar.gz로 묶는 작업 유형입니다.
+	ArchiveDownloadJobType = "archive.download"
+
 	defaultArchiveDownloadWorkerLimit = 2
 	defaultArchiveDownloadTTL         = 10 * time.Minute
+	defaultArchiveDownloadMaxAttempts = 2
 )
 
 type archiveDownloadState string
@@ -37,34 +41,15 @@ const (
 	archiveDownloadStateExpired  archiveDownloadState = "expired"
 )
 
-type archiveDownloadJob struct {
-	ID                   string
-	Owner                string
-	SpaceID              int64
-	RequestID            string
-	RequestedPaths       []string
-	FileName             string
-	State                archiveDownloadState
-	FailureReason        string
-	ArtifactPath         string
-	ArtifactSize         int64
-	SourceCount          int
-	TotalItems           int
-	ProcessedItems       int
-	TotalSourceBytes     int64
-	ProcessedSourceBytes int64
-	CreatedAt            time.Time
-	UpdatedAt            time.Time
-	ExpiresAt            time.Time
-	ctx                  context.Context
-	cancel               context.CancelFunc
-}
-
-type archiveDownloadManager struct {
-	mu      sync.Mutex
-	ttl     time.Duration
-	workers chan struct{}
-	jobs    map[string]*archiveDownloadJob
+// archiveDownloadPayload는 작업 큐에 저장되는 아카이브 작업 입력입니다.
+// 서버 재시작 후에도 같은 입력으로 다시 실행할 수 있도록 상대 경로만 저장합니다.
+// Format이 비어 있으면(이전 버전에서 만든 작업) ZIP입니다.
+// 암호는 저장하지 않고 PasswordRef로 메모리의 암호를 가리킵니다.
+type archiveDownloadPayload struct {
+	Paths       []string      `json:"paths"`
+	FileName    string        `json:"fileName"`
+	Format      archiveFormat `json:"format,omitempty"`
+	PasswordRef string        `json:"passwordRef,omitempty"`
 }
 
 type archiveDownloadSource struct {
@@ -84,6 +69,8 @@ type archiveDownloadStatusResponse struct {
 	JobID                string  `json:"jobId"`
 	Status               string  `json:"status"`
 	FileName             string  `json:"fileName"`
+	Format               string  `json:"format"`
+	Encrypted            bool    `json:"encrypted"`
 	SourceCount          int     `json:"sourceCount"`
 	TotalItems           int     `json:"totalItems"`
 	ProcessedItems       int     `json:"processedItems"`
@@ -94,144 +81,27 @@ type archiveDownloadStatusResponse struct {
 	ExpiresAt            *string `json:"expiresAt,omitempty"`
 }
 
-func newArchiveDownloadManager(workerLimit int, ttl time.Duration) *archiveDownloadManager {
-	if workerLimit < 1 {
-		workerLimit = 1
-	}
-	if ttl <= 0 {
-		ttl = defaultArchiveDownloadTTL
-	}
-	return &archiveDownloadManager{
-		ttl:     ttl,
-		workers: make(chan struct{}, workerLimit),
-		jobs:    make(map[string]*archiveDownloadJob),
-	}
-}
-
-func (m *archiveDownloadManager) createJob(owner string, spaceID int64, requestID string, requestedPaths []string, fileName string) (*archiveDownloadJob, error) {
-	jobID, err := generateDownloadTicketToken()
-	if err != nil {
-		return nil, err
-	}
-
-	now := time.Now()
-	jobCtx, cancel := context.WithCancel(context.Background())
-	job := &archiveDownloadJob{
-		ID:             jobID,
-		Owner:          owner,
-		SpaceID:        spaceID,
-		RequestID:      requestID,
-		RequestedPaths: append([]string(nil), requestedPaths...),
-		FileName:       fileName,
-		State:          archiveDownloadStateQueued,
-		SourceCount:    len(requestedPaths),
-		CreatedAt:      now,
-		UpdatedAt:      now,
-		ctx:            jobCtx,
-		cancel:         cancel,
-	}
-
-	m.mu.Lock()
-	defer m.mu.Unlock()
-	m.jobs[jobID] = job
-	return job.copy(), nil
-}
-
-func (m *archiveDownloadManager) cancelJob(jobID string, owner string) (*archiveDownloadJob, context.CancelFunc, string, *web.Error) {
-	m.mu.Lock()
-	defer m.mu.Unlock()
-
-	job, ok := m.jobs[jobID]
-	if !ok {
-		return nil, nil, "", &web.Error{Code: http.StatusNotFound, Message: "Archive job not found"}
-	}
-	if job.Owner != owner {
-		return nil, nil, "", &web.Error{Code: http.StatusForbidden, Message: "Archive job access denied"}
-	}
-	if job.State == archiveDownloadStateCanceled {
-		return job.copy(), nil, "", nil
-	}
-	if job.State == archiveDownloadStateFailed || job.State == archiveDownloadStateExpired {
-		return nil, nil, "", &web.Error{Code: http.StatusConflict, Message: "Archive job can no longer be canceled"}
-	}
-
-	artifactPath := job.ArtifactPath
-	job.ArtifactPath = ""
-	job.ArtifactSize = 0
-	job.State = archiveDownloadStateCanceled
-	job.FailureReason = "archive canceled"
-	job.ExpiresAt = time.Now().Add(m.ttl)
-	job.UpdatedAt = time.Now()
-	return job.copy(), job.cancel, artifactPath, nil
-}
-
-func (m *archiveDownloadManager) getJob(jobID string, owner string) (*archiveDownloadJob, *web.Error) {
-	m.mu.Lock()
-	defer m.mu.Unlock()
-
-	job, ok := m.jobs[jobID]
-	if !ok {
-		return nil, &web.Error{Code: http.StatusNotFound, Message: "Archive job not found"}
-	}
-	if job.Owner != owner {
-		jobCopy := job.copy()
-		return jobCopy, &web.Error{Code: http.StatusForbidden, Message: "Archive job access denied"}
-	}
-	return job.copy(), nil
-}
-
-func (m *archiveDownloadManager) updateJob(jobID string, update func(job *archiveDownloadJob)) *archiveDownloadJob {
-	m.mu.Lock()
-	defer m.mu.Unlock()
-
-	job, ok := m.jobs[jobID]
-	if !ok {
-		return nil
-	}
-	update(job)
-	job.UpdatedAt = time.Now()
-	return job.copy()
-}
-
-func (m *archiveDownloadManager) cleanupExpiredJobs() []*archiveDownloadJob {
-	m.mu.Lock()
-	defer m.mu.Unlock()
-	return m.cleanupExpiredJobsLocked(time.Now())
-}
-
-func (m *archiveDownloadManager) cleanupExpiredJobsLocked(now time.Time) []*archiveDownloadJob {
-	expiredJobs := make([]*archiveDownloadJob, 0)
-	for jobID, job := range m.jobs {
-		if !job.ExpiresAt.IsZero() && now.After(job.ExpiresAt) && job.State != archiveDownloadStateExpired {
-			if job.ArtifactPath != "" {
-				_ = os.Remove(job.ArtifactPath)
-				job.ArtifactPath = ""
-			}
-			job.ArtifactSize = 0
-			job.FailureReason = "archive expired"
-			job.State = archiveDownloadStateExpired
-			job.UpdatedAt = now
-			expiredJobs = append(expiredJobs, job.copy())
-			continue
-		}
-		if job.State == archiveDownloadStateExpired && !job.ExpiresAt.IsZero() && now.After(job.ExpiresAt.Add(m.ttl)) {
-			delete(m.jobs, jobID)
-		}
-	}
-	return expiredJobs
+// SetJobManager는 영속 작업 큐를 사용하도록 교체하고 Space 핸들러의 작업 유형을 등록합니다.
+func (h *Handler) SetJobManager(manager *job.Manager) {
+	h.jobs = manager
+	h.registerJobTypes()
 }
 
-func (m *archiveDownloadJob) copy() *archiveDownloadJob {
-	if m == nil {
-		return nil
-	}
-	jobCopy := *m
-	jobCopy.RequestedPaths = append([]string(nil), m.RequestedPaths...)
-	return &jobCopy
-}
-
-func (m *archiveDownloadManager) ttlDuration() time.Duration {
-	return m.ttl
+func (h *Handler) registerJobTypes() {
+	h.jobs.Register(ArchiveDownloadJobType, job.TypeConfig{
+		Concurrency: defaultArchiveDownloadWorkerLimit,
+		MaxAttempts: defaultArchiveDownloadMaxAttempts,
+		ArtifactTTL: defaultArchiveDownloadTTL,
+		Run:         h.runArchiveDownloadJob,
+		OnFinish:    h.finishArchiveDownloadJob,
+		OnExpire:    h.expireArchiveDownloadJob,
+	})
+	h.registerFileTransferJobType()
+	h.registerFileExtractJobType()
+	h.registerFileCompressJobType()
+	h.registerFileDuplicatesJobType()
+	h.registerSpaceRelocationJobType()
+	h.registerSpaceDeletionJobType()
 }
 
 func (h *Handler) handleArchiveDownloads(w http.ResponseWriter, r *http.Request, spaceID int64) *web.Error {
@@ -261,13 +131,22 @@ func (h *Handler) handleArchiveDownloadCreate(w http.ResponseWriter, r *http.Req
 	}
 
 	var req struct {
-		Paths []string `json:"paths"`
+		Paths    []string `json:"paths"`
+		Format   string   `json:"format"`
+		Password string   `json:"password"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		return &web.Error{Code: http.StatusBadRequest, Message: "Invalid request body", Err: err}
 	}
+	format, webErr := parseDownloadArchiveFormat(req.Format)
+	if webErr != nil {
+		return webErr
+	}
+	if webErr := validateArchivePassword(req.Password, format); webErr != nil {
+		return webErr
+	}
 
-	sources, archiveFileName, webErr := h.resolveArchiveDownloadSources(spaceData.SpacePath, req.Paths)
+	_, archiveFileName, webErr := h.resolveArchiveDownloadSources(spaceData.SpacePath, req.Paths, format)
 	if webErr != nil {
 		h.recordSpaceAudit(r, audit.Event{
 			Action: "file.archive-download",
@@ -282,35 +161,47 @@ func (h *Handler) handleArchiveDownloadCreate(w http.ResponseWriter, r *http.Req
 		return webErr
 	}
 
-	job, err := h.archiveDownloads.createJob(
-		claims.Username,
-		spaceID,
-		strings.TrimSpace(r.Header.Get("X-Request-Id")),
-		req.Paths,
-		archiveFileName,
-	)
+	// 작업이 곧바로 실행될 수 있으므로 암호는 큐에 넣기 전에 보관합니다.
+	passwordRef, err := h.storeArchivePassword(req.Password)
 	if err != nil {
 		return &web.Error{Code: http.StatusInternalServerError, Message: "Failed to create archive job", Err: err}
 	}
+	archiveJob, err := h.jobs.Enqueue(r.Context(), job.EnqueueRequest{
+		Type:      ArchiveDownloadJobType,
+		Owner:     claims.Username,
+		SpaceID:   &spaceID,
+		RequestID: strings.TrimSpace(r.Header.Get("X-Request-Id")),
+		Payload: archiveDownloadPayload{
+			Paths:       req.Paths,
+			FileName:    archiveFileName,
+			Format:      format,
+			PasswordRef: passwordRef,
+		},
+	})
+	if err != nil {
+		h.releaseArchivePassword(passwordRef)
+		return &web.Error{Code: http.StatusInternalServerError, Message: "Failed to create archive job", Err: err}
+	}
 
-	h.logArchiveJobEvent("info.archive.job_created", job, nil)
+	response := newArchiveDownloadStatusResponse(archiveJob)
+	h.logArchiveJobEvent("info.archive.job_created", archiveJob, nil)
 	h.recordSpaceAudit(r, audit.Event{
 		Action: "file.archive-download",
 		Result: audit.ResultSuccess,
 		Target: fmt.Sprintf("%d items", len(req.Paths)),
 		Metadata: map[string]any{
-			"jobId":       job.ID,
+			"jobId":       archiveJob.ID,
 			"sourceCount": len(req.Paths),
 			"filename":    archiveFileName,
-			"status":      string(job.State),
+			"format":      response.Format,
+			"encrypted":   response.Encrypted,
+			"status":      response.Status,
 		},
 	}, spaceID)
 
-	go h.runArchiveDownloadJob(job.ID, job.ctx, claims.Username, spaceID, strings.TrimSpace(r.Header.Get("X-Request-Id")), archiveFileName, sources)
-
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusAccepted)
-	if err := json.NewEncoder(w).Encode(job.toStatusResponse()); err != nil {
+	if err := json.NewEncoder(w).Encode(response); err != nil {
 		return &web.Error{Code: http.StatusInternalServerError, Message: "Failed to encode archive job response", Err: err}
 	}
 	return nil
@@ -329,45 +220,43 @@ func (h *Handler) handleArchiveDownloadCancel(w http.ResponseWriter, r *http.Req
 		return &web.Error{Code: http.StatusBadRequest, Message: "jobId is required"}
 	}
 
-	jobSnapshot, webErr := h.archiveDownloads.getJob(jobID, claims.Username)
-	if webErr != nil {
+	if _, webErr := h.getArchiveDownloadJob(r.Context(), jobID, claims.Username, spaceID); webErr != nil {
 		return webErr
 	}
-	if jobSnapshot.SpaceID != spaceID {
-		return &web.Error{Code: http.StatusForbidden, Message: "Archive job access denied"}
-	}
 
-	job, cancelFn, artifactPath, webErr := h.archiveDownloads.cancelJob(jobID, claims.Username)
-	if webErr != nil {
-		return webErr
-	}
-	if cancelFn != nil {
-		cancelFn()
-	}
-	if strings.TrimSpace(artifactPath) != "" {
-		_ = os.Remove(artifactPath)
+	canceledJob, err := h.jobs.Cancel(r.Context(), jobID)
+	if err != nil {
+		if errors.Is(err, job.ErrNotCancelable) {
+			return &web.Error{Code: http.StatusConflict, Message: "Archive job can no longer be canceled", Err: err}
+		}
+		if errors.Is(err, job.ErrNotFound) {
+			return &web.Error{Code: http.StatusNotFound, Message: "Archive job not found", Err: err}
+		}
+		return &web.Error{Code: http.StatusInternalServerError, Message: "Failed to cancel archive job", Err: err}
 	}
 
-	h.logArchiveJobEvent("info.archive.job_canceled", job, nil)
+	h.releaseArchiveJobPassword(canceledJob)
+	response := newArchiveDownloadStatusResponse(canceledJob)
+	h.logArchiveJobEvent("info.archive.job_canceled", canceledJob, nil)
 	h.recordSpaceAudit(r, audit.Event{
 		Action: "file.archive-download",
 		Result: audit.ResultSuccess,
-		Target: job.ID,
+		Target: canceledJob.ID,
 		Metadata: map[string]any{
-			"jobId":                job.ID,
-			"filename":             job.FileName,
-			"status":               string(job.State),
-			"sourceCount":          job.SourceCount,
-			"processedItems":       job.ProcessedItems,
-			"totalItems":           job.TotalItems,
-			"processedSourceBytes": job.ProcessedSourceBytes,
-			"totalSourceBytes":     job.TotalSourceBytes,
+			"jobId":                canceledJob.ID,
+			"filename":             response.FileName,
+			"status":               response.Status,
+			"sourceCount":          response.SourceCount,
+			"processedItems":       response.ProcessedItems,
+			"totalItems":           response.TotalItems,
+			"processedSourceBytes": response.ProcessedSourceBytes,
+			"totalSourceBytes":     response.TotalSourceBytes,
 		},
 	}, spaceID)
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
-	if err := json.NewEncoder(w).Encode(job.toStatusResponse()); err != nil {
+	if err := json.NewEncoder(w).Encode(response); err != nil {
 		return &web.Error{Code: http.StatusInternalServerError, Message: "Failed to encode archive job cancel response", Err: err}
 	}
 	return nil
@@ -384,17 +273,14 @@ func (h *Handler) handleArchiveDownloadStatus(w http.ResponseWriter, r *http.Req
 		return &web.Error{Code: http.StatusBadRequest, Message: "jobId is required"}
 	}
 
-	job, webErr := h.archiveDownloads.getJob(jobID, claims.Username)
+	archiveJob, webErr := h.getArchiveDownloadJob(r.Context(), jobID, claims.Username, spaceID)
 	if webErr != nil {
 		return webErr
 	}
-	if job.SpaceID != spaceID {
-		return &web.Error{Code: http.StatusForbidden, Message: "Archive job access denied"}
-	}
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
-	if err := json.NewEncoder(w).Encode(job.toStatusResponse()); err != nil {
+	if err := json.NewEncoder(w).Encode(newArchiveDownloadStatusResponse(archiveJob)); err != nil {
 		return &web.Error{Code: http.StatusInternalServerError, Message: "Failed to encode archive job status", Err: err}
 	}
 	return nil
@@ -413,6 +299,7 @@ func (h *Handler) handleArchiveDownloadTicket(w http.ResponseWriter, r *http.Req
 
 	var req struct {
 		JobID string `json:"jobId"`
+		downloadLinkOptions
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		return &web.Error{Code: http.StatusBadRequest, Message: "Invalid request body", Err: err}
@@ -421,33 +308,28 @@ func (h *Handler) handleArchiveDownloadTicket(w http.ResponseWriter, r *http.Req
 		return &web.Error{Code: http.StatusBadRequest, Message: "jobId is required"}
 	}
 
-	job, webErr := h.archiveDownloads.getJob(req.JobID, claims.Username)
+	archiveJob, webErr := h.getArchiveDownloadJob(r.Context(), req.JobID, claims.Username, spaceID)
 	if webErr != nil {
 		return webErr
 	}
-	if job.SpaceID != spaceID {
-		return &web.Error{Code: http.StatusForbidden, Message: "Archive job access denied"}
-	}
-	if job.State == archiveDownloadStateExpired {
+	if archiveJob.Status == job.StatusExpired {
 		return &web.Error{Code: http.StatusGone, Message: "Archive job expired"}
 	}
-	if job.State == archiveDownloadStateCanceled {
+	if archiveJob.Status == job.StatusCanceled {
 		return &web.Error{Code: http.StatusConflict, Message: "Archive job was canceled"}
 	}
-	if job.State != archiveDownloadStateReady || strings.TrimSpace(job.ArtifactPath) == "" {
+	if archiveJob.Status != job.StatusCompleted || strings.TrimSpace(archiveJob.ArtifactPath) == "" {
 		return &web.Error{Code: http.StatusConflict, Message: "Archive job is not ready"}
 	}
 
-	ticket, err := h.issueDownloadTicket(
-		claims.Username,
-		job.ArtifactPath,
-		job.FileName,
-		"file.archive-download-ticket",
-		&spaceID,
-		"application/zip",
-		job.ArtifactSize,
-		false,
-	)
+	var payload archiveDownloadPayload
+	_ = archiveJob.DecodePayload(&payload)
+	fileName := payload.FileName
+	downloadURL, expiresAt, err := h.signDownloadURL(r, download.Claims{
+		SpaceID: spaceID,
+		JobID:   archiveJob.ID,
+		Owner:   claims.Username,
+	}, req.downloadLinkOptions, archiveSignedLinkTTL(archiveJob, req.ttl()))
 	if err != nil {
 		h.recordSpaceAudit(r, audit.Event{
 			Action: "file.archive-download-ticket",
@@ -455,24 +337,26 @@ func (h *Handler) handleArchiveDownloadTicket(w http.ResponseWriter, r *http.Req
 			Target: req.JobID,
 			Metadata: map[string]any{
 				"jobId":    req.JobID,
-				"filename": job.FileName,
-				"size":     job.ArtifactSize,
+				"filename": fileName,
+				"size":     archiveJob.ArtifactSize,
 				"status":   "failed",
 			},
 		}, spaceID)
 		return &web.Error{Code: http.StatusInternalServerError, Message: "Failed to issue archive download ticket", Err: err}
 	}
 
-	h.logArchiveJobEvent("info.archive.job_handoff", job, nil)
+	h.logArchiveJobEvent("info.archive.job_handoff", archiveJob, nil)
 	h.recordSpaceAudit(r, audit.Event{
 		Action: "file.archive-download-ticket",
 		Result: audit.ResultSuccess,
 		Target: req.JobID,
 		Metadata: map[string]any{
-			"jobId":    req.JobID,
-			"filename": ticket.FileName,
-			"size":     job.ArtifactSize,
-			"status":   "ticket_issued",
+			"jobId":     req.JobID,
+			"filename":  fileName,
+			"size":      archiveJob.ArtifactSize,
+			"status":    "ticket_issued",
+			"expiresAt": expiresAt.Format(time.RFC3339),
+			"ipBound":   req.BindIP,
 		},
 	}, spaceID)
 
@@ -485,43 +369,40 @@ func (h *Handler) handleArchiveDownloadTicket(w http.ResponseWriter, r *http.Req
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	if err := json.NewEncoder(w).Encode(downloadTicketResponse{
-		DownloadURL: fmt.Sprintf("/api/downloads/%s", ticket.Token),
-		FileName:    ticket.FileName,
-		ExpiresAt:   ticket.ExpiresAt.Format(time.RFC3339),
+		DownloadURL: downloadURL,
+		FileName:    fileName,
+		ExpiresAt:   expiresAt.Format(time.RFC3339),
 	}); err != nil {
 		return &web.Error{Code: http.StatusInternalServerError, Message: "Failed to encode archive handoff response", Err: err}
 	}
 	return nil
 }
 
-func (h *Handler) resolveArchiveDownloadSources(spaceRoot string, paths []string) ([]archiveDownloadSource, string, *web.Error) {
-	if len(paths) == 0 {
-		return nil, "", &web.Error{Code: http.StatusBadRequest, Message: "at least 1 path is required"}
-	}
-
-	sources := make([]archiveDownloadSource, 0, len(paths))
-	for _, relPath := range paths {
-		if err := ensurePathOutsideTrash(relPath); err != nil {
-			return nil, "", &web.Error{Code: http.StatusForbidden, Message: fmt.Sprintf("Access denied: invalid path %s", relPath), Err: err}
+// getArchiveDownloadJob은 요청자 소유이면서 해당 Space에 속한 아카이브 작업만 반환합니다.
+func (h *Handler) getArchiveDownloadJob(ctx context.Context, jobID string, owner string, spaceID int64) (*job.Job, *web.Error) {
+	archiveJob, err := h.jobs.GetForOwner(ctx, jobID, owner)
+	if err != nil {
+		if errors.Is(err, job.ErrForbidden) {
+			return nil, &web.Error{Code: http.StatusForbidden, Message: "Archive job access denied"}
 		}
-		absPath, err := resolveAbsPath(spaceRoot, relPath)
-		if err != nil {
-			return nil, "", &web.Error{Code: http.StatusForbidden, Message: fmt.Sprintf("Access denied: invalid path %s", relPath)}
+		if errors.Is(err, job.ErrNotFound) {
+			return nil, &web.Error{Code: http.StatusNotFound, Message: "Archive job not found"}
 		}
-		info, err := os.Stat(absPath)
-		if err != nil {
-			if os.IsNotExist(err) {
-				return nil, "", &web.Error{Code: http.StatusNotFound, Message: fmt.Sprintf("Path not found: %s", relPath), Err: err}
-			}
-			if storageErr := storageAccessWebError(err, "", fmt.Sprintf("Failed to access path: %s", relPath)); storageErr != nil {
-				return nil, "", storageErr
-			}
-		}
-		sources = append(sources, archiveDownloadSource{
-			RelativePath: relPath,
-			AbsPath:      absPath,
-			BaseName:     info.Name(),
-		})
+		return nil, &web.Error{Code: http.StatusInternalServerError, Message: "Failed to get archive job", Err: err}
+	}
+	if archiveJob.Type != ArchiveDownloadJobType {
+		return nil, &web.Error{Code: http.StatusNotFound, Message: "Archive job not found"}
+	}
+	if archiveJob.SpaceID == nil || *archiveJob.SpaceID != spaceID {
+		return nil, &web.Error{Code: http.StatusForbidden, Message: "Archive job access denied"}
+	}
+	return archiveJob, nil
+}
+
+func (h *Handler) resolveArchiveDownloadSources(spaceRoot string, paths []string, format archiveFormat) ([]archiveDownloadSource, string, *web.Error) {
+	sources, webErr := resolveArchiveSources(spaceRoot, paths)
+	if webErr != nil {
+		return nil, "", webErr
 	}
 
 	if len(paths) == 1 {
@@ -532,198 +413,317 @@ func (h *Handler) resolveArchiveDownloadSources(spaceRoot string, paths []string
 		if !info.IsDir() {
 			return nil, "", &web.Error{Code: http.StatusBadRequest, Message: "Single-file downloads must use the direct download flow"}
 		}
-		return sources, info.Name() + ".zip", nil
+		return sources, archiveFileName(info.Name(), format), nil
 	}
 
-	return sources, fmt.Sprintf("download-%d.zip", time.Now().Unix()), nil
+	return sources, archiveFileName(fmt.Sprintf("download-%d", time.Now().Unix()), format), nil
 }
 
-func (h *Handler) runArchiveDownloadJob(
-	jobID string,
-	jobCtx context.Context,
-	owner string,
-	spaceID int64,
-	requestID string,
-	fileName string,
-	sources []archiveDownloadSource,
-) {
-	acquiredWorker := false
-	select {
-	case h.archiveDownloads.workers <- struct{}{}:
-		acquiredWorker = true
-	case <-jobCtx.Done():
-		return
+// runArchiveDownloadJob은 작업 큐에서 호출되는 아카이브 생성 함수입니다.
+// 재시도/재시작 시에도 안전하도록 매번 Space와 원본 경로를 다시 확인합니다.
+func (h *Handler) runArchiveDownloadJob(ctx context.Context, run *job.Run) error {
+	current := run.Job()
+	if current == nil || current.SpaceID == nil {
+		return job.Permanent(errors.New("archive job has no space"))
+	}
+
+	var payload archiveDownloadPayload
+	if err := current.DecodePayload(&payload); err != nil {
+		return job.Permanent(fmt.Errorf("invalid archive job payload: %w", err))
 	}
-	defer func() {
-		if acquiredWorker {
-			<-h.archiveDownloads.workers
-		}
-		h.cleanupArchiveDownloadJobs()
-	}()
 
-	job := h.archiveDownloads.updateJob(jobID, func(job *archiveDownloadJob) {
-		if job.State == archiveDownloadStateCanceled {
-			return
+	spaceData, err := h.spaceService.GetSpaceByID(ctx, *current.SpaceID)
+	if err != nil {
+		return job.Permanent(errors.New("Space not found"))
+	}
+	format := archiveDownloadFormat(payload)
+	password := ""
+	if payload.PasswordRef != "" {
+		stored, ok := h.lookupArchivePassword(payload.PasswordRef)
+		if !ok {
+			// 서버 재시작 등으로 메모리의 암호가 사라지면 암호 없이 만들지 않고 실패합니다.
+			return job.Permanent(errors.New("Archive password is no longer available"))
 		}
-		job.State = archiveDownloadStateRunning
-		job.FailureReason = ""
-	})
-	if job == nil || job.State == archiveDownloadStateCanceled {
-		return
+		password = stored
 	}
-	if err := jobCtx.Err(); err != nil {
-		return
+	sources, _, webErr := h.resolveArchiveDownloadSources(spaceData.SpacePath, payload.Paths, format)
+	if webErr != nil {
+		return job.Permanent(errors.New(webErr.Message))
 	}
 
 	entries, totalItems, totalBytes, err := scanArchiveZipEntries(sources)
 	if err != nil {
-		h.failArchiveDownloadJob(jobID, owner, requestID, safeFilesystemReason("Failed to scan archive sources", err))
-		return
+		return errors.New(safeFilesystemReason("Failed to scan archive sources", err))
 	}
+	run.SetTotals(totalItems, totalBytes)
 
-	h.archiveDownloads.updateJob(jobID, func(job *archiveDownloadJob) {
-		job.TotalItems = totalItems
-		job.TotalSourceBytes = totalBytes
-		job.ProcessedItems = 0
-		job.ProcessedSourceBytes = 0
-	})
+	if format != archiveFormatZip {
+		artifactPath, artifactSize, err := buildArchiveTempFile(ctx, format, entries, func(items int, bytes int64) {
+			run.Advance(items, bytes)
+		})
+		if err != nil {
+			if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+				return err
+			}
+			return errors.New(safeFilesystemReason("Failed to prepare archive entry", err))
+		}
+		run.SetArtifact(artifactPath, artifactSize)
+		return nil
+	}
 
 	zipTempPath, zipSize, webErr := h.buildZipTempArchive(func(zipWriter *zip.Writer) *web.Error {
 		for _, entry := range entries {
-			if err := jobCtx.Err(); err != nil {
+			if err := ctx.Err(); err != nil {
 				return &web.Error{Code: http.StatusConflict, Message: "Archive download canceled", Err: err}
 			}
-			writtenBytes, writeErr := writeArchiveZipEntry(jobCtx, zipWriter, entry)
+			writtenBytes, writeErr := writeZipEntry(ctx, zipWriter, entry, password)
 			if writeErr != nil {
 				if errors.Is(writeErr, context.Canceled) {
 					return &web.Error{Code: http.StatusConflict, Message: "Archive download canceled", Err: writeErr}
 				}
 				return &web.Error{Code: http.StatusInternalServerError, Message: safeFilesystemReason("Failed to prepare archive entry", writeErr), Err: writeErr}
 			}
-			h.archiveDownloads.updateJob(jobID, func(job *archiveDownloadJob) {
-				job.ProcessedItems++
-				job.ProcessedSourceBytes += writtenBytes
-			})
+			run.Advance(1, writtenBytes)
 		}
 		return nil
 	})
 	if webErr != nil {
 		if errors.Is(webErr.Err, context.Canceled) {
-			return
+			return webErr.Err
 		}
-		h.failArchiveDownloadJob(jobID, owner, requestID, webErr.Message)
-		return
+		return errors.New(webErr.Message)
 	}
-	if err := jobCtx.Err(); err != nil {
+	if err := ctx.Err(); err != nil {
 		_ = os.Remove(zipTempPath)
-		return
+		return err
 	}
 
-	expiresAt := time.Now().Add(h.archiveDownloads.ttlDuration())
-	publishCanceled := false
-	job = h.archiveDownloads.updateJob(jobID, func(job *archiveDownloadJob) {
-		if job.State == archiveDownloadStateCanceled {
-			publishCanceled = true
-			return
-		}
-		job.State = archiveDownloadStateReady
-		job.FailureReason = ""
-		job.ArtifactPath = zipTempPath
-		job.ArtifactSize = zipSize
-		job.FileName = fileName
-		job.ExpiresAt = expiresAt
-	})
-	if job == nil {
-		_ = os.Remove(zipTempPath)
+	run.SetArtifact(zipTempPath, zipSize)
+	return nil
+}
+
+// finishArchiveDownloadJob은 아카이브 작업이 준비 완료/실패로 끝났을 때 로그와 감사 기록을 남깁니다.
+func (h *Handler) finishArchiveDownloadJob(archiveJob *job.Job) {
+	h.releaseArchiveJobPassword(archiveJob)
+	if archiveJob.Status == job.StatusCanceled {
+		// 취소 감사 기록은 취소 요청을 처리한 쪽에서 남깁니다.
 		return
 	}
-	if publishCanceled {
-		_ = os.Remove(zipTempPath)
+	response := newArchiveDownloadStatusResponse(archiveJob)
+	if archiveJob.Status == job.StatusCompleted {
+		h.logArchiveJobEvent("info.archive.job_ready", archiveJob, nil)
+		h.recordSpaceAuditBackground(archiveJob.Owner, archiveJob.RequestID, audit.Event{
+			Action:  "file.archive-download",
+			Result:  audit.ResultSuccess,
+			Target:  archiveJob.ID,
+			SpaceID: archiveJob.SpaceID,
+			Metadata: map[string]any{
+				"jobId":                archiveJob.ID,
+				"filename":             response.FileName,
+				"format":               response.Format,
+				"encrypted":            response.Encrypted,
+				"size":                 archiveJob.ArtifactSize,
+				"status":               response.Status,
+				"sourceCount":          response.SourceCount,
+				"totalItems":           response.TotalItems,
+				"processedItems":       response.ProcessedItems,
+				"totalSourceBytes":     response.TotalSourceBytes,
+				"processedSourceBytes": response.ProcessedSourceBytes,
+			},
+		})
 		return
 	}
 
-	h.logArchiveJobEvent("info.archive.job_ready", job, nil)
-	h.recordSpaceAuditBackground(owner, requestID, audit.Event{
-		Action:    "file.archive-download",
-		Result:    audit.ResultSuccess,
-		Target:    job.ID,
-		RequestID: requestID,
-		SpaceID:   &spaceID,
+	h.logArchiveJobEvent("warn.archive.job_failed", archiveJob, errors.New(archiveJob.FailureReason))
+	h.recordSpaceAuditBackground(archiveJob.Owner, archiveJob.RequestID, audit.Event{
+		Action:  "file.archive-download",
+		Result:  audit.ResultFailure,
+		Target:  archiveJob.ID,
+		SpaceID: archiveJob.SpaceID,
 		Metadata: map[string]any{
-			"jobId":                job.ID,
-			"filename":             job.FileName,
-			"size":                 zipSize,
-			"status":               string(job.State),
-			"sourceCount":          job.SourceCount,
-			"totalItems":           job.TotalItems,
-			"processedItems":       job.ProcessedItems,
-			"totalSourceBytes":     job.TotalSourceBytes,
-			"processedSourceBytes": job.ProcessedSourceBytes,
+			"jobId":                archiveJob.ID,
+			"filename":             response.FileName,
+			"format":               response.Format,
+			"encrypted":            response.Encrypted,
+			"status":               response.Status,
+			"reason":               archiveJob.FailureReason,
+			"sourceCount":          response.SourceCount,
+			"totalItems":           response.TotalItems,
+			"processedItems":       response.ProcessedItems,
+			"totalSourceBytes":     response.TotalSourceBytes,
+			"processedSourceBytes": response.ProcessedSourceBytes,
 		},
 	})
 }
 
-func (h *Handler) failArchiveDownloadJob(jobID string, owner string, requestID string, reason string) {
-	job := h.archiveDownloads.updateJob(jobID, func(job *archiveDownloadJob) {
-		if job.State == archiveDownloadStateCanceled {
-			return
-		}
-		if job.ArtifactPath != "" {
-			_ = os.Remove(job.ArtifactPath)
-			job.ArtifactPath = ""
-		}
-		job.ArtifactSize = 0
-		job.State = archiveDownloadStateFailed
-		job.FailureReason = reason
-		job.ExpiresAt = time.Now().Add(h.archiveDownloads.ttlDuration())
-	})
-	if job == nil {
-		return
-	}
-
-	h.logArchiveJobEvent("warn.archive.job_failed", job, errors.New(reason))
-	h.recordSpaceAuditBackground(owner, requestID, audit.Event{
-		Action:    "file.archive-download",
-		Result:    audit.ResultFailure,
-		Target:    job.ID,
-		RequestID: requestID,
-		SpaceID:   &job.SpaceID,
+func (h *Handler) expireArchiveDownloadJob(archiveJob *job.Job) {
+	response := newArchiveDownloadStatusResponse(archiveJob)
+	h.logArchiveJobEvent("info.archive.job_expired", archiveJob, nil)
+	h.recordSpaceAuditBackground(archiveJob.Owner, archiveJob.RequestID, audit.Event{
+		Action:  "file.archive-download",
+		Result:  audit.ResultPartial,
+		Target:  archiveJob.ID,
+		SpaceID: archiveJob.SpaceID,
 		Metadata: map[string]any{
-			"jobId":                job.ID,
-			"filename":             job.FileName,
-			"status":               string(job.State),
-			"reason":               reason,
-			"sourceCount":          job.SourceCount,
-			"totalItems":           job.TotalItems,
-			"processedItems":       job.ProcessedItems,
-			"totalSourceBytes":     job.TotalSourceBytes,
-			"processedSourceBytes": job.ProcessedSourceBytes,
+			"jobId":                archiveJob.ID,
+			"filename":             response.FileName,
+			"status":               response.Status,
+			"reason":               response.FailureReason,
+			"sourceCount":          response.SourceCount,
+			"totalItems":           response.TotalItems,
+			"processedItems":       response.ProcessedItems,
+			"totalSourceBytes":     response.TotalSourceBytes,
+			"processedSourceBytes": response.ProcessedSourceBytes,
 		},
 	})
 }
 
-func (h *Handler) logArchiveJobEvent(eventName string, job *archiveDownloadJob, err error) {
-	if job == nil {
+func (h *Handler) logArchiveJobEvent(eventName string, archiveJob *job.Job, err error) {
+	if archiveJob == nil {
 		return
 	}
 
+	response := newArchiveDownloadStatusResponse(archiveJob)
+	var spaceID int64
+	if archiveJob.SpaceID != nil {
+		spaceID = *archiveJob.SpaceID
+	}
 	logger := logging.Event(log.Info(), logging.ComponentStorage, eventName).
-		Str("job_id", job.ID).
-		Int64("space_id", job.SpaceID).
-		Str("owner", job.Owner).
-		Str("status", string(job.State)).
-		Str("filename", job.FileName).
-		Int("source_count", job.SourceCount).
-		Int("total_items", job.TotalItems).
-		Int("processed_items", job.ProcessedItems).
-		Int64("total_source_bytes", job.TotalSourceBytes).
-		Int64("processed_source_bytes", job.ProcessedSourceBytes)
+		Str("job_id", archiveJob.ID).
+		Int64("space_id", spaceID).
+		Str("owner", archiveJob.Owner).
+		Str("status", response.Status).
+		Str("filename", response.FileName).
+		Int("source_count", response.SourceCount).
+		Int("total_items", response.TotalItems).
+		Int("processed_items", response.ProcessedItems).
+		Int64("total_source_bytes", response.TotalSourceBytes).
+		Int64("processed_source_bytes", response.ProcessedSourceBytes)
 	if err != nil {
 		logger = logger.Err(err)
 	}
 	logger.Msg("archive download job updated")
 }
 
+// cleanupArchiveDownloadJobs는 보존 기간이 지난 작업 산출물을 정리합니다.
+// 만료 감사 기록은 작업 유형의 OnExpire에서 남깁니다.
+func (h *Handler) cleanupArchiveDownloadJobs() {
+	if _, err := h.jobs.CleanupExpired(context.Background()); err != nil {
+		logging.Event(log.Warn(), logging.ComponentStorage, "warn.archive.job_cleanup_failed").
+			Err(err).
+			Msg("failed to clean up expired archive jobs")
+	}
+}
+
+// discardSpaceJobs는 삭제된 Space의 작업을 취소하고 생성된 임시 산출물을 지웁니다.
+func (h *Handler) discardSpaceJobs(spaceID int64, keepTypes ...string) int {
+	discarded, err := h.jobs.DiscardSpaceJobs(context.Background(), spaceID, keepTypes...)
+	if err != nil {
+		logging.Event(log.Warn(), logging.ComponentStorage, "warn.space.job_discard_failed").
+			Int64("space_id", spaceID).
+			Err(err).
+			Msg("failed to discard space jobs")
+	}
+	return discarded
+}
+
+// storeArchivePassword는 암호를 메모리에 보관하고 작업 입력에 넣을 참조 값을 돌려줍니다.
+// 암호가 비어 있으면 빈 참조를 돌려줍니다.
+func (h *Handler) storeArchivePassword(password string) (string, error) {
+	if password == "" {
+		return "", nil
+	}
+	ref, err := generateDownloadTicketToken()
+	if err != nil {
+		return "", err
+	}
+	h.archivePasswordMu.Lock()
+	defer h.archivePasswordMu.Unlock()
+	h.archivePasswords[ref] = password
+	return ref, nil
+}
+
+func (h *Handler) lookupArchivePassword(ref string) (string, bool) {
+	h.archivePasswordMu.Lock()
+	defer h.archivePasswordMu.Unlock()
+	password, ok := h.archivePasswords[ref]
+	return password, ok
+}
+
+func (h *Handler) releaseArchivePassword(ref string) {
+	if ref == "" {
+		return
+	}
+	h.archivePasswordMu.Lock()
+	defer h.archivePasswordMu.Unlock()
+	delete(h.archivePasswords, ref)
+}
+
+func (h *Handler) releaseArchiveJobPassword(archiveJob *job.Job) {
+	var payload archiveDownloadPayload
+	if err := archiveJob.DecodePayload(&payload); err == nil {
+		h.releaseArchivePassword(payload.PasswordRef)
+	}
+}
+
+func archiveDownloadFormat(payload archiveDownloadPayload) archiveFormat {
+	if payload.Format == "" {
+		return archiveFormatZip
+	}
+	return payload.Format
+}
+
+func newArchiveDownloadStatusResponse(archiveJob *job.Job) archiveDownloadStatusResponse {
+	var payload archiveDownloadPayload
+	_ = archiveJob.DecodePayload(&payload)
+
+	response := archiveDownloadStatusResponse{
+		JobID:                archiveJob.ID,
+		Status:               string(archiveDownloadStateFromJob(archiveJob.Status)),
+		FileName:             payload.FileName,
+		Format:               string(archiveDownloadFormat(payload)),
+		Encrypted:            payload.PasswordRef != "",
+		SourceCount:          len(payload.Paths),
+		TotalItems:           archiveJob.Progress.TotalItems,
+		ProcessedItems:       archiveJob.Progress.ProcessedItems,
+		TotalSourceBytes:     archiveJob.Progress.TotalBytes,
+		ProcessedSourceBytes: archiveJob.Progress.ProcessedBytes,
+		FailureReason:        archiveJob.FailureReason,
+		ArtifactSize:         archiveJob.ArtifactSize,
+	}
+	switch archiveJob.Status {
+	case job.StatusCanceled:
+		response.FailureReason = "archive canceled"
+	case job.StatusExpired:
+		response.FailureReason = "archive expired"
+	case job.StatusQueued, job.StatusRunning:
+		// 재시도 대기 중에는 직전 실패 사유를 노출하지 않습니다.
+		response.FailureReason = ""
+	}
+	if archiveJob.ExpiresAt != nil {
+		expiresAt := archiveJob.ExpiresAt.Format(time.RFC3339)
+		response.ExpiresAt = &expiresAt
+	}
+	return response
+}
+
+func archiveDownloadStateFromJob(status job.Status) archiveDownloadState {
+	switch status {
+	case job.StatusRunning:
+		return archiveDownloadStateRunning
+	case job.StatusCompleted:
+		return archiveDownloadStateReady
+	case job.StatusFailed:
+		return archiveDownloadStateFailed
+	case job.StatusCanceled:
+		return archiveDownloadStateCanceled
+	case job.StatusExpired:
+		return archiveDownloadStateExpired
+	default:
+		return archiveDownloadStateQueued
+	}
+}
+
 func (h *Handler) recordSpaceAuditBackground(actor string, requestID string, event audit.Event) {
 	if h.auditRecorder == nil {
 		return
@@ -737,107 +737,51 @@ func (h *Handler) recordSpaceAuditBackground(actor string, requestID string, eve
 	h.auditRecorder.RecordBestEffort(event)
 }
 
-func (h *Handler) cleanupArchiveDownloadJobs() {
-	expiredJobs := h.archiveDownloads.cleanupExpiredJobs()
-	for _, job := range expiredJobs {
-		h.logArchiveJobEvent("info.archive.job_expired", job, nil)
-		h.recordSpaceAuditBackground(job.Owner, job.RequestID, audit.Event{
-			Action:    "file.archive-download",
-			Result:    audit.ResultPartial,
-			Target:    job.ID,
-			RequestID: job.RequestID,
-			SpaceID:   &job.SpaceID,
-			Metadata: map[string]any{
-				"jobId":                job.ID,
-				"filename":             job.FileName,
-				"status":               string(job.State),
-				"reason":               job.FailureReason,
-				"sourceCount":          job.SourceCount,
-				"totalItems":           job.TotalItems,
-				"processedItems":       job.ProcessedItems,
-				"totalSourceBytes":     job.TotalSourceBytes,
-				"processedSourceBytes": job.ProcessedSourceBytes,
-			},
-		})
+// resolveArchiveSources는 아카이브에 담을 Space 상대 경로들을 확인해 절대 경로로 바꿉니다.
+func resolveArchiveSources(spaceRoot string, paths []string) ([]archiveDownloadSource, *web.Error) {
+	if len(paths) == 0 {
+		return nil, &web.Error{Code: http.StatusBadRequest, Message: "at least 1 path is required"}
 	}
-}
 
-func (j *archiveDownloadJob) toStatusResponse() archiveDownloadStatusResponse {
-	response := archiveDownloadStatusResponse{
-		JobID:                j.ID,
-		Status:               string(j.State),
-		FileName:             j.FileName,
-		SourceCount:          j.SourceCount,
-		TotalItems:           j.TotalItems,
-		ProcessedItems:       j.ProcessedItems,
-		TotalSourceBytes:     j.TotalSourceBytes,
-		ProcessedSourceBytes: j.ProcessedSourceBytes,
-		FailureReason:        j.FailureReason,
-		ArtifactSize:         j.ArtifactSize,
-	}
-	if !j.ExpiresAt.IsZero() {
-		expiresAt := j.ExpiresAt.Format(time.RFC3339)
-		response.ExpiresAt = &expiresAt
+	sources := make([]archiveDownloadSource, 0, len(paths))
+	for _, relPath := range paths {
+		if err := ensurePathOutsideTrash(relPath); err != nil {
+			return nil, &web.Error{Code: http.StatusForbidden, Message: fmt.Sprintf("Access denied: invalid path %s", relPath), Err: err}
+		}
+		absPath, err := resolveAbsPath(spaceRoot, relPath)
+		if err != nil {
+			return nil, &web.Error{Code: http.StatusForbidden, Message: fmt.Sprintf("Access denied: invalid path %s", relPath)}
+		}
+		info, err := os.Stat(absPath)
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil, &web.Error{Code: http.StatusNotFound, Message: fmt.Sprintf("Path not found: %s", relPath), Err: err}
+			}
+			return nil, storageAccessWebError(err, "", fmt.Sprintf("Failed to access path: %s", relPath))
+		}
+		sources = append(sources, archiveDownloadSource{
+			RelativePath: relPath,
+			AbsPath:      absPath,
+			BaseName:     info.Name(),
+		})
 	}
-	return response
+	return sources, nil
 }
 
 func scanArchiveZipEntries(sources []archiveDownloadSource) ([]archiveZipEntry, int, int64, error) {
 	entries := make([]archiveZipEntry, 0)
-	totalItems := 0
 	var totalBytes int64
-
-	for _, source := range sources {
-		info, err := os.Stat(source.AbsPath)
-		if err != nil {
-			return nil, 0, 0, err
-		}
-
-		if !info.IsDir() {
-			entries = append(entries, archiveZipEntry{
-				AbsPath: source.AbsPath,
-				ZipPath: filepath.ToSlash(source.BaseName),
-				Size:    info.Size(),
-			})
-			totalItems++
-			totalBytes += info.Size()
-			continue
-		}
-
-		err = filepath.Walk(source.AbsPath, func(currentPath string, entryInfo os.FileInfo, walkErr error) error {
-			if walkErr != nil {
-				return walkErr
-			}
-			if entryInfo.Mode()&os.ModeSymlink != 0 {
-				return nil
-			}
-			relPath, err := filepath.Rel(source.AbsPath, currentPath)
-			if err != nil {
-				return err
-			}
-			if relPath == "." {
-				return nil
-			}
-
-			zipPath := filepath.ToSlash(filepath.Join(source.BaseName, relPath))
-			entries = append(entries, archiveZipEntry{
-				AbsPath: currentPath,
-				ZipPath: zipPath,
-				Size:    entryInfo.Size(),
-				IsDir:   entryInfo.IsDir(),
-			})
-			totalItems++
-			if !entryInfo.IsDir() {
-				totalBytes += entryInfo.Size()
-			}
-			return nil
-		})
-		if err != nil {
-			return nil, 0, 0, err
+	err := walkArchiveSources(sources, func(entry archiveZipEntry) error {
+		entries = append(entries, entry)
+		if !entry.IsDir {
+			totalBytes += entry.Size
 		}
+		return nil
+	})
+	if err != nil {
+		return nil, 0, 0, err
 	}
-
-	return entries, totalItems, totalBytes, nil
+	return entries, len(entries), totalBytes, nil
 }
 
 func writeArchiveZipEntry(ctx context.Context, zipWriter *zip.Writer, entry archiveZipEntry) (int64, error) {
@@ -846,15 +790,12 @@ func writeArchiveZipEntry(ctx context.Context, zipWriter *zip.Writer, entry arch
 		return 0, err
 	}
 
-	header, err := zip.FileInfoHeader(info)
+	header, err := newArchiveZipHeader(info, entry.ZipPath)
 	if err != nil {
 		return 0, err
 	}
-	header.Name = filepath.ToSlash(entry.ZipPath)
-	header.Method = zip.Deflate
 
-	if entry.IsDir {
-		header.Name += "/"
+	if info.IsDir() {
 		if _, err := zipWriter.CreateHeader(header); err != nil {
 			return 0, err
 		}
@@ -0,0 +1,33 @@
+package handler
+
+import (
+	"context"
+
+	"taeu.kr/cohesion/internal/space/archive"
+)
+
+// 압축 파일 읽기는 WebDAV와 함께 쓰도록 archive 패키지에 있습니다.
+type (
+	archiveFormat         = archive.Format
+	archiveMember         = archive.Member
+	archiveMemberOpenFunc = archive.OpenFunc
+)
+
+const (
+	archiveFormatZip     = archive.FormatZip
+	archiveFormatTar     = archive.FormatTar
+	archiveFormatTarGzip = archive.FormatTarGzip
+	archiveFormatTarZstd = archive.FormatTarZstd
+)
+
+func detectArchiveFormat(name string) (archiveFormat, bool) {
+	return archive.DetectFormat(name)
+}
+
+func trimArchiveExtension(name string) string {
+	return archive.TrimExtension(name)
+}
+
+func walkArchive(ctx context.Context, absPath string, format archiveFormat, fn func(member archiveMember, open archiveMemberOpenFunc) error) error {
+	return archive.Walk(ctx, absPath, format, fn)
+}
@@ -0,0 +1,324 @@
+package handler
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/klauspost/compress/zstd"
+	"taeu.kr/cohesion/internal/platform/web"
+)
+
+// zipFlagUTF8은 항목 이름이 UTF-8임을 알리는 범용 비트 플래그(비트 11)입니다.
+const zipFlagUTF8 = 0x800
+
+// parseDownloadArchiveFormat은 다운로드 요청의 format 값을 해석합니다. 비어 있으면 ZIP입니다.
+func parseDownloadArchiveFormat(raw string) (archiveFormat, *web.Error) {
+	switch strings.ToLower(strings.TrimSpace(raw)) {
+	case "", "zip":
+		return archiveFormatZip, nil
+	case "tar":
+		return archiveFormatTar, nil
+	case "tar.gz", "tgz":
+		return archiveFormatTarGzip, nil
+	default:
+		return "", &web.Error{Code: http.StatusBadRequest, Message: "Unsupported download format"}
+	}
+}
+
+func archiveContentType(format archiveFormat) string {
+	switch format {
+	case archiveFormatTar:
+		return "application/x-tar"
+	case archiveFormatTarGzip:
+		return "application/gzip"
+	case archiveFormatTarZstd:
+		return "application/zstd"
+	default:
+		return "application/zip"
+	}
+}
+
+func archiveFileName(baseName string, format archiveFormat) string {
+	return baseName + "." + string(format)
+}
+
+// newArchiveZipHeader는 다운로드/압축 생성에서 공통으로 쓰는 ZIP 항목 헤더를 만듭니다.
+// 한글 이름이 Windows 탐색기에서 깨지지 않도록 ASCII가 아닌 UTF-8 이름에는 항상 UTF-8 플래그를 켭니다.
+// 4GiB를 넘는 항목은 archive/zip이 실제 크기를 보고 Zip64 데이터 서술자/중앙 디렉터리를 기록합니다.
+func newArchiveZipHeader(info os.FileInfo, name string) (*zip.FileHeader, error) {
+	header, err := zip.FileInfoHeader(info)
+	if err != nil {
+		return nil, err
+	}
+	header.Name = filepath.ToSlash(name)
+	header.Method = zip.Deflate
+	if info.IsDir() {
+		header.Name = strings.TrimSuffix(header.Name, "/") + "/"
+		header.Method = zip.Store
+	}
+	header.NonUTF8 = false
+	if utf8.ValidString(header.Name) && !isASCII(header.Name) {
+		header.Flags |= zipFlagUTF8
+	}
+	return header, nil
+}
+
+func isASCII(value string) bool {
+	for i := 0; i < len(value); i++ {
+		if value[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
+
+// walkArchiveSources는 아카이브에 담을 항목을 순서대로 넘깁니다.
+// 폴더 원본은 폴더 자체를 빼고 그 아래 항목만 넘기며, 심볼릭 링크는 건너뜁니다.
+func walkArchiveSources(sources []archiveDownloadSource, fn func(entry archiveZipEntry) error) error {
+	for _, source := range sources {
+		info, err := os.Stat(source.AbsPath)
+		if err != nil {
+			return err
+		}
+
+		if !info.IsDir() {
+			if err := fn(archiveZipEntry{
+				AbsPath: source.AbsPath,
+				ZipPath: filepath.ToSlash(source.BaseName),
+				Size:    info.Size(),
+			}); err != nil {
+				return err
+			}
+			continue
+		}
+
+		err = filepath.Walk(source.AbsPath, func(currentPath string, entryInfo os.FileInfo, walkErr error) error {
+			if walkErr != nil {
+				return walkErr
+			}
+			if entryInfo.Mode()&os.ModeSymlink != 0 {
+				return nil
+			}
+			relPath, err := filepath.Rel(source.AbsPath, currentPath)
+			if err != nil {
+				return err
+			}
+			if relPath == "." {
+				return nil
+			}
+
+			return fn(archiveZipEntry{
+				AbsPath: currentPath,
+				ZipPath: filepath.ToSlash(filepath.Join(source.BaseName, relPath)),
+				Size:    entryInfo.Size(),
+				IsDir:   entryInfo.IsDir(),
+			})
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// archiveStreamWriter는 zip/tar/tar.gz/tar.zst 출력을 항목 단위로 씁니다.
+// 대상이 파일이든 HTTP 응답이든 앞으로만 쓰므로 임시 파일 없이 바로 내보낼 수 있습니다.
+type archiveStreamWriter struct {
+	zipWriter  *zip.Writer
+	tarWriter  *tar.Writer
+	compressor io.WriteCloser
+	output     *abortableWriter
+}
+
+// abortableWriter는 중단 이후의 쓰기를 버립니다. 실패한 스트림 끝에 정상 종료 표식이 붙지 않게 합니다.
+type abortableWriter struct {
+	dst     io.Writer
+	aborted bool
+}
+
+func (w *abortableWriter) Write(p []byte) (int, error) {
+	if w.aborted {
+		return len(p), nil
+	}
+	return w.dst.Write(p)
+}
+
+func newArchiveStreamWriter(dst io.Writer, format archiveFormat) (*archiveStreamWriter, error) {
+	output := &abortableWriter{dst: dst}
+	writer := &archiveStreamWriter{output: output}
+
+	if format == archiveFormatZip {
+		writer.zipWriter = zip.NewWriter(output)
+		return writer, nil
+	}
+
+	var out io.Writer = output
+	switch format {
+	case archiveFormatTarGzip:
+		writer.compressor = gzip.NewWriter(output)
+	case archiveFormatTarZstd:
+		compressor, err := zstd.NewWriter(output)
+		if err != nil {
+			return nil, err
+		}
+		writer.compressor = compressor
+	case archiveFormatTar:
+	default:
+		return nil, fmt.Errorf("unsupported archive format: %s", format)
+	}
+	if writer.compressor != nil {
+		out = writer.compressor
+	}
+	writer.tarWriter = tar.NewWriter(out)
+	return writer, nil
+}
+
+// WriteEntry는 항목 하나를 쓰고 원본에서 읽은 바이트 수를 반환합니다.
+func (w *archiveStreamWriter) WriteEntry(ctx context.Context, entry archiveZipEntry) (int64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	if w.zipWriter != nil {
+		return writeArchiveZipEntry(ctx, w.zipWriter, entry)
+	}
+	return writeArchiveTarEntry(ctx, w.tarWriter, entry)
+}
+
+// Close는 아카이브 끝 표식과 압축 스트림을 마무리합니다.
+func (w *archiveStreamWriter) Close() error {
+	if w.zipWriter != nil {
+		return w.zipWriter.Close()
+	}
+	if err := w.tarWriter.Close(); err != nil {
+		return err
+	}
+	if w.compressor != nil {
+		return w.compressor.Close()
+	}
+	return nil
+}
+
+// Abort는 끝 표식을 쓰지 않고 압축기 자원만 정리합니다.
+func (w *archiveStreamWriter) Abort() {
+	w.output.aborted = true
+	if w.compressor != nil {
+		w.compressor.Close() //nolint:errcheck
+	}
+}
+
+func writeArchiveTarEntry(ctx context.Context, tarWriter *tar.Writer, entry archiveZipEntry) (int64, error) {
+	info, err := os.Stat(entry.AbsPath)
+	if err != nil {
+		return 0, err
+	}
+
+	header, err := tar.FileInfoHeader(info, "")
+	if err != nil {
+		return 0, err
+	}
+	header.Name = filepath.ToSlash(entry.ZipPath)
+	// 소유자 정보는 Space 밖 계정과 무관하므로 남기지 않습니다.
+	header.Uid, header.Gid, header.Uname, header.Gname = 0, 0, "", ""
+
+	if info.IsDir() {
+		header.Name += "/"
+		return 0, tarWriter.WriteHeader(header)
+	}
+	if err := tarWriter.WriteHeader(header); err != nil {
+		return 0, err
+	}
+
+	file, err := os.Open(entry.AbsPath)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	// 헤더에 적은 크기만큼만 씁니다. 그 사이 파일이 커져도 tar가 깨지지 않습니다.
+	return copyWithContext(ctx, tarWriter, io.LimitReader(file, header.Size))
+}
+
+// streamArchiveDownload는 원본을 훑으면서 바로 응답으로 tar/tar.gz를 내보냅니다.
+// 크기를 미리 알 수 없으므로 Content-Length 없이 전송합니다.
+func (h *Handler) streamArchiveDownload(
+	w http.ResponseWriter,
+	r *http.Request,
+	fileName string,
+	format archiveFormat,
+	sources []archiveDownloadSource,
+) *web.Error {
+	archiveWriter, err := newArchiveStreamWriter(w, format)
+	if err != nil {
+		return &web.Error{Code: http.StatusInternalServerError, Message: "Failed to prepare archive stream", Err: err}
+	}
+
+	w.Header().Set("Content-Type", archiveContentType(format))
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, fileName))
+	w.WriteHeader(http.StatusOK)
+
+	ctx := r.Context()
+	err = walkArchiveSources(sources, func(entry archiveZipEntry) error {
+		_, writeErr := archiveWriter.WriteEntry(ctx, entry)
+		return writeErr
+	})
+	if err == nil {
+		err = archiveWriter.Close()
+	}
+	if err != nil {
+		archiveWriter.Abort()
+		return &web.Error{Code: http.StatusInternalServerError, Message: "Failed to stream archive", Err: err}
+	}
+	return nil
+}
+
+// writeArchiveEntries는 항목들을 순서대로 dst에 format 형식으로 씁니다.
+func writeArchiveEntries(ctx context.Context, dst io.Writer, format archiveFormat, entries []archiveZipEntry, progress transferProgressFunc) error {
+	archiveWriter, err := newArchiveStreamWriter(dst, format)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		writtenBytes, err := archiveWriter.WriteEntry(ctx, entry)
+		if err != nil {
+			archiveWriter.Abort()
+			return err
+		}
+		progress(1, writtenBytes)
+	}
+	return archiveWriter.Close()
+}
+
+// buildArchiveTempFile은 아카이브 작업 산출물을 임시 파일로 만듭니다. 실패하면 임시 파일을 지웁니다.
+func buildArchiveTempFile(ctx context.Context, format archiveFormat, entries []archiveZipEntry, progress transferProgressFunc) (string, int64, error) {
+	tempFile, err := os.CreateTemp("", "cohesion-download-*."+string(format))
+	if err != nil {
+		return "", 0, err
+	}
+	tempPath := tempFile.Name()
+	defer tempFile.Close()
+
+	if err := writeArchiveEntries(ctx, tempFile, format, entries, progress); err != nil {
+		tempFile.Close()    //nolint:errcheck
+		os.Remove(tempPath) //nolint:errcheck
+		return "", 0, err
+	}
+	info, err := tempFile.Stat()
+	if err != nil {
+		os.Remove(tempPath) //nolint:errcheck
+		return "", 0, err
+	}
+	if err := tempFile.Close(); err != nil {
+		os.Remove(tempPath) //nolint:errcheck
+		return "", 0, err
+	}
+	return tempPath, info.Size(), nil
+}
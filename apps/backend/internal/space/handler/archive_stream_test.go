@@ -0,0 +1,182 @@
+package handler
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"taeu.kr/cohesion/internal/job"
+	"taeu.kr/cohesion/internal/space"
+)
+
+func newArchiveStreamTestHandler(t *testing.T) (*Handler, string) {
+	t.Helper()
+
+	spaceRoot := t.TempDir()
+	seedCompressSources(t, spaceRoot)
+	store := &fakeTransferSpaceStore{
+		spacesByID: map[int64]*space.Space{
+			1: {ID: 1, SpaceName: "Stream", SpacePath: spaceRoot},
+		},
+	}
+	return NewHandler(space.NewService(store), nil, nil), spaceRoot
+}
+
+func readStreamedArchive(t *testing.T, body []byte, format archiveFormat) []string {
+	t.Helper()
+
+	archivePath := filepath.Join(t.TempDir(), "download."+string(format))
+	if err := os.WriteFile(archivePath, body, 0o644); err != nil {
+		t.Fatalf("failed to save streamed archive: %v", err)
+	}
+	contents := readArchiveContents(t, archivePath, format)
+	names := make([]string, 0, len(contents))
+	for name, content := range contents {
+		names = append(names, name+"="+content)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestHandleFileDownload_StreamsFolderAsTarGzip(t *testing.T) {
+	handler, _ := newArchiveStreamTestHandler(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/spaces/1/files/download?path=docs&format=tar.gz", nil)
+	rec := httptest.NewRecorder()
+	if webErr := handler.handleFileDownload(rec, req, 1); webErr != nil {
+		t.Fatalf("unexpected web error: %+v", webErr)
+	}
+
+	if contentType := rec.Header().Get("Content-Type"); contentType != "application/gzip" {
+		t.Fatalf("unexpected content type: %q", contentType)
+	}
+	if contentLength := rec.Header().Get("Content-Length"); contentLength != "" {
+		t.Fatalf("streamed archive must not declare a length, got %q", contentLength)
+	}
+	if disposition := rec.Header().Get("Content-Disposition"); !strings.Contains(disposition, `filename="docs.tar.gz"`) {
+		t.Fatalf("unexpected content disposition: %q", disposition)
+	}
+
+	names := readStreamedArchive(t, rec.Body.Bytes(), archiveFormatTarGzip)
+	expected := "nested/=,nested/보고서.txt=report,readme.txt=readme"
+	if strings.Join(names, ",") != expected {
+		t.Fatalf("unexpected archive entries: %v", names)
+	}
+}
+
+func TestHandleFileDownloadMultiple_StreamsTar(t *testing.T) {
+	handler, _ := newArchiveStreamTestHandler(t)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/spaces/1/files/download-multiple", strings.NewReader(`{"paths":["docs","notes.txt"],"format":"tar"}`))
+	rec := httptest.NewRecorder()
+	if webErr := handler.handleFileDownloadMultiple(rec, req, 1); webErr != nil {
+		t.Fatalf("unexpected web error: %+v", webErr)
+	}
+	if contentType := rec.Header().Get("Content-Type"); contentType != "application/x-tar" {
+		t.Fatalf("unexpected content type: %q", contentType)
+	}
+
+	names := readStreamedArchive(t, rec.Body.Bytes(), archiveFormatTar)
+	expected := "docs/nested/=,docs/nested/보고서.txt=report,docs/readme.txt=readme,notes.txt=notes"
+	if strings.Join(names, ",") != expected {
+		t.Fatalf("unexpected archive entries: %v", names)
+	}
+}
+
+func TestHandleFileDownload_RejectsUnknownFormat(t *testing.T) {
+	handler, _ := newArchiveStreamTestHandler(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/spaces/1/files/download?path=docs&format=rar", nil)
+	webErr := handler.handleFileDownload(httptest.NewRecorder(), req, 1)
+	if webErr == nil || webErr.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for unsupported format, got %+v", webErr)
+	}
+}
+
+func TestHandleFileDownload_ZipMarksKoreanNamesAsUTF8(t *testing.T) {
+	handler, _ := newArchiveStreamTestHandler(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/spaces/1/files/download?path=docs", nil)
+	rec := httptest.NewRecorder()
+	if webErr := handler.handleFileDownload(rec, req, 1); webErr != nil {
+		t.Fatalf("unexpected web error: %+v", webErr)
+	}
+
+	body := rec.Body.Bytes()
+	reader, err := zip.NewReader(bytes.NewReader(body), int64(len(body)))
+	if err != nil {
+		t.Fatalf("failed to open zip: %v", err)
+	}
+	found := false
+	for _, file := range reader.File {
+		if file.Name != "nested/보고서.txt" {
+			continue
+		}
+		found = true
+		if file.Flags&zipFlagUTF8 == 0 || file.NonUTF8 {
+			t.Fatalf("expected UTF-8 flag on korean entry, flags=%#x", file.Flags)
+		}
+	}
+	if !found {
+		t.Fatal("expected korean entry in zip")
+	}
+}
+
+func TestArchiveDownloadJob_BuildsTarGzipArtifact(t *testing.T) {
+	handler, _ := newArchiveStreamTestHandler(t)
+	manager := job.NewManager(job.NewMemoryStore())
+	t.Cleanup(manager.Stop)
+	handler.SetJobManager(manager)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/spaces/1/files/archive-downloads", strings.NewReader(`{"paths":["docs","notes.txt"],"format":"tar.gz"}`))
+	req = withClaims(req, "tester")
+	rec := httptest.NewRecorder()
+	if webErr := handler.handleArchiveDownloadCreate(rec, req, 1); webErr != nil {
+		t.Fatalf("unexpected web error: %+v", webErr)
+	}
+	var created archiveDownloadStatusResponse
+	if err := json.NewDecoder(rec.Body).Decode(&created); err != nil {
+		t.Fatalf("failed to decode archive job: %v", err)
+	}
+	if created.Format != "tar.gz" || !strings.HasSuffix(created.FileName, ".tar.gz") {
+		t.Fatalf("unexpected archive job: %+v", created)
+	}
+
+	finished := waitForFileTransferJob(t, handler, created.JobID, job.StatusCompleted)
+	artifact, err := os.ReadFile(finished.ArtifactPath)
+	if err != nil {
+		t.Fatalf("failed to read artifact: %v", err)
+	}
+	names := readStreamedArchive(t, artifact, archiveFormatTarGzip)
+	expected := "docs/nested/=,docs/nested/보고서.txt=report,docs/readme.txt=readme,notes.txt=notes"
+	if strings.Join(names, ",") != expected {
+		t.Fatalf("unexpected archive entries: %v", names)
+	}
+
+	ticketReq := httptest.NewRequest(http.MethodPost, "/api/spaces/1/files/archive-download-ticket", strings.NewReader(`{"jobId":"`+created.JobID+`"}`))
+	ticketReq = withClaims(ticketReq, "tester")
+	ticketRec := httptest.NewRecorder()
+	if webErr := handler.handleArchiveDownloadTicket(ticketRec, ticketReq, 1); webErr != nil {
+		t.Fatalf("unexpected ticket error: %+v", webErr)
+	}
+	var ticket struct {
+		DownloadURL string `json:"downloadUrl"`
+	}
+	if err := json.NewDecoder(ticketRec.Body).Decode(&ticket); err != nil {
+		t.Fatalf("failed to decode ticket: %v", err)
+	}
+	downloadRec := httptest.NewRecorder()
+	if webErr := handler.handleSignedDownload(downloadRec, httptest.NewRequest(http.MethodGet, ticket.DownloadURL, nil)); webErr != nil {
+		t.Fatalf("unexpected signed download error: %+v", webErr)
+	}
+	if contentType := downloadRec.Header().Get("Content-Type"); contentType != "application/gzip" {
+		t.Fatalf("unexpected download content type: %q", contentType)
+	}
+}
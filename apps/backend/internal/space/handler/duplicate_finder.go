@@ -0,0 +1,839 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"taeu.kr/cohesion/internal/account"
+	"taeu.kr/cohesion/internal/audit"
+	"taeu.kr/cohesion/internal/auth"
+	"taeu.kr/cohesion/internal/job"
+	"taeu.kr/cohesion/internal/platform/logging"
+	"taeu.kr/cohesion/internal/platform/web"
+	"taeu.kr/cohesion/internal/space"
+	"taeu.kr/cohesion/internal/space/checksum"
+)
+
+const (
+	// FileDuplicatesJobType은 하나 이상의 Space에서 내용이 같은 파일을 찾는 분석 작업 유형입니다.
+	FileDuplicatesJobType = "file.duplicates"
+
+	defaultFileDuplicatesWorkerLimit = 1
+	// defaultDuplicateReportTTL은 분석이 끝난 보고서를 보관하는 기간입니다.
+	defaultDuplicateReportTTL = 24 * time.Hour
+	// duplicateHashWait는 파일 하나의 해시 계산을 기다리는 최대 시간입니다.
+	duplicateHashWait = 30 * time.Minute
+
+	defaultDuplicateGroupLimit = 50
+	maxDuplicateGroupLimit     = 500
+	// maxDuplicateTrashItems는 휴지통 이동 요청 한 번에 다루는 최대 파일 수입니다.
+	maxDuplicateTrashItems = 1000
+)
+
+// fileDuplicatesPayload는 작업 큐에 저장되는 중복 분석 입력입니다.
+// MinSize보다 작은 파일은 비교하지 않습니다. 0이면 빈 파일만 제외합니다.
+type fileDuplicatesPayload struct {
+	SpaceIDs []int64 `json:"spaceIds"`
+	MinSize  int64   `json:"minSize,omitempty"`
+}
+
+// fileDuplicatesResult는 중복 분석 요약입니다. 그룹 목록은 산출물 파일에 저장합니다.
+type fileDuplicatesResult struct {
+	SpaceIDs       []int64 `json:"spaceIds"`
+	ScannedFiles   int     `json:"scannedFiles"`
+	HashedFiles    int     `json:"hashedFiles"`
+	SkippedFiles   int     `json:"skippedFiles"`
+	Groups         int     `json:"groups"`
+	DuplicateFiles int     `json:"duplicateFiles"`
+	WastedBytes    int64   `json:"wastedBytes"`
+}
+
+// duplicateReport는 산출물 파일에 저장하는 전체 중복 그룹입니다.
+// 보는 사용자마다 읽을 수 있는 Space가 다르므로 권한 필터링 없이 저장하고 조회할 때 거릅니다.
+type duplicateReport struct {
+	Groups []duplicateGroup `json:"groups"`
+}
+
+type duplicateGroup struct {
+	Digest string          `json:"digest"`
+	Size   int64           `json:"size"`
+	Files  []duplicateFile `json:"files"`
+}
+
+type duplicateFile struct {
+	SpaceID int64     `json:"spaceId"`
+	Path    string    `json:"path"`
+	ModTime time.Time `json:"modTime"`
+}
+
+type duplicateGroupResponse struct {
+	Digest string `json:"digest"`
+	Size   int64  `json:"size"`
+	// WastedBytes는 한 벌만 남겼을 때 줄어드는 용량입니다.
+	WastedBytes int64           `json:"wastedBytes"`
+	Files       []duplicateFile `json:"files"`
+}
+
+type duplicateReportResponse struct {
+	JobID          string                   `json:"jobId"`
+	SpaceIDs       []int64                  `json:"spaceIds"`
+	TotalGroups    int                      `json:"totalGroups"`
+	DuplicateFiles int                      `json:"duplicateFiles"`
+	WastedBytes    int64                    `json:"wastedBytes"`
+	Offset         int                      `json:"offset"`
+	Limit          int                      `json:"limit"`
+	HasMore        bool                     `json:"hasMore"`
+	Groups         []duplicateGroupResponse `json:"groups"`
+	FinishedAt     *time.Time               `json:"finishedAt,omitempty"`
+}
+
+type duplicateTrashItem struct {
+	SpaceID int64  `json:"spaceId"`
+	Path    string `json:"path"`
+}
+
+type duplicateTrashSucceeded struct {
+	SpaceID     int64  `json:"spaceId"`
+	Path        string `json:"path"`
+	TrashItemID int64  `json:"trashItemId"`
+}
+
+type duplicateTrashFailed struct {
+	SpaceID int64  `json:"spaceId"`
+	Path    string `json:"path"`
+	Reason  string `json:"reason"`
+}
+
+type duplicateCandidate struct {
+	spaceID int64
+	relPath string
+	absPath string
+	size    int64
+	modTime time.Time
+}
+
+type duplicateFileKey struct {
+	spaceID int64
+	path    string
+}
+
+func (h *Handler) registerFileDuplicatesJobType() {
+	h.jobs.Register(FileDuplicatesJobType, job.TypeConfig{
+		Concurrency: defaultFileDuplicatesWorkerLimit,
+		ArtifactTTL: defaultDuplicateReportTTL,
+		Run:         h.runFileDuplicatesJob,
+		OnFinish:    h.finishFileDuplicatesJob,
+	})
+}
+
+// handleDuplicateScan: POST /api/duplicates
+// body: { spaceIds: []int64, minSize?: int64 }
+// 요청자가 읽을 수 있는 Space만 분석할 수 있습니다. 진행 상황은 /api/jobs/{id}로 확인합니다.
+func (h *Handler) handleDuplicateScan(w http.ResponseWriter, r *http.Request) *web.Error {
+	if r.Method != http.MethodPost {
+		return &web.Error{Code: http.StatusMethodNotAllowed, Message: "Method not allowed"}
+	}
+	claims, ok := auth.ClaimsFromContext(r.Context())
+	if !ok {
+		return &web.Error{Code: http.StatusUnauthorized, Message: "Unauthorized"}
+	}
+
+	var req struct {
+		SpaceIDs []int64 `json:"spaceIds"`
+		MinSize  int64   `json:"minSize,omitempty"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return &web.Error{Code: http.StatusBadRequest, Message: "Invalid request body", Err: err}
+	}
+	if req.MinSize < 0 {
+		return &web.Error{Code: http.StatusBadRequest, Message: "minSize must not be negative"}
+	}
+	spaceIDs := make([]int64, 0, len(req.SpaceIDs))
+	seen := make(map[int64]struct{}, len(req.SpaceIDs))
+	for _, spaceID := range req.SpaceIDs {
+		if _, exists := seen[spaceID]; exists {
+			continue
+		}
+		seen[spaceID] = struct{}{}
+		spaceIDs = append(spaceIDs, spaceID)
+	}
+	if len(spaceIDs) == 0 {
+		return &web.Error{Code: http.StatusBadRequest, Message: "spaceIds is required"}
+	}
+
+	for _, spaceID := range spaceIDs {
+		if _, webErr := h.getSpace(r, spaceID); webErr != nil {
+			return webErr
+		}
+		if webErr := h.ensureSpacePermission(r, spaceID, account.PermissionRead); webErr != nil {
+			return &web.Error{Code: http.StatusForbidden, Message: "Access denied: insufficient space permission", Err: webErr.Err}
+		}
+		if webErr := h.ensureSpaceReadable(r, spaceID); webErr != nil {
+			return webErr
+		}
+	}
+
+	request := job.EnqueueRequest{
+		Type:      FileDuplicatesJobType,
+		Owner:     claims.Username,
+		RequestID: strings.TrimSpace(r.Header.Get("X-Request-Id")),
+		Payload:   fileDuplicatesPayload{SpaceIDs: spaceIDs, MinSize: req.MinSize},
+	}
+	// Space 하나만 분석하면 Space 삭제 시 작업과 보고서도 함께 정리되도록 연결합니다.
+	if len(spaceIDs) == 1 {
+		request.SpaceID = &spaceIDs[0]
+	}
+	duplicatesJob, err := h.jobs.Enqueue(r.Context(), request)
+	if err != nil {
+		return &web.Error{Code: http.StatusInternalServerError, Message: "Failed to create duplicate scan job", Err: err}
+	}
+	h.logFileDuplicatesJobEvent("info.duplicates.job_created", duplicatesJob, nil)
+	return writeJSON(w, http.StatusAccepted, duplicatesJob)
+}
+
+// handleDuplicateReportRoute는 /api/duplicates/{jobId}와 /api/duplicates/{jobId}/trash를 분기합니다.
+func (h *Handler) handleDuplicateReportRoute(w http.ResponseWriter, r *http.Request) *web.Error {
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/duplicates/"), "/"), "/")
+	if parts[0] == "" || len(parts) > 2 {
+		return &web.Error{Code: http.StatusNotFound, Message: "Not found"}
+	}
+	if len(parts) == 2 {
+		if parts[1] != "trash" {
+			return &web.Error{Code: http.StatusNotFound, Message: "Not found"}
+		}
+		if r.Method != http.MethodPost {
+			return &web.Error{Code: http.StatusMethodNotAllowed, Message: "Method not allowed"}
+		}
+		return h.handleDuplicateTrash(w, r, parts[0])
+	}
+	if r.Method != http.MethodGet {
+		return &web.Error{Code: http.StatusMethodNotAllowed, Message: "Method not allowed"}
+	}
+	return h.handleDuplicateReport(w, r, parts[0])
+}
+
+// handleDuplicateReport: GET /api/duplicates/{jobId}?offset={n}&limit={n}
+// 보는 사용자가 지금 읽을 수 있는 Space의 파일만 보여 주고, 그 결과 두 벌 미만이 된 그룹은 뺍니다.
+func (h *Handler) handleDuplicateReport(w http.ResponseWriter, r *http.Request, jobID string) *web.Error {
+	offset, limit, err := parseDuplicatePage(r)
+	if err != nil {
+		return &web.Error{Code: http.StatusBadRequest, Message: err.Error(), Err: err}
+	}
+	duplicatesJob, report, readable, webErr := h.loadDuplicateReport(r, jobID)
+	if webErr != nil {
+		return webErr
+	}
+
+	groups := visibleDuplicateGroups(report, readable)
+	response := duplicateReportResponse{
+		JobID:       duplicatesJob.ID,
+		SpaceIDs:    make([]int64, 0, len(readable)),
+		TotalGroups: len(groups),
+		Offset:      offset,
+		Limit:       limit,
+		Groups:      []duplicateGroupResponse{},
+		FinishedAt:  duplicatesJob.FinishedAt,
+	}
+	var payload fileDuplicatesPayload
+	_ = duplicatesJob.DecodePayload(&payload)
+	for _, spaceID := range payload.SpaceIDs {
+		if readable[spaceID] {
+			response.SpaceIDs = append(response.SpaceIDs, spaceID)
+		}
+	}
+	for _, group := range groups {
+		response.DuplicateFiles += len(group.Files) - 1
+		response.WastedBytes += group.WastedBytes
+	}
+	if offset < len(groups) {
+		end := min(offset+limit, len(groups))
+		response.Groups = groups[offset:end]
+		response.HasMore = end < len(groups)
+	}
+	return writeJSON(w, http.StatusOK, response)
+}
+
+// handleDuplicateTrash: POST /api/duplicates/{jobId}/trash
+// body: { items: [{ spaceId: int64, path: string }] }
+// 보고서에 있는 중복 파일만 휴지통으로 옮기며, 그룹마다 고르지 않은 사본이 적어도 하나는 디스크에 남아 있어야 합니다.
+// 분석 이후 내용이 바뀐 파일은 옮기지 않습니다.
+func (h *Handler) handleDuplicateTrash(w http.ResponseWriter, r *http.Request, jobID string) *web.Error {
+	if webErr := h.ensureTrashService(); webErr != nil {
+		return webErr
+	}
+	var req struct {
+		Items []duplicateTrashItem `json:"items"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return &web.Error{Code: http.StatusBadRequest, Message: "Invalid request body", Err: err}
+	}
+	if len(req.Items) == 0 {
+		return &web.Error{Code: http.StatusBadRequest, Message: "items array is required and cannot be empty"}
+	}
+	if len(req.Items) > maxDuplicateTrashItems {
+		return &web.Error{Code: http.StatusBadRequest, Message: fmt.Sprintf("Too many items (max %d)", maxDuplicateTrashItems)}
+	}
+
+	duplicatesJob, report, readable, webErr := h.loadDuplicateReport(r, jobID)
+	if webErr != nil {
+		return webErr
+	}
+
+	groupByFile := make(map[duplicateFileKey]int)
+	for index, group := range report.Groups {
+		for _, file := range group.Files {
+			groupByFile[duplicateFileKey{spaceID: file.SpaceID, path: file.Path}] = index
+		}
+	}
+
+	succeeded := []duplicateTrashSucceeded{}
+	failed := []duplicateTrashFailed{}
+	selected := make([]duplicateTrashItem, 0, len(req.Items))
+	seen := make(map[duplicateFileKey]struct{}, len(req.Items))
+	for _, item := range req.Items {
+		item.Path = normalizeRelativePath(item.Path)
+		key := duplicateFileKey{spaceID: item.SpaceID, path: item.Path}
+		_, found := groupByFile[key]
+		// 읽을 수 없는 Space의 파일은 보고서에 없는 것과 같게 다룹니다.
+		if !found || !readable[item.SpaceID] {
+			failed = append(failed, duplicateTrashFailed{SpaceID: item.SpaceID, Path: item.Path, Reason: "Not a duplicate in this report"})
+			continue
+		}
+		if _, exists := seen[key]; exists {
+			continue
+		}
+		seen[key] = struct{}{}
+		selected = append(selected, item)
+	}
+
+	touched := make(map[int64]*duplicateTrashAuditCounts)
+	countFor := func(spaceID int64) *duplicateTrashAuditCounts {
+		if counts, ok := touched[spaceID]; ok {
+			return counts
+		}
+		counts := &duplicateTrashAuditCounts{}
+		touched[spaceID] = counts
+		return counts
+	}
+	keptByGroup := make(map[int]int)
+	for _, item := range selected {
+		groupIndex := groupByFile[duplicateFileKey{spaceID: item.SpaceID, path: item.Path}]
+		group := report.Groups[groupIndex]
+		counts := countFor(item.SpaceID)
+		kept, counted := keptByGroup[groupIndex]
+		if !counted {
+			kept = h.countKeptDuplicates(r.Context(), group, seen)
+			keptByGroup[groupIndex] = kept
+		}
+		if kept == 0 {
+			failed = append(failed, duplicateTrashFailed{SpaceID: item.SpaceID, Path: item.Path, Reason: "At least one copy must be kept"})
+			counts.failed++
+			continue
+		}
+		trashItem, reason := h.trashDuplicateFile(r, item, group)
+		if reason != "" {
+			failed = append(failed, duplicateTrashFailed{SpaceID: item.SpaceID, Path: item.Path, Reason: reason})
+			counts.failed++
+			continue
+		}
+		succeeded = append(succeeded, duplicateTrashSucceeded{SpaceID: item.SpaceID, Path: item.Path, TrashItemID: trashItem.ID})
+		counts.succeeded++
+		counts.bytes += group.Size
+	}
+
+	for spaceID, counts := range touched {
+		if counts.succeeded > 0 {
+			h.markSearchIndexDirty(r.Context(), spaceID, "delete-multiple")
+		}
+		result := audit.ResultSuccess
+		if counts.succeeded == 0 {
+			result = audit.ResultFailure
+		} else if counts.failed > 0 {
+			result = audit.ResultPartial
+		}
+		h.recordSpaceAudit(r, audit.Event{
+			Action: "file.duplicates.trash",
+			Result: result,
+			Target: fmt.Sprintf("%d items", counts.succeeded+counts.failed),
+			Metadata: map[string]any{
+				"jobId":      duplicatesJob.ID,
+				"total":      counts.succeeded + counts.failed,
+				"succeeded":  counts.succeeded,
+				"failed":     counts.failed,
+				"freedBytes": counts.bytes,
+			},
+		}, spaceID)
+	}
+
+	return writeJSON(w, http.StatusOK, map[string]any{"succeeded": succeeded, "failed": failed})
+}
+
+// countKeptDuplicates는 그룹에서 이번에 고르지 않았고 아직 같은 크기로 남아 있는 파일 수를 셉니다.
+// 이전 요청으로 이미 옮긴 사본은 남은 것으로 치지 않습니다.
+func (h *Handler) countKeptDuplicates(ctx context.Context, group duplicateGroup, selected map[duplicateFileKey]struct{}) int {
+	kept := 0
+	for _, file := range group.Files {
+		if _, chosen := selected[duplicateFileKey{spaceID: file.SpaceID, path: file.Path}]; chosen {
+			continue
+		}
+		spaceData, err := h.spaceService.GetSpaceByID(ctx, file.SpaceID)
+		if err != nil {
+			continue
+		}
+		absPath, err := resolveAbsPath(spaceData.SpacePath, file.Path)
+		if err != nil {
+			continue
+		}
+		if info, err := os.Stat(absPath); err == nil && info.Mode().IsRegular() && info.Size() == group.Size {
+			kept++
+		}
+	}
+	return kept
+}
+
+type duplicateTrashAuditCounts struct {
+	succeeded int
+	failed    int
+	bytes     int64
+}
+
+// trashDuplicateFile은 쓰기 권한과 Space 상태, 분석 이후 내용 변경 여부를 확인하고 파일을 휴지통으로 옮깁니다.
+// 실패하면 사용자에게 보여 줄 사유를 반환합니다.
+func (h *Handler) trashDuplicateFile(r *http.Request, item duplicateTrashItem, group duplicateGroup) (*space.TrashItem, string) {
+	if webErr := h.ensureSpacePermission(r, item.SpaceID, account.PermissionWrite); webErr != nil {
+		return nil, "Permission denied"
+	}
+	release, webErr := h.beginSpaceWrite(r.Context(), item.SpaceID)
+	if webErr != nil {
+		return nil, webErr.Message
+	}
+	defer release()
+	spaceData, webErr := h.getSpace(r, item.SpaceID)
+	if webErr != nil {
+		return nil, "Space not found"
+	}
+	absPath, err := resolveAbsPath(spaceData.SpacePath, item.Path)
+	if err != nil {
+		return nil, "Access denied: invalid path"
+	}
+	entry, err := h.checksums.Sum(r.Context(), absPath, checksum.SHA256, checksumWait)
+	if err != nil {
+		switch {
+		case errors.Is(err, checksum.ErrPending):
+			return nil, "Checksum is still being computed"
+		case os.IsNotExist(err):
+			return nil, "File or directory not found"
+		default:
+			return nil, checksumFailureReason(err)
+		}
+	}
+	if entry.Digest != group.Digest || entry.Size != group.Size {
+		return nil, "File changed since scan"
+	}
+
+	trashed, err := h.softDeletePath(r, spaceData, item.Path)
+	if err != nil {
+		return nil, err.Error()
+	}
+	return trashed, ""
+}
+
+// loadDuplicateReport는 완료된 중복 분석 작업과 보고서, 요청자가 지금 읽을 수 있는 Space 집합을 반환합니다.
+// 분석한 Space를 하나도 읽을 수 없으면 보고서가 없는 것처럼 404로 응답합니다.
+func (h *Handler) loadDuplicateReport(r *http.Request, jobID string) (*job.Job, *duplicateReport, map[int64]bool, *web.Error) {
+	claims, ok := auth.ClaimsFromContext(r.Context())
+	if !ok {
+		return nil, nil, nil, &web.Error{Code: http.StatusUnauthorized, Message: "Unauthorized"}
+	}
+
+	duplicatesJob, err := h.jobs.Get(r.Context(), jobID)
+	if err != nil {
+		if errors.Is(err, job.ErrNotFound) {
+			return nil, nil, nil, &web.Error{Code: http.StatusNotFound, Message: "Duplicate report not found", Err: err}
+		}
+		return nil, nil, nil, &web.Error{Code: http.StatusInternalServerError, Message: "Failed to get duplicate report", Err: err}
+	}
+	if duplicatesJob.Type != FileDuplicatesJobType {
+		return nil, nil, nil, &web.Error{Code: http.StatusNotFound, Message: "Duplicate report not found"}
+	}
+
+	var payload fileDuplicatesPayload
+	if err := duplicatesJob.DecodePayload(&payload); err != nil {
+		return nil, nil, nil, &web.Error{Code: http.StatusInternalServerError, Message: "Failed to decode duplicate report", Err: err}
+	}
+	readable := make(map[int64]bool, len(payload.SpaceIDs))
+	isAdmin := h.isAdminRequest(r)
+	for _, spaceID := range payload.SpaceIDs {
+		spaceData, err := h.spaceService.GetSpaceByID(r.Context(), spaceID)
+		if err != nil || spaceData.CheckReadable(isAdmin) != nil {
+			continue
+		}
+		allowed, err := h.accountService.CanAccessSpaceByID(r.Context(), claims.Username, spaceID, account.PermissionRead)
+		if err != nil {
+			return nil, nil, nil, &web.Error{Code: http.StatusInternalServerError, Message: "Failed to evaluate space access", Err: err}
+		}
+		if allowed {
+			readable[spaceID] = true
+		}
+	}
+	if len(readable) == 0 {
+		return nil, nil, nil, &web.Error{Code: http.StatusNotFound, Message: "Duplicate report not found"}
+	}
+
+	switch duplicatesJob.Status {
+	case job.StatusCompleted:
+	case job.StatusExpired:
+		return nil, nil, nil, &web.Error{Code: http.StatusGone, Message: "Duplicate report has expired"}
+	default:
+		return nil, nil, nil, &web.Error{Code: http.StatusConflict, Message: "Duplicate scan has not completed"}
+	}
+	if strings.TrimSpace(duplicatesJob.ArtifactPath) == "" {
+		return nil, nil, nil, &web.Error{Code: http.StatusGone, Message: "Duplicate report has expired"}
+	}
+
+	file, err := os.Open(duplicatesJob.ArtifactPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil, nil, &web.Error{Code: http.StatusGone, Message: "Duplicate report has expired", Err: err}
+		}
+		return nil, nil, nil, &web.Error{Code: http.StatusInternalServerError, Message: "Failed to read duplicate report", Err: err}
+	}
+	defer file.Close()
+	var report duplicateReport
+	if err := json.NewDecoder(file).Decode(&report); err != nil {
+		return nil, nil, nil, &web.Error{Code: http.StatusInternalServerError, Message: "Failed to read duplicate report", Err: err}
+	}
+	return duplicatesJob, &report, readable, nil
+}
+
+// visibleDuplicateGroups는 읽을 수 있는 Space의 파일만 남기고, 여전히 두 벌 이상인 그룹만 반환합니다.
+func visibleDuplicateGroups(report *duplicateReport, readable map[int64]bool) []duplicateGroupResponse {
+	groups := make([]duplicateGroupResponse, 0, len(report.Groups))
+	for _, group := range report.Groups {
+		files := make([]duplicateFile, 0, len(group.Files))
+		for _, file := range group.Files {
+			if readable[file.SpaceID] {
+				files = append(files, file)
+			}
+		}
+		if len(files) < 2 {
+			continue
+		}
+		groups = append(groups, duplicateGroupResponse{
+			Digest:      group.Digest,
+			Size:        group.Size,
+			WastedBytes: group.Size * int64(len(files)-1),
+			Files:       files,
+		})
+	}
+	sort.SliceStable(groups, func(i, j int) bool {
+		return groups[i].WastedBytes > groups[j].WastedBytes
+	})
+	return groups
+}
+
+func parseDuplicatePage(r *http.Request) (int, int, error) {
+	query := r.URL.Query()
+	offset := 0
+	if raw := strings.TrimSpace(query.Get("offset")); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 0 {
+			return 0, 0, errors.New("offset must be a non-negative integer")
+		}
+		offset = parsed
+	}
+	limit := defaultDuplicateGroupLimit
+	if raw := strings.TrimSpace(query.Get("limit")); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed <= 0 {
+			return 0, 0, errors.New("limit must be a positive integer")
+		}
+		limit = min(parsed, maxDuplicateGroupLimit)
+	}
+	return offset, limit, nil
+}
+
+// runFileDuplicatesJob은 Space들을 훑어 크기가 같은 파일끼리 묶고, 그 후보만 sha256으로 비교합니다.
+// 해시는 공용 해시 캐시를 쓰므로 이미 계산된 파일은 다시 읽지 않습니다. 보고서는 산출물 파일로 남깁니다.
+func (h *Handler) runFileDuplicatesJob(ctx context.Context, run *job.Run) error {
+	current := run.Job()
+	if current == nil {
+		return job.Permanent(errors.New("duplicate scan job is missing"))
+	}
+	var payload fileDuplicatesPayload
+	if err := current.DecodePayload(&payload); err != nil {
+		return job.Permanent(fmt.Errorf("invalid duplicate scan job payload: %w", err))
+	}
+
+	result := fileDuplicatesResult{SpaceIDs: payload.SpaceIDs}
+	bySize := make(map[int64][]duplicateCandidate)
+	for _, spaceID := range payload.SpaceIDs {
+		spaceData, err := h.spaceService.GetSpaceByID(ctx, spaceID)
+		if err != nil {
+			return job.Permanent(errors.New("Space not found"))
+		}
+		scanned, skipped, err := collectDuplicateCandidates(ctx, spaceID, spaceData.SpacePath, payload.MinSize, func(candidate duplicateCandidate) {
+			bySize[candidate.size] = append(bySize[candidate.size], candidate)
+		})
+		if err != nil {
+			if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+				return err
+			}
+			return errors.New(safeFilesystemReason("Failed to scan space", err))
+		}
+		result.ScannedFiles += scanned
+		result.SkippedFiles += skipped
+	}
+
+	targets := make([]duplicateCandidate, 0)
+	var totalBytes int64
+	for size, candidates := range bySize {
+		if len(candidates) < 2 {
+			continue
+		}
+		targets = append(targets, candidates...)
+		totalBytes += size * int64(len(candidates))
+	}
+	run.SetTotals(len(targets), totalBytes)
+
+	digests, err := h.hashDuplicateCandidates(ctx, targets, func(size int64) {
+		run.Advance(1, size)
+	})
+	if err != nil {
+		return err
+	}
+
+	type groupKey struct {
+		size   int64
+		digest string
+	}
+	grouped := make(map[groupKey][]duplicateFile)
+	for index, candidate := range targets {
+		if digests[index] == "" {
+			result.SkippedFiles++
+			continue
+		}
+		result.HashedFiles++
+		key := groupKey{size: candidate.size, digest: digests[index]}
+		grouped[key] = append(grouped[key], duplicateFile{SpaceID: candidate.spaceID, Path: candidate.relPath, ModTime: candidate.modTime})
+	}
+
+	report := duplicateReport{Groups: make([]duplicateGroup, 0)}
+	for key, files := range grouped {
+		if len(files) < 2 {
+			continue
+		}
+		sort.Slice(files, func(i, j int) bool {
+			if files[i].SpaceID != files[j].SpaceID {
+				return files[i].SpaceID < files[j].SpaceID
+			}
+			return files[i].Path < files[j].Path
+		})
+		report.Groups = append(report.Groups, duplicateGroup{Digest: key.digest, Size: key.size, Files: files})
+		result.Groups++
+		result.DuplicateFiles += len(files) - 1
+		result.WastedBytes += key.size * int64(len(files)-1)
+	}
+	sort.Slice(report.Groups, func(i, j int) bool {
+		left := report.Groups[i].Size * int64(len(report.Groups[i].Files)-1)
+		right := report.Groups[j].Size * int64(len(report.Groups[j].Files)-1)
+		if left != right {
+			return left > right
+		}
+		return report.Groups[i].Digest < report.Groups[j].Digest
+	})
+
+	reportPath, reportSize, err := writeDuplicateReport(report)
+	if err != nil {
+		return errors.New("Failed to write duplicate report")
+	}
+	run.SetArtifact(reportPath, reportSize)
+	return run.SetResult(result)
+}
+
+// collectDuplicateCandidates는 Space의 일반 파일 중 minSize 이상인 파일을 찾습니다.
+// 휴지통과 심볼릭 링크는 건너뛰고, 읽을 수 없는 하위 폴더는 건너뛴 항목으로 셉니다.
+func collectDuplicateCandidates(ctx context.Context, spaceID int64, root string, minSize int64, add func(duplicateCandidate)) (int, int, error) {
+	scanned, skipped := 0, 0
+	err := filepath.WalkDir(root, func(current string, entry fs.DirEntry, walkErr error) error {
+		if walkErr != nil {
+			if current == root {
+				return walkErr
+			}
+			skipped++
+			if entry != nil && entry.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if current == root {
+			return nil
+		}
+		if entry.IsDir() {
+			if entry.Name() == spaceTrashDirectoryName {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil {
+			skipped++
+			return nil
+		}
+		scanned++
+		if info.Size() == 0 || info.Size() < minSize {
+			return nil
+		}
+		relPath, err := filepath.Rel(root, current)
+		if err != nil {
+			return err
+		}
+		add(duplicateCandidate{
+			spaceID: spaceID,
+			relPath: filepath.ToSlash(relPath),
+			absPath: current,
+			size:    info.Size(),
+			modTime: info.ModTime(),
+		})
+		return nil
+	})
+	return scanned, skipped, err
+}
+
+// hashDuplicateCandidates는 후보 파일의 sha256을 해시 캐시 동시 계산 수만큼 나눠 구합니다.
+// 계산하지 못한 파일은 빈 문자열로 남깁니다.
+func (h *Handler) hashDuplicateCandidates(ctx context.Context, targets []duplicateCandidate, advance func(size int64)) ([]string, error) {
+	digests := make([]string, len(targets))
+	work := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < checksum.DefaultConcurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range work {
+				entry, err := h.checksums.Sum(ctx, targets[index].absPath, checksum.SHA256, duplicateHashWait)
+				if err == nil && entry.Size == targets[index].size {
+					digests[index] = entry.Digest
+				}
+				advance(targets[index].size)
+			}
+		}()
+	}
+
+feed:
+	for index := range targets {
+		select {
+		case work <- index:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(work)
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return digests, nil
+}
+
+func writeDuplicateReport(report duplicateReport) (string, int64, error) {
+	file, err := os.CreateTemp("", "cohesion-duplicates-*.json")
+	if err != nil {
+		return "", 0, err
+	}
+	if err := json.NewEncoder(file).Encode(report); err != nil {
+		file.Close()
+		os.Remove(file.Name()) //nolint:errcheck
+		return "", 0, err
+	}
+	if err := file.Close(); err != nil {
+		os.Remove(file.Name()) //nolint:errcheck
+		return "", 0, err
+	}
+	info, err := os.Stat(file.Name())
+	if err != nil {
+		os.Remove(file.Name()) //nolint:errcheck
+		return "", 0, err
+	}
+	return file.Name(), info.Size(), nil
+}
+
+// finishFileDuplicatesJob은 분석 결과를 감사 기록으로 남깁니다.
+func (h *Handler) finishFileDuplicatesJob(duplicatesJob *job.Job) {
+	var payload fileDuplicatesPayload
+	_ = duplicatesJob.DecodePayload(&payload)
+	var result fileDuplicatesResult
+	_ = duplicatesJob.DecodeResult(&result)
+
+	auditResult := audit.ResultSuccess
+	if duplicatesJob.Status != job.StatusCompleted {
+		auditResult = audit.ResultFailure
+	}
+	metadata := map[string]any{
+		"jobId":          duplicatesJob.ID,
+		"spaceCount":     len(payload.SpaceIDs),
+		"scannedFiles":   result.ScannedFiles,
+		"groups":         result.Groups,
+		"duplicateFiles": result.DuplicateFiles,
+		"wastedBytes":    result.WastedBytes,
+		"skippedFiles":   result.SkippedFiles,
+		"status":         string(duplicatesJob.Status),
+	}
+	if duplicatesJob.FailureReason != "" {
+		metadata["reason"] = duplicatesJob.FailureReason
+	}
+
+	var runErr error
+	if duplicatesJob.Status == job.StatusFailed {
+		runErr = errors.New(duplicatesJob.FailureReason)
+	}
+	h.logFileDuplicatesJobEvent("info.duplicates.job_finished", duplicatesJob, runErr)
+	// 여러 Space를 분석했다면 Space마다 같은 기록을 남겨 각 Space 감사 로그에서 보이게 합니다.
+	for _, spaceID := range payload.SpaceIDs {
+		spaceID := spaceID
+		h.recordSpaceAuditBackground(duplicatesJob.Owner, duplicatesJob.RequestID, audit.Event{
+			Action:   "file.duplicates.scan",
+			Result:   auditResult,
+			Target:   fmt.Sprintf("%d spaces", len(payload.SpaceIDs)),
+			SpaceID:  &spaceID,
+			Metadata: metadata,
+		})
+	}
+}
+
+func (h *Handler) logFileDuplicatesJobEvent(eventName string, duplicatesJob *job.Job, err error) {
+	var payload fileDuplicatesPayload
+	_ = duplicatesJob.DecodePayload(&payload)
+
+	logger := logging.Event(log.Info(), logging.ComponentStorage, eventName).
+		Str("job_id", duplicatesJob.ID).
+		Str("owner", duplicatesJob.Owner).
+		Str("status", string(duplicatesJob.Status)).
+		Int("space_count", len(payload.SpaceIDs)).
+		Int("total_items", duplicatesJob.Progress.TotalItems).
+		Int("processed_items", duplicatesJob.Progress.ProcessedItems).
+		Int64("total_bytes", duplicatesJob.Progress.TotalBytes).
+		Int64("processed_bytes", duplicatesJob.Progress.ProcessedBytes)
+	if err != nil {
+		logger = logger.Err(err)
+	}
+	logger.Msg("duplicate scan job updated")
+}
@@ -0,0 +1,202 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"taeu.kr/cohesion/internal/account"
+	"taeu.kr/cohesion/internal/job"
+	"taeu.kr/cohesion/internal/space"
+)
+
+// fakeDuplicateAccessService는 사용자별로 Space 권한을 다르게 줍니다.
+type fakeDuplicateAccessService struct {
+	grants map[string]map[int64]account.Permission
+}
+
+func (f *fakeDuplicateAccessService) CanAccessSpaceByID(ctx context.Context, username string, spaceID int64, required account.Permission) (bool, error) {
+	granted, ok := f.grants[username][spaceID]
+	if !ok {
+		return false, nil
+	}
+	if required == account.PermissionRead {
+		return true, nil
+	}
+	return granted == account.PermissionWrite || granted == account.PermissionManage, nil
+}
+
+func serveDuplicateRequest(t *testing.T, mux *http.ServeMux, method string, path string, username string, body any) *httptest.ResponseRecorder {
+	t.Helper()
+
+	var reader *bytes.Reader
+	if body != nil {
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			t.Fatalf("failed to marshal request: %v", err)
+		}
+		reader = bytes.NewReader(encoded)
+	} else {
+		reader = bytes.NewReader(nil)
+	}
+	req := withClaims(httptest.NewRequest(method, path, reader), username)
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestDuplicateFinder_ReportsPerUserVisibleGroupsAndTrashesKeepingOneCopy(t *testing.T) {
+	firstRoot := t.TempDir()
+	secondRoot := t.TempDir()
+	for root, files := range map[string]map[string]string{
+		firstRoot: {
+			"a.txt":      "same-content",
+			"dir/b.txt":  "same-content",
+			"c.txt":      "other-conten",
+			"empty1.txt": "",
+			"empty2.txt": "",
+		},
+		secondRoot: {
+			"d.txt":                   "same-content",
+			".cohesion_trash/old.txt": "same-content",
+		},
+	} {
+		for name, content := range files {
+			absPath := filepath.Join(root, filepath.FromSlash(name))
+			if err := os.MkdirAll(filepath.Dir(absPath), 0o755); err != nil {
+				t.Fatalf("failed to create directory: %v", err)
+			}
+			if err := os.WriteFile(absPath, []byte(content), 0o644); err != nil {
+				t.Fatalf("failed to seed %s: %v", name, err)
+			}
+		}
+	}
+
+	store := &fakeUploadSpaceStore{
+		spacesByID: map[int64]*space.Space{
+			1: {ID: 1, SpaceName: "First", SpacePath: firstRoot},
+			2: {ID: 2, SpaceName: "Second", SpacePath: secondRoot},
+		},
+	}
+	access := &fakeDuplicateAccessService{grants: map[string]map[int64]account.Permission{
+		"tester": {1: account.PermissionWrite, 2: account.PermissionWrite},
+		"viewer": {1: account.PermissionWrite},
+	}}
+	handler := NewHandler(space.NewService(store), nil, access, space.NewTrashService(newFakeTrashStore()))
+	manager := job.NewManager(job.NewMemoryStore())
+	t.Cleanup(manager.Stop)
+	handler.SetJobManager(manager)
+	mux := http.NewServeMux()
+	handler.RegisterRoutes(mux)
+
+	if rec := serveDuplicateRequest(t, mux, http.MethodPost, "/api/duplicates", "viewer", map[string]any{"spaceIds": []int64{1, 2}}); rec.Code != http.StatusForbidden {
+		t.Fatalf("expected 403 when scanning an unreadable space, got %d", rec.Code)
+	}
+
+	rec := serveDuplicateRequest(t, mux, http.MethodPost, "/api/duplicates", "tester", map[string]any{"spaceIds": []int64{1, 2}})
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("expected 202, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var created job.Job
+	if err := json.NewDecoder(rec.Body).Decode(&created); err != nil {
+		t.Fatalf("failed to decode job: %v", err)
+	}
+	finished := waitForFileTransferJob(t, handler, created.ID, job.StatusCompleted)
+	var result fileDuplicatesResult
+	if err := finished.DecodeResult(&result); err != nil {
+		t.Fatalf("failed to decode result: %v", err)
+	}
+	// 크기가 같은 c.txt도 해시까지 비교하지만 내용이 달라 그룹에 들지 않는다.
+	if result.Groups != 1 || result.DuplicateFiles != 2 || result.WastedBytes != 24 || result.HashedFiles != 4 {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+
+	decodeReport := func(rec *httptest.ResponseRecorder) duplicateReportResponse {
+		t.Helper()
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+		}
+		var report duplicateReportResponse
+		if err := json.NewDecoder(rec.Body).Decode(&report); err != nil {
+			t.Fatalf("failed to decode report: %v", err)
+		}
+		return report
+	}
+
+	report := decodeReport(serveDuplicateRequest(t, mux, http.MethodGet, "/api/duplicates/"+created.ID, "tester", nil))
+	if report.TotalGroups != 1 || len(report.Groups[0].Files) != 3 || report.WastedBytes != 24 {
+		t.Fatalf("unexpected owner report: %+v", report)
+	}
+
+	// viewer는 Space 2를 읽을 수 없으므로 Space 1의 두 파일만 보고 낭비 용량도 그만큼만 본다.
+	report = decodeReport(serveDuplicateRequest(t, mux, http.MethodGet, "/api/duplicates/"+created.ID, "viewer", nil))
+	if len(report.SpaceIDs) != 1 || report.TotalGroups != 1 || report.WastedBytes != 12 {
+		t.Fatalf("unexpected viewer report: %+v", report)
+	}
+	for _, file := range report.Groups[0].Files {
+		if file.SpaceID != 1 {
+			t.Fatalf("viewer should not see files in space %d", file.SpaceID)
+		}
+	}
+
+	if rec := serveDuplicateRequest(t, mux, http.MethodGet, "/api/duplicates/"+created.ID, "stranger", nil); rec.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for user without access, got %d", rec.Code)
+	}
+
+	var trashResponse struct {
+		Succeeded []duplicateTrashSucceeded `json:"succeeded"`
+		Failed    []duplicateTrashFailed    `json:"failed"`
+	}
+	rec = serveDuplicateRequest(t, mux, http.MethodPost, "/api/duplicates/"+created.ID+"/trash", "viewer", map[string]any{
+		"items": []map[string]any{{"spaceId": 1, "path": "a.txt"}, {"spaceId": 2, "path": "d.txt"}},
+	})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&trashResponse); err != nil {
+		t.Fatalf("failed to decode trash response: %v", err)
+	}
+	if len(trashResponse.Succeeded) != 1 || trashResponse.Succeeded[0].Path != "a.txt" || len(trashResponse.Failed) != 1 || trashResponse.Failed[0].SpaceID != 2 {
+		t.Fatalf("unexpected viewer trash response: %+v", trashResponse)
+	}
+	if _, err := os.Stat(filepath.Join(firstRoot, "a.txt")); !os.IsNotExist(err) {
+		t.Fatalf("expected a.txt to be moved to trash, got %v", err)
+	}
+
+	// a.txt가 이미 옮겨졌으므로 남은 두 사본을 모두 고르면 하나도 남지 않아 거절된다.
+	trashResponse.Succeeded, trashResponse.Failed = nil, nil
+	rec = serveDuplicateRequest(t, mux, http.MethodPost, "/api/duplicates/"+created.ID+"/trash", "tester", map[string]any{
+		"items": []map[string]any{{"spaceId": 1, "path": "dir/b.txt"}, {"spaceId": 2, "path": "d.txt"}},
+	})
+	if err := json.NewDecoder(rec.Body).Decode(&trashResponse); err != nil {
+		t.Fatalf("failed to decode trash response: %v", err)
+	}
+	if len(trashResponse.Succeeded) != 0 || len(trashResponse.Failed) != 2 || trashResponse.Failed[0].Reason != "At least one copy must be kept" {
+		t.Fatalf("expected every copy to be kept, got %+v", trashResponse)
+	}
+
+	// 분석 이후 내용이 바뀐 파일은 옮기지 않는다.
+	if err := os.WriteFile(filepath.Join(secondRoot, "d.txt"), []byte("edited-after"), 0o644); err != nil {
+		t.Fatalf("failed to modify file: %v", err)
+	}
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(filepath.Join(secondRoot, "d.txt"), later, later); err != nil {
+		t.Fatalf("failed to touch file: %v", err)
+	}
+	trashResponse.Succeeded, trashResponse.Failed = nil, nil
+	rec = serveDuplicateRequest(t, mux, http.MethodPost, "/api/duplicates/"+created.ID+"/trash", "tester", map[string]any{
+		"items": []map[string]any{{"spaceId": 2, "path": "d.txt"}},
+	})
+	if err := json.NewDecoder(rec.Body).Decode(&trashResponse); err != nil {
+		t.Fatalf("failed to decode trash response: %v", err)
+	}
+	if len(trashResponse.Failed) != 1 || trashResponse.Failed[0].Reason != "File changed since scan" {
+		t.Fatalf("expected changed file to be rejected, got %+v", trashResponse)
+	}
+}
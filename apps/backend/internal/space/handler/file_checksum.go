@@ -0,0 +1,247 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"taeu.kr/cohesion/internal/browse"
+	"taeu.kr/cohesion/internal/platform/web"
+	"taeu.kr/cohesion/internal/space/checksum"
+)
+
+const (
+	// checksumWait는 단일 파일 요청이 계산 완료를 기다리는 시간입니다. 넘으면 202로 응답합니다.
+	checksumWait = 2 * time.Second
+	// checksumRetryAfterSeconds는 202 응답의 Retry-After 값입니다.
+	checksumRetryAfterSeconds = 2
+	// maxChecksumBatchFiles는 폴더 단위 요청 한 번에 다루는 최대 파일 수입니다.
+	maxChecksumBatchFiles = 10000
+)
+
+var errTooManyChecksumFiles = errors.New("too many files for checksum batch")
+
+// SetChecksumService는 해시 캐시를 영속 저장소에 두는 Service로 교체합니다.
+func (h *Handler) SetChecksumService(service *checksum.Service) {
+	h.checksums = service
+}
+
+type checksumResponse struct {
+	Path      string             `json:"path"`
+	Algorithm checksum.Algorithm `json:"algorithm"`
+	// Status는 ready, pending, failed 중 하나입니다.
+	Status  string    `json:"status"`
+	Digest  string    `json:"digest,omitempty"`
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"modTime"`
+	Reason  string    `json:"reason,omitempty"`
+}
+
+type checksumBatchResponse struct {
+	Path      string             `json:"path"`
+	Algorithm checksum.Algorithm `json:"algorithm"`
+	Items     []checksumResponse `json:"items"`
+	Ready     int                `json:"ready"`
+	Pending   int                `json:"pending"`
+	Failed    int                `json:"failed"`
+}
+
+// handleFileChecksum: GET /api/spaces/{id}/files/checksum?path={relativePath}&algo={sha256|sha1|md5|blake3}
+// 캐시된 해시가 있으면 바로, 없으면 백그라운드 계산을 잠시 기다렸다가 응답합니다.
+// 기다리는 동안 끝나지 않으면 202와 Retry-After를 반환하며 같은 요청을 다시 보내면 됩니다.
+func (h *Handler) handleFileChecksum(w http.ResponseWriter, r *http.Request, spaceID int64) *web.Error {
+	if r.Method != http.MethodGet {
+		return &web.Error{Code: http.StatusMethodNotAllowed, Message: "Method not allowed"}
+	}
+	algo, relativePath, absPath, webErr := h.resolveChecksumRequest(r, spaceID)
+	if webErr != nil {
+		return webErr
+	}
+
+	info, err := os.Stat(absPath)
+	if err != nil {
+		return storageAccessWebError(err, "File not found", "Failed to access file")
+	}
+	if !info.Mode().IsRegular() {
+		return &web.Error{Code: http.StatusBadRequest, Message: "Path is not a regular file"}
+	}
+
+	entry, err := h.checksums.Sum(r.Context(), absPath, algo, checksumWait)
+	if errors.Is(err, checksum.ErrPending) {
+		w.Header().Set("Retry-After", strconv.Itoa(checksumRetryAfterSeconds))
+		return writeJSON(w, http.StatusAccepted, checksumResponse{
+			Path:      relativePath,
+			Algorithm: algo,
+			Status:    "pending",
+			Size:      info.Size(),
+			ModTime:   info.ModTime(),
+		})
+	}
+	if err != nil {
+		return checksumWebError(err)
+	}
+	return writeJSON(w, http.StatusOK, readyChecksumResponse(relativePath, entry))
+}
+
+// handleFileChecksums: GET /api/spaces/{id}/files/checksums?path={folder}&algo=...&recursive={true|false}
+// 폴더 안 파일마다 캐시된 해시를 반환하고, 없는 파일은 백그라운드 계산을 시작해 pending으로 표시합니다.
+// 모든 항목이 ready가 될 때까지 같은 요청을 반복하면 됩니다.
+func (h *Handler) handleFileChecksums(w http.ResponseWriter, r *http.Request, spaceID int64) *web.Error {
+	if r.Method != http.MethodGet {
+		return &web.Error{Code: http.StatusMethodNotAllowed, Message: "Method not allowed"}
+	}
+	algo, relativePath, absPath, webErr := h.resolveChecksumRequest(r, spaceID)
+	if webErr != nil {
+		return webErr
+	}
+	recursive := r.URL.Query().Get("recursive") == "true"
+
+	info, err := os.Stat(absPath)
+	if err != nil {
+		return storageAccessWebError(err, "Folder not found", "Failed to access folder")
+	}
+	if !info.IsDir() {
+		return &web.Error{Code: http.StatusBadRequest, Message: "Path is not a directory"}
+	}
+
+	files, err := collectChecksumTargets(r.Context(), absPath, recursive)
+	if errors.Is(err, errTooManyChecksumFiles) {
+		return &web.Error{Code: http.StatusUnprocessableEntity, Message: "Folder has too many files for a checksum request", Err: err}
+	}
+	if err != nil {
+		return storageAccessWebError(err, "Folder not found", "Failed to list folder")
+	}
+
+	response := checksumBatchResponse{
+		Path:      relativePath,
+		Algorithm: algo,
+		Items:     make([]checksumResponse, 0, len(files)),
+	}
+	for _, file := range files {
+		itemPath := filepath.ToSlash(filepath.Join(relativePath, file.relPath))
+		entry, err := h.checksums.Request(r.Context(), file.absPath, algo)
+		switch {
+		case err == nil:
+			response.Items = append(response.Items, readyChecksumResponse(itemPath, entry))
+			response.Ready++
+		case errors.Is(err, checksum.ErrPending):
+			response.Items = append(response.Items, checksumResponse{Path: itemPath, Algorithm: algo, Status: "pending", Size: file.info.Size(), ModTime: file.info.ModTime()})
+			response.Pending++
+		default:
+			response.Items = append(response.Items, checksumResponse{Path: itemPath, Algorithm: algo, Status: "failed", Size: file.info.Size(), ModTime: file.info.ModTime(), Reason: checksumFailureReason(err)})
+			response.Failed++
+		}
+	}
+
+	if response.Pending > 0 {
+		w.Header().Set("Retry-After", strconv.Itoa(checksumRetryAfterSeconds))
+	}
+	return writeJSON(w, http.StatusOK, response)
+}
+
+func (h *Handler) resolveChecksumRequest(r *http.Request, spaceID int64) (checksum.Algorithm, string, string, *web.Error) {
+	algo, err := checksum.ParseAlgorithm(r.URL.Query().Get("algo"))
+	if err != nil {
+		return "", "", "", &web.Error{Code: http.StatusBadRequest, Message: "Unsupported checksum algorithm", Err: err}
+	}
+	spaceData, webErr := h.getSpace(r, spaceID)
+	if webErr != nil {
+		return "", "", "", webErr
+	}
+
+	relativePath := r.URL.Query().Get("path")
+	if err := ensurePathOutsideTrash(relativePath); err != nil {
+		return "", "", "", &web.Error{Code: http.StatusForbidden, Message: "Access denied: invalid path", Err: err}
+	}
+	absPath, err := resolveAbsPath(spaceData.SpacePath, relativePath)
+	if err != nil {
+		return "", "", "", &web.Error{Code: http.StatusForbidden, Message: "Access denied: invalid path"}
+	}
+	return algo, relativePath, absPath, nil
+}
+
+type checksumTarget struct {
+	relPath string
+	absPath string
+	info    fs.FileInfo
+}
+
+// collectChecksumTargets는 폴더 안 일반 파일을 모읍니다. 심볼릭 링크와 휴지통은 건너뜁니다.
+func collectChecksumTargets(ctx context.Context, root string, recursive bool) ([]checksumTarget, error) {
+	targets := make([]checksumTarget, 0)
+	err := filepath.WalkDir(root, func(current string, entry fs.DirEntry, walkErr error) error {
+		if walkErr != nil {
+			return walkErr
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if current == root {
+			return nil
+		}
+		if entry.IsDir() {
+			if !recursive || entry.Name() == spaceTrashDirectoryName {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+		if len(targets) >= maxChecksumBatchFiles {
+			return errTooManyChecksumFiles
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		relPath, err := filepath.Rel(root, current)
+		if err != nil {
+			return err
+		}
+		targets = append(targets, checksumTarget{relPath: relPath, absPath: current, info: info})
+		return nil
+	})
+	return targets, err
+}
+
+func readyChecksumResponse(relativePath string, entry *checksum.Entry) checksumResponse {
+	return checksumResponse{
+		Path:      relativePath,
+		Algorithm: entry.Algorithm,
+		Status:    "ready",
+		Digest:    entry.Digest,
+		Size:      entry.Size,
+		ModTime:   entry.ModTime,
+	}
+}
+
+func checksumWebError(err error) *web.Error {
+	switch {
+	case errors.Is(err, checksum.ErrFileChanged):
+		return &web.Error{Code: http.StatusConflict, Message: "File changed while computing checksum", Err: err}
+	case errors.Is(err, checksum.ErrNotRegularFile):
+		return &web.Error{Code: http.StatusBadRequest, Message: "Path is not a regular file", Err: err}
+	default:
+		return storageAccessWebError(err, "File not found", "Failed to compute checksum")
+	}
+}
+
+// checksumFailureReason은 응답에 파일 시스템 경로가 드러나지 않도록 실패 사유를 코드로 바꿉니다.
+func checksumFailureReason(err error) string {
+	switch {
+	case errors.Is(err, checksum.ErrFileChanged):
+		return "file_changed"
+	case os.IsNotExist(err):
+		return "not_found"
+	case browse.IsPermissionError(err):
+		return "permission_denied"
+	default:
+		return "read_failed"
+	}
+}
@@ -0,0 +1,115 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"taeu.kr/cohesion/internal/space"
+)
+
+func newChecksumTestHandler(t *testing.T) (*Handler, string) {
+	t.Helper()
+
+	spaceRoot := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(spaceRoot, "docs", "nested"), 0o755); err != nil {
+		t.Fatalf("failed to create folders: %v", err)
+	}
+	files := map[string]string{
+		"docs/hello.txt":    "hello",
+		"docs/nested/a.txt": "a",
+		"docs/b.md":         "b",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(spaceRoot, filepath.FromSlash(name)), []byte(content), 0o644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+	store := &fakeTransferSpaceStore{
+		spacesByID: map[int64]*space.Space{
+			1: {ID: 1, SpaceName: "Docs", SpacePath: spaceRoot},
+		},
+	}
+	return NewHandler(space.NewService(store), nil, nil), spaceRoot
+}
+
+func TestHandleFileChecksum_ReturnsDigestForRequestedAlgorithm(t *testing.T) {
+	handler, _ := newChecksumTestHandler(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/spaces/1/files/checksum?path=docs/hello.txt&algo=md5", nil)
+	rec := httptest.NewRecorder()
+	if webErr := handler.handleFileChecksum(rec, req, 1); webErr != nil {
+		t.Fatalf("unexpected checksum error: %+v", webErr)
+	}
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	var response checksumResponse
+	if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if response.Status != "ready" || response.Algorithm != "md5" || response.Digest != "5d41402abc4b2a76b9719d911017c592" || response.Size != 5 {
+		t.Fatalf("unexpected checksum response: %+v", response)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/spaces/1/files/checksum?path=docs/hello.txt&algo=crc32", nil)
+	if webErr := handler.handleFileChecksum(httptest.NewRecorder(), req, 1); webErr == nil || webErr.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for unsupported algorithm, got %+v", webErr)
+	}
+	req = httptest.NewRequest(http.MethodGet, "/api/spaces/1/files/checksum?path=docs", nil)
+	if webErr := handler.handleFileChecksum(httptest.NewRecorder(), req, 1); webErr == nil || webErr.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for folder, got %+v", webErr)
+	}
+}
+
+func TestHandleFileChecksums_ReportsPendingUntilFolderIsHashed(t *testing.T) {
+	handler, _ := newChecksumTestHandler(t)
+
+	fetch := func() checksumBatchResponse {
+		req := httptest.NewRequest(http.MethodGet, "/api/spaces/1/files/checksums?path=docs&recursive=true", nil)
+		rec := httptest.NewRecorder()
+		if webErr := handler.handleFileChecksums(rec, req, 1); webErr != nil {
+			t.Fatalf("unexpected checksums error: %+v", webErr)
+		}
+		var response checksumBatchResponse
+		if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		return response
+	}
+
+	response := fetch()
+	if len(response.Items) != 3 || response.Failed != 0 {
+		t.Fatalf("expected three files without failures, got %+v", response)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for response.Ready != 3 {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected all checksums to become ready, got %+v", response)
+		}
+		time.Sleep(10 * time.Millisecond)
+		response = fetch()
+	}
+	for _, item := range response.Items {
+		if item.Path == "docs/hello.txt" && item.Digest != "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824" {
+			t.Fatalf("unexpected digest for %s: %s", item.Path, item.Digest)
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/spaces/1/files/checksums?path=docs", nil)
+	rec := httptest.NewRecorder()
+	if webErr := handler.handleFileChecksums(rec, req, 1); webErr != nil {
+		t.Fatalf("unexpected checksums error: %+v", webErr)
+	}
+	var shallow checksumBatchResponse
+	if err := json.NewDecoder(rec.Body).Decode(&shallow); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(shallow.Items) != 2 {
+		t.Fatalf("expected only direct children without recursive, got %+v", shallow.Items)
+	}
+}
@@ -0,0 +1,386 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	"taeu.kr/cohesion/internal/audit"
+	"taeu.kr/cohesion/internal/auth"
+	"taeu.kr/cohesion/internal/job"
+	"taeu.kr/cohesion/internal/platform/logging"
+	"taeu.kr/cohesion/internal/platform/web"
+	"taeu.kr/cohesion/internal/space"
+)
+
+const (
+	// FileCompressJobType은 선택한 항목을 Space 안의 압축 파일로 만드는 작업 유형입니다.
+	FileCompressJobType = "file.compress"
+
+	defaultFileCompressWorkerLimit = 1
+
+	// compressEntryOverheadBytes는 할당량 예약 시 항목마다 더하는 헤더 여유분입니다.
+	compressEntryOverheadBytes = 1024
+)
+
+// fileCompressPayload는 작업 큐에 저장되는 압축 생성 입력입니다.
+type fileCompressPayload struct {
+	Paths           []string      `json:"paths"`
+	DestinationPath string        `json:"destinationPath"`
+	Format          archiveFormat `json:"format"`
+	ConflictPolicy  string        `json:"conflictPolicy,omitempty"`
+}
+
+// fileCompressResult는 압축 생성 작업 출력입니다. Path는 충돌 처리 후 실제로 만든 파일 경로입니다.
+type fileCompressResult struct {
+	Path    string `json:"path,omitempty"`
+	Size    int64  `json:"size"`
+	Entries int    `json:"entries"`
+	Skipped bool   `json:"skipped,omitempty"`
+}
+
+func (h *Handler) registerFileCompressJobType() {
+	h.jobs.Register(FileCompressJobType, job.TypeConfig{
+		Concurrency: defaultFileCompressWorkerLimit,
+		Run:         h.runFileCompressJob,
+		OnFinish:    h.finishFileCompressJob,
+	})
+}
+
+// handleFileCompress: POST /api/spaces/{id}/files/compress
+// body: { paths: []string, destination: string, conflictPolicy?: string }
+func (h *Handler) handleFileCompress(w http.ResponseWriter, r *http.Request, spaceID int64) *web.Error {
+	if r.Method != http.MethodPost {
+		return &web.Error{Code: http.StatusMethodNotAllowed, Message: "Method not allowed"}
+	}
+
+	spaceData, webErr := h.getSpace(r, spaceID)
+	if webErr != nil {
+		return webErr
+	}
+	claims, ok := auth.ClaimsFromContext(r.Context())
+	if !ok {
+		return &web.Error{Code: http.StatusUnauthorized, Message: "Unauthorized"}
+	}
+
+	var req struct {
+		Paths          []string `json:"paths"`
+		Destination    string   `json:"destination"`
+		ConflictPolicy string   `json:"conflictPolicy,omitempty"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return &web.Error{Code: http.StatusBadRequest, Message: "Invalid request body", Err: err}
+	}
+	if strings.TrimSpace(req.Destination) == "" {
+		return &web.Error{Code: http.StatusBadRequest, Message: "destination is required"}
+	}
+	format, ok := detectArchiveFormat(req.Destination)
+	if !ok {
+		return &web.Error{Code: http.StatusBadRequest, Message: "Unsupported archive format"}
+	}
+	_, hasConflictPolicy, err := resolveUploadConflictPolicy(req.ConflictPolicy, false)
+	if err != nil {
+		return &web.Error{Code: http.StatusBadRequest, Message: "Invalid conflict policy", Err: err}
+	}
+
+	sources, webErr := resolveArchiveSources(spaceData.SpacePath, req.Paths)
+	if webErr != nil {
+		return webErr
+	}
+
+	if err := ensurePathOutsideTrash(req.Destination); err != nil {
+		return &web.Error{Code: http.StatusForbidden, Message: "Access denied: invalid destination path", Err: err}
+	}
+	absDestination, err := resolveAbsPath(spaceData.SpacePath, req.Destination)
+	if err != nil || filepath.Clean(absDestination) == filepath.Clean(spaceData.SpacePath) {
+		return &web.Error{Code: http.StatusForbidden, Message: "Access denied: invalid destination path"}
+	}
+	parentInfo, err := os.Stat(filepath.Dir(absDestination))
+	if err != nil {
+		return storageAccessWebError(err, "Destination directory not found", "Failed to access destination directory")
+	}
+	if !parentInfo.IsDir() {
+		return &web.Error{Code: http.StatusBadRequest, Message: "Destination must be a directory"}
+	}
+	if _, err := os.Stat(absDestination); err == nil && !hasConflictPolicy {
+		return &web.Error{Code: http.StatusConflict, Message: "File already exists"}
+	}
+
+	var sourceBytes int64
+	for _, source := range sources {
+		size, err := h.quotaService.CalculatePathSize(r.Context(), source.AbsPath)
+		if err != nil {
+			return storageAccessWebError(err, "Path not found", "Failed to evaluate source size")
+		}
+		sourceBytes += size
+	}
+	if webErr := h.ensureSpaceQuotaForWrite(r.Context(), spaceID, sourceBytes); webErr != nil {
+		return webErr
+	}
+	if _, err := os.Stat(absDestination); os.IsNotExist(err) {
+		if webErr := h.ensureSpaceFileQuota(r.Context(), spaceID, 1); webErr != nil {
+			return webErr
+		}
+	}
+	if webErr := h.ensureUserQuotaForWrite(r.Context(), claims.Username, spaceID, sourceBytes); webErr != nil {
+		return webErr
+	}
+	if webErr := h.checkUploadName(r.Context(), spaceID, filepath.Base(absDestination)); webErr != nil {
+		return webErr
+	}
+
+	compressJob, err := h.jobs.Enqueue(r.Context(), job.EnqueueRequest{
+		Type:      FileCompressJobType,
+		Owner:     claims.Username,
+		SpaceID:   &spaceID,
+		RequestID: strings.TrimSpace(r.Header.Get("X-Request-Id")),
+		Payload: fileCompressPayload{
+			Paths:           req.Paths,
+			DestinationPath: req.Destination,
+			Format:          format,
+			ConflictPolicy:  req.ConflictPolicy,
+		},
+	})
+	if err != nil {
+		return &web.Error{Code: http.StatusInternalServerError, Message: "Failed to create compress job", Err: err}
+	}
+	h.logFileCompressJobEvent("info.compress.job_created", compressJob, nil)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	if err := json.NewEncoder(w).Encode(compressJob); err != nil {
+		return &web.Error{Code: http.StatusInternalServerError, Message: "Failed to encode compress job response", Err: err}
+	}
+	return nil
+}
+
+// runFileCompressJob은 원본을 훑어 출력 크기만큼 할당량을 예약한 뒤 임시 파일에 압축하고,
+// 끝나면 충돌 정책에 따라 최종 경로로 옮깁니다. 취소/실패 시 임시 파일과 예약을 정리합니다.
+func (h *Handler) runFileCompressJob(ctx context.Context, run *job.Run) error {
+	current := run.Job()
+	if current == nil || current.SpaceID == nil {
+		return job.Permanent(errors.New("compress job has no space"))
+	}
+	spaceID := *current.SpaceID
+
+	var payload fileCompressPayload
+	if err := current.DecodePayload(&payload); err != nil {
+		return job.Permanent(fmt.Errorf("invalid compress job payload: %w", err))
+	}
+	conflictPolicy, hasConflictPolicy, err := resolveUploadConflictPolicy(payload.ConflictPolicy, false)
+	if err != nil {
+		return job.Permanent(errors.New("Invalid conflict policy"))
+	}
+
+	if _, err := h.spaceService.GetSpaceByID(ctx, spaceID); err != nil {
+		return job.Permanent(errors.New("Space not found"))
+	}
+	spaceData, release, err := h.spaceService.BeginWrite(ctx, spaceID)
+	if err != nil {
+		return err
+	}
+	defer release()
+	sources, webErr := resolveArchiveSources(spaceData.SpacePath, payload.Paths)
+	if webErr != nil {
+		return job.Permanent(errors.New(webErr.Message))
+	}
+	absDestination, err := resolveAbsPath(spaceData.SpacePath, payload.DestinationPath)
+	if err != nil {
+		return job.Permanent(errors.New("Access denied: invalid destination path"))
+	}
+
+	entries, totalItems, totalBytes, err := scanArchiveZipEntries(sources)
+	if err != nil {
+		return errors.New(safeFilesystemReason("Failed to scan archive sources", err))
+	}
+	run.SetTotals(totalItems, totalBytes)
+
+	if h.quotaService != nil {
+		reservationID := "compress-" + current.ID
+		reserveBytes := totalBytes + int64(len(entries))*compressEntryOverheadBytes
+		if _, err := h.quotaService.AcquireWriteReservation(ctx, spaceID, reservationID, reserveBytes); err != nil {
+			var quotaErr *space.QuotaExceededError
+			if errors.As(err, &quotaErr) {
+				return job.Permanent(errors.New(quotaFailureReason(err)))
+			}
+			return err
+		}
+		defer h.quotaService.ReleaseWriteReservation(reservationID)
+	}
+
+	stagedPath, err := resolveUniqueSiblingPath(absDestination, "compress")
+	if err != nil {
+		return errors.New(safeFilesystemReason("Failed to allocate staging path", err))
+	}
+	finalized := false
+	defer func() {
+		if !finalized {
+			os.Remove(stagedPath) //nolint:errcheck
+		}
+	}()
+
+	if err := writeCompressedArchive(ctx, stagedPath, payload.Format, entries, func(items int, bytes int64) {
+		run.Advance(items, bytes)
+	}); err != nil {
+		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+			return err
+		}
+		return errors.New(safeFilesystemReason("Failed to create archive", err))
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	// 형식과 크기는 만들어진 압축 파일로 확인합니다.
+	policy, err := h.uploadPolicyFor(ctx, spaceID)
+	if err != nil {
+		return err
+	}
+	if err := policy.CheckFile(filepath.Base(absDestination), stagedPath); err != nil {
+		reason, _ := uploadPolicyFailure(err)
+		return job.Permanent(errors.New(reason))
+	}
+
+	result := fileCompressResult{Entries: len(entries)}
+	targetPath := absDestination
+	if destInfo, statErr := os.Stat(targetPath); statErr == nil {
+		if !hasConflictPolicy {
+			return job.Permanent(errors.New("File already exists"))
+		}
+		switch conflictPolicy {
+		case uploadConflictPolicyOverwrite:
+			if destInfo.IsDir() {
+				return job.Permanent(errors.New("Cannot overwrite destination with different type"))
+			}
+			if err := h.checkRetentionAs(ctx, current.Owner, spaceID, spaceData.SpacePath, targetPath, "overwrite"); err != nil {
+				reason, _ := retentionFailure(err)
+				return job.Permanent(errors.New(reason))
+			}
+		case uploadConflictPolicyRename:
+			renamedPath, _, renameErr := resolveUploadRenamePath(targetPath)
+			if renameErr != nil {
+				return errors.New(safeFilesystemReason("Failed to resolve rename destination", renameErr))
+			}
+			targetPath = renamedPath
+		case uploadConflictPolicySkip:
+			result.Skipped = true
+			return run.SetResult(result)
+		}
+	} else if !os.IsNotExist(statErr) {
+		return errors.New(safeFilesystemReason("Failed to access destination", statErr))
+	}
+
+	commitUsage := h.trackQuotaUsage(ctx, spaceID, targetPath)
+	if err := os.Rename(stagedPath, targetPath); err != nil {
+		return errors.New(safeFilesystemReason("Failed to finalize archive", err))
+	}
+	finalized = true
+	commitUsage()
+	h.recordFileOwner(ctx, current.Owner, spaceID, spaceData.SpacePath, targetPath)
+
+	if info, err := os.Stat(targetPath); err == nil {
+		result.Size = info.Size()
+	}
+	if relPath, err := filepath.Rel(spaceData.SpacePath, targetPath); err == nil {
+		result.Path = filepath.ToSlash(relPath)
+	}
+	return run.SetResult(result)
+}
+
+// writeCompressedArchive는 항목들을 format 형식의 압축 파일로 씁니다.
+func writeCompressedArchive(ctx context.Context, outputPath string, format archiveFormat, entries []archiveZipEntry, progress transferProgressFunc) error {
+	file, err := os.OpenFile(outputPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if err := writeArchiveEntries(ctx, file, format, entries, progress); err != nil {
+		return err
+	}
+	return file.Close()
+}
+
+// finishFileCompressJob은 검색 색인을 갱신하고 감사 기록을 남깁니다.
+func (h *Handler) finishFileCompressJob(compressJob *job.Job) {
+	var payload fileCompressPayload
+	_ = compressJob.DecodePayload(&payload)
+	var result fileCompressResult
+	_ = compressJob.DecodeResult(&result)
+
+	var spaceID int64
+	if compressJob.SpaceID != nil {
+		spaceID = *compressJob.SpaceID
+	}
+	created := compressJob.Status == job.StatusCompleted && !result.Skipped
+	if created {
+		h.markSearchIndexDirty(context.Background(), spaceID, "compress")
+	}
+
+	auditResult := audit.ResultSuccess
+	switch {
+	case compressJob.Status != job.StatusCompleted:
+		auditResult = audit.ResultFailure
+	case result.Skipped:
+		auditResult = audit.ResultPartial
+	}
+	target := result.Path
+	if target == "" {
+		target = payload.DestinationPath
+	}
+	metadata := map[string]any{
+		"jobId":       compressJob.ID,
+		"destination": target,
+		"format":      string(payload.Format),
+		"sourceCount": len(payload.Paths),
+		"entries":     result.Entries,
+		"size":        result.Size,
+		"status":      string(compressJob.Status),
+	}
+	if compressJob.FailureReason != "" {
+		metadata["reason"] = compressJob.FailureReason
+	}
+
+	var runErr error
+	if compressJob.Status == job.StatusFailed {
+		runErr = errors.New(compressJob.FailureReason)
+	}
+	h.logFileCompressJobEvent("info.compress.job_finished", compressJob, runErr)
+	h.recordSpaceAuditBackground(compressJob.Owner, compressJob.RequestID, audit.Event{
+		Action:   "file.compress",
+		Result:   auditResult,
+		Target:   target,
+		SpaceID:  compressJob.SpaceID,
+		Metadata: metadata,
+	})
+}
+
+func (h *Handler) logFileCompressJobEvent(eventName string, compressJob *job.Job, err error) {
+	var payload fileCompressPayload
+	_ = compressJob.DecodePayload(&payload)
+	var spaceID int64
+	if compressJob.SpaceID != nil {
+		spaceID = *compressJob.SpaceID
+	}
+
+	logger := logging.Event(log.Info(), logging.ComponentStorage, eventName).
+		Str("job_id", compressJob.ID).
+		Int64("space_id", spaceID).
+		Str("owner", compressJob.Owner).
+		Str("status", string(compressJob.Status)).
+		Str("format", string(payload.Format)).
+		Int("source_count", len(payload.Paths)).
+		Int("total_items", compressJob.Progress.TotalItems).
+		Int("processed_items", compressJob.Progress.ProcessedItems).
+		Int64("total_bytes", compressJob.Progress.TotalBytes).
+		Int64("processed_bytes", compressJob.Progress.ProcessedBytes)
+	if err != nil {
+		logger = logger.Err(err)
+	}
+	logger.Msg("compress job updated")
+}
@@ -0,0 +1,214 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"taeu.kr/cohesion/internal/audit"
+	"taeu.kr/cohesion/internal/job"
+	"taeu.kr/cohesion/internal/space"
+)
+
+func seedCompressSources(t *testing.T, root string) {
+	t.Helper()
+
+	if err := os.MkdirAll(filepath.Join(root, "docs", "nested"), 0o755); err != nil {
+		t.Fatalf("failed to create source tree: %v", err)
+	}
+	for name, content := range map[string]string{
+		"docs/readme.txt":     "readme",
+		"docs/nested/보고서.txt": "report",
+		"notes.txt":           "notes",
+	} {
+		if err := os.WriteFile(filepath.Join(root, filepath.FromSlash(name)), []byte(content), 0o644); err != nil {
+			t.Fatalf("failed to seed %s: %v", name, err)
+		}
+	}
+}
+
+func postFileCompress(t *testing.T, handler *Handler, body map[string]any) (*httptest.ResponseRecorder, *job.Job) {
+	t.Helper()
+
+	encoded, err := json.Marshal(body)
+	if err != nil {
+		t.Fatalf("failed to marshal request: %v", err)
+	}
+	req := httptest.NewRequest(http.MethodPost, "/api/spaces/1/files/compress", bytes.NewReader(encoded))
+	req = withClaims(req, "tester")
+	rec := httptest.NewRecorder()
+	if webErr := handler.handleFileCompress(rec, req, 1); webErr != nil {
+		t.Fatalf("unexpected web error: %+v", webErr)
+	}
+	var created job.Job
+	if err := json.NewDecoder(rec.Body).Decode(&created); err != nil {
+		t.Fatalf("failed to decode job: %v", err)
+	}
+	return rec, &created
+}
+
+func readArchiveContents(t *testing.T, absPath string, format archiveFormat) map[string]string {
+	t.Helper()
+
+	contents := map[string]string{}
+	err := walkArchive(context.Background(), absPath, format, func(member archiveMember, open archiveMemberOpenFunc) error {
+		if member.IsDir {
+			contents[member.Name+"/"] = ""
+			return nil
+		}
+		reader, err := open()
+		if err != nil {
+			return err
+		}
+		defer reader.Close()
+		data, err := io.ReadAll(reader)
+		if err != nil {
+			return err
+		}
+		contents[member.Name] = string(data)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("failed to read archive: %v", err)
+	}
+	return contents
+}
+
+func TestFileCompressJob_CreatesArchiveInSpace(t *testing.T) {
+	for _, ext := range []string{"zip", "tar.gz", "tar.zst"} {
+		t.Run(ext, func(t *testing.T) {
+			handler, root, _, _, sink := newFileTransferJobTestHandler(t)
+			seedCompressSources(t, root)
+
+			rec, created := postFileCompress(t, handler, map[string]any{
+				"paths":       []string{"docs", "notes.txt"},
+				"destination": "Backup-2026-10." + ext,
+			})
+			if rec.Code != http.StatusAccepted {
+				t.Fatalf("expected 202, got %d", rec.Code)
+			}
+
+			finished := waitForFileTransferJob(t, handler, created.ID, job.StatusCompleted)
+			if finished.Progress.ProcessedItems != finished.Progress.TotalItems || finished.Progress.TotalBytes != int64(len("readme")+len("report")+len("notes")) {
+				t.Fatalf("unexpected progress: %+v", finished.Progress)
+			}
+			var result fileCompressResult
+			if err := finished.DecodeResult(&result); err != nil {
+				t.Fatalf("failed to decode result: %v", err)
+			}
+			if result.Path != "Backup-2026-10."+ext || result.Size == 0 {
+				t.Fatalf("unexpected result: %+v", result)
+			}
+
+			format, _ := detectArchiveFormat(result.Path)
+			contents := readArchiveContents(t, filepath.Join(root, result.Path), format)
+			names := make([]string, 0, len(contents))
+			for name := range contents {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			expected := []string{"docs/nested/", "docs/nested/보고서.txt", "docs/readme.txt", "notes.txt"}
+			if strings.Join(names, ",") != strings.Join(expected, ",") {
+				t.Fatalf("unexpected archive entries: %v", names)
+			}
+			if contents["docs/nested/보고서.txt"] != "report" {
+				t.Fatalf("unexpected entry content: %q", contents["docs/nested/보고서.txt"])
+			}
+
+			event := sink.waitForAction(t, "file.compress")
+			if event.Result != audit.ResultSuccess || event.Metadata["jobId"] != created.ID {
+				t.Fatalf("unexpected compress audit: %+v", event)
+			}
+			entries, _ := os.ReadDir(root)
+			for _, entry := range entries {
+				if strings.Contains(entry.Name(), "cohesion-compress") {
+					t.Fatalf("staging file must not remain: %s", entry.Name())
+				}
+			}
+		})
+	}
+}
+
+func TestFileCompressJob_ConflictPolicies(t *testing.T) {
+	handler, root, _, _, _ := newFileTransferJobTestHandler(t)
+	seedCompressSources(t, root)
+	if err := os.WriteFile(filepath.Join(root, "Backup.zip"), []byte("existing"), 0o644); err != nil {
+		t.Fatalf("failed to seed existing archive: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/spaces/1/files/compress", strings.NewReader(`{"paths":["notes.txt"],"destination":"Backup.zip"}`))
+	req = withClaims(req, "tester")
+	if webErr := handler.handleFileCompress(httptest.NewRecorder(), req, 1); webErr == nil || webErr.Code != http.StatusConflict {
+		t.Fatalf("expected 409 without conflict policy, got %+v", webErr)
+	}
+
+	_, created := postFileCompress(t, handler, map[string]any{
+		"paths":          []string{"notes.txt"},
+		"destination":    "Backup.zip",
+		"conflictPolicy": "rename",
+	})
+	finished := waitForFileTransferJob(t, handler, created.ID, job.StatusCompleted)
+	var result fileCompressResult
+	if err := finished.DecodeResult(&result); err != nil {
+		t.Fatalf("failed to decode result: %v", err)
+	}
+	if result.Path != "Backup (1).zip" {
+		t.Fatalf("expected renamed archive, got %+v", result)
+	}
+	if content, _ := os.ReadFile(filepath.Join(root, "Backup.zip")); string(content) != "existing" {
+		t.Fatalf("existing archive must be kept, got %q", content)
+	}
+}
+
+func TestHandleFileCompress_RejectsWhenQuotaExceeded(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "big.bin"), bytes.Repeat([]byte("x"), 4096), 0o644); err != nil {
+		t.Fatalf("failed to seed source: %v", err)
+	}
+	quota := int64(6000)
+	store := &fakeQuotaSpaceStore{
+		spacesByID: map[int64]*space.Space{
+			1: {ID: 1, SpaceName: "Quota", SpacePath: root, QuotaBytes: &quota},
+		},
+	}
+	handler := NewHandler(space.NewService(store), nil, nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/spaces/1/files/compress", strings.NewReader(`{"paths":["big.bin"],"destination":"big.tar.gz"}`))
+	req = withClaims(req, "tester")
+	webErr := handler.handleFileCompress(httptest.NewRecorder(), req, 1)
+	if webErr == nil || webErr.Code != http.StatusInsufficientStorage {
+		t.Fatalf("expected 507 when output may exceed quota, got %+v", webErr)
+	}
+	if reserved := handler.quotaService.ReservedBytes(1); reserved != 0 {
+		t.Fatalf("expected no quota reservation to remain, got %d", reserved)
+	}
+}
+
+func TestWriteCompressedArchive_StopsOnCancel(t *testing.T) {
+	root := t.TempDir()
+	seedCompressSources(t, root)
+	sources, webErr := resolveArchiveSources(root, []string{"docs"})
+	if webErr != nil {
+		t.Fatalf("failed to resolve sources: %+v", webErr)
+	}
+	entries, _, _, err := scanArchiveZipEntries(sources)
+	if err != nil {
+		t.Fatalf("failed to scan sources: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = writeCompressedArchive(ctx, filepath.Join(root, "out.tar.zst"), archiveFormatTarZstd, entries, func(int, int64) {})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected cancellation error, got %v", err)
+	}
+}
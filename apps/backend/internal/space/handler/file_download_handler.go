@@ -13,15 +13,21 @@ import (
 
 	"taeu.kr/cohesion/internal/audit"
 	"taeu.kr/cohesion/internal/auth"
+	"taeu.kr/cohesion/internal/download"
 	"taeu.kr/cohesion/internal/platform/web"
 )
 
-// handleFileDownload: GET /api/spaces/{id}/files/download?path={relativePath}
+// handleFileDownload: GET /api/spaces/{id}/files/download?path={relativePath}&format={zip|tar|tar.gz}
+// format은 폴더를 내려받을 때만 쓰이며 기본값은 zip입니다.
 func (h *Handler) handleFileDownload(w http.ResponseWriter, r *http.Request, spaceID int64) *web.Error {
 	spaceData, webErr := h.getSpace(r, spaceID)
 	if webErr != nil {
 		return webErr
 	}
+	format, webErr := parseDownloadArchiveFormat(r.URL.Query().Get("format"))
+	if webErr != nil {
+		return webErr
+	}
 
 	relativePath := r.URL.Query().Get("path")
 	if err := ensurePathOutsideTrash(relativePath); err != nil {
@@ -34,11 +40,18 @@ func (h *Handler) handleFileDownload(w http.ResponseWriter, r *http.Request, spa
 
 	fileInfo, err := os.Stat(absPath)
 	if err != nil {
+		archiveTarget, webErr := h.lookupArchiveEntry(r.Context(), spaceData.SpacePath, relativePath, false)
+		if webErr != nil {
+			return webErr
+		}
+		if archiveTarget != nil {
+			return h.downloadArchiveEntry(w, r, spaceID, relativePath, archiveTarget)
+		}
 		return storageAccessWebError(err, "File not found", "Failed to access file")
 	}
 
 	if fileInfo.IsDir() {
-		downloadErr := h.downloadFolderAsZip(w, absPath, fileInfo.Name())
+		downloadErr := h.downloadFolderArchive(w, r, absPath, fileInfo.Name(), format)
 		if downloadErr != nil {
 			h.recordSpaceAudit(r, audit.Event{
 				Action: "file.download",
@@ -46,8 +59,8 @@ func (h *Handler) handleFileDownload(w http.ResponseWriter, r *http.Request, spa
 				Target: relativePath,
 				Metadata: map[string]any{
 					"path":        relativePath,
-					"filename":    fileInfo.Name() + ".zip",
-					"format":      "zip",
+					"filename":    archiveFileName(fileInfo.Name(), format),
+					"format":      string(format),
 					"sourceCount": 1,
 					"status":      "failed",
 					"reason":      "stream_failed",
@@ -60,8 +73,8 @@ func (h *Handler) handleFileDownload(w http.ResponseWriter, r *http.Request, spa
 				Target: relativePath,
 				Metadata: map[string]any{
 					"path":        relativePath,
-					"filename":    fileInfo.Name() + ".zip",
-					"format":      "zip",
+					"filename":    archiveFileName(fileInfo.Name(), format),
+					"format":      string(format),
 					"sourceCount": 1,
 					"status":      "downloaded",
 				},
@@ -116,12 +129,15 @@ func (h *Handler) streamFileDownload(w http.ResponseWriter, r *http.Request, abs
 		return storageAccessWebError(err, "File not found", "Failed to inspect file")
 	}
 
+	h.setDigestHeader(w, r, absPath, fileInfo)
 	serveAttachmentContent(w, r, file, fileInfo, filepath.Base(absPath), "")
 	return nil
 }
 
 // handleFileDownloadTicket: POST /api/spaces/{id}/files/download-ticket
-// body: { path: string }
+// body: { path: string, expiresIn?: number, bindIp?: boolean }
+// 파일은 재시작 후에도 유효하고 Range 이어받기가 되는 서명 URL을 발급합니다.
+// 폴더는 임시 ZIP을 만들어야 하므로 기존처럼 메모리 티켓(1회용, 5분)을 발급합니다.
 func (h *Handler) handleFileDownloadTicket(w http.ResponseWriter, r *http.Request, spaceID int64) *web.Error {
 	if r.Method != http.MethodPost {
 		return &web.Error{Code: http.StatusMethodNotAllowed, Message: "Method not allowed"}
@@ -139,6 +155,7 @@ func (h *Handler) handleFileDownloadTicket(w http.ResponseWriter, r *http.Reques
 
 	var req struct {
 		Path string `json:"path"`
+		downloadLinkOptions
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		return &web.Error{Code: http.StatusBadRequest, Message: "Invalid request body", Err: err}
@@ -160,50 +177,83 @@ func (h *Handler) handleFileDownloadTicket(w http.ResponseWriter, r *http.Reques
 		return storageAccessWebError(err, "File not found", "Failed to access file")
 	}
 
-	downloadFilePath := absPath
-	downloadFileName := fileInfo.Name()
-	contentType := "application/octet-stream"
-	contentSize := fileInfo.Size()
-	removeAfterUse := false
+	type downloadTicketResponse struct {
+		DownloadURL string `json:"downloadUrl"`
+		FileName    string `json:"fileName"`
+		ExpiresAt   string `json:"expiresAt"`
+	}
 
-	if fileInfo.IsDir() {
-		zipFileName := fileInfo.Name() + ".zip"
-		zipTempPath, zipSize, zipErr := h.buildZipTempArchive(func(zipWriter *zip.Writer) *web.Error {
-			return h.writeFolderToZip(absPath, zipWriter)
-		})
-		if zipErr != nil {
-			return zipErr
+	if !fileInfo.IsDir() {
+		downloadURL, expiresAt, err := h.signDownloadURL(r, download.Claims{
+			SpaceID: spaceID,
+			Path:    filepath.ToSlash(filepath.Clean(req.Path)),
+			Owner:   claims.Username,
+		}, req.downloadLinkOptions, req.ttl())
+		if err != nil {
+			h.recordSpaceAudit(r, audit.Event{
+				Action: "file.download-ticket",
+				Result: audit.ResultFailure,
+				Target: req.Path,
+				Metadata: map[string]any{
+					"path":     req.Path,
+					"filename": fileInfo.Name(),
+					"size":     fileInfo.Size(),
+					"format":   "signed-url",
+					"status":   "failed",
+					"reason":   "sign_url_failed",
+				},
+			}, spaceID)
+			return &web.Error{Code: http.StatusInternalServerError, Message: "Failed to issue download ticket", Err: err}
 		}
-		downloadFilePath = zipTempPath
-		downloadFileName = zipFileName
-		contentType = "application/zip"
-		contentSize = zipSize
-		removeAfterUse = true
+		h.recordSpaceAudit(r, audit.Event{
+			Action: "file.download-ticket",
+			Result: audit.ResultSuccess,
+			Target: req.Path,
+			Metadata: map[string]any{
+				"path":      req.Path,
+				"filename":  fileInfo.Name(),
+				"size":      fileInfo.Size(),
+				"format":    "signed-url",
+				"status":    "ticket_issued",
+				"expiresAt": expiresAt.Format(time.RFC3339),
+				"ipBound":   req.BindIP,
+			},
+		}, spaceID)
+		return writeJSON(w, http.StatusOK, downloadTicketResponse{
+			DownloadURL: downloadURL,
+			FileName:    fileInfo.Name(),
+			ExpiresAt:   expiresAt.Format(time.RFC3339),
+		})
+	}
+
+	zipTempPath, zipSize, zipErr := h.buildZipTempArchive(func(zipWriter *zip.Writer) *web.Error {
+		return h.writeFolderToZip(absPath, zipWriter)
+	})
+	if zipErr != nil {
+		return zipErr
 	}
 
 	ticket, err := h.issueDownloadTicket(
 		claims.Username,
-		downloadFilePath,
-		downloadFileName,
+		zipTempPath,
+		fileInfo.Name()+".zip",
 		"file.download-ticket",
 		&spaceID,
-		contentType,
-		contentSize,
-		removeAfterUse,
+		"application/zip",
+		zipSize,
+		true,
 	)
 	if err != nil {
-		if removeAfterUse {
-			os.Remove(downloadFilePath) //nolint:errcheck
-		}
+		os.Remove(zipTempPath) //nolint:errcheck
 		h.recordSpaceAudit(r, audit.Event{
 			Action: "file.download-ticket",
 			Result: audit.ResultFailure,
 			Target: req.Path,
 			Metadata: map[string]any{
 				"path":     req.Path,
-				"filename": downloadFileName,
-				"size":     contentSize,
-				"format":   contentType,
+				"filename": fileInfo.Name() + ".zip",
+				"size":     zipSize,
+				"format":   "application/zip",
 				"status":   "failed",
 				"reason":   "issue_ticket_failed",
 			},
@@ -217,18 +267,12 @@ func (h *Handler) handleFileDownloadTicket(w http.ResponseWriter, r *http.Reques
 		Metadata: map[string]any{
 			"path":     req.Path,
 			"filename": ticket.FileName,
-			"size":     contentSize,
-			"format":   contentType,
+			"size":     zipSize,
+			"format":   "application/zip",
 			"status":   "ticket_issued",
 		},
 	}, spaceID)
 
-	type downloadTicketResponse struct {
-		DownloadURL string `json:"downloadUrl"`
-		FileName    string `json:"fileName"`
-		ExpiresAt   string `json:"expiresAt"`
-	}
-
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	_ = json.NewEncoder(w).Encode(downloadTicketResponse{
@@ -240,7 +284,8 @@ func (h *Handler) handleFileDownloadTicket(w http.ResponseWriter, r *http.Reques
 }
 
 // handleFileDownloadMultiple: POST /api/spaces/{id}/files/download-multiple
-// body: { paths: []string }
+// body: { paths: []string, format?: "zip" | "tar" | "tar.gz", password?: string }
+// password가 있으면 항목 수와 관계없이 AES-256으로 암호화한 ZIP을 만듭니다.
 func (h *Handler) handleFileDownloadMultiple(w http.ResponseWriter, r *http.Request, spaceID int64) *web.Error {
 	if r.Method != http.MethodPost {
 		return &web.Error{Code: http.StatusMethodNotAllowed, Message: "Method not allowed"}
@@ -252,7 +297,9 @@ func (h *Handler) handleFileDownloadMultiple(w http.ResponseWriter, r *http.Requ
 	}
 
 	var req struct {
-		Paths []string `json:"paths"`
+		Paths    []string `json:"paths"`
+		Format   string   `json:"format"`
+		Password string   `json:"password"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		return &web.Error{Code: http.StatusBadRequest, Message: "Invalid request body", Err: err}
@@ -260,6 +307,14 @@ func (h *Handler) handleFileDownloadMultiple(w http.ResponseWriter, r *http.Requ
 	if len(req.Paths) == 0 {
 		return &web.Error{Code: http.StatusBadRequest, Message: "paths array is required and cannot be empty"}
 	}
+	format, webErr := parseDownloadArchiveFormat(req.Format)
+	if webErr != nil {
+		return webErr
+	}
+	if webErr := validateArchivePassword(req.Password, format); webErr != nil {
+		return webErr
+	}
+	encrypted := req.Password != ""
 
 	absPaths := make([]string, 0, len(req.Paths))
 	for _, relPath := range req.Paths {
@@ -281,14 +336,14 @@ func (h *Handler) handleFileDownloadMultiple(w http.ResponseWriter, r *http.Requ
 		absPaths = append(absPaths, absPath)
 	}
 
-	if len(absPaths) == 1 {
+	if len(absPaths) == 1 && !encrypted {
 		fileInfo, err := os.Stat(absPaths[0])
 		if err != nil {
 			return storageAccessWebError(err, "File not found", "Failed to access file")
 		}
 
 		if fileInfo.IsDir() {
-			downloadErr := h.downloadFolderAsZip(w, absPaths[0], fileInfo.Name())
+			downloadErr := h.downloadFolderArchive(w, r, absPaths[0], fileInfo.Name(), format)
 			if downloadErr != nil {
 				h.recordSpaceAudit(r, audit.Event{
 					Action: "file.download",
@@ -296,8 +351,8 @@ func (h *Handler) handleFileDownloadMultiple(w http.ResponseWriter, r *http.Requ
 					Target: req.Paths[0],
 					Metadata: map[string]any{
 						"path":        req.Paths[0],
-						"filename":    fileInfo.Name() + ".zip",
-						"format":      "zip",
+						"filename":    archiveFileName(fileInfo.Name(), format),
+						"format":      string(format),
 						"sourceCount": 1,
 						"status":      "failed",
 						"reason":      "stream_failed",
@@ -310,8 +365,8 @@ func (h *Handler) handleFileDownloadMultiple(w http.ResponseWriter, r *http.Requ
 					Target: req.Paths[0],
 					Metadata: map[string]any{
 						"path":        req.Paths[0],
-						"filename":    fileInfo.Name() + ".zip",
-						"format":      "zip",
+						"filename":    archiveFileName(fileInfo.Name(), format),
+						"format":      string(format),
 						"sourceCount": 1,
 						"status":      "downloaded",
 					},
@@ -354,15 +409,42 @@ func (h *Handler) handleFileDownloadMultiple(w http.ResponseWriter, r *http.Requ
 		return downloadErr
 	}
 
-	zipFileName := fmt.Sprintf("download-%d.zip", os.Getpid())
-	downloadErr := h.streamZipDownload(w, zipFileName, func(zipWriter *zip.Writer) *web.Error {
-		for i, absPath := range absPaths {
-			if err := addToZip(zipWriter, absPath, filepath.Base(req.Paths[i])); err != nil {
+	downloadFileName := archiveFileName(fmt.Sprintf("download-%d", os.Getpid()), format)
+	if len(absPaths) == 1 {
+		downloadFileName = archiveFileName(filepath.Base(absPaths[0]), format)
+	}
+	sources := make([]archiveDownloadSource, 0, len(absPaths))
+	for i, absPath := range absPaths {
+		sources = append(sources, archiveDownloadSource{
+			RelativePath: req.Paths[i],
+			AbsPath:      absPath,
+			BaseName:     filepath.Base(req.Paths[i]),
+		})
+	}
+	var downloadErr *web.Error
+	if encrypted {
+		downloadErr = h.streamZipDownload(w, downloadFileName, func(zipWriter *zip.Writer) *web.Error {
+			err := walkArchiveSources(sources, func(entry archiveZipEntry) error {
+				_, writeErr := writeEncryptedZipEntry(r.Context(), zipWriter, entry, req.Password)
+				return writeErr
+			})
+			if err != nil {
 				return &web.Error{Code: http.StatusInternalServerError, Message: "Failed to create zip archive", Err: err}
 			}
-		}
-		return nil
-	})
+			return nil
+		})
+	} else if format == archiveFormatZip {
+		downloadErr = h.streamZipDownload(w, downloadFileName, func(zipWriter *zip.Writer) *web.Error {
+			for i, absPath := range absPaths {
+				if err := addToZip(zipWriter, absPath, filepath.Base(req.Paths[i])); err != nil {
+					return &web.Error{Code: http.StatusInternalServerError, Message: "Failed to create zip archive", Err: err}
+				}
+			}
+			return nil
+		})
+	} else {
+		downloadErr = h.streamArchiveDownload(w, r, downloadFileName, format, sources)
+	}
 	if downloadErr != nil {
 		h.recordSpaceAudit(r, audit.Event{
 			Action: "file.download-multiple",
@@ -370,8 +452,9 @@ func (h *Handler) handleFileDownloadMultiple(w http.ResponseWriter, r *http.Requ
 			Target: fmt.Sprintf("%d items", len(req.Paths)),
 			Metadata: map[string]any{
 				"sourceCount": len(req.Paths),
-				"filename":    zipFileName,
-				"format":      "zip",
+				"filename":    downloadFileName,
+				"format":      string(format),
+				"encrypted":   encrypted,
 				"status":      "failed",
 				"reason":      "stream_failed",
 			},
@@ -383,8 +466,9 @@ func (h *Handler) handleFileDownloadMultiple(w http.ResponseWriter, r *http.Requ
 			Target: fmt.Sprintf("%d items", len(req.Paths)),
 			Metadata: map[string]any{
 				"sourceCount": len(req.Paths),
-				"filename":    zipFileName,
-				"format":      "zip",
+				"filename":    downloadFileName,
+				"format":      string(format),
+				"encrypted":   encrypted,
 				"status":      "downloaded",
 			},
 		}, spaceID)
@@ -499,6 +583,17 @@ func (h *Handler) handleFileDownloadMultipleTicket(w http.ResponseWriter, r *htt
 	return nil
 }
 
+// downloadFolderArchive는 폴더를 format 형식으로 내려보냅니다.
+// ZIP은 임시 파일로 만든 뒤 크기와 함께 보내고, tar/tar.gz는 임시 파일 없이 바로 스트리밍합니다.
+func (h *Handler) downloadFolderArchive(w http.ResponseWriter, r *http.Request, folderPath string, folderName string, format archiveFormat) *web.Error {
+	if format == archiveFormatZip {
+		return h.downloadFolderAsZip(w, folderPath, folderName)
+	}
+	return h.streamArchiveDownload(w, r, archiveFileName(folderName, format), format, []archiveDownloadSource{
+		{AbsPath: folderPath},
+	})
+}
+
 func (h *Handler) downloadFolderAsZip(w http.ResponseWriter, folderPath string, folderName string) *web.Error {
 	zipFileName := folderName + ".zip"
 	return h.streamZipDownload(w, zipFileName, func(zipWriter *zip.Writer) *web.Error {
@@ -522,15 +617,12 @@ func (h *Handler) writeFolderToZip(folderPath string, zipWriter *zip.Writer) *we
 			return nil
 		}
 
-		header, err := zip.FileInfoHeader(info)
+		header, err := newArchiveZipHeader(info, relPath)
 		if err != nil {
 			return err
 		}
-		header.Name = filepath.ToSlash(relPath)
-		header.Method = zip.Deflate
 
 		if info.IsDir() {
-			header.Name += "/"
 			_, err = zipWriter.CreateHeader(header)
 			return err
 		}
@@ -635,16 +727,13 @@ func addToZip(zipWriter *zip.Writer, sourcePath string, baseName string) error {
 				zipPath = baseName
 			}
 
-			header, err := zip.FileInfoHeader(info)
+			header, err := newArchiveZipHeader(info, zipPath)
 			if err != nil {
 				return err
 			}
-			header.Name = filepath.ToSlash(zipPath)
-			header.Method = zip.Deflate
 
 			if info.IsDir() {
 				if relPath != "." {
-					header.Name += "/"
 					if _, err := zipWriter.CreateHeader(header); err != nil {
 						return err
 					}
@@ -666,12 +755,10 @@ func addToZip(zipWriter *zip.Writer, sourcePath string, baseName string) error {
 		})
 	}
 
-	header, err := zip.FileInfoHeader(info)
+	header, err := newArchiveZipHeader(info, baseName)
 	if err != nil {
 		return err
 	}
-	header.Name = filepath.ToSlash(baseName)
-	header.Method = zip.Deflate
 
 	writer, err := zipWriter.CreateHeader(header)
 	if err != nil {
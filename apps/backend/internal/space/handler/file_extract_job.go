@@ -0,0 +1,604 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	"taeu.kr/cohesion/internal/audit"
+	"taeu.kr/cohesion/internal/auth"
+	"taeu.kr/cohesion/internal/job"
+	"taeu.kr/cohesion/internal/platform/logging"
+	"taeu.kr/cohesion/internal/platform/web"
+	"taeu.kr/cohesion/internal/space"
+)
+
+const (
+	// FileExtractJobType은 Space 안의 압축 파일을 대상 폴더에 푸는 작업 유형입니다.
+	FileExtractJobType = "file.extract"
+
+	defaultFileExtractWorkerLimit = 1
+
+	// 압축 폭탄 방지 한도입니다. 압축률은 풀린 크기가 maxExtractRatioFloorBytes를 넘을 때만 봅니다.
+	maxExtractEntries         = 200000
+	maxExtractTotalBytes      = int64(100) << 30
+	maxExtractRatio           = 100
+	maxExtractRatioFloorBytes = int64(64) << 20
+
+	// maxExtractFailureDetails는 작업 결과에 남기는 항목별 실패 수 상한입니다.
+	maxExtractFailureDetails = 100
+
+	fileExtractCodeUnsafePath      = "unsafe_path"
+	fileExtractCodeUnsupportedType = "unsupported_entry"
+)
+
+// errExtractWriteInterrupted는 항목을 풀던 중 Space 쓰기가 막히거나 root가 바뀌었음을 나타냅니다.
+var errExtractWriteInterrupted = errors.New("space writes were interrupted")
+
+// fileExtractPayload는 작업 큐에 저장되는 압축 해제 입력입니다.
+type fileExtractPayload struct {
+	Path            string        `json:"path"`
+	DestinationPath string        `json:"destinationPath"`
+	Format          archiveFormat `json:"format"`
+	ConflictPolicy  string        `json:"conflictPolicy,omitempty"`
+}
+
+// fileExtractResult는 압축 해제 작업 출력입니다. Failed는 앞의 일부만 담고 전체 수는 FailedCount입니다.
+type fileExtractResult struct {
+	DestinationPath string                `json:"destinationPath"`
+	Extracted       int                   `json:"extracted"`
+	Skipped         int                   `json:"skipped"`
+	FailedCount     int                   `json:"failedCount"`
+	Failed          []fileTransferFailure `json:"failed"`
+}
+
+func (r *fileExtractResult) addFailure(name string, reason string, code string) {
+	r.FailedCount++
+	if len(r.Failed) < maxExtractFailureDetails {
+		r.Failed = append(r.Failed, fileTransferFailure{Path: name, Reason: reason, Code: code})
+	}
+}
+
+// archiveExtractPlan은 압축을 풀기 전에 항목을 훑어 얻은 크기 정보입니다.
+type archiveExtractPlan struct {
+	Entries    int
+	Files      int
+	TotalBytes int64
+}
+
+func (h *Handler) registerFileExtractJobType() {
+	h.jobs.Register(FileExtractJobType, job.TypeConfig{
+		Concurrency: defaultFileExtractWorkerLimit,
+		Run:         h.runFileExtractJob,
+		OnFinish:    h.finishFileExtractJob,
+	})
+}
+
+// handleFileExtract: POST /api/spaces/{id}/files/extract
+// body: { path: string, destination?: string, conflictPolicy?: string }
+func (h *Handler) handleFileExtract(w http.ResponseWriter, r *http.Request, spaceID int64) *web.Error {
+	if r.Method != http.MethodPost {
+		return &web.Error{Code: http.StatusMethodNotAllowed, Message: "Method not allowed"}
+	}
+
+	spaceData, webErr := h.getSpace(r, spaceID)
+	if webErr != nil {
+		return webErr
+	}
+	claims, ok := auth.ClaimsFromContext(r.Context())
+	if !ok {
+		return &web.Error{Code: http.StatusUnauthorized, Message: "Unauthorized"}
+	}
+
+	var req struct {
+		Path           string `json:"path"`
+		Destination    string `json:"destination,omitempty"`
+		ConflictPolicy string `json:"conflictPolicy,omitempty"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return &web.Error{Code: http.StatusBadRequest, Message: "Invalid request body", Err: err}
+	}
+	if strings.TrimSpace(req.Path) == "" {
+		return &web.Error{Code: http.StatusBadRequest, Message: "path is required"}
+	}
+	if _, _, err := resolveUploadConflictPolicy(req.ConflictPolicy, false); err != nil {
+		return &web.Error{Code: http.StatusBadRequest, Message: "Invalid conflict policy", Err: err}
+	}
+
+	if err := ensurePathOutsideTrash(req.Path); err != nil {
+		return &web.Error{Code: http.StatusForbidden, Message: "Access denied: invalid path", Err: err}
+	}
+	absArchive, err := resolveAbsPath(spaceData.SpacePath, req.Path)
+	if err != nil {
+		return &web.Error{Code: http.StatusForbidden, Message: "Access denied: invalid path", Err: err}
+	}
+	archiveInfo, err := os.Stat(absArchive)
+	if err != nil {
+		return storageAccessWebError(err, "Archive not found", "Failed to access archive")
+	}
+	if archiveInfo.IsDir() {
+		return &web.Error{Code: http.StatusBadRequest, Message: "Path must be an archive file"}
+	}
+	format, ok := detectArchiveFormat(archiveInfo.Name())
+	if !ok {
+		return &web.Error{Code: http.StatusBadRequest, Message: "Unsupported archive format"}
+	}
+
+	destination := strings.TrimSpace(req.Destination)
+	if destination == "" {
+		destination = filepath.ToSlash(filepath.Join(filepath.Dir(req.Path), trimArchiveExtension(archiveInfo.Name())))
+	}
+	if err := ensurePathOutsideTrash(destination); err != nil {
+		return &web.Error{Code: http.StatusForbidden, Message: "Access denied: invalid destination path", Err: err}
+	}
+	absDestination, err := resolveAbsPath(spaceData.SpacePath, destination)
+	if err != nil {
+		return &web.Error{Code: http.StatusForbidden, Message: "Access denied: invalid destination path", Err: err}
+	}
+	if destInfo, err := os.Stat(absDestination); err == nil {
+		if !destInfo.IsDir() {
+			return &web.Error{Code: http.StatusBadRequest, Message: "Destination must be a directory"}
+		}
+	} else if !os.IsNotExist(err) {
+		return storageAccessWebError(err, "", "Failed to access destination directory")
+	}
+
+	extractJob, err := h.jobs.Enqueue(r.Context(), job.EnqueueRequest{
+		Type:      FileExtractJobType,
+		Owner:     claims.Username,
+		SpaceID:   &spaceID,
+		RequestID: strings.TrimSpace(r.Header.Get("X-Request-Id")),
+		Payload: fileExtractPayload{
+			Path:            req.Path,
+			DestinationPath: destination,
+			Format:          format,
+			ConflictPolicy:  req.ConflictPolicy,
+		},
+	})
+	if err != nil {
+		return &web.Error{Code: http.StatusInternalServerError, Message: "Failed to create extract job", Err: err}
+	}
+	h.logFileExtractJobEvent("info.extract.job_created", extractJob, nil)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	if err := json.NewEncoder(w).Encode(extractJob); err != nil {
+		return &web.Error{Code: http.StatusInternalServerError, Message: "Failed to encode extract job response", Err: err}
+	}
+	return nil
+}
+
+// runFileExtractJob은 압축 파일을 먼저 훑어 한도와 Space 할당량을 확인한 뒤 항목을 풉니다.
+func (h *Handler) runFileExtractJob(ctx context.Context, run *job.Run) error {
+	current := run.Job()
+	if current == nil || current.SpaceID == nil {
+		return job.Permanent(errors.New("extract job has no space"))
+	}
+	spaceID := *current.SpaceID
+
+	var payload fileExtractPayload
+	if err := current.DecodePayload(&payload); err != nil {
+		return job.Permanent(fmt.Errorf("invalid extract job payload: %w", err))
+	}
+	conflictPolicy, hasConflictPolicy, err := resolveUploadConflictPolicy(payload.ConflictPolicy, false)
+	if err != nil {
+		return job.Permanent(errors.New("Invalid conflict policy"))
+	}
+
+	if _, err := h.spaceService.GetSpaceByID(ctx, spaceID); err != nil {
+		return job.Permanent(errors.New("Space not found"))
+	}
+	// root는 쓰기를 기록한 뒤에 읽어야 하므로 경로를 정할 때만 잡았다가 놓습니다.
+	spaceData, release, err := h.spaceService.BeginWrite(ctx, spaceID)
+	if err != nil {
+		return err
+	}
+	release()
+	// 쓰기는 항목마다 잡았다 놓습니다. 작업 내내 잡고 있으면 저장소 이전이나 상태 변경의 동결이
+	// 압축을 다 풀 때까지 기다려야 합니다.
+	beginWrite := func() (func(), error) {
+		writable, release, err := h.spaceService.BeginWrite(ctx, spaceID)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", errExtractWriteInterrupted, err)
+		}
+		if writable.SpacePath != spaceData.SpacePath {
+			release()
+			return nil, fmt.Errorf("%w: space root changed", errExtractWriteInterrupted)
+		}
+		return release, nil
+	}
+	absArchive, err := resolveAbsPath(spaceData.SpacePath, payload.Path)
+	if err != nil {
+		return job.Permanent(errors.New("Access denied: invalid path"))
+	}
+	archiveInfo, err := os.Stat(absArchive)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return job.Permanent(errors.New("Archive not found"))
+		}
+		return errors.New(safeFilesystemReason("Failed to access archive", err))
+	}
+	absDestination, err := resolveAbsPath(spaceData.SpacePath, payload.DestinationPath)
+	if err != nil {
+		return job.Permanent(errors.New("Access denied: invalid destination path"))
+	}
+
+	plan, err := scanArchiveForExtract(ctx, absArchive, payload.Format)
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		return job.Permanent(errors.New(safeFilesystemReason("Failed to read archive", err)))
+	}
+	if err := checkArchiveExtractLimits(plan, archiveInfo.Size()); err != nil {
+		return job.Permanent(err)
+	}
+	if webErr := h.ensureSpaceQuotaForWrite(ctx, spaceID, plan.TotalBytes); webErr != nil {
+		if webErr.Code == http.StatusInsufficientStorage {
+			return job.Permanent(errors.New(quotaFailureReason(webErr.Err)))
+		}
+		return webErr.Err
+	}
+	if webErr := h.ensureSpaceFileQuota(ctx, spaceID, int64(plan.Files)); webErr != nil {
+		if webErr.Code == http.StatusInsufficientStorage {
+			return job.Permanent(errors.New(quotaFailureReason(webErr.Err)))
+		}
+		return webErr.Err
+	}
+	if webErr := h.ensureUserQuotaForWrite(ctx, current.Owner, spaceID, plan.TotalBytes); webErr != nil {
+		if webErr.Code == http.StatusInsufficientStorage {
+			return job.Permanent(errors.New(userQuotaFailureReason(webErr.Err)))
+		}
+		return webErr.Err
+	}
+	policy, err := h.uploadPolicyFor(ctx, spaceID)
+	if err != nil {
+		return err
+	}
+	run.SetTotals(plan.Files, plan.TotalBytes)
+
+	releaseWrite, err := beginWrite()
+	if err != nil {
+		return job.Permanent(errors.New("Space is no longer writable"))
+	}
+	err = os.MkdirAll(absDestination, 0o755)
+	releaseWrite()
+	if err != nil {
+		return job.Permanent(errors.New(safeFilesystemReason("Failed to create destination directory", err)))
+	}
+
+	result := fileExtractResult{DestinationPath: payload.DestinationPath, Failed: []fileTransferFailure{}}
+	progress := func(items int, bytes int64) {
+		run.Advance(items, bytes)
+	}
+	var usageDelta, fileDelta int64
+	walkErr := walkArchive(ctx, absArchive, payload.Format, func(member archiveMember, open archiveMemberOpenFunc) error {
+		releaseWrite, err := beginWrite()
+		if err != nil {
+			return err
+		}
+		outcome, err := extractArchiveMember(ctx, payload.DestinationPath, absDestination, member, open, conflictPolicy, hasConflictPolicy, policy, func(absPath string) error {
+			return h.checkRetentionAs(ctx, current.Owner, spaceID, spaceData.SpacePath, absPath, "overwrite")
+		}, progress)
+		if err == nil && outcome.WrittenPath != "" {
+			h.recordFileOwner(ctx, current.Owner, spaceID, spaceData.SpacePath, outcome.WrittenPath)
+		}
+		releaseWrite()
+		if err != nil {
+			return err
+		}
+		usageDelta += outcome.UsageDelta
+		fileDelta += outcome.FileDelta
+		if member.IsDir {
+			if outcome.Status == fileTransferItemFailed {
+				result.addFailure(member.Name, outcome.Failure.Reason, outcome.Failure.Code)
+			}
+			return nil
+		}
+		switch outcome.Status {
+		case fileTransferItemSucceeded:
+			result.Extracted++
+		case fileTransferItemSkipped:
+			result.Skipped++
+			run.Advance(1, member.Size)
+		default:
+			result.addFailure(member.Name, outcome.Failure.Reason, outcome.Failure.Code)
+			run.Advance(1, member.Size)
+		}
+		return nil
+	})
+	// 취소되거나 실패해도 이미 풀린 항목만큼은 사용량에 반영합니다.
+	h.adjustQuotaUsage(ctx, spaceID, usageDelta, fileDelta)
+	if setErr := run.SetResult(result); setErr != nil && walkErr == nil {
+		walkErr = setErr
+	}
+	if walkErr != nil {
+		if errors.Is(walkErr, context.Canceled) || errors.Is(walkErr, context.DeadlineExceeded) {
+			return walkErr
+		}
+		if errors.Is(walkErr, errExtractWriteInterrupted) {
+			return job.Permanent(errors.New("Space is no longer writable"))
+		}
+		return job.Permanent(errors.New(safeFilesystemReason("Failed to extract archive", walkErr)))
+	}
+	return nil
+}
+
+// scanArchiveForExtract는 항목 수와 풀린 크기 합계를 셉니다. tar 계열은 전체를 한 번 읽습니다.
+func scanArchiveForExtract(ctx context.Context, absArchive string, format archiveFormat) (archiveExtractPlan, error) {
+	var plan archiveExtractPlan
+	err := walkArchive(ctx, absArchive, format, func(member archiveMember, _ archiveMemberOpenFunc) error {
+		plan.Entries++
+		if plan.Entries > maxExtractEntries {
+			return fmt.Errorf("archive has more than %d entries", maxExtractEntries)
+		}
+		if member.IsDir {
+			return nil
+		}
+		plan.Files++
+		if member.Size > 0 {
+			plan.TotalBytes += member.Size
+		}
+		if plan.TotalBytes > maxExtractTotalBytes {
+			return fmt.Errorf("archive expands beyond %d bytes", maxExtractTotalBytes)
+		}
+		return nil
+	})
+	return plan, err
+}
+
+// checkArchiveExtractLimits는 압축 폭탄을 막기 위해 풀린 크기와 압축률을 확인합니다.
+func checkArchiveExtractLimits(plan archiveExtractPlan, archiveSize int64) error {
+	if plan.Entries > maxExtractEntries {
+		return fmt.Errorf("Archive exceeds extraction limits: more than %d entries", maxExtractEntries)
+	}
+	if plan.TotalBytes > maxExtractTotalBytes {
+		return fmt.Errorf("Archive exceeds extraction limits: expands beyond %d bytes", maxExtractTotalBytes)
+	}
+	if plan.TotalBytes > maxExtractRatioFloorBytes {
+		if archiveSize <= 0 || plan.TotalBytes/archiveSize > maxExtractRatio {
+			return fmt.Errorf("Archive exceeds extraction limits: compression ratio above %d", maxExtractRatio)
+		}
+	}
+	return nil
+}
+
+// extractArchiveMember는 항목 하나를 대상 폴더에 풉니다. 파일은 임시 경로에 쓴 뒤 이름을 바꾸므로
+// 실패하거나 취소되어도 반쯤 쓴 파일이 남지 않습니다. ctx 취소만 오류로 반환합니다.
+// destinationPath는 absDestination의 Space 기준 상대 경로로, 휴지통 아래로 풀리는 항목을 거르는 데 씁니다.
+func extractArchiveMember(
+	ctx context.Context,
+	destinationPath string,
+	absDestination string,
+	member archiveMember,
+	open archiveMemberOpenFunc,
+	conflictPolicy uploadConflictPolicy,
+	hasConflictPolicy bool,
+	policy *space.UploadPolicy,
+	ensureMutable func(absPath string) error,
+	progress transferProgressFunc,
+) (fileTransferOutcome, error) {
+	fail := func(reason string, code string) (fileTransferOutcome, error) {
+		return fileTransferOutcome{
+			Status:  fileTransferItemFailed,
+			Failure: fileTransferFailure{Path: member.Name, Reason: reason, Code: code},
+		}, nil
+	}
+
+	if member.Unsafe || containsTrashDirectorySegment(member.Name) {
+		return fail("Unsafe entry path", fileExtractCodeUnsafePath)
+	}
+	if member.Unsupported {
+		return fail("Unsupported entry type", fileExtractCodeUnsupportedType)
+	}
+	if err := ensurePathOutsideTrash(path.Join(filepath.ToSlash(destinationPath), member.Name)); err != nil {
+		return fail("Unsafe entry path", fileExtractCodeUnsafePath)
+	}
+	targetPath, err := resolveAbsPath(absDestination, filepath.FromSlash(member.Name))
+	if err != nil {
+		return fail("Unsafe entry path", fileExtractCodeUnsafePath)
+	}
+
+	if member.IsDir {
+		if info, statErr := os.Stat(targetPath); statErr == nil {
+			if !info.IsDir() {
+				return fail("Cannot overwrite destination with different type", fileConflictCodeDestinationTypeMismatch)
+			}
+			return fileTransferOutcome{Status: fileTransferItemSucceeded}, nil
+		}
+		if err := os.MkdirAll(targetPath, 0o755); err != nil {
+			return fail(safeFilesystemReason("Failed to create directory", err), "")
+		}
+		return fileTransferOutcome{Status: fileTransferItemSucceeded}, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(targetPath), 0o755); err != nil {
+		return fail(safeFilesystemReason("Failed to create directory", err), "")
+	}
+	var replacedBytes int64
+	replaced := false
+	if destInfo, statErr := os.Stat(targetPath); statErr == nil {
+		if !hasConflictPolicy {
+			return fail("Destination path already exists", fileConflictCodeDestinationExists)
+		}
+		switch conflictPolicy {
+		case uploadConflictPolicyOverwrite:
+			if destInfo.IsDir() {
+				return fail("Cannot overwrite destination with different type", fileConflictCodeDestinationTypeMismatch)
+			}
+			if err := ensureMutable(targetPath); err != nil {
+				return fail(retentionFailure(err))
+			}
+			replacedBytes = destInfo.Size()
+			replaced = true
+		case uploadConflictPolicyRename:
+			renamedPath, _, renameErr := resolveUploadRenamePath(targetPath)
+			if renameErr != nil {
+				return fail(safeFilesystemReason("Failed to resolve rename destination", renameErr), "")
+			}
+			targetPath = renamedPath
+		case uploadConflictPolicySkip:
+			return fileTransferOutcome{Status: fileTransferItemSkipped}, nil
+		}
+	} else if !os.IsNotExist(statErr) {
+		return fail(safeFilesystemReason("Failed to access destination", statErr), "")
+	}
+	if err := policy.CheckName(member.Name); err != nil {
+		return fail(uploadPolicyFailure(err))
+	}
+	if err := policy.CheckSize(member.Name, member.Size); err != nil {
+		return fail(uploadPolicyFailure(err))
+	}
+
+	stagedPath, err := resolveUniqueSiblingPath(targetPath, "extract")
+	if err != nil {
+		return fail(safeFilesystemReason("Failed to allocate staging path", err), "")
+	}
+	if err := writeArchiveMember(ctx, stagedPath, member, open, progress); err != nil {
+		os.Remove(stagedPath) //nolint:errcheck
+		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+			return fileTransferOutcome{}, err
+		}
+		return fail(safeFilesystemReason("Failed to extract", err), "")
+	}
+	// 이름만으로는 알 수 없는 형식은 풀어 놓은 내용으로 확인합니다.
+	if err := policy.CheckFile(member.Name, stagedPath); err != nil {
+		os.Remove(stagedPath) //nolint:errcheck
+		return fail(uploadPolicyFailure(err))
+	}
+	if err := os.Rename(stagedPath, targetPath); err != nil {
+		os.Remove(stagedPath) //nolint:errcheck
+		return fail(safeFilesystemReason("Failed to extract", err), "")
+	}
+	writtenBytes := member.Size
+	if info, statErr := os.Stat(targetPath); statErr == nil {
+		writtenBytes = info.Size()
+	}
+	if !member.ModTime.IsZero() {
+		_ = os.Chtimes(targetPath, member.ModTime, member.ModTime)
+	}
+	if progress != nil {
+		progress(1, 0)
+	}
+	outcome := fileTransferOutcome{Status: fileTransferItemSucceeded, UsageDelta: writtenBytes - replacedBytes, WrittenPath: targetPath}
+	if !replaced {
+		outcome.FileDelta = 1
+	}
+	return outcome, nil
+}
+
+func writeArchiveMember(ctx context.Context, stagedPath string, member archiveMember, open archiveMemberOpenFunc, progress transferProgressFunc) error {
+	reader, err := open()
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	mode := member.Mode.Perm()
+	if mode == 0 {
+		mode = 0o644
+	}
+	file, err := os.OpenFile(stagedPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, mode)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	var writer io.Writer = file
+	if progress != nil {
+		writer = &progressWriter{writer: file, progress: progress}
+	}
+	// 선언된 크기보다 많이 풀리는 항목은 사전 검사를 우회하므로 거부합니다.
+	written, err := copyWithContext(ctx, writer, io.LimitReader(reader, member.Size+1))
+	if err != nil {
+		return err
+	}
+	if written > member.Size {
+		return fmt.Errorf("entry expands beyond declared size")
+	}
+	return file.Close()
+}
+
+// finishFileExtractJob은 검색 색인을 갱신하고 요약 감사 기록을 남깁니다.
+// 사용량은 항목을 풀 때마다 runFileExtractJob이 반영합니다.
+func (h *Handler) finishFileExtractJob(extractJob *job.Job) {
+	var payload fileExtractPayload
+	_ = extractJob.DecodePayload(&payload)
+	var result fileExtractResult
+	_ = extractJob.DecodeResult(&result)
+
+	var spaceID int64
+	if extractJob.SpaceID != nil {
+		spaceID = *extractJob.SpaceID
+	}
+	if result.Extracted > 0 {
+		h.markSearchIndexDirty(context.Background(), spaceID, "extract")
+	}
+
+	auditResult := audit.ResultSuccess
+	switch {
+	case extractJob.Status != job.StatusCompleted && result.Extracted == 0:
+		auditResult = audit.ResultFailure
+	case extractJob.Status != job.StatusCompleted || result.FailedCount > 0 || result.Skipped > 0:
+		auditResult = audit.ResultPartial
+	}
+	metadata := map[string]any{
+		"jobId":       extractJob.ID,
+		"path":        payload.Path,
+		"destination": payload.DestinationPath,
+		"format":      string(payload.Format),
+		"status":      string(extractJob.Status),
+		"extracted":   result.Extracted,
+		"skipped":     result.Skipped,
+		"failed":      result.FailedCount,
+		"failedItems": auditFailureItems(result.Failed),
+	}
+	if extractJob.FailureReason != "" {
+		metadata["reason"] = extractJob.FailureReason
+	}
+
+	var runErr error
+	if extractJob.Status == job.StatusFailed {
+		runErr = errors.New(extractJob.FailureReason)
+	}
+	h.logFileExtractJobEvent("info.extract.job_finished", extractJob, runErr)
+	h.recordSpaceAuditBackground(extractJob.Owner, extractJob.RequestID, audit.Event{
+		Action:   "file.extract",
+		Result:   auditResult,
+		Target:   payload.Path,
+		SpaceID:  extractJob.SpaceID,
+		Metadata: metadata,
+	})
+}
+
+func (h *Handler) logFileExtractJobEvent(eventName string, extractJob *job.Job, err error) {
+	var payload fileExtractPayload
+	_ = extractJob.DecodePayload(&payload)
+	var spaceID int64
+	if extractJob.SpaceID != nil {
+		spaceID = *extractJob.SpaceID
+	}
+
+	logger := logging.Event(log.Info(), logging.ComponentStorage, eventName).
+		Str("job_id", extractJob.ID).
+		Int64("space_id", spaceID).
+		Str("owner", extractJob.Owner).
+		Str("status", string(extractJob.Status)).
+		Str("format", string(payload.Format)).
+		Int("total_items", extractJob.Progress.TotalItems).
+		Int("processed_items", extractJob.Progress.ProcessedItems).
+		Int64("total_bytes", extractJob.Progress.TotalBytes).
+		Int64("processed_bytes", extractJob.Progress.ProcessedBytes)
+	if err != nil {
+		logger = logger.Err(err)
+	}
+	logger.Msg("extract job updated")
+}
@@ -0,0 +1,351 @@
+package handler
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+	"golang.org/x/text/encoding/korean"
+	"taeu.kr/cohesion/internal/audit"
+	"taeu.kr/cohesion/internal/job"
+	"taeu.kr/cohesion/internal/space"
+)
+
+type testArchiveEntry struct {
+	name    string
+	content string
+	dir     bool
+	nonUTF8 bool
+}
+
+func writeTestZip(t *testing.T, path string, entries []testArchiveEntry) {
+	t.Helper()
+
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("failed to create zip: %v", err)
+	}
+	defer file.Close()
+	writer := zip.NewWriter(file)
+	for _, entry := range entries {
+		header := &zip.FileHeader{Name: entry.name, Method: zip.Deflate, NonUTF8: entry.nonUTF8}
+		if entry.dir {
+			header.Name += "/"
+		}
+		entryWriter, err := writer.CreateHeader(header)
+		if err != nil {
+			t.Fatalf("failed to add zip entry %q: %v", entry.name, err)
+		}
+		if _, err := io.WriteString(entryWriter, entry.content); err != nil {
+			t.Fatalf("failed to write zip entry %q: %v", entry.name, err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("failed to close zip: %v", err)
+	}
+}
+
+func writeTestTar(t *testing.T, path string, format archiveFormat, entries []testArchiveEntry) {
+	t.Helper()
+
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("failed to create tar: %v", err)
+	}
+	defer file.Close()
+
+	var out io.Writer = file
+	var compressor io.WriteCloser
+	switch format {
+	case archiveFormatTarGzip:
+		compressor = gzip.NewWriter(file)
+	case archiveFormatTarZstd:
+		compressor, err = zstd.NewWriter(file)
+		if err != nil {
+			t.Fatalf("failed to create zstd writer: %v", err)
+		}
+	}
+	if compressor != nil {
+		out = compressor
+	}
+	writer := tar.NewWriter(out)
+	for _, entry := range entries {
+		header := &tar.Header{Name: entry.name, Mode: 0o644, Size: int64(len(entry.content)), Typeflag: tar.TypeReg}
+		if entry.dir {
+			header = &tar.Header{Name: entry.name + "/", Mode: 0o755, Typeflag: tar.TypeDir}
+		}
+		if err := writer.WriteHeader(header); err != nil {
+			t.Fatalf("failed to add tar entry %q: %v", entry.name, err)
+		}
+		if _, err := io.WriteString(writer, entry.content); err != nil {
+			t.Fatalf("failed to write tar entry %q: %v", entry.name, err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("failed to close tar: %v", err)
+	}
+	if compressor != nil {
+		if err := compressor.Close(); err != nil {
+			t.Fatalf("failed to close compressor: %v", err)
+		}
+	}
+}
+
+func requestFileExtract(t *testing.T, handler *Handler, body map[string]any) (*job.Job, *httptest.ResponseRecorder) {
+	t.Helper()
+
+	encoded, err := json.Marshal(body)
+	if err != nil {
+		t.Fatalf("failed to marshal request: %v", err)
+	}
+	req := httptest.NewRequest(http.MethodPost, "/api/spaces/1/files/extract", bytes.NewReader(encoded))
+	req = withClaims(req, "tester")
+	rec := httptest.NewRecorder()
+	if webErr := handler.handleFileExtract(rec, req, 1); webErr != nil {
+		t.Fatalf("unexpected web error: %+v", webErr)
+	}
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("expected 202, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var created job.Job
+	if err := json.NewDecoder(rec.Body).Decode(&created); err != nil {
+		t.Fatalf("failed to decode job: %v", err)
+	}
+	return &created, rec
+}
+
+func TestFileExtractJob_ExtractsZipWithCP949NamesAndRejectsZipSlip(t *testing.T) {
+	handler, root, _, _, sink := newFileTransferJobTestHandler(t)
+
+	koreanName, err := korean.EUCKR.NewEncoder().String("보고서.txt")
+	if err != nil {
+		t.Fatalf("failed to encode cp949 name: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Join(root, "uploads"), 0o755); err != nil {
+		t.Fatalf("failed to create uploads dir: %v", err)
+	}
+	writeTestZip(t, filepath.Join(root, "uploads", "bundle.zip"), []testArchiveEntry{
+		{name: "docs", dir: true},
+		{name: "docs/readme.txt", content: "hello"},
+		{name: "docs/" + koreanName, content: "korean", nonUTF8: true},
+		{name: "../escape.txt", content: "evil"},
+	})
+
+	created, _ := requestFileExtract(t, handler, map[string]any{"path": "uploads/bundle.zip"})
+	finished := waitForFileTransferJob(t, handler, created.ID, job.StatusCompleted)
+	if finished.Progress.TotalItems != 3 || finished.Progress.ProcessedItems != 3 {
+		t.Fatalf("expected 3/3 items, got %+v", finished.Progress)
+	}
+
+	var result fileExtractResult
+	if err := finished.DecodeResult(&result); err != nil {
+		t.Fatalf("failed to decode result: %v", err)
+	}
+	if result.DestinationPath != "uploads/bundle" || result.Extracted != 2 || result.FailedCount != 1 {
+		t.Fatalf("unexpected extract result: %+v", result)
+	}
+	if result.Failed[0].Code != fileExtractCodeUnsafePath {
+		t.Fatalf("expected zip-slip entry to fail with unsafe_path, got %+v", result.Failed)
+	}
+	if _, err := os.Stat(filepath.Join(root, "uploads", "escape.txt")); !os.IsNotExist(err) {
+		t.Fatalf("zip-slip entry must not be written, err=%v", err)
+	}
+	if content, err := os.ReadFile(filepath.Join(root, "uploads", "bundle", "docs", "보고서.txt")); err != nil || string(content) != "korean" {
+		t.Fatalf("expected cp949 name to be decoded, content=%q err=%v", content, err)
+	}
+
+	event := sink.waitForAction(t, "file.extract")
+	if event.Result != audit.ResultPartial || event.Metadata["extracted"] != 2 {
+		t.Fatalf("unexpected extract audit: %+v", event)
+	}
+}
+
+func TestFileExtractJob_ExtractsTarFormatsWithConflictPolicy(t *testing.T) {
+	for _, format := range []archiveFormat{archiveFormatTar, archiveFormatTarGzip, archiveFormatTarZstd} {
+		t.Run(string(format), func(t *testing.T) {
+			handler, root, _, _, _ := newFileTransferJobTestHandler(t)
+
+			writeTestTar(t, filepath.Join(root, "bundle."+string(format)), format, []testArchiveEntry{
+				{name: "./", dir: true},
+				{name: "./a.txt", content: "from-archive"},
+				{name: "./nested", dir: true},
+				{name: "./nested/b.txt", content: "bbb"},
+			})
+			if err := os.MkdirAll(filepath.Join(root, "out"), 0o755); err != nil {
+				t.Fatalf("failed to create destination: %v", err)
+			}
+			if err := os.WriteFile(filepath.Join(root, "out", "a.txt"), []byte("existing"), 0o644); err != nil {
+				t.Fatalf("failed to seed conflict: %v", err)
+			}
+
+			created, _ := requestFileExtract(t, handler, map[string]any{
+				"path":           "bundle." + string(format),
+				"destination":    "out",
+				"conflictPolicy": "rename",
+			})
+			finished := waitForFileTransferJob(t, handler, created.ID, job.StatusCompleted)
+			var result fileExtractResult
+			if err := finished.DecodeResult(&result); err != nil {
+				t.Fatalf("failed to decode result: %v", err)
+			}
+			if result.Extracted != 2 || result.FailedCount != 0 {
+				t.Fatalf("unexpected extract result: %+v", result)
+			}
+			if content, err := os.ReadFile(filepath.Join(root, "out", "a (1).txt")); err != nil || string(content) != "from-archive" {
+				t.Fatalf("expected renamed entry, content=%q err=%v", content, err)
+			}
+			if content, _ := os.ReadFile(filepath.Join(root, "out", "a.txt")); string(content) != "existing" {
+				t.Fatalf("existing file must be kept, got %q", content)
+			}
+			if content, err := os.ReadFile(filepath.Join(root, "out", "nested", "b.txt")); err != nil || string(content) != "bbb" {
+				t.Fatalf("expected nested entry, content=%q err=%v", content, err)
+			}
+		})
+	}
+}
+
+func TestFileExtractJob_RejectsEntriesUnderTrash(t *testing.T) {
+	handler, root, _, _, _ := newFileTransferJobTestHandler(t)
+
+	writeTestZip(t, filepath.Join(root, "bundle.zip"), []testArchiveEntry{
+		{name: spaceTrashDirectoryName + "/planted.txt", content: "evil"},
+		{name: "docs/" + spaceTrashDirectoryName + "/nested.txt", content: "evil"},
+		{name: "docs/kept.txt", content: "kept"},
+	})
+
+	created, _ := requestFileExtract(t, handler, map[string]any{"path": "bundle.zip", "destination": "."})
+	finished := waitForFileTransferJob(t, handler, created.ID, job.StatusCompleted)
+	var result fileExtractResult
+	if err := finished.DecodeResult(&result); err != nil {
+		t.Fatalf("failed to decode result: %v", err)
+	}
+	if result.Extracted != 1 || result.FailedCount != 2 {
+		t.Fatalf("unexpected extract result: %+v", result)
+	}
+	for _, failure := range result.Failed {
+		if failure.Code != fileExtractCodeUnsafePath {
+			t.Fatalf("expected trash entries to fail with unsafe_path, got %+v", result.Failed)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(root, spaceTrashDirectoryName)); !os.IsNotExist(err) {
+		t.Fatalf("trash entry must not be written, err=%v", err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "docs", spaceTrashDirectoryName)); !os.IsNotExist(err) {
+		t.Fatalf("nested trash entry must not be written, err=%v", err)
+	}
+	if content, err := os.ReadFile(filepath.Join(root, "docs", "kept.txt")); err != nil || string(content) != "kept" {
+		t.Fatalf("expected regular entry, content=%q err=%v", content, err)
+	}
+}
+
+func TestFileExtractJob_StopsWhenWritesAreFrozen(t *testing.T) {
+	handler, root, _, _, _ := newFileTransferJobTestHandler(t)
+
+	entries := make([]testArchiveEntry, 20000)
+	for i := range entries {
+		entries[i] = testArchiveEntry{name: fmt.Sprintf("file-%05d.txt", i), content: "x"}
+	}
+	writeTestTar(t, filepath.Join(root, "many.tar"), archiveFormatTar, entries)
+
+	created, _ := requestFileExtract(t, handler, map[string]any{"path": "many.tar"})
+	deadline := time.Now().Add(3 * time.Second)
+	for {
+		current, err := handler.jobs.Get(context.Background(), created.ID)
+		if err != nil {
+			t.Fatalf("failed to load extract job: %v", err)
+		}
+		if current.Status == job.StatusRunning && current.Progress.ProcessedItems > 0 {
+			break
+		}
+		if current.Status.IsTerminal() || time.Now().After(deadline) {
+			t.Fatalf("expected extract job to be running, got %+v", current)
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	// 쓰기를 항목마다 잡으므로 동결은 작업이 끝나기를 기다리지 않습니다.
+	freezeCtx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	unfreeze, err := handler.spaceService.FreezeWrites(freezeCtx, 1)
+	if err != nil {
+		t.Fatalf("expected freeze to wait only for the current entry: %v", err)
+	}
+	defer unfreeze()
+
+	failed := waitForFileTransferJob(t, handler, created.ID, job.StatusFailed)
+	if failed.FailureReason != "Space is no longer writable" {
+		t.Fatalf("unexpected failure reason: %q", failed.FailureReason)
+	}
+	if failed.Progress.ProcessedItems >= len(entries) {
+		t.Fatalf("expected extraction to stop early, got %+v", failed.Progress)
+	}
+}
+
+func TestFileExtractJob_FailsUpFrontWhenQuotaIsExceeded(t *testing.T) {
+	root := t.TempDir()
+	quota := int64(8)
+	store := &fakeQuotaSpaceStore{
+		spacesByID: map[int64]*space.Space{
+			1: {ID: 1, SpaceName: "Quota", SpacePath: root, QuotaBytes: &quota},
+		},
+	}
+	handler := NewHandler(space.NewService(store), nil, nil)
+	manager := job.NewManager(job.NewMemoryStore())
+	t.Cleanup(manager.Stop)
+	handler.SetJobManager(manager)
+
+	// 압축 파일 자체(수백 바이트)는 할당량을 넘지만 검사 대상은 풀린 크기입니다.
+	writeTestZip(t, filepath.Join(root, "big.zip"), []testArchiveEntry{
+		{name: "big.txt", content: strings.Repeat("x", 4096)},
+	})
+	created, _ := requestFileExtract(t, handler, map[string]any{"path": "big.zip"})
+	failed := waitForFileTransferJob(t, handler, created.ID, job.StatusFailed)
+	if !strings.Contains(failed.FailureReason, "Space quota exceeded") {
+		t.Fatalf("expected quota failure, got %q", failed.FailureReason)
+	}
+	if _, err := os.Stat(filepath.Join(root, "big")); !os.IsNotExist(err) {
+		t.Fatalf("nothing should be extracted when quota check fails, err=%v", err)
+	}
+}
+
+func TestCheckArchiveExtractLimits(t *testing.T) {
+	if err := checkArchiveExtractLimits(archiveExtractPlan{Entries: 10, Files: 10, TotalBytes: 1 << 20}, 10); err != nil {
+		t.Fatalf("small archives should not be checked for ratio: %v", err)
+	}
+	if err := checkArchiveExtractLimits(archiveExtractPlan{Entries: 1, Files: 1, TotalBytes: 1 << 30}, 1<<20); err == nil {
+		t.Fatal("expected compression ratio limit to reject 1024:1 archive")
+	}
+	if err := checkArchiveExtractLimits(archiveExtractPlan{Entries: maxExtractEntries + 1}, 1<<30); err == nil {
+		t.Fatal("expected entry count limit")
+	}
+}
+
+func TestHandleFileExtract_RejectsUnsupportedFormat(t *testing.T) {
+	handler, root, _, _, _ := newFileTransferJobTestHandler(t)
+	if err := os.WriteFile(filepath.Join(root, "notes.rar"), []byte("rar"), 0o644); err != nil {
+		t.Fatalf("failed to seed file: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/spaces/1/files/extract", strings.NewReader(`{"path":"notes.rar"}`))
+	req = withClaims(req, "tester")
+	webErr := handler.handleFileExtract(httptest.NewRecorder(), req, 1)
+	if webErr == nil || webErr.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for unsupported format, got %+v", webErr)
+	}
+	if !strings.Contains(webErr.Message, "Unsupported archive format") {
+		t.Fatalf("unexpected message: %q", webErr.Message)
+	}
+}
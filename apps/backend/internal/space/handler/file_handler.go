@@ -11,6 +11,14 @@ import (
 func (h *Handler) handleSpaceFiles(w http.ResponseWriter, r *http.Request, spaceID int64, action string) *web.Error {
 	var webErr *web.Error
 
+	if isSpaceFileMutationAction(action) {
+		release, webErr := h.beginSpaceWrite(r.Context(), spaceID)
+		if webErr != nil {
+			return webErr
+		}
+		defer release()
+	}
+
 	switch action {
 	case "download":
 		webErr = h.handleFileDownload(w, r, spaceID)
@@ -20,6 +28,10 @@ func (h *Handler) handleSpaceFiles(w http.ResponseWriter, r *http.Request, space
 		webErr = h.handleArchiveDownloads(w, r, spaceID)
 	case "archive-download-ticket":
 		webErr = h.handleArchiveDownloadTicket(w, r, spaceID)
+	case "checksum":
+		webErr = h.handleFileChecksum(w, r, spaceID)
+	case "checksums":
+		webErr = h.handleFileChecksums(w, r, spaceID)
 	case "rename":
 		webErr = h.handleFileRename(w, r, spaceID)
 	case "delete":
@@ -42,6 +54,10 @@ func (h *Handler) handleSpaceFiles(w http.ResponseWriter, r *http.Request, space
 		webErr = h.handleFileMove(w, r, spaceID)
 	case "copy":
 		webErr = h.handleFileCopy(w, r, spaceID)
+	case "extract":
+		webErr = h.handleFileExtract(w, r, spaceID)
+	case "compress":
+		webErr = h.handleFileCompress(w, r, spaceID)
 	case "download-multiple":
 		webErr = h.handleFileDownloadMultiple(w, r, spaceID)
 	case "download-multiple-ticket":
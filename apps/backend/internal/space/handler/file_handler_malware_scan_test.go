@@ -0,0 +1,99 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"taeu.kr/cohesion/internal/space"
+	"taeu.kr/cohesion/internal/space/malware"
+)
+
+type fakeMalwareScanner struct{}
+
+func (fakeMalwareScanner) Name() string { return "fake" }
+
+func (fakeMalwareScanner) Scan(ctx context.Context, path string) (malware.Result, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return malware.Result{}, err
+	}
+	if strings.Contains(string(data), "EICAR") {
+		return malware.Result{Infected: true, Signature: "Eicar-Test-Signature"}, nil
+	}
+	return malware.Result{}, nil
+}
+
+func TestHandleFileUpload_BlockModeQuarantinesInfectedUpload(t *testing.T) {
+	spaceRoot := t.TempDir()
+	store := &fakeQuotaSpaceStore{
+		spacesByID: map[int64]*space.Space{
+			1: {ID: 1, SpaceName: "Documents", SpacePath: spaceRoot},
+		},
+	}
+	spaceService := space.NewService(store)
+	handler := NewHandler(spaceService, nil, nil)
+
+	// 검사기가 없으면 off 외의 모드를 켤 수 없다.
+	req := httptest.NewRequest(http.MethodPut, "/api/spaces/1/malware-scan", bytes.NewBufferString(`{"mode":"block"}`))
+	if webErr := handler.handleSpaceMalwareScan(httptest.NewRecorder(), req, 1); webErr == nil || webErr.Code != http.StatusBadRequest {
+		t.Fatalf("expected block mode without scanner to be rejected, got %+v", webErr)
+	}
+
+	handler.SetMalwareScanService(space.NewMalwareScanService(spaceService, fakeMalwareScanner{}, t.TempDir()))
+	req = httptest.NewRequest(http.MethodPut, "/api/spaces/1/malware-scan", bytes.NewBufferString(`{"mode":"block"}`))
+	rec := httptest.NewRecorder()
+	if webErr := handler.handleSpaceMalwareScan(rec, req, 1); webErr != nil {
+		t.Fatalf("expected settings update to succeed, got %+v", webErr)
+	}
+	var settings malwareScanSettingsResponse
+	if err := json.NewDecoder(rec.Body).Decode(&settings); err != nil {
+		t.Fatalf("decode settings: %v", err)
+	}
+	if settings.Mode != space.MalwareScanModeBlock || settings.Scanner != "fake" {
+		t.Fatalf("unexpected settings response: %+v", settings)
+	}
+
+	webErr := handler.handleFileUpload(httptest.NewRecorder(), newUploadRequest(t, "invoice.pdf", "EICAR payload", nil), 1)
+	if webErr == nil || webErr.Code != http.StatusUnprocessableEntity || webErr.Message != "File was quarantined: malware detected (Eicar-Test-Signature)" {
+		t.Fatalf("expected infected upload to be rejected with 422, got %+v", webErr)
+	}
+	if _, err := os.Stat(filepath.Join(spaceRoot, "invoice.pdf")); !os.IsNotExist(err) {
+		t.Fatalf("infected upload should not be stored, err=%v", err)
+	}
+
+	if webErr := handler.handleFileUpload(httptest.NewRecorder(), newUploadRequest(t, "notes.txt", "hello", nil), 1); webErr != nil {
+		t.Fatalf("expected clean upload to succeed, got %+v", webErr)
+	}
+
+	rec = httptest.NewRecorder()
+	if webErr := handler.handleQuarantineList(rec, httptest.NewRequest(http.MethodGet, "/api/quarantine?spaceId=1", nil)); webErr != nil {
+		t.Fatalf("expected quarantine list to succeed, got %+v", webErr)
+	}
+	var items []space.QuarantineItem
+	if err := json.NewDecoder(rec.Body).Decode(&items); err != nil {
+		t.Fatalf("decode items: %v", err)
+	}
+	if len(items) != 1 || items[0].OriginalPath != "invoice.pdf" || items[0].Signature != "Eicar-Test-Signature" {
+		t.Fatalf("unexpected quarantine items: %+v", items)
+	}
+
+	releasePath := fmt.Sprintf("%s%d/release", quarantinePathPrefix, items[0].ID)
+	if webErr := handler.handleQuarantineItem(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, releasePath, nil)); webErr != nil {
+		t.Fatalf("expected release to succeed, got %+v", webErr)
+	}
+	if data, err := os.ReadFile(filepath.Join(spaceRoot, "invoice.pdf")); err != nil || string(data) != "EICAR payload" {
+		t.Fatalf("expected released file at original path, got %q (%v)", data, err)
+	}
+	webErr = handler.handleQuarantineItem(httptest.NewRecorder(), httptest.NewRequest(http.MethodDelete, releasePath[:strings.LastIndex(releasePath, "/")], nil))
+	if webErr == nil || webErr.Code != http.StatusNotFound {
+		t.Fatalf("expected released item to be gone, got %+v", webErr)
+	}
+}
@@ -29,7 +29,7 @@ func (f *fakeQuotaSpaceStore) GetByName(ctx context.Context, name string) (*spac
 func (f *fakeQuotaSpaceStore) GetByID(ctx context.Context, id int64) (*space.Space, error) {
 	spaceData, ok := f.spacesByID[id]
 	if !ok {
-		return nil, errors.New("space not found")
+		return nil, space.ErrSpaceNotFound
 	}
 	return spaceData, nil
 }
@@ -206,3 +206,80 @@ func TestHandleFileUpload_ReleasesReservationAfterStreamFailure(t *testing.T) {
 		t.Fatalf("expected upload reservation to be released, got %d", reserved)
 	}
 }
+
+func TestHandleFileUpload_EnforcesUserQuotaAndRecordsOwner(t *testing.T) {
+	spaceRoot := t.TempDir()
+	store := &fakeQuotaSpaceStore{
+		spacesByID: map[int64]*space.Space{
+			1: {ID: 1, SpaceName: "Shared", SpacePath: spaceRoot},
+		},
+	}
+
+	handler := NewHandler(space.NewService(store), nil, nil)
+	if err := handler.userQuotaService.ReplaceUserQuotas(context.Background(), "tester", []*space.UserQuota{
+		{SpaceID: space.GlobalUserQuotaSpaceID, QuotaBytes: 3},
+	}); err != nil {
+		t.Fatalf("failed to set user quota: %v", err)
+	}
+
+	rec := httptest.NewRecorder()
+	if webErr := handler.handleFileUpload(rec, withClaims(newUploadRequest(t, "a.txt", "12", nil), "tester"), 1); webErr != nil {
+		t.Fatalf("expected first upload to fit user quota, got %+v", webErr)
+	}
+
+	rec = httptest.NewRecorder()
+	webErr := handler.handleFileUpload(rec, withClaims(newUploadRequest(t, "b.txt", "12", nil), "tester"), 1)
+	if webErr == nil || webErr.Code != http.StatusInsufficientStorage {
+		t.Fatalf("expected user quota exceeded, got %+v", webErr)
+	}
+	if _, err := os.Stat(filepath.Join(spaceRoot, "b.txt")); !os.IsNotExist(err) {
+		t.Fatalf("upload over user quota should not create file, err=%v", err)
+	}
+
+	rec = httptest.NewRecorder()
+	req := withClaims(httptest.NewRequest(http.MethodGet, "/api/storage/me", nil), "tester")
+	if webErr := handler.handleMyStorage(rec, req); webErr != nil {
+		t.Fatalf("unexpected web error: %+v", webErr)
+	}
+	var usage space.UserStorageUsage
+	if err := json.NewDecoder(rec.Body).Decode(&usage); err != nil {
+		t.Fatalf("failed to decode usage: %v", err)
+	}
+	if usage.UsedBytes != 2 || usage.FileCount != 1 || usage.QuotaBytes == nil || *usage.QuotaBytes != 3 {
+		t.Fatalf("unexpected user usage: %+v", usage)
+	}
+}
+
+func TestHandleFileUpload_WarnsNearQuotaAndBlocksOverFileQuota(t *testing.T) {
+	spaceRoot := t.TempDir()
+	quotaBytes, quotaFiles := int64(10), int64(1)
+	store := &fakeQuotaSpaceStore{
+		spacesByID: map[int64]*space.Space{
+			1: {ID: 1, SpaceName: "Files", SpacePath: spaceRoot, QuotaBytes: &quotaBytes, QuotaFiles: &quotaFiles},
+		},
+	}
+
+	handler := NewHandler(space.NewService(store), nil, nil)
+	rec := httptest.NewRecorder()
+	if webErr := handler.handleFileUpload(rec, newUploadRequest(t, "a.txt", "123456789", nil), 1); webErr != nil {
+		t.Fatalf("expected first upload to succeed, got %+v", webErr)
+	}
+	if got := rec.Header().Get(quotaWarningHeader); got != "bytes=80, files=95" {
+		t.Fatalf("expected quota warning header, got %q", got)
+	}
+
+	rec = httptest.NewRecorder()
+	webErr := handler.handleFileUpload(rec, newUploadRequest(t, "b.txt", "1", nil), 1)
+	if webErr == nil || webErr.Code != http.StatusInsufficientStorage || webErr.Message != "Space file quota exceeded" {
+		t.Fatalf("expected file quota exceeded, got %+v", webErr)
+	}
+	if _, err := os.Stat(filepath.Join(spaceRoot, "b.txt")); !os.IsNotExist(err) {
+		t.Fatalf("upload over file quota should not create file, err=%v", err)
+	}
+
+	// 기존 파일 덮어쓰기는 파일 수를 늘리지 않는다.
+	rec = httptest.NewRecorder()
+	if webErr := handler.handleFileUpload(rec, newUploadRequest(t, "a.txt", "1", map[string]string{"conflictPolicy": "overwrite"}), 1); webErr != nil {
+		t.Fatalf("expected overwrite to succeed, got %+v", webErr)
+	}
+}
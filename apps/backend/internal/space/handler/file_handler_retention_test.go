@@ -0,0 +1,91 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"taeu.kr/cohesion/internal/space"
+)
+
+func TestRetentionLock_BlocksMutationsOfExistingFilesInLockedFolder(t *testing.T) {
+	handler, root := setupTrashHandler(t)
+	if err := os.MkdirAll(filepath.Join(root, "contracts"), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "contracts", "signed.pdf"), []byte("pdf"), 0o644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+
+	until := time.Now().Add(48 * time.Hour).UTC().Truncate(time.Second)
+	req := newJSONRequestWithClaims(t, http.MethodPut, "/api/spaces/1/retention-locks", map[string]any{
+		"path":        "contracts",
+		"retainUntil": until,
+		"reason":      "계약서 보관",
+	})
+	rec := httptest.NewRecorder()
+	if webErr := handler.handleSpaceRetentionLocks(rec, req, 1); webErr != nil {
+		t.Fatalf("expected lock to be saved, got %+v", webErr)
+	}
+	var lock space.RetentionLock
+	if err := json.NewDecoder(rec.Body).Decode(&lock); err != nil {
+		t.Fatalf("decode lock: %v", err)
+	}
+	if lock.Path != "contracts" || lock.CreatedBy != "tester" || !lock.RetainUntil.Equal(until) {
+		t.Fatalf("unexpected lock: %+v", lock)
+	}
+
+	shorter := until.Add(-time.Hour)
+	req = newJSONRequestWithClaims(t, http.MethodPut, "/api/spaces/1/retention-locks", map[string]any{"path": "contracts", "retainUntil": shorter})
+	if webErr := handler.handleSpaceRetentionLocks(httptest.NewRecorder(), req, 1); webErr == nil || webErr.Code != http.StatusConflict {
+		t.Fatalf("expected shortening to be rejected with 409, got %+v", webErr)
+	}
+	req = newJSONRequestWithClaims(t, http.MethodDelete, "/api/spaces/1/retention-locks?path=contracts", nil)
+	if webErr := handler.handleSpaceRetentionLocks(httptest.NewRecorder(), req, 1); webErr == nil || webErr.Code != http.StatusConflict {
+		t.Fatalf("expected active lock delete to be rejected with 409, got %+v", webErr)
+	}
+
+	req = newJSONRequestWithClaims(t, http.MethodPost, "/api/spaces/1/files/delete", map[string]string{"path": "contracts/signed.pdf"})
+	if webErr := handler.handleFileDelete(httptest.NewRecorder(), req, 1); webErr == nil || webErr.Code != http.StatusLocked {
+		t.Fatalf("expected delete to be rejected with 423, got %+v", webErr)
+	}
+	req = newJSONRequestWithClaims(t, http.MethodPost, "/api/spaces/1/files/rename", map[string]string{"path": "contracts/signed.pdf", "newName": "old.pdf"})
+	if webErr := handler.handleFileRename(httptest.NewRecorder(), req, 1); webErr == nil || webErr.Code != http.StatusLocked {
+		t.Fatalf("expected rename to be rejected with 423, got %+v", webErr)
+	}
+
+	req = newJSONRequestWithClaims(t, http.MethodPost, "/api/spaces/1/files/delete-multiple", map[string][]string{"paths": {"contracts"}})
+	rec = httptest.NewRecorder()
+	if webErr := handler.handleFileDeleteMultiple(rec, req, 1); webErr != nil {
+		t.Fatalf("delete-multiple: %+v", webErr)
+	}
+	var result struct {
+		Failed []struct {
+			Path string `json:"path"`
+			Code string `json:"code"`
+		} `json:"failed"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&result); err != nil {
+		t.Fatalf("decode result: %v", err)
+	}
+	if len(result.Failed) != 1 || result.Failed[0].Code != retentionLockedFailureCode {
+		t.Fatalf("expected locked folder delete to fail with %s, got %+v", retentionLockedFailureCode, result.Failed)
+	}
+	if _, err := os.Stat(filepath.Join(root, "contracts", "signed.pdf")); err != nil {
+		t.Fatalf("locked file should remain, err=%v", err)
+	}
+
+	// 잠긴 폴더에도 새 파일은 만들 수 있다.
+	if err := handler.checkRetention(req.Context(), 1, root, filepath.Join(root, "contracts", "new.pdf"), "write"); err != nil {
+		t.Fatalf("expected new file to be allowed, got %v", err)
+	}
+
+	req = newJSONRequestWithClaims(t, http.MethodDelete, "/api/spaces/1", map[string]string{"mode": "delete_data"})
+	if webErr := handler.handleDeleteSpace(httptest.NewRecorder(), req, 1); webErr == nil || webErr.Code != http.StatusConflict {
+		t.Fatalf("expected space deletion to be rejected with 409, got %+v", webErr)
+	}
+}
@@ -39,6 +39,8 @@ const (
 
 const (
 	spaceTrashDirectoryName = ".cohesion_trash"
+	// quotaWarningHeader는 쓰기는 성공했지만 Space 사용량이 경고 비율을 넘었음을 알리는 응답 헤더입니다.
+	quotaWarningHeader = "X-Cohesion-Quota-Warning"
 )
 
 func resolveAbsPath(spacePath, relativePath string) (string, error) {
@@ -135,6 +137,12 @@ func moveWithDestinationSwap(srcPath, destPath string) error {
 }
 
 func copyWithDestinationSwap(srcPath, destPath string, isDir bool) error {
+	return copyWithDestinationSwapContext(context.Background(), srcPath, destPath, isDir, nil)
+}
+
+// copyWithDestinationSwapContext는 취소 가능한 copyWithDestinationSwap입니다.
+// 스테이징 복사 중 취소되면 기존 대상은 그대로 두고 스테이징 경로만 지웁니다.
+func copyWithDestinationSwapContext(ctx context.Context, srcPath, destPath string, isDir bool, progress transferProgressFunc) error {
 	stagedPath, err := resolveUniqueSiblingPath(destPath, "stage")
 	if err != nil {
 		return fmt.Errorf("failed to allocate staging path: %w", err)
@@ -142,9 +150,9 @@ func copyWithDestinationSwap(srcPath, destPath string, isDir bool) error {
 
 	var copyErr error
 	if isDir {
-		copyErr = copyDir(srcPath, stagedPath)
+		copyErr = copyDirWithProgress(ctx, srcPath, stagedPath, progress)
 	} else {
-		copyErr = copyFile(srcPath, stagedPath)
+		copyErr = copyFileWithProgress(ctx, srcPath, stagedPath, progress)
 	}
 	if copyErr != nil {
 		os.RemoveAll(stagedPath) //nolint:errcheck
@@ -287,6 +295,56 @@ func (h *Handler) ensureSpaceQuotaForWrite(ctx context.Context, spaceID int64, d
 	return nil
 }
 
+// ensureSpaceFileQuota는 Space에 파일을 deltaFiles개 더 만들어도 파일 수 할당량을 넘지 않는지 확인합니다.
+func (h *Handler) ensureSpaceFileQuota(ctx context.Context, spaceID int64, deltaFiles int64) *web.Error {
+	if h.quotaService == nil {
+		return nil
+	}
+
+	if err := h.quotaService.EnsureCanAddFiles(ctx, spaceID, deltaFiles); err != nil {
+		var fileQuotaErr *space.FileQuotaExceededError
+		if errors.As(err, &fileQuotaErr) {
+			return &web.Error{Code: http.StatusInsufficientStorage, Message: "Space file quota exceeded", Err: err}
+		}
+		return &web.Error{Code: http.StatusInternalServerError, Message: "Failed to evaluate space quota", Err: err}
+	}
+	return nil
+}
+
+// adjustQuotaUsage는 변화량을 아는 쓰기 작업 뒤 Space 사용량 카운터에 변화량을 더합니다.
+func (h *Handler) adjustQuotaUsage(ctx context.Context, spaceID int64, deltaBytes int64, deltaFiles int64) {
+	if h.quotaService == nil {
+		return
+	}
+	h.quotaService.AdjustUsage(context.WithoutCancel(ctx), spaceID, deltaBytes, deltaFiles)
+}
+
+// setQuotaWarningHeader는 쓰기 뒤 Space 사용량이 경고 비율을 넘었으면 X-Cohesion-Quota-Warning 헤더를 붙입니다.
+// 값은 "bytes=80, files=95"처럼 기준별로 넘은 가장 높은 비율입니다.
+func (h *Handler) setQuotaWarningHeader(w http.ResponseWriter, ctx context.Context, spaceID int64) {
+	if h.quotaService == nil {
+		return
+	}
+	usage, err := h.quotaService.GetSpaceUsage(ctx, spaceID)
+	if err != nil || len(usage.Warnings) == 0 {
+		return
+	}
+	parts := make([]string, 0, len(usage.Warnings))
+	for _, warning := range usage.Warnings {
+		parts = append(parts, fmt.Sprintf("%s=%d", warning.Metric, warning.ThresholdPercent))
+	}
+	w.Header().Set(quotaWarningHeader, strings.Join(parts, ", "))
+}
+
+// trackQuotaUsage는 작업 전후로 absPaths의 크기를 재어 차이를 Space 사용량에 더하는 함수를 반환합니다.
+func (h *Handler) trackQuotaUsage(ctx context.Context, spaceID int64, absPaths ...string) func() {
+	if h.quotaService == nil {
+		return func() {}
+	}
+	return space.TrackUsage(ctx, h.quotaService, spaceID, absPaths...)
+}
+
+// invalidateQuotaForSpaces는 변화량을 알 수 없는 작업 뒤 카운터를 버려 다음 조회 때 다시 세게 합니다.
 func (h *Handler) invalidateQuotaForSpaces(spaceIDs ...int64) {
 	if h.quotaService == nil || len(spaceIDs) == 0 {
 		return
@@ -323,6 +381,10 @@ func quotaFailureReason(err error) string {
 	if errors.As(err, &quotaErr) {
 		return fmt.Sprintf("Space quota exceeded (used=%d, quota=%d)", quotaErr.UsedBytes, quotaErr.QuotaBytes)
 	}
+	var fileQuotaErr *space.FileQuotaExceededError
+	if errors.As(err, &fileQuotaErr) {
+		return fmt.Sprintf("Space file quota exceeded (files=%d, quota=%d)", fileQuotaErr.UsedFiles, fileQuotaErr.QuotaFiles)
+	}
 	return "Space quota exceeded"
 }
 
@@ -390,6 +452,14 @@ func (h *Handler) softDeletePath(r *http.Request, spaceData *space.Space, relPat
 	if webErr != nil {
 		return nil, errors.New(webErr.Message)
 	}
+	return h.moveToTrash(r.Context(), spaceData, relPath, username)
+}
+
+// moveToTrash는 relPath를 Space 휴지통으로 옮기고 deletedBy 이름으로 휴지통 항목을 만듭니다.
+func (h *Handler) moveToTrash(ctx context.Context, spaceData *space.Space, relPath, deletedBy string) (*space.TrashItem, error) {
+	if h.trashService == nil {
+		return nil, errors.New("Trash service is unavailable")
+	}
 
 	normalizedPath := normalizeRelativePath(relPath)
 	if normalizedPath == "" {
@@ -414,6 +484,9 @@ func (h *Handler) softDeletePath(r *http.Request, spaceData *space.Space, relPat
 		}
 		return nil, errors.New("Failed to access file")
 	}
+	if err := h.checkRetention(ctx, spaceData.ID, spaceData.SpacePath, absPath, "delete"); err != nil {
+		return nil, err
+	}
 
 	storageRelativePath, storageAbsPath, err := generateTrashStoragePath(spaceData.SpacePath, fileInfo.Name())
 	if err != nil {
@@ -435,14 +508,14 @@ func (h *Handler) softDeletePath(r *http.Request, spaceData *space.Space, relPat
 		itemSize = 0
 	}
 
-	item, err := h.trashService.CreateTrashItem(r.Context(), &space.CreateTrashItemRequest{
+	item, err := h.trashService.CreateTrashItem(ctx, &space.CreateTrashItemRequest{
 		SpaceID:      spaceData.ID,
 		OriginalPath: normalizedPath,
 		StoragePath:  storageRelativePath,
 		ItemName:     fileInfo.Name(),
 		IsDir:        fileInfo.IsDir(),
 		ItemSize:     itemSize,
-		DeletedBy:    username,
+		DeletedBy:    deletedBy,
 	})
 	if err != nil {
 		if rollbackErr := os.Rename(storageAbsPath, absPath); rollbackErr != nil {
@@ -450,6 +523,8 @@ func (h *Handler) softDeletePath(r *http.Request, spaceData *space.Space, relPat
 		}
 		return nil, errors.New("Failed to create trash metadata")
 	}
+	// 휴지통 항목도 Space 용량을 차지하므로 영구 삭제할 때까지 소유자 사용량에 남긴다.
+	h.moveFileOwners(ctx, spaceData.ID, spaceData.SpacePath, absPath, spaceData.ID, spaceData.SpacePath, storageAbsPath)
 
 	return item, nil
 }
@@ -461,7 +536,7 @@ func (h *Handler) markSearchIndexDirty(ctx context.Context, spaceID int64, actio
 
 	var err error
 	switch action {
-	case "rename", "delete", "delete-multiple", "trash", "trash-restore", "trash-delete", "trash-empty", "create-folder", "upload":
+	case "rename", "delete", "delete-multiple", "trash", "trash-restore", "trash-delete", "trash-empty", "create-folder", "upload", "extract", "compress", "cleanup", "organize":
 		err = h.searchIndexer.MarkSpaceDirty(ctx, spaceID)
 	case "move", "copy":
 		err = h.searchIndexer.MarkAllDirty(ctx)
@@ -473,6 +548,27 @@ func (h *Handler) markSearchIndexDirty(ctx context.Context, spaceID int64, actio
 	}
 }
 
+// isSpaceFileMutationAction은 요청 Space의 파일을 바꾸는 액션인지 판단합니다.
+// copy는 원본 Space를 읽기만 하므로 대상 Space 검사만 각 핸들러에서 수행합니다.
+func isSpaceFileMutationAction(action string) bool {
+	switch action {
+	case "rename", "delete", "delete-multiple", "trash-restore", "trash-delete", "trash-empty", "create-folder", "upload", "move", "extract", "compress":
+		return true
+	default:
+		return false
+	}
+}
+
+// beginSpaceWrite는 요청이 끝날 때까지 Space 쓰기를 진행 중으로 기록합니다.
+// 저장소 이전의 동결은 이 쓰기가 끝나기를 기다리므로 반환한 함수는 꼭 호출해야 합니다.
+func (h *Handler) beginSpaceWrite(ctx context.Context, spaceID int64) (func(), *web.Error) {
+	_, release, err := h.spaceService.BeginWrite(ctx, spaceID)
+	if err != nil {
+		return nil, spaceStateWebError(err, http.StatusLocked)
+	}
+	return release, nil
+}
+
 func (h *Handler) getSpace(r *http.Request, spaceID int64) (*space.Space, *web.Error) {
 	spaceData, err := h.spaceService.GetSpaceByID(r.Context(), spaceID)
 	if err != nil {
@@ -14,6 +14,7 @@ import (
 	"testing"
 	"time"
 
+	"taeu.kr/cohesion/internal/job"
 	"taeu.kr/cohesion/internal/space"
 )
 
@@ -40,7 +41,7 @@ func (f *fakeTransferSpaceStore) GetByName(ctx context.Context, name string) (*s
 func (f *fakeTransferSpaceStore) GetByID(ctx context.Context, id int64) (*space.Space, error) {
 	spaceData, ok := f.spacesByID[id]
 	if !ok {
-		return nil, errors.New("space not found")
+		return nil, space.ErrSpaceNotFound
 	}
 	return spaceData, nil
 }
@@ -218,7 +219,8 @@ func TestArchiveDownloadJobLifecycle(t *testing.T) {
 		},
 	}
 	handler := NewHandler(space.NewService(store), nil, nil)
-	handler.archiveDownloads = newArchiveDownloadManager(1, 100*time.Millisecond)
+	jobStore := job.NewMemoryStore()
+	handler.SetJobManager(job.NewManager(jobStore))
 
 	createBody, err := json.Marshal(map[string]any{
 		"paths": []string{"docs"},
@@ -307,10 +309,19 @@ func TestArchiveDownloadJobLifecycle(t *testing.T) {
 		t.Fatalf("expected archive file name docs.zip, got %q", ticketPayload.FileName)
 	}
 
-	handler.archiveDownloads.updateJob(createPayload.JobID, func(job *archiveDownloadJob) {
-		job.ExpiresAt = time.Now().Add(-time.Millisecond)
-	})
+	readyJob, err := jobStore.Get(context.Background(), createPayload.JobID)
+	if err != nil {
+		t.Fatalf("failed to load archive job: %v", err)
+	}
+	pastExpiry := time.Now().Add(-time.Millisecond)
+	readyJob.ExpiresAt = &pastExpiry
+	if err := jobStore.Update(context.Background(), readyJob); err != nil {
+		t.Fatalf("failed to expire archive job: %v", err)
+	}
 	handler.cleanupArchiveDownloadJobs()
+	if _, err := os.Stat(readyJob.ArtifactPath); !os.IsNotExist(err) {
+		t.Fatalf("expected expired archive artifact to be removed, err=%v", err)
+	}
 
 	expiredReq := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/api/spaces/1/files/archive-downloads?jobId=%s", createPayload.JobID), nil)
 	expiredReq = withClaims(expiredReq, "tester")
@@ -361,7 +372,6 @@ func TestArchiveDownloadJobRunningCancelStopsPreparationAndRejectsTickets(t *tes
 		},
 	}
 	handler := NewHandler(space.NewService(store), nil, nil)
-	handler.archiveDownloads = newArchiveDownloadManager(1, 2*time.Second)
 
 	createBody, err := json.Marshal(map[string]any{
 		"paths": []string{"docs"},
@@ -480,7 +490,6 @@ func TestArchiveDownloadJobReadyCancelRemovesArtifact(t *testing.T) {
 		},
 	}
 	handler := NewHandler(space.NewService(store), nil, nil)
-	handler.archiveDownloads = newArchiveDownloadManager(1, time.Second)
 
 	createBody, err := json.Marshal(map[string]any{
 		"paths": []string{"docs"},
@@ -502,18 +511,18 @@ func TestArchiveDownloadJobReadyCancelRemovesArtifact(t *testing.T) {
 	}
 
 	deadline := time.Now().Add(2 * time.Second)
-	var readyJob *archiveDownloadJob
+	var readyJob *job.Job
 	for {
-		job, webErr := handler.archiveDownloads.getJob(createPayload.JobID, "tester")
-		if webErr != nil {
-			t.Fatalf("unexpected archive getJob error: %+v", webErr)
+		archiveJob, err := handler.jobs.GetForOwner(context.Background(), createPayload.JobID, "tester")
+		if err != nil {
+			t.Fatalf("unexpected archive job lookup error: %v", err)
 		}
-		if job.State == archiveDownloadStateReady {
-			readyJob = job
+		if archiveJob.Status == job.StatusCompleted {
+			readyJob = archiveJob
 			break
 		}
 		if time.Now().After(deadline) {
-			t.Fatalf("archive job did not reach ready state: %+v", job)
+			t.Fatalf("archive job did not reach ready state: %+v", archiveJob)
 		}
 		time.Sleep(20 * time.Millisecond)
 	}
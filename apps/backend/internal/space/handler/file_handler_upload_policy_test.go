@@ -0,0 +1,74 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"taeu.kr/cohesion/internal/space"
+)
+
+func TestHandleFileUpload_EnforcesSpaceUploadPolicy(t *testing.T) {
+	spaceRoot := t.TempDir()
+	store := &fakeQuotaSpaceStore{
+		spacesByID: map[int64]*space.Space{
+			1: {ID: 1, SpaceName: "Documents", SpacePath: spaceRoot},
+		},
+	}
+	handler := NewHandler(space.NewService(store), nil, nil)
+
+	body := `{"blockedExtensions":[".EXE","msi"],"allowedTypes":["application/pdf","text/*"],"maxFileBytes":64}`
+	req := httptest.NewRequest(http.MethodPut, "/api/spaces/1/upload-policy", bytes.NewBufferString(body))
+	rec := httptest.NewRecorder()
+	if webErr := handler.handleSpaceUploadPolicy(rec, req, 1); webErr != nil {
+		t.Fatalf("expected policy update to succeed, got %+v", webErr)
+	}
+	var saved space.UploadPolicy
+	if err := json.NewDecoder(rec.Body).Decode(&saved); err != nil {
+		t.Fatalf("decode policy: %v", err)
+	}
+	if len(saved.BlockedExtensions) != 2 || saved.BlockedExtensions[0] != "exe" {
+		t.Fatalf("expected normalized blocked extensions, got %v", saved.BlockedExtensions)
+	}
+
+	cases := []struct {
+		name     string
+		fileName string
+		content  string
+		code     int
+		message  string
+	}{
+		{"blocked extension", "setup.exe", "hello", http.StatusUnsupportedMediaType, "Extension .exe is blocked in this Space"},
+		{"disguised executable", "report.pdf", "MZ\x90\x00\x03\x00\x00\x00", http.StatusUnsupportedMediaType, "File type application/x-msdownload is not allowed in this Space"},
+		{"too large", "notes.txt", string(bytes.Repeat([]byte("a"), 65)), http.StatusRequestEntityTooLarge, "File exceeds the maximum size of 64 bytes for this Space"},
+	}
+	for _, tc := range cases {
+		rec := httptest.NewRecorder()
+		webErr := handler.handleFileUpload(rec, newUploadRequest(t, tc.fileName, tc.content, nil), 1)
+		if webErr == nil || webErr.Code != tc.code || webErr.Message != tc.message {
+			t.Fatalf("%s: expected %d %q, got %+v", tc.name, tc.code, tc.message, webErr)
+		}
+		if _, err := os.Stat(filepath.Join(spaceRoot, tc.fileName)); !os.IsNotExist(err) {
+			t.Fatalf("%s: rejected upload should not create file, err=%v", tc.name, err)
+		}
+	}
+
+	rec = httptest.NewRecorder()
+	if webErr := handler.handleFileUpload(rec, newUploadRequest(t, "notes.txt", "plain text", nil), 1); webErr != nil {
+		t.Fatalf("expected allowed upload to succeed, got %+v", webErr)
+	}
+
+	// 확장자를 바꾸는 이름 변경으로 차단된 형식을 들여올 수 없다.
+	renameReq := httptest.NewRequest(http.MethodPost, "/api/spaces/1/files/rename", bytes.NewBufferString(`{"path":"notes.txt","newName":"notes.msi"}`))
+	webErr := handler.handleFileRename(httptest.NewRecorder(), renameReq, 1)
+	if webErr == nil || webErr.Code != http.StatusUnsupportedMediaType {
+		t.Fatalf("expected rename to blocked extension to fail, got %+v", webErr)
+	}
+	if _, err := os.Stat(filepath.Join(spaceRoot, "notes.txt")); err != nil {
+		t.Fatalf("rejected rename should keep the original file: %v", err)
+	}
+}
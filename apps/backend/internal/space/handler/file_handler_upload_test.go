@@ -33,7 +33,7 @@ func (f *fakeUploadSpaceStore) GetByName(ctx context.Context, name string) (*spa
 func (f *fakeUploadSpaceStore) GetByID(ctx context.Context, id int64) (*space.Space, error) {
 	spaceData, ok := f.spacesByID[id]
 	if !ok {
-		return nil, errors.New("space not found")
+		return nil, space.ErrSpaceNotFound
 	}
 	return spaceData, nil
 }
@@ -352,3 +352,86 @@ func TestHandleFileUpload_ConflictPolicies(t *testing.T) {
 		}
 	})
 }
+
+func TestHandleFileUpload_VerifiesExpectedChecksum(t *testing.T) {
+	setup := func(t *testing.T) (*Handler, string) {
+		t.Helper()
+
+		spaceRoot := t.TempDir()
+		store := &fakeUploadSpaceStore{
+			spacesByID: map[int64]*space.Space{
+				1: {ID: 1, SpaceName: "Upload", SpacePath: spaceRoot},
+			},
+		}
+		return NewHandler(space.NewService(store), nil, nil), spaceRoot
+	}
+	const helloSHA256 = "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"
+
+	t.Run("stores verified sha256 and returns it on download", func(t *testing.T) {
+		handler, _ := setup(t)
+
+		req := newUploadRequest(t, "hello.txt", "hello", map[string]string{"sha256": helloSHA256})
+		req.Header.Set("Content-MD5", "XUFAKrxLKna5cZ2REBfFkg==")
+		rec := httptest.NewRecorder()
+		if webErr := handler.handleFileUpload(rec, req, 1); webErr != nil {
+			t.Fatalf("expected success, got %+v", webErr)
+		}
+		if payload := decodeUploadResponse(t, rec); payload["sha256"] != helloSHA256 {
+			t.Fatalf("expected sha256 in response, got %+v", payload)
+		}
+
+		downloadReq := httptest.NewRequest(http.MethodGet, "/api/spaces/1/files/download?path=hello.txt", nil)
+		downloadRec := httptest.NewRecorder()
+		if webErr := handler.handleFileDownload(downloadRec, downloadReq, 1); webErr != nil {
+			t.Fatalf("unexpected download error: %+v", webErr)
+		}
+		digest := downloadRec.Header().Get("Digest")
+		if !strings.Contains(digest, "sha-256=LPJNul+wow4m6DsqxbninhsWHlwfp0JecwQzYpOLmCQ=") || !strings.Contains(digest, "md5=XUFAKrxLKna5cZ2REBfFkg==") {
+			t.Fatalf("expected Digest header with verified hashes, got %q", digest)
+		}
+	})
+
+	t.Run("rejects and discards mismatched upload", func(t *testing.T) {
+		handler, root := setup(t)
+		existingPath := filepath.Join(root, "hello.txt")
+		if err := os.WriteFile(existingPath, []byte("old"), 0o644); err != nil {
+			t.Fatalf("failed to prepare existing file: %v", err)
+		}
+
+		req := newUploadRequest(t, "hello.txt", "hellO", map[string]string{"conflictPolicy": "overwrite"})
+		req.Header.Set("Digest", "sha-256=LPJNul+wow4m6DsqxbninhsWHlwfp0JecwQzYpOLmCQ=")
+		rec := httptest.NewRecorder()
+		if webErr := handler.handleFileUpload(rec, req, 1); webErr != nil {
+			t.Fatalf("expected mismatch response, got %+v", webErr)
+		}
+		if rec.Code != http.StatusUnprocessableEntity {
+			t.Fatalf("expected 422, got %d", rec.Code)
+		}
+		var payload uploadChecksumMismatchResponse
+		if err := json.NewDecoder(rec.Body).Decode(&payload); err != nil {
+			t.Fatalf("failed to decode mismatch response: %v", err)
+		}
+		if payload.Code != uploadChecksumMismatchCode || payload.Algorithm != "sha256" || payload.Expected != helloSHA256 {
+			t.Fatalf("unexpected mismatch response: %+v", payload)
+		}
+
+		got, err := os.ReadFile(existingPath)
+		if err != nil || string(got) != "old" {
+			t.Fatalf("expected existing file to remain old, got %q %v", string(got), err)
+		}
+		entries, err := os.ReadDir(root)
+		if err != nil || len(entries) != 1 {
+			t.Fatalf("expected staged upload to be discarded, got %v %v", entries, err)
+		}
+	})
+
+	t.Run("rejects malformed digest", func(t *testing.T) {
+		handler, _ := setup(t)
+
+		req := newUploadRequest(t, "hello.txt", "hello", map[string]string{"sha256": "not-hex"})
+		webErr := handler.handleFileUpload(httptest.NewRecorder(), req, 1)
+		if webErr == nil || webErr.Code != http.StatusBadRequest {
+			t.Fatalf("expected 400 for malformed digest, got %+v", webErr)
+		}
+	})
+}
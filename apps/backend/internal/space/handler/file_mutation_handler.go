@@ -1,9 +1,10 @@
 package handler
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
 	"net/http"
 	"os"
 	"path/filepath"
@@ -15,6 +16,7 @@ import (
 	"taeu.kr/cohesion/internal/audit"
 	"taeu.kr/cohesion/internal/platform/logging"
 	"taeu.kr/cohesion/internal/platform/web"
+	"taeu.kr/cohesion/internal/space"
 )
 
 // handleFileRename: POST /api/spaces/{id}/files/rename
@@ -59,9 +61,42 @@ func (h *Handler) handleFileRename(w http.ResponseWriter, r *http.Request, space
 		return &web.Error{Code: http.StatusForbidden, Message: "Access denied: new path is outside Space"}
 	}
 
-	if _, err := os.Stat(absPath); err != nil {
+	info, err := os.Stat(absPath)
+	if err != nil {
 		return storageAccessWebError(err, "File or directory not found", "Failed to access file")
 	}
+	// 확장자를 바꾸는 이름 변경은 정책을 피해 들여오는 통로가 되므로 새 이름을 업로드 정책으로 확인합니다.
+	if !info.IsDir() && !strings.EqualFold(filepath.Ext(absPath), filepath.Ext(newAbsPath)) {
+		if webErr := h.checkUploadName(r.Context(), spaceID, req.NewName); webErr != nil {
+			h.recordSpaceAudit(r, audit.Event{
+				Action: "file.rename",
+				Result: audit.ResultFailure,
+				Target: req.Path,
+				Metadata: map[string]any{
+					"path":    req.Path,
+					"newName": req.NewName,
+					"reason":  "upload_policy",
+				},
+			}, spaceID)
+			return webErr
+		}
+	}
+
+	for _, candidate := range []string{absPath, newAbsPath} {
+		if webErr := h.ensureMutable(r, spaceData, candidate, "rename"); webErr != nil {
+			h.recordSpaceAudit(r, audit.Event{
+				Action: "file.rename",
+				Result: audit.ResultFailure,
+				Target: req.Path,
+				Metadata: map[string]any{
+					"path":    req.Path,
+					"newName": req.NewName,
+					"reason":  retentionLockedFailureCode,
+				},
+			}, spaceID)
+			return webErr
+		}
+	}
 
 	if err := os.Rename(absPath, newAbsPath); err != nil {
 		h.recordSpaceAudit(r, audit.Event{
@@ -76,6 +111,7 @@ func (h *Handler) handleFileRename(w http.ResponseWriter, r *http.Request, space
 		}, spaceID)
 		return storageOperationWebError(err, "Failed to rename")
 	}
+	h.moveFileOwners(r.Context(), spaceID, spaceData.SpacePath, absPath, spaceID, spaceData.SpacePath, newAbsPath)
 	h.recordSpaceAudit(r, audit.Event{
 		Action: "file.rename",
 		Result: audit.ResultSuccess,
@@ -119,6 +155,15 @@ func (h *Handler) handleFileDelete(w http.ResponseWriter, r *http.Request, space
 
 	item, err := h.softDeletePath(r, spaceData, req.Path)
 	if err != nil {
+		if errors.Is(err, space.ErrRetentionLocked) {
+			h.recordSpaceAudit(r, audit.Event{
+				Action:   "file.delete",
+				Result:   audit.ResultFailure,
+				Target:   req.Path,
+				Metadata: map[string]any{"path": req.Path, "reason": retentionLockedFailureCode},
+			}, spaceID)
+			return retentionWebError(err)
+		}
 		message := strings.TrimSpace(err.Error())
 		lowerMessage := strings.ToLower(message)
 		switch {
@@ -184,6 +229,7 @@ func (h *Handler) handleFileDeleteMultiple(w http.ResponseWriter, r *http.Reques
 	type deleteResult struct {
 		Path   string `json:"path"`
 		Reason string `json:"reason,omitempty"`
+		Code   string `json:"code,omitempty"`
 	}
 	succeeded := []string{}
 	failed := []deleteResult{}
@@ -191,6 +237,11 @@ func (h *Handler) handleFileDeleteMultiple(w http.ResponseWriter, r *http.Reques
 	for _, relPath := range req.Paths {
 		item, err := h.softDeletePath(r, spaceData, relPath)
 		if err != nil {
+			if errors.Is(err, space.ErrRetentionLocked) {
+				reason, code := retentionFailure(err)
+				failed = append(failed, deleteResult{Path: relPath, Reason: reason, Code: code})
+				continue
+			}
 			failed = append(failed, deleteResult{Path: relPath, Reason: err.Error()})
 			continue
 		}
@@ -408,6 +459,18 @@ func (h *Handler) handleTrashRestore(w http.ResponseWriter, r *http.Request, spa
 					})
 					continue
 				}
+				if retentionErr := h.checkRetention(r.Context(), spaceData.ID, spaceData.SpacePath, finalDestAbsPath, "restore"); retentionErr != nil {
+					reason, code := retentionFailure(retentionErr)
+					failed = append(failed, restoreFailed{
+						ID:           item.ID,
+						OriginalPath: item.OriginalPath,
+						Reason:       reason,
+						Code:         code,
+					})
+					continue
+				}
+				// 휴지통도 Space 안에 있으므로 덮어쓴 대상 크기만큼만 사용량이 줄어든다.
+				commitUsage := h.trackQuotaUsage(r.Context(), spaceID, absStoragePath, finalDestAbsPath)
 				if overwriteErr := moveWithDestinationSwap(absStoragePath, finalDestAbsPath); overwriteErr != nil {
 					failed = append(failed, restoreFailed{
 						ID:           item.ID,
@@ -416,6 +479,8 @@ func (h *Handler) handleTrashRestore(w http.ResponseWriter, r *http.Request, spa
 					})
 					continue
 				}
+				commitUsage()
+				h.moveFileOwners(r.Context(), spaceID, spaceData.SpacePath, absStoragePath, spaceID, spaceData.SpacePath, finalDestAbsPath)
 				if deleteErr := h.trashService.DeleteTrashItem(r.Context(), item.ID); deleteErr != nil {
 					logging.Event(log.Warn(), logging.ComponentStorage, "warn.trash.metadata_cleanup_failed").
 						Int64("trash_id", item.ID).
@@ -456,6 +521,7 @@ func (h *Handler) handleTrashRestore(w http.ResponseWriter, r *http.Request, spa
 			})
 			continue
 		}
+		h.moveFileOwners(r.Context(), spaceID, spaceData.SpacePath, absStoragePath, spaceID, spaceData.SpacePath, finalDestAbsPath)
 		if deleteErr := h.trashService.DeleteTrashItem(r.Context(), item.ID); deleteErr != nil {
 			logging.Event(log.Warn(), logging.ComponentStorage, "warn.trash.metadata_cleanup_failed").
 				Int64("trash_id", item.ID).
@@ -464,9 +530,6 @@ func (h *Handler) handleTrashRestore(w http.ResponseWriter, r *http.Request, spa
 		}
 		succeeded = append(succeeded, restoreSuccess{ID: item.ID, OriginalPath: item.OriginalPath})
 	}
-	if len(succeeded) > 0 {
-		h.invalidateQuotaForSpaces(spaceID)
-	}
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
@@ -527,7 +590,13 @@ func (h *Handler) handleTrashDelete(w http.ResponseWriter, r *http.Request, spac
 			continue
 		}
 
-		if removeErr := os.RemoveAll(absStoragePath); removeErr != nil && !os.IsNotExist(removeErr) {
+		commitUsage := h.trackQuotaUsage(r.Context(), spaceID, absStoragePath)
+		removeErr := os.RemoveAll(absStoragePath)
+		commitUsage()
+		if removeErr == nil || os.IsNotExist(removeErr) {
+			h.deleteFileOwners(r.Context(), spaceID, spaceData.SpacePath, absStoragePath)
+		}
+		if removeErr != nil && !os.IsNotExist(removeErr) {
 			failed = append(failed, deleteFailed{ID: item.ID, Reason: safeFilesystemReason("Failed to delete trash file", removeErr)})
 			continue
 		}
@@ -537,9 +606,6 @@ func (h *Handler) handleTrashDelete(w http.ResponseWriter, r *http.Request, spac
 		}
 		succeeded = append(succeeded, deleteSuccess{ID: item.ID})
 	}
-	if len(succeeded) > 0 {
-		h.invalidateQuotaForSpaces(spaceID)
-	}
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
@@ -583,7 +649,13 @@ func (h *Handler) handleTrashEmpty(w http.ResponseWriter, r *http.Request, space
 			continue
 		}
 
-		if removeErr := os.RemoveAll(absStoragePath); removeErr != nil && !os.IsNotExist(removeErr) {
+		commitUsage := h.trackQuotaUsage(r.Context(), spaceID, absStoragePath)
+		removeErr := os.RemoveAll(absStoragePath)
+		commitUsage()
+		if removeErr == nil || os.IsNotExist(removeErr) {
+			h.deleteFileOwners(r.Context(), spaceID, spaceData.SpacePath, absStoragePath)
+		}
+		if removeErr != nil && !os.IsNotExist(removeErr) {
 			failed = append(failed, emptyFailed{ID: item.ID, Reason: safeFilesystemReason("Failed to delete trash file", removeErr)})
 			continue
 		}
@@ -593,9 +665,6 @@ func (h *Handler) handleTrashEmpty(w http.ResponseWriter, r *http.Request, space
 		}
 		removed++
 	}
-	if removed > 0 {
-		h.invalidateQuotaForSpaces(spaceID)
-	}
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
@@ -664,7 +733,6 @@ func (h *Handler) handleFileCreateFolder(w http.ResponseWriter, r *http.Request,
 	if err := os.Mkdir(folderPath, 0o755); err != nil {
 		return storageOperationWebError(err, "Failed to create folder")
 	}
-	h.invalidateQuotaForSpaces(spaceID)
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
@@ -685,238 +753,20 @@ func (h *Handler) handleFileCreateFolder(w http.ResponseWriter, r *http.Request,
 }
 
 // handleFileMove: POST /api/spaces/{id}/files/move
-// body: { sources: []string, destination: { spaceId: int64, path: string } }
+// body: { sources: []string, destination: { spaceId: int64, path: string }, async?: bool }
 func (h *Handler) handleFileMove(w http.ResponseWriter, r *http.Request, spaceID int64) *web.Error {
-	if r.Method != http.MethodPost {
-		return &web.Error{Code: http.StatusMethodNotAllowed, Message: "Method not allowed"}
-	}
-
-	srcSpace, webErr := h.getSpace(r, spaceID)
-	if webErr != nil {
-		return webErr
-	}
-
-	var req struct {
-		Sources        []string `json:"sources"`
-		ConflictPolicy string   `json:"conflictPolicy,omitempty"`
-		Destination    struct {
-			SpaceID int64  `json:"spaceId"`
-			Path    string `json:"path"`
-		} `json:"destination"`
-	}
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		return &web.Error{Code: http.StatusBadRequest, Message: "Invalid request body", Err: err}
-	}
-	if len(req.Sources) == 0 {
-		return &web.Error{Code: http.StatusBadRequest, Message: "sources array is required and cannot be empty"}
-	}
-	conflictPolicy, hasConflictPolicy, err := resolveUploadConflictPolicy(req.ConflictPolicy, false)
-	if err != nil {
-		return &web.Error{Code: http.StatusBadRequest, Message: "Invalid conflict policy", Err: err}
-	}
-
-	dstSpaceID := req.Destination.SpaceID
-	if dstSpaceID == 0 {
-		dstSpaceID = spaceID
-	}
-	if webErr := h.ensureSpacePermission(r, dstSpaceID, account.PermissionWrite); webErr != nil {
-		return webErr
-	}
-	if err := ensurePathOutsideTrash(req.Destination.Path); err != nil {
-		return &web.Error{Code: http.StatusForbidden, Message: "Access denied: invalid destination path", Err: err}
-	}
-	dstSpace, webErr := h.getSpace(r, dstSpaceID)
-	if webErr != nil {
-		return webErr
-	}
-
-	absDestDir, err := resolveAbsPath(dstSpace.SpacePath, req.Destination.Path)
-	if err != nil {
-		return &web.Error{Code: http.StatusForbidden, Message: "Access denied: invalid destination path"}
-	}
-
-	destDirInfo, err := os.Stat(absDestDir)
-	if err != nil {
-		return storageAccessWebError(err, "Destination directory not found", "Failed to access destination directory")
-	}
-	if !destDirInfo.IsDir() {
-		return &web.Error{Code: http.StatusBadRequest, Message: "Destination must be a directory"}
-	}
-
-	type moveResult struct {
-		Path   string `json:"path"`
-		Reason string `json:"reason,omitempty"`
-		Code   string `json:"code,omitempty"`
-	}
-	succeeded := []string{}
-	skipped := []string{}
-	failed := []moveResult{}
-	quotaInvalidationTargets := map[int64]struct{}{}
-
-	for _, relSrc := range req.Sources {
-		if err := ensurePathOutsideTrash(relSrc); err != nil {
-			failed = append(failed, moveResult{Path: relSrc, Reason: "Access denied: invalid source path"})
-			continue
-		}
-		absSrc, err := resolveAbsPath(srcSpace.SpacePath, relSrc)
-		if err != nil {
-			failed = append(failed, moveResult{Path: relSrc, Reason: "Access denied: invalid source path"})
-			continue
-		}
-
-		srcInfo, err := os.Stat(absSrc)
-		if err != nil {
-			if os.IsNotExist(err) {
-				failed = append(failed, moveResult{Path: relSrc, Reason: "Source not found"})
-			} else {
-				failed = append(failed, moveResult{Path: relSrc, Reason: safeFilesystemReason("Failed to access source", err)})
-			}
-			continue
-		}
-		sourceSize, sizeErr := h.quotaService.CalculatePathSize(r.Context(), absSrc)
-		if sizeErr != nil {
-			failed = append(failed, moveResult{Path: relSrc, Reason: safeFilesystemReason("Failed to evaluate source size", sizeErr)})
-			continue
-		}
-
-		cleanSrc := filepath.Clean(absSrc)
-		cleanDst := filepath.Clean(absDestDir)
-		if strings.HasPrefix(cleanDst, cleanSrc+string(filepath.Separator)) {
-			failed = append(failed, moveResult{Path: relSrc, Reason: "Cannot move to a subdirectory of itself"})
-			continue
-		}
-
-		destPath := filepath.Join(absDestDir, filepath.Base(absSrc))
-		cleanDestPath := filepath.Clean(destPath)
-		if cleanSrc == cleanDestPath {
-			failed = append(failed, moveResult{
-				Path:   relSrc,
-				Reason: "Cannot move to the same destination",
-				Code:   fileConflictCodeSameDestination,
-			})
-			continue
-		}
-
-		projectedDelta := sourceSize
-		if dstSpaceID == spaceID {
-			projectedDelta = 0
-		}
-
-		if destInfo, statErr := os.Stat(destPath); statErr == nil {
-			if !hasConflictPolicy {
-				failed = append(failed, moveResult{
-					Path:   relSrc,
-					Reason: "Destination path already exists",
-					Code:   fileConflictCodeDestinationExists,
-				})
-				continue
-			}
-
-			switch conflictPolicy {
-			case uploadConflictPolicyOverwrite:
-				if srcInfo.IsDir() != destInfo.IsDir() {
-					failed = append(failed, moveResult{
-						Path:   relSrc,
-						Reason: "Cannot overwrite destination with different type",
-						Code:   fileConflictCodeDestinationTypeMismatch,
-					})
-					continue
-				}
-				existingSize, existingSizeErr := h.quotaService.CalculatePathSize(r.Context(), destPath)
-				if existingSizeErr != nil {
-					failed = append(failed, moveResult{Path: relSrc, Reason: safeFilesystemReason("Failed to evaluate destination size", existingSizeErr)})
-					continue
-				}
-				projectedDelta -= existingSize
-				if webErr := h.ensureSpaceQuotaForWrite(r.Context(), dstSpaceID, projectedDelta); webErr != nil {
-					failed = append(failed, moveResult{
-						Path:   relSrc,
-						Reason: quotaFailureReason(webErr.Err),
-						Code:   fileConflictCodeQuotaExceeded,
-					})
-					continue
-				}
-				if overwriteErr := moveWithDestinationSwap(absSrc, destPath); overwriteErr != nil {
-					failed = append(failed, moveResult{Path: relSrc, Reason: safeFilesystemReason("Failed to overwrite destination", overwriteErr)})
-					continue
-				}
-				succeeded = append(succeeded, relSrc)
-				quotaInvalidationTargets[dstSpaceID] = struct{}{}
-				quotaInvalidationTargets[spaceID] = struct{}{}
-				continue
-			case uploadConflictPolicyRename:
-				renamedPath, _, renameErr := resolveUploadRenamePath(destPath)
-				if renameErr != nil {
-					failed = append(failed, moveResult{Path: relSrc, Reason: safeFilesystemReason("Failed to resolve rename destination", renameErr)})
-					continue
-				}
-				destPath = renamedPath
-			case uploadConflictPolicySkip:
-				skipped = append(skipped, relSrc)
-				continue
-			}
-		} else if !os.IsNotExist(statErr) {
-			failed = append(failed, moveResult{Path: relSrc, Reason: safeFilesystemReason("Failed to access destination", statErr)})
-			continue
-		}
-
-		if webErr := h.ensureSpaceQuotaForWrite(r.Context(), dstSpaceID, projectedDelta); webErr != nil {
-			failed = append(failed, moveResult{
-				Path:   relSrc,
-				Reason: quotaFailureReason(webErr.Err),
-				Code:   fileConflictCodeQuotaExceeded,
-			})
-			continue
-		}
-
-		if err := os.Rename(absSrc, destPath); err != nil {
-			failed = append(failed, moveResult{Path: relSrc, Reason: safeFilesystemReason("Failed to move", err)})
-		} else {
-			succeeded = append(succeeded, relSrc)
-			quotaInvalidationTargets[dstSpaceID] = struct{}{}
-			quotaInvalidationTargets[spaceID] = struct{}{}
-		}
-	}
-	if len(quotaInvalidationTargets) > 0 {
-		spaceIDs := make([]int64, 0, len(quotaInvalidationTargets))
-		for targetID := range quotaInvalidationTargets {
-			spaceIDs = append(spaceIDs, targetID)
-		}
-		h.invalidateQuotaForSpaces(spaceIDs...)
-	}
-
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"succeeded": succeeded,
-		"failed":    failed,
-		"skipped":   skipped,
-	})
-	result := audit.ResultSuccess
-	if len(succeeded) == 0 && len(failed) > 0 {
-		result = audit.ResultFailure
-	} else if len(failed) > 0 || len(skipped) > 0 {
-		result = audit.ResultPartial
-	}
-	h.recordSpaceAudit(r, audit.Event{
-		Action: "file.move",
-		Result: result,
-		Target: req.Destination.Path,
-		Metadata: map[string]any{
-			"sourceCount": len(req.Sources),
-			"succeeded":   len(succeeded),
-			"failed":      len(failed),
-			"skipped":     len(skipped),
-			"fromSpaceId": spaceID,
-			"toSpaceId":   dstSpaceID,
-		},
-	}, spaceID)
-	return nil
+	return h.handleFileTransfer(w, r, spaceID, fileTransferOperationMove)
 }
 
 // handleFileCopy: POST /api/spaces/{id}/files/copy
-// body: { sources: []string, destination: { spaceId: int64, path: string } }
+// body: { sources: []string, destination: { spaceId: int64, path: string }, async?: bool }
 func (h *Handler) handleFileCopy(w http.ResponseWriter, r *http.Request, spaceID int64) *web.Error {
+	return h.handleFileTransfer(w, r, spaceID, fileTransferOperationCopy)
+}
+
+// handleFileTransfer는 move/copy 공통 처리입니다. async가 true이면 작업 큐에 등록하고
+// 202와 작업 정보를 반환하며, 결과와 감사 기록은 작업이 끝날 때 남깁니다.
+func (h *Handler) handleFileTransfer(w http.ResponseWriter, r *http.Request, spaceID int64, operation fileTransferOperation) *web.Error {
 	if r.Method != http.MethodPost {
 		return &web.Error{Code: http.StatusMethodNotAllowed, Message: "Method not allowed"}
 	}
@@ -927,9 +777,11 @@ func (h *Handler) handleFileCopy(w http.ResponseWriter, r *http.Request, spaceID
 	}
 
 	var req struct {
-		Sources        []string `json:"sources"`
-		ConflictPolicy string   `json:"conflictPolicy,omitempty"`
-		Destination    struct {
+		Sources             []string          `json:"sources"`
+		ConflictPolicy      string            `json:"conflictPolicy,omitempty"`
+		ConflictResolutions map[string]string `json:"conflictResolutions,omitempty"`
+		Async               bool              `json:"async,omitempty"`
+		Destination         struct {
 			SpaceID int64  `json:"spaceId"`
 			Path    string `json:"path"`
 		} `json:"destination"`
@@ -944,6 +796,10 @@ func (h *Handler) handleFileCopy(w http.ResponseWriter, r *http.Request, spaceID
 	if err != nil {
 		return &web.Error{Code: http.StatusBadRequest, Message: "Invalid conflict policy", Err: err}
 	}
+	resolutions, err := parseConflictResolutions(req.ConflictResolutions)
+	if err != nil {
+		return &web.Error{Code: http.StatusBadRequest, Message: "Invalid conflict policy", Err: err}
+	}
 
 	dstSpaceID := req.Destination.SpaceID
 	if dstSpaceID == 0 {
@@ -952,6 +808,11 @@ func (h *Handler) handleFileCopy(w http.ResponseWriter, r *http.Request, spaceID
 	if webErr := h.ensureSpacePermission(r, dstSpaceID, account.PermissionWrite); webErr != nil {
 		return webErr
 	}
+	release, webErr := h.beginSpaceWrite(r.Context(), dstSpaceID)
+	if webErr != nil {
+		return webErr
+	}
+	defer release()
 	if err := ensurePathOutsideTrash(req.Destination.Path); err != nil {
 		return &web.Error{Code: http.StatusForbidden, Message: "Access denied: invalid destination path", Err: err}
 	}
@@ -973,170 +834,54 @@ func (h *Handler) handleFileCopy(w http.ResponseWriter, r *http.Request, spaceID
 		return &web.Error{Code: http.StatusBadRequest, Message: "Destination must be a directory"}
 	}
 
-	type copyResult struct {
-		Path   string `json:"path"`
-		Reason string `json:"reason,omitempty"`
-		Code   string `json:"code,omitempty"`
+	if req.Async {
+		return h.enqueueFileTransferJob(w, r, spaceID, fileTransferPayload{
+			Operation:           operation,
+			Sources:             req.Sources,
+			DestinationSpaceID:  dstSpaceID,
+			DestinationPath:     req.Destination.Path,
+			ConflictPolicy:      req.ConflictPolicy,
+			ConflictResolutions: req.ConflictResolutions,
+		})
 	}
-	succeeded := []string{}
-	skipped := []string{}
-	failed := []copyResult{}
-	quotaInvalidationTargets := map[int64]struct{}{}
 
+	actor, _ := claimsUsernameFromRequest(r)
+	transfer := &fileTransfer{
+		Operation:          operation,
+		SourceSpaceID:      spaceID,
+		SourceRoot:         srcSpace.SpacePath,
+		DestinationSpaceID: dstSpaceID,
+		DestinationRoot:    dstSpace.SpacePath,
+		DestinationDir:     absDestDir,
+		Actor:              actor,
+		ConflictPolicy:     conflictPolicy,
+		HasConflictPolicy:  hasConflictPolicy,
+		Resolutions:        resolutions,
+	}
+	result := newFileTransferResult()
 	for _, relSrc := range req.Sources {
-		if err := ensurePathOutsideTrash(relSrc); err != nil {
-			failed = append(failed, copyResult{Path: relSrc, Reason: "Access denied: invalid source path"})
-			continue
-		}
-		absSrc, err := resolveAbsPath(srcSpace.SpacePath, relSrc)
-		if err != nil {
-			failed = append(failed, copyResult{Path: relSrc, Reason: "Access denied: invalid source path"})
-			continue
-		}
-
-		sourceInfo, err := os.Stat(absSrc)
+		outcome, err := h.transferItem(r.Context(), transfer, relSrc)
 		if err != nil {
-			if os.IsNotExist(err) {
-				failed = append(failed, copyResult{Path: relSrc, Reason: "Source not found"})
-			} else {
-				failed = append(failed, copyResult{Path: relSrc, Reason: safeFilesystemReason("Failed to access source", err)})
+			// 요청이 끊기면 남은 항목은 처리하지 않고 실패로 기록합니다.
+			outcome = fileTransferOutcome{
+				Status:  fileTransferItemFailed,
+				Failure: fileTransferFailure{Path: relSrc, Reason: "Request canceled"},
 			}
-			continue
-		}
-		sourceSize, sizeErr := h.quotaService.CalculatePathSize(r.Context(), absSrc)
-		if sizeErr != nil {
-			failed = append(failed, copyResult{Path: relSrc, Reason: safeFilesystemReason("Failed to evaluate source size", sizeErr)})
-			continue
-		}
-
-		cleanSrc := filepath.Clean(absSrc)
-		cleanDst := filepath.Clean(absDestDir)
-		if strings.HasPrefix(cleanDst, cleanSrc+string(filepath.Separator)) {
-			failed = append(failed, copyResult{Path: relSrc, Reason: "Cannot copy to a subdirectory of itself"})
-			continue
-		}
-
-		destPath := filepath.Join(absDestDir, filepath.Base(absSrc))
-		cleanDestPath := filepath.Clean(destPath)
-		if cleanSrc == cleanDestPath {
-			failed = append(failed, copyResult{
-				Path:   relSrc,
-				Reason: "Cannot copy to the same destination",
-				Code:   fileConflictCodeSameDestination,
-			})
-			continue
-		}
-
-		projectedDelta := sourceSize
-		if destInfo, statErr := os.Stat(destPath); statErr == nil {
-			if !hasConflictPolicy {
-				failed = append(failed, copyResult{
-					Path:   relSrc,
-					Reason: "Destination path already exists",
-					Code:   fileConflictCodeDestinationExists,
-				})
-				continue
-			}
-
-			switch conflictPolicy {
-			case uploadConflictPolicyOverwrite:
-				if sourceInfo.IsDir() != destInfo.IsDir() {
-					failed = append(failed, copyResult{
-						Path:   relSrc,
-						Reason: "Cannot overwrite destination with different type",
-						Code:   fileConflictCodeDestinationTypeMismatch,
-					})
-					continue
-				}
-				existingSize, existingSizeErr := h.quotaService.CalculatePathSize(r.Context(), destPath)
-				if existingSizeErr != nil {
-					failed = append(failed, copyResult{Path: relSrc, Reason: safeFilesystemReason("Failed to evaluate destination size", existingSizeErr)})
-					continue
-				}
-				projectedDelta -= existingSize
-				if webErr := h.ensureSpaceQuotaForWrite(r.Context(), dstSpaceID, projectedDelta); webErr != nil {
-					failed = append(failed, copyResult{
-						Path:   relSrc,
-						Reason: quotaFailureReason(webErr.Err),
-						Code:   fileConflictCodeQuotaExceeded,
-					})
-					continue
-				}
-				if overwriteErr := copyWithDestinationSwap(absSrc, destPath, sourceInfo.IsDir()); overwriteErr != nil {
-					failed = append(failed, copyResult{Path: relSrc, Reason: safeFilesystemReason("Failed to overwrite destination", overwriteErr)})
-					continue
-				}
-				succeeded = append(succeeded, relSrc)
-				quotaInvalidationTargets[dstSpaceID] = struct{}{}
-				continue
-			case uploadConflictPolicyRename:
-				renamedPath, _, renameErr := resolveUploadRenamePath(destPath)
-				if renameErr != nil {
-					failed = append(failed, copyResult{Path: relSrc, Reason: safeFilesystemReason("Failed to resolve rename destination", renameErr)})
-					continue
-				}
-				destPath = renamedPath
-			case uploadConflictPolicySkip:
-				skipped = append(skipped, relSrc)
-				continue
-			}
-		} else if !os.IsNotExist(statErr) {
-			failed = append(failed, copyResult{Path: relSrc, Reason: safeFilesystemReason("Failed to access destination", statErr)})
-			continue
-		}
-
-		if webErr := h.ensureSpaceQuotaForWrite(r.Context(), dstSpaceID, projectedDelta); webErr != nil {
-			failed = append(failed, copyResult{
-				Path:   relSrc,
-				Reason: quotaFailureReason(webErr.Err),
-				Code:   fileConflictCodeQuotaExceeded,
-			})
-			continue
-		}
-
-		var copyErr error
-		if sourceInfo.IsDir() {
-			copyErr = copyDir(absSrc, destPath)
-		} else {
-			copyErr = copyFile(absSrc, destPath)
-		}
-		if copyErr != nil {
-			failed = append(failed, copyResult{Path: relSrc, Reason: safeFilesystemReason("Failed to copy", copyErr)})
-		} else {
-			succeeded = append(succeeded, relSrc)
-			quotaInvalidationTargets[dstSpaceID] = struct{}{}
 		}
+		result.add(relSrc, outcome)
 	}
-	if len(quotaInvalidationTargets) > 0 {
-		spaceIDs := make([]int64, 0, len(quotaInvalidationTargets))
-		for targetID := range quotaInvalidationTargets {
-			spaceIDs = append(spaceIDs, targetID)
-		}
-		h.invalidateQuotaForSpaces(spaceIDs...)
-	}
-
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"succeeded": succeeded,
-		"failed":    failed,
-		"skipped":   skipped,
-	})
-	result := audit.ResultSuccess
-	if len(succeeded) == 0 && len(failed) > 0 {
-		result = audit.ResultFailure
-	} else if len(failed) > 0 || len(skipped) > 0 {
-		result = audit.ResultPartial
-	}
+	json.NewEncoder(w).Encode(result)
 	h.recordSpaceAudit(r, audit.Event{
-		Action: "file.copy",
-		Result: result,
+		Action: "file." + string(operation),
+		Result: result.auditResult(),
 		Target: req.Destination.Path,
 		Metadata: map[string]any{
 			"sourceCount": len(req.Sources),
-			"succeeded":   len(succeeded),
-			"failed":      len(failed),
-			"skipped":     len(skipped),
+			"succeeded":   len(result.Succeeded),
+			"failed":      len(result.Failed),
+			"skipped":     len(result.Skipped),
 			"fromSpaceId": spaceID,
 			"toSpaceId":   dstSpaceID,
 		},
@@ -1145,55 +890,9 @@ func (h *Handler) handleFileCopy(w http.ResponseWriter, r *http.Request, spaceID
 }
 
 func copyFile(src, dst string) error {
-	sourceFile, err := os.Open(src)
-	if err != nil {
-		return err
-	}
-	defer sourceFile.Close()
-
-	destFile, err := os.Create(dst)
-	if err != nil {
-		return err
-	}
-	defer destFile.Close()
-
-	if _, err = io.Copy(destFile, sourceFile); err != nil {
-		return err
-	}
-
-	sourceInfo, err := os.Stat(src)
-	if err != nil {
-		return err
-	}
-	return os.Chmod(dst, sourceInfo.Mode())
+	return copyFileWithProgress(context.Background(), src, dst, nil)
 }
 
 func copyDir(src, dst string) error {
-	srcInfo, err := os.Stat(src)
-	if err != nil {
-		return err
-	}
-	if err := os.MkdirAll(dst, srcInfo.Mode()); err != nil {
-		return err
-	}
-
-	entries, err := os.ReadDir(src)
-	if err != nil {
-		return err
-	}
-
-	for _, entry := range entries {
-		srcPath := filepath.Join(src, entry.Name())
-		dstPath := filepath.Join(dst, entry.Name())
-		if entry.IsDir() {
-			if err := copyDir(srcPath, dstPath); err != nil {
-				return err
-			}
-		} else {
-			if err := copyFile(srcPath, dstPath); err != nil {
-				return err
-			}
-		}
-	}
-	return nil
+	return copyDirWithProgress(context.Background(), src, dst, nil)
 }
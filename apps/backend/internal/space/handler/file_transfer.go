@@ -0,0 +1,490 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"github.com/rs/zerolog/log"
+	"taeu.kr/cohesion/internal/audit"
+	"taeu.kr/cohesion/internal/platform/logging"
+	"taeu.kr/cohesion/internal/space"
+)
+
+type fileTransferOperation string
+
+const (
+	fileTransferOperationMove fileTransferOperation = "move"
+	fileTransferOperationCopy fileTransferOperation = "copy"
+)
+
+type fileTransferItemStatus string
+
+const (
+	fileTransferItemSucceeded fileTransferItemStatus = "succeeded"
+	fileTransferItemSkipped   fileTransferItemStatus = "skipped"
+	fileTransferItemFailed    fileTransferItemStatus = "failed"
+)
+
+// transferProgressFunc는 복사를 마친 파일 수와 복사한 바이트를 보고받습니다.
+type transferProgressFunc func(items int, bytes int64)
+
+type fileTransferFailure struct {
+	Path   string `json:"path"`
+	Reason string `json:"reason,omitempty"`
+	Code   string `json:"code,omitempty"`
+}
+
+type fileTransferOutcome struct {
+	Status  fileTransferItemStatus
+	Failure fileTransferFailure
+	// UsageDelta는 항목 처리로 늘어난(음수면 줄어든) Space 사용량입니다. 압축 해제에서만 채웁니다.
+	UsageDelta int64
+	// FileDelta는 항목 처리로 새로 생긴 파일 수입니다. 압축 해제에서만 채웁니다.
+	FileDelta int64
+	// WrittenPath는 새로 쓴 파일의 실제 경로입니다. 압축 해제에서만 채웁니다.
+	WrittenPath string
+}
+
+// fileTransferResult는 move/copy 응답 및 작업 결과의 항목별 처리 결과입니다.
+type fileTransferResult struct {
+	Succeeded []string              `json:"succeeded"`
+	Skipped   []string              `json:"skipped"`
+	Failed    []fileTransferFailure `json:"failed"`
+}
+
+// fileTransfer는 원본 Space의 항목들을 대상 디렉터리로 옮기거나 복사하는 한 번의 요청입니다.
+type fileTransfer struct {
+	Operation          fileTransferOperation
+	SourceSpaceID      int64
+	SourceRoot         string
+	DestinationSpaceID int64
+	DestinationRoot    string
+	DestinationDir     string
+	// Actor는 복사본의 소유자로 기록하고 사용자 할당량을 확인할 사용자입니다.
+	Actor             string
+	ConflictPolicy    uploadConflictPolicy
+	HasConflictPolicy bool
+	// Resolutions는 원본 경로별 충돌 처리 방식으로, 요청 전체 정책보다 우선합니다.
+	Resolutions map[string]uploadConflictPolicy
+	Progress    transferProgressFunc
+}
+
+func newFileTransferResult() fileTransferResult {
+	return fileTransferResult{
+		Succeeded: []string{},
+		Skipped:   []string{},
+		Failed:    []fileTransferFailure{},
+	}
+}
+
+func (r *fileTransferResult) add(relSrc string, outcome fileTransferOutcome) {
+	switch outcome.Status {
+	case fileTransferItemSucceeded:
+		r.Succeeded = append(r.Succeeded, relSrc)
+	case fileTransferItemSkipped:
+		r.Skipped = append(r.Skipped, relSrc)
+	default:
+		r.Failed = append(r.Failed, outcome.Failure)
+	}
+}
+
+// processed는 이미 결과가 기록된 원본 경로 집합입니다.
+func (r *fileTransferResult) processed() map[string]struct{} {
+	done := make(map[string]struct{}, len(r.Succeeded)+len(r.Skipped)+len(r.Failed))
+	for _, path := range r.Succeeded {
+		done[path] = struct{}{}
+	}
+	for _, path := range r.Skipped {
+		done[path] = struct{}{}
+	}
+	for _, failure := range r.Failed {
+		done[failure.Path] = struct{}{}
+	}
+	return done
+}
+
+func (r *fileTransferResult) auditResult() audit.Result {
+	if len(r.Succeeded) == 0 && len(r.Failed) > 0 {
+		return audit.ResultFailure
+	}
+	if len(r.Failed) > 0 || len(r.Skipped) > 0 {
+		return audit.ResultPartial
+	}
+	return audit.ResultSuccess
+}
+
+func (r *fileTransferResult) auditFailedItems() []any {
+	return auditFailureItems(r.Failed)
+}
+
+// auditFailureItems는 감사 로그에 남길 항목별 실패 목록입니다.
+func auditFailureItems(failures []fileTransferFailure) []any {
+	items := make([]any, 0, len(failures))
+	for _, failure := range failures {
+		item := map[string]any{
+			"path":   failure.Path,
+			"reason": failure.Reason,
+		}
+		if failure.Code != "" {
+			item["code"] = failure.Code
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+func parseConflictResolutions(raw map[string]string) (map[string]uploadConflictPolicy, error) {
+	if len(raw) == 0 {
+		return nil, nil
+	}
+	resolutions := make(map[string]uploadConflictPolicy, len(raw))
+	for path, value := range raw {
+		policy, ok, err := resolveUploadConflictPolicy(value, false)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			resolutions[path] = policy
+		}
+	}
+	return resolutions, nil
+}
+
+func (t *fileTransfer) conflictPolicyFor(relSrc string) (uploadConflictPolicy, bool) {
+	if policy, ok := t.Resolutions[relSrc]; ok {
+		return policy, true
+	}
+	return t.ConflictPolicy, t.HasConflictPolicy
+}
+
+// quotaInvalidationTargets는 항목 처리로 사용량이 바뀔 수 있는 Space 목록입니다.
+func (t *fileTransfer) quotaInvalidationTargets() []int64 {
+	if t.Operation == fileTransferOperationMove {
+		return []int64{t.DestinationSpaceID, t.SourceSpaceID}
+	}
+	return []int64{t.DestinationSpaceID}
+}
+
+// transferItem은 원본 항목 하나를 처리합니다. ctx가 취소되면 만들던 대상은 지우고 ctx 오류를 반환하며,
+// 이때 원본은 그대로 남습니다.
+func (h *Handler) transferItem(ctx context.Context, t *fileTransfer, relSrc string) (fileTransferOutcome, error) {
+	verb := string(t.Operation)
+	fail := func(reason string, code string) (fileTransferOutcome, error) {
+		return fileTransferOutcome{
+			Status:  fileTransferItemFailed,
+			Failure: fileTransferFailure{Path: relSrc, Reason: reason, Code: code},
+		}, nil
+	}
+
+	if err := ensurePathOutsideTrash(relSrc); err != nil {
+		return fail("Access denied: invalid source path", "")
+	}
+	absSrc, err := resolveAbsPath(t.SourceRoot, relSrc)
+	if err != nil {
+		return fail("Access denied: invalid source path", "")
+	}
+
+	srcInfo, err := os.Stat(absSrc)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fail("Source not found", "")
+		}
+		return fail(safeFilesystemReason("Failed to access source", err), "")
+	}
+	source, sizeErr := h.quotaService.CalculatePathUsage(ctx, absSrc)
+	if sizeErr != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return fileTransferOutcome{}, ctxErr
+		}
+		return fail(safeFilesystemReason("Failed to evaluate source size", sizeErr), "")
+	}
+
+	cleanSrc := filepath.Clean(absSrc)
+	cleanDst := filepath.Clean(t.DestinationDir)
+	if strings.HasPrefix(cleanDst, cleanSrc+string(filepath.Separator)) {
+		return fail(fmt.Sprintf("Cannot %s to a subdirectory of itself", verb), "")
+	}
+
+	destPath := filepath.Join(t.DestinationDir, filepath.Base(absSrc))
+	if cleanSrc == filepath.Clean(destPath) {
+		return fail(fmt.Sprintf("Cannot %s to the same destination", verb), fileConflictCodeSameDestination)
+	}
+
+	projectedDelta := source.Bytes
+	projectedFiles := source.Files
+	if t.Operation == fileTransferOperationMove && t.DestinationSpaceID == t.SourceSpaceID {
+		projectedDelta = 0
+	}
+
+	overwrite := false
+	var existing space.PathUsage
+	if destInfo, statErr := os.Stat(destPath); statErr == nil {
+		policy, hasPolicy := t.conflictPolicyFor(relSrc)
+		if !hasPolicy {
+			return fail("Destination path already exists", fileConflictCodeDestinationExists)
+		}
+
+		switch policy {
+		case uploadConflictPolicyOverwrite:
+			if srcInfo.IsDir() != destInfo.IsDir() {
+				return fail("Cannot overwrite destination with different type", fileConflictCodeDestinationTypeMismatch)
+			}
+			var existingSizeErr error
+			existing, existingSizeErr = h.quotaService.CalculatePathUsage(ctx, destPath)
+			if existingSizeErr != nil {
+				return fail(safeFilesystemReason("Failed to evaluate destination size", existingSizeErr), "")
+			}
+			projectedDelta -= existing.Bytes
+			projectedFiles -= existing.Files
+			overwrite = true
+		case uploadConflictPolicyRename:
+			renamedPath, _, renameErr := resolveUploadRenamePath(destPath)
+			if renameErr != nil {
+				return fail(safeFilesystemReason("Failed to resolve rename destination", renameErr), "")
+			}
+			destPath = renamedPath
+		case uploadConflictPolicySkip:
+			return fileTransferOutcome{Status: fileTransferItemSkipped}, nil
+		}
+	} else if !os.IsNotExist(statErr) {
+		return fail(safeFilesystemReason("Failed to access destination", statErr), "")
+	}
+
+	if t.Operation == fileTransferOperationMove {
+		if err := h.checkRetentionAs(ctx, t.Actor, t.SourceSpaceID, t.SourceRoot, absSrc, "move"); err != nil {
+			return fail(retentionFailure(err))
+		}
+	}
+	if overwrite {
+		if err := h.checkRetentionAs(ctx, t.Actor, t.DestinationSpaceID, t.DestinationRoot, destPath, "overwrite"); err != nil {
+			return fail(retentionFailure(err))
+		}
+	}
+
+	if webErr := h.ensureSpaceQuotaForWrite(ctx, t.DestinationSpaceID, projectedDelta); webErr != nil {
+		return fail(quotaFailureReason(webErr.Err), fileConflictCodeQuotaExceeded)
+	}
+	if t.Operation == fileTransferOperationCopy || t.DestinationSpaceID != t.SourceSpaceID {
+		if webErr := h.ensureSpaceFileQuota(ctx, t.DestinationSpaceID, projectedFiles); webErr != nil {
+			return fail(quotaFailureReason(webErr.Err), fileConflictCodeQuotaExceeded)
+		}
+		// 새로 생기는 파일은 모두 대상 Space의 업로드 정책을 따라야 합니다.
+		if err := h.uploadPolicies.CheckPath(ctx, t.DestinationSpaceID, absSrc); err != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return fileTransferOutcome{}, ctxErr
+			}
+			return fail(uploadPolicyFailure(err))
+		}
+	}
+	// 이동한 파일은 원래 소유자를 유지하므로 사용자 할당량은 복사본을 만들 때만 확인합니다.
+	if t.Operation == fileTransferOperationCopy {
+		if webErr := h.ensureUserQuotaForWrite(ctx, t.Actor, t.DestinationSpaceID, projectedDelta); webErr != nil {
+			return fail(userQuotaFailureReason(webErr.Err), fileConflictCodeQuotaExceeded)
+		}
+	}
+
+	var transferErr error
+	switch {
+	case t.Operation == fileTransferOperationMove:
+		transferErr = moveTransferSource(ctx, absSrc, destPath, srcInfo.IsDir(), overwrite, t.Progress)
+	case overwrite:
+		transferErr = copyWithDestinationSwapContext(ctx, absSrc, destPath, srcInfo.IsDir(), t.Progress)
+	default:
+		transferErr = copyTransferSource(ctx, absSrc, destPath, srcInfo.IsDir(), t.Progress)
+	}
+	if transferErr != nil {
+		if errors.Is(transferErr, context.Canceled) || errors.Is(transferErr, context.DeadlineExceeded) {
+			return fileTransferOutcome{}, transferErr
+		}
+		if t.Operation == fileTransferOperationMove {
+			// 다른 파일시스템으로 옮기다 실패하면 원본 일부만 지워졌을 수 있어 변화량을 알 수 없다.
+			h.invalidateQuotaForSpaces(t.quotaInvalidationTargets()...)
+		}
+		if overwrite {
+			return fail(safeFilesystemReason("Failed to overwrite destination", transferErr), "")
+		}
+		if t.Operation == fileTransferOperationMove {
+			return fail(safeFilesystemReason("Failed to move", transferErr), "")
+		}
+		return fail(safeFilesystemReason("Failed to copy", transferErr), "")
+	}
+	h.applyTransferUsage(ctx, t, source, existing)
+	if t.Operation == fileTransferOperationMove {
+		h.moveFileOwners(ctx, t.SourceSpaceID, t.SourceRoot, absSrc, t.DestinationSpaceID, t.DestinationRoot, destPath)
+	} else {
+		h.recordFileOwner(ctx, t.Actor, t.DestinationSpaceID, t.DestinationRoot, destPath)
+	}
+	return fileTransferOutcome{Status: fileTransferItemSucceeded}, nil
+}
+
+// applyTransferUsage는 성공한 항목의 크기 변화를 원본/대상 Space 사용량에 반영합니다.
+// 덮어쓴 대상은 사라지고, 다른 Space로 옮긴 원본은 원본 Space에서 빠집니다.
+func (h *Handler) applyTransferUsage(ctx context.Context, t *fileTransfer, source space.PathUsage, replaced space.PathUsage) {
+	if t.Operation == fileTransferOperationMove && t.DestinationSpaceID == t.SourceSpaceID {
+		h.adjustQuotaUsage(ctx, t.DestinationSpaceID, -replaced.Bytes, -replaced.Files)
+		return
+	}
+	h.adjustQuotaUsage(ctx, t.DestinationSpaceID, source.Bytes-replaced.Bytes, source.Files-replaced.Files)
+	if t.Operation == fileTransferOperationMove {
+		h.adjustQuotaUsage(ctx, t.SourceSpaceID, -source.Bytes, -source.Files)
+	}
+}
+
+// copyTransferSource는 새 대상 경로로 복사합니다. 실패하거나 취소되면 만들던 대상을 지웁니다.
+func copyTransferSource(ctx context.Context, srcPath, destPath string, isDir bool, progress transferProgressFunc) error {
+	var copyErr error
+	if isDir {
+		copyErr = copyDirWithProgress(ctx, srcPath, destPath, progress)
+	} else {
+		copyErr = copyFileWithProgress(ctx, srcPath, destPath, progress)
+	}
+	if copyErr != nil {
+		if removeErr := os.RemoveAll(destPath); removeErr != nil {
+			logging.Event(log.Warn(), logging.ComponentStorage, "warn.storage.cleanup_failed").
+				Str("operation", "copy-partial-cleanup").
+				Str("path", destPath).
+				Err(removeErr).
+				Msg("cleanup failed")
+		}
+		return copyErr
+	}
+	return nil
+}
+
+// moveTransferSource는 rename으로 옮기고, 다른 파일시스템이면 복사 후 원본을 지웁니다.
+func moveTransferSource(ctx context.Context, srcPath, destPath string, isDir bool, overwrite bool, progress transferProgressFunc) error {
+	var renameErr error
+	if overwrite {
+		renameErr = moveWithDestinationSwap(srcPath, destPath)
+	} else {
+		renameErr = os.Rename(srcPath, destPath)
+	}
+	if renameErr == nil || !isCrossDeviceError(renameErr) {
+		return renameErr
+	}
+
+	var copyErr error
+	if overwrite {
+		copyErr = copyWithDestinationSwapContext(ctx, srcPath, destPath, isDir, progress)
+	} else {
+		copyErr = copyTransferSource(ctx, srcPath, destPath, isDir, progress)
+	}
+	if copyErr != nil {
+		return copyErr
+	}
+	if err := os.RemoveAll(srcPath); err != nil {
+		return fmt.Errorf("failed to remove moved source: %w", err)
+	}
+	return nil
+}
+
+func isCrossDeviceError(err error) bool {
+	return errors.Is(err, syscall.EXDEV)
+}
+
+// measureTransferSource는 진행률 계산을 위해 원본의 파일 수와 바이트를 셉니다.
+func measureTransferSource(ctx context.Context, absPath string) (int, int64, error) {
+	items := 0
+	var bytes int64
+	err := filepath.Walk(absPath, func(_ string, info os.FileInfo, walkErr error) error {
+		if walkErr != nil {
+			return walkErr
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if info.IsDir() || info.Mode()&os.ModeSymlink != 0 {
+			return nil
+		}
+		items++
+		bytes += info.Size()
+		return nil
+	})
+	return items, bytes, err
+}
+
+type progressWriter struct {
+	writer   io.Writer
+	progress transferProgressFunc
+}
+
+func (w *progressWriter) Write(p []byte) (int, error) {
+	n, err := w.writer.Write(p)
+	if n > 0 {
+		w.progress(0, int64(n))
+	}
+	return n, err
+}
+
+func copyFileWithProgress(ctx context.Context, src, dst string, progress transferProgressFunc) error {
+	sourceFile, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer sourceFile.Close()
+
+	destFile, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	defer destFile.Close()
+
+	var writer io.Writer = destFile
+	if progress != nil {
+		writer = &progressWriter{writer: destFile, progress: progress}
+	}
+	if _, err := copyWithContext(ctx, writer, sourceFile); err != nil {
+		return err
+	}
+
+	sourceInfo, err := os.Stat(src)
+	if err != nil {
+		return err
+	}
+	if err := os.Chmod(dst, sourceInfo.Mode()); err != nil {
+		return err
+	}
+	if progress != nil {
+		progress(1, 0)
+	}
+	return nil
+}
+
+func copyDirWithProgress(ctx context.Context, src, dst string, progress transferProgressFunc) error {
+	srcInfo, err := os.Stat(src)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dst, srcInfo.Mode()); err != nil {
+		return err
+	}
+
+	entries, err := os.ReadDir(src)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		srcPath := filepath.Join(src, entry.Name())
+		dstPath := filepath.Join(dst, entry.Name())
+		if entry.IsDir() {
+			if err := copyDirWithProgress(ctx, srcPath, dstPath, progress); err != nil {
+				return err
+			}
+		} else {
+			if err := copyFileWithProgress(ctx, srcPath, dstPath, progress); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
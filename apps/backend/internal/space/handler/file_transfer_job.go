@@ -0,0 +1,303 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	"taeu.kr/cohesion/internal/audit"
+	"taeu.kr/cohesion/internal/auth"
+	"taeu.kr/cohesion/internal/job"
+	"taeu.kr/cohesion/internal/platform/logging"
+	"taeu.kr/cohesion/internal/platform/web"
+)
+
+const (
+	// FileTransferJobType은 대용량 move/copy를 백그라운드에서 처리하는 작업 유형입니다.
+	FileTransferJobType = "file.transfer"
+
+	defaultFileTransferWorkerLimit = 2
+	defaultFileTransferMaxAttempts = 3
+)
+
+// fileTransferPayload는 작업 큐에 저장되는 move/copy 입력입니다. 원본 Space는 작업의 SpaceID입니다.
+type fileTransferPayload struct {
+	Operation           fileTransferOperation `json:"operation"`
+	Sources             []string              `json:"sources"`
+	DestinationSpaceID  int64                 `json:"destinationSpaceId"`
+	DestinationPath     string                `json:"destinationPath"`
+	ConflictPolicy      string                `json:"conflictPolicy,omitempty"`
+	ConflictResolutions map[string]string     `json:"conflictResolutions,omitempty"`
+}
+
+// fileTransferJobResult는 항목이 끝날 때마다 저장하는 작업 출력입니다.
+// 재시도나 서버 재시작 후에는 이미 기록된 항목을 건너뛰고 이어서 처리합니다.
+type fileTransferJobResult struct {
+	fileTransferResult
+	ProcessedItems int   `json:"processedItems"`
+	ProcessedBytes int64 `json:"processedBytes"`
+}
+
+func (h *Handler) registerFileTransferJobType() {
+	h.jobs.Register(FileTransferJobType, job.TypeConfig{
+		Concurrency: defaultFileTransferWorkerLimit,
+		MaxAttempts: defaultFileTransferMaxAttempts,
+		Run:         h.runFileTransferJob,
+		OnFinish:    h.finishFileTransferJob,
+	})
+}
+
+func (h *Handler) enqueueFileTransferJob(w http.ResponseWriter, r *http.Request, spaceID int64, payload fileTransferPayload) *web.Error {
+	claims, ok := auth.ClaimsFromContext(r.Context())
+	if !ok {
+		return &web.Error{Code: http.StatusUnauthorized, Message: "Unauthorized"}
+	}
+
+	transferJob, err := h.jobs.Enqueue(r.Context(), job.EnqueueRequest{
+		Type:      FileTransferJobType,
+		Owner:     claims.Username,
+		SpaceID:   &spaceID,
+		RequestID: strings.TrimSpace(r.Header.Get("X-Request-Id")),
+		Payload:   payload,
+	})
+	if err != nil {
+		return &web.Error{Code: http.StatusInternalServerError, Message: "Failed to create transfer job", Err: err}
+	}
+	h.logFileTransferJobEvent("info.transfer.job_created", transferJob, nil)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	if err := json.NewEncoder(w).Encode(transferJob); err != nil {
+		return &web.Error{Code: http.StatusInternalServerError, Message: "Failed to encode transfer job response", Err: err}
+	}
+	return nil
+}
+
+// runFileTransferJob은 작업 큐에서 호출되는 move/copy 실행 함수입니다.
+// 실행할 때마다 Space 상태와 대상 경로를 다시 확인합니다.
+func (h *Handler) runFileTransferJob(ctx context.Context, run *job.Run) error {
+	current := run.Job()
+	if current == nil || current.SpaceID == nil {
+		return job.Permanent(errors.New("transfer job has no space"))
+	}
+
+	var payload fileTransferPayload
+	if err := current.DecodePayload(&payload); err != nil {
+		return job.Permanent(fmt.Errorf("invalid transfer job payload: %w", err))
+	}
+	conflictPolicy, hasConflictPolicy, err := resolveUploadConflictPolicy(payload.ConflictPolicy, false)
+	if err != nil {
+		return job.Permanent(errors.New("Invalid conflict policy"))
+	}
+	resolutions, err := parseConflictResolutions(payload.ConflictResolutions)
+	if err != nil {
+		return job.Permanent(errors.New("Invalid conflict policy"))
+	}
+
+	srcSpace, err := h.spaceService.GetSpaceByID(ctx, *current.SpaceID)
+	if err != nil {
+		return job.Permanent(errors.New("Source space not found"))
+	}
+	dstSpace, err := h.spaceService.GetSpaceByID(ctx, payload.DestinationSpaceID)
+	if err != nil {
+		return job.Permanent(errors.New("Destination space not found"))
+	}
+	// 점검 중이거나 루트가 오프라인이면 재시도 시 다시 확인합니다.
+	// 작업이 끝날 때까지 쓰기를 진행 중으로 기록해 저장소 이전이 root를 바꾸지 못하게 합니다.
+	dstSpace, releaseDst, err := h.spaceService.BeginWrite(ctx, dstSpace.ID)
+	if err != nil {
+		return err
+	}
+	defer releaseDst()
+	if payload.Operation == fileTransferOperationMove && srcSpace.ID != dstSpace.ID {
+		var releaseSrc func()
+		srcSpace, releaseSrc, err = h.spaceService.BeginWrite(ctx, srcSpace.ID)
+		if err != nil {
+			return err
+		}
+		defer releaseSrc()
+	} else if srcSpace.ID == dstSpace.ID {
+		srcSpace = dstSpace
+	}
+
+	absDestDir, err := resolveAbsPath(dstSpace.SpacePath, payload.DestinationPath)
+	if err != nil {
+		return job.Permanent(errors.New("Access denied: invalid destination path"))
+	}
+	destDirInfo, err := os.Stat(absDestDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return job.Permanent(errors.New("Destination directory not found"))
+		}
+		return errors.New(safeFilesystemReason("Failed to access destination directory", err))
+	}
+	if !destDirInfo.IsDir() {
+		return job.Permanent(errors.New("Destination must be a directory"))
+	}
+
+	result := fileTransferJobResult{fileTransferResult: newFileTransferResult()}
+	if err := current.DecodeResult(&result); err != nil {
+		return job.Permanent(fmt.Errorf("invalid transfer job result: %w", err))
+	}
+	done := result.processed()
+
+	type pendingSource struct {
+		path  string
+		items int
+		bytes int64
+	}
+	pending := make([]pendingSource, 0, len(payload.Sources))
+	totalItems := result.ProcessedItems
+	totalBytes := result.ProcessedBytes
+	for _, relSrc := range payload.Sources {
+		if _, ok := done[relSrc]; ok {
+			continue
+		}
+		source := pendingSource{path: relSrc}
+		if absSrc, resolveErr := resolveAbsPath(srcSpace.SpacePath, relSrc); resolveErr == nil {
+			// 측정에 실패한 항목은 진행률 없이 처리하고 실제 실패는 transferItem이 기록합니다.
+			source.items, source.bytes, _ = measureTransferSource(ctx, absSrc)
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		totalItems += source.items
+		totalBytes += source.bytes
+		pending = append(pending, source)
+	}
+	run.SetTotals(totalItems, totalBytes)
+	run.Advance(result.ProcessedItems, result.ProcessedBytes)
+
+	var itemReported int
+	var bytesReported int64
+	transfer := &fileTransfer{
+		Operation:          payload.Operation,
+		SourceSpaceID:      srcSpace.ID,
+		SourceRoot:         srcSpace.SpacePath,
+		DestinationSpaceID: dstSpace.ID,
+		DestinationRoot:    dstSpace.SpacePath,
+		DestinationDir:     absDestDir,
+		Actor:              current.Owner,
+		ConflictPolicy:     conflictPolicy,
+		HasConflictPolicy:  hasConflictPolicy,
+		Resolutions:        resolutions,
+		Progress: func(items int, bytes int64) {
+			itemReported += items
+			bytesReported += bytes
+			run.Advance(items, bytes)
+		},
+	}
+
+	for _, source := range pending {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		itemReported, bytesReported = 0, 0
+		outcome, err := h.transferItem(ctx, transfer, source.path)
+		if err != nil {
+			return err
+		}
+
+		// rename이나 건너뛴 항목은 진행률 콜백이 없으므로 측정한 남은 양을 한 번에 반영합니다.
+		remainingItems := source.items - itemReported
+		remainingBytes := source.bytes - bytesReported
+		if remainingItems < 0 {
+			remainingItems = 0
+		}
+		if remainingBytes < 0 {
+			remainingBytes = 0
+		}
+		run.Advance(remainingItems, remainingBytes)
+
+		result.add(source.path, outcome)
+		result.ProcessedItems += source.items
+		result.ProcessedBytes += source.bytes
+		if err := run.SetResult(result); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// finishFileTransferJob은 작업이 끝나면 사용량/검색 색인을 갱신하고 요약 감사 기록을 한 번 남깁니다.
+func (h *Handler) finishFileTransferJob(transferJob *job.Job) {
+	var payload fileTransferPayload
+	_ = transferJob.DecodePayload(&payload)
+	result := fileTransferJobResult{fileTransferResult: newFileTransferResult()}
+	_ = transferJob.DecodeResult(&result)
+
+	var spaceID int64
+	if transferJob.SpaceID != nil {
+		spaceID = *transferJob.SpaceID
+	}
+	if len(result.Succeeded) > 0 {
+		h.markSearchIndexDirty(context.Background(), spaceID, string(payload.Operation))
+	}
+
+	auditResult := result.auditResult()
+	if transferJob.Status != job.StatusCompleted {
+		auditResult = audit.ResultFailure
+		if len(result.Succeeded) > 0 {
+			auditResult = audit.ResultPartial
+		}
+	}
+	metadata := map[string]any{
+		"jobId":       transferJob.ID,
+		"async":       true,
+		"status":      string(transferJob.Status),
+		"sourceCount": len(payload.Sources),
+		"succeeded":   len(result.Succeeded),
+		"failed":      len(result.Failed),
+		"skipped":     len(result.Skipped),
+		"fromSpaceId": spaceID,
+		"toSpaceId":   payload.DestinationSpaceID,
+		"failedItems": result.auditFailedItems(),
+	}
+	if transferJob.FailureReason != "" {
+		metadata["reason"] = transferJob.FailureReason
+	}
+
+	var runErr error
+	if transferJob.Status == job.StatusFailed {
+		runErr = errors.New(transferJob.FailureReason)
+	}
+	h.logFileTransferJobEvent("info.transfer.job_finished", transferJob, runErr)
+	h.recordSpaceAuditBackground(transferJob.Owner, transferJob.RequestID, audit.Event{
+		Action:   "file." + string(payload.Operation),
+		Result:   auditResult,
+		Target:   payload.DestinationPath,
+		SpaceID:  transferJob.SpaceID,
+		Metadata: metadata,
+	})
+}
+
+func (h *Handler) logFileTransferJobEvent(eventName string, transferJob *job.Job, err error) {
+	var payload fileTransferPayload
+	_ = transferJob.DecodePayload(&payload)
+	var spaceID int64
+	if transferJob.SpaceID != nil {
+		spaceID = *transferJob.SpaceID
+	}
+
+	logger := logging.Event(log.Info(), logging.ComponentStorage, eventName).
+		Str("job_id", transferJob.ID).
+		Str("operation", string(payload.Operation)).
+		Int64("space_id", spaceID).
+		Int64("destination_space_id", payload.DestinationSpaceID).
+		Str("owner", transferJob.Owner).
+		Str("status", string(transferJob.Status)).
+		Int("source_count", len(payload.Sources)).
+		Int("total_items", transferJob.Progress.TotalItems).
+		Int("processed_items", transferJob.Progress.ProcessedItems).
+		Int64("total_bytes", transferJob.Progress.TotalBytes).
+		Int64("processed_bytes", transferJob.Progress.ProcessedBytes)
+	if err != nil {
+		logger = logger.Err(err)
+	}
+	logger.Msg("file transfer job updated")
+}
@@ -0,0 +1,289 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"taeu.kr/cohesion/internal/audit"
+	"taeu.kr/cohesion/internal/job"
+	"taeu.kr/cohesion/internal/space"
+)
+
+type syncAuditSink struct {
+	mu     sync.Mutex
+	events []audit.Event
+}
+
+func (s *syncAuditSink) RecordBestEffort(event audit.Event) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events = append(s.events, event)
+}
+
+func (s *syncAuditSink) waitForAction(t *testing.T, action string) audit.Event {
+	t.Helper()
+
+	deadline := time.Now().Add(3 * time.Second)
+	for {
+		s.mu.Lock()
+		for _, event := range s.events {
+			if event.Action == action {
+				s.mu.Unlock()
+				return event
+			}
+		}
+		s.mu.Unlock()
+		if time.Now().After(deadline) {
+			t.Fatalf("audit event %q was not recorded", action)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func newFileTransferJobTestHandler(t *testing.T) (*Handler, string, string, *job.MemoryStore, *syncAuditSink) {
+	t.Helper()
+
+	srcRoot := t.TempDir()
+	dstRoot := t.TempDir()
+	store := &fakeUploadSpaceStore{
+		spacesByID: map[int64]*space.Space{
+			1: {ID: 1, SpaceName: "Source", SpacePath: srcRoot},
+			2: {ID: 2, SpaceName: "Destination", SpacePath: dstRoot},
+		},
+	}
+	handler := NewHandler(space.NewService(store), nil, &allowAllSpaceAccessService{})
+	jobStore := job.NewMemoryStore()
+	manager := job.NewManager(jobStore)
+	t.Cleanup(manager.Stop)
+	handler.SetJobManager(manager)
+	sink := &syncAuditSink{}
+	handler.SetAuditRecorder(sink)
+	return handler, srcRoot, dstRoot, jobStore, sink
+}
+
+func waitForFileTransferJob(t *testing.T, handler *Handler, jobID string, status job.Status) *job.Job {
+	t.Helper()
+
+	deadline := time.Now().Add(3 * time.Second)
+	for {
+		current, err := handler.jobs.GetForOwner(context.Background(), jobID, "tester")
+		if err != nil {
+			t.Fatalf("failed to load transfer job: %v", err)
+		}
+		if current.Status == status {
+			return current
+		}
+		if current.Status.IsTerminal() || time.Now().After(deadline) {
+			t.Fatalf("expected transfer job status %q, got %+v", status, current)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestFileTransferJob_AsyncCopyReportsProgressAndPerItemResolutions(t *testing.T) {
+	handler, srcRoot, dstRoot, _, sink := newFileTransferJobTestHandler(t)
+
+	if err := os.MkdirAll(filepath.Join(srcRoot, "tree", "nested"), 0o755); err != nil {
+		t.Fatalf("failed to create source tree: %v", err)
+	}
+	for name, content := range map[string]string{
+		"tree/one.txt":        "one",
+		"tree/nested/two.txt": "two-two",
+		"a.txt":               "from-src",
+		"b.txt":               "from-src",
+	} {
+		if err := os.WriteFile(filepath.Join(srcRoot, name), []byte(content), 0o644); err != nil {
+			t.Fatalf("failed to seed %s: %v", name, err)
+		}
+	}
+	for _, name := range []string{"a.txt", "b.txt"} {
+		if err := os.WriteFile(filepath.Join(dstRoot, name), []byte("from-dst"), 0o644); err != nil {
+			t.Fatalf("failed to seed destination %s: %v", name, err)
+		}
+	}
+
+	body, err := json.Marshal(map[string]any{
+		"sources":             []string{"tree", "a.txt", "b.txt"},
+		"async":               true,
+		"conflictPolicy":      "skip",
+		"conflictResolutions": map[string]string{"a.txt": "rename"},
+		"destination":         map[string]any{"spaceId": 2, "path": ""},
+	})
+	if err != nil {
+		t.Fatalf("failed to marshal request: %v", err)
+	}
+	req := httptest.NewRequest(http.MethodPost, "/api/spaces/1/files/copy", bytes.NewReader(body))
+	req = withClaims(req, "tester")
+	rec := httptest.NewRecorder()
+	if webErr := handler.handleFileCopy(rec, req, 1); webErr != nil {
+		t.Fatalf("unexpected web error: %+v", webErr)
+	}
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("expected 202, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var created job.Job
+	if err := json.NewDecoder(rec.Body).Decode(&created); err != nil {
+		t.Fatalf("failed to decode job response: %v", err)
+	}
+	if created.Type != FileTransferJobType {
+		t.Fatalf("expected %s job, got %+v", FileTransferJobType, created)
+	}
+
+	finished := waitForFileTransferJob(t, handler, created.ID, job.StatusCompleted)
+	if finished.Progress.TotalItems != 4 || finished.Progress.ProcessedItems != 4 {
+		t.Fatalf("expected 4/4 items, got %+v", finished.Progress)
+	}
+	if finished.Progress.ProcessedBytes != finished.Progress.TotalBytes || finished.Progress.TotalBytes == 0 {
+		t.Fatalf("expected all bytes processed, got %+v", finished.Progress)
+	}
+
+	var result fileTransferJobResult
+	if err := finished.DecodeResult(&result); err != nil {
+		t.Fatalf("failed to decode job result: %v", err)
+	}
+	if len(result.Succeeded) != 2 || len(result.Skipped) != 1 || result.Skipped[0] != "b.txt" {
+		t.Fatalf("unexpected job result: %+v", result)
+	}
+	if content, err := os.ReadFile(filepath.Join(dstRoot, "a (1).txt")); err != nil || string(content) != "from-src" {
+		t.Fatalf("expected per-item rename resolution, content=%q err=%v", content, err)
+	}
+	if content, err := os.ReadFile(filepath.Join(dstRoot, "tree", "nested", "two.txt")); err != nil || string(content) != "two-two" {
+		t.Fatalf("expected nested tree copy, content=%q err=%v", content, err)
+	}
+
+	event := sink.waitForAction(t, "file.copy")
+	if event.Result != audit.ResultPartial {
+		t.Fatalf("expected partial audit result for skipped item, got %+v", event)
+	}
+	if event.Metadata["jobId"] != created.ID || event.Metadata["succeeded"] != 2 || event.Metadata["skipped"] != 1 {
+		t.Fatalf("unexpected audit metadata: %+v", event.Metadata)
+	}
+}
+
+func TestFileTransferJob_InvalidConflictResolutionIsRejected(t *testing.T) {
+	handler, srcRoot, _, _, _ := newFileTransferJobTestHandler(t)
+	if err := os.WriteFile(filepath.Join(srcRoot, "a.txt"), []byte("a"), 0o644); err != nil {
+		t.Fatalf("failed to seed source: %v", err)
+	}
+
+	body, err := json.Marshal(map[string]any{
+		"sources":             []string{"a.txt"},
+		"async":               true,
+		"conflictResolutions": map[string]string{"a.txt": "merge"},
+		"destination":         map[string]any{"spaceId": 2, "path": ""},
+	})
+	if err != nil {
+		t.Fatalf("failed to marshal request: %v", err)
+	}
+	req := httptest.NewRequest(http.MethodPost, "/api/spaces/1/files/move", bytes.NewReader(body))
+	req = withClaims(req, "tester")
+	webErr := handler.handleFileMove(httptest.NewRecorder(), req, 1)
+	if webErr == nil || webErr.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for invalid resolution, got %+v", webErr)
+	}
+}
+
+func TestFileTransferJob_ResumeSkipsRecordedItems(t *testing.T) {
+	handler, srcRoot, dstRoot, jobStore, sink := newFileTransferJobTestHandler(t)
+
+	for _, name := range []string{"done.txt", "next.txt"} {
+		if err := os.WriteFile(filepath.Join(srcRoot, name), []byte(name), 0o644); err != nil {
+			t.Fatalf("failed to seed %s: %v", name, err)
+		}
+	}
+
+	// 이전 시도에서 done.txt까지 처리하고 중단된 작업을 저장소에 둡니다.
+	payload, _ := json.Marshal(fileTransferPayload{
+		Operation:          fileTransferOperationMove,
+		Sources:            []string{"done.txt", "next.txt"},
+		DestinationSpaceID: 2,
+	})
+	previous := fileTransferJobResult{fileTransferResult: newFileTransferResult(), ProcessedItems: 1, ProcessedBytes: 8}
+	previous.Succeeded = append(previous.Succeeded, "done.txt")
+	recorded, _ := json.Marshal(previous)
+	spaceID := int64(1)
+	now := time.Now()
+	if err := jobStore.Create(context.Background(), &job.Job{
+		ID:          "resume-job",
+		Type:        FileTransferJobType,
+		Owner:       "tester",
+		SpaceID:     &spaceID,
+		Status:      job.StatusQueued,
+		Payload:     payload,
+		Result:      recorded,
+		MaxAttempts: defaultFileTransferMaxAttempts,
+		RunAfter:    now,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}); err != nil {
+		t.Fatalf("failed to seed job: %v", err)
+	}
+	if err := handler.jobs.Start(context.Background()); err != nil {
+		t.Fatalf("failed to start job manager: %v", err)
+	}
+
+	finished := waitForFileTransferJob(t, handler, "resume-job", job.StatusCompleted)
+	if finished.Progress.TotalItems != 2 || finished.Progress.ProcessedItems != 2 {
+		t.Fatalf("expected resumed progress 2/2, got %+v", finished.Progress)
+	}
+	if _, err := os.Stat(filepath.Join(srcRoot, "done.txt")); err != nil {
+		t.Fatalf("recorded item must not be processed again: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dstRoot, "next.txt")); err != nil {
+		t.Fatalf("expected pending item to be moved: %v", err)
+	}
+	event := sink.waitForAction(t, "file.move")
+	if event.Metadata["succeeded"] != 2 || event.Result != audit.ResultSuccess {
+		t.Fatalf("expected summarizing audit for both items, got %+v", event)
+	}
+}
+
+func TestTransferItem_CancelRemovesPartialCopy(t *testing.T) {
+	handler, srcRoot, dstRoot, _, _ := newFileTransferJobTestHandler(t)
+
+	treeDir := filepath.Join(srcRoot, "tree")
+	if err := os.MkdirAll(treeDir, 0o755); err != nil {
+		t.Fatalf("failed to create source tree: %v", err)
+	}
+	for _, name := range []string{"a.bin", "b.bin", "c.bin"} {
+		if err := writePatternFile(filepath.Join(treeDir, name), 256*1024); err != nil {
+			t.Fatalf("failed to seed %s: %v", name, err)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	transfer := &fileTransfer{
+		Operation:          fileTransferOperationCopy,
+		SourceSpaceID:      1,
+		SourceRoot:         srcRoot,
+		DestinationSpaceID: 2,
+		DestinationDir:     dstRoot,
+		Progress: func(items int, _ int64) {
+			// 첫 파일 복사가 끝나면 취소합니다.
+			if items > 0 {
+				cancel()
+			}
+		},
+	}
+
+	_, err := handler.transferItem(ctx, transfer, "tree")
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context cancellation, got %v", err)
+	}
+	if _, statErr := os.Stat(filepath.Join(dstRoot, "tree")); !os.IsNotExist(statErr) {
+		t.Fatalf("expected partial destination tree to be removed, err=%v", statErr)
+	}
+	if entries, err := os.ReadDir(treeDir); err != nil || len(entries) != 3 {
+		t.Fatalf("expected source tree to remain intact, entries=%d err=%v", len(entries), err)
+	}
+}
@@ -12,10 +12,13 @@ import (
 
 	"taeu.kr/cohesion/internal/audit"
 	"taeu.kr/cohesion/internal/platform/web"
+	"taeu.kr/cohesion/internal/space"
+	"taeu.kr/cohesion/internal/space/checksum"
 )
 
 // handleFileUpload: POST /api/spaces/{id}/files/upload
-// multipart form: file, path (상대 경로), conflictPolicy (optional: overwrite|rename|skip), overwrite (legacy optional)
+// multipart form: file, path (상대 경로), conflictPolicy (optional: overwrite|rename|skip), overwrite (legacy optional),
+// sha256 (optional, hex). Digest(sha-256/sha/md5)와 Content-MD5 헤더로도 기대 해시를 보낼 수 있습니다.
 func (h *Handler) handleFileUpload(w http.ResponseWriter, r *http.Request, spaceID int64) *web.Error {
 	if r.Method != http.MethodPost {
 		return &web.Error{Code: http.StatusMethodNotAllowed, Message: "Method not allowed"}
@@ -26,6 +29,11 @@ func (h *Handler) handleFileUpload(w http.ResponseWriter, r *http.Request, space
 		return webErr
 	}
 
+	expectedDigests, err := parseUploadDigestHeaders(r.Header)
+	if err != nil {
+		return &web.Error{Code: http.StatusBadRequest, Message: "Invalid upload digest", Err: err}
+	}
+
 	reader, err := r.MultipartReader()
 	if err != nil {
 		return &web.Error{Code: http.StatusBadRequest, Message: "Failed to parse multipart form", Err: err}
@@ -44,6 +52,7 @@ func (h *Handler) handleFileUpload(w http.ResponseWriter, r *http.Request, space
 		fileSize            int64
 		plan                *uploadPlan
 		uploadReservationID string
+		hasher              *uploadHasher
 	)
 
 	defer func() {
@@ -90,6 +99,15 @@ func (h *Handler) handleFileUpload(w http.ResponseWriter, r *http.Request, space
 					return &web.Error{Code: http.StatusBadRequest, Message: "Invalid upload size", Err: parseErr}
 				}
 				declaredUploadSize = parsedSize
+			case "sha256":
+				if strings.TrimSpace(value) == "" {
+					break
+				}
+				digest, parseErr := parseUploadSHA256Field(value)
+				if parseErr != nil {
+					return &web.Error{Code: http.StatusBadRequest, Message: "Invalid upload digest", Err: parseErr}
+				}
+				expectedDigests = append(expectedDigests, digest)
 			}
 			continue
 		}
@@ -106,6 +124,7 @@ func (h *Handler) handleFileUpload(w http.ResponseWriter, r *http.Request, space
 		if pathProvided {
 			plan, webErr = h.buildUploadPlan(r.Context(), spaceID, spaceData.SpacePath, targetRelPath, fileName, declaredUploadSize, rawConflictPolicy, overwriteLegacy)
 			if webErr != nil {
+				h.auditUploadPolicyRejection(r, spaceID, targetRelPath, webErr)
 				return webErr
 			}
 			if uploadReservationID == "" {
@@ -126,8 +145,17 @@ func (h *Handler) handleFileUpload(w http.ResponseWriter, r *http.Request, space
 		}
 
 		stageDestPath := ""
+		var policyGuard *space.UploadWriteGuard
 		if plan != nil {
 			stageDestPath = plan.destPath
+			policyGuard = plan.policyGuard
+		} else {
+			policyGuard, err = h.uploadPolicies.BeginUpload(r.Context(), spaceID, fileName)
+			if err != nil {
+				webErr = uploadPolicyWebError(err)
+				h.auditUploadPolicyRejection(r, spaceID, targetRelPath, webErr)
+				return webErr
+			}
 		}
 		stageFile, stagePath, err = createUploadStageFile(stageDestPath)
 		if err != nil {
@@ -138,11 +166,19 @@ func (h *Handler) handleFileUpload(w http.ResponseWriter, r *http.Request, space
 		if plan != nil && plan.quotaWindow.enabled {
 			writer = &quotaEnforcingWriter{writer: stageFile, remaining: plan.quotaWindow.maxBytes}
 		}
-		fileSize, err = io.Copy(writer, part)
+		writer = &policyEnforcingWriter{writer: writer, guard: policyGuard}
+		hasher = newUploadHasher(expectedDigests)
+		fileSize, err = io.Copy(io.MultiWriter(writer, hasher), part)
 		if err != nil {
 			if errors.Is(err, errUploadQuotaExceeded) {
 				return createQuotaExceededWebError(spaceID, plan.quotaWindow.usedBytes, plan.quotaWindow.quotaBytes, fileSize-plan.existingBytes)
 			}
+			var policyErr *space.UploadPolicyError
+			if errors.As(err, &policyErr) {
+				webErr = uploadPolicyWebError(err)
+				h.auditUploadPolicyRejection(r, spaceID, targetRelPath, webErr)
+				return webErr
+			}
 			return &web.Error{Code: http.StatusInternalServerError, Message: "Failed to save uploaded file", Err: err}
 		}
 	}
@@ -154,6 +190,7 @@ func (h *Handler) handleFileUpload(w http.ResponseWriter, r *http.Request, space
 	if plan == nil {
 		plan, webErr = h.buildUploadPlan(r.Context(), spaceID, spaceData.SpacePath, targetRelPath, fileName, fileSize, rawConflictPolicy, overwriteLegacy)
 		if webErr != nil {
+			h.auditUploadPolicyRejection(r, spaceID, targetRelPath, webErr)
 			return webErr
 		}
 	}
@@ -189,27 +226,74 @@ func (h *Handler) handleFileUpload(w http.ResponseWriter, r *http.Request, space
 	}
 	stageFile = nil
 
+	// 기대 해시와 다르면 스테이징 파일은 defer에서 지워지고 대상 파일은 건드리지 않습니다.
+	if mismatch, ok := hasher.verify(expectedDigests); !ok {
+		h.recordSpaceAudit(r, audit.Event{
+			Action: "file.upload",
+			Result: audit.ResultFailure,
+			Target: filepath.ToSlash(filepath.Join(targetRelPath, plan.resultFileName)),
+			Metadata: map[string]any{
+				"filename":  plan.resultFileName,
+				"size":      fileSize,
+				"status":    "failed",
+				"reason":    "checksum_mismatch",
+				"algorithm": string(mismatch.Algorithm),
+			},
+		}, spaceID)
+		return writeJSON(w, http.StatusUnprocessableEntity, mismatch)
+	}
+
 	projectedDelta := fileSize - plan.existingBytes
 	if projectedDelta < 0 {
 		projectedDelta = 0
 	}
-	h.invalidateQuotaForSpaces(spaceID)
 	if webErr := h.ensureSpaceQuotaForWrite(r.Context(), spaceID, projectedDelta); webErr != nil {
 		return webErr
 	}
+	username, _ := claimsUsernameFromRequest(r)
+	if webErr := h.ensureUserQuotaForWrite(r.Context(), username, spaceID, projectedDelta); webErr != nil {
+		return webErr
+	}
+
+	// block 모드면 제자리에 옮기기 전에 검사합니다. 감염된 파일은 격리 영역으로 옮겨지고 대상 파일은 그대로입니다.
+	scanTarget := space.MalwareScanTarget{
+		SpaceID:   spaceID,
+		SpaceRoot: spaceData.SpacePath,
+		FilePath:  stagePath,
+		DestPath:  plan.destPath,
+		Actor:     username,
+		Source:    "rest",
+	}
+	if err := h.malwareScans.ScanBeforeFinalize(r.Context(), scanTarget); err != nil {
+		h.auditUploadMalwareRejection(r, spaceID, targetRelPath, plan.resultFileName, fileSize, err)
+		return malwareScanWebError(err)
+	}
 
 	if err := finalizeUploadedFile(stagePath, plan.destPath, plan.existingBytes > 0); err != nil {
 		return storageOperationWebError(err, "Failed to finalize uploaded file")
 	}
 	stagePath = ""
+	scanTarget.FilePath = plan.destPath
+	h.malwareScans.ScanAfterFinalize(r.Context(), scanTarget)
+	fileDelta := int64(1)
+	if plan.replacesFile {
+		fileDelta = 0
+	}
+	h.adjustQuotaUsage(r.Context(), spaceID, fileSize-plan.existingBytes, fileDelta)
+	h.recordFileOwner(r.Context(), username, spaceID, spaceData.SpacePath, plan.destPath)
 	resultFileName = plan.resultFileName
+	h.recordUploadChecksums(r.Context(), plan.destPath, hasher)
+	h.publishFileCreated(r.Context(), spaceData, plan.destPath, username)
+	sha256Digest := hasher.hexDigest(checksum.SHA256)
 
+	h.setQuotaWarningHeader(w, r.Context(), spaceID)
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	if err := json.NewEncoder(w).Encode(map[string]string{
 		"message":  "Successfully uploaded",
 		"filename": resultFileName,
 		"status":   "uploaded",
+		"sha256":   sha256Digest,
 	}); err != nil {
 		return &web.Error{Code: http.StatusInternalServerError, Message: "Failed to encode upload response", Err: err}
 	}
@@ -218,12 +302,54 @@ func (h *Handler) handleFileUpload(w http.ResponseWriter, r *http.Request, space
 		Result: audit.ResultSuccess,
 		Target: filepath.ToSlash(filepath.Join(targetRelPath, resultFileName)),
 		Metadata: map[string]any{
-			"filename":       resultFileName,
-			"size":           fileSize,
-			"status":         "uploaded",
-			"conflictPolicy": string(plan.conflictPolicy),
+			"filename":         resultFileName,
+			"size":             fileSize,
+			"status":           "uploaded",
+			"conflictPolicy":   string(plan.conflictPolicy),
+			"sha256":           sha256Digest,
+			"checksumVerified": len(expectedDigests) > 0,
 		},
 	}, spaceID)
-	h.invalidateQuotaForSpaces(spaceID)
 	return nil
 }
+
+// auditUploadPolicyRejection은 업로드 정책에 걸려 거절한 업로드를 감사 기록에 남깁니다. 다른 오류는 무시합니다.
+func (h *Handler) auditUploadPolicyRejection(r *http.Request, spaceID int64, targetRelPath string, webErr *web.Error) {
+	var policyErr *space.UploadPolicyError
+	if webErr == nil || !errors.As(webErr.Err, &policyErr) {
+		return
+	}
+	h.recordSpaceAudit(r, audit.Event{
+		Action: "file.upload",
+		Result: audit.ResultFailure,
+		Target: filepath.ToSlash(filepath.Join(targetRelPath, policyErr.Name)),
+		Metadata: map[string]any{
+			"filename":  policyErr.Name,
+			"status":    "failed",
+			"reason":    "upload_policy",
+			"violation": string(policyErr.Violation),
+		},
+	}, spaceID)
+}
+
+// auditUploadMalwareRejection은 block 모드 검사에서 감염되었거나 검사하지 못해 거절한 업로드를 감사 기록에 남깁니다.
+func (h *Handler) auditUploadMalwareRejection(r *http.Request, spaceID int64, targetRelPath, fileName string, fileSize int64, err error) {
+	metadata := map[string]any{
+		"filename": fileName,
+		"size":     fileSize,
+		"status":   "failed",
+		"reason":   "malware_scan_failed",
+	}
+	var detected *space.MalwareDetectedError
+	if errors.As(err, &detected) {
+		metadata["reason"] = "malware_detected"
+		metadata["signature"] = detected.Signature
+		metadata["quarantineId"] = detected.QuarantineID
+	}
+	h.recordSpaceAudit(r, audit.Event{
+		Action:   "file.upload",
+		Result:   audit.ResultFailure,
+		Target:   filepath.ToSlash(filepath.Join(targetRelPath, fileName)),
+		Metadata: metadata,
+	}, spaceID)
+}
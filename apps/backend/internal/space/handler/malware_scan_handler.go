@@ -0,0 +1,185 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"taeu.kr/cohesion/internal/audit"
+	"taeu.kr/cohesion/internal/platform/web"
+	"taeu.kr/cohesion/internal/space"
+)
+
+const quarantinePathPrefix = "/api/quarantine/"
+
+type malwareScanSettingsResponse struct {
+	*space.MalwareScanSettings
+	// Scanner는 서버에 설정된 검사기 이름입니다. 비어 있으면 off만 고를 수 있습니다.
+	Scanner string `json:"scanner"`
+}
+
+// SetMalwareScanService는 검사 설정과 격리 영역을 프로토콜 서버와 함께 쓰도록 공용 MalwareScanService로 교체합니다.
+func (h *Handler) SetMalwareScanService(malwareScans *space.MalwareScanService) {
+	if malwareScans != nil {
+		h.malwareScans = malwareScans
+	}
+}
+
+// handleSpaceMalwareScan은 GET/PUT /api/spaces/{id}/malware-scan 요청을 처리합니다.
+// PUT body: { mode: "off" | "block" | "quarantine" }
+func (h *Handler) handleSpaceMalwareScan(w http.ResponseWriter, r *http.Request, spaceID int64) *web.Error {
+	switch r.Method {
+	case http.MethodGet:
+		if _, err := h.spaceService.GetSpaceByID(r.Context(), spaceID); err != nil {
+			return spaceLookupWebError(err)
+		}
+		settings, err := h.malwareScans.GetSettings(r.Context(), spaceID)
+		if err != nil {
+			return &web.Error{Code: http.StatusInternalServerError, Message: "Failed to get malware scan settings", Err: err}
+		}
+		return writeJSON(w, http.StatusOK, malwareScanSettingsResponse{MalwareScanSettings: settings, Scanner: h.malwareScans.ScannerName()})
+	case http.MethodPut:
+	default:
+		return &web.Error{Code: http.StatusMethodNotAllowed, Message: "Method not allowed"}
+	}
+
+	var req struct {
+		Mode space.MalwareScanMode `json:"mode"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return &web.Error{Code: http.StatusBadRequest, Message: "Invalid request body", Err: err}
+	}
+	if _, err := h.spaceService.GetSpaceByID(r.Context(), spaceID); err != nil {
+		return spaceLookupWebError(err)
+	}
+
+	settings, err := h.malwareScans.SaveSettings(r.Context(), spaceID, req.Mode)
+	if err != nil {
+		h.recordSpaceAudit(r, audit.Event{
+			Action: "space.malware-scan.update",
+			Result: audit.ResultFailure,
+			Target: fmt.Sprintf("space:%d", spaceID),
+		}, spaceID)
+		if errors.Is(err, space.ErrMalwareScannerNotConfigured) {
+			return &web.Error{Code: http.StatusBadRequest, Message: "No malware scanner is configured on this server", Err: err}
+		}
+		if errors.Is(err, space.ErrInvalidMalwareScanMode) {
+			return &web.Error{Code: http.StatusBadRequest, Message: "Invalid malware scan mode", Err: err}
+		}
+		return &web.Error{Code: http.StatusInternalServerError, Message: "Failed to update malware scan settings", Err: err}
+	}
+
+	h.recordSpaceAudit(r, audit.Event{
+		Action:   "space.malware-scan.update",
+		Result:   audit.ResultSuccess,
+		Target:   fmt.Sprintf("space:%d", spaceID),
+		Metadata: map[string]any{"mode": string(settings.Mode)},
+	}, spaceID)
+	return writeJSON(w, http.StatusOK, malwareScanSettingsResponse{MalwareScanSettings: settings, Scanner: h.malwareScans.ScannerName()})
+}
+
+// handleQuarantineList: GET /api/quarantine?spaceId=
+// 격리 항목을 최근 것부터 반환합니다. spaceId가 없으면 모든 Space입니다.
+func (h *Handler) handleQuarantineList(w http.ResponseWriter, r *http.Request) *web.Error {
+	if r.Method != http.MethodGet {
+		return &web.Error{Code: http.StatusMethodNotAllowed, Message: "Method not allowed"}
+	}
+	var spaceID int64
+	if raw := strings.TrimSpace(r.URL.Query().Get("spaceId")); raw != "" {
+		parsed, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || parsed <= 0 {
+			return &web.Error{Code: http.StatusBadRequest, Message: "Invalid spaceId", Err: err}
+		}
+		spaceID = parsed
+	}
+	items, err := h.malwareScans.ListQuarantine(r.Context(), spaceID)
+	if err != nil {
+		return &web.Error{Code: http.StatusInternalServerError, Message: "Failed to list quarantined files", Err: err}
+	}
+	return writeJSON(w, http.StatusOK, items)
+}
+
+// handleQuarantineItem은 격리 항목 하나를 다룹니다.
+//   - GET    /api/quarantine/{id}
+//   - POST   /api/quarantine/{id}/release : 다시 검사하지 않고 원래 경로로 돌려놓습니다.
+//   - DELETE /api/quarantine/{id}         : 격리한 파일을 영구히 지웁니다.
+func (h *Handler) handleQuarantineItem(w http.ResponseWriter, r *http.Request) *web.Error {
+	rest := strings.Trim(strings.TrimPrefix(r.URL.Path, quarantinePathPrefix), "/")
+	rawID, action, _ := strings.Cut(rest, "/")
+	id, err := strconv.ParseInt(rawID, 10, 64)
+	if err != nil || id <= 0 {
+		return &web.Error{Code: http.StatusNotFound, Message: "Not found"}
+	}
+
+	switch {
+	case action == "" && r.Method == http.MethodGet:
+		item, err := h.malwareScans.GetQuarantineItem(r.Context(), id)
+		if err != nil {
+			return quarantineWebError(err, "Failed to get quarantined file")
+		}
+		return writeJSON(w, http.StatusOK, item)
+	case action == "" && r.Method == http.MethodDelete:
+		item, err := h.malwareScans.DeleteQuarantineItem(r.Context(), id)
+		if err != nil {
+			h.recordQuarantineAudit(r, "quarantine.delete", audit.ResultFailure, id, nil)
+			return quarantineWebError(err, "Failed to delete quarantined file")
+		}
+		h.recordQuarantineAudit(r, "quarantine.delete", audit.ResultSuccess, id, item)
+		return writeJSON(w, http.StatusOK, map[string]any{"id": id, "status": "deleted"})
+	case action == "release" && r.Method == http.MethodPost:
+		item, err := h.malwareScans.ReleaseQuarantineItem(r.Context(), id)
+		if err != nil {
+			h.recordQuarantineAudit(r, "quarantine.release", audit.ResultFailure, id, nil)
+			if errors.Is(err, space.ErrQuarantineReleaseConflict) {
+				return &web.Error{Code: http.StatusConflict, Message: "A file already exists at the original path", Err: err}
+			}
+			return quarantineWebError(err, "Failed to release quarantined file")
+		}
+		h.recordQuarantineAudit(r, "quarantine.release", audit.ResultSuccess, id, item)
+		return writeJSON(w, http.StatusOK, item)
+	case action == "" || action == "release":
+		return &web.Error{Code: http.StatusMethodNotAllowed, Message: "Method not allowed"}
+	default:
+		return &web.Error{Code: http.StatusNotFound, Message: "Not found"}
+	}
+}
+
+func quarantineWebError(err error, message string) *web.Error {
+	if errors.Is(err, space.ErrQuarantineItemNotFound) {
+		return &web.Error{Code: http.StatusNotFound, Message: "Quarantined file not found", Err: err}
+	}
+	return &web.Error{Code: http.StatusInternalServerError, Message: message, Err: err}
+}
+
+func (h *Handler) recordQuarantineAudit(r *http.Request, action string, result audit.Result, id int64, item *space.QuarantineItem) {
+	event := audit.Event{
+		Action:   action,
+		Result:   result,
+		Target:   fmt.Sprintf("quarantine:%d", id),
+		Metadata: map[string]any{"quarantineId": id},
+	}
+	if item == nil {
+		h.recordUserStorageAudit(r, event)
+		return
+	}
+	event.Metadata["path"] = item.OriginalPath
+	event.Metadata["signature"] = item.Signature
+	event.Metadata["uploadedBy"] = item.UploadedBy
+	h.recordSpaceAudit(r, event, item.SpaceID)
+}
+
+// malwareScanWebError는 감염 판정을 422로, 검사 실패를 503으로 바꿉니다.
+func malwareScanWebError(err error) *web.Error {
+	var detected *space.MalwareDetectedError
+	if errors.As(err, &detected) {
+		return &web.Error{
+			Code:    http.StatusUnprocessableEntity,
+			Message: fmt.Sprintf("File was quarantined: malware detected (%s)", detected.Signature),
+			Err:     err,
+		}
+	}
+	return &web.Error{Code: http.StatusServiceUnavailable, Message: "Malware scan is unavailable; the file was not stored", Err: err}
+}
@@ -99,6 +99,9 @@ func (h *Handler) handleSearchFiles(w http.ResponseWriter, r *http.Request) *web
 		if !allowed {
 			continue
 		}
+		if item.State() == space.SpaceStateMaintenance && item.CheckReadable(h.isAdminRequest(r)) != nil {
+			continue
+		}
 		readableSpaces = append(readableSpaces, item)
 		readableSpaceIDs = append(readableSpaceIDs, item.ID)
 	}
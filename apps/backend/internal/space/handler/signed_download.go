@@ -0,0 +1,264 @@
+package handler
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"taeu.kr/cohesion/internal/account"
+	"taeu.kr/cohesion/internal/audit"
+	"taeu.kr/cohesion/internal/auth"
+	"taeu.kr/cohesion/internal/download"
+	"taeu.kr/cohesion/internal/job"
+	"taeu.kr/cohesion/internal/platform/web"
+)
+
+const signedDownloadPathPrefix = "/api/downloads/signed/"
+
+// SetDownloadSigner는 서명 URL 키/nonce를 영속 저장소에 두는 Signer로 교체합니다.
+func (h *Handler) SetDownloadSigner(signer *download.Signer) {
+	h.downloadSigner = signer
+}
+
+// downloadLinkOptions는 다운로드 티켓 요청에서 서명 URL에 적용할 선택 항목입니다.
+type downloadLinkOptions struct {
+	// ExpiresIn은 유효 기간(초)입니다. 0이면 기본값, 최대값을 넘으면 최대값을 사용합니다.
+	ExpiresIn int64 `json:"expiresIn"`
+	// BindIP가 true이면 요청한 클라이언트 주소에서만 URL을 쓸 수 있습니다.
+	BindIP bool `json:"bindIp"`
+}
+
+func (o downloadLinkOptions) ttl() time.Duration {
+	if o.ExpiresIn <= 0 {
+		return download.DefaultTTL
+	}
+	if o.ExpiresIn > int64(download.MaxTTL/time.Second) {
+		return download.MaxTTL
+	}
+	return time.Duration(o.ExpiresIn) * time.Second
+}
+
+// requestClientIP는 요청의 원격 주소에서 포트를 뺀 IP를 반환합니다.
+func requestClientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return strings.TrimSpace(r.RemoteAddr)
+	}
+	return host
+}
+
+// signDownloadURL은 claims에 요청자와 IP 바인딩을 채워 서명 URL을 만듭니다.
+func (h *Handler) signDownloadURL(r *http.Request, claims download.Claims, options downloadLinkOptions, ttl time.Duration) (string, time.Time, error) {
+	if options.BindIP {
+		claims.ClientIP = requestClientIP(r)
+	}
+	token, expiresAt, err := h.downloadSigner.Sign(r.Context(), claims, ttl)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return signedDownloadPathPrefix + token, expiresAt, nil
+}
+
+// handleSignedDownload: GET /api/downloads/signed/{token}
+// 로그인 쿠키 없이 쓸 수 있는 공개 경로입니다. 토큰 서명과 소유자의 현재 Space 권한을 매번 확인하므로
+// 다운로드 관리자가 만료 전까지 Range 요청을 반복해도 됩니다.
+func (h *Handler) handleSignedDownload(w http.ResponseWriter, r *http.Request) *web.Error {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return &web.Error{Code: http.StatusMethodNotAllowed, Message: "Method not allowed"}
+	}
+
+	token := strings.TrimPrefix(r.URL.Path, signedDownloadPathPrefix)
+	if token == "" || strings.Contains(token, "/") {
+		return &web.Error{Code: http.StatusBadRequest, Message: "Invalid download link"}
+	}
+
+	claims, err := h.downloadSigner.Verify(r.Context(), token, requestClientIP(r))
+	if err != nil {
+		webErr := signedDownloadWebError(err)
+		if claims != nil {
+			h.recordSignedDownloadDeniedAudit(r, claims, err, webErr.Code)
+		}
+		return webErr
+	}
+
+	// 이후 Space 상태 확인과 감사 로그는 링크 소유자의 요청으로 처리합니다.
+	r = r.WithContext(auth.WithClaims(r.Context(), &auth.Claims{Username: claims.Owner}))
+	if h.accountService != nil {
+		allowed, err := h.accountService.CanAccessSpaceByID(r.Context(), claims.Owner, claims.SpaceID, account.PermissionRead)
+		if err != nil {
+			return &web.Error{Code: http.StatusInternalServerError, Message: "Failed to evaluate space access", Err: err}
+		}
+		if !allowed {
+			h.recordSignedDownloadDeniedAudit(r, claims, errors.New("space permission denied"), http.StatusForbidden)
+			return &web.Error{Code: http.StatusForbidden, Message: "Download link access denied"}
+		}
+	}
+	if webErr := h.ensureSpaceReadable(r, claims.SpaceID); webErr != nil {
+		return webErr
+	}
+
+	if claims.JobID != "" {
+		return h.serveSignedArchiveArtifact(w, r, claims)
+	}
+	return h.serveSignedFile(w, r, claims)
+}
+
+func (h *Handler) serveSignedFile(w http.ResponseWriter, r *http.Request, claims *download.Claims) *web.Error {
+	spaceData, webErr := h.getSpace(r, claims.SpaceID)
+	if webErr != nil {
+		return webErr
+	}
+	if err := ensurePathOutsideTrash(claims.Path); err != nil {
+		return &web.Error{Code: http.StatusForbidden, Message: "Access denied: invalid path", Err: err}
+	}
+	absPath, err := resolveAbsPath(spaceData.SpacePath, claims.Path)
+	if err != nil {
+		return &web.Error{Code: http.StatusForbidden, Message: "Access denied: invalid path"}
+	}
+
+	file, err := os.Open(absPath)
+	if err != nil {
+		return storageAccessWebError(err, "File not found", "Failed to open file")
+	}
+	defer file.Close()
+
+	fileInfo, err := file.Stat()
+	if err != nil {
+		return storageAccessWebError(err, "File not found", "Failed to inspect file")
+	}
+	if fileInfo.IsDir() {
+		return &web.Error{Code: http.StatusBadRequest, Message: "Path is a directory"}
+	}
+
+	h.setDigestHeader(w, r, absPath, fileInfo)
+	serveAttachmentContent(w, r, file, fileInfo, filepath.Base(absPath), "")
+	if isFirstDownloadRequest(r) {
+		h.recordSpaceAudit(r, audit.Event{
+			Action: "file.download",
+			Result: audit.ResultSuccess,
+			Target: claims.Path,
+			Metadata: map[string]any{
+				"path":        claims.Path,
+				"filename":    fileInfo.Name(),
+				"size":        fileInfo.Size(),
+				"format":      "signed-url",
+				"sourceCount": 1,
+				"status":      "downloaded",
+			},
+		}, claims.SpaceID)
+	}
+	return nil
+}
+
+func (h *Handler) serveSignedArchiveArtifact(w http.ResponseWriter, r *http.Request, claims *download.Claims) *web.Error {
+	archiveJob, webErr := h.getArchiveDownloadJob(r.Context(), claims.JobID, claims.Owner, claims.SpaceID)
+	if webErr != nil {
+		return webErr
+	}
+	if archiveJob.Status == job.StatusExpired {
+		return &web.Error{Code: http.StatusGone, Message: "Archive job expired"}
+	}
+	if archiveJob.Status != job.StatusCompleted || strings.TrimSpace(archiveJob.ArtifactPath) == "" {
+		return &web.Error{Code: http.StatusConflict, Message: "Archive job is not ready"}
+	}
+
+	file, err := os.Open(archiveJob.ArtifactPath)
+	if err != nil {
+		return storageAccessWebError(err, "Download file not found", "Failed to open download file")
+	}
+	defer file.Close()
+
+	fileInfo, err := file.Stat()
+	if err != nil {
+		return storageAccessWebError(err, "Download file not found", "Failed to inspect download file")
+	}
+
+	var payload archiveDownloadPayload
+	_ = archiveJob.DecodePayload(&payload)
+	serveAttachmentContent(w, r, file, fileInfo, payload.FileName, archiveContentType(archiveDownloadFormat(payload)))
+	return nil
+}
+
+// isFirstDownloadRequest는 다운로드 관리자의 이어받기 요청마다 감사 로그가 쌓이지 않도록
+// 처음부터 받는 요청만 골라냅니다.
+func isFirstDownloadRequest(r *http.Request) bool {
+	if r.Method != http.MethodGet {
+		return false
+	}
+	rangeHeader := strings.TrimSpace(r.Header.Get("Range"))
+	return rangeHeader == "" || strings.HasPrefix(rangeHeader, "bytes=0-")
+}
+
+func signedDownloadWebError(err error) *web.Error {
+	switch {
+	case errors.Is(err, download.ErrExpired):
+		return &web.Error{Code: http.StatusGone, Message: "Download link has expired", Err: err}
+	case errors.Is(err, download.ErrRevoked):
+		return &web.Error{Code: http.StatusForbidden, Message: "Download link has been revoked", Err: err}
+	case errors.Is(err, download.ErrAddressMismatch):
+		return &web.Error{Code: http.StatusForbidden, Message: "Download link is bound to another address", Err: err}
+	case errors.Is(err, download.ErrMalformedToken), errors.Is(err, download.ErrInvalidSignature):
+		return &web.Error{Code: http.StatusForbidden, Message: "Invalid download link", Err: err}
+	default:
+		return &web.Error{Code: http.StatusInternalServerError, Message: "Failed to verify download link", Err: err}
+	}
+}
+
+func signedDownloadDeniedCode(err error) string {
+	switch {
+	case errors.Is(err, download.ErrExpired):
+		return "download.link_expired"
+	case errors.Is(err, download.ErrRevoked):
+		return "download.link_revoked"
+	case errors.Is(err, download.ErrAddressMismatch):
+		return "download.link_address_mismatch"
+	default:
+		return "download.link_access_denied"
+	}
+}
+
+// recordSignedDownloadDeniedAudit는 서명은 맞지만 쓸 수 없는 링크 요청을 기록합니다.
+// 서명이 틀린 요청은 누가 보냈는지 알 수 없으므로 기록하지 않습니다.
+func (h *Handler) recordSignedDownloadDeniedAudit(r *http.Request, claims *download.Claims, err error, status int) {
+	if h.auditRecorder == nil {
+		return
+	}
+
+	action := "file.download-ticket"
+	target := claims.Path
+	if claims.JobID != "" {
+		action = "file.archive-download-ticket"
+		target = claims.JobID
+	}
+	spaceID := claims.SpaceID
+	code := signedDownloadDeniedCode(err)
+	h.auditRecorder.RecordBestEffort(audit.Event{
+		Actor:     claims.Owner,
+		Action:    action,
+		Result:    audit.ResultDenied,
+		Target:    target,
+		RequestID: strings.TrimSpace(r.Header.Get("X-Request-Id")),
+		SpaceID:   &spaceID,
+		Metadata: map[string]any{
+			"reason": strings.TrimPrefix(code, "download."),
+			"code":   code,
+			"status": status,
+		},
+	})
+}
+
+// archiveSignedLinkTTL은 아카이브 결과물 링크가 결과물 보관 기간보다 오래 남지 않도록 유효 기간을 줄입니다.
+func archiveSignedLinkTTL(archiveJob *job.Job, requested time.Duration) time.Duration {
+	if archiveJob.ExpiresAt == nil {
+		return requested
+	}
+	remaining := time.Until(*archiveJob.ExpiresAt)
+	if remaining < requested {
+		return max(remaining, time.Second)
+	}
+	return requested
+}
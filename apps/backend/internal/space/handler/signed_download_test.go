@@ -0,0 +1,100 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"taeu.kr/cohesion/internal/download"
+	"taeu.kr/cohesion/internal/space"
+)
+
+func newSignedDownloadTestHandler(t *testing.T, signer *download.Signer) *Handler {
+	t.Helper()
+
+	spaceRoot := t.TempDir()
+	if err := os.WriteFile(filepath.Join(spaceRoot, "video.bin"), []byte("0123456789"), 0o644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	store := &fakeTransferSpaceStore{
+		spacesByID: map[int64]*space.Space{
+			1: {ID: 1, SpaceName: "Media", SpacePath: spaceRoot},
+		},
+	}
+	handler := NewHandler(space.NewService(store), nil, nil)
+	handler.SetDownloadSigner(signer)
+	return handler
+}
+
+func issueSignedDownloadURL(t *testing.T, handler *Handler, body string) string {
+	t.Helper()
+
+	req := withClaims(httptest.NewRequest(http.MethodPost, "/api/spaces/1/files/download-ticket", strings.NewReader(body)), "tester")
+	req.RemoteAddr = "192.0.2.10:50000"
+	rec := httptest.NewRecorder()
+	if webErr := handler.handleFileDownloadTicket(rec, req, 1); webErr != nil {
+		t.Fatalf("unexpected ticket error: %+v", webErr)
+	}
+	var response struct {
+		DownloadURL string `json:"downloadUrl"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode ticket response: %v", err)
+	}
+	if !strings.HasPrefix(response.DownloadURL, signedDownloadPathPrefix) {
+		t.Fatalf("expected signed download URL, got %q", response.DownloadURL)
+	}
+	return response.DownloadURL
+}
+
+func TestHandleSignedDownload_SupportsRepeatedRangeRequestsAcrossRestart(t *testing.T) {
+	store := download.NewMemoryStore()
+	downloadURL := issueSignedDownloadURL(t, newSignedDownloadTestHandler(t, download.NewSigner(store)), `{"path":"video.bin"}`)
+
+	// 새 핸들러와 Signer는 재시작된 서버와 같다. 쿠키 없이 여러 번 이어받을 수 있어야 한다.
+	restarted := newSignedDownloadTestHandler(t, download.NewSigner(store))
+	for _, rangeHeader := range []string{"bytes=0-3", "bytes=4-"} {
+		req := httptest.NewRequest(http.MethodGet, downloadURL, nil)
+		req.Header.Set("Range", rangeHeader)
+		rec := httptest.NewRecorder()
+		if webErr := restarted.handleSignedDownload(rec, req); webErr != nil {
+			t.Fatalf("unexpected signed download error for %s: %+v", rangeHeader, webErr)
+		}
+		if rec.Code != http.StatusPartialContent {
+			t.Fatalf("expected 206 for %s, got %d", rangeHeader, rec.Code)
+		}
+	}
+}
+
+func TestHandleSignedDownload_RejectsRevokedAndAddressBoundLinks(t *testing.T) {
+	signer := download.NewSigner(download.NewMemoryStore())
+	handler := newSignedDownloadTestHandler(t, signer)
+
+	boundURL := issueSignedDownloadURL(t, handler, `{"path":"video.bin","bindIp":true}`)
+	req := httptest.NewRequest(http.MethodGet, boundURL, nil)
+	req.RemoteAddr = "198.51.100.7:40000"
+	if webErr := handler.handleSignedDownload(httptest.NewRecorder(), req); webErr == nil || webErr.Code != http.StatusForbidden {
+		t.Fatalf("expected 403 for another address, got %+v", webErr)
+	}
+	req = httptest.NewRequest(http.MethodGet, boundURL, nil)
+	req.RemoteAddr = "192.0.2.10:50001"
+	rec := httptest.NewRecorder()
+	if webErr := handler.handleSignedDownload(rec, req); webErr != nil || rec.Body.String() != "0123456789" {
+		t.Fatalf("expected bound address to download, got %+v %q", webErr, rec.Body.String())
+	}
+
+	if err := signer.RevokeOwner(context.Background(), "tester"); err != nil {
+		t.Fatalf("failed to revoke: %v", err)
+	}
+	req = httptest.NewRequest(http.MethodGet, boundURL, nil)
+	req.RemoteAddr = "192.0.2.10:50001"
+	webErr := handler.handleSignedDownload(httptest.NewRecorder(), req)
+	if webErr == nil || webErr.Code != http.StatusForbidden || webErr.Message != "Download link has been revoked" {
+		t.Fatalf("expected revoked link to be rejected, got %+v", webErr)
+	}
+}
@@ -0,0 +1,131 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"taeu.kr/cohesion/internal/account"
+	"taeu.kr/cohesion/internal/platform/logging"
+	"taeu.kr/cohesion/internal/platform/web"
+	"taeu.kr/cohesion/internal/space"
+	"taeu.kr/cohesion/internal/space/usage"
+)
+
+const (
+	defaultUsageHistoryDays = 90
+	maxUsageHistoryDays     = 400
+)
+
+// searchIndexEntryLister는 Space의 색인 항목 전체를 돌려줄 수 있는 색인입니다.
+// space.SearchIndexManager가 구현하며, 없으면 분석은 직접 스캔으로 대체합니다.
+type searchIndexEntryLister interface {
+	SpaceEntries(ctx context.Context, spaceID int64) ([]space.SearchIndexEntry, error)
+}
+
+// SetUsageSnapshotStore는 하루 사용량 기록 저장소를 교체합니다. 기본값은 메모리 저장소입니다.
+func (h *Handler) SetUsageSnapshotStore(store usage.Storer) {
+	h.usageSnapshots = store
+}
+
+type spaceAnalyticsResponse struct {
+	*space.StorageAnalytics
+	// Source는 index(검색 색인) 또는 scan(직접 스캔)입니다.
+	Source      string    `json:"source"`
+	GeneratedAt time.Time `json:"generatedAt"`
+}
+
+type spaceUsageHistoryResponse struct {
+	SpaceID    int64             `json:"spaceId"`
+	QuotaBytes *int64            `json:"quotaBytes,omitempty"`
+	Snapshots  []*usage.Snapshot `json:"snapshots"`
+	Forecast   usage.Forecast    `json:"forecast"`
+}
+
+// handleSpaceAnalytics는 /api/spaces/{id}/analytics[/history] 요청을 처리합니다.
+func (h *Handler) handleSpaceAnalytics(w http.ResponseWriter, r *http.Request, spaceID int64, action string) *web.Error {
+	if r.Method != http.MethodGet {
+		return &web.Error{Code: http.StatusMethodNotAllowed, Message: "Method not allowed"}
+	}
+	if webErr := h.ensureSpacePermission(r, spaceID, account.PermissionRead); webErr != nil {
+		return webErr
+	}
+	spaceData, err := h.spaceService.GetSpaceByID(r.Context(), spaceID)
+	if err != nil {
+		return &web.Error{Code: http.StatusNotFound, Message: "Space not found", Err: err}
+	}
+
+	switch action {
+	case "":
+		return h.handleSpaceAnalyticsSummary(w, r, spaceData)
+	case "history":
+		return h.handleSpaceUsageHistory(w, r, spaceData)
+	default:
+		return &web.Error{Code: http.StatusNotFound, Message: "Not found"}
+	}
+}
+
+func (h *Handler) handleSpaceAnalyticsSummary(w http.ResponseWriter, r *http.Request, spaceData *space.Space) *web.Error {
+	top := space.DefaultAnalyticsTop
+	if raw := r.URL.Query().Get("top"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed <= 0 {
+			return &web.Error{Code: http.StatusBadRequest, Message: "Invalid top"}
+		}
+		top = parsed
+	}
+
+	entries, source, err := h.spaceAnalyticsEntries(r.Context(), spaceData)
+	if err != nil {
+		return &web.Error{Code: http.StatusInternalServerError, Message: "Failed to analyze space", Err: err}
+	}
+
+	now := time.Now()
+	return writeJSON(w, http.StatusOK, spaceAnalyticsResponse{
+		StorageAnalytics: space.BuildStorageAnalytics(spaceData.ID, entries, now, top),
+		Source:           source,
+		GeneratedAt:      now.UTC(),
+	})
+}
+
+// spaceAnalyticsEntries는 가능하면 검색 색인을, 색인이 없거나 갱신에 실패하면 직접 스캔 결과를 사용합니다.
+func (h *Handler) spaceAnalyticsEntries(ctx context.Context, spaceData *space.Space) ([]space.SearchIndexEntry, string, error) {
+	if lister, ok := h.searchIndexer.(searchIndexEntryLister); ok {
+		entries, err := lister.SpaceEntries(ctx, spaceData.ID)
+		if err == nil {
+			return entries, "index", nil
+		}
+		logging.Event(log.Warn(), logging.ComponentStorage, "warn.space.analytics_index_unavailable").
+			Err(err).
+			Int64("space_id", spaceData.ID).
+			Msg("search index unavailable for analytics, falling back to scan")
+	}
+	entries, err := space.ScanSpaceEntries(spaceData)
+	return entries, "scan", err
+}
+
+func (h *Handler) handleSpaceUsageHistory(w http.ResponseWriter, r *http.Request, spaceData *space.Space) *web.Error {
+	days := defaultUsageHistoryDays
+	if raw := r.URL.Query().Get("days"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed <= 0 {
+			return &web.Error{Code: http.StatusBadRequest, Message: "Invalid days"}
+		}
+		days = min(parsed, maxUsageHistoryDays)
+	}
+
+	now := time.Now()
+	snapshots, err := h.usageSnapshots.List(r.Context(), spaceData.ID, usage.Day(now).AddDate(0, 0, -(days-1)))
+	if err != nil {
+		return &web.Error{Code: http.StatusInternalServerError, Message: "Failed to get usage history", Err: err}
+	}
+
+	return writeJSON(w, http.StatusOK, spaceUsageHistoryResponse{
+		SpaceID:    spaceData.ID,
+		QuotaBytes: spaceData.QuotaBytes,
+		Snapshots:  snapshots,
+		Forecast:   usage.ForecastQuota(snapshots, spaceData.QuotaBytes, now),
+	})
+}
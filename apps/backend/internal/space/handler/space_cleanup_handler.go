@@ -0,0 +1,277 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+
+	"taeu.kr/cohesion/internal/audit"
+	"taeu.kr/cohesion/internal/browse"
+	"taeu.kr/cohesion/internal/platform/web"
+	"taeu.kr/cohesion/internal/space"
+)
+
+// SetCleanupService는 스케줄러와 같은 규칙을 보도록 공용 CleanupService로 교체합니다.
+func (h *Handler) SetCleanupService(cleanup *space.CleanupService) {
+	if cleanup != nil {
+		h.cleanup = cleanup
+	}
+}
+
+// handleSpaceCleanupRules는 /api/spaces/{id}/cleanup-rules[/{ruleId}[/dry-run]] 요청을 처리합니다.
+//   - GET    /cleanup-rules                 : 규칙 목록
+//   - POST   /cleanup-rules                 : { pathPrefix, maxAgeDays, pattern?, action?, enabled? } 규칙 추가
+//   - GET    /cleanup-rules/{ruleId}         : 규칙 하나
+//   - PUT    /cleanup-rules/{ruleId}         : 규칙을 통째로 바꿉니다.
+//   - DELETE /cleanup-rules/{ruleId}         : 규칙 삭제
+//   - GET    /cleanup-rules/{ruleId}/dry-run : 지금 실행하면 치울 파일 보고서
+func (h *Handler) handleSpaceCleanupRules(w http.ResponseWriter, r *http.Request, spaceID int64, parts []string) *web.Error {
+	if len(parts) == 0 {
+		switch r.Method {
+		case http.MethodGet:
+			if _, err := h.spaceService.GetSpaceByID(r.Context(), spaceID); err != nil {
+				return spaceLookupWebError(err)
+			}
+			rules, err := h.cleanup.ListRules(r.Context(), spaceID)
+			if err != nil {
+				return &web.Error{Code: http.StatusInternalServerError, Message: "Failed to list cleanup rules", Err: err}
+			}
+			return writeJSON(w, http.StatusOK, rules)
+		case http.MethodPost:
+			return h.handleCleanupRuleCreate(w, r, spaceID)
+		default:
+			return &web.Error{Code: http.StatusMethodNotAllowed, Message: "Method not allowed"}
+		}
+	}
+
+	ruleID, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil || ruleID <= 0 {
+		return &web.Error{Code: http.StatusBadRequest, Message: "Invalid cleanup rule ID", Err: err}
+	}
+	if len(parts) > 1 {
+		if parts[1] != "dry-run" || len(parts) > 2 {
+			return &web.Error{Code: http.StatusNotFound, Message: "Not found"}
+		}
+		if r.Method != http.MethodGet {
+			return &web.Error{Code: http.StatusMethodNotAllowed, Message: "Method not allowed"}
+		}
+		return h.handleCleanupRuleDryRun(w, r, spaceID, ruleID)
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		if _, err := h.spaceService.GetSpaceByID(r.Context(), spaceID); err != nil {
+			return spaceLookupWebError(err)
+		}
+		rule, err := h.cleanup.GetRule(r.Context(), spaceID, ruleID)
+		if err != nil {
+			return cleanupRuleWebError(err, "Failed to get cleanup rule")
+		}
+		return writeJSON(w, http.StatusOK, rule)
+	case http.MethodPut:
+		return h.handleCleanupRuleUpdate(w, r, spaceID, ruleID)
+	case http.MethodDelete:
+		return h.handleCleanupRuleDelete(w, r, spaceID, ruleID)
+	default:
+		return &web.Error{Code: http.StatusMethodNotAllowed, Message: "Method not allowed"}
+	}
+}
+
+func (h *Handler) handleCleanupRuleCreate(w http.ResponseWriter, r *http.Request, spaceID int64) *web.Error {
+	var req space.CleanupRuleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return &web.Error{Code: http.StatusBadRequest, Message: "Invalid request body", Err: err}
+	}
+	username, webErr := claimsUsernameFromRequest(r)
+	if webErr != nil {
+		return webErr
+	}
+	if _, err := h.spaceService.GetSpaceByID(r.Context(), spaceID); err != nil {
+		return spaceLookupWebError(err)
+	}
+	if err := ensurePathOutsideTrash(normalizeRelativePath(req.PathPrefix)); err != nil {
+		return &web.Error{Code: http.StatusForbidden, Message: "Access denied: invalid path", Err: err}
+	}
+
+	rule, err := h.cleanup.CreateRule(r.Context(), spaceID, req, username)
+	if err != nil {
+		h.recordSpaceAudit(r, audit.Event{
+			Action:   "space.cleanup-rule.create",
+			Result:   audit.ResultFailure,
+			Target:   fmt.Sprintf("space:%d", spaceID),
+			Metadata: map[string]any{"pathPrefix": req.PathPrefix, "reason": err.Error()},
+		}, spaceID)
+		return cleanupRuleWebError(err, "Failed to create cleanup rule")
+	}
+
+	h.recordSpaceAudit(r, audit.Event{
+		Action:   "space.cleanup-rule.create",
+		Result:   audit.ResultSuccess,
+		Target:   fmt.Sprintf("space:%d", spaceID),
+		Metadata: cleanupRuleAuditMetadata(rule),
+	}, spaceID)
+	return writeJSON(w, http.StatusCreated, rule)
+}
+
+func (h *Handler) handleCleanupRuleUpdate(w http.ResponseWriter, r *http.Request, spaceID, ruleID int64) *web.Error {
+	var req space.CleanupRuleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return &web.Error{Code: http.StatusBadRequest, Message: "Invalid request body", Err: err}
+	}
+	if _, err := h.spaceService.GetSpaceByID(r.Context(), spaceID); err != nil {
+		return spaceLookupWebError(err)
+	}
+	if err := ensurePathOutsideTrash(normalizeRelativePath(req.PathPrefix)); err != nil {
+		return &web.Error{Code: http.StatusForbidden, Message: "Access denied: invalid path", Err: err}
+	}
+
+	rule, previous, err := h.cleanup.UpdateRule(r.Context(), spaceID, ruleID, req)
+	if err != nil {
+		h.recordSpaceAudit(r, audit.Event{
+			Action:   "space.cleanup-rule.update",
+			Result:   audit.ResultFailure,
+			Target:   fmt.Sprintf("space:%d", spaceID),
+			Metadata: map[string]any{"ruleId": ruleID, "pathPrefix": req.PathPrefix, "reason": err.Error()},
+		}, spaceID)
+		return cleanupRuleWebError(err, "Failed to update cleanup rule")
+	}
+
+	metadata := cleanupRuleAuditMetadata(rule)
+	metadata["previousPathPrefix"] = previous.PathPrefix
+	metadata["previousMaxAgeDays"] = previous.MaxAgeDays
+	metadata["previousAction"] = string(previous.Action)
+	metadata["previousEnabled"] = previous.Enabled
+	h.recordSpaceAudit(r, audit.Event{
+		Action:   "space.cleanup-rule.update",
+		Result:   audit.ResultSuccess,
+		Target:   fmt.Sprintf("space:%d", spaceID),
+		Metadata: metadata,
+	}, spaceID)
+	return writeJSON(w, http.StatusOK, rule)
+}
+
+func (h *Handler) handleCleanupRuleDelete(w http.ResponseWriter, r *http.Request, spaceID, ruleID int64) *web.Error {
+	if _, err := h.spaceService.GetSpaceByID(r.Context(), spaceID); err != nil {
+		return spaceLookupWebError(err)
+	}
+
+	rule, err := h.cleanup.DeleteRule(r.Context(), spaceID, ruleID)
+	if err != nil {
+		h.recordSpaceAudit(r, audit.Event{
+			Action:   "space.cleanup-rule.delete",
+			Result:   audit.ResultFailure,
+			Target:   fmt.Sprintf("space:%d", spaceID),
+			Metadata: map[string]any{"ruleId": ruleID, "reason": err.Error()},
+		}, spaceID)
+		return cleanupRuleWebError(err, "Failed to delete cleanup rule")
+	}
+
+	h.recordSpaceAudit(r, audit.Event{
+		Action:   "space.cleanup-rule.delete",
+		Result:   audit.ResultSuccess,
+		Target:   fmt.Sprintf("space:%d", spaceID),
+		Metadata: cleanupRuleAuditMetadata(rule),
+	}, spaceID)
+	return writeJSON(w, http.StatusOK, map[string]any{"id": rule.ID, "status": "deleted"})
+}
+
+func (h *Handler) handleCleanupRuleDryRun(w http.ResponseWriter, r *http.Request, spaceID, ruleID int64) *web.Error {
+	if webErr := h.ensureSpaceReadable(r, spaceID); webErr != nil {
+		return webErr
+	}
+	spaceData, err := h.spaceService.GetSpaceByID(r.Context(), spaceID)
+	if err != nil {
+		return spaceLookupWebError(err)
+	}
+	rule, err := h.cleanup.GetRule(r.Context(), spaceID, ruleID)
+	if err != nil {
+		return cleanupRuleWebError(err, "Failed to get cleanup rule")
+	}
+
+	report, err := h.cleanup.DryRun(r.Context(), spaceData, rule)
+	if err != nil {
+		if browse.IsPermissionError(err) {
+			return &web.Error{Code: http.StatusForbidden, Message: "Permission denied", Err: err}
+		}
+		return &web.Error{Code: http.StatusInternalServerError, Message: "Failed to evaluate cleanup rule", Err: err}
+	}
+	return writeJSON(w, http.StatusOK, report)
+}
+
+// RemoveExpiredFile은 space.CleanupRemover를 구현합니다.
+// trash는 사용자가 지운 것처럼 휴지통으로 옮기고, delete는 파일을 바로 지우며 사용량과 소유자 기록도 함께 줄입니다.
+func (h *Handler) RemoveExpiredFile(ctx context.Context, spaceObj *space.Space, relPath string, action space.CleanupAction) error {
+	switch action {
+	case space.CleanupActionTrash:
+		if _, err := h.moveToTrash(ctx, spaceObj, relPath, space.CleanupActor); err != nil {
+			return err
+		}
+	case space.CleanupActionDelete:
+		if err := h.deleteExpiredFile(ctx, spaceObj, relPath); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unknown cleanup action: %s", action)
+	}
+	h.markSearchIndexDirty(ctx, spaceObj.ID, "cleanup")
+	return nil
+}
+
+func (h *Handler) deleteExpiredFile(ctx context.Context, spaceObj *space.Space, relPath string) error {
+	normalizedPath := normalizeRelativePath(relPath)
+	if err := ensurePathOutsideTrash(normalizedPath); err != nil {
+		return err
+	}
+	absPath, err := resolveAbsPath(spaceObj.SpacePath, normalizedPath)
+	if err != nil {
+		return fmt.Errorf("access denied: invalid path")
+	}
+
+	info, err := os.Lstat(absPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return errors.New("File or directory not found")
+		}
+		return errors.New(safeFilesystemReason("Failed to access file", err))
+	}
+	if !info.Mode().IsRegular() {
+		return errors.New("Only regular files can be removed")
+	}
+	if err := h.checkRetentionAs(ctx, space.CleanupActor, spaceObj.ID, spaceObj.SpacePath, absPath, "delete"); err != nil {
+		return err
+	}
+	if err := os.Remove(absPath); err != nil {
+		return errors.New(safeFilesystemReason("Failed to delete file", err))
+	}
+	h.adjustQuotaUsage(ctx, spaceObj.ID, -info.Size(), -1)
+	h.deleteFileOwners(ctx, spaceObj.ID, spaceObj.SpacePath, absPath)
+	return nil
+}
+
+var _ space.CleanupRemover = (*Handler)(nil)
+
+func cleanupRuleWebError(err error, fallback string) *web.Error {
+	switch {
+	case errors.Is(err, space.ErrInvalidCleanupRule):
+		return &web.Error{Code: http.StatusBadRequest, Message: err.Error(), Err: err}
+	case errors.Is(err, space.ErrCleanupRuleNotFound):
+		return &web.Error{Code: http.StatusNotFound, Message: "Cleanup rule not found", Err: err}
+	default:
+		return &web.Error{Code: http.StatusInternalServerError, Message: fallback, Err: err}
+	}
+}
+
+func cleanupRuleAuditMetadata(rule *space.CleanupRule) map[string]any {
+	return map[string]any{
+		"ruleId":     rule.ID,
+		"pathPrefix": rule.PathPrefix,
+		"maxAgeDays": rule.MaxAgeDays,
+		"pattern":    rule.Pattern,
+		"action":     string(rule.Action),
+		"enabled":    rule.Enabled,
+	}
+}
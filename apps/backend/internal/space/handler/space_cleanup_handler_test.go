@@ -0,0 +1,91 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"taeu.kr/cohesion/internal/space"
+)
+
+func TestCleanupRules_DryRunReportsAndRunMovesExpiredFilesToTrash(t *testing.T) {
+	handler, root := setupTrashHandler(t)
+	if err := os.MkdirAll(filepath.Join(root, "Scan Inbox"), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	expired := filepath.Join(root, "Scan Inbox", "scan-001.pdf")
+	if err := os.WriteFile(expired, []byte("scan"), 0o644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+	old := time.Now().Add(-48 * time.Hour)
+	if err := os.Chtimes(expired, old, old); err != nil {
+		t.Fatalf("chtimes: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "Scan Inbox", "scan-002.pdf"), []byte("scan"), 0o644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+
+	req := newJSONRequestWithClaims(t, http.MethodPost, "/api/spaces/1/cleanup-rules", map[string]any{"pathPrefix": ".cohesion_trash", "maxAgeDays": 1})
+	if webErr := handler.handleSpaceCleanupRules(httptest.NewRecorder(), req, 1, nil); webErr == nil || webErr.Code != http.StatusForbidden {
+		t.Fatalf("expected trash prefix to be rejected with 403, got %+v", webErr)
+	}
+	req = newJSONRequestWithClaims(t, http.MethodPost, "/api/spaces/1/cleanup-rules", map[string]any{"pathPrefix": "Scan Inbox", "maxAgeDays": 0})
+	if webErr := handler.handleSpaceCleanupRules(httptest.NewRecorder(), req, 1, nil); webErr == nil || webErr.Code != http.StatusBadRequest {
+		t.Fatalf("expected zero max age to be rejected with 400, got %+v", webErr)
+	}
+
+	req = newJSONRequestWithClaims(t, http.MethodPost, "/api/spaces/1/cleanup-rules", map[string]any{"pathPrefix": "Scan Inbox", "maxAgeDays": 1, "pattern": "*.pdf"})
+	rec := httptest.NewRecorder()
+	if webErr := handler.handleSpaceCleanupRules(rec, req, 1, nil); webErr != nil {
+		t.Fatalf("create rule: %+v", webErr)
+	}
+	var rule space.CleanupRule
+	if err := json.NewDecoder(rec.Body).Decode(&rule); err != nil {
+		t.Fatalf("decode rule: %v", err)
+	}
+	if rec.Code != http.StatusCreated || rule.CreatedBy != "tester" || rule.Action != space.CleanupActionTrash {
+		t.Fatalf("unexpected created rule (status %d): %+v", rec.Code, rule)
+	}
+
+	dryRunParts := []string{"1", "dry-run"}
+	req = newJSONRequestWithClaims(t, http.MethodGet, "/api/spaces/1/cleanup-rules/1/dry-run", nil)
+	rec = httptest.NewRecorder()
+	if webErr := handler.handleSpaceCleanupRules(rec, req, 1, dryRunParts); webErr != nil {
+		t.Fatalf("dry run: %+v", webErr)
+	}
+	var report space.CleanupReport
+	if err := json.NewDecoder(rec.Body).Decode(&report); err != nil {
+		t.Fatalf("decode report: %v", err)
+	}
+	if !report.DryRun || report.FileCount != 1 || report.Files[0].Path != "Scan Inbox/scan-001.pdf" || report.TotalBytes != 4 {
+		t.Fatalf("unexpected dry run report: %+v", report)
+	}
+	if _, err := os.Stat(expired); err != nil {
+		t.Fatalf("dry run must keep the file, err=%v", err)
+	}
+
+	spaceData, err := handler.spaceService.GetSpaceByID(req.Context(), 1)
+	if err != nil {
+		t.Fatalf("get space: %v", err)
+	}
+	if _, err := handler.cleanup.Run(req.Context(), spaceData, &rule); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if _, err := os.Stat(expired); !os.IsNotExist(err) {
+		t.Fatalf("expired file should be moved into trash, err=%v", err)
+	}
+	items, err := handler.trashService.ListTrashItems(req.Context(), 1)
+	if err != nil {
+		t.Fatalf("list trash: %v", err)
+	}
+	if len(items) != 1 || items[0].OriginalPath != "Scan Inbox/scan-001.pdf" || items[0].DeletedBy != space.CleanupActor {
+		t.Fatalf("unexpected trash items: %+v", items)
+	}
+	if _, err := os.Stat(filepath.Join(root, "Scan Inbox", "scan-002.pdf")); err != nil {
+		t.Fatalf("fresh file should remain, err=%v", err)
+	}
+}
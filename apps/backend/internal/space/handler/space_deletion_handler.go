@@ -0,0 +1,338 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	"taeu.kr/cohesion/internal/audit"
+	"taeu.kr/cohesion/internal/job"
+	"taeu.kr/cohesion/internal/platform/logging"
+	"taeu.kr/cohesion/internal/platform/web"
+	"taeu.kr/cohesion/internal/space"
+)
+
+// SpaceDeletionJobType은 Space를 보관하거나 데이터까지 지우는 작업 유형입니다.
+const SpaceDeletionJobType = "space.delete"
+
+func (h *Handler) registerSpaceDeletionJobType() {
+	h.jobs.Register(SpaceDeletionJobType, job.TypeConfig{
+		Concurrency: defaultSpaceJobWorkerLimit,
+		MaxAttempts: defaultSpaceJobMaxAttempts,
+		ArtifactTTL: defaultSpaceJobTTL,
+		Run:         h.runSpaceDeletionJob,
+		OnFinish:    h.finishSpaceDeletionJob,
+	})
+}
+
+// handleSpaceDeletion은 /api/spaces/{id}/deletion 요청(작업 상태 조회/취소)을 처리합니다.
+func (h *Handler) handleSpaceDeletion(w http.ResponseWriter, r *http.Request, spaceID int64) *web.Error {
+	switch r.Method {
+	case http.MethodGet:
+		found, webErr := h.lookupSpaceJob(r, SpaceDeletionJobType, spaceID, "Deletion job not found")
+		if webErr != nil {
+			return webErr
+		}
+		return writeJSON(w, http.StatusOK, deletionJobFromJob(found))
+	case http.MethodDelete:
+		return h.handleSpaceDeletionCancel(w, r, spaceID)
+	default:
+		return &web.Error{Code: http.StatusMethodNotAllowed, Message: "Method not allowed"}
+	}
+}
+
+func (h *Handler) handleSpaceDeletionStart(w http.ResponseWriter, r *http.Request, spaceID int64, req *space.DeleteSpaceRequest) *web.Error {
+	username, webErr := claimsUsernameFromRequest(r)
+	if webErr != nil {
+		return webErr
+	}
+
+	var created *job.Job
+	plan, err := h.deletions.Prepare(r.Context(), spaceID, req)
+	if err == nil {
+		created, err = h.enqueueSpaceJob(r, SpaceDeletionJobType, spaceID, username, plan, nil)
+	}
+	if err != nil {
+		h.recordSpaceAudit(r, audit.Event{
+			Action: "space.delete",
+			Result: audit.ResultFailure,
+			Target: fmt.Sprintf("space:%d", spaceID),
+			Metadata: map[string]any{
+				"mode":        string(req.Mode),
+				"archivePath": req.ArchivePath,
+				"status":      "rejected",
+				"reason":      deletionFailureReason(err),
+			},
+		}, spaceID)
+
+		statusCode := http.StatusInternalServerError
+		message := "Failed to start space deletion"
+		switch {
+		case errors.Is(err, space.ErrSpaceValidation):
+			statusCode = http.StatusBadRequest
+			message = strings.TrimPrefix(err.Error(), "validation failed: ")
+		case errors.Is(err, errSpaceJobInProgress):
+			statusCode = http.StatusConflict
+			message = "Space deletion is already in progress"
+		case errors.Is(err, errSpaceJobConflict):
+			statusCode = http.StatusConflict
+			message = "Space relocation is in progress"
+		case errors.Is(err, space.ErrNestedSpaceRoot):
+			statusCode = http.StatusConflict
+			message = "Space root contains another Space's root"
+		case errors.Is(err, space.ErrSpaceNotFound):
+			statusCode = http.StatusNotFound
+			message = "Space not found"
+		case errors.Is(err, space.ErrInvalidSpaceID):
+			statusCode = http.StatusBadRequest
+			message = "Invalid Space request"
+		}
+		return &web.Error{Code: statusCode, Message: message, Err: err}
+	}
+
+	job := deletionJobFromJob(created)
+	h.logDeletionEvent("info.space.deletion_started", job)
+	h.recordSpaceAudit(r, audit.Event{
+		Action: "space.delete",
+		Result: audit.ResultSuccess,
+		Target: fmt.Sprintf("space:%d", spaceID),
+		Metadata: map[string]any{
+			"jobId":       job.ID,
+			"mode":        string(job.Mode),
+			"sourcePath":  job.SourcePath,
+			"archivePath": job.ArchivePath,
+			"status":      string(job.Status),
+		},
+	}, spaceID)
+
+	return writeJSON(w, http.StatusAccepted, job)
+}
+
+func (h *Handler) handleSpaceDeletionCancel(w http.ResponseWriter, r *http.Request, spaceID int64) *web.Error {
+	found, webErr := h.lookupSpaceJob(r, SpaceDeletionJobType, spaceID, "Deletion job not found")
+	if webErr != nil {
+		return webErr
+	}
+
+	// 재시작 후 대기열로 돌아온 작업도 등록 해제 단계에 들어섰으면 끝까지 진행해야 한다.
+	var (
+		progress    space.DeletionJob
+		canceledJob *job.Job
+		err         error
+	)
+	_ = found.DecodeResult(&progress)
+	if progress.Detached || progress.Status == space.DeletionStateDetaching || progress.Status == space.DeletionStateRemoving {
+		err = job.ErrNotCancelable
+	} else {
+		canceledJob, err = h.cancelSpaceJob(r.Context(), found)
+	}
+	if err != nil {
+		if errors.Is(err, job.ErrNotCancelable) {
+			return &web.Error{Code: http.StatusConflict, Message: "Space deletion can no longer be canceled", Err: err}
+		}
+		return &web.Error{Code: http.StatusInternalServerError, Message: "Failed to cancel space deletion", Err: err}
+	}
+	canceled := deletionJobFromJob(canceledJob)
+	if found.Status == job.StatusQueued {
+		if err := h.deletions.Discard(canceled); err != nil {
+			logging.Event(log.Warn(), logging.ComponentStorage, "warn.space.deletion_cleanup_failed").
+				Str("job_id", canceled.ID).
+				Int64("space_id", canceled.SpaceID).
+				Err(err).
+				Msg("failed to remove partial archive after cancel")
+		}
+	}
+
+	h.recordSpaceAudit(r, audit.Event{
+		Action: "space.delete.cancel",
+		Result: audit.ResultSuccess,
+		Target: fmt.Sprintf("space:%d", spaceID),
+		Metadata: map[string]any{
+			"jobId":          canceled.ID,
+			"mode":           string(canceled.Mode),
+			"status":         string(canceled.Status),
+			"processedItems": canceled.ProcessedItems,
+			"processedBytes": canceled.ProcessedBytes,
+		},
+	}, spaceID)
+	return writeJSON(w, http.StatusOK, canceled)
+}
+
+// runSpaceDeletionJob은 작업 큐에서 호출되는 Space 삭제 실행 함수입니다.
+// 지난 시도가 남긴 단계별 상태를 함께 넘겨 DeletionManager.Run이 이어서 처리하게 합니다.
+func (h *Handler) runSpaceDeletionJob(ctx context.Context, run *job.Run) error {
+	current := run.Job()
+	if current == nil {
+		return job.Permanent(errors.New("deletion job is no longer running"))
+	}
+	var state space.DeletionJob
+	if err := current.DecodePayload(&state); err != nil {
+		return job.Permanent(fmt.Errorf("invalid deletion job payload: %w", err))
+	}
+	if err := current.DecodeResult(&state); err != nil {
+		return job.Permanent(fmt.Errorf("invalid deletion job result: %w", err))
+	}
+
+	err := h.deletions.Run(ctx, &state, run)
+	if errors.Is(err, space.ErrSpaceNotFound) || errors.Is(err, space.ErrRelocationSourceChanged) || errors.Is(err, space.ErrNestedSpaceRoot) {
+		return job.Permanent(err)
+	}
+	return err
+}
+
+// finishSpaceDeletionJob은 삭제 작업이 끝나면 남은 임시 파일을 정리하고,
+// 등록 해제까지 진행했으면 Space에 딸린 다른 작업과 캐시를 정리한 뒤 결과를 기록합니다.
+func (h *Handler) finishSpaceDeletionJob(finished *job.Job) {
+	job := deletionJobFromJob(finished)
+	if job.Status != space.DeletionStateCompleted {
+		if err := h.deletions.Discard(job); err != nil {
+			job.FailureReason += fmt.Sprintf(" (archive cleanup failed: %v)", err)
+		}
+	}
+
+	discardedArchives := 0
+	if job.Detached {
+		discardedArchives = h.discardSpaceJobs(job.SpaceID, SpaceDeletionJobType)
+		h.invalidateQuotaForSpaces(job.SpaceID)
+		if h.searchIndexer != nil {
+			if err := h.searchIndexer.MarkAllDirty(context.Background()); err != nil {
+				logging.Event(log.Warn(), logging.ComponentStorage, "warn.space.deletion_search_index_failed").
+					Str("job_id", job.ID).
+					Int64("space_id", job.SpaceID).
+					Err(err).
+					Msg("failed to mark search index dirty after space deletion")
+			}
+		}
+	}
+
+	result := audit.ResultSuccess
+	eventName := "info.space.deletion_completed"
+	switch job.Status {
+	case space.DeletionStateFailed:
+		result = audit.ResultFailure
+		if job.Detached {
+			result = audit.ResultPartial
+		}
+		eventName = "warn.space.deletion_failed"
+	case space.DeletionStateCanceled:
+		result = audit.ResultPartial
+		eventName = "info.space.deletion_canceled"
+	case space.DeletionStateCompleted:
+		if job.FailureReason != "" {
+			result = audit.ResultPartial
+		}
+	}
+
+	h.logDeletionEvent(eventName, job)
+	h.recordSpaceAuditBackground(job.Owner, job.RequestID, audit.Event{
+		Action:  "space.delete",
+		Result:  result,
+		Target:  fmt.Sprintf("space:%d", job.SpaceID),
+		SpaceID: &job.SpaceID,
+		Metadata: map[string]any{
+			"jobId":             job.ID,
+			"mode":              string(job.Mode),
+			"spaceName":         job.SpaceName,
+			"spaceSlug":         job.SpaceSlug,
+			"sourcePath":        job.SourcePath,
+			"archivePath":       job.ArchivePath,
+			"archiveSize":       job.ArchiveSize,
+			"status":            string(job.Status),
+			"reason":            job.FailureReason,
+			"detached":          job.Detached,
+			"totalItems":        job.TotalItems,
+			"totalBytes":        job.TotalBytes,
+			"removedFiles":      job.RemovedFiles,
+			"removedDirs":       job.RemovedDirs,
+			"removedBytes":      job.RemovedBytes,
+			"removedEntries":    job.RemovedEntries,
+			"rootRemoved":       job.RootRemoved,
+			"trashItemsRemoved": job.TrashItemsRemoved,
+			"archiveDownloads":  discardedArchives,
+		},
+	})
+}
+
+func (h *Handler) logDeletionEvent(eventName string, job *space.DeletionJob) {
+	if job == nil {
+		return
+	}
+
+	logger := logging.Event(log.Info(), logging.ComponentStorage, eventName)
+	if job.Status == space.DeletionStateFailed {
+		logger = logging.Event(log.Warn(), logging.ComponentStorage, eventName).Str("reason", job.FailureReason)
+	}
+	logger.
+		Str("job_id", job.ID).
+		Int64("space_id", job.SpaceID).
+		Str("owner", job.Owner).
+		Str("mode", string(job.Mode)).
+		Str("status", string(job.Status)).
+		Str("source_path", job.SourcePath).
+		Str("archive_path", job.ArchivePath).
+		Int("removed_files", job.RemovedFiles).
+		Int("removed_dirs", job.RemovedDirs).
+		Int64("removed_bytes", job.RemovedBytes).
+		Msg("space deletion job updated")
+}
+
+// deletionJobFromJob은 작업 큐 기록을 Space 삭제 응답으로 바꿉니다.
+// 계획(payload) 위에 실행이 남긴 단계별 상태(result)를 덮고, 진행률과 시각은 작업 기록을 따릅니다.
+func deletionJobFromJob(item *job.Job) *space.DeletionJob {
+	var deletion space.DeletionJob
+	_ = item.DecodePayload(&deletion)
+	_ = item.DecodeResult(&deletion)
+	deletion.ID = item.ID
+	if item.SpaceID != nil {
+		deletion.SpaceID = *item.SpaceID
+	}
+	deletion.Owner = item.Owner
+	deletion.RequestID = item.RequestID
+	deletion.TotalItems = item.Progress.TotalItems
+	deletion.ProcessedItems = item.Progress.ProcessedItems
+	deletion.TotalBytes = item.Progress.TotalBytes
+	deletion.ProcessedBytes = item.Progress.ProcessedBytes
+	deletion.CreatedAt = item.CreatedAt
+	deletion.UpdatedAt = item.UpdatedAt
+	deletion.FinishedAt = item.FinishedAt
+
+	switch item.Status {
+	case job.StatusQueued:
+		deletion.Status = space.DeletionStateQueued
+	case job.StatusRunning:
+		if deletion.IsFinished() {
+			deletion.Status = space.DeletionStateQueued
+		}
+	case job.StatusCompleted:
+		deletion.Status = space.DeletionStateCompleted
+	case job.StatusFailed:
+		deletion.Status = space.DeletionStateFailed
+		deletion.FailureReason = item.FailureReason
+	case job.StatusCanceled:
+		deletion.Status = space.DeletionStateCanceled
+		deletion.FailureReason = "deletion canceled"
+	}
+	return &deletion
+}
+
+func deletionFailureReason(err error) string {
+	switch {
+	case errors.Is(err, errSpaceJobInProgress):
+		return "in_progress"
+	case errors.Is(err, errSpaceJobConflict):
+		return "job_conflict"
+	case errors.Is(err, space.ErrNestedSpaceRoot):
+		return "nested_space_root"
+	case errors.Is(err, space.ErrSpaceNotFound):
+		return "space_not_found"
+	case errors.Is(err, space.ErrDeletionConfirmationMismatch):
+		return "confirmation_mismatch"
+	case errors.Is(err, space.ErrSpaceValidation):
+		return "invalid_request"
+	default:
+		return "start_failed"
+	}
+}
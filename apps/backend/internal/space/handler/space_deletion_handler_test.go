@@ -0,0 +1,77 @@
+package handler
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"taeu.kr/cohesion/internal/space"
+)
+
+func TestHandleDeleteSpace_RejectsInvalidDispositionRequests(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		expectedStatus int
+	}{
+		{
+			name:           "unknown mode",
+			body:           `{"mode":"shred"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "archive without path",
+			body:           `{"mode":"archive"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "delete data with wrong confirmation",
+			body:           `{"mode":"delete_data","confirmation":"Alpha"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "malformed body",
+			body:           `{"mode":`,
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			store := &fakeRenameSpaceStore{
+				spacesByID: map[int64]*space.Space{
+					1: {ID: 1, SpaceName: "Alpha", SpaceSlug: "alpha", SpacePath: t.TempDir()},
+				},
+			}
+			handler := NewHandler(space.NewService(store), nil, nil)
+
+			req := httptest.NewRequest(http.MethodDelete, "/api/spaces/1", bytes.NewBufferString(tc.body))
+			req = withClaims(req, "admin")
+			rec := httptest.NewRecorder()
+
+			webErr := handler.handleSpaceByID(rec, req)
+			if webErr == nil {
+				t.Fatalf("expected web error, got status %d", rec.Code)
+			}
+			if webErr.Code != tc.expectedStatus {
+				t.Fatalf("expected status %d, got %d (%s)", tc.expectedStatus, webErr.Code, webErr.Message)
+			}
+			if _, ok := store.spacesByID[1]; !ok {
+				t.Fatal("expected space to remain registered")
+			}
+		})
+	}
+}
+
+func TestHandleSpaceDeletion_ReturnsNotFoundWithoutJob(t *testing.T) {
+	handler := NewHandler(space.NewService(&fakeRenameSpaceStore{spacesByID: map[int64]*space.Space{}}), nil, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/spaces/1/deletion", nil)
+	rec := httptest.NewRecorder()
+
+	webErr := handler.handleSpaceByID(rec, req)
+	if webErr == nil || webErr.Code != http.StatusNotFound {
+		t.Fatalf("expected not found, got %+v", webErr)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"os"
 	"path/filepath"
@@ -17,8 +18,13 @@ import (
 	"taeu.kr/cohesion/internal/audit"
 	"taeu.kr/cohesion/internal/auth"
 	"taeu.kr/cohesion/internal/browse"
+	"taeu.kr/cohesion/internal/download"
+	"taeu.kr/cohesion/internal/job"
 	"taeu.kr/cohesion/internal/platform/web"
 	"taeu.kr/cohesion/internal/space"
+	"taeu.kr/cohesion/internal/space/archive"
+	"taeu.kr/cohesion/internal/space/checksum"
+	"taeu.kr/cohesion/internal/space/usage"
 )
 
 // BrowseService 인터페이스 정의 (browse handler 의존성)
@@ -43,8 +49,22 @@ type SearchIndexService interface {
 }
 
 type Handler struct {
-	spaceService      *space.Service
-	quotaService      *space.QuotaService
+	spaceService *space.Service
+	quotaService *space.QuotaService
+	// userQuotaService는 파일 소유자 기록으로 사용자별 사용량과 사용자 할당량을 판단합니다.
+	userQuotaService *space.UserQuotaService
+	// uploadPolicies는 Space별 허용 확장자·형식과 파일 하나의 최대 크기를 확인합니다.
+	uploadPolicies *space.UploadPolicyService
+	// malwareScans는 Space 설정에 따라 업로드한 파일을 검사하고 감염된 파일을 격리합니다.
+	malwareScans *space.MalwareScanService
+	// retention은 폴더별 보존 잠금과 법적 보존을 모든 변경 요청에 적용합니다.
+	retention *space.RetentionService
+	// cleanup은 오래된 파일을 치우는 Space별 자동 정리 규칙입니다. 규칙 실행은 space.CleanupScheduler가 맡습니다.
+	cleanup *space.CleanupService
+	// organize는 새로 놓인 파일을 옮기고 태그를 붙이는 자동 정리 규칙입니다. 규칙 실행은 파일 이벤트 파이프라인이 맡습니다.
+	organize *space.OrganizeService
+	// fileEvents는 업로드를 마친 파일의 이벤트를 받을 곳입니다. 없으면 이벤트를 내지 않습니다.
+	fileEvents        space.FileEventPublisher
 	trashService      *space.TrashService
 	browseService     BrowseService
 	accountService    SpaceAccessService
@@ -52,16 +72,40 @@ type Handler struct {
 	ticketMu          sync.Mutex
 	downloadTickets   map[string]downloadTicket
 	downloadTicketTTL time.Duration
-	archiveDownloads  *archiveDownloadManager
-	auditRecorder     audit.Recorder
+	// downloadSigner는 재시작 후에도 유효한 서명 다운로드 URL을 만들고 검증합니다.
+	downloadSigner *download.Signer
+	// archivePasswords는 암호화 아카이브 작업의 암호입니다. DB에 남기지 않도록 메모리에만 둡니다.
+	archivePasswordMu sync.Mutex
+	archivePasswords  map[string]string
+	// archiveIndexes는 압축 파일 탐색용 항목 목록 캐시입니다.
+	archiveIndexes *archive.Cache
+	// checksums는 파일 해시를 백그라운드에서 계산하고 캐시합니다.
+	checksums *checksum.Service
+	jobs      *job.Manager
+	// spaceJobMu는 같은 Space에 저장소 이전/삭제 작업이 겹쳐 등록되지 않도록 확인과 등록을 묶습니다.
+	spaceJobMu    sync.Mutex
+	relocations   *space.RelocationManager
+	deletions     *space.DeletionManager
+	auditRecorder audit.Recorder
+	// usageSnapshots는 용량 추이 차트와 할당량 도달 예측에 쓰는 하루 사용량 기록입니다.
+	usageSnapshots usage.Storer
 }
 
 type spaceResponse struct {
 	ID            int64   `json:"id"`
 	SpaceName     string  `json:"space_name"`
+	SpaceSlug     string  `json:"space_slug"`
 	Icon          *string `json:"icon,omitempty"`
 	SpaceCategory *string `json:"space_category,omitempty"`
 	QuotaBytes    *int64  `json:"quota_bytes,omitempty"`
+	QuotaFiles    *int64  `json:"quota_files,omitempty"`
+	// QuotaWarningPercents는 적용 중인 경고 비율입니다. 따로 정하지 않았으면 기본값입니다.
+	QuotaWarningPercents []int  `json:"quota_warning_percents"`
+	SpaceState           string `json:"space_state"`
+	// WebDAVArchiveBrowsing은 WebDAV에서 압축 파일을 읽기 전용 폴더로 보여 주는지 여부입니다.
+	WebDAVArchiveBrowsing bool `json:"webdav_archive_browsing"`
+	// RootHealth는 마지막 root 점검 결과입니다. 아직 점검 전이면 생략합니다.
+	RootHealth *space.RootHealth `json:"root_health,omitempty"`
 }
 
 type spaceRootValidationErrorResponse struct {
@@ -76,16 +120,44 @@ func NewHandler(spaceService *space.Service, browseService BrowseService, accoun
 		resolvedTrashService = trashService[0]
 	}
 
-	return &Handler{
+	relocations := space.NewRelocationManager(spaceService)
+	deletions := space.NewDeletionManager(spaceService)
+	if resolvedTrashService != nil {
+		relocations.SetTrashRewriter(resolvedTrashService)
+		deletions.SetTrashPurger(resolvedTrashService)
+	}
+
+	quotaService := space.NewQuotaService(spaceService)
+	h := &Handler{
 		spaceService:      spaceService,
-		quotaService:      space.NewQuotaService(spaceService),
+		quotaService:      quotaService,
+		userQuotaService:  space.NewUserQuotaService(quotaService),
+		uploadPolicies:    space.NewUploadPolicyService(nil),
+		malwareScans:      space.NewMalwareScanService(spaceService, nil, ""),
+		retention:         space.NewRetentionService(nil),
+		cleanup:           space.NewCleanupService(spaceService),
+		organize:          space.NewOrganizeService(spaceService),
 		trashService:      resolvedTrashService,
 		browseService:     browseService,
 		accountService:    accountService,
 		downloadTickets:   make(map[string]downloadTicket),
 		downloadTicketTTL: 5 * time.Minute,
-		archiveDownloads:  newArchiveDownloadManager(defaultArchiveDownloadWorkerLimit, defaultArchiveDownloadTTL),
-	}
+		downloadSigner:    download.NewSigner(download.NewMemoryStore()),
+		archivePasswords:  make(map[string]string),
+		archiveIndexes:    archive.NewCache(16, archive.DefaultMaxEntries),
+		checksums:         checksum.NewService(checksum.NewMemoryStore(), checksum.DefaultConcurrency),
+		jobs:              job.NewManager(job.NewMemoryStore()),
+		relocations:       relocations,
+		deletions:         deletions,
+		usageSnapshots:    usage.NewMemoryStore(),
+	}
+	h.cleanup.SetRemover(h)
+	h.cleanup.SetRetentionService(h.retention)
+	h.organize.SetMover(h)
+	h.organize.SetRetentionService(h.retention)
+	h.organize.SetScanWaiter(h.malwareScans)
+	h.registerJobTypes()
+	return h
 }
 
 func (h *Handler) SetAuditRecorder(recorder audit.Recorder) {
@@ -94,15 +166,21 @@ func (h *Handler) SetAuditRecorder(recorder audit.Recorder) {
 
 func (h *Handler) SetSearchIndexer(indexer SearchIndexService) {
 	h.searchIndexer = indexer
+	h.relocations.SetSearchIndexer(indexer)
 }
 
 func newSpaceResponse(item *space.Space) spaceResponse {
 	return spaceResponse{
-		ID:            item.ID,
-		SpaceName:     item.SpaceName,
-		Icon:          item.Icon,
-		SpaceCategory: item.SpaceCategory,
-		QuotaBytes:    item.QuotaBytes,
+		ID:                    item.ID,
+		SpaceName:             item.SpaceName,
+		SpaceSlug:             item.ProtocolName(),
+		Icon:                  item.Icon,
+		SpaceCategory:         item.SpaceCategory,
+		QuotaBytes:            item.QuotaBytes,
+		QuotaFiles:            item.QuotaFiles,
+		QuotaWarningPercents:  item.EffectiveQuotaWarningPercents(),
+		SpaceState:            string(item.State()),
+		WebDAVArchiveBrowsing: item.WebDAVArchiveBrowsing,
 	}
 }
 
@@ -131,7 +209,15 @@ func (h *Handler) RegisterRoutes(mux *http.ServeMux) {
 	mux.Handle("/api/spaces/validate-root", web.Handler(h.handleValidateSpaceRoot))
 	mux.Handle("/api/spaces/", web.Handler(h.handleSpaceByID))
 	mux.Handle("/api/search/files", web.Handler(h.handleSearchFiles))
+	mux.Handle("/api/duplicates", web.Handler(h.handleDuplicateScan))
+	mux.Handle("/api/duplicates/", web.Handler(h.handleDuplicateReportRoute))
 	mux.Handle("/api/downloads/", web.Handler(h.handleDownloadByTicket))
+	mux.Handle("/api/storage/me", web.Handler(h.handleMyStorage))
+	mux.Handle("/api/storage/users", web.Handler(h.handleUserStorageReport))
+	mux.Handle(userStorageQuotaPathPrefix, web.Handler(h.handleUserStorageQuotas))
+	mux.Handle(signedDownloadPathPrefix, web.Handler(h.handleSignedDownload))
+	mux.Handle("/api/quarantine", web.Handler(h.handleQuarantineList))
+	mux.Handle(quarantinePathPrefix, web.Handler(h.handleQuarantineItem))
 }
 
 // handleSpaces는 HTTP 메서드에 따라 요청을 라우팅합니다
@@ -170,8 +256,13 @@ func (h *Handler) handleGetSpaces(w http.ResponseWriter, r *http.Request) *web.E
 		}
 	}
 
+	// archived Space는 include_archived=true일 때만 목록에 포함한다.
+	includeArchived := r.URL.Query().Get("include_archived") == "true"
 	filteredSpaces := make([]*space.Space, 0, len(spaces))
 	for _, item := range spaces {
+		if !includeArchived && !item.IsListed() {
+			continue
+		}
 		allowed, err := h.accountService.CanAccessSpaceByID(r.Context(), claims.Username, item.ID, account.PermissionRead)
 		if err != nil {
 			return &web.Error{
@@ -187,7 +278,11 @@ func (h *Handler) handleGetSpaces(w http.ResponseWriter, r *http.Request) *web.E
 
 	response := make([]spaceResponse, 0, len(filteredSpaces))
 	for _, item := range filteredSpaces {
-		response = append(response, newSpaceResponse(item))
+		itemResponse := newSpaceResponse(item)
+		if health, ok := h.spaceService.RootHealth(item.ID); ok {
+			itemResponse.RootHealth = &health
+		}
+		response = append(response, itemResponse)
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -240,6 +335,9 @@ func (h *Handler) handleCreateSpace(w http.ResponseWriter, r *http.Request) *web
 		}
 	}
 
+	// 마커 파일을 바로 만들어 이후 점검에서 root 마운트를 식별할 수 있게 한다.
+	h.spaceService.CheckSpaceRoot(r.Context(), createdSpace)
+
 	// 응답 생성
 	response := space.CreateSpaceResponse{
 		ID:        createdSpace.ID,
@@ -336,11 +434,36 @@ func (h *Handler) handleSpaceByID(w http.ResponseWriter, r *http.Request) *web.E
 		}
 	}
 
+	// 내용 조회/변경 경로는 maintenance 상태에서 관리자만 접근할 수 있다.
+	if len(parts) > 1 && (parts[1] == "browse" || parts[1] == "files") {
+		if webErr := h.ensureSpaceReadable(r, id); webErr != nil {
+			return webErr
+		}
+	}
+
 	// 액션 확인 (/api/spaces/{id}/browse)
 	if len(parts) > 1 && parts[1] == "browse" {
 		return h.handleSpaceBrowse(w, r, id)
 	}
 
+	if len(parts) > 1 && parts[1] == "analytics" {
+		if webErr := h.ensureSpaceReadable(r, id); webErr != nil {
+			return webErr
+		}
+		action := ""
+		if len(parts) > 2 {
+			action = parts[2]
+		}
+		return h.handleSpaceAnalytics(w, r, id, action)
+	}
+
+	if len(parts) > 2 && parts[1] == "quota" && parts[2] == "recalculate" {
+		if webErr := h.ensureSpaceReadable(r, id); webErr != nil {
+			return webErr
+		}
+		return h.handleSpaceQuotaRecalculate(w, r, id)
+	}
+
 	if len(parts) > 1 && parts[1] == "quota" {
 		return h.handleSpaceQuota(w, r, id)
 	}
@@ -349,6 +472,46 @@ func (h *Handler) handleSpaceByID(w http.ResponseWriter, r *http.Request) *web.E
 		return h.handleSpaceMembers(w, r, id)
 	}
 
+	if len(parts) > 1 && parts[1] == "relocation" {
+		return h.handleSpaceRelocation(w, r, id)
+	}
+
+	if len(parts) > 1 && parts[1] == "deletion" {
+		return h.handleSpaceDeletion(w, r, id)
+	}
+
+	if len(parts) > 1 && parts[1] == "state" {
+		return h.handleSpaceState(w, r, id)
+	}
+
+	if len(parts) > 1 && parts[1] == "webdav" {
+		return h.handleSpaceWebDAV(w, r, id)
+	}
+
+	if len(parts) > 1 && parts[1] == "upload-policy" {
+		return h.handleSpaceUploadPolicy(w, r, id)
+	}
+
+	if len(parts) > 1 && parts[1] == "malware-scan" {
+		return h.handleSpaceMalwareScan(w, r, id)
+	}
+
+	if len(parts) > 1 && parts[1] == "retention-locks" {
+		return h.handleSpaceRetentionLocks(w, r, id)
+	}
+
+	if len(parts) > 1 && parts[1] == "cleanup-rules" {
+		return h.handleSpaceCleanupRules(w, r, id, parts[2:])
+	}
+
+	if len(parts) > 1 && parts[1] == "organize-rules" {
+		return h.handleSpaceOrganizeRules(w, r, id, parts[2:])
+	}
+
+	if len(parts) > 1 && parts[1] == "file-tags" {
+		return h.handleSpaceFileTags(w, r, id)
+	}
+
 	// 파일 작업 (/api/spaces/{id}/files/{action})
 	if len(parts) > 2 && parts[1] == "files" {
 		return h.handleSpaceFiles(w, r, id, parts[2])
@@ -522,8 +685,26 @@ func (h *Handler) handleUpdateSpace(w http.ResponseWriter, r *http.Request, id i
 	return nil
 }
 
-// handleDeleteSpace는 Space를 삭제합니다
+// handleDeleteSpace는 Space를 삭제합니다.
+// 본문이 없거나 mode가 detach면 DB 등록만 해제하고, archive/delete_data는 백그라운드 작업으로 넘깁니다.
 func (h *Handler) handleDeleteSpace(w http.ResponseWriter, r *http.Request, id int64) *web.Error {
+	var req space.DeleteSpaceRequest
+	if r.Body != nil {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+			return &web.Error{Code: http.StatusBadRequest, Message: "Invalid request body", Err: err}
+		}
+	}
+	if err := req.Validate(); err != nil {
+		return &web.Error{Code: http.StatusBadRequest, Message: err.Error(), Err: err}
+	}
+	// detach도 잠금 기록을 함께 지우므로 유효한 잠금이 남아 있으면 어떤 방식으로도 지울 수 없습니다.
+	if webErr := h.ensureSpaceHasNoActiveRetention(r, id, req.Mode); webErr != nil {
+		return webErr
+	}
+	if req.Mode != space.DeleteSpaceModeDetach {
+		return h.handleSpaceDeletionStart(w, r, id, &req)
+	}
+
 	if err := h.spaceService.DeleteSpace(r.Context(), id); err != nil {
 		statusCode := http.StatusInternalServerError
 		message := "Failed to delete Space"
@@ -542,6 +723,17 @@ func (h *Handler) handleDeleteSpace(w http.ResponseWriter, r *http.Request, id i
 		}
 	}
 
+	discardedArchives := h.discardSpaceJobs(id)
+	h.recordSpaceAudit(r, audit.Event{
+		Action: "space.delete",
+		Result: audit.ResultSuccess,
+		Target: fmt.Sprintf("space:%d", id),
+		Metadata: map[string]any{
+			"mode":             string(space.DeleteSpaceModeDetach),
+			"archiveDownloads": discardedArchives,
+		},
+	}, id)
+
 	if h.searchIndexer != nil {
 		if err := h.searchIndexer.MarkAllDirty(r.Context()); err != nil {
 			return &web.Error{
@@ -610,6 +802,19 @@ func (h *Handler) handleSpaceBrowse(w http.ResponseWriter, r *http.Request, spac
 		}
 	}
 
+	// 압축 파일과 그 안의 경로는 읽기 전용 가상 폴더로 보여 줍니다.
+	archiveTarget, webErr := h.lookupArchiveEntry(ctx, spaceData.SpacePath, relativePath, true)
+	if webErr != nil {
+		return webErr
+	}
+	if archiveTarget != nil {
+		entries, webErr := listArchiveEntries(archiveTarget)
+		if webErr != nil {
+			return webErr
+		}
+		return writeJSON(w, http.StatusOK, entries)
+	}
+
 	// 디렉토리 목록 조회
 	files, err := h.browseService.ListDirectory(false, absolutePath)
 	if err != nil {
@@ -26,13 +26,13 @@ func (f *fakeRenameSpaceStore) GetByName(_ context.Context, name string) (*space
 			return cloneSpace(item), nil
 		}
 	}
-	return nil, errors.New("space not found")
+	return nil, space.ErrSpaceNotFound
 }
 
 func (f *fakeRenameSpaceStore) GetByID(_ context.Context, id int64) (*space.Space, error) {
 	item, ok := f.spacesByID[id]
 	if !ok {
-		return nil, errors.New("space not found")
+		return nil, space.ErrSpaceNotFound
 	}
 	return cloneSpace(item), nil
 }
@@ -48,7 +48,7 @@ func (f *fakeRenameSpaceStore) Delete(context.Context, int64) error {
 func (f *fakeRenameSpaceStore) Update(_ context.Context, id int64, req *space.UpdateSpaceRequest) (*space.Space, error) {
 	item, ok := f.spacesByID[id]
 	if !ok {
-		return nil, errors.New("space not found")
+		return nil, space.ErrSpaceNotFound
 	}
 	if req.SpaceName != nil {
 		item.SpaceName = *req.SpaceName
@@ -0,0 +1,114 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"taeu.kr/cohesion/internal/job"
+	"taeu.kr/cohesion/internal/platform/web"
+)
+
+const (
+	defaultSpaceJobWorkerLimit = 2
+	defaultSpaceJobMaxAttempts = 3
+	// defaultSpaceJobTTL은 끝난 저장소 이전/삭제 작업의 상태를 조회할 수 있는 시간입니다.
+	defaultSpaceJobTTL = 30 * time.Minute
+)
+
+var (
+	// errSpaceJobInProgress는 같은 Space에 같은 유형의 작업이 이미 대기 중이거나 실행 중임을 나타냅니다.
+	errSpaceJobInProgress = errors.New("already in progress")
+	// errSpaceJobConflict는 같은 Space에 다른 유형의 저장소 작업(이전/삭제)이 대기 중이거나 실행 중임을 나타냅니다.
+	errSpaceJobConflict = errors.New("another storage job is in progress")
+)
+
+// exclusiveSpaceJobTypes는 같은 Space에서 함께 대기/실행할 수 없는 작업 유형입니다.
+// 이전과 삭제는 둘 다 root를 다루므로, 겹치면 삭제가 옛 root를 지우고 새 root를 버려둘 수 있습니다.
+var exclusiveSpaceJobTypes = []string{SpaceRelocationJobType, SpaceDeletionJobType}
+
+// enqueueSpaceJob은 Space 단위 작업(저장소 이전/삭제)을 등록합니다. 같은 Space에 끝나지 않은 이전/삭제 작업이 있으면 거절합니다.
+// conflicts가 있으면 같은 잠금 안에서 호출해, 다른 Space의 작업과 겹치는지도 등록 직전에 확인합니다.
+func (h *Handler) enqueueSpaceJob(r *http.Request, jobType string, spaceID int64, owner string, payload any, conflicts func(ctx context.Context) error) (*job.Job, error) {
+	h.spaceJobMu.Lock()
+	defer h.spaceJobMu.Unlock()
+
+	for _, activeType := range exclusiveSpaceJobTypes {
+		active, err := h.jobs.List(r.Context(), job.ListFilter{
+			Type:     activeType,
+			SpaceID:  &spaceID,
+			Statuses: []job.Status{job.StatusQueued, job.StatusRunning},
+			Limit:    1,
+		})
+		if err != nil {
+			return nil, err
+		}
+		if len(active) == 0 {
+			continue
+		}
+		if activeType == jobType {
+			return nil, fmt.Errorf("%s for space %d: %w", jobType, spaceID, errSpaceJobInProgress)
+		}
+		return nil, fmt.Errorf("%s for space %d: %w", activeType, spaceID, errSpaceJobConflict)
+	}
+	if conflicts != nil {
+		if err := conflicts(r.Context()); err != nil {
+			return nil, err
+		}
+	}
+
+	return h.jobs.Enqueue(r.Context(), job.EnqueueRequest{
+		Type:      jobType,
+		Owner:     owner,
+		SpaceID:   &spaceID,
+		RequestID: strings.TrimSpace(r.Header.Get("X-Request-Id")),
+		Payload:   payload,
+	})
+}
+
+// lookupSpaceJob은 jobId 쿼리로 지정한 작업이나, 없으면 Space의 가장 최근 작업을 찾습니다.
+// 보존 기간이 지나 expired가 된 작업은 없는 것으로 봅니다.
+func (h *Handler) lookupSpaceJob(r *http.Request, jobType string, spaceID int64, notFoundMessage string) (*job.Job, *web.Error) {
+	if jobID := strings.TrimSpace(r.URL.Query().Get("jobId")); jobID != "" {
+		found, err := h.jobs.Get(r.Context(), jobID)
+		if err != nil {
+			if errors.Is(err, job.ErrNotFound) {
+				return nil, &web.Error{Code: http.StatusNotFound, Message: notFoundMessage, Err: err}
+			}
+			return nil, &web.Error{Code: http.StatusInternalServerError, Message: "Failed to load job", Err: err}
+		}
+		if found.Type != jobType || found.SpaceID == nil || *found.SpaceID != spaceID || found.Status == job.StatusExpired {
+			return nil, &web.Error{Code: http.StatusNotFound, Message: notFoundMessage}
+		}
+		return found, nil
+	}
+
+	items, err := h.jobs.List(r.Context(), job.ListFilter{
+		Type:     jobType,
+		SpaceID:  &spaceID,
+		Statuses: []job.Status{job.StatusQueued, job.StatusRunning, job.StatusCompleted, job.StatusFailed, job.StatusCanceled},
+		Limit:    1,
+	})
+	if err != nil {
+		return nil, &web.Error{Code: http.StatusInternalServerError, Message: "Failed to load job", Err: err}
+	}
+	if len(items) == 0 {
+		return nil, &web.Error{Code: http.StatusNotFound, Message: notFoundMessage}
+	}
+	return items[0], nil
+}
+
+// cancelSpaceJob은 끝나지 않은 작업만 취소합니다. 이미 취소된 작업은 그대로 반환하고,
+// 끝났거나 되돌릴 수 없는 단계에 들어선 작업은 job.ErrNotCancelable을 반환합니다.
+func (h *Handler) cancelSpaceJob(ctx context.Context, found *job.Job) (*job.Job, error) {
+	switch found.Status {
+	case job.StatusCanceled:
+		return found, nil
+	case job.StatusCompleted, job.StatusFailed, job.StatusExpired:
+		return nil, job.ErrNotCancelable
+	}
+	return h.jobs.Cancel(ctx, found.ID)
+}
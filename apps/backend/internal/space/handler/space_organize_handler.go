@@ -0,0 +1,317 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"taeu.kr/cohesion/internal/audit"
+	"taeu.kr/cohesion/internal/platform/web"
+	"taeu.kr/cohesion/internal/space"
+)
+
+// SetOrganizeService는 파일 이벤트 파이프라인과 같은 규칙을 보도록 공용 OrganizeService로 교체합니다.
+func (h *Handler) SetOrganizeService(organize *space.OrganizeService) {
+	if organize != nil {
+		h.organize = organize
+	}
+}
+
+// SetFileEventPublisher는 업로드를 마친 파일의 이벤트를 내보낼 대상을 설정합니다.
+func (h *Handler) SetFileEventPublisher(publisher space.FileEventPublisher) {
+	h.fileEvents = publisher
+}
+
+// publishFileCreated는 파일 내용이 새로 놓였음을 알립니다. 처리는 구독자가 뒤이어 합니다.
+func (h *Handler) publishFileCreated(ctx context.Context, spaceData *space.Space, absPath, actor string) {
+	if h.fileEvents == nil {
+		return
+	}
+	h.fileEvents.PublishFileEvent(ctx, space.FileEvent{
+		Kind:      space.FileEventCreated,
+		SpaceID:   spaceData.ID,
+		SpaceRoot: spaceData.SpacePath,
+		AbsPath:   absPath,
+		Actor:     actor,
+		Source:    "rest",
+		At:        time.Now().UTC(),
+	})
+}
+
+// handleSpaceOrganizeRules는 /api/spaces/{id}/organize-rules[/{ruleId}] 요청을 처리합니다.
+//   - GET    /organize-rules          : 규칙 목록
+//   - POST   /organize-rules          : { name, watchPath, includeSubfolders?, conditions?, actions, enabled? } 규칙 추가
+//   - GET    /organize-rules/{ruleId} : 규칙 하나
+//   - PUT    /organize-rules/{ruleId} : 규칙을 통째로 바꿉니다.
+//   - DELETE /organize-rules/{ruleId} : 규칙 삭제
+func (h *Handler) handleSpaceOrganizeRules(w http.ResponseWriter, r *http.Request, spaceID int64, parts []string) *web.Error {
+	if len(parts) == 0 {
+		switch r.Method {
+		case http.MethodGet:
+			if _, err := h.spaceService.GetSpaceByID(r.Context(), spaceID); err != nil {
+				return spaceLookupWebError(err)
+			}
+			rules, err := h.organize.ListRules(r.Context(), spaceID)
+			if err != nil {
+				return &web.Error{Code: http.StatusInternalServerError, Message: "Failed to list organize rules", Err: err}
+			}
+			return writeJSON(w, http.StatusOK, rules)
+		case http.MethodPost:
+			return h.handleOrganizeRuleCreate(w, r, spaceID)
+		default:
+			return &web.Error{Code: http.StatusMethodNotAllowed, Message: "Method not allowed"}
+		}
+	}
+
+	ruleID, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil || ruleID <= 0 {
+		return &web.Error{Code: http.StatusBadRequest, Message: "Invalid organize rule ID", Err: err}
+	}
+	if len(parts) > 1 {
+		return &web.Error{Code: http.StatusNotFound, Message: "Not found"}
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		if _, err := h.spaceService.GetSpaceByID(r.Context(), spaceID); err != nil {
+			return spaceLookupWebError(err)
+		}
+		rule, err := h.organize.GetRule(r.Context(), spaceID, ruleID)
+		if err != nil {
+			return organizeRuleWebError(err, "Failed to get organize rule")
+		}
+		return writeJSON(w, http.StatusOK, rule)
+	case http.MethodPut:
+		return h.handleOrganizeRuleUpdate(w, r, spaceID, ruleID)
+	case http.MethodDelete:
+		return h.handleOrganizeRuleDelete(w, r, spaceID, ruleID)
+	default:
+		return &web.Error{Code: http.StatusMethodNotAllowed, Message: "Method not allowed"}
+	}
+}
+
+func (h *Handler) handleOrganizeRuleCreate(w http.ResponseWriter, r *http.Request, spaceID int64) *web.Error {
+	var req space.OrganizeRuleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return &web.Error{Code: http.StatusBadRequest, Message: "Invalid request body", Err: err}
+	}
+	username, webErr := claimsUsernameFromRequest(r)
+	if webErr != nil {
+		return webErr
+	}
+	if _, err := h.spaceService.GetSpaceByID(r.Context(), spaceID); err != nil {
+		return spaceLookupWebError(err)
+	}
+	if err := ensureOrganizeRuleOutsideTrash(req); err != nil {
+		return &web.Error{Code: http.StatusForbidden, Message: "Access denied: invalid path", Err: err}
+	}
+
+	rule, err := h.organize.CreateRule(r.Context(), spaceID, req, username)
+	if err != nil {
+		h.recordSpaceAudit(r, audit.Event{
+			Action:   "space.organize-rule.create",
+			Result:   audit.ResultFailure,
+			Target:   fmt.Sprintf("space:%d", spaceID),
+			Metadata: map[string]any{"ruleName": req.Name, "watchPath": req.WatchPath, "reason": err.Error()},
+		}, spaceID)
+		return organizeRuleWebError(err, "Failed to create organize rule")
+	}
+
+	h.recordSpaceAudit(r, audit.Event{
+		Action:   "space.organize-rule.create",
+		Result:   audit.ResultSuccess,
+		Target:   fmt.Sprintf("space:%d", spaceID),
+		Metadata: organizeRuleAuditMetadata(rule),
+	}, spaceID)
+	return writeJSON(w, http.StatusCreated, rule)
+}
+
+func (h *Handler) handleOrganizeRuleUpdate(w http.ResponseWriter, r *http.Request, spaceID, ruleID int64) *web.Error {
+	var req space.OrganizeRuleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return &web.Error{Code: http.StatusBadRequest, Message: "Invalid request body", Err: err}
+	}
+	if _, err := h.spaceService.GetSpaceByID(r.Context(), spaceID); err != nil {
+		return spaceLookupWebError(err)
+	}
+	if err := ensureOrganizeRuleOutsideTrash(req); err != nil {
+		return &web.Error{Code: http.StatusForbidden, Message: "Access denied: invalid path", Err: err}
+	}
+
+	rule, previous, err := h.organize.UpdateRule(r.Context(), spaceID, ruleID, req)
+	if err != nil {
+		h.recordSpaceAudit(r, audit.Event{
+			Action:   "space.organize-rule.update",
+			Result:   audit.ResultFailure,
+			Target:   fmt.Sprintf("space:%d", spaceID),
+			Metadata: map[string]any{"ruleId": ruleID, "ruleName": req.Name, "watchPath": req.WatchPath, "reason": err.Error()},
+		}, spaceID)
+		return organizeRuleWebError(err, "Failed to update organize rule")
+	}
+
+	metadata := organizeRuleAuditMetadata(rule)
+	metadata["previousName"] = previous.Name
+	metadata["previousWatchPath"] = previous.WatchPath
+	metadata["previousActions"] = organizeActionTypes(previous.Actions)
+	metadata["previousEnabled"] = previous.Enabled
+	h.recordSpaceAudit(r, audit.Event{
+		Action:   "space.organize-rule.update",
+		Result:   audit.ResultSuccess,
+		Target:   fmt.Sprintf("space:%d", spaceID),
+		Metadata: metadata,
+	}, spaceID)
+	return writeJSON(w, http.StatusOK, rule)
+}
+
+func (h *Handler) handleOrganizeRuleDelete(w http.ResponseWriter, r *http.Request, spaceID, ruleID int64) *web.Error {
+	if _, err := h.spaceService.GetSpaceByID(r.Context(), spaceID); err != nil {
+		return spaceLookupWebError(err)
+	}
+
+	rule, err := h.organize.DeleteRule(r.Context(), spaceID, ruleID)
+	if err != nil {
+		h.recordSpaceAudit(r, audit.Event{
+			Action:   "space.organize-rule.delete",
+			Result:   audit.ResultFailure,
+			Target:   fmt.Sprintf("space:%d", spaceID),
+			Metadata: map[string]any{"ruleId": ruleID, "reason": err.Error()},
+		}, spaceID)
+		return organizeRuleWebError(err, "Failed to delete organize rule")
+	}
+
+	h.recordSpaceAudit(r, audit.Event{
+		Action:   "space.organize-rule.delete",
+		Result:   audit.ResultSuccess,
+		Target:   fmt.Sprintf("space:%d", spaceID),
+		Metadata: organizeRuleAuditMetadata(rule),
+	}, spaceID)
+	return writeJSON(w, http.StatusOK, map[string]any{"id": rule.ID, "status": "deleted"})
+}
+
+// handleSpaceFileTags는 GET /api/spaces/{id}/file-tags?path=&tag= 요청을 처리합니다.
+// path로 파일 하나의 태그를, tag로 그 태그가 붙은 파일을 찾습니다. 둘 다 없으면 Space의 모든 태그입니다.
+func (h *Handler) handleSpaceFileTags(w http.ResponseWriter, r *http.Request, spaceID int64) *web.Error {
+	if r.Method != http.MethodGet {
+		return &web.Error{Code: http.StatusMethodNotAllowed, Message: "Method not allowed"}
+	}
+	if webErr := h.ensureSpaceReadable(r, spaceID); webErr != nil {
+		return webErr
+	}
+	spaceData, err := h.spaceService.GetSpaceByID(r.Context(), spaceID)
+	if err != nil {
+		return spaceLookupWebError(err)
+	}
+	query := space.FileTagQuery{Path: r.URL.Query().Get("path"), Tag: r.URL.Query().Get("tag")}
+	if err := ensurePathOutsideTrash(normalizeRelativePath(query.Path)); err != nil {
+		return &web.Error{Code: http.StatusForbidden, Message: "Access denied: invalid path", Err: err}
+	}
+
+	tags, err := h.organize.ListTags(r.Context(), spaceData, query)
+	if err != nil {
+		if errors.Is(err, space.ErrInvalidFileTagQuery) {
+			return &web.Error{Code: http.StatusBadRequest, Message: err.Error(), Err: err}
+		}
+		return &web.Error{Code: http.StatusInternalServerError, Message: "Failed to list file tags", Err: err}
+	}
+	return writeJSON(w, http.StatusOK, tags)
+}
+
+// MoveOrganizedFile은 space.OrganizeMover를 구현합니다. 같은 Space 안 이동이라 사용량은 그대로이고,
+// 소유자 기록을 따라 옮기고 검색 색인을 다시 만들도록 표시합니다.
+func (h *Handler) MoveOrganizedFile(ctx context.Context, spaceObj *space.Space, fromRel, toRel string) error {
+	fromPath := normalizeRelativePath(fromRel)
+	toPath := normalizeRelativePath(toRel)
+	if err := ensurePathOutsideTrash(fromPath); err != nil {
+		return err
+	}
+	if err := ensurePathOutsideTrash(toPath); err != nil {
+		return err
+	}
+	fromAbs, err := resolveAbsPath(spaceObj.SpacePath, fromPath)
+	if err != nil {
+		return fmt.Errorf("access denied: invalid path")
+	}
+	toAbs, err := resolveAbsPath(spaceObj.SpacePath, toPath)
+	if err != nil {
+		return fmt.Errorf("access denied: invalid path")
+	}
+
+	info, err := os.Lstat(fromAbs)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return errors.New("File or directory not found")
+		}
+		return errors.New(safeFilesystemReason("Failed to access file", err))
+	}
+	if !info.Mode().IsRegular() {
+		return errors.New("Only regular files can be organized")
+	}
+	if _, err := os.Lstat(toAbs); err == nil {
+		return errors.New("Destination already exists")
+	} else if !os.IsNotExist(err) {
+		return errors.New(safeFilesystemReason("Failed to access destination", err))
+	}
+	if err := os.MkdirAll(filepath.Dir(toAbs), 0o755); err != nil {
+		return errors.New(safeFilesystemReason("Failed to create destination folder", err))
+	}
+	if err := os.Rename(fromAbs, toAbs); err != nil {
+		return errors.New(safeFilesystemReason("Failed to move file", err))
+	}
+	h.moveFileOwners(ctx, spaceObj.ID, spaceObj.SpacePath, fromAbs, spaceObj.ID, spaceObj.SpacePath, toAbs)
+	h.markSearchIndexDirty(ctx, spaceObj.ID, "organize")
+	return nil
+}
+
+var _ space.OrganizeMover = (*Handler)(nil)
+
+// ensureOrganizeRuleOutsideTrash는 감시 폴더와 move 대상 폴더가 휴지통을 가리키지 않는지 확인합니다.
+func ensureOrganizeRuleOutsideTrash(req space.OrganizeRuleRequest) error {
+	if err := ensurePathOutsideTrash(normalizeRelativePath(req.WatchPath)); err != nil {
+		return err
+	}
+	for _, action := range req.Actions {
+		if action.Type != space.OrganizeActionMove {
+			continue
+		}
+		if err := ensurePathOutsideTrash(normalizeRelativePath(action.Destination)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func organizeRuleWebError(err error, fallback string) *web.Error {
+	switch {
+	case errors.Is(err, space.ErrInvalidOrganizeRule):
+		return &web.Error{Code: http.StatusBadRequest, Message: err.Error(), Err: err}
+	case errors.Is(err, space.ErrOrganizeRuleNotFound):
+		return &web.Error{Code: http.StatusNotFound, Message: "Organize rule not found", Err: err}
+	default:
+		return &web.Error{Code: http.StatusInternalServerError, Message: fallback, Err: err}
+	}
+}
+
+func organizeRuleAuditMetadata(rule *space.OrganizeRule) map[string]any {
+	return map[string]any{
+		"ruleId":            rule.ID,
+		"ruleName":          rule.Name,
+		"watchPath":         rule.WatchPath,
+		"includeSubfolders": rule.IncludeSubfolders,
+		"actions":           organizeActionTypes(rule.Actions),
+		"enabled":           rule.Enabled,
+	}
+}
+
+func organizeActionTypes(actions []space.OrganizeAction) []string {
+	types := make([]string, 0, len(actions))
+	for _, action := range actions {
+		types = append(types, string(action.Type))
+	}
+	return types
+}
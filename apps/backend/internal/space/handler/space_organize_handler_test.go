@@ -0,0 +1,85 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"taeu.kr/cohesion/internal/auth"
+	"taeu.kr/cohesion/internal/space"
+)
+
+func TestOrganizeRules_UploadIsMovedRenamedAndTaggedByPipeline(t *testing.T) {
+	handler, root := setupTrashHandler(t)
+	pipeline := space.NewFileEventPipeline(space.DefaultFileEventQueueSize, 1)
+	pipeline.Subscribe(handler.organize)
+	handler.SetFileEventPublisher(pipeline)
+	pipeline.Start()
+
+	req := newJSONRequestWithClaims(t, http.MethodPost, "/api/spaces/1/organize-rules", map[string]any{
+		"name":      "trash",
+		"watchPath": ".cohesion_trash",
+		"actions":   []map[string]any{{"type": "tag", "tags": []string{"x"}}},
+	})
+	if webErr := handler.handleSpaceOrganizeRules(httptest.NewRecorder(), req, 1, nil); webErr == nil || webErr.Code != http.StatusForbidden {
+		t.Fatalf("expected trash watch path to be rejected with 403, got %+v", webErr)
+	}
+	req = newJSONRequestWithClaims(t, http.MethodPost, "/api/spaces/1/organize-rules", map[string]any{
+		"name":    "bad template",
+		"actions": []map[string]any{{"type": "rename", "template": "{when}.{ext}"}},
+	})
+	if webErr := handler.handleSpaceOrganizeRules(httptest.NewRecorder(), req, 1, nil); webErr == nil || webErr.Code != http.StatusBadRequest {
+		t.Fatalf("expected unknown placeholder to be rejected with 400, got %+v", webErr)
+	}
+
+	req = newJSONRequestWithClaims(t, http.MethodPost, "/api/spaces/1/organize-rules", map[string]any{
+		"name":       "Phone dump",
+		"conditions": map[string]any{"extensions": []string{"txt"}},
+		"actions": []map[string]any{
+			{"type": "move", "destination": "Phone/{uploader}"},
+			{"type": "rename", "template": "{name}-{uploader}.{ext}"},
+			{"type": "tag", "tags": []string{"phone"}},
+		},
+	})
+	rec := httptest.NewRecorder()
+	if webErr := handler.handleSpaceOrganizeRules(rec, req, 1, nil); webErr != nil {
+		t.Fatalf("create rule: %+v", webErr)
+	}
+	var rule space.OrganizeRule
+	if err := json.NewDecoder(rec.Body).Decode(&rule); err != nil {
+		t.Fatalf("decode rule: %v", err)
+	}
+	if rec.Code != http.StatusCreated || rule.CreatedBy != "tester" || !rule.Enabled || len(rule.Actions) != 3 {
+		t.Fatalf("unexpected created rule (status %d): %+v", rec.Code, rule)
+	}
+
+	req = newUploadRequest(t, "note.txt", "hello", nil)
+	req = req.WithContext(auth.WithClaims(req.Context(), &auth.Claims{Username: "tester"}))
+	if webErr := handler.handleFileUpload(httptest.NewRecorder(), req, 1); webErr != nil {
+		t.Fatalf("upload: %+v", webErr)
+	}
+	pipeline.Stop()
+
+	if _, err := os.Stat(filepath.Join(root, "note.txt")); !os.IsNotExist(err) {
+		t.Fatalf("uploaded file should be organized away, err=%v", err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "Phone", "tester", "note-tester.txt")); err != nil {
+		t.Fatalf("expected organized file: %v", err)
+	}
+
+	req = newJSONRequestWithClaims(t, http.MethodGet, "/api/spaces/1/file-tags?tag=phone", nil)
+	rec = httptest.NewRecorder()
+	if webErr := handler.handleSpaceFileTags(rec, req, 1); webErr != nil {
+		t.Fatalf("list tags: %+v", webErr)
+	}
+	var tags []space.FileTag
+	if err := json.NewDecoder(rec.Body).Decode(&tags); err != nil {
+		t.Fatalf("decode tags: %v", err)
+	}
+	if len(tags) != 1 || tags[0].Path != "Phone/tester/note-tester.txt" {
+		t.Fatalf("unexpected tags: %+v", tags)
+	}
+}
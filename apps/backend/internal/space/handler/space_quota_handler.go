@@ -8,17 +8,30 @@ import (
 	"strings"
 
 	"taeu.kr/cohesion/internal/account"
+	"taeu.kr/cohesion/internal/audit"
 	"taeu.kr/cohesion/internal/auth"
 	"taeu.kr/cohesion/internal/platform/web"
+	"taeu.kr/cohesion/internal/space"
 )
 
 type spaceUsageResponse struct {
-	SpaceID    int64  `json:"spaceId"`
-	SpaceName  string `json:"spaceName"`
-	UsedBytes  int64  `json:"usedBytes"`
-	QuotaBytes *int64 `json:"quotaBytes,omitempty"`
-	OverQuota  bool   `json:"overQuota"`
-	ScannedAt  string `json:"scannedAt"`
+	SpaceID       int64                `json:"spaceId"`
+	SpaceName     string               `json:"spaceName"`
+	UsedBytes     int64                `json:"usedBytes"`
+	QuotaBytes    *int64               `json:"quotaBytes,omitempty"`
+	OverQuota     bool                 `json:"overQuota"`
+	UsedFiles     int64                `json:"usedFiles"`
+	QuotaFiles    *int64               `json:"quotaFiles,omitempty"`
+	OverFileQuota bool                 `json:"overFileQuota"`
+	Warnings      []space.QuotaWarning `json:"warnings,omitempty"`
+	ScannedAt     string               `json:"scannedAt"`
+}
+
+// SetQuotaService는 사용량 카운터를 프로토콜 서버·주기 대조와 함께 쓰도록 공용 QuotaService로 교체합니다.
+func (h *Handler) SetQuotaService(quotaService *space.QuotaService) {
+	if quotaService != nil {
+		h.quotaService = quotaService
+	}
 }
 
 func (h *Handler) handleSpaceUsage(w http.ResponseWriter, r *http.Request) *web.Error {
@@ -52,12 +65,16 @@ func (h *Handler) handleSpaceUsage(w http.ResponseWriter, r *http.Request) *web.
 		}
 
 		items = append(items, spaceUsageResponse{
-			SpaceID:    usage.SpaceID,
-			SpaceName:  usage.SpaceName,
-			UsedBytes:  usage.UsedBytes,
-			QuotaBytes: usage.QuotaBytes,
-			OverQuota:  usage.OverQuota,
-			ScannedAt:  usage.ScannedAt.UTC().Format(http.TimeFormat),
+			SpaceID:       usage.SpaceID,
+			SpaceName:     usage.SpaceName,
+			UsedBytes:     usage.UsedBytes,
+			QuotaBytes:    usage.QuotaBytes,
+			OverQuota:     usage.OverQuota,
+			UsedFiles:     usage.UsedFiles,
+			QuotaFiles:    usage.QuotaFiles,
+			OverFileQuota: usage.OverFileQuota,
+			Warnings:      usage.Warnings,
+			ScannedAt:     usage.ScannedAt.UTC().Format(http.TimeFormat),
 		})
 	}
 
@@ -76,22 +93,64 @@ func (h *Handler) handleSpaceUsage(w http.ResponseWriter, r *http.Request) *web.
 	return nil
 }
 
+// handleSpaceQuota: PATCH /api/spaces/{id}/quota
+// body: { quotaBytes?: int64, quotaFiles?: int64, warningPercents?: int[] }
+// quotaBytes를 생략하거나 null이면 용량 제한을 없앱니다. quotaFiles와 warningPercents는 생략하면 그대로 두고,
+// null이면 각각 제한 없음·기본 경고 비율로 되돌립니다.
 func (h *Handler) handleSpaceQuota(w http.ResponseWriter, r *http.Request, spaceID int64) *web.Error {
 	if r.Method != http.MethodPatch {
 		return &web.Error{Code: http.StatusMethodNotAllowed, Message: "Method not allowed"}
 	}
 
-	var req struct {
-		QuotaBytes *int64 `json:"quotaBytes"`
-	}
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+	var fields map[string]json.RawMessage
+	if err := json.NewDecoder(r.Body).Decode(&fields); err != nil {
 		return &web.Error{Code: http.StatusBadRequest, Message: "Invalid request body", Err: err}
 	}
-	if req.QuotaBytes != nil && *req.QuotaBytes < 0 {
+
+	current, err := h.spaceService.GetSpaceByID(r.Context(), spaceID)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return &web.Error{Code: http.StatusNotFound, Message: "Space not found", Err: err}
+		}
+		return &web.Error{Code: http.StatusInternalServerError, Message: "Failed to get space", Err: err}
+	}
+	limits := space.QuotaLimits{
+		QuotaFiles:      current.QuotaFiles,
+		WarningPercents: current.QuotaWarningPercents,
+	}
+	decodeField := func(name string, target any) *web.Error {
+		raw, ok := fields[name]
+		if !ok {
+			return nil
+		}
+		if err := json.Unmarshal(raw, target); err != nil {
+			return &web.Error{Code: http.StatusBadRequest, Message: fmt.Sprintf("Invalid %s", name), Err: err}
+		}
+		return nil
+	}
+	if webErr := decodeField("quotaBytes", &limits.QuotaBytes); webErr != nil {
+		return webErr
+	}
+	if _, ok := fields["quotaFiles"]; ok {
+		limits.QuotaFiles = nil
+	}
+	if webErr := decodeField("quotaFiles", &limits.QuotaFiles); webErr != nil {
+		return webErr
+	}
+	if _, ok := fields["warningPercents"]; ok {
+		limits.WarningPercents = nil
+	}
+	if webErr := decodeField("warningPercents", &limits.WarningPercents); webErr != nil {
+		return webErr
+	}
+	if limits.QuotaBytes != nil && *limits.QuotaBytes < 0 {
 		return &web.Error{Code: http.StatusBadRequest, Message: "quotaBytes must be greater than or equal to 0"}
 	}
+	if limits.QuotaFiles != nil && *limits.QuotaFiles < 0 {
+		return &web.Error{Code: http.StatusBadRequest, Message: "quotaFiles must be greater than or equal to 0"}
+	}
 
-	updatedSpace, err := h.spaceService.UpdateSpaceQuota(r.Context(), spaceID, req.QuotaBytes)
+	updatedSpace, err := h.spaceService.UpdateSpaceQuota(r.Context(), spaceID, limits)
 	if err != nil {
 		statusCode := http.StatusInternalServerError
 		message := "Failed to update space quota"
@@ -106,16 +165,56 @@ func (h *Handler) handleSpaceQuota(w http.ResponseWriter, r *http.Request, space
 		return &web.Error{Code: statusCode, Message: message, Err: err}
 	}
 
-	h.quotaService.Invalidate(spaceID)
+	// 할당량이 바뀌면 이미 경고 비율을 넘었을 수 있다.
+	h.quotaService.CheckQuotaAlerts(r.Context(), spaceID)
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	if err := json.NewEncoder(w).Encode(map[string]interface{}{
-		"id":         updatedSpace.ID,
-		"quotaBytes": updatedSpace.QuotaBytes,
-		"message":    fmt.Sprintf("Space quota updated for '%s'", updatedSpace.SpaceName),
+		"id":              updatedSpace.ID,
+		"quotaBytes":      updatedSpace.QuotaBytes,
+		"quotaFiles":      updatedSpace.QuotaFiles,
+		"warningPercents": updatedSpace.EffectiveQuotaWarningPercents(),
+		"message":         fmt.Sprintf("Space quota updated for '%s'", updatedSpace.SpaceName),
 	}); err != nil {
 		return &web.Error{Code: http.StatusInternalServerError, Message: "Failed to encode response", Err: err}
 	}
 	return nil
 }
+
+// handleSpaceQuotaRecalculate는 Space 전체를 바로 다시 스캔해 사용량 카운터를 맞춥니다.
+// 주기 대조를 기다리지 않고 어긋난 사용량을 고칠 때 씁니다.
+func (h *Handler) handleSpaceQuotaRecalculate(w http.ResponseWriter, r *http.Request, spaceID int64) *web.Error {
+	if r.Method != http.MethodPost {
+		return &web.Error{Code: http.StatusMethodNotAllowed, Message: "Method not allowed"}
+	}
+
+	result, err := h.quotaService.Reconcile(r.Context(), spaceID)
+	if err != nil {
+		h.recordSpaceAudit(r, audit.Event{
+			Action: "space.quota.recalculate",
+			Result: audit.ResultFailure,
+			Target: fmt.Sprintf("space:%d", spaceID),
+		}, spaceID)
+		if strings.Contains(err.Error(), "not found") {
+			return &web.Error{Code: http.StatusNotFound, Message: "Space not found", Err: err}
+		}
+		return &web.Error{Code: http.StatusInternalServerError, Message: "Failed to recalculate space usage", Err: err}
+	}
+
+	metadata := map[string]any{
+		"usedBytes":  result.UsedBytes,
+		"driftBytes": result.DriftBytes,
+	}
+	if result.PreviousBytes != nil {
+		metadata["previousBytes"] = *result.PreviousBytes
+	}
+	h.recordSpaceAudit(r, audit.Event{
+		Action:   "space.quota.recalculate",
+		Result:   audit.ResultSuccess,
+		Target:   fmt.Sprintf("space:%d", spaceID),
+		Metadata: metadata,
+	}, spaceID)
+
+	return writeJSON(w, http.StatusOK, result)
+}
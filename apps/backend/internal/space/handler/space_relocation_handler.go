@@ -0,0 +1,356 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	"taeu.kr/cohesion/internal/audit"
+	"taeu.kr/cohesion/internal/job"
+	"taeu.kr/cohesion/internal/platform/logging"
+	"taeu.kr/cohesion/internal/platform/web"
+	"taeu.kr/cohesion/internal/space"
+)
+
+// SpaceRelocationJobType은 Space root를 새 경로로 옮기는 작업 유형입니다.
+const SpaceRelocationJobType = "space.relocate"
+
+func (h *Handler) registerSpaceRelocationJobType() {
+	h.jobs.Register(SpaceRelocationJobType, job.TypeConfig{
+		Concurrency: defaultSpaceJobWorkerLimit,
+		MaxAttempts: defaultSpaceJobMaxAttempts,
+		ArtifactTTL: defaultSpaceJobTTL,
+		Run:         h.runSpaceRelocationJob,
+		OnFinish:    h.finishSpaceRelocationJob,
+	})
+}
+
+// handleSpaceRelocation은 /api/spaces/{id}/relocation 요청을 처리합니다.
+func (h *Handler) handleSpaceRelocation(w http.ResponseWriter, r *http.Request, spaceID int64) *web.Error {
+	switch r.Method {
+	case http.MethodPost:
+		return h.handleSpaceRelocationStart(w, r, spaceID)
+	case http.MethodGet:
+		return h.handleSpaceRelocationStatus(w, r, spaceID)
+	case http.MethodDelete:
+		return h.handleSpaceRelocationCancel(w, r, spaceID)
+	default:
+		return &web.Error{Code: http.StatusMethodNotAllowed, Message: "Method not allowed"}
+	}
+}
+
+func (h *Handler) handleSpaceRelocationStart(w http.ResponseWriter, r *http.Request, spaceID int64) *web.Error {
+	username, webErr := claimsUsernameFromRequest(r)
+	if webErr != nil {
+		return webErr
+	}
+
+	var req space.RelocateSpaceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return &web.Error{Code: http.StatusBadRequest, Message: "Invalid request body", Err: err}
+	}
+
+	var created *job.Job
+	plan, err := h.relocations.Prepare(r.Context(), spaceID, &req)
+	if err == nil {
+		created, err = h.enqueueSpaceJob(r, SpaceRelocationJobType, spaceID, username, plan, func(ctx context.Context) error {
+			return h.checkRelocationTargetClaimed(ctx, plan.TargetPath)
+		})
+	}
+	if err != nil {
+		h.recordSpaceAudit(r, audit.Event{
+			Action: "space.relocate",
+			Result: audit.ResultFailure,
+			Target: fmt.Sprintf("space:%d", spaceID),
+			Metadata: map[string]any{
+				"targetPath": req.TargetPath,
+				"status":     "rejected",
+				"reason":     relocationFailureReason(err),
+			},
+		}, spaceID)
+
+		var rootValidationErr *space.SpaceRootValidationError
+		if errors.As(err, &rootValidationErr) {
+			result := rootValidationErr.Result()
+			return writeJSON(w, spaceRootValidationStatusCode(result), spaceRootValidationErrorResponse{
+				Error: result.Message,
+				Code:  result.Code,
+			})
+		}
+
+		statusCode := http.StatusInternalServerError
+		message := "Failed to start space relocation"
+		switch {
+		case errors.Is(err, space.ErrRelocationTargetInUse):
+			statusCode = http.StatusConflict
+			message = "target_path is in use by another Space or relocation"
+		case errors.Is(err, space.ErrSpaceValidation):
+			statusCode = http.StatusBadRequest
+			message = strings.TrimPrefix(err.Error(), "validation failed: ")
+		case errors.Is(err, errSpaceJobInProgress):
+			statusCode = http.StatusConflict
+			message = "Space relocation is already in progress"
+		case errors.Is(err, errSpaceJobConflict):
+			statusCode = http.StatusConflict
+			message = "Space deletion is in progress"
+		case errors.Is(err, space.ErrSpaceNotFound):
+			statusCode = http.StatusNotFound
+			message = "Space not found"
+		case errors.Is(err, space.ErrInvalidSpaceID):
+			statusCode = http.StatusBadRequest
+			message = "Invalid Space request"
+		}
+		return &web.Error{Code: statusCode, Message: message, Err: err}
+	}
+
+	job := relocationJobFromJob(created)
+	h.logRelocationEvent("info.space.relocation_started", job)
+	h.recordSpaceAudit(r, audit.Event{
+		Action: "space.relocate",
+		Result: audit.ResultSuccess,
+		Target: fmt.Sprintf("space:%d", spaceID),
+		Metadata: map[string]any{
+			"jobId":      job.ID,
+			"sourcePath": job.SourcePath,
+			"targetPath": job.TargetPath,
+			"status":     string(job.Status),
+		},
+	}, spaceID)
+
+	return writeJSON(w, http.StatusAccepted, job)
+}
+
+func (h *Handler) handleSpaceRelocationStatus(w http.ResponseWriter, r *http.Request, spaceID int64) *web.Error {
+	found, webErr := h.lookupSpaceJob(r, SpaceRelocationJobType, spaceID, "Relocation job not found")
+	if webErr != nil {
+		return webErr
+	}
+	return writeJSON(w, http.StatusOK, relocationJobFromJob(found))
+}
+
+func (h *Handler) handleSpaceRelocationCancel(w http.ResponseWriter, r *http.Request, spaceID int64) *web.Error {
+	found, webErr := h.lookupSpaceJob(r, SpaceRelocationJobType, spaceID, "Relocation job not found")
+	if webErr != nil {
+		return webErr
+	}
+
+	// 재시작 후 대기열로 돌아온 작업도 root 전환 단계에 들어섰으면 끝까지 진행해야 한다.
+	var (
+		progress    space.RelocationJob
+		canceledJob *job.Job
+		err         error
+	)
+	_ = found.DecodeResult(&progress)
+	if progress.Status == space.RelocationStateSwitching {
+		err = job.ErrNotCancelable
+	} else {
+		canceledJob, err = h.cancelSpaceJob(r.Context(), found)
+	}
+	if err != nil {
+		if errors.Is(err, job.ErrNotCancelable) {
+			return &web.Error{Code: http.StatusConflict, Message: "Space relocation can no longer be canceled", Err: err}
+		}
+		return &web.Error{Code: http.StatusInternalServerError, Message: "Failed to cancel space relocation", Err: err}
+	}
+	canceled := relocationJobFromJob(canceledJob)
+	// 대기 중에 취소된 작업은 실행 함수가 정리하지 않는다. 재시작 전 시도가 남긴 복사본을 여기서 지운다.
+	if found.Status == job.StatusQueued {
+		if err := h.relocations.Discard(r.Context(), canceled); err != nil {
+			logging.Event(log.Warn(), logging.ComponentStorage, "warn.space.relocation_cleanup_failed").
+				Str("job_id", canceled.ID).
+				Int64("space_id", canceled.SpaceID).
+				Err(err).
+				Msg("failed to clear relocation target after cancel")
+		}
+	}
+
+	h.recordSpaceAudit(r, audit.Event{
+		Action: "space.relocate.cancel",
+		Result: audit.ResultSuccess,
+		Target: fmt.Sprintf("space:%d", spaceID),
+		Metadata: map[string]any{
+			"jobId":          canceled.ID,
+			"status":         string(canceled.Status),
+			"processedItems": canceled.ProcessedItems,
+			"processedBytes": canceled.ProcessedBytes,
+		},
+	}, spaceID)
+	return writeJSON(w, http.StatusOK, canceled)
+}
+
+// checkRelocationTargetClaimed는 대기 중이거나 실행 중인 다른 이전 작업이 같은 대상(또는 서로 품는 경로)을 쓰는지 확인합니다.
+func (h *Handler) checkRelocationTargetClaimed(ctx context.Context, targetPath string) error {
+	active, err := h.jobs.List(ctx, job.ListFilter{
+		Type:     SpaceRelocationJobType,
+		Statuses: []job.Status{job.StatusQueued, job.StatusRunning},
+	})
+	if err != nil {
+		return err
+	}
+	for _, item := range active {
+		var other space.RelocationJob
+		if err := item.DecodePayload(&other); err != nil {
+			continue
+		}
+		if space.PathsOverlap(other.TargetPath, targetPath) {
+			return fmt.Errorf("%w: already claimed by relocation job %s", space.ErrRelocationTargetInUse, item.ID)
+		}
+	}
+	return nil
+}
+
+// runSpaceRelocationJob은 작업 큐에서 호출되는 저장소 이전 실행 함수입니다.
+// 재시도와 서버 재시작 후 재실행은 RelocationManager.Run이 이어서 처리합니다.
+func (h *Handler) runSpaceRelocationJob(ctx context.Context, run *job.Run) error {
+	current := run.Job()
+	if current == nil {
+		return job.Permanent(errors.New("relocation job is no longer running"))
+	}
+	var plan space.RelocationJob
+	if err := current.DecodePayload(&plan); err != nil {
+		return job.Permanent(fmt.Errorf("invalid relocation job payload: %w", err))
+	}
+	// 지난 실행이 대상에 만든 항목은 결과에 남아 있다.
+	_ = current.DecodeResult(&plan)
+
+	err := h.relocations.Run(ctx, &plan, run)
+	if errors.Is(err, space.ErrRelocationSourceChanged) || errors.Is(err, space.ErrRelocationTargetInUse) || errors.Is(err, space.ErrSpaceNotFound) {
+		return job.Permanent(err)
+	}
+	return err
+}
+
+// finishSpaceRelocationJob은 이전 작업이 끝나면 남은 복사본을 정리하고 결과를 기록합니다.
+func (h *Handler) finishSpaceRelocationJob(finished *job.Job) {
+	job := relocationJobFromJob(finished)
+	if job.Status != space.RelocationStateCompleted {
+		if err := h.relocations.Discard(context.Background(), job); err != nil {
+			job.FailureReason += fmt.Sprintf(" (target cleanup failed: %v)", err)
+		}
+	}
+
+	h.invalidateQuotaForSpaces(job.SpaceID)
+
+	result := audit.ResultSuccess
+	eventName := "info.space.relocation_completed"
+	switch job.Status {
+	case space.RelocationStateFailed:
+		result = audit.ResultFailure
+		eventName = "warn.space.relocation_failed"
+	case space.RelocationStateCanceled:
+		result = audit.ResultPartial
+		eventName = "info.space.relocation_canceled"
+	case space.RelocationStateCompleted:
+		if job.FailureReason != "" {
+			result = audit.ResultPartial
+		}
+	}
+
+	h.logRelocationEvent(eventName, job)
+	h.recordSpaceAuditBackground(job.Owner, job.RequestID, audit.Event{
+		Action:  "space.relocate",
+		Result:  result,
+		Target:  fmt.Sprintf("space:%d", job.SpaceID),
+		SpaceID: &job.SpaceID,
+		Metadata: map[string]any{
+			"jobId":               job.ID,
+			"sourcePath":          job.SourcePath,
+			"targetPath":          job.TargetPath,
+			"status":              string(job.Status),
+			"reason":              job.FailureReason,
+			"totalItems":          job.TotalItems,
+			"processedItems":      job.ProcessedItems,
+			"totalBytes":          job.TotalBytes,
+			"processedBytes":      job.ProcessedBytes,
+			"verifiedItems":       job.VerifiedItems,
+			"resyncedItems":       job.ResyncedItems,
+			"trashItemsRewritten": job.TrashItemsRewritten,
+		},
+	})
+}
+
+func (h *Handler) logRelocationEvent(eventName string, job *space.RelocationJob) {
+	if job == nil {
+		return
+	}
+
+	logger := logging.Event(log.Info(), logging.ComponentStorage, eventName)
+	if job.Status == space.RelocationStateFailed {
+		logger = logging.Event(log.Warn(), logging.ComponentStorage, eventName).Str("reason", job.FailureReason)
+	}
+	logger.
+		Str("job_id", job.ID).
+		Int64("space_id", job.SpaceID).
+		Str("owner", job.Owner).
+		Str("status", string(job.Status)).
+		Str("source_path", job.SourcePath).
+		Str("target_path", job.TargetPath).
+		Int("total_items", job.TotalItems).
+		Int("processed_items", job.ProcessedItems).
+		Int64("total_bytes", job.TotalBytes).
+		Int64("processed_bytes", job.ProcessedBytes).
+		Msg("space relocation job updated")
+}
+
+// relocationJobFromJob은 작업 큐 기록을 저장소 이전 응답으로 바꿉니다.
+// 계획(payload) 위에 실행이 남긴 단계별 상태(result)를 덮고, 진행률과 시각은 작업 기록을 따릅니다.
+func relocationJobFromJob(item *job.Job) *space.RelocationJob {
+	var relocation space.RelocationJob
+	_ = item.DecodePayload(&relocation)
+	_ = item.DecodeResult(&relocation)
+	relocation.ID = item.ID
+	if item.SpaceID != nil {
+		relocation.SpaceID = *item.SpaceID
+	}
+	relocation.Owner = item.Owner
+	relocation.RequestID = item.RequestID
+	relocation.TotalItems = item.Progress.TotalItems
+	relocation.ProcessedItems = item.Progress.ProcessedItems
+	relocation.TotalBytes = item.Progress.TotalBytes
+	relocation.ProcessedBytes = item.Progress.ProcessedBytes
+	relocation.CreatedAt = item.CreatedAt
+	relocation.UpdatedAt = item.UpdatedAt
+	relocation.FinishedAt = item.FinishedAt
+
+	switch item.Status {
+	case job.StatusQueued:
+		relocation.Status = space.RelocationStateQueued
+	case job.StatusRunning:
+		if relocation.Status == space.RelocationStateQueued || relocation.IsFinished() {
+			relocation.Status = space.RelocationStateCopying
+		}
+	case job.StatusCompleted:
+		relocation.Status = space.RelocationStateCompleted
+	case job.StatusFailed:
+		relocation.Status = space.RelocationStateFailed
+		relocation.FailureReason = item.FailureReason
+	case job.StatusCanceled:
+		relocation.Status = space.RelocationStateCanceled
+		relocation.FailureReason = "relocation canceled"
+	}
+	return &relocation
+}
+
+func relocationFailureReason(err error) string {
+	var rootValidationErr *space.SpaceRootValidationError
+	switch {
+	case errors.As(err, &rootValidationErr):
+		return string(rootValidationErr.Result().Code)
+	case errors.Is(err, errSpaceJobInProgress):
+		return "in_progress"
+	case errors.Is(err, errSpaceJobConflict):
+		return "job_conflict"
+	case errors.Is(err, space.ErrRelocationTargetInUse):
+		return "target_in_use"
+	case errors.Is(err, space.ErrSpaceNotFound):
+		return "space_not_found"
+	case errors.Is(err, space.ErrSpaceValidation):
+		return "invalid_target"
+	default:
+		return "start_failed"
+	}
+}
@@ -0,0 +1,235 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"taeu.kr/cohesion/internal/auth"
+	"taeu.kr/cohesion/internal/job"
+	"taeu.kr/cohesion/internal/platform/database"
+	"taeu.kr/cohesion/internal/space"
+	spacestore "taeu.kr/cohesion/internal/space/store"
+)
+
+func newSpaceJobTestHandler(t *testing.T) (*Handler, *space.Service) {
+	t.Helper()
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	db.SetMaxOpenConns(1)
+	if err := database.Migrate(context.Background(), db); err != nil {
+		t.Fatalf("migrate db: %v", err)
+	}
+	spaceSvc := space.NewService(spacestore.NewStore(db))
+	return NewHandler(spaceSvc, nil, nil), spaceSvc
+}
+
+func TestSpaceRelocationJob_ResumesAfterServerRestart(t *testing.T) {
+	handler, spaceSvc := newSpaceJobTestHandler(t)
+	ctx := context.Background()
+
+	// 이전 프로세스가 복사 도중 멈춘 작업을 흉내 낸다. 대상에는 반쯤 쓴 파일이 남아 있다.
+	jobStore := job.NewMemoryStore()
+	seed := func(name string, attempts int) (*space.Space, string) {
+		t.Helper()
+		sourceRoot := t.TempDir()
+		targetRoot := t.TempDir()
+		if err := os.WriteFile(filepath.Join(sourceRoot, "a.txt"), []byte("alpha"), 0o644); err != nil {
+			t.Fatalf("write source file: %v", err)
+		}
+		if err := os.WriteFile(filepath.Join(targetRoot, "a.txt"), []byte("al"), 0o644); err != nil {
+			t.Fatalf("write partial copy: %v", err)
+		}
+		created, err := spaceSvc.CreateSpace(ctx, &space.CreateSpaceRequest{SpaceName: name, SpacePath: sourceRoot})
+		if err != nil {
+			t.Fatalf("create space: %v", err)
+		}
+		payload, err := json.Marshal(space.RelocationJob{SpaceID: created.ID, SourcePath: sourceRoot, TargetPath: targetRoot, Status: space.RelocationStateQueued})
+		if err != nil {
+			t.Fatalf("encode payload: %v", err)
+		}
+		now := time.Now().UTC()
+		if err := jobStore.Create(ctx, &job.Job{
+			ID:          "relocate-" + name,
+			Type:        SpaceRelocationJobType,
+			Owner:       "admin",
+			SpaceID:     &created.ID,
+			Status:      job.StatusRunning,
+			Payload:     payload,
+			Result:      json.RawMessage(`{"status":"copying","targetEntries":["a.txt"]}`),
+			Attempts:    attempts,
+			MaxAttempts: defaultSpaceJobMaxAttempts,
+			RunAfter:    now,
+			CreatedAt:   now,
+			UpdatedAt:   now,
+			StartedAt:   &now,
+		}); err != nil {
+			t.Fatalf("seed job: %v", err)
+		}
+		return created, targetRoot
+	}
+	resumed, resumedTarget := seed("Media", 1)
+	exhausted, exhaustedTarget := seed("Photos", defaultSpaceJobMaxAttempts)
+
+	manager := job.NewManager(jobStore)
+	handler.SetJobManager(manager)
+	if err := manager.Start(ctx); err != nil {
+		t.Fatalf("start job manager: %v", err)
+	}
+	defer manager.Stop()
+
+	status := func(spaceID int64, want space.RelocationState) *space.RelocationJob {
+		t.Helper()
+		deadline := time.Now().Add(5 * time.Second)
+		for {
+			req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/api/spaces/%d/relocation", spaceID), nil)
+			rec := httptest.NewRecorder()
+			if webErr := handler.handleSpaceRelocation(rec, req, spaceID); webErr != nil {
+				t.Fatalf("get relocation status: %+v", webErr)
+			}
+			var current space.RelocationJob
+			if err := json.NewDecoder(rec.Body).Decode(&current); err != nil {
+				t.Fatalf("decode relocation status: %v", err)
+			}
+			if current.Status == want {
+				return &current
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("relocation of space %d did not reach %s: %+v", spaceID, want, current)
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+
+	completed := status(resumed.ID, space.RelocationStateCompleted)
+	if completed.SourcePath == "" || completed.TargetPath != resumedTarget || completed.TotalItems != 1 {
+		t.Fatalf("unexpected resumed relocation: %+v", completed)
+	}
+	content, err := os.ReadFile(filepath.Join(resumedTarget, "a.txt"))
+	if err != nil || string(content) != "alpha" {
+		t.Fatalf("expected a fresh copy in the new root, got %q (%v)", content, err)
+	}
+	moved, err := spaceSvc.GetSpaceByID(ctx, resumed.ID)
+	if err != nil || moved.SpacePath != resumedTarget {
+		t.Fatalf("expected space root to be switched, got %+v (%v)", moved, err)
+	}
+
+	// 시도 횟수를 다 쓴 작업은 실패로 남고, 반쯤 복사한 대상은 비워지며 원래 root를 계속 쓴다.
+	failed := status(exhausted.ID, space.RelocationStateFailed)
+	if failed.FailureReason == "" {
+		t.Fatalf("expected a failure reason, got %+v", failed)
+	}
+	if entries, _ := os.ReadDir(exhaustedTarget); len(entries) != 0 {
+		t.Fatalf("expected leftovers to be cleared, got %d entries", len(entries))
+	}
+	if err := spaceSvc.EnsureWritable(ctx, exhausted.ID); err != nil {
+		t.Fatalf("expected writes to the original root, got %v", err)
+	}
+}
+
+func TestSpaceRelocationStart_RejectsTargetClaimedByAnotherJob(t *testing.T) {
+	handler, spaceSvc := newSpaceJobTestHandler(t)
+	ctx := context.Background()
+
+	first, err := spaceSvc.CreateSpace(ctx, &space.CreateSpaceRequest{SpaceName: "Media", SpacePath: t.TempDir()})
+	if err != nil {
+		t.Fatalf("create space: %v", err)
+	}
+	second, err := spaceSvc.CreateSpace(ctx, &space.CreateSpaceRequest{SpaceName: "Photos", SpacePath: t.TempDir()})
+	if err != nil {
+		t.Fatalf("create space: %v", err)
+	}
+
+	// 첫 Space의 이전이 대상 경로를 잡고 대기 중이다.
+	targetRoot := t.TempDir()
+	jobStore := job.NewMemoryStore()
+	seedQueuedSpaceJob(t, jobStore, SpaceRelocationJobType, first.ID, space.RelocationJob{SpaceID: first.ID, SourcePath: first.SpacePath, TargetPath: targetRoot})
+	handler.SetJobManager(job.NewManager(jobStore))
+
+	for _, target := range []string{targetRoot, filepath.Join(targetRoot, "inner")} {
+		if err := os.MkdirAll(target, 0o755); err != nil {
+			t.Fatalf("mkdir target: %v", err)
+		}
+		body := strings.NewReader(fmt.Sprintf(`{"target_path":%q}`, target))
+		req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/spaces/%d/relocation", second.ID), body)
+		req = req.WithContext(auth.WithClaims(req.Context(), &auth.Claims{Username: "admin"}))
+		rec := httptest.NewRecorder()
+		webErr := handler.handleSpaceRelocation(rec, req, second.ID)
+		if webErr == nil || webErr.Code != http.StatusConflict || !errors.Is(webErr.Err, space.ErrRelocationTargetInUse) {
+			t.Fatalf("expected conflict for claimed target %s, got %+v", target, webErr)
+		}
+	}
+}
+
+func TestSpaceJobs_RelocationAndDeletionExcludeEachOther(t *testing.T) {
+	handler, spaceSvc := newSpaceJobTestHandler(t)
+	ctx := context.Background()
+
+	relocating, err := spaceSvc.CreateSpace(ctx, &space.CreateSpaceRequest{SpaceName: "Media", SpacePath: t.TempDir()})
+	if err != nil {
+		t.Fatalf("create space: %v", err)
+	}
+	deleting, err := spaceSvc.CreateSpace(ctx, &space.CreateSpaceRequest{SpaceName: "Photos", SpacePath: t.TempDir()})
+	if err != nil {
+		t.Fatalf("create space: %v", err)
+	}
+	jobStore := job.NewMemoryStore()
+	seedQueuedSpaceJob(t, jobStore, SpaceRelocationJobType, relocating.ID, space.RelocationJob{SpaceID: relocating.ID, SourcePath: relocating.SpacePath, TargetPath: t.TempDir()})
+	seedQueuedSpaceJob(t, jobStore, SpaceDeletionJobType, deleting.ID, space.DeletionJob{SpaceID: deleting.ID, Mode: space.DeleteSpaceModeDeleteData, SourcePath: deleting.SpacePath})
+	handler.SetJobManager(job.NewManager(jobStore))
+
+	withAdmin := func(req *http.Request) *http.Request {
+		return req.WithContext(auth.WithClaims(req.Context(), &auth.Claims{Username: "admin"}))
+	}
+
+	// 이전 중인 Space는 삭제할 수 없다.
+	req := withAdmin(httptest.NewRequest(http.MethodDelete, fmt.Sprintf("/api/spaces/%d", relocating.ID), nil))
+	webErr := handler.handleSpaceDeletionStart(httptest.NewRecorder(), req, relocating.ID, &space.DeleteSpaceRequest{Mode: space.DeleteSpaceModeDeleteData, Confirmation: relocating.ProtocolName()})
+	if webErr == nil || webErr.Code != http.StatusConflict || !errors.Is(webErr.Err, errSpaceJobConflict) {
+		t.Fatalf("expected deletion to conflict with relocation, got %+v", webErr)
+	}
+
+	// 삭제 중인 Space는 이전할 수 없다.
+	body := strings.NewReader(fmt.Sprintf(`{"target_path":%q}`, t.TempDir()))
+	req = withAdmin(httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/spaces/%d/relocation", deleting.ID), body))
+	webErr = handler.handleSpaceRelocation(httptest.NewRecorder(), req, deleting.ID)
+	if webErr == nil || webErr.Code != http.StatusConflict || !errors.Is(webErr.Err, errSpaceJobConflict) {
+		t.Fatalf("expected relocation to conflict with deletion, got %+v", webErr)
+	}
+}
+
+// seedQueuedSpaceJob은 대기 중인 Space 작업을 저장소에 직접 넣는다. 작업 관리자를 시작하지 않으면 대기 상태로 남는다.
+func seedQueuedSpaceJob(t *testing.T, store job.Storer, jobType string, spaceID int64, plan any) {
+	t.Helper()
+	payload, err := json.Marshal(plan)
+	if err != nil {
+		t.Fatalf("encode payload: %v", err)
+	}
+	now := time.Now().UTC()
+	if err := store.Create(context.Background(), &job.Job{
+		ID:          fmt.Sprintf("%s-%d", jobType, spaceID),
+		Type:        jobType,
+		Owner:       "admin",
+		SpaceID:     &spaceID,
+		Status:      job.StatusQueued,
+		Payload:     payload,
+		MaxAttempts: defaultSpaceJobMaxAttempts,
+		RunAfter:    now,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}); err != nil {
+		t.Fatalf("seed job: %v", err)
+	}
+}
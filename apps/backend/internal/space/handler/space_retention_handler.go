@@ -0,0 +1,220 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"taeu.kr/cohesion/internal/audit"
+	"taeu.kr/cohesion/internal/auth"
+	"taeu.kr/cohesion/internal/platform/web"
+	"taeu.kr/cohesion/internal/space"
+)
+
+// retentionLockedFailureCode는 항목별 실패 목록에서 보존 잠금으로 거절한 항목의 code입니다.
+const retentionLockedFailureCode = "retention_locked"
+
+// SetRetentionService는 보존 잠금을 프로토콜 서버와 함께 쓰도록 공용 RetentionService로 교체합니다.
+func (h *Handler) SetRetentionService(retention *space.RetentionService) {
+	if retention != nil {
+		h.retention = retention
+	}
+}
+
+// handleSpaceRetentionLocks는 /api/spaces/{id}/retention-locks 요청을 처리합니다.
+//   - GET    : Space의 잠금 목록
+//   - PUT    : { path, retainUntil?, legalHold, reason? } 잠금을 걸거나 늘립니다.
+//   - DELETE : ?path= 기간이 지나고 법적 보존도 풀린 잠금을 지웁니다.
+func (h *Handler) handleSpaceRetentionLocks(w http.ResponseWriter, r *http.Request, spaceID int64) *web.Error {
+	switch r.Method {
+	case http.MethodGet:
+		if _, err := h.spaceService.GetSpaceByID(r.Context(), spaceID); err != nil {
+			return spaceLookupWebError(err)
+		}
+		locks, err := h.retention.ListLocks(r.Context(), spaceID)
+		if err != nil {
+			return &web.Error{Code: http.StatusInternalServerError, Message: "Failed to list retention locks", Err: err}
+		}
+		return writeJSON(w, http.StatusOK, locks)
+	case http.MethodPut:
+		return h.handleRetentionLockSave(w, r, spaceID)
+	case http.MethodDelete:
+		return h.handleRetentionLockDelete(w, r, spaceID)
+	default:
+		return &web.Error{Code: http.StatusMethodNotAllowed, Message: "Method not allowed"}
+	}
+}
+
+func (h *Handler) handleRetentionLockSave(w http.ResponseWriter, r *http.Request, spaceID int64) *web.Error {
+	var req space.RetentionLockRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return &web.Error{Code: http.StatusBadRequest, Message: "Invalid request body", Err: err}
+	}
+	username, webErr := claimsUsernameFromRequest(r)
+	if webErr != nil {
+		return webErr
+	}
+	spaceData, err := h.spaceService.GetSpaceByID(r.Context(), spaceID)
+	if err != nil {
+		return spaceLookupWebError(err)
+	}
+	if err := ensurePathOutsideTrash(normalizeRelativePath(req.Path)); err != nil {
+		return &web.Error{Code: http.StatusForbidden, Message: "Access denied: invalid path", Err: err}
+	}
+
+	lock, previous, err := h.retention.SaveLock(r.Context(), spaceID, spaceData.SpacePath, req, username)
+	if err != nil {
+		h.recordSpaceAudit(r, audit.Event{
+			Action:   "space.retention.update",
+			Result:   audit.ResultFailure,
+			Target:   fmt.Sprintf("space:%d", spaceID),
+			Metadata: map[string]any{"path": req.Path, "reason": err.Error()},
+		}, spaceID)
+		switch {
+		case errors.Is(err, space.ErrInvalidRetentionLock):
+			return &web.Error{Code: http.StatusBadRequest, Message: err.Error(), Err: err}
+		case errors.Is(err, space.ErrRetentionShortened):
+			return &web.Error{Code: http.StatusConflict, Message: "Retention can only be extended while it is active", Err: err}
+		default:
+			return &web.Error{Code: http.StatusInternalServerError, Message: "Failed to save retention lock", Err: err}
+		}
+	}
+
+	metadata := map[string]any{
+		"path":        lock.Path,
+		"retainUntil": formatRetainUntil(lock.RetainUntil),
+		"legalHold":   lock.LegalHold,
+		"reason":      lock.Reason,
+	}
+	if previous != nil {
+		metadata["previousRetainUntil"] = formatRetainUntil(previous.RetainUntil)
+		metadata["previousLegalHold"] = previous.LegalHold
+	}
+	h.recordSpaceAudit(r, audit.Event{
+		Action:   "space.retention.update",
+		Result:   audit.ResultSuccess,
+		Target:   fmt.Sprintf("space:%d", spaceID),
+		Metadata: metadata,
+	}, spaceID)
+	return writeJSON(w, http.StatusOK, lock)
+}
+
+func (h *Handler) handleRetentionLockDelete(w http.ResponseWriter, r *http.Request, spaceID int64) *web.Error {
+	lockPath := r.URL.Query().Get("path")
+	if _, err := h.spaceService.GetSpaceByID(r.Context(), spaceID); err != nil {
+		return spaceLookupWebError(err)
+	}
+
+	lock, err := h.retention.DeleteLock(r.Context(), spaceID, lockPath)
+	if err != nil {
+		h.recordSpaceAudit(r, audit.Event{
+			Action:   "space.retention.delete",
+			Result:   audit.ResultFailure,
+			Target:   fmt.Sprintf("space:%d", spaceID),
+			Metadata: map[string]any{"path": lockPath, "reason": err.Error()},
+		}, spaceID)
+		switch {
+		case errors.Is(err, space.ErrInvalidRetentionLock):
+			return &web.Error{Code: http.StatusBadRequest, Message: err.Error(), Err: err}
+		case errors.Is(err, space.ErrRetentionLockActive):
+			return &web.Error{Code: http.StatusConflict, Message: "Retention lock is still active", Err: err}
+		case errors.Is(err, space.ErrRetentionLockNotFound):
+			return &web.Error{Code: http.StatusNotFound, Message: "Retention lock not found", Err: err}
+		default:
+			return &web.Error{Code: http.StatusInternalServerError, Message: "Failed to delete retention lock", Err: err}
+		}
+	}
+
+	h.recordSpaceAudit(r, audit.Event{
+		Action:   "space.retention.delete",
+		Result:   audit.ResultSuccess,
+		Target:   fmt.Sprintf("space:%d", spaceID),
+		Metadata: map[string]any{"path": lock.Path, "retainUntil": formatRetainUntil(lock.RetainUntil)},
+	}, spaceID)
+	return writeJSON(w, http.StatusOK, map[string]any{"path": lock.Path, "status": "deleted"})
+}
+
+// checkRetention은 absPath를 바꾸기 전에 요청한 사용자 이름으로 보존 잠금을 확인합니다. 거절하면 *space.RetentionLockedError입니다.
+func (h *Handler) checkRetention(ctx context.Context, spaceID int64, spaceRoot, absPath, operation string) error {
+	actor := ""
+	if claims, ok := auth.ClaimsFromContext(ctx); ok {
+		actor = claims.Username
+	}
+	return h.checkRetentionAs(ctx, actor, spaceID, spaceRoot, absPath, operation)
+}
+
+// checkRetentionAs는 요청 인증 정보가 없는 백그라운드 작업에서 작업 소유자 이름으로 보존 잠금을 확인합니다.
+func (h *Handler) checkRetentionAs(ctx context.Context, actor string, spaceID int64, spaceRoot, absPath, operation string) error {
+	if h.retention == nil {
+		return nil
+	}
+	return h.retention.EnsureMutable(ctx, space.RetentionTarget{
+		SpaceID:   spaceID,
+		SpaceRoot: spaceRoot,
+		AbsPath:   absPath,
+		Operation: operation,
+		Actor:     actor,
+		Source:    "rest",
+	})
+}
+
+// ensureMutable은 checkRetention 결과를 응답 오류로 바꿉니다.
+func (h *Handler) ensureMutable(r *http.Request, spaceData *space.Space, absPath, operation string) *web.Error {
+	if err := h.checkRetention(r.Context(), spaceData.ID, spaceData.SpacePath, absPath, operation); err != nil {
+		return retentionWebError(err)
+	}
+	return nil
+}
+
+// ensureSpaceHasNoActiveRetention은 유효한 보존 잠금이 있는 Space 삭제를 409로 거절합니다.
+func (h *Handler) ensureSpaceHasNoActiveRetention(r *http.Request, spaceID int64, mode space.DeleteSpaceMode) *web.Error {
+	if h.retention == nil {
+		return nil
+	}
+	active, err := h.retention.HasActiveLocks(r.Context(), spaceID)
+	if err != nil {
+		return &web.Error{Code: http.StatusInternalServerError, Message: "Failed to check retention locks", Err: err}
+	}
+	if !active {
+		return nil
+	}
+	h.recordSpaceAudit(r, audit.Event{
+		Action: "space.delete",
+		Result: audit.ResultFailure,
+		Target: fmt.Sprintf("space:%d", spaceID),
+		Metadata: map[string]any{
+			"mode":   string(mode),
+			"status": "rejected",
+			"reason": retentionLockedFailureCode,
+		},
+	}, spaceID)
+	return &web.Error{Code: http.StatusConflict, Message: "Space has active retention locks", Err: space.ErrRetentionLocked}
+}
+
+// retentionWebError는 보존 잠금 거절을 423으로, 나머지는 500으로 바꿉니다.
+func retentionWebError(err error) *web.Error {
+	var lockedErr *space.RetentionLockedError
+	if errors.As(err, &lockedErr) {
+		return &web.Error{Code: http.StatusLocked, Message: lockedErr.Reason(), Err: err}
+	}
+	return &web.Error{Code: http.StatusInternalServerError, Message: "Failed to check retention lock", Err: err}
+}
+
+// retentionFailure는 항목별 실패 목록에 남길 사유와 code입니다.
+func retentionFailure(err error) (string, string) {
+	var lockedErr *space.RetentionLockedError
+	if errors.As(err, &lockedErr) {
+		return lockedErr.Reason(), retentionLockedFailureCode
+	}
+	return safeFilesystemReason("Failed to check retention lock", err), ""
+}
+
+func formatRetainUntil(value *time.Time) string {
+	if value == nil {
+		return ""
+	}
+	return value.UTC().Format(time.RFC3339)
+}
@@ -27,7 +27,7 @@ func (f *fakeCreateSpaceStore) GetAll(context.Context) ([]*space.Space, error) {
 func (f *fakeCreateSpaceStore) GetByName(_ context.Context, name string) (*space.Space, error) {
 	item, ok := f.spacesByName[name]
 	if !ok {
-		return nil, errors.New("space not found")
+		return nil, space.ErrSpaceNotFound
 	}
 	return cloneSpace(item), nil
 }
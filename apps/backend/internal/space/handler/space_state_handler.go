@@ -0,0 +1,128 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"taeu.kr/cohesion/internal/audit"
+	"taeu.kr/cohesion/internal/auth"
+	"taeu.kr/cohesion/internal/platform/web"
+	"taeu.kr/cohesion/internal/space"
+)
+
+// spaceAdminChecker는 maintenance 상태에서 관리자 읽기를 허용하기 위한 선택 인터페이스입니다.
+type spaceAdminChecker interface {
+	IsAdmin(ctx context.Context, username string) (bool, error)
+}
+
+// handleSpaceState는 PATCH /api/spaces/{id}/state 요청을 처리합니다.
+func (h *Handler) handleSpaceState(w http.ResponseWriter, r *http.Request, spaceID int64) *web.Error {
+	if r.Method != http.MethodPatch {
+		return &web.Error{Code: http.StatusMethodNotAllowed, Message: "Method not allowed"}
+	}
+
+	var req space.UpdateSpaceStateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return &web.Error{Code: http.StatusBadRequest, Message: "Invalid request body", Err: err}
+	}
+
+	previous, err := h.spaceService.GetSpaceByID(r.Context(), spaceID)
+	if err != nil {
+		return &web.Error{Code: http.StatusNotFound, Message: "Space not found", Err: err}
+	}
+
+	updated, err := h.spaceService.UpdateSpaceState(r.Context(), spaceID, &req)
+	if err != nil {
+		h.recordSpaceAudit(r, audit.Event{
+			Action: "space.state.update",
+			Result: audit.ResultFailure,
+			Target: fmt.Sprintf("space:%d", spaceID),
+			Metadata: map[string]any{
+				"previousState": string(previous.State()),
+				"state":         string(req.State),
+			},
+		}, spaceID)
+
+		statusCode := http.StatusInternalServerError
+		message := "Failed to update Space state"
+		switch {
+		case errors.Is(err, space.ErrSpaceValidation):
+			statusCode = http.StatusBadRequest
+			message = strings.TrimPrefix(err.Error(), "validation failed: ")
+		case errors.Is(err, space.ErrSpaceNotFound):
+			statusCode = http.StatusNotFound
+			message = "Space not found"
+		case errors.Is(err, space.ErrInvalidSpaceID):
+			statusCode = http.StatusBadRequest
+			message = "Invalid Space request"
+		}
+		return &web.Error{Code: statusCode, Message: message, Err: err}
+	}
+
+	h.recordSpaceAudit(r, audit.Event{
+		Action: "space.state.update",
+		Result: audit.ResultSuccess,
+		Target: fmt.Sprintf("space:%d", spaceID),
+		Metadata: map[string]any{
+			"previousState": string(previous.State()),
+			"state":         string(updated.State()),
+		},
+	}, spaceID)
+
+	return writeJSON(w, http.StatusOK, newSpaceResponse(updated))
+}
+
+// ensureSpaceReadable은 root가 offline인 Space를 막고, maintenance 상태 Space의 내용은 관리자에게만 보여 줍니다.
+// Space 조회 실패는 이후 핸들러가 404로 처리하도록 통과시킵니다.
+func (h *Handler) ensureSpaceReadable(r *http.Request, spaceID int64) *web.Error {
+	if err := h.spaceService.EnsureOnline(spaceID); err != nil {
+		return spaceStateWebError(err, http.StatusServiceUnavailable)
+	}
+	spaceObj, err := h.spaceService.GetSpaceByID(r.Context(), spaceID)
+	if err != nil {
+		return nil
+	}
+	if spaceObj.State() != space.SpaceStateMaintenance {
+		return nil
+	}
+	if err := spaceObj.CheckReadable(h.isAdminRequest(r)); err != nil {
+		return spaceStateWebError(err, http.StatusServiceUnavailable)
+	}
+	return nil
+}
+
+func (h *Handler) isAdminRequest(r *http.Request) bool {
+	claims, ok := auth.ClaimsFromContext(r.Context())
+	if !ok {
+		return false
+	}
+	checker, ok := h.accountService.(spaceAdminChecker)
+	if !ok {
+		return false
+	}
+	isAdmin, err := checker.IsAdmin(r.Context(), claims.Username)
+	return err == nil && isAdmin
+}
+
+// spaceStateWebError는 Space 상태 때문에 거절된 요청을 상태별 메시지로 변환합니다.
+// root offline은 일시적 장애이므로 항상 503으로 응답합니다.
+func spaceStateWebError(err error, statusCode int) *web.Error {
+	switch {
+	case errors.Is(err, space.ErrSpaceOffline):
+		return &web.Error{Code: http.StatusServiceUnavailable, Message: "Space storage is offline", Err: err}
+	case errors.Is(err, space.ErrSpaceMaintenance):
+		return &web.Error{Code: statusCode, Message: "Space is under maintenance", Err: err}
+	case errors.Is(err, space.ErrSpaceArchived):
+		return &web.Error{Code: statusCode, Message: "Space is archived", Err: err}
+	case errors.Is(err, space.ErrSpaceReadOnly):
+		return &web.Error{Code: statusCode, Message: "Space is read-only", Err: err}
+	case errors.Is(err, space.ErrSpaceNotFound):
+		return &web.Error{Code: http.StatusNotFound, Message: "Space not found", Err: err}
+	default:
+		return &web.Error{Code: http.StatusInternalServerError, Message: "Failed to evaluate space state", Err: err}
+	}
+}
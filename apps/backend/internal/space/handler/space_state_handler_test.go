@@ -0,0 +1,197 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"taeu.kr/cohesion/internal/account"
+	"taeu.kr/cohesion/internal/space"
+)
+
+type fakeStateSpaceStore struct {
+	fakeRenameSpaceStore
+}
+
+func (f *fakeStateSpaceStore) GetAll(context.Context) ([]*space.Space, error) {
+	items := make([]*space.Space, 0, len(f.spacesByID))
+	for id := int64(1); id <= int64(len(f.spacesByID)); id++ {
+		if item, ok := f.spacesByID[id]; ok {
+			items = append(items, cloneSpace(item))
+		}
+	}
+	return items, nil
+}
+
+func (f *fakeStateSpaceStore) UpdateState(_ context.Context, id int64, state space.SpaceState) (*space.Space, error) {
+	item, ok := f.spacesByID[id]
+	if !ok {
+		return nil, space.ErrSpaceNotFound
+	}
+	item.SpaceState = state
+	return cloneSpace(item), nil
+}
+
+type fakeStateAccessService struct {
+	admins map[string]bool
+}
+
+func (f fakeStateAccessService) CanAccessSpaceByID(context.Context, string, int64, account.Permission) (bool, error) {
+	return true, nil
+}
+
+func (f fakeStateAccessService) IsAdmin(_ context.Context, username string) (bool, error) {
+	return f.admins[username], nil
+}
+
+func newStateTestHandler(t *testing.T, states map[int64]space.SpaceState) (*Handler, *fakeStateSpaceStore) {
+	t.Helper()
+	store := &fakeStateSpaceStore{fakeRenameSpaceStore{spacesByID: map[int64]*space.Space{}}}
+	for id, state := range states {
+		store.spacesByID[id] = &space.Space{ID: id, SpaceName: "Space", SpacePath: t.TempDir(), SpaceState: state}
+	}
+	handler := NewHandler(space.NewService(store), nil, fakeStateAccessService{admins: map[string]bool{"admin": true}})
+	return handler, store
+}
+
+func TestHandleSpaceState_UpdatesStateAndBlocksWrites(t *testing.T) {
+	handler, store := newStateTestHandler(t, map[int64]space.SpaceState{1: space.SpaceStateActive})
+
+	req := httptest.NewRequest(http.MethodPatch, "/api/spaces/1/state", bytes.NewBufferString(`{"state":"read_only"}`))
+	req = withClaims(req, "admin")
+	rec := httptest.NewRecorder()
+	if webErr := handler.handleSpaceByID(rec, req); webErr != nil {
+		t.Fatalf("unexpected web error: %+v", webErr)
+	}
+	var resp spaceResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if resp.SpaceState != string(space.SpaceStateReadOnly) || store.spacesByID[1].SpaceState != space.SpaceStateReadOnly {
+		t.Fatalf("expected read_only state, got response %q store %q", resp.SpaceState, store.spacesByID[1].SpaceState)
+	}
+
+	tests := []struct {
+		state   space.SpaceState
+		message string
+	}{
+		{state: space.SpaceStateReadOnly, message: "Space is read-only"},
+		{state: space.SpaceStateMaintenance, message: "Space is under maintenance"},
+		{state: space.SpaceStateArchived, message: "Space is archived"},
+	}
+	for _, tc := range tests {
+		store.spacesByID[1].SpaceState = tc.state
+		for _, action := range []string{"upload", "create-folder", "trash-empty"} {
+			req := httptest.NewRequest(http.MethodPost, "/api/spaces/1/files/"+action, bytes.NewBufferString(`{}`))
+			req = withClaims(req, "admin")
+			webErr := handler.handleSpaceByID(httptest.NewRecorder(), req)
+			if webErr == nil || webErr.Code != http.StatusLocked || webErr.Message != tc.message {
+				t.Fatalf("%s %s: expected 423 %q, got %+v", tc.state, action, tc.message, webErr)
+			}
+		}
+	}
+}
+
+func TestHandleSpaceState_RejectsUnknownState(t *testing.T) {
+	handler, store := newStateTestHandler(t, map[int64]space.SpaceState{1: space.SpaceStateActive})
+
+	req := httptest.NewRequest(http.MethodPatch, "/api/spaces/1/state", bytes.NewBufferString(`{"state":"frozen"}`))
+	req = withClaims(req, "admin")
+	webErr := handler.handleSpaceByID(httptest.NewRecorder(), req)
+	if webErr == nil || webErr.Code != http.StatusBadRequest {
+		t.Fatalf("expected bad request, got %+v", webErr)
+	}
+	if store.spacesByID[1].SpaceState != space.SpaceStateActive {
+		t.Fatalf("expected state to stay active, got %q", store.spacesByID[1].SpaceState)
+	}
+}
+
+func TestHandleSpaceByID_MaintenanceBlocksReadsForNonAdmins(t *testing.T) {
+	handler, _ := newStateTestHandler(t, map[int64]space.SpaceState{1: space.SpaceStateMaintenance})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/spaces/1/browse?path=", nil)
+	req = withClaims(req, "member")
+	webErr := handler.handleSpaceByID(httptest.NewRecorder(), req)
+	if webErr == nil || webErr.Code != http.StatusServiceUnavailable || webErr.Message != "Space is under maintenance" {
+		t.Fatalf("expected 503 maintenance error, got %+v", webErr)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/spaces/1/files/download?path=missing.txt", nil)
+	req = withClaims(req, "admin")
+	webErr = handler.handleSpaceByID(httptest.NewRecorder(), req)
+	if webErr != nil && webErr.Code == http.StatusServiceUnavailable {
+		t.Fatalf("expected admin reads to pass maintenance check, got %+v", webErr)
+	}
+}
+
+func TestHandleGetSpaces_HidesArchivedSpaces(t *testing.T) {
+	handler, _ := newStateTestHandler(t, map[int64]space.SpaceState{
+		1: space.SpaceStateActive,
+		2: space.SpaceStateArchived,
+		3: space.SpaceStateMaintenance,
+	})
+
+	listIDs := func(target string) []int64 {
+		req := withClaims(httptest.NewRequest(http.MethodGet, target, nil), "member")
+		rec := httptest.NewRecorder()
+		if webErr := handler.handleGetSpaces(rec, req); webErr != nil {
+			t.Fatalf("unexpected web error: %+v", webErr)
+		}
+		var resp []spaceResponse
+		if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+			t.Fatalf("decode response: %v", err)
+		}
+		ids := make([]int64, 0, len(resp))
+		for _, item := range resp {
+			ids = append(ids, item.ID)
+		}
+		return ids
+	}
+
+	if got := listIDs("/api/spaces"); len(got) != 2 || got[0] != 1 || got[1] != 3 {
+		t.Fatalf("expected archived space to be hidden, got %v", got)
+	}
+	if got := listIDs("/api/spaces?include_archived=true"); len(got) != 3 {
+		t.Fatalf("expected archived space with include_archived, got %v", got)
+	}
+}
+
+func TestHandleSpaceByID_OfflineRootBlocksReadsAndWrites(t *testing.T) {
+	handler, store := newStateTestHandler(t, map[int64]space.SpaceState{1: space.SpaceStateActive})
+	root := store.spacesByID[1].SpacePath
+	if err := os.Remove(root); err != nil {
+		t.Fatalf("remove root: %v", err)
+	}
+	if health := handler.spaceService.CheckSpaceRoot(context.Background(), cloneSpace(store.spacesByID[1])); health.Online() {
+		t.Fatalf("expected root to be offline, got %+v", health)
+	}
+
+	for _, req := range []*http.Request{
+		httptest.NewRequest(http.MethodGet, "/api/spaces/1/browse?path=", nil),
+		httptest.NewRequest(http.MethodPost, "/api/spaces/1/files/create-folder", bytes.NewBufferString(`{"path":"","name":"docs"}`)),
+	} {
+		webErr := handler.handleSpaceByID(httptest.NewRecorder(), withClaims(req, "admin"))
+		if webErr == nil || webErr.Code != http.StatusServiceUnavailable || webErr.Message != "Space storage is offline" {
+			t.Fatalf("%s %s: expected 503 offline error, got %+v", req.Method, req.URL.Path, webErr)
+		}
+	}
+	if _, err := os.Stat(root); !os.IsNotExist(err) {
+		t.Fatalf("expected no directory to be created at the missing root, got %v", err)
+	}
+
+	rec := httptest.NewRecorder()
+	if webErr := handler.handleGetSpaces(rec, withClaims(httptest.NewRequest(http.MethodGet, "/api/spaces", nil), "member")); webErr != nil {
+		t.Fatalf("unexpected web error: %+v", webErr)
+	}
+	var resp []spaceResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if len(resp) != 1 || resp[0].RootHealth == nil || resp[0].RootHealth.Status != space.RootHealthOffline || resp[0].RootHealth.Reason != space.RootHealthReasonMissing {
+		t.Fatalf("expected offline root health in list, got %+v", resp)
+	}
+}
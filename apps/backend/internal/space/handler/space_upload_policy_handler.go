@@ -0,0 +1,133 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"taeu.kr/cohesion/internal/audit"
+	"taeu.kr/cohesion/internal/platform/web"
+	"taeu.kr/cohesion/internal/space"
+)
+
+// SetUploadPolicyService는 업로드 정책을 프로토콜 서버와 함께 쓰도록 공용 UploadPolicyService로 교체합니다.
+func (h *Handler) SetUploadPolicyService(uploadPolicies *space.UploadPolicyService) {
+	if uploadPolicies != nil {
+		h.uploadPolicies = uploadPolicies
+	}
+}
+
+// handleSpaceUploadPolicy는 GET/PUT /api/spaces/{id}/upload-policy 요청을 처리합니다.
+// PUT body: { allowedExtensions, blockedExtensions, allowedTypes, blockedTypes: []string, maxFileBytes?: int64 }
+// 정책 전체를 바꾸며 생략한 항목은 제한 없음입니다.
+func (h *Handler) handleSpaceUploadPolicy(w http.ResponseWriter, r *http.Request, spaceID int64) *web.Error {
+	switch r.Method {
+	case http.MethodGet:
+		if _, err := h.spaceService.GetSpaceByID(r.Context(), spaceID); err != nil {
+			return spaceLookupWebError(err)
+		}
+		policy, err := h.uploadPolicies.GetUploadPolicy(r.Context(), spaceID)
+		if err != nil {
+			return &web.Error{Code: http.StatusInternalServerError, Message: "Failed to get upload policy", Err: err}
+		}
+		return writeJSON(w, http.StatusOK, policy)
+	case http.MethodPut:
+	default:
+		return &web.Error{Code: http.StatusMethodNotAllowed, Message: "Method not allowed"}
+	}
+
+	var req struct {
+		AllowedExtensions []string `json:"allowedExtensions"`
+		BlockedExtensions []string `json:"blockedExtensions"`
+		AllowedTypes      []string `json:"allowedTypes"`
+		BlockedTypes      []string `json:"blockedTypes"`
+		MaxFileBytes      *int64   `json:"maxFileBytes"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return &web.Error{Code: http.StatusBadRequest, Message: "Invalid request body", Err: err}
+	}
+	if _, err := h.spaceService.GetSpaceByID(r.Context(), spaceID); err != nil {
+		return spaceLookupWebError(err)
+	}
+
+	saved, err := h.uploadPolicies.SaveUploadPolicy(r.Context(), &space.UploadPolicy{
+		SpaceID:           spaceID,
+		AllowedExtensions: req.AllowedExtensions,
+		BlockedExtensions: req.BlockedExtensions,
+		AllowedTypes:      req.AllowedTypes,
+		BlockedTypes:      req.BlockedTypes,
+		MaxFileBytes:      req.MaxFileBytes,
+	})
+	if err != nil {
+		h.recordSpaceAudit(r, audit.Event{
+			Action: "space.upload-policy.update",
+			Result: audit.ResultFailure,
+			Target: fmt.Sprintf("space:%d", spaceID),
+		}, spaceID)
+		if errors.Is(err, space.ErrInvalidUploadPolicy) {
+			return &web.Error{Code: http.StatusBadRequest, Message: "Invalid upload policy", Err: err}
+		}
+		return &web.Error{Code: http.StatusInternalServerError, Message: "Failed to update upload policy", Err: err}
+	}
+
+	metadata := map[string]any{
+		"allowedExtensions": saved.AllowedExtensions,
+		"blockedExtensions": saved.BlockedExtensions,
+		"allowedTypes":      saved.AllowedTypes,
+		"blockedTypes":      saved.BlockedTypes,
+	}
+	if saved.MaxFileBytes != nil {
+		metadata["maxFileBytes"] = *saved.MaxFileBytes
+	}
+	h.recordSpaceAudit(r, audit.Event{
+		Action:   "space.upload-policy.update",
+		Result:   audit.ResultSuccess,
+		Target:   fmt.Sprintf("space:%d", spaceID),
+		Metadata: metadata,
+	}, spaceID)
+	return writeJSON(w, http.StatusOK, saved)
+}
+
+func spaceLookupWebError(err error) *web.Error {
+	if errors.Is(err, space.ErrSpaceNotFound) {
+		return &web.Error{Code: http.StatusNotFound, Message: "Space not found", Err: err}
+	}
+	return &web.Error{Code: http.StatusInternalServerError, Message: "Failed to get space", Err: err}
+}
+
+// uploadPolicyWebError는 정책 위반을 413(크기) 또는 415(확장자·형식)로, 나머지는 500으로 바꿉니다.
+func uploadPolicyWebError(err error) *web.Error {
+	var policyErr *space.UploadPolicyError
+	if !errors.As(err, &policyErr) {
+		return &web.Error{Code: http.StatusInternalServerError, Message: "Failed to evaluate upload policy", Err: err}
+	}
+	code := http.StatusUnsupportedMediaType
+	if policyErr.Violation == space.UploadPolicyFileTooLarge {
+		code = http.StatusRequestEntityTooLarge
+	}
+	return &web.Error{Code: code, Message: policyErr.Reason(), Err: err}
+}
+
+// uploadPolicyFailure는 항목별 실패 목록에 남길 사유와 code입니다.
+func uploadPolicyFailure(err error) (string, string) {
+	var policyErr *space.UploadPolicyError
+	if errors.As(err, &policyErr) {
+		return policyErr.Reason(), string(policyErr.Violation)
+	}
+	return safeFilesystemReason("Failed to evaluate upload policy", err), ""
+}
+
+// checkUploadName은 Space 업로드 정책으로 새 파일 이름(확장자)을 확인합니다.
+func (h *Handler) checkUploadName(ctx context.Context, spaceID int64, name string) *web.Error {
+	if err := h.uploadPolicies.CheckName(ctx, spaceID, name); err != nil {
+		return uploadPolicyWebError(err)
+	}
+	return nil
+}
+
+// uploadPolicyFor는 Space 업로드 정책을 불러옵니다. 한 요청에서 여러 파일을 확인할 때 씁니다.
+func (h *Handler) uploadPolicyFor(ctx context.Context, spaceID int64) (*space.UploadPolicy, error) {
+	return h.uploadPolicies.GetUploadPolicy(ctx, spaceID)
+}
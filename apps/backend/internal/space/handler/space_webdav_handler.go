@@ -0,0 +1,60 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"taeu.kr/cohesion/internal/audit"
+	"taeu.kr/cohesion/internal/platform/web"
+)
+
+// handleSpaceWebDAV는 PATCH /api/spaces/{id}/webdav 요청을 처리합니다.
+// body: { archiveBrowsing: bool }
+func (h *Handler) handleSpaceWebDAV(w http.ResponseWriter, r *http.Request, spaceID int64) *web.Error {
+	if r.Method != http.MethodPatch {
+		return &web.Error{Code: http.StatusMethodNotAllowed, Message: "Method not allowed"}
+	}
+
+	var req struct {
+		ArchiveBrowsing *bool `json:"archiveBrowsing"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return &web.Error{Code: http.StatusBadRequest, Message: "Invalid request body", Err: err}
+	}
+	if req.ArchiveBrowsing == nil {
+		return &web.Error{Code: http.StatusBadRequest, Message: "archiveBrowsing is required"}
+	}
+
+	updated, err := h.spaceService.UpdateWebDAVArchiveBrowsing(r.Context(), spaceID, *req.ArchiveBrowsing)
+	if err != nil {
+		h.recordSpaceAudit(r, audit.Event{
+			Action:   "space.webdav.update",
+			Result:   audit.ResultFailure,
+			Target:   fmt.Sprintf("space:%d", spaceID),
+			Metadata: map[string]any{"archiveBrowsing": *req.ArchiveBrowsing},
+		}, spaceID)
+
+		statusCode := http.StatusInternalServerError
+		message := "Failed to update Space WebDAV settings"
+		switch {
+		case strings.Contains(err.Error(), "not found"):
+			statusCode = http.StatusNotFound
+			message = "Space not found"
+		case strings.Contains(err.Error(), "invalid"):
+			statusCode = http.StatusBadRequest
+			message = "Invalid Space request"
+		}
+		return &web.Error{Code: statusCode, Message: message, Err: err}
+	}
+
+	h.recordSpaceAudit(r, audit.Event{
+		Action:   "space.webdav.update",
+		Result:   audit.ResultSuccess,
+		Target:   fmt.Sprintf("space:%d", spaceID),
+		Metadata: map[string]any{"archiveBrowsing": updated.WebDAVArchiveBrowsing},
+	}, spaceID)
+
+	return writeJSON(w, http.StatusOK, newSpaceResponse(updated))
+}
@@ -33,10 +33,13 @@ type uploadPlan struct {
 	resultFileName   string
 	conflictPolicy   uploadConflictPolicy
 	existingBytes    int64
+	replacesFile     bool
 	estimatedSize    int64
 	quotaWindow      uploadQuotaWindow
 	skip             bool
 	originalFileName string
+	// policyGuard는 받은 내용의 형식과 크기를 Space 업로드 정책으로 확인합니다. 정책이 없으면 nil입니다.
+	policyGuard *space.UploadWriteGuard
 }
 
 type quotaEnforcingWriter struct {
@@ -63,6 +66,22 @@ func (w *quotaEnforcingWriter) Write(p []byte) (int, error) {
 	return n, errUploadQuotaExceeded
 }
 
+// policyEnforcingWriter는 쓰기 전에 업로드 정책으로 크기와 파일 앞부분의 형식을 확인합니다.
+type policyEnforcingWriter struct {
+	writer  io.Writer
+	guard   *space.UploadWriteGuard
+	written int64
+}
+
+func (w *policyEnforcingWriter) Write(p []byte) (int, error) {
+	if err := w.guard.Check(w.written, p); err != nil {
+		return 0, err
+	}
+	n, err := w.writer.Write(p)
+	w.written += int64(n)
+	return n, err
+}
+
 func createUploadStageFile(destPath string) (*os.File, string, error) {
 	if strings.TrimSpace(destPath) == "" {
 		file, err := os.CreateTemp("", "cohesion-upload-*")
@@ -201,6 +220,7 @@ func (h *Handler) buildUploadPlan(
 	destPath := filepath.Join(absTarget, fileName)
 	resultFileName := fileName
 	existingBytes := int64(0)
+	replacesFile := false
 
 	if existingInfo, err := os.Stat(destPath); err == nil {
 		if !hasConflictPolicy {
@@ -211,7 +231,11 @@ func (h *Handler) buildUploadPlan(
 			if existingInfo.IsDir() {
 				return nil, &web.Error{Code: http.StatusConflict, Message: "Directory already exists"}
 			}
+			if err := h.checkRetention(ctx, spaceID, spaceRoot, destPath, "overwrite"); err != nil {
+				return nil, retentionWebError(err)
+			}
 			existingBytes = existingInfo.Size()
+			replacesFile = true
 		case uploadConflictPolicyRename:
 			renamedPath, renamedFileName, resolveErr := resolveUploadRenamePath(destPath)
 			if resolveErr != nil {
@@ -234,10 +258,28 @@ func (h *Handler) buildUploadPlan(
 		return nil, storageAccessWebError(err, "", "Failed to inspect destination path")
 	}
 
+	policy, err := h.uploadPolicyFor(ctx, spaceID)
+	if err != nil {
+		return nil, uploadPolicyWebError(err)
+	}
+	if err := policy.CheckName(fileName); err != nil {
+		return nil, uploadPolicyWebError(err)
+	}
+	if estimatedSize >= 0 {
+		if err := policy.CheckSize(fileName, estimatedSize); err != nil {
+			return nil, uploadPolicyWebError(err)
+		}
+	}
+
 	quotaWindow, webErr := h.calculateUploadQuotaWindow(ctx, spaceID, existingBytes, estimatedSize)
 	if webErr != nil {
 		return nil, webErr
 	}
+	if !replacesFile {
+		if webErr := h.ensureSpaceFileQuota(ctx, spaceID, 1); webErr != nil {
+			return nil, webErr
+		}
+	}
 
 	return &uploadPlan{
 		targetRelPath:    targetRelPath,
@@ -245,9 +287,11 @@ func (h *Handler) buildUploadPlan(
 		resultFileName:   resultFileName,
 		conflictPolicy:   conflictPolicy,
 		existingBytes:    existingBytes,
+		replacesFile:     replacesFile,
 		estimatedSize:    estimatedSize,
 		quotaWindow:      quotaWindow,
 		originalFileName: fileName,
+		policyGuard:      policy.NewWriteGuard(fileName),
 	}, nil
 }
 
@@ -289,7 +333,7 @@ func readMultipartFieldValue(part *multipart.Part) (string, error) {
 	return string(value), nil
 }
 
-func serveAttachmentContent(w http.ResponseWriter, r *http.Request, file *os.File, fileInfo os.FileInfo, fileName string, contentType string) {
+func serveAttachmentContent(w http.ResponseWriter, r *http.Request, content io.ReadSeeker, fileInfo os.FileInfo, fileName string, contentType string) {
 	downloadName := strings.TrimSpace(fileName)
 	if downloadName == "" {
 		downloadName = fileInfo.Name()
@@ -306,5 +350,5 @@ func serveAttachmentContent(w http.ResponseWriter, r *http.Request, file *os.Fil
 	safeFileName := strings.ReplaceAll(downloadName, `"`, "")
 	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, safeFileName))
 	w.Header().Set("Content-Type", resolvedContentType)
-	http.ServeContent(w, r, downloadName, fileInfo.ModTime(), file)
+	http.ServeContent(w, r, downloadName, fileInfo.ModTime(), content)
 }
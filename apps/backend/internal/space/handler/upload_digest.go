@@ -0,0 +1,164 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"hash"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	"taeu.kr/cohesion/internal/space/checksum"
+)
+
+const uploadChecksumMismatchCode = "upload.checksum_mismatch"
+
+var errInvalidUploadDigest = errors.New("invalid upload digest")
+
+// expectedUploadDigest는 클라이언트가 업로드 파일에 대해 보낸 기대 해시입니다.
+type expectedUploadDigest struct {
+	Algorithm checksum.Algorithm
+	Digest    []byte
+}
+
+type uploadChecksumMismatchResponse struct {
+	Error     string             `json:"error"`
+	Code      string             `json:"code"`
+	Algorithm checksum.Algorithm `json:"algorithm"`
+	Expected  string             `json:"expected"`
+	Actual    string             `json:"actual"`
+}
+
+// digestHeaderAlgorithms는 RFC 3230 Digest 헤더의 알고리즘 이름입니다.
+var digestHeaderAlgorithms = map[string]checksum.Algorithm{
+	"sha-256": checksum.SHA256,
+	"sha":     checksum.SHA1,
+	"md5":     checksum.MD5,
+}
+
+// parseUploadDigestHeaders는 Digest(RFC 3230)와 Content-MD5 헤더에서 기대 해시를 읽습니다.
+// Digest의 모르는 알고리즘은 RFC대로 무시하고, 값이 잘못되면 errInvalidUploadDigest입니다.
+func parseUploadDigestHeaders(header http.Header) ([]expectedUploadDigest, error) {
+	digests := make([]expectedUploadDigest, 0)
+	for _, headerValue := range header.Values("Digest") {
+		for _, item := range strings.Split(headerValue, ",") {
+			name, value, ok := strings.Cut(strings.TrimSpace(item), "=")
+			if !ok {
+				return nil, errInvalidUploadDigest
+			}
+			algo, known := digestHeaderAlgorithms[strings.ToLower(strings.TrimSpace(name))]
+			if !known {
+				continue
+			}
+			digest, err := decodeBase64Digest(value, algo)
+			if err != nil {
+				return nil, err
+			}
+			digests = append(digests, expectedUploadDigest{Algorithm: algo, Digest: digest})
+		}
+	}
+	if value := strings.TrimSpace(header.Get("Content-MD5")); value != "" {
+		digest, err := decodeBase64Digest(value, checksum.MD5)
+		if err != nil {
+			return nil, err
+		}
+		digests = append(digests, expectedUploadDigest{Algorithm: checksum.MD5, Digest: digest})
+	}
+	return digests, nil
+}
+
+// parseUploadSHA256Field는 multipart sha256 필드(hex)를 읽습니다.
+func parseUploadSHA256Field(value string) (expectedUploadDigest, error) {
+	digest, err := hex.DecodeString(strings.TrimSpace(value))
+	if err != nil || len(digest) != checksum.SHA256.NewHash().Size() {
+		return expectedUploadDigest{}, errInvalidUploadDigest
+	}
+	return expectedUploadDigest{Algorithm: checksum.SHA256, Digest: digest}, nil
+}
+
+func decodeBase64Digest(value string, algo checksum.Algorithm) ([]byte, error) {
+	digest, err := base64.StdEncoding.DecodeString(strings.TrimSpace(value))
+	if err != nil || len(digest) != algo.NewHash().Size() {
+		return nil, errInvalidUploadDigest
+	}
+	return digest, nil
+}
+
+// uploadHasher는 업로드를 스테이징하면서 필요한 해시를 함께 계산합니다.
+// sha256은 multipart 필드가 파일 뒤에 올 수 있고 검증된 해시로 저장하므로 항상 계산합니다.
+type uploadHasher struct {
+	hashes map[checksum.Algorithm]hash.Hash
+	writer io.Writer
+}
+
+func newUploadHasher(expected []expectedUploadDigest) *uploadHasher {
+	hashes := map[checksum.Algorithm]hash.Hash{checksum.SHA256: checksum.SHA256.NewHash()}
+	for _, digest := range expected {
+		if _, exists := hashes[digest.Algorithm]; !exists {
+			hashes[digest.Algorithm] = digest.Algorithm.NewHash()
+		}
+	}
+	writers := make([]io.Writer, 0, len(hashes))
+	for _, hasher := range hashes {
+		writers = append(writers, hasher)
+	}
+	return &uploadHasher{hashes: hashes, writer: io.MultiWriter(writers...)}
+}
+
+func (u *uploadHasher) Write(p []byte) (int, error) {
+	return u.writer.Write(p)
+}
+
+// verify는 기대 해시와 계산한 해시를 비교해 처음 어긋난 항목을 반환합니다.
+func (u *uploadHasher) verify(expected []expectedUploadDigest) (*uploadChecksumMismatchResponse, bool) {
+	for _, digest := range expected {
+		actual := u.hashes[digest.Algorithm].Sum(nil)
+		if !bytes.Equal(actual, digest.Digest) {
+			return &uploadChecksumMismatchResponse{
+				Error:     "Upload checksum mismatch",
+				Code:      uploadChecksumMismatchCode,
+				Algorithm: digest.Algorithm,
+				Expected:  hex.EncodeToString(digest.Digest),
+				Actual:    hex.EncodeToString(actual),
+			}, false
+		}
+	}
+	return nil, true
+}
+
+func (u *uploadHasher) hexDigest(algo checksum.Algorithm) string {
+	return hex.EncodeToString(u.hashes[algo].Sum(nil))
+}
+
+// recordUploadChecksums는 업로드하면서 계산한 해시를 해시 캐시에 넣어 다운로드와 체크섬 API가 다시 계산하지 않게 합니다.
+func (h *Handler) recordUploadChecksums(ctx context.Context, absPath string, hasher *uploadHasher) {
+	for algo := range hasher.hashes {
+		if err := h.checksums.Record(ctx, absPath, algo, hasher.hexDigest(algo)); err != nil {
+			log.Warn().Err(err).Str("algorithm", string(algo)).Msg("failed to record upload checksum")
+		}
+	}
+}
+
+// setDigestHeader는 캐시된 해시가 있으면 RFC 3230 Digest 헤더로 알려 줍니다. 다운로드 때 새로 계산하지는 않습니다.
+func (h *Handler) setDigestHeader(w http.ResponseWriter, r *http.Request, absPath string, info os.FileInfo) {
+	values := make([]string, 0, len(digestHeaderAlgorithms))
+	for _, name := range []string{"sha-256", "sha", "md5"} {
+		entry, err := h.checksums.Lookup(r.Context(), absPath, digestHeaderAlgorithms[name], info)
+		if err != nil || entry == nil {
+			continue
+		}
+		digest, err := hex.DecodeString(entry.Digest)
+		if err != nil {
+			continue
+		}
+		values = append(values, name+"="+base64.StdEncoding.EncodeToString(digest))
+	}
+	if len(values) > 0 {
+		w.Header().Set("Digest", strings.Join(values, ","))
+	}
+}
@@ -0,0 +1,228 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	"taeu.kr/cohesion/internal/account"
+	"taeu.kr/cohesion/internal/audit"
+	"taeu.kr/cohesion/internal/auth"
+	"taeu.kr/cohesion/internal/platform/logging"
+	"taeu.kr/cohesion/internal/platform/web"
+	"taeu.kr/cohesion/internal/space"
+)
+
+const userStorageQuotaPathPrefix = "/api/storage/users/"
+
+// UserDirectoryService는 관리자 사용량 보고서에 사용자 목록을 채울 때 쓰는 계정 서비스 기능입니다.
+type UserDirectoryService interface {
+	ListUsers(ctx context.Context) ([]*account.User, error)
+	GetUserByUsername(ctx context.Context, username string) (*account.User, error)
+}
+
+type userSpaceQuotaRequest struct {
+	SpaceID    int64 `json:"spaceId"`
+	QuotaBytes int64 `json:"quotaBytes"`
+}
+
+// SetUserQuotaService는 파일 소유자 기록을 프로토콜 서버와 함께 쓰도록 공용 UserQuotaService로 교체합니다.
+func (h *Handler) SetUserQuotaService(userQuotaService *space.UserQuotaService) {
+	if userQuotaService != nil {
+		h.userQuotaService = userQuotaService
+	}
+}
+
+// handleMyStorage: GET /api/storage/me
+// 요청자가 소유한 파일의 Space별 사용량과 사용자 할당량을 반환합니다.
+func (h *Handler) handleMyStorage(w http.ResponseWriter, r *http.Request) *web.Error {
+	if r.Method != http.MethodGet {
+		return &web.Error{Code: http.StatusMethodNotAllowed, Message: "Method not allowed"}
+	}
+	username, webErr := claimsUsernameFromRequest(r)
+	if webErr != nil {
+		return webErr
+	}
+
+	usage, err := h.userQuotaService.GetUserUsage(r.Context(), username)
+	if err != nil {
+		return &web.Error{Code: http.StatusInternalServerError, Message: "Failed to get user storage usage", Err: err}
+	}
+	return writeJSON(w, http.StatusOK, usage)
+}
+
+// handleUserStorageReport: GET /api/storage/users
+// 모든 사용자의 사용량과 할당량을 반환합니다. 파일을 소유하지 않은 사용자도 0으로 포함합니다.
+func (h *Handler) handleUserStorageReport(w http.ResponseWriter, r *http.Request) *web.Error {
+	if r.Method != http.MethodGet {
+		return &web.Error{Code: http.StatusMethodNotAllowed, Message: "Method not allowed"}
+	}
+
+	usernames := make([]string, 0)
+	if directory, ok := h.accountService.(UserDirectoryService); ok {
+		users, err := directory.ListUsers(r.Context())
+		if err != nil {
+			return &web.Error{Code: http.StatusInternalServerError, Message: "Failed to list users", Err: err}
+		}
+		for _, user := range users {
+			usernames = append(usernames, user.Username)
+		}
+	}
+
+	report, err := h.userQuotaService.ListUserUsage(r.Context(), usernames)
+	if err != nil {
+		return &web.Error{Code: http.StatusInternalServerError, Message: "Failed to get user storage usage", Err: err}
+	}
+	return writeJSON(w, http.StatusOK, report)
+}
+
+// handleUserStorageQuotas: PUT /api/storage/users/{username}/quotas
+// body: { quotaBytes?: int64, spaces: [{ spaceId, quotaBytes }] }
+// 사용자의 전체 할당량(quotaBytes)과 Space별 할당량을 통째로 바꿉니다. 생략한 항목은 제한 없음입니다.
+func (h *Handler) handleUserStorageQuotas(w http.ResponseWriter, r *http.Request) *web.Error {
+	rest := strings.TrimPrefix(r.URL.Path, userStorageQuotaPathPrefix)
+	username, suffix, found := strings.Cut(rest, "/")
+	if !found || suffix != "quotas" || strings.TrimSpace(username) == "" {
+		return &web.Error{Code: http.StatusNotFound, Message: "Not found"}
+	}
+	if r.Method != http.MethodPut {
+		return &web.Error{Code: http.StatusMethodNotAllowed, Message: "Method not allowed"}
+	}
+
+	var req struct {
+		QuotaBytes *int64                  `json:"quotaBytes"`
+		Spaces     []userSpaceQuotaRequest `json:"spaces"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return &web.Error{Code: http.StatusBadRequest, Message: "Invalid request body", Err: err}
+	}
+
+	if directory, ok := h.accountService.(UserDirectoryService); ok {
+		if _, err := directory.GetUserByUsername(r.Context(), username); err != nil {
+			return &web.Error{Code: http.StatusNotFound, Message: "User not found", Err: err}
+		}
+	}
+
+	quotas := make([]*space.UserQuota, 0, len(req.Spaces)+1)
+	if req.QuotaBytes != nil {
+		quotas = append(quotas, &space.UserQuota{SpaceID: space.GlobalUserQuotaSpaceID, QuotaBytes: *req.QuotaBytes})
+	}
+	for _, item := range req.Spaces {
+		if item.SpaceID <= 0 {
+			return &web.Error{Code: http.StatusBadRequest, Message: "spaceId must be greater than 0"}
+		}
+		if _, err := h.spaceService.GetSpaceByID(r.Context(), item.SpaceID); err != nil {
+			return &web.Error{Code: http.StatusNotFound, Message: "Space not found", Err: err}
+		}
+		quotas = append(quotas, &space.UserQuota{SpaceID: item.SpaceID, QuotaBytes: item.QuotaBytes})
+	}
+
+	if err := h.userQuotaService.ReplaceUserQuotas(r.Context(), username, quotas); err != nil {
+		h.recordUserStorageAudit(r, audit.Event{
+			Action: "account.storage-quota.update",
+			Result: audit.ResultFailure,
+			Target: "user:" + username,
+		})
+		if errors.Is(err, space.ErrInvalidUserQuota) {
+			return &web.Error{Code: http.StatusBadRequest, Message: "Invalid quota request", Err: err}
+		}
+		return &web.Error{Code: http.StatusInternalServerError, Message: "Failed to update user quotas", Err: err}
+	}
+
+	metadata := map[string]any{"spaceCount": len(req.Spaces)}
+	if req.QuotaBytes != nil {
+		metadata["quotaBytes"] = *req.QuotaBytes
+	}
+	h.recordUserStorageAudit(r, audit.Event{
+		Action:   "account.storage-quota.update",
+		Result:   audit.ResultSuccess,
+		Target:   "user:" + username,
+		Metadata: metadata,
+	})
+
+	usage, err := h.userQuotaService.GetUserUsage(r.Context(), username)
+	if err != nil {
+		return &web.Error{Code: http.StatusInternalServerError, Message: "Failed to get user storage usage", Err: err}
+	}
+	return writeJSON(w, http.StatusOK, usage)
+}
+
+func (h *Handler) recordUserStorageAudit(r *http.Request, event audit.Event) {
+	if h.auditRecorder == nil {
+		return
+	}
+	if claims, ok := auth.ClaimsFromContext(r.Context()); ok {
+		event.Actor = claims.Username
+	}
+	event.RequestID = strings.TrimSpace(r.Header.Get("X-Request-Id"))
+	h.auditRecorder.RecordBestEffort(event)
+}
+
+// ensureUserQuotaForWrite는 username의 전체·Space별 사용자 할당량을 확인합니다.
+func (h *Handler) ensureUserQuotaForWrite(ctx context.Context, username string, spaceID int64, deltaBytes int64) *web.Error {
+	if h.userQuotaService == nil {
+		return nil
+	}
+	if err := h.userQuotaService.EnsureCanWrite(ctx, username, spaceID, deltaBytes); err != nil {
+		var quotaErr *space.UserQuotaExceededError
+		if errors.As(err, &quotaErr) {
+			return &web.Error{Code: http.StatusInsufficientStorage, Message: "User quota exceeded", Err: err}
+		}
+		return &web.Error{Code: http.StatusInternalServerError, Message: "Failed to evaluate user quota", Err: err}
+	}
+	return nil
+}
+
+// recordFileOwner는 쓰기를 마친 absPath를 username 소유로 기록합니다. 실패해도 쓰기는 되돌리지 않습니다.
+func (h *Handler) recordFileOwner(ctx context.Context, username string, spaceID int64, spaceRoot, absPath string) {
+	if h.userQuotaService == nil {
+		return
+	}
+	if err := h.userQuotaService.RecordOwner(context.WithoutCancel(ctx), username, spaceID, spaceRoot, absPath); err != nil {
+		logging.Event(log.Warn(), logging.ComponentStorage, "warn.space.file_owner_record_failed").
+			Err(err).
+			Int64("space_id", spaceID).
+			Str("path", absPath).
+			Msg("failed to record file owner")
+	}
+}
+
+// moveFileOwners는 이동한 항목의 소유자 기록을 따라 옮깁니다.
+func (h *Handler) moveFileOwners(ctx context.Context, fromSpaceID int64, fromRoot, fromAbs string, toSpaceID int64, toRoot, toAbs string) {
+	if h.userQuotaService == nil {
+		return
+	}
+	if err := h.userQuotaService.MoveOwners(context.WithoutCancel(ctx), fromSpaceID, fromRoot, fromAbs, toSpaceID, toRoot, toAbs); err != nil {
+		logging.Event(log.Warn(), logging.ComponentStorage, "warn.space.file_owner_move_failed").
+			Err(err).
+			Int64("space_id", fromSpaceID).
+			Str("path", fromAbs).
+			Msg("failed to move file owners")
+	}
+}
+
+// deleteFileOwners는 영구 삭제한 항목의 소유자 기록을 지웁니다.
+func (h *Handler) deleteFileOwners(ctx context.Context, spaceID int64, spaceRoot, absPath string) {
+	if h.userQuotaService == nil {
+		return
+	}
+	if err := h.userQuotaService.DeleteOwners(context.WithoutCancel(ctx), spaceID, spaceRoot, absPath); err != nil {
+		logging.Event(log.Warn(), logging.ComponentStorage, "warn.space.file_owner_delete_failed").
+			Err(err).
+			Int64("space_id", spaceID).
+			Str("path", absPath).
+			Msg("failed to delete file owners")
+	}
+}
+
+func userQuotaFailureReason(err error) string {
+	var quotaErr *space.UserQuotaExceededError
+	if errors.As(err, &quotaErr) {
+		return fmt.Sprintf("User quota exceeded (used=%d, quota=%d)", quotaErr.UsedBytes, quotaErr.QuotaBytes)
+	}
+	return "User quota exceeded"
+}
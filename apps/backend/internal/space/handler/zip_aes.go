@@ -0,0 +1,235 @@
+package handler
+
+import (
+	"archive/zip"
+	"compress/flate"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/binary"
+	"hash"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"taeu.kr/cohesion/internal/platform/web"
+)
+
+// WinZip AES(AE-2) 규격 상수입니다. 7-Zip, WinZip, macOS 아카이브 유틸리티 등에서 열 수 있습니다.
+const (
+	zipMethodWinZipAES       = 99
+	zipAESExtraID            = 0x9901
+	zipAESVendorVersionAE2   = 2
+	zipAESStrength256        = 3
+	zipAES256KeySize         = 32
+	zipAES256SaltSize        = 16
+	zipAESVerifierSize       = 2
+	zipAESAuthCodeSize       = 10
+	zipAESKeyIterations      = 1000
+	zipFlagEncrypted         = 0x1
+	zipVersionAES            = 51
+	maxArchivePasswordLength = 256
+)
+
+// validateArchivePassword는 다운로드 요청의 암호를 확인합니다. 빈 값은 암호화하지 않는다는 뜻입니다.
+func validateArchivePassword(password string, format archiveFormat) *web.Error {
+	if password == "" {
+		return nil
+	}
+	if format != archiveFormatZip {
+		return &web.Error{Code: http.StatusBadRequest, Message: "Password protection requires zip format"}
+	}
+	if len(password) > maxArchivePasswordLength {
+		return &web.Error{Code: http.StatusBadRequest, Message: "Password is too long"}
+	}
+	return nil
+}
+
+// writeZipEntry는 password가 있으면 AES-256으로 암호화한 항목을, 없으면 일반 항목을 씁니다.
+func writeZipEntry(ctx context.Context, zipWriter *zip.Writer, entry archiveZipEntry, password string) (int64, error) {
+	if password == "" {
+		return writeArchiveZipEntry(ctx, zipWriter, entry)
+	}
+	return writeEncryptedZipEntry(ctx, zipWriter, entry, password)
+}
+
+// writeEncryptedZipEntry는 항목을 WinZip AES-256(AE-2) 형식으로 씁니다.
+// 압축 후 크기를 헤더에 먼저 적어야 하므로 항목마다 압축 결과를 임시 파일에 둔 뒤 암호화하며 복사합니다.
+// 폴더 항목은 내용이 없으므로 암호화하지 않습니다.
+func writeEncryptedZipEntry(ctx context.Context, zipWriter *zip.Writer, entry archiveZipEntry, password string) (int64, error) {
+	info, err := os.Stat(entry.AbsPath)
+	if err != nil {
+		return 0, err
+	}
+	header, err := newArchiveZipHeader(info, entry.ZipPath)
+	if err != nil {
+		return 0, err
+	}
+	if info.IsDir() {
+		_, err := zipWriter.CreateHeader(header)
+		return 0, err
+	}
+
+	staged, err := os.CreateTemp("", "cohesion-zip-aes-*")
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		staged.Close()           //nolint:errcheck
+		os.Remove(staged.Name()) //nolint:errcheck
+	}()
+
+	writtenBytes, err := deflateFileTo(ctx, staged, entry.AbsPath, info.Size())
+	if err != nil {
+		return writtenBytes, err
+	}
+	compressedSize, err := staged.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return writtenBytes, err
+	}
+	if _, err := staged.Seek(0, io.SeekStart); err != nil {
+		return writtenBytes, err
+	}
+
+	salt := make([]byte, zipAES256SaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return writtenBytes, err
+	}
+	keys, err := pbkdf2.Key(sha1.New, password, salt, zipAESKeyIterations, 2*zipAES256KeySize+zipAESVerifierSize)
+	if err != nil {
+		return writtenBytes, err
+	}
+	block, err := aes.NewCipher(keys[:zipAES256KeySize])
+	if err != nil {
+		return writtenBytes, err
+	}
+	mac := hmac.New(sha1.New, keys[zipAES256KeySize:2*zipAES256KeySize])
+
+	// AE-2는 CRC 대신 HMAC으로 무결성을 확인하므로 CRC를 0으로 둡니다.
+	header.Method = zipMethodWinZipAES
+	header.Flags |= zipFlagEncrypted
+	header.CRC32 = 0
+	header.CreatorVersion = header.CreatorVersion&0xff00 | zipVersionAES
+	header.ReaderVersion = zipVersionAES
+	header.UncompressedSize64 = uint64(writtenBytes)
+	header.CompressedSize64 = uint64(zipAES256SaltSize + zipAESVerifierSize + compressedSize + zipAESAuthCodeSize)
+	header.ModifiedDate, header.ModifiedTime = zipMSDOSTime(info.ModTime())
+	header.Extra = append(header.Extra, zipAESExtraField(zip.Deflate)...)
+
+	writer, err := zipWriter.CreateRaw(header)
+	if err != nil {
+		return writtenBytes, err
+	}
+	if _, err := writer.Write(salt); err != nil {
+		return writtenBytes, err
+	}
+	if _, err := writer.Write(keys[2*zipAES256KeySize:]); err != nil {
+		return writtenBytes, err
+	}
+
+	encrypter := &zipAESWriter{dst: writer, stream: newZipAESCounter(block), mac: mac}
+	if _, err := copyWithContext(ctx, encrypter, staged); err != nil {
+		return writtenBytes, err
+	}
+	if _, err := writer.Write(mac.Sum(nil)[:zipAESAuthCodeSize]); err != nil {
+		return writtenBytes, err
+	}
+	return writtenBytes, nil
+}
+
+// deflateFileTo는 원본을 size만큼 읽어 deflate로 압축해 dst에 쓰고 읽은 바이트 수를 반환합니다.
+func deflateFileTo(ctx context.Context, dst io.Writer, absPath string, size int64) (int64, error) {
+	file, err := os.Open(absPath)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	compressor, err := flate.NewWriter(dst, flate.DefaultCompression)
+	if err != nil {
+		return 0, err
+	}
+	writtenBytes, err := copyWithContext(ctx, compressor, io.LimitReader(file, size))
+	if err != nil {
+		return writtenBytes, err
+	}
+	return writtenBytes, compressor.Close()
+}
+
+func zipAESExtraField(actualMethod uint16) []byte {
+	extra := make([]byte, 11)
+	binary.LittleEndian.PutUint16(extra[0:], zipAESExtraID)
+	binary.LittleEndian.PutUint16(extra[2:], 7)
+	binary.LittleEndian.PutUint16(extra[4:], zipAESVendorVersionAE2)
+	copy(extra[6:], "AE")
+	extra[8] = zipAESStrength256
+	binary.LittleEndian.PutUint16(extra[9:], actualMethod)
+	return extra
+}
+
+// zipMSDOSTime은 CreateRaw가 채워 주지 않는 MS-DOS 수정 시각 필드를 계산합니다.
+func zipMSDOSTime(modTime time.Time) (uint16, uint16) {
+	if modTime.Year() < 1980 {
+		modTime = time.Date(1980, 1, 1, 0, 0, 0, 0, modTime.Location())
+	}
+	date := uint16(modTime.Day() + int(modTime.Month())<<5 + (modTime.Year()-1980)<<9)
+	clock := uint16(modTime.Second()/2 + modTime.Minute()<<5 + modTime.Hour()<<11)
+	return date, clock
+}
+
+// zipAESCounter는 WinZip AES의 CTR 모드입니다.
+// 표준 CTR과 달리 카운터를 리틀 엔디언으로 1부터 증가시킵니다.
+type zipAESCounter struct {
+	block     cipher.Block
+	counter   [aes.BlockSize]byte
+	keystream [aes.BlockSize]byte
+	used      int
+}
+
+func newZipAESCounter(block cipher.Block) *zipAESCounter {
+	return &zipAESCounter{block: block, used: aes.BlockSize}
+}
+
+func (c *zipAESCounter) XORKeyStream(dst, src []byte) {
+	for i := range src {
+		if c.used == aes.BlockSize {
+			for j := range c.counter {
+				c.counter[j]++
+				if c.counter[j] != 0 {
+					break
+				}
+			}
+			c.block.Encrypt(c.keystream[:], c.counter[:])
+			c.used = 0
+		}
+		dst[i] = src[i] ^ c.keystream[c.used]
+		c.used++
+	}
+}
+
+// zipAESWriter는 평문을 암호화해 쓰고, 암호문을 인증 코드 계산에 넣습니다.
+type zipAESWriter struct {
+	dst    io.Writer
+	stream *zipAESCounter
+	mac    hash.Hash
+	buffer []byte
+}
+
+func (w *zipAESWriter) Write(p []byte) (int, error) {
+	if cap(w.buffer) < len(p) {
+		w.buffer = make([]byte, len(p))
+	}
+	encrypted := w.buffer[:len(p)]
+	w.stream.XORKeyStream(encrypted, p)
+	w.mac.Write(encrypted) //nolint:errcheck
+	written, err := w.dst.Write(encrypted)
+	if err == nil && written != len(p) {
+		err = io.ErrShortWrite
+	}
+	return written, err
+}
@@ -0,0 +1,220 @@
+package handler
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/flate"
+	"crypto/aes"
+	"crypto/hmac"
+	"crypto/pbkdf2"
+	"crypto/sha1"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"taeu.kr/cohesion/internal/job"
+)
+
+// decryptWinZipAESEntry는 규격대로 항목을 검증/복호화합니다. 암호가 틀리면 ok=false입니다.
+func decryptWinZipAESEntry(t *testing.T, file *zip.File, password string) (string, bool) {
+	t.Helper()
+
+	if file.Method != zipMethodWinZipAES || file.Flags&zipFlagEncrypted == 0 {
+		t.Fatalf("expected %s to be AES encrypted, method=%d flags=%#x", file.Name, file.Method, file.Flags)
+	}
+	if !bytes.Contains(file.Extra, []byte{0x01, 0x99, 0x07, 0x00, 0x02, 0x00, 'A', 'E', zipAESStrength256}) {
+		t.Fatalf("missing AES extra field on %s: %x", file.Name, file.Extra)
+	}
+	raw, err := file.OpenRaw()
+	if err != nil {
+		t.Fatalf("failed to open raw entry: %v", err)
+	}
+	data, err := io.ReadAll(raw)
+	if err != nil {
+		t.Fatalf("failed to read raw entry: %v", err)
+	}
+
+	salt := data[:zipAES256SaltSize]
+	verifier := data[zipAES256SaltSize : zipAES256SaltSize+zipAESVerifierSize]
+	encrypted := data[zipAES256SaltSize+zipAESVerifierSize : len(data)-zipAESAuthCodeSize]
+	authCode := data[len(data)-zipAESAuthCodeSize:]
+
+	keys, err := pbkdf2.Key(sha1.New, password, salt, zipAESKeyIterations, 2*zipAES256KeySize+zipAESVerifierSize)
+	if err != nil {
+		t.Fatalf("failed to derive keys: %v", err)
+	}
+	if !bytes.Equal(keys[2*zipAES256KeySize:], verifier) {
+		return "", false
+	}
+	mac := hmac.New(sha1.New, keys[zipAES256KeySize:2*zipAES256KeySize])
+	mac.Write(encrypted)
+	if !hmac.Equal(mac.Sum(nil)[:zipAESAuthCodeSize], authCode) {
+		t.Fatalf("authentication code mismatch for %s", file.Name)
+	}
+
+	block, err := aes.NewCipher(keys[:zipAES256KeySize])
+	if err != nil {
+		t.Fatalf("failed to create cipher: %v", err)
+	}
+	compressed := make([]byte, len(encrypted))
+	newZipAESCounter(block).XORKeyStream(compressed, encrypted)
+	plain, err := io.ReadAll(flate.NewReader(bytes.NewReader(compressed)))
+	if err != nil {
+		t.Fatalf("failed to inflate %s: %v", file.Name, err)
+	}
+	if uint64(len(plain)) != file.UncompressedSize64 {
+		t.Fatalf("unexpected size for %s: %d", file.Name, len(plain))
+	}
+	return string(plain), true
+}
+
+func TestZipAESCounter_IncrementsLittleEndianFromOne(t *testing.T) {
+	block, err := aes.NewCipher(make([]byte, zipAES256KeySize))
+	if err != nil {
+		t.Fatalf("failed to create cipher: %v", err)
+	}
+	counter := newZipAESCounter(block)
+	counter.XORKeyStream(make([]byte, 2*aes.BlockSize), make([]byte, 2*aes.BlockSize))
+	if value := binary.LittleEndian.Uint64(counter.counter[:8]); value != 2 {
+		t.Fatalf("expected counter to reach 2 after two blocks, got %d", value)
+	}
+}
+
+func TestHandleFileDownloadMultiple_EncryptsZipWithPassword(t *testing.T) {
+	handler, root, _, _, sink := newFileTransferJobTestHandler(t)
+	seedCompressSources(t, root)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/spaces/1/files/download-multiple", strings.NewReader(`{"paths":["docs"],"password":"s3cret-pw"}`))
+	req = withClaims(req, "tester")
+	rec := httptest.NewRecorder()
+	if webErr := handler.handleFileDownloadMultiple(rec, req, 1); webErr != nil {
+		t.Fatalf("unexpected web error: %+v", webErr)
+	}
+	if disposition := rec.Header().Get("Content-Disposition"); !strings.Contains(disposition, `filename="docs.zip"`) {
+		t.Fatalf("unexpected content disposition: %q", disposition)
+	}
+
+	body := rec.Body.Bytes()
+	reader, err := zip.NewReader(bytes.NewReader(body), int64(len(body)))
+	if err != nil {
+		t.Fatalf("failed to open zip: %v", err)
+	}
+	contents := map[string]string{}
+	for _, file := range reader.File {
+		if file.FileInfo().IsDir() {
+			continue
+		}
+		if _, ok := decryptWinZipAESEntry(t, file, "wrong-pw"); ok {
+			t.Fatalf("wrong password must not verify for %s", file.Name)
+		}
+		plain, ok := decryptWinZipAESEntry(t, file, "s3cret-pw")
+		if !ok {
+			t.Fatalf("password verifier mismatch for %s", file.Name)
+		}
+		contents[file.Name] = plain
+	}
+	if contents["docs/readme.txt"] != "readme" || contents["docs/nested/보고서.txt"] != "report" {
+		t.Fatalf("unexpected decrypted contents: %v", contents)
+	}
+
+	event := sink.waitForAction(t, "file.download-multiple")
+	if event.Metadata["encrypted"] != true {
+		t.Fatalf("expected encryption to be audited, got %+v", event.Metadata)
+	}
+	for key, value := range event.Metadata {
+		if strings.Contains(key, "password") || value == "s3cret-pw" {
+			t.Fatalf("password must not be audited: %+v", event.Metadata)
+		}
+	}
+}
+
+func TestHandleFileDownloadMultiple_RejectsPasswordForTar(t *testing.T) {
+	handler, _ := newArchiveStreamTestHandler(t)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/spaces/1/files/download-multiple", strings.NewReader(`{"paths":["docs","notes.txt"],"format":"tar","password":"pw"}`))
+	webErr := handler.handleFileDownloadMultiple(httptest.NewRecorder(), req, 1)
+	if webErr == nil || webErr.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 when password is combined with tar, got %+v", webErr)
+	}
+}
+
+func TestArchiveDownloadJob_EncryptsWithoutPersistingPassword(t *testing.T) {
+	handler, root, _, jobStore, sink := newFileTransferJobTestHandler(t)
+	seedCompressSources(t, root)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/spaces/1/files/archive-downloads", strings.NewReader(`{"paths":["docs","notes.txt"],"password":"s3cret-pw"}`))
+	req = withClaims(req, "tester")
+	rec := httptest.NewRecorder()
+	if webErr := handler.handleArchiveDownloadCreate(rec, req, 1); webErr != nil {
+		t.Fatalf("unexpected web error: %+v", webErr)
+	}
+	var created archiveDownloadStatusResponse
+	if err := json.NewDecoder(rec.Body).Decode(&created); err != nil {
+		t.Fatalf("failed to decode archive job: %v", err)
+	}
+	if !created.Encrypted {
+		t.Fatalf("expected encrypted archive job, got %+v", created)
+	}
+
+	finished := waitForFileTransferJob(t, handler, created.JobID, job.StatusCompleted)
+	stored, err := jobStore.Get(t.Context(), created.JobID)
+	if err != nil {
+		t.Fatalf("failed to load stored job: %v", err)
+	}
+	if strings.Contains(string(stored.Payload), "s3cret-pw") {
+		t.Fatalf("password must not be persisted in job payload: %s", stored.Payload)
+	}
+
+	artifact, err := os.ReadFile(finished.ArtifactPath)
+	if err != nil {
+		t.Fatalf("failed to read artifact: %v", err)
+	}
+	reader, err := zip.NewReader(bytes.NewReader(artifact), int64(len(artifact)))
+	if err != nil {
+		t.Fatalf("failed to open artifact: %v", err)
+	}
+	for _, file := range reader.File {
+		if file.Name != "notes.txt" {
+			continue
+		}
+		if plain, ok := decryptWinZipAESEntry(t, file, "s3cret-pw"); !ok || plain != "notes" {
+			t.Fatalf("unexpected decrypted content %q ok=%v", plain, ok)
+		}
+	}
+
+	event := sink.waitForAction(t, "file.archive-download")
+	if event.Metadata["encrypted"] != true {
+		t.Fatalf("expected encryption to be audited, got %+v", event.Metadata)
+	}
+	handler.archivePasswordMu.Lock()
+	remaining := len(handler.archivePasswords)
+	handler.archivePasswordMu.Unlock()
+	if remaining != 0 {
+		t.Fatalf("expected archive password to be released after the job, %d remain", remaining)
+	}
+}
+
+func TestArchiveDownloadJob_FailsWhenPasswordIsLost(t *testing.T) {
+	handler, root, _, _, _ := newFileTransferJobTestHandler(t)
+	seedCompressSources(t, root)
+
+	spaceID := int64(1)
+	created, err := handler.jobs.Enqueue(t.Context(), job.EnqueueRequest{
+		Type:    ArchiveDownloadJobType,
+		Owner:   "tester",
+		SpaceID: &spaceID,
+		Payload: archiveDownloadPayload{Paths: []string{"notes.txt", "docs"}, FileName: "lost.zip", PasswordRef: "missing"},
+	})
+	if err != nil {
+		t.Fatalf("failed to enqueue job: %v", err)
+	}
+	failed := waitForFileTransferJob(t, handler, created.ID, job.StatusFailed)
+	if !strings.Contains(failed.FailureReason, "password is no longer available") {
+		t.Fatalf("unexpected failure reason: %q", failed.FailureReason)
+	}
+}
@@ -0,0 +1,166 @@
+package malware
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// clamdChunkSize는 INSTREAM으로 보내는 조각 하나의 크기입니다. clamd의 StreamMaxLength와는 별개입니다.
+const clamdChunkSize = 64 << 10
+
+// maxClamdResponseBytes는 clamd 응답 한 줄로 읽을 최대 길이입니다.
+const maxClamdResponseBytes = 4 << 10
+
+// ClamdScanner는 clamd에 INSTREAM 명령으로 파일 내용을 보내 검사합니다.
+// clamd가 파일 경로를 직접 읽지 않으므로 다른 호스트나 컨테이너의 clamd도 쓸 수 있습니다.
+type ClamdScanner struct {
+	network string
+	address string
+	timeout time.Duration
+}
+
+// NewClamdScanner는 address의 clamd에 연결하는 Scanner를 만듭니다.
+// address는 "unix:///run/clamav/clamd.ctl", "tcp://127.0.0.1:3310", "127.0.0.1:3310" 또는 소켓 절대 경로입니다.
+func NewClamdScanner(address string, timeout time.Duration) (*ClamdScanner, error) {
+	network, resolved, err := parseClamdAddress(address)
+	if err != nil {
+		return nil, err
+	}
+	return &ClamdScanner{network: network, address: resolved, timeout: resolveTimeout(timeout)}, nil
+}
+
+func parseClamdAddress(address string) (string, string, error) {
+	address = strings.TrimSpace(address)
+	switch {
+	case address == "":
+		return "", "", fmt.Errorf("clamd address is required")
+	case strings.HasPrefix(address, "unix://"):
+		address = strings.TrimPrefix(address, "unix://")
+	case strings.HasPrefix(address, "unix:"):
+		address = strings.TrimPrefix(address, "unix:")
+	case strings.HasPrefix(address, "tcp://"):
+		return "tcp", strings.TrimPrefix(address, "tcp://"), nil
+	case filepath.IsAbs(address):
+	default:
+		if _, _, err := net.SplitHostPort(address); err != nil {
+			return "", "", fmt.Errorf("invalid clamd address %q: %w", address, err)
+		}
+		return "tcp", address, nil
+	}
+	if address == "" {
+		return "", "", fmt.Errorf("clamd socket path is required")
+	}
+	return "unix", address, nil
+}
+
+func (s *ClamdScanner) Name() string {
+	return "clamd"
+}
+
+func (s *ClamdScanner) Scan(ctx context.Context, path string) (Result, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return Result{}, err
+	}
+	defer file.Close()
+
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, s.network, s.address)
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to connect to clamd: %w", err)
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+	// 취소되면 연결을 끊어 막힌 읽기·쓰기를 풉니다.
+	stop := context.AfterFunc(ctx, func() { _ = conn.Close() })
+	defer stop()
+
+	if err := writeClamdStream(conn, file); err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return Result{}, ctxErr
+		}
+		return Result{}, fmt.Errorf("failed to send file to clamd: %w", err)
+	}
+
+	response, err := readClamdResponse(conn)
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return Result{}, ctxErr
+		}
+		return Result{}, fmt.Errorf("failed to read clamd response: %w", err)
+	}
+	return parseClamdResponse(response)
+}
+
+// writeClamdStream은 zINSTREAM 뒤에 [4바이트 길이][내용] 조각들을 보내고 길이 0으로 끝냅니다.
+func writeClamdStream(w io.Writer, r io.Reader) error {
+	if _, err := io.WriteString(w, "zINSTREAM\x00"); err != nil {
+		return err
+	}
+	buf := make([]byte, clamdChunkSize)
+	var header [4]byte
+	for {
+		n, readErr := r.Read(buf)
+		if n > 0 {
+			binary.BigEndian.PutUint32(header[:], uint32(n))
+			if _, err := w.Write(header[:]); err != nil {
+				return err
+			}
+			if _, err := w.Write(buf[:n]); err != nil {
+				return err
+			}
+		}
+		if errors.Is(readErr, io.EOF) {
+			break
+		}
+		if readErr != nil {
+			return readErr
+		}
+	}
+	binary.BigEndian.PutUint32(header[:], 0)
+	_, err := w.Write(header[:])
+	return err
+}
+
+// readClamdResponse는 NUL(z 명령) 또는 연결 종료까지 응답 한 줄을 읽습니다.
+func readClamdResponse(r io.Reader) (string, error) {
+	response, err := bufio.NewReader(io.LimitReader(r, maxClamdResponseBytes)).ReadString(0)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", err
+	}
+	if response == "" {
+		return "", io.ErrUnexpectedEOF
+	}
+	return strings.TrimRight(response, "\x00\r\n "), nil
+}
+
+// parseClamdResponse는 "stream: OK", "stream: <이름> FOUND", "<사유> ERROR" 응답을 해석합니다.
+func parseClamdResponse(response string) (Result, error) {
+	body := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(response), "stream:"))
+	switch {
+	case body == "OK":
+		return Result{}, nil
+	case strings.HasSuffix(body, " FOUND"):
+		return Result{Infected: true, Signature: truncateSignature(strings.TrimSuffix(body, " FOUND"))}, nil
+	case strings.HasSuffix(body, "ERROR"):
+		return Result{}, fmt.Errorf("clamd error: %s", strings.TrimSpace(strings.TrimSuffix(body, "ERROR")))
+	default:
+		return Result{}, fmt.Errorf("unexpected clamd response: %q", response)
+	}
+}
+
+var _ Scanner = (*ClamdScanner)(nil)
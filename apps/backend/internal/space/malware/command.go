@@ -0,0 +1,128 @@
+package malware
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// PathPlaceholder는 명령 인자에서 검사할 파일 경로로 바뀌는 자리입니다.
+const PathPlaceholder = "{path}"
+
+// maxCommandOutputBytes는 탐지 이름과 오류 사유를 찾으려고 보관하는 명령 출력의 최대 길이입니다.
+const maxCommandOutputBytes = 64 << 10
+
+// CommandScanner는 외부 명령으로 파일을 검사합니다. clamscan과 같은 관례를 따라
+// 종료 코드 0은 깨끗함, 1은 감염, 그 밖의 코드는 검사 실패로 봅니다.
+type CommandScanner struct {
+	command string
+	args    []string
+	timeout time.Duration
+}
+
+// NewCommandScanner는 command를 args와 함께 실행하는 Scanner를 만듭니다.
+// args에 {path}가 있으면 그 자리를, 없으면 마지막 인자로 파일 경로를 넘깁니다. 셸을 거치지 않습니다.
+func NewCommandScanner(command string, args []string, timeout time.Duration) *CommandScanner {
+	return &CommandScanner{
+		command: command,
+		args:    append([]string(nil), args...),
+		timeout: resolveTimeout(timeout),
+	}
+}
+
+func (s *CommandScanner) Name() string {
+	return "command"
+}
+
+func (s *CommandScanner) Scan(ctx context.Context, path string) (Result, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	args := make([]string, 0, len(s.args)+1)
+	replaced := false
+	for _, arg := range s.args {
+		if strings.Contains(arg, PathPlaceholder) {
+			arg = strings.ReplaceAll(arg, PathPlaceholder, path)
+			replaced = true
+		}
+		args = append(args, arg)
+	}
+	if !replaced {
+		args = append(args, path)
+	}
+
+	output := &limitedBuffer{limit: maxCommandOutputBytes}
+	cmd := exec.CommandContext(ctx, s.command, args...)
+	cmd.Stdout = output
+	cmd.Stderr = output
+	err := cmd.Run()
+	if err == nil {
+		return Result{}, nil
+	}
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return Result{}, fmt.Errorf("scan command did not finish: %w", ctxErr)
+	}
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		return Result{}, fmt.Errorf("failed to run scan command: %w", err)
+	}
+	if exitErr.ExitCode() == 1 {
+		return Result{Infected: true, Signature: commandSignature(output.String())}, nil
+	}
+	return Result{}, fmt.Errorf("scan command exited with code %d: %s", exitErr.ExitCode(), firstLine(output.String()))
+}
+
+// commandSignature는 "경로: 이름 FOUND" 줄에서 탐지 이름을 찾고, 없으면 출력 첫 줄을 씁니다.
+func commandSignature(output string) string {
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if !strings.HasSuffix(line, " FOUND") {
+			continue
+		}
+		line = strings.TrimSuffix(line, " FOUND")
+		if idx := strings.LastIndex(line, ": "); idx >= 0 {
+			line = line[idx+2:]
+		}
+		return truncateSignature(line)
+	}
+	if line := firstLine(output); line != "" {
+		return truncateSignature(line)
+	}
+	return "unknown"
+}
+
+func firstLine(output string) string {
+	for _, line := range strings.Split(output, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			return truncateSignature(line)
+		}
+	}
+	return ""
+}
+
+// limitedBuffer는 limit까지만 보관하고 나머지는 버리는 io.Writer입니다. 명령이 쓰기에서 막히지 않게 합니다.
+type limitedBuffer struct {
+	buf   bytes.Buffer
+	limit int
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if remaining := b.limit - b.buf.Len(); remaining > 0 {
+		if len(p) > remaining {
+			b.buf.Write(p[:remaining])
+		} else {
+			b.buf.Write(p)
+		}
+	}
+	return len(p), nil
+}
+
+func (b *limitedBuffer) String() string {
+	return b.buf.String()
+}
+
+var _ Scanner = (*CommandScanner)(nil)
@@ -0,0 +1,71 @@
+// Package malware는 업로드된 파일을 악성 코드 검사기로 확인하는 Scanner와 그 구현을 모읍니다.
+package malware
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// DefaultTimeout은 설정하지 않았을 때 파일 하나를 검사하는 데 기다리는 최대 시간입니다.
+const DefaultTimeout = 2 * time.Minute
+
+// maxSignatureLength는 검사기가 돌려준 탐지 이름을 기록에 남길 때의 최대 길이입니다.
+const maxSignatureLength = 200
+
+// Result는 파일 하나의 검사 결과입니다.
+type Result struct {
+	Infected bool `json:"infected"`
+	// Signature는 검사기가 알려 준 탐지 이름입니다. 깨끗하면 비어 있습니다.
+	Signature string `json:"signature,omitempty"`
+}
+
+// Scanner는 파일 하나를 검사합니다. 검사하지 못했으면 error를 반환하며, 이는 감염과 구분합니다.
+type Scanner interface {
+	// Name은 감사 기록과 격리 항목에 남길 검사기 이름입니다.
+	Name() string
+	Scan(ctx context.Context, path string) (Result, error)
+}
+
+// Config는 서버 설정에서 고른 검사기입니다.
+type Config struct {
+	// Engine은 "clamd" 또는 "command"입니다. 비어 있으면 검사하지 않습니다.
+	Engine       string
+	ClamdAddress string
+	Command      string
+	Args         []string
+	Timeout      time.Duration
+}
+
+// NewScanner는 설정에 맞는 Scanner를 만듭니다. Engine이 비어 있으면 nil을 반환합니다.
+func NewScanner(cfg Config) (Scanner, error) {
+	switch strings.ToLower(strings.TrimSpace(cfg.Engine)) {
+	case "":
+		return nil, nil
+	case "clamd":
+		return NewClamdScanner(cfg.ClamdAddress, cfg.Timeout)
+	case "command":
+		if strings.TrimSpace(cfg.Command) == "" {
+			return nil, fmt.Errorf("malware scan command is required")
+		}
+		return NewCommandScanner(cfg.Command, cfg.Args, cfg.Timeout), nil
+	default:
+		return nil, fmt.Errorf("unknown malware scan engine: %s", cfg.Engine)
+	}
+}
+
+func resolveTimeout(timeout time.Duration) time.Duration {
+	if timeout <= 0 {
+		return DefaultTimeout
+	}
+	return timeout
+}
+
+func truncateSignature(signature string) string {
+	signature = strings.TrimSpace(signature)
+	if len(signature) > maxSignatureLength {
+		signature = signature[:maxSignatureLength]
+	}
+	return signature
+}
@@ -0,0 +1,138 @@
+package malware
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const eicarMarker = "EICAR-STANDARD-ANTIVIRUS-TEST-FILE"
+
+// startFakeClamd는 INSTREAM만 처리하는 clamd 대역입니다. EICAR 표식이 있으면 감염으로 답합니다.
+func startFakeClamd(t *testing.T) string {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { _ = listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go serveFakeClamd(conn)
+		}
+	}()
+	return listener.Addr().String()
+}
+
+func serveFakeClamd(conn net.Conn) {
+	defer conn.Close()
+	command := make([]byte, len("zINSTREAM\x00"))
+	if _, err := io.ReadFull(conn, command); err != nil || string(command) != "zINSTREAM\x00" {
+		_, _ = io.WriteString(conn, "UNKNOWN COMMAND\x00")
+		return
+	}
+	var content bytes.Buffer
+	var header [4]byte
+	for {
+		if _, err := io.ReadFull(conn, header[:]); err != nil {
+			return
+		}
+		size := binary.BigEndian.Uint32(header[:])
+		if size == 0 {
+			break
+		}
+		if _, err := io.CopyN(&content, conn, int64(size)); err != nil {
+			return
+		}
+	}
+	if strings.Contains(content.String(), eicarMarker) {
+		_, _ = io.WriteString(conn, "stream: Eicar-Test-Signature FOUND\x00")
+		return
+	}
+	_, _ = io.WriteString(conn, "stream: OK\x00")
+}
+
+func writeTestFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+	return path
+}
+
+func TestClamdScanner_StreamsFileAndParsesVerdict(t *testing.T) {
+	scanner, err := NewScanner(Config{Engine: "clamd", ClamdAddress: "tcp://" + startFakeClamd(t), Timeout: 5 * time.Second})
+	if err != nil {
+		t.Fatalf("new scanner: %v", err)
+	}
+
+	// 조각 크기보다 큰 파일도 끝까지 보내야 뒤쪽 표식을 찾는다.
+	infected := writeTestFile(t, "payload.bin", strings.Repeat("a", clamdChunkSize+10)+eicarMarker)
+	result, err := scanner.Scan(context.Background(), infected)
+	if err != nil || !result.Infected || result.Signature != "Eicar-Test-Signature" {
+		t.Fatalf("expected infected verdict, got %+v (%v)", result, err)
+	}
+
+	result, err = scanner.Scan(context.Background(), writeTestFile(t, "clean.txt", "hello"))
+	if err != nil || result.Infected {
+		t.Fatalf("expected clean verdict, got %+v (%v)", result, err)
+	}
+
+	if _, err := parseClamdResponse("INSTREAM size limit exceeded. ERROR"); err == nil {
+		t.Fatal("expected clamd ERROR response to fail the scan")
+	}
+}
+
+func TestClamdScanner_FailsWhenDaemonIsUnreachable(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	address := listener.Addr().String()
+	_ = listener.Close()
+
+	scanner, err := NewClamdScanner(address, time.Second)
+	if err != nil {
+		t.Fatalf("new scanner: %v", err)
+	}
+	if _, err := scanner.Scan(context.Background(), writeTestFile(t, "clean.txt", "hello")); err == nil {
+		t.Fatal("expected unreachable clamd to fail the scan")
+	}
+}
+
+func TestCommandScanner_MapsExitCodes(t *testing.T) {
+	script := `case "$(cat "$1")" in
+*` + eicarMarker + `*) echo "$1: Eicar-Test-Signature FOUND"; exit 1 ;;
+broken) echo "database missing" >&2; exit 2 ;;
+esac
+echo "$1: OK"`
+	scanner, err := NewScanner(Config{Engine: "command", Command: "sh", Args: []string{"-c", script, "scan", PathPlaceholder}})
+	if err != nil {
+		t.Fatalf("new scanner: %v", err)
+	}
+
+	result, err := scanner.Scan(context.Background(), writeTestFile(t, "virus.com", eicarMarker))
+	if err != nil || !result.Infected || result.Signature != "Eicar-Test-Signature" {
+		t.Fatalf("expected infected verdict, got %+v (%v)", result, err)
+	}
+	result, err = scanner.Scan(context.Background(), writeTestFile(t, "clean.txt", "hello"))
+	if err != nil || result.Infected {
+		t.Fatalf("expected clean verdict, got %+v (%v)", result, err)
+	}
+	if _, err := scanner.Scan(context.Background(), writeTestFile(t, "broken.txt", "broken")); err == nil || !strings.Contains(err.Error(), "database missing") {
+		t.Fatalf("expected scan failure with command output, got %v", err)
+	}
+}
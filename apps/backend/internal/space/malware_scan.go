@@ -0,0 +1,710 @@
+package space
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"taeu.kr/cohesion/internal/space/malware"
+)
+
+// DefaultMalwareScanConcurrency는 격리 모드에서 뒤이어 실행하는 검사를 동시에 몇 개까지 돌릴지입니다.
+const DefaultMalwareScanConcurrency = 2
+
+// MalwareScanMode는 Space에 새로 들어온 파일을 언제 검사할지입니다.
+type MalwareScanMode string
+
+const (
+	// MalwareScanModeOff는 검사하지 않습니다.
+	MalwareScanModeOff MalwareScanMode = "off"
+	// MalwareScanModeBlock은 검사가 끝나 깨끗할 때만 업로드를 마칩니다. 검사하지 못하면 업로드를 거절합니다.
+	MalwareScanModeBlock MalwareScanMode = "block"
+	// MalwareScanModeQuarantine은 업로드를 먼저 마치고 뒤이어 검사해 감염된 파일을 격리합니다.
+	MalwareScanModeQuarantine MalwareScanMode = "quarantine"
+)
+
+var (
+	// ErrMalwareScannerNotConfigured는 서버에 검사기가 없는데 Space 검사를 켜려 할 때입니다.
+	ErrMalwareScannerNotConfigured = errors.New("malware scanner is not configured")
+	// ErrMalwareScanFailed는 block 모드에서 검사기가 결과를 주지 못해 업로드를 거절할 때입니다.
+	ErrMalwareScanFailed = errors.New("malware scan failed")
+	// ErrQuarantineItemNotFound는 없는 격리 항목입니다.
+	ErrQuarantineItemNotFound = errors.New("quarantine item not found")
+	// ErrQuarantineReleaseConflict는 풀어 줄 원래 경로에 이미 다른 항목이 있을 때입니다.
+	ErrQuarantineReleaseConflict = errors.New("a file already exists at the original path")
+	// ErrInvalidMalwareScanMode는 지원하지 않는 검사 모드입니다.
+	ErrInvalidMalwareScanMode = errors.New("invalid malware scan mode")
+)
+
+// MalwareScanSettings는 Space 하나의 검사 설정입니다.
+type MalwareScanSettings struct {
+	SpaceID   int64           `json:"spaceId"`
+	Mode      MalwareScanMode `json:"mode"`
+	UpdatedAt *time.Time      `json:"updatedAt,omitempty"`
+}
+
+// MalwareScanSettingsStorer는 Space별 검사 설정을 저장합니다. 기록이 없으면 nil을 반환합니다.
+type MalwareScanSettingsStorer interface {
+	GetMalwareScanSettings(ctx context.Context, spaceID int64) (*MalwareScanSettings, error)
+	SaveMalwareScanSettings(ctx context.Context, settings *MalwareScanSettings) error
+}
+
+// QuarantineItem은 감염으로 판정되어 사용자에게 보이지 않는 격리 영역으로 옮긴 파일입니다.
+type QuarantineItem struct {
+	ID      int64 `json:"id"`
+	SpaceID int64 `json:"spaceId"`
+	// OriginalPath는 파일이 놓였거나 놓일 Space 루트 기준 경로(슬래시 구분)입니다.
+	OriginalPath string `json:"originalPath"`
+	// StoragePath는 격리 디렉터리 안의 파일 이름입니다. 원래 이름과 확장자를 쓰지 않습니다.
+	StoragePath string `json:"-"`
+	ItemName    string `json:"itemName"`
+	ItemSize    int64  `json:"itemSize"`
+	Signature   string `json:"signature"`
+	Scanner     string `json:"scanner"`
+	Mode        string `json:"mode"`
+	// Source는 파일이 들어온 경로입니다(rest, webdav, sftp, ftp).
+	Source        string    `json:"source"`
+	UploadedBy    string    `json:"uploadedBy"`
+	QuarantinedAt time.Time `json:"quarantinedAt"`
+}
+
+// QuarantineStorer는 격리 항목을 저장합니다. spaceID가 0이면 모든 Space의 항목을 나열합니다.
+type QuarantineStorer interface {
+	CreateQuarantineItem(ctx context.Context, item *QuarantineItem) (*QuarantineItem, error)
+	GetQuarantineItem(ctx context.Context, id int64) (*QuarantineItem, error)
+	ListQuarantineItems(ctx context.Context, spaceID int64) ([]*QuarantineItem, error)
+	DeleteQuarantineItem(ctx context.Context, id int64) error
+}
+
+// MalwareDetectedError는 block 모드 검사에서 감염이 확인되어 업로드를 거절한 경우입니다.
+type MalwareDetectedError struct {
+	SpaceID      int64
+	Name         string
+	Signature    string
+	QuarantineID int64
+}
+
+func (e *MalwareDetectedError) Error() string {
+	return fmt.Sprintf("malware detected in %s: %s", e.Name, e.Signature)
+}
+
+// MalwareScanTarget은 검사할 파일 하나입니다.
+type MalwareScanTarget struct {
+	SpaceID   int64
+	SpaceRoot string
+	// FilePath는 검사할 파일입니다. 아직 제자리에 옮기지 않은 스테이징 파일일 수 있습니다.
+	FilePath string
+	// DestPath는 파일이 놓였거나 놓일 Space 안 경로입니다. 비어 있으면 FilePath입니다.
+	DestPath string
+	Actor    string
+	Source   string
+}
+
+func (t MalwareScanTarget) destPath() string {
+	if t.DestPath != "" {
+		return t.DestPath
+	}
+	return t.FilePath
+}
+
+// MalwareScanEventKind는 알림 종류입니다.
+type MalwareScanEventKind string
+
+const (
+	// MalwareScanEventQuarantined는 감염된 파일을 격리했을 때입니다.
+	MalwareScanEventQuarantined MalwareScanEventKind = "quarantined"
+	// MalwareScanEventFailed는 검사기가 결과를 주지 못했을 때입니다.
+	MalwareScanEventFailed MalwareScanEventKind = "failed"
+)
+
+// MalwareScanEvent는 감사 기록으로 남길 검사 결과입니다.
+type MalwareScanEvent struct {
+	Kind    MalwareScanEventKind
+	SpaceID int64
+	// Path는 Space 루트 기준 경로입니다.
+	Path    string
+	Actor   string
+	Source  string
+	Mode    MalwareScanMode
+	Scanner string
+	// Item은 Kind가 quarantined일 때의 격리 항목입니다.
+	Item *QuarantineItem
+	Err  error
+}
+
+// MalwareScanNotifier는 격리와 검사 실패를 알리는 통로입니다.
+type MalwareScanNotifier interface {
+	NotifyMalwareScan(ctx context.Context, event MalwareScanEvent)
+}
+
+// MalwareScanNotifierFunc는 함수를 MalwareScanNotifier로 씁니다.
+type MalwareScanNotifierFunc func(ctx context.Context, event MalwareScanEvent)
+
+func (f MalwareScanNotifierFunc) NotifyMalwareScan(ctx context.Context, event MalwareScanEvent) {
+	f(ctx, event)
+}
+
+// MalwareScanHook은 WebDAV/SFTP/FTP가 파일을 다 쓴 뒤 검사를 요청할 때 쓰는 기능입니다.
+type MalwareScanHook interface {
+	// ScanWrittenFile은 block 모드면 바로 검사해 감염된 파일을 격리하고 *MalwareDetectedError를,
+	// quarantine 모드면 뒤이어 검사하도록 맡기고 nil을 반환합니다.
+	ScanWrittenFile(ctx context.Context, target MalwareScanTarget) error
+}
+
+// MalwareScanService는 Space 설정에 따라 업로드된 파일을 검사하고 감염된 파일을 격리합니다.
+// 격리 디렉터리는 Space 밖에 있어 어떤 프로토콜로도 보이지 않으며, 관리자가 검토해 풀어 주거나 지웁니다.
+type MalwareScanService struct {
+	spaceService *Service
+	scanner      malware.Scanner
+	dir          string
+	settings     MalwareScanSettingsStorer
+	items        QuarantineStorer
+	// usage와 owners가 있으면 제자리에 있던 파일을 격리하거나 풀어 줄 때 사용량과 소유자를 함께 고칩니다.
+	usage    UsageTracker
+	owners   UserStorageTracker
+	notifier MalwareScanNotifier
+
+	slots   chan struct{}
+	pending sync.WaitGroup
+	// inflight는 뒤이어 검사할 파일 경로별로 검사가 끝나면 닫히는 채널입니다.
+	inflightMu sync.Mutex
+	inflight   map[string]*inflightScan
+}
+
+type inflightScan struct {
+	count int
+	done  chan struct{}
+}
+
+// NewMalwareScanService는 scanner로 검사하고 dir에 격리하는 서비스를 만듭니다.
+// scanner가 nil이면 검사를 켤 수 없습니다. 설정과 격리 목록은 메모리에 두며 Set* 로 바꿉니다.
+func NewMalwareScanService(spaceService *Service, scanner malware.Scanner, dir string) *MalwareScanService {
+	if strings.TrimSpace(dir) == "" {
+		dir = filepath.Join(os.TempDir(), "cohesion-quarantine")
+	}
+	return &MalwareScanService{
+		spaceService: spaceService,
+		scanner:      scanner,
+		dir:          dir,
+		settings:     NewMemoryMalwareScanSettingsStore(),
+		items:        NewMemoryQuarantineStore(),
+		slots:        make(chan struct{}, DefaultMalwareScanConcurrency),
+		inflight:     make(map[string]*inflightScan),
+	}
+}
+
+func (s *MalwareScanService) SetSettingsStore(store MalwareScanSettingsStorer) {
+	if store != nil {
+		s.settings = store
+	}
+}
+
+func (s *MalwareScanService) SetQuarantineStore(store QuarantineStorer) {
+	if store != nil {
+		s.items = store
+	}
+}
+
+func (s *MalwareScanService) SetUsageTracker(tracker UsageTracker) {
+	s.usage = tracker
+}
+
+func (s *MalwareScanService) SetUserStorageTracker(tracker UserStorageTracker) {
+	s.owners = tracker
+}
+
+// SetNotifier는 격리와 검사 실패를 알릴 대상을 설정합니다.
+func (s *MalwareScanService) SetNotifier(notifier MalwareScanNotifier) {
+	s.notifier = notifier
+}
+
+// ScannerName은 설정된 검사기 이름입니다. 없으면 빈 문자열입니다.
+func (s *MalwareScanService) ScannerName() string {
+	if s.scanner == nil {
+		return ""
+	}
+	return s.scanner.Name()
+}
+
+// GetSettings는 Space의 검사 설정을 반환합니다. 기록이 없으면 off입니다.
+func (s *MalwareScanService) GetSettings(ctx context.Context, spaceID int64) (*MalwareScanSettings, error) {
+	settings, err := s.settings.GetMalwareScanSettings(ctx, spaceID)
+	if err != nil {
+		return nil, err
+	}
+	if settings == nil {
+		return &MalwareScanSettings{SpaceID: spaceID, Mode: MalwareScanModeOff}, nil
+	}
+	return settings, nil
+}
+
+// SaveSettings는 Space의 검사 모드를 바꿉니다. 검사기가 없으면 off만 저장할 수 있습니다.
+func (s *MalwareScanService) SaveSettings(ctx context.Context, spaceID int64, mode MalwareScanMode) (*MalwareScanSettings, error) {
+	switch mode {
+	case MalwareScanModeOff, MalwareScanModeBlock, MalwareScanModeQuarantine:
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrInvalidMalwareScanMode, mode)
+	}
+	if mode != MalwareScanModeOff && s.scanner == nil {
+		return nil, ErrMalwareScannerNotConfigured
+	}
+	now := time.Now()
+	settings := &MalwareScanSettings{SpaceID: spaceID, Mode: mode, UpdatedAt: &now}
+	if err := s.settings.SaveMalwareScanSettings(ctx, settings); err != nil {
+		return nil, err
+	}
+	return settings, nil
+}
+
+// modeFor는 실제로 적용할 모드입니다. 검사기가 없으면 설정과 관계없이 off입니다.
+func (s *MalwareScanService) modeFor(ctx context.Context, spaceID int64) (MalwareScanMode, error) {
+	if s == nil || s.scanner == nil {
+		return MalwareScanModeOff, nil
+	}
+	settings, err := s.GetSettings(ctx, spaceID)
+	if err != nil {
+		return MalwareScanModeOff, err
+	}
+	return settings.Mode, nil
+}
+
+// ScanBeforeFinalize는 block 모드일 때 제자리에 옮기기 전의 파일을 검사합니다.
+// 감염이면 파일을 격리하고 *MalwareDetectedError를, 검사하지 못하면 ErrMalwareScanFailed를 감싼 오류를 반환합니다.
+// 다른 모드에서는 아무것도 하지 않습니다.
+func (s *MalwareScanService) ScanBeforeFinalize(ctx context.Context, target MalwareScanTarget) error {
+	mode, err := s.modeFor(ctx, target.SpaceID)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrMalwareScanFailed, err)
+	}
+	if mode != MalwareScanModeBlock {
+		return nil
+	}
+	return s.scanNow(ctx, target, mode, false)
+}
+
+// ScanAfterFinalize는 quarantine 모드일 때 제자리에 놓인 파일을 뒤이어 검사하도록 맡깁니다.
+func (s *MalwareScanService) ScanAfterFinalize(ctx context.Context, target MalwareScanTarget) {
+	mode, err := s.modeFor(ctx, target.SpaceID)
+	if err != nil {
+		log.Warn().Err(err).Int64("space_id", target.SpaceID).Msg("failed to load malware scan settings")
+		return
+	}
+	if mode == MalwareScanModeQuarantine {
+		s.scanLater(ctx, target, mode)
+	}
+}
+
+// ScanWrittenFile은 프로토콜 쓰기로 이미 제자리에 놓인 파일을 모드에 따라 검사합니다.
+// block 모드에서 검사하지 못한 파일은 그대로 두고 실패만 알립니다. 쓰기를 되돌릴 수 없기 때문입니다.
+func (s *MalwareScanService) ScanWrittenFile(ctx context.Context, target MalwareScanTarget) error {
+	mode, err := s.modeFor(ctx, target.SpaceID)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrMalwareScanFailed, err)
+	}
+	switch mode {
+	case MalwareScanModeBlock:
+		err := s.scanNow(ctx, target, mode, true)
+		var detected *MalwareDetectedError
+		if errors.As(err, &detected) {
+			return err
+		}
+		return nil
+	case MalwareScanModeQuarantine:
+		s.scanLater(ctx, target, mode)
+	}
+	return nil
+}
+
+// Wait는 뒤이어 실행 중인 검사가 모두 끝날 때까지 기다립니다.
+func (s *MalwareScanService) Wait() {
+	s.pending.Wait()
+}
+
+// WaitForScan은 absPath에 맡겨 둔 검사가 끝날 때까지 기다립니다. 맡긴 검사가 없으면 바로 반환합니다.
+// 업로드 직후 파일을 옮기는 쪽이 검사 전에 파일을 옮겨 격리를 피하지 않도록 씁니다.
+func (s *MalwareScanService) WaitForScan(ctx context.Context, absPath string) error {
+	if s == nil {
+		return nil
+	}
+	s.inflightMu.Lock()
+	scan, ok := s.inflight[filepath.Clean(absPath)]
+	s.inflightMu.Unlock()
+	if !ok {
+		return nil
+	}
+	select {
+	case <-scan.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *MalwareScanService) scanLater(ctx context.Context, target MalwareScanTarget, mode MalwareScanMode) {
+	ctx = context.WithoutCancel(ctx)
+	release := s.trackInflight(target.FilePath)
+	s.pending.Add(1)
+	go func() {
+		defer s.pending.Done()
+		defer release()
+		s.slots <- struct{}{}
+		defer func() { <-s.slots }()
+		_ = s.scanNow(ctx, target, mode, true)
+	}()
+}
+
+// trackInflight는 path의 검사를 진행 중으로 기록하고, 끝났을 때 부를 함수를 반환합니다.
+// 같은 파일을 여러 번 맡기면 마지막 검사가 끝날 때 기다림이 풀립니다.
+func (s *MalwareScanService) trackInflight(path string) func() {
+	key := filepath.Clean(path)
+	s.inflightMu.Lock()
+	scan, ok := s.inflight[key]
+	if !ok {
+		scan = &inflightScan{done: make(chan struct{})}
+		s.inflight[key] = scan
+	}
+	scan.count++
+	s.inflightMu.Unlock()
+
+	return func() {
+		s.inflightMu.Lock()
+		defer s.inflightMu.Unlock()
+		scan.count--
+		if scan.count == 0 {
+			close(scan.done)
+			delete(s.inflight, key)
+		}
+	}
+}
+
+// scanNow는 target을 검사하고 감염이면 격리합니다. placed이면 Space 안에 놓인 파일이므로
+// 옮길 때 사용량과 소유자 기록도 고칩니다.
+func (s *MalwareScanService) scanNow(ctx context.Context, target MalwareScanTarget, mode MalwareScanMode, placed bool) error {
+	relPath := quarantineRelPath(target.SpaceRoot, target.destPath())
+	info, err := os.Stat(target.FilePath)
+	if err != nil {
+		if placed && os.IsNotExist(err) {
+			// 검사 전에 지우거나 옮긴 파일입니다.
+			return nil
+		}
+		return s.scanFailed(ctx, target, relPath, mode, err)
+	}
+	if !info.Mode().IsRegular() {
+		return nil
+	}
+
+	result, err := s.scanner.Scan(ctx, target.FilePath)
+	if err != nil {
+		return s.scanFailed(ctx, target, relPath, mode, err)
+	}
+	if !result.Infected {
+		return nil
+	}
+
+	item, err := s.quarantine(ctx, target, relPath, info.Size(), result, mode, placed)
+	if err != nil {
+		log.Error().Err(err).Int64("space_id", target.SpaceID).Str("path", relPath).Msg("failed to quarantine infected file")
+		return s.scanFailed(ctx, target, relPath, mode, err)
+	}
+	log.Warn().
+		Int64("space_id", target.SpaceID).
+		Str("path", relPath).
+		Str("signature", result.Signature).
+		Int64("quarantine_id", item.ID).
+		Msg("infected file quarantined")
+	s.notify(ctx, MalwareScanEvent{
+		Kind:    MalwareScanEventQuarantined,
+		SpaceID: target.SpaceID,
+		Path:    relPath,
+		Actor:   target.Actor,
+		Source:  target.Source,
+		Mode:    mode,
+		Scanner: s.scanner.Name(),
+		Item:    item,
+	})
+	return &MalwareDetectedError{
+		SpaceID:      target.SpaceID,
+		Name:         filepath.Base(target.destPath()),
+		Signature:    result.Signature,
+		QuarantineID: item.ID,
+	}
+}
+
+func (s *MalwareScanService) scanFailed(ctx context.Context, target MalwareScanTarget, relPath string, mode MalwareScanMode, cause error) error {
+	log.Warn().Err(cause).Int64("space_id", target.SpaceID).Str("path", relPath).Msg("malware scan failed")
+	s.notify(ctx, MalwareScanEvent{
+		Kind:    MalwareScanEventFailed,
+		SpaceID: target.SpaceID,
+		Path:    relPath,
+		Actor:   target.Actor,
+		Source:  target.Source,
+		Mode:    mode,
+		Scanner: s.ScannerName(),
+		Err:     cause,
+	})
+	return fmt.Errorf("%w: %v", ErrMalwareScanFailed, cause)
+}
+
+func (s *MalwareScanService) notify(ctx context.Context, event MalwareScanEvent) {
+	if s.notifier != nil {
+		s.notifier.NotifyMalwareScan(context.WithoutCancel(ctx), event)
+	}
+}
+
+func (s *MalwareScanService) quarantine(ctx context.Context, target MalwareScanTarget, relPath string, size int64, result malware.Result, mode MalwareScanMode, placed bool) (*QuarantineItem, error) {
+	if err := os.MkdirAll(s.dir, 0o700); err != nil {
+		return nil, err
+	}
+	storageName, err := newQuarantineStorageName(target.SpaceID)
+	if err != nil {
+		return nil, err
+	}
+	storagePath := filepath.Join(s.dir, storageName)
+
+	commit := func() {}
+	if placed {
+		commit = TrackUsage(ctx, s.usage, target.SpaceID, target.FilePath)
+	}
+	if err := moveQuarantineFile(target.FilePath, storagePath); err != nil {
+		return nil, err
+	}
+	_ = os.Chmod(storagePath, 0o600)
+	commit()
+	if placed && s.owners != nil {
+		if err := s.owners.DeleteOwners(context.WithoutCancel(ctx), target.SpaceID, target.SpaceRoot, target.FilePath); err != nil {
+			log.Warn().Err(err).Str("path", target.FilePath).Msg("failed to delete owners of quarantined file")
+		}
+	}
+
+	item, err := s.items.CreateQuarantineItem(context.WithoutCancel(ctx), &QuarantineItem{
+		SpaceID:       target.SpaceID,
+		OriginalPath:  relPath,
+		StoragePath:   storageName,
+		ItemName:      filepath.Base(target.destPath()),
+		ItemSize:      size,
+		Signature:     result.Signature,
+		Scanner:       s.scanner.Name(),
+		Mode:          string(mode),
+		Source:        target.Source,
+		UploadedBy:    target.Actor,
+		QuarantinedAt: time.Now(),
+	})
+	if err != nil {
+		// 기록 없이 남은 파일은 아무도 찾을 수 없으므로 지웁니다.
+		_ = os.Remove(storagePath)
+		return nil, err
+	}
+	return item, nil
+}
+
+// ListQuarantine은 격리 항목을 최근 것부터 나열합니다. spaceID가 0이면 모든 Space입니다.
+func (s *MalwareScanService) ListQuarantine(ctx context.Context, spaceID int64) ([]*QuarantineItem, error) {
+	return s.items.ListQuarantineItems(ctx, spaceID)
+}
+
+func (s *MalwareScanService) GetQuarantineItem(ctx context.Context, id int64) (*QuarantineItem, error) {
+	return s.items.GetQuarantineItem(ctx, id)
+}
+
+// ReleaseQuarantineItem은 오탐으로 확인한 파일을 다시 검사하지 않고 원래 경로로 돌려놓습니다.
+// 원래 경로에 다른 항목이 있으면 ErrQuarantineReleaseConflict입니다. 소유자는 업로드한 사용자로 복원합니다.
+func (s *MalwareScanService) ReleaseQuarantineItem(ctx context.Context, id int64) (*QuarantineItem, error) {
+	item, err := s.items.GetQuarantineItem(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	spaceObj, err := s.spaceService.GetSpaceByID(ctx, item.SpaceID)
+	if err != nil {
+		return nil, err
+	}
+	destPath := filepath.Join(spaceObj.SpacePath, filepath.FromSlash(item.OriginalPath))
+	if rel, err := filepath.Rel(spaceObj.SpacePath, destPath); err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return nil, fmt.Errorf("invalid original path: %s", item.OriginalPath)
+	}
+	if _, err := os.Lstat(destPath); err == nil {
+		return nil, ErrQuarantineReleaseConflict
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(destPath), 0o755); err != nil {
+		return nil, err
+	}
+
+	commit := TrackUsage(ctx, s.usage, item.SpaceID, destPath)
+	if err := moveQuarantineFile(filepath.Join(s.dir, item.StoragePath), destPath); err != nil {
+		return nil, err
+	}
+	_ = os.Chmod(destPath, 0o644)
+	commit()
+	if s.owners != nil && item.UploadedBy != "" {
+		if err := s.owners.RecordOwner(context.WithoutCancel(ctx), item.UploadedBy, item.SpaceID, spaceObj.SpacePath, destPath); err != nil {
+			log.Warn().Err(err).Str("path", destPath).Msg("failed to record owner of released file")
+		}
+	}
+	if err := s.items.DeleteQuarantineItem(ctx, id); err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+// DeleteQuarantineItem은 격리한 파일을 영구히 지웁니다.
+func (s *MalwareScanService) DeleteQuarantineItem(ctx context.Context, id int64) (*QuarantineItem, error) {
+	item, err := s.items.GetQuarantineItem(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Remove(filepath.Join(s.dir, item.StoragePath)); err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	if err := s.items.DeleteQuarantineItem(ctx, id); err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+func quarantineRelPath(root, absPath string) string {
+	if root != "" {
+		if rel, err := filepath.Rel(root, absPath); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return filepath.ToSlash(rel)
+		}
+	}
+	return filepath.Base(absPath)
+}
+
+func newQuarantineStorageName(spaceID int64) (string, error) {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%d-%d-%s", spaceID, time.Now().UnixNano(), hex.EncodeToString(buf)), nil
+}
+
+// moveQuarantineFile은 파일을 옮깁니다. 격리 디렉터리가 다른 파일 시스템에 있으면 복사한 뒤 원본을 지웁니다.
+func moveQuarantineFile(src, dst string) error {
+	if err := os.Rename(src, dst); err == nil {
+		return nil
+	}
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		_ = out.Close()
+		_ = os.Remove(dst)
+		return err
+	}
+	if err := out.Sync(); err != nil {
+		_ = out.Close()
+		_ = os.Remove(dst)
+		return err
+	}
+	if err := out.Close(); err != nil {
+		_ = os.Remove(dst)
+		return err
+	}
+	return os.Remove(src)
+}
+
+var _ MalwareScanHook = (*MalwareScanService)(nil)
+
+// MemoryMalwareScanSettingsStore는 프로세스 메모리에만 검사 설정을 두는 저장소입니다.
+type MemoryMalwareScanSettingsStore struct {
+	mu       sync.RWMutex
+	settings map[int64]MalwareScanSettings
+}
+
+func NewMemoryMalwareScanSettingsStore() *MemoryMalwareScanSettingsStore {
+	return &MemoryMalwareScanSettingsStore{settings: make(map[int64]MalwareScanSettings)}
+}
+
+func (s *MemoryMalwareScanSettingsStore) GetMalwareScanSettings(ctx context.Context, spaceID int64) (*MalwareScanSettings, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	settings, ok := s.settings[spaceID]
+	if !ok {
+		return nil, nil
+	}
+	return &settings, nil
+}
+
+func (s *MemoryMalwareScanSettingsStore) SaveMalwareScanSettings(ctx context.Context, settings *MalwareScanSettings) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.settings[settings.SpaceID] = *settings
+	return nil
+}
+
+var _ MalwareScanSettingsStorer = (*MemoryMalwareScanSettingsStore)(nil)
+
+// MemoryQuarantineStore는 프로세스 메모리에만 격리 항목을 두는 저장소입니다.
+type MemoryQuarantineStore struct {
+	mu     sync.RWMutex
+	nextID int64
+	items  map[int64]QuarantineItem
+}
+
+func NewMemoryQuarantineStore() *MemoryQuarantineStore {
+	return &MemoryQuarantineStore{items: make(map[int64]QuarantineItem)}
+}
+
+func (s *MemoryQuarantineStore) CreateQuarantineItem(ctx context.Context, item *QuarantineItem) (*QuarantineItem, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.nextID++
+	created := *item
+	created.ID = s.nextID
+	s.items[created.ID] = created
+	return &created, nil
+}
+
+func (s *MemoryQuarantineStore) GetQuarantineItem(ctx context.Context, id int64) (*QuarantineItem, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	item, ok := s.items[id]
+	if !ok {
+		return nil, ErrQuarantineItemNotFound
+	}
+	return &item, nil
+}
+
+func (s *MemoryQuarantineStore) ListQuarantineItems(ctx context.Context, spaceID int64) ([]*QuarantineItem, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	items := make([]*QuarantineItem, 0, len(s.items))
+	for _, item := range s.items {
+		if spaceID != 0 && item.SpaceID != spaceID {
+			continue
+		}
+		item := item
+		items = append(items, &item)
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].ID > items[j].ID })
+	return items, nil
+}
+
+func (s *MemoryQuarantineStore) DeleteQuarantineItem(ctx context.Context, id int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.items[id]; !ok {
+		return ErrQuarantineItemNotFound
+	}
+	delete(s.items, id)
+	return nil
+}
+
+var _ QuarantineStorer = (*MemoryQuarantineStore)(nil)
@@ -0,0 +1,160 @@
+package space_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"taeu.kr/cohesion/internal/space"
+	"taeu.kr/cohesion/internal/space/malware"
+	spaceStore "taeu.kr/cohesion/internal/space/store"
+)
+
+// markerScanner는 내용에 INFECTED가 있으면 감염으로 판정하는 검사기 대역입니다.
+type markerScanner struct{}
+
+func (markerScanner) Name() string { return "marker" }
+
+func (markerScanner) Scan(ctx context.Context, path string) (malware.Result, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return malware.Result{}, err
+	}
+	if strings.Contains(string(data), "INFECTED") {
+		return malware.Result{Infected: true, Signature: "Test-Marker"}, nil
+	}
+	return malware.Result{}, nil
+}
+
+func TestMalwareScanService_BlockModeQuarantinesStagedFileAndReleasesIt(t *testing.T) {
+	service, db := setupSlugSpaceService(t)
+	ctx := context.Background()
+	root := t.TempDir()
+	created, err := service.CreateSpace(ctx, &space.CreateSpaceRequest{SpaceName: "Scanned", SpacePath: root})
+	if err != nil {
+		t.Fatalf("create space: %v", err)
+	}
+
+	if _, err := space.NewMalwareScanService(service, nil, t.TempDir()).SaveSettings(ctx, created.ID, space.MalwareScanModeBlock); !errors.Is(err, space.ErrMalwareScannerNotConfigured) {
+		t.Fatalf("expected block mode without scanner to fail, got %v", err)
+	}
+
+	scans := space.NewMalwareScanService(service, markerScanner{}, t.TempDir())
+	store := spaceStore.NewMalwareScanStore(db)
+	scans.SetSettingsStore(store)
+	scans.SetQuarantineStore(store)
+	var events []space.MalwareScanEvent
+	scans.SetNotifier(space.MalwareScanNotifierFunc(func(ctx context.Context, event space.MalwareScanEvent) {
+		events = append(events, event)
+	}))
+	if _, err := scans.SaveSettings(ctx, created.ID, space.MalwareScanModeBlock); err != nil {
+		t.Fatalf("save settings: %v", err)
+	}
+
+	staged := filepath.Join(root, ".upload-stage")
+	if err := os.WriteFile(staged, []byte("clean"), 0o644); err != nil {
+		t.Fatalf("write staged: %v", err)
+	}
+	target := space.MalwareScanTarget{
+		SpaceID:   created.ID,
+		SpaceRoot: root,
+		FilePath:  staged,
+		DestPath:  filepath.Join(root, "docs", "report.txt"),
+		Actor:     "alice",
+		Source:    "rest",
+	}
+	if err := scans.ScanBeforeFinalize(ctx, target); err != nil {
+		t.Fatalf("expected clean file to pass, got %v", err)
+	}
+
+	if err := os.WriteFile(staged, []byte("INFECTED payload"), 0o644); err != nil {
+		t.Fatalf("write staged: %v", err)
+	}
+	var detected *space.MalwareDetectedError
+	if err := scans.ScanBeforeFinalize(ctx, target); !errors.As(err, &detected) || detected.Signature != "Test-Marker" {
+		t.Fatalf("expected malware detection, got %v", err)
+	}
+	if _, err := os.Stat(staged); !os.IsNotExist(err) {
+		t.Fatalf("staged file should be moved to quarantine, err=%v", err)
+	}
+	if len(events) != 1 || events[0].Kind != space.MalwareScanEventQuarantined || events[0].Path != "docs/report.txt" {
+		t.Fatalf("expected one quarantine event for docs/report.txt, got %+v", events)
+	}
+
+	items, err := scans.ListQuarantine(ctx, created.ID)
+	if err != nil || len(items) != 1 {
+		t.Fatalf("expected one quarantined item, got %v (%v)", items, err)
+	}
+	item := items[0]
+	if item.ID != detected.QuarantineID || item.OriginalPath != "docs/report.txt" || item.UploadedBy != "alice" || item.Mode != "block" {
+		t.Fatalf("unexpected quarantine item: %+v", item)
+	}
+
+	// 오탐으로 풀어 주면 원래 경로로 돌아가고 목록에서 빠진다.
+	if _, err := scans.ReleaseQuarantineItem(ctx, item.ID); err != nil {
+		t.Fatalf("release: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(root, "docs", "report.txt"))
+	if err != nil || string(data) != "INFECTED payload" {
+		t.Fatalf("expected released file at original path, got %q (%v)", data, err)
+	}
+	if _, err := scans.GetQuarantineItem(ctx, item.ID); !errors.Is(err, space.ErrQuarantineItemNotFound) {
+		t.Fatalf("expected released item to be removed, got %v", err)
+	}
+}
+
+func TestMalwareScanService_QuarantineModeMovesPlacedFileInBackground(t *testing.T) {
+	service, db := setupSlugSpaceService(t)
+	ctx := context.Background()
+	root := t.TempDir()
+	created, err := service.CreateSpace(ctx, &space.CreateSpaceRequest{SpaceName: "Scanned", SpacePath: root})
+	if err != nil {
+		t.Fatalf("create space: %v", err)
+	}
+
+	dir := t.TempDir()
+	scans := space.NewMalwareScanService(service, markerScanner{}, dir)
+	store := spaceStore.NewMalwareScanStore(db)
+	scans.SetSettingsStore(store)
+	scans.SetQuarantineStore(store)
+	if _, err := scans.SaveSettings(ctx, created.ID, space.MalwareScanModeQuarantine); err != nil {
+		t.Fatalf("save settings: %v", err)
+	}
+
+	placed := filepath.Join(root, "tool.bin")
+	if err := os.WriteFile(placed, []byte("INFECTED"), 0o644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+	target := space.MalwareScanTarget{SpaceID: created.ID, SpaceRoot: root, FilePath: placed, Actor: "bob", Source: "webdav"}
+	// quarantine 모드는 쓰기를 막지 않고 뒤이어 검사한다.
+	if err := scans.ScanWrittenFile(ctx, target); err != nil {
+		t.Fatalf("expected quarantine mode not to fail the write, got %v", err)
+	}
+	scans.Wait()
+	if _, err := os.Stat(placed); !os.IsNotExist(err) {
+		t.Fatalf("infected file should be quarantined, err=%v", err)
+	}
+
+	items, err := scans.ListQuarantine(ctx, 0)
+	if err != nil || len(items) != 1 || items[0].Source != "webdav" {
+		t.Fatalf("expected one webdav quarantine item, got %v (%v)", items, err)
+	}
+
+	// 같은 경로에 새 파일이 생기면 풀어 줄 수 없다.
+	if err := os.WriteFile(placed, []byte("replacement"), 0o644); err != nil {
+		t.Fatalf("write replacement: %v", err)
+	}
+	if _, err := scans.ReleaseQuarantineItem(ctx, items[0].ID); !errors.Is(err, space.ErrQuarantineReleaseConflict) {
+		t.Fatalf("expected release conflict, got %v", err)
+	}
+
+	if _, err := scans.DeleteQuarantineItem(ctx, items[0].ID); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if entries, err := os.ReadDir(dir); err != nil || len(entries) != 0 {
+		t.Fatalf("expected quarantine directory to be empty, got %v (%v)", entries, err)
+	}
+}
@@ -0,0 +1,985 @@
+package space
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"taeu.kr/cohesion/internal/platform/logging"
+	"taeu.kr/cohesion/internal/space/exif"
+)
+
+const (
+	// OrganizeActor는 자동 정리가 옮긴 파일과 붙인 태그, 감사 기록의 행위자입니다.
+	OrganizeActor = "system"
+	// MaxOrganizeActions는 규칙 하나에 둘 수 있는 동작 수입니다.
+	MaxOrganizeActions = 10
+	// MaxOrganizeTags는 tag 동작 하나가 붙일 수 있는 태그 수입니다.
+	MaxOrganizeTags = 20
+	// MaxFileTagLength는 태그 하나의 최대 길이(문자 수)입니다.
+	MaxFileTagLength = 64
+	// MaxOrganizeRuleNameLength는 규칙 이름의 최대 길이(문자 수)입니다.
+	MaxOrganizeRuleNameLength = 100
+	// MaxOrganizeMessageLength는 notify 동작 메시지의 최대 길이(문자 수)입니다.
+	MaxOrganizeMessageLength = 500
+	// maxOrganizeNameSuffix는 같은 이름이 있을 때 붙여 볼 " (n)" 번호의 상한입니다.
+	maxOrganizeNameSuffix = 1000
+)
+
+// OrganizeActionType은 규칙에 걸린 파일에 할 일입니다.
+type OrganizeActionType string
+
+const (
+	// OrganizeActionMove는 Destination 템플릿이 가리키는 폴더로 옮깁니다. 폴더가 없으면 만듭니다.
+	OrganizeActionMove OrganizeActionType = "move"
+	// OrganizeActionRename은 같은 폴더 안에서 Template 템플릿 이름으로 바꿉니다.
+	OrganizeActionRename OrganizeActionType = "rename"
+	// OrganizeActionTag는 파일에 Tags를 붙입니다.
+	OrganizeActionTag OrganizeActionType = "tag"
+	// OrganizeActionNotify는 Message 템플릿으로 알림(감사 기록)을 남깁니다.
+	OrganizeActionNotify OrganizeActionType = "notify"
+)
+
+var (
+	// ErrInvalidOrganizeRule은 규칙 값이 잘못된 경우입니다.
+	ErrInvalidOrganizeRule = errors.New("invalid organize rule")
+	// ErrOrganizeRuleNotFound는 없는 규칙이거나 다른 Space의 규칙입니다.
+	ErrOrganizeRuleNotFound = errors.New("organize rule not found")
+	// ErrInvalidFileTagQuery는 태그 조회 조건이 잘못된 경우입니다.
+	ErrInvalidFileTagQuery = errors.New("invalid file tag query")
+
+	// errOrganizeInvalidTarget은 템플릿을 채운 결과가 쓸 수 없는 경로나 이름인 경우입니다.
+	errOrganizeInvalidTarget = errors.New("invalid organize target")
+)
+
+// organizeTemplateFields는 템플릿에 쓸 수 있는 자리표시자입니다.
+//   - {yyyy} {mm} {dd}: 촬영 날짜(EXIF). 없으면 수정 날짜입니다.
+//   - {name}: 확장자를 뺀 파일 이름, {ext}: 점 없는 확장자, {filename}: 파일 이름 전체
+//   - {uploader}: 파일을 올린 사용자
+var organizeTemplateFields = []string{"yyyy", "mm", "dd", "name", "ext", "filename", "uploader"}
+
+// OrganizeConditions는 규칙에 걸릴 파일의 조건입니다. 비어 있는 조건은 보지 않고, 모든 조건을 만족해야 합니다.
+type OrganizeConditions struct {
+	// NamePattern은 파일 이름에 맞출 path.Match 형식 glob입니다. 대소문자를 가리지 않습니다.
+	NamePattern string `json:"namePattern,omitempty"`
+	// Extensions는 점 없는 확장자 목록입니다. "tar.gz"처럼 여러 단계도 됩니다.
+	Extensions []string `json:"extensions,omitempty"`
+	MinSize    *int64   `json:"minSize,omitempty"`
+	MaxSize    *int64   `json:"maxSize,omitempty"`
+	// TakenAfter와 TakenBefore는 EXIF 촬영 시각 범위입니다([after, before)). 둘 중 하나라도 있으면
+	// 촬영 시각이 없는 파일은 걸리지 않습니다.
+	TakenAfter  *time.Time `json:"takenAfter,omitempty"`
+	TakenBefore *time.Time `json:"takenBefore,omitempty"`
+}
+
+// OrganizeAction은 규칙의 동작 하나입니다. Type에 맞는 필드만 씁니다.
+type OrganizeAction struct {
+	Type OrganizeActionType `json:"type"`
+	// Destination은 move의 대상 폴더 템플릿입니다(예: "Photos/{yyyy}/{mm}").
+	Destination string `json:"destination,omitempty"`
+	// Template은 rename의 새 이름 템플릿입니다(예: "{yyyy}-{mm}-{dd} {name}.{ext}").
+	Template string   `json:"template,omitempty"`
+	Tags     []string `json:"tags,omitempty"`
+	// Message는 notify로 남길 문장 템플릿입니다.
+	Message string `json:"message,omitempty"`
+}
+
+// OrganizeRule은 Space에 새로 놓인 파일을 정리하는 규칙입니다.
+// WatchPath 폴더(IncludeSubfolders면 하위 폴더 포함)에 파일이 생기면 Conditions를 보고 Actions를 차례로 실행합니다.
+// 한 파일에는 ID 순서로 처음 맞는 규칙 하나만 적용합니다.
+type OrganizeRule struct {
+	ID      int64  `json:"id"`
+	SpaceID int64  `json:"spaceId"`
+	Name    string `json:"name"`
+	// WatchPath는 Space 루트 기준 폴더입니다. 비어 있으면 루트입니다.
+	WatchPath         string             `json:"watchPath"`
+	IncludeSubfolders bool               `json:"includeSubfolders"`
+	Conditions        OrganizeConditions `json:"conditions"`
+	Actions           []OrganizeAction   `json:"actions"`
+	Enabled           bool               `json:"enabled"`
+	CreatedBy         string             `json:"createdBy"`
+	CreatedAt         time.Time          `json:"createdAt"`
+	UpdatedAt         time.Time          `json:"updatedAt"`
+}
+
+// OrganizeRuleRequest는 규칙을 만들거나 통째로 바꾸는 요청입니다. Enabled가 없으면 켭니다.
+type OrganizeRuleRequest struct {
+	Name              string             `json:"name"`
+	WatchPath         string             `json:"watchPath"`
+	IncludeSubfolders bool               `json:"includeSubfolders"`
+	Conditions        OrganizeConditions `json:"conditions"`
+	Actions           []OrganizeAction   `json:"actions"`
+	Enabled           *bool              `json:"enabled"`
+}
+
+// OrganizeRuleStorer는 자동 정리 규칙 저장소입니다.
+type OrganizeRuleStorer interface {
+	ListOrganizeRules(ctx context.Context, spaceID int64) ([]*OrganizeRule, error)
+	GetOrganizeRule(ctx context.Context, id int64) (*OrganizeRule, error)
+	CreateOrganizeRule(ctx context.Context, rule *OrganizeRule) (*OrganizeRule, error)
+	UpdateOrganizeRule(ctx context.Context, rule *OrganizeRule) error
+	DeleteOrganizeRule(ctx context.Context, id int64) error
+}
+
+// FileTag는 파일 경로에 붙은 태그 하나입니다. 태그는 경로에 묶이므로 파일을 옮기거나 지우면 조회할 때 떨어집니다.
+type FileTag struct {
+	SpaceID   int64     `json:"spaceId"`
+	Path      string    `json:"path"`
+	Tag       string    `json:"tag"`
+	CreatedBy string    `json:"createdBy"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// FileTagQuery는 태그 조회 조건입니다. Path와 Tag가 모두 비면 Space의 모든 태그입니다.
+type FileTagQuery struct {
+	Path string
+	Tag  string
+}
+
+// FileTagStorer는 파일 태그 저장소입니다. 이미 붙은 태그를 다시 붙이면 무시합니다.
+type FileTagStorer interface {
+	AddFileTags(ctx context.Context, tags []FileTag) error
+	ListFileTags(ctx context.Context, spaceID int64, query FileTagQuery) ([]*FileTag, error)
+	DeleteFileTags(ctx context.Context, spaceID int64, path string) error
+}
+
+// OrganizeMover는 Space 안에서 파일 하나를 옮깁니다. 소유자와 검색 색인 기록을 함께 다뤄야 해서 handler가 구현합니다.
+// toRel에 이미 무언가 있으면 덮어쓰지 않고 오류를 반환해야 합니다.
+type OrganizeMover interface {
+	MoveOrganizedFile(ctx context.Context, spaceObj *Space, fromRel, toRel string) error
+}
+
+// OrganizeScanWaiter는 업로드 직후 맡겨 둔 악성코드 검사가 끝나기를 기다립니다.
+// 검사 전에 파일을 옮겨 격리를 피하지 않도록 move와 rename 전에 씁니다.
+type OrganizeScanWaiter interface {
+	WaitForScan(ctx context.Context, absPath string) error
+}
+
+// OrganizeEvent는 감사 기록으로 남길 동작 하나의 결과입니다. Err가 있으면 그 동작에서 멈춘 것입니다.
+type OrganizeEvent struct {
+	Rule    OrganizeRule
+	SpaceID int64
+	Action  OrganizeActionType
+	// Path는 동작 전 경로, Destination은 move/rename 뒤 경로입니다. 모두 Space 루트 기준입니다.
+	Path        string
+	Destination string
+	Tags        []string
+	Message     string
+	Uploader    string
+	Source      string
+	Err         error
+}
+
+// Reason은 감사 기록에 남길 실패 사유 code입니다. 실패가 아니면 빈 문자열입니다.
+// 오류 문장에는 서버 절대 경로가 들어갈 수 있어 code로만 남깁니다.
+func (e OrganizeEvent) Reason() string {
+	var lockedErr *RetentionLockedError
+	switch {
+	case e.Err == nil:
+		return ""
+	case errors.As(e.Err, &lockedErr):
+		return "retention_locked"
+	case errors.Is(e.Err, ErrSpaceReadOnly), errors.Is(e.Err, ErrSpaceOffline),
+		errors.Is(e.Err, ErrSpaceMaintenance), errors.Is(e.Err, ErrSpaceArchived):
+		return "space_not_writable"
+	case errors.Is(e.Err, os.ErrNotExist):
+		return "file_missing"
+	case errors.Is(e.Err, errOrganizeInvalidTarget):
+		return "invalid_target"
+	default:
+		return "organize_failed"
+	}
+}
+
+// OrganizeNotifier는 자동 정리 동작을 알리는 통로입니다.
+type OrganizeNotifier interface {
+	NotifyOrganize(ctx context.Context, event OrganizeEvent)
+}
+
+// OrganizeNotifierFunc는 함수를 OrganizeNotifier로 씁니다.
+type OrganizeNotifierFunc func(ctx context.Context, event OrganizeEvent)
+
+func (f OrganizeNotifierFunc) NotifyOrganize(ctx context.Context, event OrganizeEvent) {
+	f(ctx, event)
+}
+
+// OrganizeService는 자동 정리 규칙을 관리하고, 파일 이벤트를 받아 규칙을 실행합니다.
+// 자동 정리가 옮긴 파일은 다시 이벤트를 내지 않으므로 규칙끼리 서로를 부르며 돌지 않습니다.
+type OrganizeService struct {
+	spaceService *Service
+	store        OrganizeRuleStorer
+	tags         FileTagStorer
+	mover        OrganizeMover
+	retention    *RetentionService
+	scans        OrganizeScanWaiter
+	notifier     OrganizeNotifier
+	now          func() time.Time
+}
+
+// NewOrganizeService는 규칙과 태그를 메모리에 두는 서비스를 만듭니다. 저장소와 mover는 Set* 로 바꿉니다.
+func NewOrganizeService(spaceService *Service) *OrganizeService {
+	return &OrganizeService{
+		spaceService: spaceService,
+		store:        NewMemoryOrganizeRuleStore(),
+		tags:         NewMemoryFileTagStore(),
+		now:          time.Now,
+	}
+}
+
+func (s *OrganizeService) SetStore(store OrganizeRuleStorer) {
+	if store != nil {
+		s.store = store
+	}
+}
+
+func (s *OrganizeService) SetTagStore(store FileTagStorer) {
+	if store != nil {
+		s.tags = store
+	}
+}
+
+func (s *OrganizeService) SetMover(mover OrganizeMover) {
+	s.mover = mover
+}
+
+// SetRetentionService는 옮기기 전에 확인할 보존 잠금 서비스를 설정합니다.
+func (s *OrganizeService) SetRetentionService(retention *RetentionService) {
+	s.retention = retention
+}
+
+// SetScanWaiter는 옮기기 전에 기다릴 악성코드 검사 서비스를 설정합니다.
+func (s *OrganizeService) SetScanWaiter(waiter OrganizeScanWaiter) {
+	s.scans = waiter
+}
+
+// SetNotifier는 동작마다 결과를 알릴 대상을 설정합니다.
+func (s *OrganizeService) SetNotifier(notifier OrganizeNotifier) {
+	s.notifier = notifier
+}
+
+func (s *OrganizeService) ListRules(ctx context.Context, spaceID int64) ([]*OrganizeRule, error) {
+	return s.store.ListOrganizeRules(ctx, spaceID)
+}
+
+// GetRule은 spaceID에 속한 규칙을 찾습니다. 다른 Space의 규칙이면 ErrOrganizeRuleNotFound입니다.
+func (s *OrganizeService) GetRule(ctx context.Context, spaceID, ruleID int64) (*OrganizeRule, error) {
+	rule, err := s.store.GetOrganizeRule(ctx, ruleID)
+	if err != nil {
+		return nil, err
+	}
+	if rule.SpaceID != spaceID {
+		return nil, ErrOrganizeRuleNotFound
+	}
+	return rule, nil
+}
+
+func (s *OrganizeService) CreateRule(ctx context.Context, spaceID int64, req OrganizeRuleRequest, actor string) (*OrganizeRule, error) {
+	rule, err := req.toRule()
+	if err != nil {
+		return nil, err
+	}
+	now := s.now().UTC()
+	rule.SpaceID = spaceID
+	rule.CreatedBy = actor
+	rule.CreatedAt = now
+	rule.UpdatedAt = now
+	return s.store.CreateOrganizeRule(ctx, rule)
+}
+
+// UpdateRule은 규칙을 req로 통째로 바꾸고 바꾸기 전 규칙도 함께 반환합니다.
+func (s *OrganizeService) UpdateRule(ctx context.Context, spaceID, ruleID int64, req OrganizeRuleRequest) (rule *OrganizeRule, previous *OrganizeRule, err error) {
+	previous, err = s.GetRule(ctx, spaceID, ruleID)
+	if err != nil {
+		return nil, nil, err
+	}
+	rule, err = req.toRule()
+	if err != nil {
+		return nil, nil, err
+	}
+	rule.ID = previous.ID
+	rule.SpaceID = previous.SpaceID
+	rule.CreatedBy = previous.CreatedBy
+	rule.CreatedAt = previous.CreatedAt
+	rule.UpdatedAt = s.now().UTC()
+	if err := s.store.UpdateOrganizeRule(ctx, rule); err != nil {
+		return nil, nil, err
+	}
+	return rule, previous, nil
+}
+
+func (s *OrganizeService) DeleteRule(ctx context.Context, spaceID, ruleID int64) (*OrganizeRule, error) {
+	rule, err := s.GetRule(ctx, spaceID, ruleID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.store.DeleteOrganizeRule(ctx, ruleID); err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+// ListTags는 조건에 맞는 태그를 경로, 태그 순으로 반환합니다. 파일이 없어진 경로의 태그는 지우고 빼냅니다.
+func (s *OrganizeService) ListTags(ctx context.Context, spaceObj *Space, query FileTagQuery) ([]*FileTag, error) {
+	if query.Path != "" {
+		normalized, ok := normalizeOrganizeFolder(query.Path)
+		if !ok || normalized == "" {
+			return nil, fmt.Errorf("%w: invalid path", ErrInvalidFileTagQuery)
+		}
+		query.Path = normalized
+	}
+	query.Tag = strings.TrimSpace(query.Tag)
+
+	tags, err := s.tags.ListFileTags(ctx, spaceObj.ID, query)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*FileTag, 0, len(tags))
+	missing := make(map[string]bool)
+	for _, tag := range tags {
+		gone, seen := missing[tag.Path]
+		if !seen {
+			_, statErr := os.Lstat(filepath.Join(spaceObj.SpacePath, filepath.FromSlash(tag.Path)))
+			gone = os.IsNotExist(statErr)
+			missing[tag.Path] = gone
+			if gone {
+				if err := s.tags.DeleteFileTags(ctx, spaceObj.ID, tag.Path); err != nil {
+					logging.Event(log.Warn(), logging.ComponentStorage, "warn.space.file_tags_prune_failed").
+						Err(err).
+						Int64("space_id", spaceObj.ID).
+						Str("path", tag.Path).
+						Msg("failed to drop tags of missing file")
+				}
+			}
+		}
+		if !gone {
+			result = append(result, tag)
+		}
+	}
+	return result, nil
+}
+
+// HandleFileEvent는 FileEventSubscriber를 구현합니다. 새로 놓인 파일에 처음 맞는 규칙을 실행합니다.
+func (s *OrganizeService) HandleFileEvent(ctx context.Context, event FileEvent) {
+	if event.Kind != FileEventCreated {
+		return
+	}
+	if err := s.organize(ctx, event); err != nil {
+		logging.Event(log.Warn(), logging.ComponentStorage, "warn.space.organize_failed").
+			Err(err).
+			Int64("space_id", event.SpaceID).
+			Str("source", event.Source).
+			Msg("failed to organize new file")
+	}
+}
+
+func (s *OrganizeService) organize(ctx context.Context, event FileEvent) error {
+	relPath, ok := organizeRelPath(event.SpaceRoot, event.AbsPath)
+	if !ok || isOrganizeInternalPath(relPath) {
+		return nil
+	}
+	rules, err := s.store.ListOrganizeRules(ctx, event.SpaceID)
+	if err != nil {
+		return err
+	}
+	if !slices.ContainsFunc(rules, func(rule *OrganizeRule) bool { return rule.Enabled }) {
+		return nil
+	}
+	spaceObj, err := s.spaceService.GetSpaceByID(ctx, event.SpaceID)
+	if err != nil {
+		return err
+	}
+	// 이벤트를 처리하기 전에 Space 경로가 바뀌었으면 경로를 다시 맞출 수 없으므로 건너뜁니다.
+	if filepath.Clean(spaceObj.SpacePath) != filepath.Clean(event.SpaceRoot) {
+		return nil
+	}
+	info, err := os.Lstat(event.AbsPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	if !info.Mode().IsRegular() {
+		return nil
+	}
+
+	file := &organizeFile{relPath: relPath, absPath: event.AbsPath, info: info}
+	for _, rule := range rules {
+		if rule.Enabled && rule.watches(relPath) && rule.Conditions.matches(file) {
+			return s.apply(ctx, spaceObj, rule, file, event)
+		}
+	}
+	return nil
+}
+
+// apply는 규칙의 동작을 차례로 실행합니다. 한 동작이 실패하면 알리고 나머지는 실행하지 않습니다.
+func (s *OrganizeService) apply(ctx context.Context, spaceObj *Space, rule *OrganizeRule, file *organizeFile, event FileEvent) error {
+	current := file.relPath
+	for _, action := range rule.Actions {
+		result := OrganizeEvent{
+			Rule:     *rule,
+			SpaceID:  spaceObj.ID,
+			Action:   action.Type,
+			Path:     current,
+			Uploader: event.Actor,
+			Source:   event.Source,
+		}
+		values := file.templateValues(path.Base(current), event.Actor)
+
+		switch action.Type {
+		case OrganizeActionMove, OrganizeActionRename:
+			target, err := s.relocate(ctx, spaceObj, current, action, values)
+			result.Destination = target
+			if err != nil {
+				result.Err = err
+				s.notify(ctx, result)
+				return err
+			}
+			if target == current {
+				continue
+			}
+			current = target
+		case OrganizeActionTag:
+			now := s.now().UTC()
+			tags := make([]FileTag, 0, len(action.Tags))
+			for _, tag := range action.Tags {
+				tags = append(tags, FileTag{SpaceID: spaceObj.ID, Path: current, Tag: tag, CreatedBy: OrganizeActor, CreatedAt: now})
+			}
+			result.Tags = action.Tags
+			if err := s.tags.AddFileTags(ctx, tags); err != nil {
+				result.Err = err
+				s.notify(ctx, result)
+				return err
+			}
+		case OrganizeActionNotify:
+			result.Message = renderOrganizeTemplate(action.Message, values)
+		}
+		s.notify(ctx, result)
+	}
+	return nil
+}
+
+// relocate는 move/rename 대상 경로를 정하고 옮깁니다. 같은 이름이 있으면 " (n)"을 붙입니다.
+// 대상이 지금 경로와 같으면 아무것도 하지 않고 current를 반환합니다.
+func (s *OrganizeService) relocate(ctx context.Context, spaceObj *Space, current string, action OrganizeAction, values map[string]string) (string, error) {
+	var target string
+	switch action.Type {
+	case OrganizeActionMove:
+		folder, ok := normalizeOrganizeFolder(renderOrganizeTemplate(action.Destination, values))
+		if !ok || isOrganizeInternalPath(folder) {
+			return "", fmt.Errorf("%w: destination %q is not a folder inside the Space", errOrganizeInvalidTarget, action.Destination)
+		}
+		target = path.Join(folder, path.Base(current))
+	default:
+		name := renderOrganizeTemplate(action.Template, values)
+		if !validOrganizeFileName(name) {
+			return "", fmt.Errorf("%w: template %q produced invalid file name %q", errOrganizeInvalidTarget, action.Template, name)
+		}
+		target = path.Join(path.Dir(current), name)
+	}
+	if target == current {
+		return current, nil
+	}
+
+	if s.mover == nil {
+		return "", errors.New("organize mover is not configured")
+	}
+	// 옮기는 동안 저장소 이전이 root를 바꾸지 못하게 하고, 그 사이 바뀐 root가 있으면 새 root를 씁니다.
+	spaceObj, release, err := s.spaceService.BeginWrite(ctx, spaceObj.ID)
+	if err != nil {
+		return "", err
+	}
+	defer release()
+	absCurrent := filepath.Join(spaceObj.SpacePath, filepath.FromSlash(current))
+	if s.scans != nil {
+		if err := s.scans.WaitForScan(ctx, absCurrent); err != nil {
+			return "", err
+		}
+		if _, err := os.Lstat(absCurrent); err != nil {
+			// 검사 결과 격리되었거나 그 사이 지워진 파일입니다.
+			return "", err
+		}
+	}
+	target, err = uniqueOrganizePath(spaceObj.SpacePath, target)
+	if err != nil {
+		return "", err
+	}
+	operation := string(action.Type)
+	if err := s.retention.EnsureMutable(ctx, s.retentionTarget(spaceObj, current, operation)); err != nil {
+		return target, err
+	}
+	if err := s.retention.EnsureMutable(ctx, s.retentionTarget(spaceObj, target, operation)); err != nil {
+		return target, err
+	}
+	if err := s.mover.MoveOrganizedFile(ctx, spaceObj, current, target); err != nil {
+		return target, err
+	}
+	return target, nil
+}
+
+func (s *OrganizeService) retentionTarget(spaceObj *Space, relPath, operation string) RetentionTarget {
+	return RetentionTarget{
+		SpaceID:   spaceObj.ID,
+		SpaceRoot: spaceObj.SpacePath,
+		AbsPath:   filepath.Join(spaceObj.SpacePath, filepath.FromSlash(relPath)),
+		Operation: operation,
+		Actor:     OrganizeActor,
+		Source:    "organize",
+	}
+}
+
+func (s *OrganizeService) notify(ctx context.Context, event OrganizeEvent) {
+	if s.notifier != nil {
+		s.notifier.NotifyOrganize(context.WithoutCancel(ctx), event)
+	}
+}
+
+// organizeFile은 규칙을 평가하는 동안 파일 정보와 촬영 시각을 한 번만 읽도록 담아 둡니다.
+type organizeFile struct {
+	relPath string
+	absPath string
+	info    os.FileInfo
+
+	takenLoaded bool
+	taken       *time.Time
+}
+
+// takenAt은 EXIF 촬영 시각입니다. 없으면 nil입니다.
+func (f *organizeFile) takenAt() *time.Time {
+	if !f.takenLoaded {
+		f.takenLoaded = true
+		if taken, ok, err := exif.DateTaken(f.absPath); err == nil && ok {
+			f.taken = &taken
+		}
+	}
+	return f.taken
+}
+
+func (f *organizeFile) templateValues(name, uploader string) map[string]string {
+	date := f.info.ModTime().UTC()
+	if taken := f.takenAt(); taken != nil {
+		date = *taken
+	}
+	ext := fileExtension(name)
+	stem := name
+	if ext != "" {
+		stem = strings.TrimSuffix(name, "."+ext)
+	}
+	if uploader == "" {
+		uploader = "unknown"
+	}
+	return map[string]string{
+		"yyyy":     date.Format("2006"),
+		"mm":       date.Format("01"),
+		"dd":       date.Format("02"),
+		"name":     stem,
+		"ext":      ext,
+		"filename": name,
+		"uploader": uploader,
+	}
+}
+
+func (r *OrganizeRule) watches(relPath string) bool {
+	dir := path.Dir(relPath)
+	if dir == "." {
+		dir = ""
+	}
+	if dir == r.WatchPath {
+		return true
+	}
+	if !r.IncludeSubfolders {
+		return false
+	}
+	return r.WatchPath == "" || strings.HasPrefix(dir, r.WatchPath+"/")
+}
+
+func (c OrganizeConditions) matches(file *organizeFile) bool {
+	name := strings.ToLower(file.info.Name())
+	if c.NamePattern != "" {
+		if matched, err := path.Match(c.NamePattern, name); err != nil || !matched {
+			return false
+		}
+	}
+	if len(c.Extensions) > 0 && matchUploadPolicyExtension(name, c.Extensions) == "" {
+		return false
+	}
+	size := file.info.Size()
+	if c.MinSize != nil && size < *c.MinSize {
+		return false
+	}
+	if c.MaxSize != nil && size > *c.MaxSize {
+		return false
+	}
+	if c.TakenAfter != nil || c.TakenBefore != nil {
+		taken := file.takenAt()
+		if taken == nil {
+			return false
+		}
+		if c.TakenAfter != nil && taken.Before(*c.TakenAfter) {
+			return false
+		}
+		if c.TakenBefore != nil && !taken.Before(*c.TakenBefore) {
+			return false
+		}
+	}
+	return true
+}
+
+func (req OrganizeRuleRequest) toRule() (*OrganizeRule, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" || len([]rune(name)) > MaxOrganizeRuleNameLength {
+		return nil, fmt.Errorf("%w: name must be 1 to %d characters", ErrInvalidOrganizeRule, MaxOrganizeRuleNameLength)
+	}
+	watchPath, ok := normalizeOrganizeFolder(req.WatchPath)
+	if !ok || isOrganizeInternalPath(watchPath) {
+		return nil, fmt.Errorf("%w: watchPath must be a folder inside the Space", ErrInvalidOrganizeRule)
+	}
+	conditions, err := req.Conditions.normalize()
+	if err != nil {
+		return nil, err
+	}
+	if len(req.Actions) == 0 || len(req.Actions) > MaxOrganizeActions {
+		return nil, fmt.Errorf("%w: actions must have 1 to %d items", ErrInvalidOrganizeRule, MaxOrganizeActions)
+	}
+	actions := make([]OrganizeAction, 0, len(req.Actions))
+	for _, action := range req.Actions {
+		normalized, err := action.normalize()
+		if err != nil {
+			return nil, err
+		}
+		actions = append(actions, normalized)
+	}
+	enabled := true
+	if req.Enabled != nil {
+		enabled = *req.Enabled
+	}
+	return &OrganizeRule{
+		Name:              name,
+		WatchPath:         watchPath,
+		IncludeSubfolders: req.IncludeSubfolders,
+		Conditions:        conditions,
+		Actions:           actions,
+		Enabled:           enabled,
+	}, nil
+}
+
+func (c OrganizeConditions) normalize() (OrganizeConditions, error) {
+	normalized := OrganizeConditions{
+		NamePattern: strings.ToLower(strings.TrimSpace(c.NamePattern)),
+		MinSize:     c.MinSize,
+		MaxSize:     c.MaxSize,
+	}
+	if _, err := path.Match(normalized.NamePattern, ""); err != nil || strings.Contains(normalized.NamePattern, "/") {
+		return normalized, fmt.Errorf("%w: invalid namePattern %q", ErrInvalidOrganizeRule, c.NamePattern)
+	}
+	if len(c.Extensions) > 0 {
+		extensions, err := normalizeUploadPolicyExtensions(c.Extensions)
+		if err != nil {
+			return normalized, fmt.Errorf("%w: %v", ErrInvalidOrganizeRule, err)
+		}
+		normalized.Extensions = extensions
+	}
+	if (c.MinSize != nil && *c.MinSize < 0) || (c.MaxSize != nil && *c.MaxSize < 0) {
+		return normalized, fmt.Errorf("%w: size limits must not be negative", ErrInvalidOrganizeRule)
+	}
+	if c.MinSize != nil && c.MaxSize != nil && *c.MinSize > *c.MaxSize {
+		return normalized, fmt.Errorf("%w: minSize must not exceed maxSize", ErrInvalidOrganizeRule)
+	}
+	if c.TakenAfter != nil {
+		value := c.TakenAfter.UTC()
+		normalized.TakenAfter = &value
+	}
+	if c.TakenBefore != nil {
+		value := c.TakenBefore.UTC()
+		normalized.TakenBefore = &value
+	}
+	if normalized.TakenAfter != nil && normalized.TakenBefore != nil && !normalized.TakenAfter.Before(*normalized.TakenBefore) {
+		return normalized, fmt.Errorf("%w: takenAfter must be before takenBefore", ErrInvalidOrganizeRule)
+	}
+	return normalized, nil
+}
+
+func (a OrganizeAction) normalize() (OrganizeAction, error) {
+	switch a.Type {
+	case OrganizeActionMove:
+		destination := strings.TrimSpace(a.Destination)
+		if err := validateOrganizeTemplate(destination); err != nil {
+			return a, err
+		}
+		// 자리표시자를 채우기 전에도 Space 안 폴더여야 합니다. 자리표시자 값에는 "/"가 들어가지 않습니다.
+		folder, ok := normalizeOrganizeFolder(destination)
+		if !ok || isOrganizeInternalPath(folder) {
+			return a, fmt.Errorf("%w: destination must be a folder inside the Space", ErrInvalidOrganizeRule)
+		}
+		return OrganizeAction{Type: a.Type, Destination: folder}, nil
+	case OrganizeActionRename:
+		template := strings.TrimSpace(a.Template)
+		if err := validateOrganizeTemplate(template); err != nil {
+			return a, err
+		}
+		if template == "" || strings.ContainsAny(template, `/\`) {
+			return a, fmt.Errorf("%w: rename template must be a file name", ErrInvalidOrganizeRule)
+		}
+		return OrganizeAction{Type: a.Type, Template: template}, nil
+	case OrganizeActionTag:
+		tags := make([]string, 0, len(a.Tags))
+		for _, tag := range a.Tags {
+			tag = strings.TrimSpace(tag)
+			if tag == "" || len([]rune(tag)) > MaxFileTagLength || strings.ContainsAny(tag, ",\n\r") {
+				return a, fmt.Errorf("%w: invalid tag %q", ErrInvalidOrganizeRule, tag)
+			}
+			if !slices.Contains(tags, tag) {
+				tags = append(tags, tag)
+			}
+		}
+		if len(tags) == 0 || len(tags) > MaxOrganizeTags {
+			return a, fmt.Errorf("%w: tag action needs 1 to %d tags", ErrInvalidOrganizeRule, MaxOrganizeTags)
+		}
+		slices.Sort(tags)
+		return OrganizeAction{Type: a.Type, Tags: tags}, nil
+	case OrganizeActionNotify:
+		message := strings.TrimSpace(a.Message)
+		if len([]rune(message)) > MaxOrganizeMessageLength {
+			return a, fmt.Errorf("%w: message must be at most %d characters", ErrInvalidOrganizeRule, MaxOrganizeMessageLength)
+		}
+		if err := validateOrganizeTemplate(message); err != nil {
+			return a, err
+		}
+		return OrganizeAction{Type: a.Type, Message: message}, nil
+	default:
+		return a, fmt.Errorf("%w: unknown action type %q", ErrInvalidOrganizeRule, a.Type)
+	}
+}
+
+// validateOrganizeTemplate은 중괄호 짝과 자리표시자 이름을 확인합니다.
+func validateOrganizeTemplate(template string) error {
+	rest := template
+	for {
+		open := strings.IndexAny(rest, "{}")
+		if open < 0 {
+			return nil
+		}
+		if rest[open] == '}' {
+			return fmt.Errorf("%w: unmatched '}' in template %q", ErrInvalidOrganizeRule, template)
+		}
+		end := strings.IndexByte(rest[open:], '}')
+		if end < 0 {
+			return fmt.Errorf("%w: unmatched '{' in template %q", ErrInvalidOrganizeRule, template)
+		}
+		field := rest[open+1 : open+end]
+		if !slices.Contains(organizeTemplateFields, field) {
+			return fmt.Errorf("%w: unknown placeholder {%s}", ErrInvalidOrganizeRule, field)
+		}
+		rest = rest[open+end+1:]
+	}
+}
+
+func renderOrganizeTemplate(template string, values map[string]string) string {
+	replacements := make([]string, 0, len(values)*2)
+	for key, value := range values {
+		replacements = append(replacements, "{"+key+"}", value)
+	}
+	return strings.NewReplacer(replacements...).Replace(template)
+}
+
+// normalizeOrganizeFolder는 Space 루트 기준 폴더 경로를 정리합니다. 루트는 ""이고, ".."로 루트를 벗어나면 false입니다.
+func normalizeOrganizeFolder(value string) (string, bool) {
+	value = strings.TrimSpace(strings.ReplaceAll(value, `\`, "/"))
+	cleaned := path.Clean("/" + value)
+	for _, segment := range strings.Split(value, "/") {
+		if segment == ".." {
+			return "", false
+		}
+	}
+	return strings.TrimPrefix(cleaned, "/"), true
+}
+
+// isOrganizeInternalPath는 휴지통과 같은 내부 폴더(.cohesion*) 안 경로인지 확인합니다.
+func isOrganizeInternalPath(relPath string) bool {
+	for _, segment := range strings.Split(relPath, "/") {
+		if strings.HasPrefix(segment, ".cohesion") {
+			return true
+		}
+	}
+	return false
+}
+
+func validOrganizeFileName(name string) bool {
+	return name != "" && name != "." && name != ".." &&
+		!strings.ContainsAny(name, "/\\\x00") && !strings.HasPrefix(name, ".cohesion")
+}
+
+func organizeRelPath(spaceRoot, absPath string) (string, bool) {
+	rel, err := filepath.Rel(spaceRoot, absPath)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	return filepath.ToSlash(rel), true
+}
+
+// uniqueOrganizePath는 relPath에 이미 무언가 있으면 "name (n).ext" 중 비어 있는 경로를 찾습니다.
+func uniqueOrganizePath(spaceRoot, relPath string) (string, error) {
+	exists := func(rel string) bool {
+		_, err := os.Lstat(filepath.Join(spaceRoot, filepath.FromSlash(rel)))
+		return !os.IsNotExist(err)
+	}
+	if !exists(relPath) {
+		return relPath, nil
+	}
+	dir, name := path.Split(relPath)
+	ext := ""
+	if e := fileExtension(name); e != "" {
+		ext = "." + e
+	}
+	stem := strings.TrimSuffix(name, ext)
+	for n := 1; n <= maxOrganizeNameSuffix; n++ {
+		candidate := dir + fmt.Sprintf("%s (%d)%s", stem, n, ext)
+		if !exists(candidate) {
+			return candidate, nil
+		}
+	}
+	return "", fmt.Errorf("no free name for %q", relPath)
+}
+
+// MemoryOrganizeRuleStore는 프로세스 메모리에만 규칙을 두는 저장소입니다.
+type MemoryOrganizeRuleStore struct {
+	mu     sync.RWMutex
+	nextID int64
+	rules  map[int64]OrganizeRule
+}
+
+func NewMemoryOrganizeRuleStore() *MemoryOrganizeRuleStore {
+	return &MemoryOrganizeRuleStore{rules: make(map[int64]OrganizeRule)}
+}
+
+func (s *MemoryOrganizeRuleStore) ListOrganizeRules(ctx context.Context, spaceID int64) ([]*OrganizeRule, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	rules := make([]*OrganizeRule, 0, len(s.rules))
+	for _, rule := range s.rules {
+		if rule.SpaceID != spaceID {
+			continue
+		}
+		rule := rule
+		rules = append(rules, &rule)
+	}
+	sort.Slice(rules, func(i, j int) bool { return rules[i].ID < rules[j].ID })
+	return rules, nil
+}
+
+func (s *MemoryOrganizeRuleStore) GetOrganizeRule(ctx context.Context, id int64) (*OrganizeRule, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	rule, ok := s.rules[id]
+	if !ok {
+		return nil, ErrOrganizeRuleNotFound
+	}
+	return &rule, nil
+}
+
+func (s *MemoryOrganizeRuleStore) CreateOrganizeRule(ctx context.Context, rule *OrganizeRule) (*OrganizeRule, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.nextID++
+	created := *rule
+	created.ID = s.nextID
+	s.rules[created.ID] = created
+	return &created, nil
+}
+
+func (s *MemoryOrganizeRuleStore) UpdateOrganizeRule(ctx context.Context, rule *OrganizeRule) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.rules[rule.ID]; !ok {
+		return ErrOrganizeRuleNotFound
+	}
+	s.rules[rule.ID] = *rule
+	return nil
+}
+
+func (s *MemoryOrganizeRuleStore) DeleteOrganizeRule(ctx context.Context, id int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.rules[id]; !ok {
+		return ErrOrganizeRuleNotFound
+	}
+	delete(s.rules, id)
+	return nil
+}
+
+var _ OrganizeRuleStorer = (*MemoryOrganizeRuleStore)(nil)
+
+// MemoryFileTagStore는 프로세스 메모리에만 태그를 두는 저장소입니다.
+type MemoryFileTagStore struct {
+	mu   sync.RWMutex
+	tags map[int64]map[string]map[string]FileTag
+}
+
+func NewMemoryFileTagStore() *MemoryFileTagStore {
+	return &MemoryFileTagStore{tags: make(map[int64]map[string]map[string]FileTag)}
+}
+
+func (s *MemoryFileTagStore) AddFileTags(ctx context.Context, tags []FileTag) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, tag := range tags {
+		paths, ok := s.tags[tag.SpaceID]
+		if !ok {
+			paths = make(map[string]map[string]FileTag)
+			s.tags[tag.SpaceID] = paths
+		}
+		byTag, ok := paths[tag.Path]
+		if !ok {
+			byTag = make(map[string]FileTag)
+			paths[tag.Path] = byTag
+		}
+		if _, exists := byTag[tag.Tag]; !exists {
+			byTag[tag.Tag] = tag
+		}
+	}
+	return nil
+}
+
+func (s *MemoryFileTagStore) ListFileTags(ctx context.Context, spaceID int64, query FileTagQuery) ([]*FileTag, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	result := make([]*FileTag, 0)
+	for filePath, byTag := range s.tags[spaceID] {
+		if query.Path != "" && filePath != query.Path {
+			continue
+		}
+		for name, tag := range byTag {
+			if query.Tag != "" && name != query.Tag {
+				continue
+			}
+			tag := tag
+			result = append(result, &tag)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Path != result[j].Path {
+			return result[i].Path < result[j].Path
+		}
+		return result[i].Tag < result[j].Tag
+	})
+	return result, nil
+}
+
+func (s *MemoryFileTagStore) DeleteFileTags(ctx context.Context, spaceID int64, path string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.tags[spaceID], path)
+	return nil
+}
+
+var _ FileTagStorer = (*MemoryFileTagStore)(nil)
+var _ FileEventSubscriber = (*OrganizeService)(nil)
@@ -0,0 +1,244 @@
+package space_test
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"taeu.kr/cohesion/internal/space"
+	spaceStore "taeu.kr/cohesion/internal/space/store"
+)
+
+type renameOrganizeMover struct{}
+
+func (renameOrganizeMover) MoveOrganizedFile(ctx context.Context, spaceObj *space.Space, fromRel, toRel string) error {
+	from := filepath.Join(spaceObj.SpacePath, filepath.FromSlash(fromRel))
+	to := filepath.Join(spaceObj.SpacePath, filepath.FromSlash(toRel))
+	if _, err := os.Lstat(to); err == nil {
+		return os.ErrExist
+	}
+	if err := os.MkdirAll(filepath.Dir(to), 0o755); err != nil {
+		return err
+	}
+	return os.Rename(from, to)
+}
+
+// exifJPEG는 DateTimeOriginal만 있는 최소 JPEG입니다.
+func exifJPEG(taken string) []byte {
+	order := binary.BigEndian
+	tiff := make([]byte, 44)
+	copy(tiff, "MM")
+	order.PutUint16(tiff[2:], 42)
+	order.PutUint32(tiff[4:], 8)
+	order.PutUint16(tiff[8:], 1)
+	order.PutUint16(tiff[10:], 0x8769)
+	order.PutUint16(tiff[12:], 4)
+	order.PutUint32(tiff[14:], 1)
+	order.PutUint32(tiff[18:], 26)
+	value := append([]byte(taken), 0)
+	order.PutUint16(tiff[26:], 1)
+	order.PutUint16(tiff[28:], 0x9003)
+	order.PutUint16(tiff[30:], 2)
+	order.PutUint32(tiff[32:], uint32(len(value)))
+	order.PutUint32(tiff[36:], 44)
+	tiff = append(tiff, value...)
+
+	segment := append([]byte("Exif\x00\x00"), tiff...)
+	data := []byte{0xFF, 0xD8, 0xFF, 0xE1}
+	data = binary.BigEndian.AppendUint16(data, uint16(len(segment)+2))
+	data = append(data, segment...)
+	return append(data, 0xFF, 0xD9)
+}
+
+func TestOrganizeService_MovesRenamesAndTagsMatchingUploads(t *testing.T) {
+	service, db := setupSlugSpaceService(t)
+	ctx := context.Background()
+	root := t.TempDir()
+	created, err := service.CreateSpace(ctx, &space.CreateSpaceRequest{SpaceName: "Phone", SpacePath: root})
+	if err != nil {
+		t.Fatalf("create space: %v", err)
+	}
+
+	writeFile := func(rel string, data []byte, modTime time.Time) string {
+		t.Helper()
+		absPath := filepath.Join(root, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(absPath), 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(absPath, data, 0o644); err != nil {
+			t.Fatalf("write file: %v", err)
+		}
+		if err := os.Chtimes(absPath, modTime, modTime); err != nil {
+			t.Fatalf("chtimes: %v", err)
+		}
+		return absPath
+	}
+	exists := func(rel string) bool {
+		_, err := os.Lstat(filepath.Join(root, filepath.FromSlash(rel)))
+		return err == nil
+	}
+
+	organize := space.NewOrganizeService(service)
+	organize.SetStore(spaceStore.NewOrganizeRuleStore(db))
+	organize.SetTagStore(spaceStore.NewFileTagStore(db))
+	organize.SetMover(renameOrganizeMover{})
+	var events []space.OrganizeEvent
+	organize.SetNotifier(space.OrganizeNotifierFunc(func(ctx context.Context, event space.OrganizeEvent) {
+		events = append(events, event)
+	}))
+
+	invalid := []space.OrganizeRuleRequest{
+		{Name: "no actions", WatchPath: "Camera"},
+		{Name: "unknown field", WatchPath: "Camera", Actions: []space.OrganizeAction{{Type: space.OrganizeActionMove, Destination: "Photos/{year}"}}},
+		{Name: "escape", WatchPath: "Camera", Actions: []space.OrganizeAction{{Type: space.OrganizeActionMove, Destination: "../outside"}}},
+		{Name: "slash in name", WatchPath: "Camera", Actions: []space.OrganizeAction{{Type: space.OrganizeActionRename, Template: "{yyyy}/{name}"}}},
+		{Name: "bad glob", WatchPath: "Camera", Conditions: space.OrganizeConditions{NamePattern: "[img"}, Actions: []space.OrganizeAction{{Type: space.OrganizeActionTag, Tags: []string{"x"}}}},
+	}
+	for _, req := range invalid {
+		if _, err := organize.CreateRule(ctx, created.ID, req, "alice"); !errors.Is(err, space.ErrInvalidOrganizeRule) {
+			t.Fatalf("%s: expected invalid rule, got %v", req.Name, err)
+		}
+	}
+
+	after := time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC)
+	photos, err := organize.CreateRule(ctx, created.ID, space.OrganizeRuleRequest{
+		Name:       "Camera photos",
+		WatchPath:  "/Camera/",
+		Conditions: space.OrganizeConditions{NamePattern: "IMG_*", Extensions: []string{".JPG"}, TakenAfter: &after},
+		Actions: []space.OrganizeAction{
+			{Type: space.OrganizeActionMove, Destination: "Photos/{yyyy}/{mm}"},
+			{Type: space.OrganizeActionTag, Tags: []string{"photo", "phone"}},
+			{Type: space.OrganizeActionNotify, Message: "{filename} from {uploader}"},
+		},
+	}, "alice")
+	if err != nil {
+		t.Fatalf("create photo rule: %v", err)
+	}
+	if photos.WatchPath != "Camera" || !photos.Enabled || photos.Conditions.NamePattern != "img_*" || photos.Conditions.Extensions[0] != "jpg" {
+		t.Fatalf("unexpected normalized rule: %+v", photos)
+	}
+	if _, err := organize.CreateRule(ctx, created.ID, space.OrganizeRuleRequest{
+		Name:      "Everything else",
+		WatchPath: "Camera",
+		Actions:   []space.OrganizeAction{{Type: space.OrganizeActionRename, Template: "{yyyy}-{mm}-{dd} {name}.{ext}"}},
+	}, "alice"); err != nil {
+		t.Fatalf("create fallback rule: %v", err)
+	}
+
+	publish := func(absPath string) {
+		organize.HandleFileEvent(ctx, space.FileEvent{
+			Kind:      space.FileEventCreated,
+			SpaceID:   created.ID,
+			SpaceRoot: root,
+			AbsPath:   absPath,
+			Actor:     "bob",
+			Source:    "webdav",
+		})
+	}
+
+	modTime := time.Date(2024, time.March, 2, 12, 0, 0, 0, time.UTC)
+	writeFile("Photos/2023/07/IMG_0001.jpg", []byte("existing"), modTime)
+	publish(writeFile("Camera/IMG_0001.jpg", exifJPEG("2023:07:14 09:30:00"), modTime))
+
+	if exists("Camera/IMG_0001.jpg") || !exists("Photos/2023/07/IMG_0001 (1).jpg") {
+		t.Fatal("expected photo to move into its EXIF month with a free name")
+	}
+	if len(events) != 3 || events[0].Action != space.OrganizeActionMove || events[0].Destination != "Photos/2023/07/IMG_0001 (1).jpg" ||
+		events[2].Message != "IMG_0001 (1).jpg from bob" || events[2].Source != "webdav" || events[2].Rule.ID != photos.ID {
+		t.Fatalf("unexpected organize events: %+v", events)
+	}
+	for _, event := range events {
+		if event.Err != nil || event.Reason() != "" {
+			t.Fatalf("unexpected failed event: %+v", event)
+		}
+	}
+
+	// EXIF가 없으면 촬영 시각 조건에 걸리지 않아 다음 규칙이 수정 날짜로 이름을 바꿉니다.
+	publish(writeFile("Camera/IMG_0002.jpg", []byte("no exif"), modTime))
+	if !exists("Camera/2024-03-02 IMG_0002.jpg") {
+		t.Fatal("expected file without EXIF to fall through to the rename rule")
+	}
+	// 하위 폴더는 IncludeSubfolders가 없으면 보지 않습니다.
+	publish(writeFile("Camera/nested/IMG_0003.jpg", exifJPEG("2023:07:14 09:30:00"), modTime))
+	if !exists("Camera/nested/IMG_0003.jpg") {
+		t.Fatal("expected nested file to be left alone")
+	}
+
+	spaceObj, err := service.GetSpaceByID(ctx, created.ID)
+	if err != nil {
+		t.Fatalf("get space: %v", err)
+	}
+	tags, err := organize.ListTags(ctx, spaceObj, space.FileTagQuery{Tag: "photo"})
+	if err != nil {
+		t.Fatalf("list tags: %v", err)
+	}
+	if len(tags) != 1 || tags[0].Path != "Photos/2023/07/IMG_0001 (1).jpg" || tags[0].CreatedBy != space.OrganizeActor {
+		t.Fatalf("unexpected tags: %+v", tags)
+	}
+	if err := os.Remove(filepath.Join(root, "Photos", "2023", "07", "IMG_0001 (1).jpg")); err != nil {
+		t.Fatalf("remove tagged file: %v", err)
+	}
+	tags, err = organize.ListTags(ctx, spaceObj, space.FileTagQuery{})
+	if err != nil {
+		t.Fatalf("list tags after removal: %v", err)
+	}
+	if len(tags) != 0 {
+		t.Fatalf("expected tags of removed file to be dropped, got %+v", tags)
+	}
+	if _, err := organize.ListTags(ctx, spaceObj, space.FileTagQuery{Path: "../etc"}); !errors.Is(err, space.ErrInvalidFileTagQuery) {
+		t.Fatalf("expected invalid tag query, got %v", err)
+	}
+}
+
+func TestOrganizeService_RetentionLockStopsRuleAndReportsFailure(t *testing.T) {
+	service, _ := setupSlugSpaceService(t)
+	ctx := context.Background()
+	root := t.TempDir()
+	created, err := service.CreateSpace(ctx, &space.CreateSpaceRequest{SpaceName: "Inbox", SpacePath: root})
+	if err != nil {
+		t.Fatalf("create space: %v", err)
+	}
+
+	if err := os.Mkdir(filepath.Join(root, "Held"), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	retention := space.NewRetentionService(nil)
+	if _, _, err := retention.SaveLock(ctx, created.ID, root, space.RetentionLockRequest{Path: "Held", LegalHold: true}, "alice"); err != nil {
+		t.Fatalf("save lock: %v", err)
+	}
+	organize := space.NewOrganizeService(service)
+	organize.SetMover(renameOrganizeMover{})
+	organize.SetRetentionService(retention)
+	var events []space.OrganizeEvent
+	organize.SetNotifier(space.OrganizeNotifierFunc(func(ctx context.Context, event space.OrganizeEvent) {
+		events = append(events, event)
+	}))
+	if _, err := organize.CreateRule(ctx, created.ID, space.OrganizeRuleRequest{
+		Name:      "Archive held files",
+		WatchPath: "Held",
+		Actions: []space.OrganizeAction{
+			{Type: space.OrganizeActionMove, Destination: "Archive"},
+			{Type: space.OrganizeActionTag, Tags: []string{"archived"}},
+		},
+	}, "alice"); err != nil {
+		t.Fatalf("create rule: %v", err)
+	}
+
+	absPath := filepath.Join(root, "Held", "report.pdf")
+	if err := os.WriteFile(absPath, []byte("report"), 0o644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+	organize.HandleFileEvent(ctx, space.FileEvent{Kind: space.FileEventCreated, SpaceID: created.ID, SpaceRoot: root, AbsPath: absPath, Actor: "bob", Source: "rest"})
+
+	if _, err := os.Stat(absPath); err != nil {
+		t.Fatalf("expected file to stay in place: %v", err)
+	}
+	var lockedErr *space.RetentionLockedError
+	if len(events) != 1 || !errors.As(events[0].Err, &lockedErr) || events[0].Reason() != "retention_locked" || events[0].Destination != "Archive/report.pdf" {
+		t.Fatalf("expected a single retention failure event, got %+v", events)
+	}
+}
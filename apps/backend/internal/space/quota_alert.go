@@ -0,0 +1,189 @@
+package space
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"taeu.kr/cohesion/internal/platform/logging"
+)
+
+// QuotaMetric은 할당량을 거는 기준입니다.
+type QuotaMetric string
+
+const (
+	QuotaMetricBytes QuotaMetric = "bytes"
+	QuotaMetricFiles QuotaMetric = "files"
+)
+
+// QuotaWarning은 사용량이 넘어선 경고 비율입니다.
+type QuotaWarning struct {
+	Metric QuotaMetric `json:"metric"`
+	// ThresholdPercent는 넘어선 경고 비율 중 가장 높은 값입니다.
+	ThresholdPercent int   `json:"thresholdPercent"`
+	Used             int64 `json:"used"`
+	Quota            int64 `json:"quota"`
+}
+
+// QuotaThresholdEvent는 사용량이 경고 비율을 새로 넘었을 때 알리는 내용입니다.
+type QuotaThresholdEvent struct {
+	SpaceID          int64
+	SpaceName        string
+	Metric           QuotaMetric
+	ThresholdPercent int
+	// PreviousPercent는 직전에 알린 경고 비율입니다. 처음 넘었으면 0입니다.
+	PreviousPercent int
+	Used            int64
+	Quota           int64
+	At              time.Time
+}
+
+// QuotaAlertNotifier는 경고 비율을 새로 넘은 Space를 알리는 통로입니다.
+type QuotaAlertNotifier interface {
+	NotifyQuotaThreshold(ctx context.Context, event QuotaThresholdEvent)
+}
+
+// QuotaAlertNotifierFunc는 함수를 QuotaAlertNotifier로 씁니다.
+type QuotaAlertNotifierFunc func(ctx context.Context, event QuotaThresholdEvent)
+
+func (f QuotaAlertNotifierFunc) NotifyQuotaThreshold(ctx context.Context, event QuotaThresholdEvent) {
+	f(ctx, event)
+}
+
+// QuotaAlertStorer는 Space·기준별로 마지막으로 알린 경고 비율을 저장합니다.
+// 사용량이 내려가면 더 낮은 값으로 바꿔 다시 넘을 때 또 알릴 수 있게 합니다.
+type QuotaAlertStorer interface {
+	// GetQuotaAlertLevel은 기록이 없으면 0을 반환합니다.
+	GetQuotaAlertLevel(ctx context.Context, spaceID int64, metric QuotaMetric) (int, error)
+	SaveQuotaAlertLevel(ctx context.Context, spaceID int64, metric QuotaMetric, percent int, at time.Time) error
+}
+
+// SetQuotaAlertStore는 경고 알림 상태 저장소를 교체합니다. 기본값은 메모리 저장소입니다.
+func (s *QuotaService) SetQuotaAlertStore(store QuotaAlertStorer) {
+	s.alerts = store
+}
+
+// SetQuotaAlertNotifier는 경고 비율을 넘었을 때 로그와 함께 알릴 대상을 설정합니다.
+func (s *QuotaService) SetQuotaAlertNotifier(notifier QuotaAlertNotifier) {
+	s.notifier = notifier
+}
+
+// CheckQuotaAlerts는 Space 사용량을 경고 비율과 비교해 새로 넘은 경고를 한 번씩 알립니다.
+// 사용량 변화와 할당량 변경 뒤에 호출합니다. 실패는 로그만 남깁니다.
+func (s *QuotaService) CheckQuotaAlerts(ctx context.Context, spaceID int64) {
+	ctx = context.WithoutCancel(ctx)
+	usage, err := s.GetSpaceUsage(ctx, spaceID)
+	if err != nil {
+		logging.Event(log.Warn(), logging.ComponentStorage, "warn.space.quota_alert_check_failed").
+			Err(err).
+			Int64("space_id", spaceID).
+			Msg("failed to check space quota alerts")
+		return
+	}
+
+	levels := map[QuotaMetric]QuotaWarning{}
+	for _, warning := range usage.Warnings {
+		levels[warning.Metric] = warning
+	}
+
+	s.alertMu.Lock()
+	defer s.alertMu.Unlock()
+	for _, metric := range []QuotaMetric{QuotaMetricBytes, QuotaMetricFiles} {
+		warning := levels[metric]
+		previous, err := s.alerts.GetQuotaAlertLevel(ctx, spaceID, metric)
+		if err != nil {
+			logging.Event(log.Warn(), logging.ComponentStorage, "warn.space.quota_alert_check_failed").
+				Err(err).
+				Int64("space_id", spaceID).
+				Str("metric", string(metric)).
+				Msg("failed to load space quota alert state")
+			continue
+		}
+		if warning.ThresholdPercent == previous {
+			continue
+		}
+		now := time.Now()
+		if err := s.alerts.SaveQuotaAlertLevel(ctx, spaceID, metric, warning.ThresholdPercent, now); err != nil {
+			logging.Event(log.Warn(), logging.ComponentStorage, "warn.space.quota_alert_check_failed").
+				Err(err).
+				Int64("space_id", spaceID).
+				Str("metric", string(metric)).
+				Msg("failed to save space quota alert state")
+			continue
+		}
+		if warning.ThresholdPercent < previous {
+			continue
+		}
+
+		event := QuotaThresholdEvent{
+			SpaceID:          usage.SpaceID,
+			SpaceName:        usage.SpaceName,
+			Metric:           metric,
+			ThresholdPercent: warning.ThresholdPercent,
+			PreviousPercent:  previous,
+			Used:             warning.Used,
+			Quota:            warning.Quota,
+			At:               now,
+		}
+		logging.Event(log.Warn(), logging.ComponentStorage, "warn.space.quota_threshold_crossed").
+			Int64("space_id", event.SpaceID).
+			Str("metric", string(metric)).
+			Int("threshold_percent", event.ThresholdPercent).
+			Int64("used", event.Used).
+			Int64("quota", event.Quota).
+			Msg("space usage crossed quota warning threshold")
+		if s.notifier != nil {
+			s.notifier.NotifyQuotaThreshold(ctx, event)
+		}
+	}
+}
+
+// crossedWarningPercent는 used가 넘어선 경고 비율 중 가장 높은 값을 반환합니다. 없거나 quota가 0이면 0입니다.
+func crossedWarningPercent(used, quota int64, percents []int) int {
+	if quota <= 0 {
+		return 0
+	}
+	crossed := 0
+	for _, percent := range percents {
+		if used*100 >= quota*int64(percent) && percent > crossed {
+			crossed = percent
+		}
+	}
+	return crossed
+}
+
+type quotaAlertKey struct {
+	spaceID int64
+	metric  QuotaMetric
+}
+
+// MemoryQuotaAlertStore는 프로세스 메모리에만 알림 상태를 두는 QuotaAlertStorer입니다.
+type MemoryQuotaAlertStore struct {
+	mu     sync.Mutex
+	levels map[quotaAlertKey]int
+}
+
+func NewMemoryQuotaAlertStore() *MemoryQuotaAlertStore {
+	return &MemoryQuotaAlertStore{levels: make(map[quotaAlertKey]int)}
+}
+
+func (s *MemoryQuotaAlertStore) GetQuotaAlertLevel(ctx context.Context, spaceID int64, metric QuotaMetric) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.levels[quotaAlertKey{spaceID: spaceID, metric: metric}], nil
+}
+
+func (s *MemoryQuotaAlertStore) SaveQuotaAlertLevel(ctx context.Context, spaceID int64, metric QuotaMetric, percent int, at time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := quotaAlertKey{spaceID: spaceID, metric: metric}
+	if percent == 0 {
+		delete(s.levels, key)
+		return nil
+	}
+	s.levels[key] = percent
+	return nil
+}
+
+var _ QuotaAlertStorer = (*MemoryQuotaAlertStore)(nil)
@@ -0,0 +1,268 @@
+package space_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"taeu.kr/cohesion/internal/space"
+	spaceStore "taeu.kr/cohesion/internal/space/store"
+)
+
+func TestQuotaService_CounterTracksDeltasAndReconcileCorrectsDrift(t *testing.T) {
+	service, db := setupSlugSpaceService(t)
+	ctx := context.Background()
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "a.bin"), make([]byte, 100), 0o644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+	created, err := service.CreateSpace(ctx, &space.CreateSpaceRequest{SpaceName: "Quota", SpacePath: root})
+	if err != nil {
+		t.Fatalf("create space: %v", err)
+	}
+	markerBytes, err := space.MeasurePaths(ctx, filepath.Join(root, space.RootMarkerFileName))
+	if err != nil {
+		t.Fatalf("measure marker: %v", err)
+	}
+
+	quota := space.NewQuotaService(service)
+	quota.SetUsageCounterStore(spaceStore.NewUsageCounterStore(db))
+
+	usage, err := quota.GetSpaceUsage(ctx, created.ID)
+	if err != nil {
+		t.Fatalf("get usage: %v", err)
+	}
+	if usage.UsedBytes != 100+markerBytes {
+		t.Fatalf("expected initial scan of %d bytes, got %d", 100+markerBytes, usage.UsedBytes)
+	}
+
+	// 쓰기 경로가 알린 변화량은 다시 스캔하지 않고 카운터에 더해진다.
+	target := filepath.Join(root, "b.bin")
+	commit := space.TrackUsage(ctx, quota, created.ID, target)
+	if err := os.WriteFile(target, make([]byte, 50), 0o644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+	commit()
+	quota.AdjustUsage(ctx, created.ID, -10, 0)
+	if usage, _ = quota.GetSpaceUsage(ctx, created.ID); usage.UsedBytes != 140+markerBytes {
+		t.Fatalf("expected counter %d after deltas, got %d", 140+markerBytes, usage.UsedBytes)
+	}
+
+	// 카운터는 DB에 있으므로 새 서비스(재시작)도 같은 값을 본다.
+	restarted := space.NewQuotaService(service)
+	restarted.SetUsageCounterStore(spaceStore.NewUsageCounterStore(db))
+	if usage, _ = restarted.GetSpaceUsage(ctx, created.ID); usage.UsedBytes != 140+markerBytes {
+		t.Fatalf("expected persisted counter %d, got %d", 140+markerBytes, usage.UsedBytes)
+	}
+
+	result, err := restarted.Reconcile(ctx, created.ID)
+	if err != nil {
+		t.Fatalf("reconcile: %v", err)
+	}
+	if result.PreviousBytes == nil || *result.PreviousBytes != 140+markerBytes {
+		t.Fatalf("expected previous bytes %d, got %+v", 140+markerBytes, result.PreviousBytes)
+	}
+	if result.UsedBytes != 150+markerBytes || result.DriftBytes != -10 {
+		t.Fatalf("expected reconcile to 150+marker with drift -10, got %+v", result)
+	}
+	if usage, _ = restarted.GetSpaceUsage(ctx, created.ID); usage.UsedBytes != 150+markerBytes {
+		t.Fatalf("expected reconciled counter, got %d", usage.UsedBytes)
+	}
+}
+
+// scanHookContext는 카운터를 읽은 뒤 스캔이 처음 파일을 셀 때 onScan을 한 번 호출한다.
+// 그 전의 DB 조회도 Err를 부르므로 hookedCounterStore가 카운터를 읽을 때 켠다.
+type scanHookContext struct {
+	context.Context
+	armed  bool
+	once   sync.Once
+	onScan func()
+}
+
+func (c *scanHookContext) Err() error {
+	if c.armed {
+		c.once.Do(c.onScan)
+	}
+	return c.Context.Err()
+}
+
+type hookedCounterStore struct {
+	space.UsageCounterStorer
+}
+
+func (s hookedCounterStore) GetUsageCounter(ctx context.Context, spaceID int64) (*space.UsageCounter, error) {
+	if hooked, ok := ctx.(*scanHookContext); ok {
+		hooked.armed = true
+	}
+	return s.UsageCounterStorer.GetUsageCounter(ctx, spaceID)
+}
+
+func TestQuotaService_ReconcileKeepsDeltasReportedDuringScan(t *testing.T) {
+	service, _ := setupSlugSpaceService(t)
+	ctx := context.Background()
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "a.bin"), make([]byte, 100), 0o644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+	created, err := service.CreateSpace(ctx, &space.CreateSpaceRequest{SpaceName: "Busy", SpacePath: root})
+	if err != nil {
+		t.Fatalf("create space: %v", err)
+	}
+	markerBytes, err := space.MeasurePaths(ctx, filepath.Join(root, space.RootMarkerFileName))
+	if err != nil {
+		t.Fatalf("measure marker: %v", err)
+	}
+
+	quota := space.NewQuotaService(service)
+	quota.SetUsageCounterStore(hookedCounterStore{UsageCounterStorer: space.NewMemoryUsageCounterStore()})
+	if _, err := quota.GetSpaceUsage(ctx, created.ID); err != nil {
+		t.Fatalf("get usage: %v", err)
+	}
+
+	// 스캔이 root 목록을 읽은 뒤에 쓰인 파일은 스캔 결과에 없고, 쓰기 경로가 알린 변화량으로만 남는다.
+	hooked := &scanHookContext{Context: ctx, onScan: func() {
+		target := filepath.Join(root, "late.bin")
+		commit := space.TrackUsage(ctx, quota, created.ID, target)
+		if err := os.WriteFile(target, make([]byte, 40), 0o644); err != nil {
+			t.Errorf("write file during scan: %v", err)
+		}
+		commit()
+	}}
+	result, err := quota.Reconcile(hooked, created.ID)
+	if err != nil {
+		t.Fatalf("reconcile: %v", err)
+	}
+	if result.UsedBytes != 140+markerBytes || result.UsedFiles != 2 || result.DriftBytes != 0 {
+		t.Fatalf("expected reconcile to keep the delta reported during the scan, got %+v", result)
+	}
+	if usage, _ := quota.GetSpaceUsage(ctx, created.ID); usage.UsedBytes != 140+markerBytes {
+		t.Fatalf("expected counter %d, got %d", 140+markerBytes, usage.UsedBytes)
+	}
+}
+
+func TestQuotaReconciler_SkipsOfflineSpaces(t *testing.T) {
+	service, db := setupSlugSpaceService(t)
+	ctx := context.Background()
+	root := t.TempDir()
+	created, err := service.CreateSpace(ctx, &space.CreateSpaceRequest{SpaceName: "Offline", SpacePath: root})
+	if err != nil {
+		t.Fatalf("create space: %v", err)
+	}
+	if health := service.CheckSpaceRoot(ctx, created); !health.Online() {
+		t.Fatalf("expected healthy root, got %+v", health)
+	}
+
+	counters := spaceStore.NewUsageCounterStore(db)
+	quota := space.NewQuotaService(service)
+	quota.SetUsageCounterStore(counters)
+	if _, err := quota.GetSpaceUsage(ctx, created.ID); err != nil {
+		t.Fatalf("get usage: %v", err)
+	}
+	quota.AdjustUsage(ctx, created.ID, 1000, 0)
+
+	// 마운트가 빠진 root를 세면 카운터가 0이 되므로 건너뛰어야 한다.
+	if err := os.Remove(filepath.Join(root, space.RootMarkerFileName)); err != nil {
+		t.Fatalf("remove marker: %v", err)
+	}
+	reloaded, err := service.GetSpaceByID(ctx, created.ID)
+	if err != nil {
+		t.Fatalf("reload space: %v", err)
+	}
+	service.CheckSpaceRoot(ctx, reloaded)
+
+	reconciler := space.NewQuotaReconciler(quota, 0)
+	if err := reconciler.ReconcileAll(ctx); err != nil {
+		t.Fatalf("reconcile all: %v", err)
+	}
+	counter, err := counters.GetUsageCounter(ctx, created.ID)
+	if err != nil {
+		t.Fatalf("get counter: %v", err)
+	}
+	if counter.UsedBytes < 1000 {
+		t.Fatalf("expected offline space counter to be kept, got %d", counter.UsedBytes)
+	}
+	reconciler.Stop()
+}
+
+func TestQuotaService_FileQuotaAndWarningsNotifyOncePerCrossing(t *testing.T) {
+	service, db := setupSlugSpaceService(t)
+	ctx := context.Background()
+	root := t.TempDir()
+	created, err := service.CreateSpace(ctx, &space.CreateSpaceRequest{SpaceName: "Warn", SpacePath: root})
+	if err != nil {
+		t.Fatalf("create space: %v", err)
+	}
+	base, err := space.MeasurePathUsage(ctx, root)
+	if err != nil {
+		t.Fatalf("measure root: %v", err)
+	}
+
+	quotaBytes, quotaFiles := int64(1000), base.Files+2
+	if _, err := service.UpdateSpaceQuota(ctx, created.ID, space.QuotaLimits{
+		QuotaBytes:      &quotaBytes,
+		QuotaFiles:      &quotaFiles,
+		WarningPercents: []int{90, 50},
+	}); err != nil {
+		t.Fatalf("update quota: %v", err)
+	}
+
+	var events []space.QuotaThresholdEvent
+	quota := space.NewQuotaService(service)
+	quota.SetUsageCounterStore(spaceStore.NewUsageCounterStore(db))
+	quota.SetQuotaAlertStore(spaceStore.NewQuotaAlertStore(db))
+	quota.SetQuotaAlertNotifier(space.QuotaAlertNotifierFunc(func(ctx context.Context, event space.QuotaThresholdEvent) {
+		events = append(events, event)
+	}))
+
+	usage, err := quota.GetSpaceUsage(ctx, created.ID)
+	if err != nil {
+		t.Fatalf("get usage: %v", err)
+	}
+	if usage.UsedFiles != base.Files || len(usage.Warnings) != 0 {
+		t.Fatalf("expected %d files without warnings, got %+v", base.Files, usage)
+	}
+
+	used := base.Bytes
+	setUsed := func(target int64) {
+		quota.AdjustUsage(ctx, created.ID, target-used, 0)
+		used = target
+	}
+	setUsed(600)
+	setUsed(610)
+	if len(events) != 1 || events[0].Metric != space.QuotaMetricBytes || events[0].ThresholdPercent != 50 {
+		t.Fatalf("expected one 50%% bytes alert, got %+v", events)
+	}
+	setUsed(950)
+	if len(events) != 2 || events[1].ThresholdPercent != 90 || events[1].PreviousPercent != 50 {
+		t.Fatalf("expected 90%% alert after 50%%, got %+v", events)
+	}
+	// 내려갔다가 다시 넘으면 다시 알린다.
+	setUsed(100)
+	setUsed(700)
+	if len(events) != 3 || events[2].ThresholdPercent != 50 {
+		t.Fatalf("expected re-armed 50%% alert, got %+v", events)
+	}
+	if usage, _ = quota.GetSpaceUsage(ctx, created.ID); len(usage.Warnings) != 1 || usage.Warnings[0].ThresholdPercent != 50 || usage.OverQuota {
+		t.Fatalf("expected soft warning without blocking, got %+v", usage)
+	}
+
+	target := filepath.Join(root, "a.bin")
+	commit := space.TrackUsage(ctx, quota, created.ID, target)
+	if err := os.WriteFile(target, []byte("x"), 0o644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+	commit()
+	if err := quota.EnsureCanAddFiles(ctx, created.ID, 1); err != nil {
+		t.Fatalf("expected one more file to fit, got %v", err)
+	}
+	var fileQuotaErr *space.FileQuotaExceededError
+	if err := quota.EnsureCanAddFiles(ctx, created.ID, 2); !errors.As(err, &fileQuotaErr) || fileQuotaErr.UsedFiles != base.Files+1 {
+		t.Fatalf("expected file quota error, got %v", err)
+	}
+	if err := space.EnsureCanCreateFile(ctx, quota, created.ID, target); err != nil {
+		t.Fatalf("expected overwrite of existing file to skip file quota, got %v", err)
+	}
+}
@@ -0,0 +1,120 @@
+package space
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"taeu.kr/cohesion/internal/platform/logging"
+)
+
+// DefaultQuotaReconcileInterval은 사용량 카운터를 전체 스캔으로 맞추는 주기입니다.
+// 쓰기 경로를 거치지 않은 변화(서버 밖에서 바꾼 파일 등)는 이 주기 안에 반영됩니다.
+const DefaultQuotaReconcileInterval = time.Hour
+
+// QuotaReconciler는 주기적으로 모든 online Space의 사용량 카운터를 다시 셉니다.
+type QuotaReconciler struct {
+	quota    *QuotaService
+	interval time.Duration
+
+	startOnce sync.Once
+	stopOnce  sync.Once
+	stopCh    chan struct{}
+	doneCh    chan struct{}
+}
+
+func NewQuotaReconciler(quota *QuotaService, interval time.Duration) *QuotaReconciler {
+	if interval <= 0 {
+		interval = DefaultQuotaReconcileInterval
+	}
+	return &QuotaReconciler{
+		quota:    quota,
+		interval: interval,
+		stopCh:   make(chan struct{}),
+		doneCh:   make(chan struct{}),
+	}
+}
+
+// Start는 즉시 한 번 대조한 뒤 interval마다 다시 대조합니다.
+func (r *QuotaReconciler) Start() {
+	r.startOnce.Do(func() {
+		go r.run()
+	})
+}
+
+// Stop은 대조 루프를 멈추고 종료를 기다립니다. Start 전에 호출해도 안전합니다.
+func (r *QuotaReconciler) Stop() {
+	r.stopOnce.Do(func() {
+		close(r.stopCh)
+	})
+	r.startOnce.Do(func() {
+		close(r.doneCh)
+	})
+	<-r.doneCh
+}
+
+// ReconcileAll은 online Space마다 카운터를 다시 셉니다. offline Space는 빈 마운트 지점을
+// 세어 카운터를 망가뜨리지 않도록 건너뜁니다. 한 Space의 실패는 기록만 하고 계속합니다.
+func (r *QuotaReconciler) ReconcileAll(ctx context.Context) error {
+	spaces, err := r.quota.spaceService.GetAllSpaces(ctx)
+	if err != nil {
+		return err
+	}
+	for _, item := range spaces {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if r.quota.spaceService.EnsureOnline(item.ID) != nil {
+			continue
+		}
+		result, err := r.quota.Reconcile(ctx, item.ID)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			logging.Event(log.Warn(), logging.ComponentStorage, "warn.space.usage_reconcile_failed").
+				Err(err).
+				Int64("space_id", item.ID).
+				Msg("failed to reconcile space usage")
+			continue
+		}
+		if result.DriftBytes != 0 {
+			logging.Event(log.Info(), logging.ComponentStorage, "info.space.usage_drift_corrected").
+				Int64("space_id", item.ID).
+				Int64("drift_bytes", result.DriftBytes).
+				Int64("used_bytes", result.UsedBytes).
+				Msg("space usage counter corrected")
+		}
+	}
+	return nil
+}
+
+func (r *QuotaReconciler) run() {
+	defer close(r.doneCh)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-r.stopCh:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+	for {
+		if err := r.ReconcileAll(ctx); err != nil && ctx.Err() == nil {
+			logging.Event(log.Warn(), logging.ComponentStorage, "warn.space.usage_reconcile_failed").
+				Err(err).
+				Msg("failed to reconcile space usage")
+		}
+		select {
+		case <-r.stopCh:
+			return
+		case <-ticker.C:
+		}
+	}
+}
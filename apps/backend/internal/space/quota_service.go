@@ -2,19 +2,16 @@ package space
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"sync"
 	"time"
-)
-
-const defaultQuotaUsageCacheTTL = 10 * time.Second
 
-type cachedSpaceUsage struct {
-	usedBytes int64
-	scannedAt time.Time
-}
+	"github.com/rs/zerolog/log"
+	"taeu.kr/cohesion/internal/platform/logging"
+)
 
 type quotaReservation struct {
 	spaceID    int64
@@ -22,12 +19,31 @@ type quotaReservation struct {
 }
 
 type SpaceUsage struct {
-	SpaceID    int64     `json:"spaceId"`
-	SpaceName  string    `json:"spaceName"`
-	UsedBytes  int64     `json:"usedBytes"`
-	QuotaBytes *int64    `json:"quotaBytes,omitempty"`
-	OverQuota  bool      `json:"overQuota"`
-	ScannedAt  time.Time `json:"scannedAt"`
+	SpaceID    int64  `json:"spaceId"`
+	SpaceName  string `json:"spaceName"`
+	UsedBytes  int64  `json:"usedBytes"`
+	QuotaBytes *int64 `json:"quotaBytes,omitempty"`
+	OverQuota  bool   `json:"overQuota"`
+	// UsedFiles는 디렉터리를 뺀 파일 수입니다.
+	UsedFiles     int64  `json:"usedFiles"`
+	QuotaFiles    *int64 `json:"quotaFiles,omitempty"`
+	OverFileQuota bool   `json:"overFileQuota"`
+	// Warnings는 넘어선 경고 비율입니다. 쓰기를 막지 않으며 용량과 파일 수를 따로 봅니다.
+	Warnings []QuotaWarning `json:"warnings,omitempty"`
+	// ScannedAt은 마지막 전체 스캔(대조) 시각입니다. 이후 변화는 카운터에 누적돼 있습니다.
+	ScannedAt time.Time `json:"scannedAt"`
+}
+
+// UsageReconcileResult는 전체 스캔으로 카운터를 맞춘 결과입니다.
+type UsageReconcileResult struct {
+	SpaceID int64 `json:"spaceId"`
+	// PreviousBytes는 대조 전 카운터 값입니다. 카운터가 없었으면 비어 있습니다.
+	PreviousBytes *int64 `json:"previousBytes,omitempty"`
+	UsedBytes     int64  `json:"usedBytes"`
+	UsedFiles     int64  `json:"usedFiles"`
+	// DriftBytes는 카운터가 실제보다 많았던 양입니다(음수면 적었던 양).
+	DriftBytes   int64     `json:"driftBytes"`
+	ReconciledAt time.Time `json:"reconciledAt"`
 }
 
 type QuotaExceededError struct {
@@ -42,49 +58,92 @@ func (e *QuotaExceededError) Error() string {
 	return fmt.Sprintf("space quota exceeded (spaceId=%d, used=%d, quota=%d, delta=%d)", e.SpaceID, e.UsedBytes, e.QuotaBytes, e.DeltaBytes)
 }
 
+// FileQuotaExceededError는 Space의 파일 수 할당량을 넘는 쓰기입니다.
+type FileQuotaExceededError struct {
+	SpaceID    int64
+	SpaceName  string
+	UsedFiles  int64
+	QuotaFiles int64
+	DeltaFiles int64
+}
+
+func (e *FileQuotaExceededError) Error() string {
+	return fmt.Sprintf("space file quota exceeded (spaceId=%d, used=%d, quota=%d, delta=%d)", e.SpaceID, e.UsedFiles, e.QuotaFiles, e.DeltaFiles)
+}
+
+// QuotaService는 Space 사용량 카운터와 쓰기 예약으로 할당량을 판단합니다.
+// 사용량은 카운터가 없을 때(처음 조회, Invalidate 이후)만 전체 스캔으로 세고,
+// 그 뒤로는 쓰기 경로가 AdjustUsage로 알린 변화량을 더합니다.
+// 사용량이 경고 비율을 새로 넘으면 넘을 때마다 한 번 알립니다.
 type QuotaService struct {
 	spaceService *Service
-	ttl          time.Duration
+	counters     UsageCounterStorer
+	alerts       QuotaAlertStorer
+	notifier     QuotaAlertNotifier
+	// alertMu는 같은 경고를 동시에 두 번 알리지 않게 합니다.
+	alertMu sync.Mutex
 
 	mu              sync.RWMutex
-	cache           map[int64]cachedSpaceUsage
 	reservations    map[string]quotaReservation
 	reservedBySpace map[int64]int64
+	// scanLocks는 같은 Space의 전체 스캔이 겹치지 않게 합니다.
+	scanLocks map[int64]*sync.Mutex
+	// usageLocks는 AdjustUsage와 스캔 결과로 카운터를 덮어쓰는 작업이 겹치지 않게 합니다.
+	usageLocks map[int64]*sync.Mutex
+	// scanDeltas는 스캔 중인 Space에 AdjustUsage로 들어온 변화량입니다. 스캔 결과를 저장할 때 더합니다.
+	scanDeltas map[int64]*PathUsage
 }
 
 func NewQuotaService(spaceService *Service) *QuotaService {
 	return &QuotaService{
 		spaceService:    spaceService,
-		ttl:             defaultQuotaUsageCacheTTL,
-		cache:           make(map[int64]cachedSpaceUsage),
+		counters:        NewMemoryUsageCounterStore(),
+		alerts:          NewMemoryQuotaAlertStore(),
 		reservations:    make(map[string]quotaReservation),
 		reservedBySpace: make(map[int64]int64),
+		scanLocks:       make(map[int64]*sync.Mutex),
+		usageLocks:      make(map[int64]*sync.Mutex),
+		scanDeltas:      make(map[int64]*PathUsage),
 	}
 }
 
+// SetUsageCounterStore는 사용량 카운터 저장소를 교체합니다. 기본값은 메모리 저장소입니다.
+func (s *QuotaService) SetUsageCounterStore(store UsageCounterStorer) {
+	s.counters = store
+}
+
 func (s *QuotaService) GetSpaceUsage(ctx context.Context, spaceID int64) (*SpaceUsage, error) {
 	spaceData, err := s.spaceService.GetSpaceByID(ctx, spaceID)
 	if err != nil {
 		return nil, err
 	}
 
-	usedBytes, scannedAt, err := s.getUsedBytes(ctx, spaceData.ID, spaceData.SpacePath)
+	counter, err := s.getCounter(ctx, spaceData)
 	if err != nil {
 		return nil, err
 	}
 
-	overQuota := false
-	if spaceData.QuotaBytes != nil {
-		overQuota = usedBytes > *spaceData.QuotaBytes
-	}
-
 	usage := &SpaceUsage{
 		SpaceID:    spaceData.ID,
 		SpaceName:  spaceData.SpaceName,
-		UsedBytes:  usedBytes,
+		UsedBytes:  counter.UsedBytes,
 		QuotaBytes: spaceData.QuotaBytes,
-		OverQuota:  overQuota,
-		ScannedAt:  scannedAt,
+		UsedFiles:  counter.UsedFiles,
+		QuotaFiles: spaceData.QuotaFiles,
+		ScannedAt:  counter.ReconciledAt,
+	}
+	percents := spaceData.EffectiveQuotaWarningPercents()
+	if spaceData.QuotaBytes != nil {
+		usage.OverQuota = counter.UsedBytes > *spaceData.QuotaBytes
+		if percent := crossedWarningPercent(counter.UsedBytes, *spaceData.QuotaBytes, percents); percent > 0 {
+			usage.Warnings = append(usage.Warnings, QuotaWarning{Metric: QuotaMetricBytes, ThresholdPercent: percent, Used: counter.UsedBytes, Quota: *spaceData.QuotaBytes})
+		}
+	}
+	if spaceData.QuotaFiles != nil {
+		usage.OverFileQuota = counter.UsedFiles > *spaceData.QuotaFiles
+		if percent := crossedWarningPercent(counter.UsedFiles, *spaceData.QuotaFiles, percents); percent > 0 {
+			usage.Warnings = append(usage.Warnings, QuotaWarning{Metric: QuotaMetricFiles, ThresholdPercent: percent, Used: counter.UsedFiles, Quota: *spaceData.QuotaFiles})
+		}
 	}
 
 	return usage, nil
@@ -117,6 +176,39 @@ func (s *QuotaService) EnsureCanWrite(ctx context.Context, spaceID int64, deltaB
 	}
 }
 
+// EnsureCanAddFiles는 Space에 파일을 deltaFiles개 더 만들어도 파일 수 할당량을 넘지 않는지 확인합니다.
+func (s *QuotaService) EnsureCanAddFiles(ctx context.Context, spaceID int64, deltaFiles int64) error {
+	if deltaFiles <= 0 {
+		return nil
+	}
+	usage, err := s.GetSpaceUsage(ctx, spaceID)
+	if err != nil {
+		return err
+	}
+	if usage.QuotaFiles == nil || usage.UsedFiles+deltaFiles <= *usage.QuotaFiles {
+		return nil
+	}
+	return &FileQuotaExceededError{
+		SpaceID:    usage.SpaceID,
+		SpaceName:  usage.SpaceName,
+		UsedFiles:  usage.UsedFiles,
+		QuotaFiles: *usage.QuotaFiles,
+		DeltaFiles: deltaFiles,
+	}
+}
+
+// WriteAllowance는 Space에 더 쓸 수 있는 바이트 수(할당량 − 사용량 − 예약)를 반환합니다. 할당량이 없으면 -1입니다.
+func (s *QuotaService) WriteAllowance(ctx context.Context, spaceID int64) (int64, error) {
+	usage, err := s.GetSpaceUsage(ctx, spaceID)
+	if err != nil {
+		return 0, err
+	}
+	if usage.QuotaBytes == nil {
+		return -1, nil
+	}
+	return max(*usage.QuotaBytes-usage.UsedBytes-s.ReservedBytes(spaceID), 0), nil
+}
+
 func (s *QuotaService) AcquireWriteReservation(ctx context.Context, spaceID int64, reservationID string, deltaBytes int64) (*SpaceUsage, error) {
 	if deltaBytes <= 0 {
 		return s.GetSpaceUsage(ctx, spaceID)
@@ -190,15 +282,31 @@ func (s *QuotaService) ReservedBytes(spaceID int64) int64 {
 }
 
 func (s *QuotaService) CalculatePathSize(ctx context.Context, absPath string) (int64, error) {
+	return PathSize(ctx, absPath)
+}
+
+// CalculatePathUsage는 absPath 아래 파일 크기의 합과 파일 수를 반환합니다.
+func (s *QuotaService) CalculatePathUsage(ctx context.Context, absPath string) (PathUsage, error) {
+	return MeasurePath(ctx, absPath)
+}
+
+// PathSize는 파일 하나 또는 디렉터리 아래 파일 크기의 합을 반환합니다.
+func PathSize(ctx context.Context, absPath string) (int64, error) {
+	usage, err := MeasurePath(ctx, absPath)
+	return usage.Bytes, err
+}
+
+// MeasurePath는 파일 하나 또는 디렉터리 아래 파일 크기의 합과 파일 수를 반환합니다.
+func MeasurePath(ctx context.Context, absPath string) (PathUsage, error) {
 	info, err := os.Stat(absPath)
 	if err != nil {
-		return 0, err
+		return PathUsage{}, err
 	}
 	if !info.IsDir() {
-		return info.Size(), nil
+		return PathUsage{Bytes: info.Size(), Files: 1}, nil
 	}
 
-	var total int64
+	var total PathUsage
 	err = filepath.WalkDir(absPath, func(currentPath string, entry os.DirEntry, walkErr error) error {
 		if walkErr != nil {
 			return walkErr
@@ -214,58 +322,185 @@ func (s *QuotaService) CalculatePathSize(ctx context.Context, absPath string) (i
 		if err != nil {
 			return err
 		}
-		total += entryInfo.Size()
+		total.Bytes += entryInfo.Size()
+		total.Files++
 		return nil
 	})
 	if err != nil {
-		return 0, err
+		return PathUsage{}, err
 	}
 
 	return total, nil
 }
 
-func (s *QuotaService) Invalidate(spaceID int64) {
+// AdjustUsage는 쓰기 경로가 알린 사용량 변화를 카운터에 더하고 경고 비율을 다시 확인합니다.
+// 카운터가 아직 없으면 다음 조회 때 전체 스캔으로 세므로 아무 것도 하지 않습니다.
+// 전체 스캔 중이면 변화량을 따로 모아 두었다가 스캔 결과에 더합니다.
+func (s *QuotaService) AdjustUsage(ctx context.Context, spaceID int64, deltaBytes int64, deltaFiles int64) {
+	if spaceID <= 0 || (deltaBytes == 0 && deltaFiles == 0) {
+		return
+	}
+	lock := s.usageLock(spaceID)
+	lock.Lock()
 	s.mu.Lock()
-	defer s.mu.Unlock()
-	delete(s.cache, spaceID)
+	if pending, ok := s.scanDeltas[spaceID]; ok {
+		pending.Bytes += deltaBytes
+		pending.Files += deltaFiles
+	}
+	s.mu.Unlock()
+	err := s.counters.AddUsage(ctx, spaceID, deltaBytes, deltaFiles, time.Now())
+	lock.Unlock()
+	if err != nil {
+		// 반영하지 못한 변화는 카운터를 버려 다음 조회 때 다시 세도록 한다.
+		logging.Event(log.Warn(), logging.ComponentStorage, "warn.space.usage_adjust_failed").
+			Err(err).
+			Int64("space_id", spaceID).
+			Int64("delta_bytes", deltaBytes).
+			Int64("delta_files", deltaFiles).
+			Msg("failed to adjust space usage counter")
+		s.Invalidate(spaceID)
+		return
+	}
+	s.CheckQuotaAlerts(ctx, spaceID)
+}
+
+// Invalidate는 카운터를 버려 다음 조회 때 전체 스캔으로 다시 세게 합니다.
+// 변화량을 알 수 없는 작업(Space root 이전 등)에서만 사용합니다.
+func (s *QuotaService) Invalidate(spaceID int64) {
+	s.InvalidateMany(spaceID)
 }
 
 func (s *QuotaService) InvalidateMany(spaceIDs ...int64) {
-	s.mu.Lock()
-	defer s.mu.Unlock()
 	for _, spaceID := range spaceIDs {
-		delete(s.cache, spaceID)
+		if err := s.counters.DeleteUsageCounter(context.Background(), spaceID); err != nil {
+			logging.Event(log.Warn(), logging.ComponentStorage, "warn.space.usage_invalidate_failed").
+				Err(err).
+				Int64("space_id", spaceID).
+				Msg("failed to invalidate space usage counter")
+		}
 	}
 }
 
-func (s *QuotaService) getUsedBytes(ctx context.Context, spaceID int64, spacePath string) (int64, time.Time, error) {
-	now := time.Now()
+// Reconcile은 Space 전체를 다시 스캔해 카운터를 맞춥니다.
+// 스캔 중에 AdjustUsage로 들어온 변화는 스캔 결과에 더해 저장하므로 잃지 않습니다.
+// 스캔이 이미 센 파일의 변화가 한 번 더 더해질 수는 있으며 다음 대조에서 바로잡힙니다.
+func (s *QuotaService) Reconcile(ctx context.Context, spaceID int64) (*UsageReconcileResult, error) {
+	spaceData, err := s.spaceService.GetSpaceByID(ctx, spaceID)
+	if err != nil {
+		return nil, err
+	}
 
-	s.mu.RLock()
-	cached, ok := s.cache[spaceID]
-	s.mu.RUnlock()
-	if ok && now.Sub(cached.scannedAt) <= s.ttl {
-		return cached.usedBytes, cached.scannedAt, nil
+	lock := s.scanLock(spaceID)
+	lock.Lock()
+	defer lock.Unlock()
+
+	result := &UsageReconcileResult{SpaceID: spaceID}
+	previous, err := s.counters.GetUsageCounter(ctx, spaceID)
+	switch {
+	case err == nil:
+		result.PreviousBytes = &previous.UsedBytes
+	case !errors.Is(err, ErrUsageCounterNotFound):
+		return nil, err
 	}
 
-	usedBytes, err := s.scanSpaceUsage(ctx, spacePath)
+	counter, pending, err := s.scanAndSaveLocked(ctx, spaceData)
 	if err != nil {
-		return 0, time.Time{}, err
+		return nil, err
+	}
+	result.UsedBytes = counter.UsedBytes
+	result.UsedFiles = counter.UsedFiles
+	result.ReconciledAt = counter.ReconciledAt
+	if result.PreviousBytes != nil {
+		// 스캔 중에 알려진 변화는 이전 값에도 반영되었어야 하므로 오차로 보지 않는다.
+		result.DriftBytes = *result.PreviousBytes + pending.Bytes - counter.UsedBytes
+	}
+	s.CheckQuotaAlerts(ctx, spaceID)
+	return result, nil
+}
+
+func (s *QuotaService) getCounter(ctx context.Context, spaceData *Space) (*UsageCounter, error) {
+	counter, err := s.counters.GetUsageCounter(ctx, spaceData.ID)
+	if err == nil {
+		return counter, nil
+	}
+	if !errors.Is(err, ErrUsageCounterNotFound) {
+		return nil, err
 	}
-	scannedAt := time.Now()
 
+	lock := s.scanLock(spaceData.ID)
+	lock.Lock()
+	defer lock.Unlock()
+
+	// 기다리는 동안 다른 요청이 이미 셌을 수 있다.
+	if counter, err := s.counters.GetUsageCounter(ctx, spaceData.ID); err == nil {
+		return counter, nil
+	}
+	counter, _, err = s.scanAndSaveLocked(ctx, spaceData)
+	return counter, err
+}
+
+// scanAndSaveLocked는 scanLock을 쥔 채로 호출합니다. 스캔하는 동안 들어온 변화량은
+// 스캔 결과에 더해 함께 반환하고, 저장을 마칠 때까지 AdjustUsage를 막아 저장이 변화를 덮어쓰지 않게 합니다.
+func (s *QuotaService) scanAndSaveLocked(ctx context.Context, spaceData *Space) (*UsageCounter, PathUsage, error) {
 	s.mu.Lock()
-	s.cache[spaceID] = cachedSpaceUsage{
-		usedBytes: usedBytes,
-		scannedAt: scannedAt,
+	s.scanDeltas[spaceData.ID] = &PathUsage{}
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(s.scanDeltas, spaceData.ID)
+		s.mu.Unlock()
+	}()
+
+	used, err := s.scanSpaceUsage(ctx, spaceData.SpacePath)
+	if err != nil {
+		return nil, PathUsage{}, err
 	}
+
+	lock := s.usageLock(spaceData.ID)
+	lock.Lock()
+	defer lock.Unlock()
+	s.mu.Lock()
+	pending := *s.scanDeltas[spaceData.ID]
 	s.mu.Unlock()
 
-	return usedBytes, scannedAt, nil
+	now := time.Now()
+	counter := &UsageCounter{
+		SpaceID:      spaceData.ID,
+		UsedBytes:    max(used.Bytes+pending.Bytes, 0),
+		UsedFiles:    max(used.Files+pending.Files, 0),
+		ReconciledAt: now,
+		UpdatedAt:    now,
+	}
+	if err := s.counters.SaveUsageCounter(ctx, counter); err != nil {
+		return nil, PathUsage{}, err
+	}
+	return counter, pending, nil
+}
+
+func (s *QuotaService) scanLock(spaceID int64) *sync.Mutex {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	lock, ok := s.scanLocks[spaceID]
+	if !ok {
+		lock = &sync.Mutex{}
+		s.scanLocks[spaceID] = lock
+	}
+	return lock
 }
 
-func (s *QuotaService) scanSpaceUsage(ctx context.Context, spacePath string) (int64, error) {
-	var usedBytes int64
+func (s *QuotaService) usageLock(spaceID int64) *sync.Mutex {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	lock, ok := s.usageLocks[spaceID]
+	if !ok {
+		lock = &sync.Mutex{}
+		s.usageLocks[spaceID] = lock
+	}
+	return lock
+}
+
+func (s *QuotaService) scanSpaceUsage(ctx context.Context, spacePath string) (PathUsage, error) {
+	var used PathUsage
 	err := filepath.WalkDir(spacePath, func(currentPath string, entry os.DirEntry, walkErr error) error {
 		if walkErr != nil {
 			if os.IsPermission(walkErr) {
@@ -290,12 +525,15 @@ func (s *QuotaService) scanSpaceUsage(ctx context.Context, spacePath string) (in
 			}
 			return err
 		}
-		usedBytes += info.Size()
+		used.Bytes += info.Size()
+		used.Files++
 		return nil
 	})
 	if err != nil {
-		return 0, err
+		return PathUsage{}, err
 	}
 
-	return usedBytes, nil
+	return used, nil
 }
+
+var _ UsageTracker = (*QuotaService)(nil)
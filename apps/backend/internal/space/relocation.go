@@ -0,0 +1,676 @@
+package space
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+const relocationCopyBufferSize = 1 << 20
+
+type RelocationState string
+
+const (
+	RelocationStateQueued    RelocationState = "queued"
+	RelocationStateCopying   RelocationState = "copying"
+	RelocationStateVerifying RelocationState = "verifying"
+	RelocationStateSwitching RelocationState = "switching"
+	RelocationStateCompleted RelocationState = "completed"
+	RelocationStateFailed    RelocationState = "failed"
+	RelocationStateCanceled  RelocationState = "canceled"
+)
+
+// RelocateSpaceRequest는 Space root 이전 요청 데이터를 정의합니다.
+type RelocateSpaceRequest struct {
+	TargetPath string `json:"target_path"`
+}
+
+func (req *RelocateSpaceRequest) Validate() error {
+	if req == nil {
+		return errors.New("request is required")
+	}
+	req.TargetPath = strings.TrimSpace(req.TargetPath)
+	if req.TargetPath == "" {
+		return errors.New("target_path is required")
+	}
+	if !filepath.IsAbs(req.TargetPath) {
+		return errors.New("target_path must be an absolute path")
+	}
+	req.TargetPath = filepath.Clean(req.TargetPath)
+	return nil
+}
+
+// RelocationJob은 Space root 이전 작업의 진행 상태입니다.
+type RelocationJob struct {
+	ID                  string          `json:"jobId"`
+	SpaceID             int64           `json:"spaceId"`
+	Owner               string          `json:"-"`
+	RequestID           string          `json:"-"`
+	SourcePath          string          `json:"sourcePath"`
+	TargetPath          string          `json:"targetPath"`
+	Status              RelocationState `json:"status"`
+	FailureReason       string          `json:"failureReason,omitempty"`
+	TotalItems          int             `json:"totalItems"`
+	ProcessedItems      int             `json:"processedItems"`
+	TotalBytes          int64           `json:"totalBytes"`
+	ProcessedBytes      int64           `json:"processedBytes"`
+	VerifiedItems       int             `json:"verifiedItems"`
+	ResyncedItems       int             `json:"resyncedItems"`
+	TrashItemsRewritten int             `json:"trashItemsRewritten"`
+	// TargetEntries는 이 작업이 대상 디렉터리에 만든 최상위 항목입니다. 재시도와 정리는 이 항목만 지웁니다.
+	TargetEntries []string   `json:"targetEntries,omitempty"`
+	CreatedAt     time.Time  `json:"createdAt"`
+	UpdatedAt     time.Time  `json:"updatedAt"`
+	FinishedAt    *time.Time `json:"finishedAt,omitempty"`
+}
+
+func (j *RelocationJob) IsFinished() bool {
+	switch j.Status {
+	case RelocationStateCompleted, RelocationStateFailed, RelocationStateCanceled:
+		return true
+	default:
+		return false
+	}
+}
+
+type relocationTrashRewriter interface {
+	RewriteStoragePaths(ctx context.Context, spaceID int64, oldRoot string) (int, error)
+}
+
+type relocationSearchIndexer interface {
+	MarkSpaceDirty(ctx context.Context, spaceID int64) error
+}
+
+type relocationFileDigest struct {
+	Size    int64
+	ModTime time.Time
+	SHA256  string
+}
+
+var (
+	// ErrRelocationSourceChanged는 작업을 실행할 때 Space root가 요청 시점과 다른 곳으로 바뀌어 있음을 나타냅니다.
+	// 저장소 이전과 삭제 작업이 함께 씁니다.
+	ErrRelocationSourceChanged = errors.New("space root changed since the job was requested")
+	// ErrRelocationTargetInUse는 대상이 다른 Space root와 겹치거나 이 작업이 만들지 않은 항목을 담고 있음을 나타냅니다.
+	ErrRelocationTargetInUse = errors.New("target_path is in use")
+)
+
+// SpaceJobRun은 저장소 이전/삭제 작업이 진행률과 단계별 상태를 남기는 창구입니다. *job.Run이 만족합니다.
+type SpaceJobRun interface {
+	SetTotals(items int, bytes int64)
+	Advance(items int, bytes int64)
+	SetResult(v any) error
+	// PreventCancel은 되돌릴 수 없는 단계 직전에 호출합니다. 이미 취소되었으면 false입니다.
+	PreventCancel() bool
+}
+
+// RelocationManager는 Space 데이터를 새 root로 복사/검증한 뒤 짧은 쓰기 동결 구간에서 root를 전환합니다.
+// 원본 디렉터리는 삭제하지 않으며, 전환 후 정리는 운영자가 직접 수행합니다.
+// 작업 기록과 재시도는 호출자의 작업 큐가 맡고, 여기서는 한 번의 실행만 수행합니다.
+type RelocationManager struct {
+	spaceService *Service
+	trash        relocationTrashRewriter
+	searchIndex  relocationSearchIndexer
+}
+
+func NewRelocationManager(spaceService *Service) *RelocationManager {
+	return &RelocationManager{spaceService: spaceService}
+}
+
+func (m *RelocationManager) SetTrashRewriter(rewriter relocationTrashRewriter) {
+	m.trash = rewriter
+}
+
+func (m *RelocationManager) SetSearchIndexer(indexer relocationSearchIndexer) {
+	m.searchIndex = indexer
+}
+
+// Prepare는 대상 경로를 검증하고 queued 상태의 작업 계획을 만듭니다. 실제 복사는 Run이 수행합니다.
+func (m *RelocationManager) Prepare(ctx context.Context, spaceID int64, req *RelocateSpaceRequest) (*RelocationJob, error) {
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrSpaceValidation, err)
+	}
+
+	spaceObj, err := m.spaceService.GetSpaceByID(ctx, spaceID)
+	if err != nil {
+		return nil, err
+	}
+
+	rootValidation, err := m.spaceService.ValidateSpaceRoot(ctx, req.TargetPath)
+	if err != nil {
+		return nil, err
+	}
+	if !rootValidation.Valid {
+		return nil, fmt.Errorf("%w: %w", ErrSpaceValidation, NewSpaceRootValidationError(*rootValidation))
+	}
+	if err := validateRelocationTarget(spaceObj.SpacePath, req.TargetPath); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrSpaceValidation, err)
+	}
+	if err := m.checkTargetOverlap(ctx, spaceID, req.TargetPath); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrSpaceValidation, err)
+	}
+
+	return &RelocationJob{
+		SpaceID:    spaceID,
+		SourcePath: filepath.Clean(spaceObj.SpacePath),
+		TargetPath: req.TargetPath,
+		Status:     RelocationStateQueued,
+	}, nil
+}
+
+// Run은 계획한 이전을 한 번 수행하고 단계마다 상태를 run에 남깁니다. plan은 지난 실행이 남긴 상태입니다.
+// 재시도나 서버 재시작으로 다시 호출되면 이전 시도가 대상에 만든 항목을 지우고 처음부터 복사하며,
+// root가 이미 대상으로 바뀌어 있으면 후속 작업만 마칩니다. 실패하거나 취소되면 이 작업이 만든 항목만 지웁니다.
+// 대상이 다른 Space root와 겹치거나 이 작업이 만들지 않은 항목이 있으면 아무 것도 지우지 않고 ErrRelocationTargetInUse를 반환합니다.
+func (m *RelocationManager) Run(ctx context.Context, plan *RelocationJob, run SpaceJobRun) error {
+	state := *plan
+	spaceObj, err := m.spaceService.GetSpaceByID(ctx, state.SpaceID)
+	if err != nil {
+		return err
+	}
+	switch filepath.Clean(spaceObj.SpacePath) {
+	case state.TargetPath:
+		return m.finishSwitch(&state, run)
+	case state.SourcePath:
+	default:
+		return fmt.Errorf("%w: expected %s, found %s", ErrRelocationSourceChanged, state.SourcePath, spaceObj.SpacePath)
+	}
+
+	// 요청 뒤에 다른 Space나 작업이 대상을 쓰기 시작했을 수 있으므로 다시 확인한다.
+	if err := m.checkTargetOverlap(ctx, state.SpaceID, state.TargetPath); err != nil {
+		return err
+	}
+	if err := checkTargetEntries(state.TargetPath, state.TargetEntries); err != nil {
+		return err
+	}
+	if err := removeRelocationEntries(state.TargetPath, state.TargetEntries); err != nil {
+		return fmt.Errorf("failed to clear leftovers in target_path: %w", err)
+	}
+	state.TargetEntries = nil
+	state.VerifiedItems = 0
+	state.Status = RelocationStateCopying
+	_ = run.SetResult(state)
+
+	// 최상위 항목은 만들기 전에 기록해, 중간에 멈춰도 다음 실행과 정리가 이 작업의 것만 지우게 한다.
+	claim := func(name string) error {
+		if slices.Contains(state.TargetEntries, name) {
+			return nil
+		}
+		if _, err := os.Lstat(filepath.Join(state.TargetPath, name)); err == nil {
+			return fmt.Errorf("%w: %s was not created by this relocation", ErrRelocationTargetInUse, name)
+		} else if !os.IsNotExist(err) {
+			return err
+		}
+		state.TargetEntries = append(state.TargetEntries, name)
+		return run.SetResult(state)
+	}
+
+	totalItems, totalBytes, err := scanRelocationSource(ctx, state.SourcePath)
+	if err != nil {
+		return abortRelocation(&state, err)
+	}
+	run.SetTotals(totalItems, totalBytes)
+
+	manifest, err := copyRelocationTree(ctx, state.SourcePath, state.TargetPath, claim, run.Advance)
+	if err != nil {
+		return abortRelocation(&state, err)
+	}
+
+	state.Status = RelocationStateVerifying
+	_ = run.SetResult(state)
+	if err := verifyRelocationTree(ctx, state.TargetPath, manifest, func() {
+		state.VerifiedItems++
+	}); err != nil {
+		return abortRelocation(&state, err)
+	}
+
+	// 복사 중 바뀐 파일을 맞추는 동안만 쓰기를 막는다. 이미 시작한 쓰기가 옛 root에 끝까지 써야 다시 맞출 수 있으므로 기다린다.
+	drainCtx, cancelDrain := context.WithTimeout(ctx, writeDrainTimeout)
+	release, err := m.spaceService.FreezeWrites(drainCtx, state.SpaceID)
+	cancelDrain()
+	if err != nil {
+		return abortRelocation(&state, err)
+	}
+	defer release()
+
+	// 복사하는 동안 다른 곳에서 대상에 쓴 항목이 있으면 새 root에 섞이므로 전환하지 않는다.
+	if err := checkTargetEntries(state.TargetPath, state.TargetEntries); err != nil {
+		return abortRelocation(&state, err)
+	}
+
+	// 여기서부터는 사용자가 취소할 수 없다. 서버 종료로 root 전환 전에 멈추면 다음 실행이 처음부터 다시 한다.
+	if !run.PreventCancel() {
+		return abortRelocation(&state, context.Canceled)
+	}
+	state.Status = RelocationStateSwitching
+	_ = run.SetResult(state)
+
+	resynced, err := resyncRelocationTree(ctx, state.SourcePath, state.TargetPath, manifest, claim)
+	if err != nil {
+		return abortRelocation(&state, err)
+	}
+	state.ResyncedItems = resynced
+
+	// DB 전환이 성공하면 새 root가 기준이 된다.
+	if _, err := m.spaceService.UpdateSpaceRoot(context.Background(), state.SpaceID, state.TargetPath); err != nil {
+		return abortRelocation(&state, err)
+	}
+	return m.finishSwitch(&state, run)
+}
+
+// finishSwitch는 root 전환 뒤 휴지통 경로와 검색 색인을 맞추고 완료 상태를 남깁니다.
+// 후속 작업의 실패는 기록만 하고 이전 자체는 완료로 봅니다.
+func (m *RelocationManager) finishSwitch(state *RelocationJob, run SpaceJobRun) error {
+	ctx := context.Background()
+	var followUpErrors []string
+	if m.trash != nil {
+		rewritten, err := m.trash.RewriteStoragePaths(ctx, state.SpaceID, state.SourcePath)
+		if err != nil {
+			followUpErrors = append(followUpErrors, fmt.Sprintf("trash paths: %v", err))
+		}
+		state.TrashItemsRewritten = rewritten
+	}
+	if m.searchIndex != nil {
+		if err := m.searchIndex.MarkSpaceDirty(ctx, state.SpaceID); err != nil {
+			followUpErrors = append(followUpErrors, fmt.Sprintf("search index: %v", err))
+		}
+	}
+
+	state.Status = RelocationStateCompleted
+	if len(followUpErrors) > 0 {
+		state.FailureReason = "relocated with follow-up errors: " + strings.Join(followUpErrors, "; ")
+	}
+	return run.SetResult(*state)
+}
+
+// Discard는 실패/취소로 끝난 작업이 대상 디렉터리에 만든 항목을 지웁니다.
+// 서버 재시작으로 Run이 정리하지 못하고 끝난 경우를 위한 것이며, root가 이미 대상으로 바뀌었으면 건드리지 않습니다.
+func (m *RelocationManager) Discard(ctx context.Context, job *RelocationJob) error {
+	spaceObj, err := m.spaceService.GetSpaceByID(ctx, job.SpaceID)
+	switch {
+	case errors.Is(err, ErrSpaceNotFound):
+	case err != nil:
+		return err
+	case filepath.Clean(spaceObj.SpacePath) == job.TargetPath:
+		return nil
+	}
+	return removeRelocationEntries(job.TargetPath, job.TargetEntries)
+}
+
+func abortRelocation(state *RelocationJob, cause error) error {
+	if cleanupErr := removeRelocationEntries(state.TargetPath, state.TargetEntries); cleanupErr != nil {
+		return fmt.Errorf("%w (target cleanup failed: %v)", cause, cleanupErr)
+	}
+	return cause
+}
+
+// validateRelocationTarget은 대상이 원본과 겹치지 않는 빈 디렉터리인지 확인합니다.
+func validateRelocationTarget(sourceRoot string, targetRoot string) error {
+	source := filepath.Clean(sourceRoot)
+	target := filepath.Clean(targetRoot)
+	if source == target {
+		return errors.New("target_path must differ from the current space root")
+	}
+	if isNestedPath(source, target) || isNestedPath(target, source) {
+		return errors.New("target_path must not be inside the current space root or contain it")
+	}
+
+	entries, err := os.ReadDir(target)
+	if err != nil {
+		return fmt.Errorf("failed to read target_path: %w", err)
+	}
+	if len(entries) > 0 {
+		return errors.New("target_path must be an empty directory")
+	}
+	return nil
+}
+
+// checkTargetOverlap은 대상이 다른 Space의 root와 같거나 그 안에 있거나 그것을 품지 않는지 확인합니다.
+func (m *RelocationManager) checkTargetOverlap(ctx context.Context, spaceID int64, targetRoot string) error {
+	spaces, err := m.spaceService.GetAllSpaces(ctx)
+	if err != nil {
+		return err
+	}
+	for _, other := range spaces {
+		if other.ID != spaceID && PathsOverlap(other.SpacePath, targetRoot) {
+			return fmt.Errorf("%w: overlaps the root of space %q", ErrRelocationTargetInUse, other.SpaceName)
+		}
+	}
+	return nil
+}
+
+// checkTargetEntries는 대상에 이 작업이 만든 항목(owned) 외에는 아무 것도 없는지 확인합니다.
+func checkTargetEntries(targetRoot string, owned []string) error {
+	entries, err := os.ReadDir(targetRoot)
+	if err != nil {
+		return fmt.Errorf("failed to read target_path: %w", err)
+	}
+	for _, entry := range entries {
+		if !slices.Contains(owned, entry.Name()) {
+			return fmt.Errorf("%w: %s was not created by this relocation", ErrRelocationTargetInUse, entry.Name())
+		}
+	}
+	return nil
+}
+
+// PathsOverlap은 두 경로가 같거나 한쪽이 다른 쪽 안에 있는지 확인합니다.
+func PathsOverlap(a string, b string) bool {
+	a, b = filepath.Clean(a), filepath.Clean(b)
+	return a == b || isNestedPath(a, b) || isNestedPath(b, a)
+}
+
+func isNestedPath(parent string, child string) bool {
+	rel, err := filepath.Rel(parent, child)
+	if err != nil {
+		return false
+	}
+	return rel != "." && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+func scanRelocationSource(ctx context.Context, sourceRoot string) (int, int64, error) {
+	items := 0
+	var totalBytes int64
+	err := filepath.WalkDir(sourceRoot, func(path string, entry fs.DirEntry, walkErr error) error {
+		if walkErr != nil {
+			return walkErr
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if path == sourceRoot {
+			return nil
+		}
+		items++
+		if entry.Type().IsRegular() {
+			info, err := entry.Info()
+			if err != nil {
+				return err
+			}
+			totalBytes += info.Size()
+		}
+		return nil
+	})
+	return items, totalBytes, err
+}
+
+// copyRelocationTree는 원본을 대상으로 복사합니다. 최상위 항목은 만들기 전에 claim으로 알립니다.
+func copyRelocationTree(ctx context.Context, sourceRoot string, targetRoot string, claim func(name string) error, progress func(items int, bytes int64)) (map[string]relocationFileDigest, error) {
+	manifest := make(map[string]relocationFileDigest)
+	err := filepath.WalkDir(sourceRoot, func(path string, entry fs.DirEntry, walkErr error) error {
+		if walkErr != nil {
+			return walkErr
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if path == sourceRoot {
+			return nil
+		}
+
+		relPath, err := filepath.Rel(sourceRoot, path)
+		if err != nil {
+			return err
+		}
+		if filepath.Dir(relPath) == "." {
+			if err := claim(relPath); err != nil {
+				return err
+			}
+		}
+		digest, copied, err := copyRelocationEntry(ctx, path, filepath.Join(targetRoot, relPath), entry, func(n int64) {
+			progress(0, n)
+		})
+		if err != nil {
+			return fmt.Errorf("failed to copy %s: %w", filepath.ToSlash(relPath), err)
+		}
+		if copied && digest != nil {
+			manifest[relPath] = *digest
+		}
+		progress(1, 0)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+// copyRelocationEntry는 디렉터리/일반 파일/심볼릭 링크를 복사합니다. 그 외 특수 파일은 건너뜁니다.
+func copyRelocationEntry(ctx context.Context, sourcePath string, targetPath string, entry fs.DirEntry, progress func(int64)) (*relocationFileDigest, bool, error) {
+	info, err := entry.Info()
+	if err != nil {
+		return nil, false, err
+	}
+
+	switch {
+	case entry.IsDir():
+		return nil, true, os.MkdirAll(targetPath, info.Mode().Perm()|0o700)
+	case entry.Type()&fs.ModeSymlink != 0:
+		linkTarget, err := os.Readlink(sourcePath)
+		if err != nil {
+			return nil, false, err
+		}
+		if err := os.Remove(targetPath); err != nil && !os.IsNotExist(err) {
+			return nil, false, err
+		}
+		return nil, true, os.Symlink(linkTarget, targetPath)
+	case entry.Type().IsRegular():
+		digest, err := copyRelocationFile(ctx, sourcePath, targetPath, info, progress)
+		if err != nil {
+			return nil, false, err
+		}
+		return digest, true, nil
+	default:
+		return nil, false, nil
+	}
+}
+
+func copyRelocationFile(ctx context.Context, sourcePath string, targetPath string, info os.FileInfo, progress func(int64)) (*relocationFileDigest, error) {
+	source, err := os.Open(sourcePath)
+	if err != nil {
+		return nil, err
+	}
+	defer source.Close()
+
+	target, err := os.OpenFile(targetPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, info.Mode().Perm())
+	if err != nil {
+		return nil, err
+	}
+
+	hasher := sha256.New()
+	written, copyErr := copyRelocationStream(ctx, io.MultiWriter(target, hasher), source, progress)
+	closeErr := target.Close()
+	if copyErr != nil {
+		return nil, copyErr
+	}
+	if closeErr != nil {
+		return nil, closeErr
+	}
+	if err := os.Chtimes(targetPath, info.ModTime(), info.ModTime()); err != nil {
+		return nil, err
+	}
+
+	return &relocationFileDigest{
+		Size:    written,
+		ModTime: info.ModTime(),
+		SHA256:  hex.EncodeToString(hasher.Sum(nil)),
+	}, nil
+}
+
+func copyRelocationStream(ctx context.Context, dst io.Writer, src io.Reader, progress func(int64)) (int64, error) {
+	buffer := make([]byte, relocationCopyBufferSize)
+	var written int64
+	for {
+		if err := ctx.Err(); err != nil {
+			return written, err
+		}
+		n, readErr := src.Read(buffer)
+		if n > 0 {
+			if _, err := dst.Write(buffer[:n]); err != nil {
+				return written, err
+			}
+			written += int64(n)
+			if progress != nil {
+				progress(int64(n))
+			}
+		}
+		if readErr == io.EOF {
+			return written, nil
+		}
+		if readErr != nil {
+			return written, readErr
+		}
+	}
+}
+
+func verifyRelocationTree(ctx context.Context, targetRoot string, manifest map[string]relocationFileDigest, progress func()) error {
+	relPaths := make([]string, 0, len(manifest))
+	for relPath := range manifest {
+		relPaths = append(relPaths, relPath)
+	}
+	sort.Strings(relPaths)
+
+	for _, relPath := range relPaths {
+		if err := verifyRelocationFile(ctx, filepath.Join(targetRoot, relPath), manifest[relPath]); err != nil {
+			return fmt.Errorf("checksum verification failed for %s: %w", filepath.ToSlash(relPath), err)
+		}
+		if progress != nil {
+			progress()
+		}
+	}
+	return nil
+}
+
+func verifyRelocationFile(ctx context.Context, targetPath string, expected relocationFileDigest) error {
+	file, err := os.Open(targetPath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	hasher := sha256.New()
+	size, err := copyRelocationStream(ctx, hasher, file, nil)
+	if err != nil {
+		return err
+	}
+	if size != expected.Size {
+		return fmt.Errorf("size mismatch: expected %d, got %d", expected.Size, size)
+	}
+	if actual := hex.EncodeToString(hasher.Sum(nil)); actual != expected.SHA256 {
+		return errors.New("sha256 mismatch")
+	}
+	return nil
+}
+
+// resyncRelocationTree는 쓰기 동결 상태에서 복사 이후 생기거나 바뀐 항목만 다시 맞춥니다.
+// resyncRelocationTree는 복사 이후 원본에서 바뀐 항목을 대상에 다시 맞춥니다.
+// 새 최상위 항목은 만들기 전에 claim으로 알립니다.
+func resyncRelocationTree(ctx context.Context, sourceRoot string, targetRoot string, manifest map[string]relocationFileDigest, claim func(name string) error) (int, error) {
+	resynced := 0
+	err := filepath.WalkDir(sourceRoot, func(path string, entry fs.DirEntry, walkErr error) error {
+		if walkErr != nil {
+			return walkErr
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if path == sourceRoot {
+			return nil
+		}
+
+		relPath, err := filepath.Rel(sourceRoot, path)
+		if err != nil {
+			return err
+		}
+		if filepath.Dir(relPath) == "." {
+			if err := claim(relPath); err != nil {
+				return err
+			}
+		}
+		targetPath := filepath.Join(targetRoot, relPath)
+
+		if entry.Type().IsRegular() {
+			info, err := entry.Info()
+			if err != nil {
+				return err
+			}
+			previous, known := manifest[relPath]
+			if known && previous.Size == info.Size() && previous.ModTime.Equal(info.ModTime()) {
+				return nil
+			}
+			digest, err := copyRelocationFile(ctx, path, targetPath, info, nil)
+			if err != nil {
+				return fmt.Errorf("failed to resync %s: %w", filepath.ToSlash(relPath), err)
+			}
+			if err := verifyRelocationFile(ctx, targetPath, *digest); err != nil {
+				return fmt.Errorf("checksum verification failed for %s: %w", filepath.ToSlash(relPath), err)
+			}
+			manifest[relPath] = *digest
+			resynced++
+			return nil
+		}
+
+		if _, err := os.Lstat(targetPath); err == nil {
+			return nil
+		}
+		if _, _, err := copyRelocationEntry(ctx, path, targetPath, entry, nil); err != nil {
+			return fmt.Errorf("failed to resync %s: %w", filepath.ToSlash(relPath), err)
+		}
+		resynced++
+		return nil
+	})
+	if err != nil {
+		return resynced, err
+	}
+
+	// 원본에서 사라진 항목은 대상에서도 제거한다.
+	var stale []string
+	err = filepath.WalkDir(targetRoot, func(path string, entry fs.DirEntry, walkErr error) error {
+		if walkErr != nil {
+			return walkErr
+		}
+		if path == targetRoot {
+			return nil
+		}
+		relPath, err := filepath.Rel(targetRoot, path)
+		if err != nil {
+			return err
+		}
+		if _, err := os.Lstat(filepath.Join(sourceRoot, relPath)); os.IsNotExist(err) {
+			stale = append(stale, path)
+			if entry.IsDir() {
+				return filepath.SkipDir
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return resynced, err
+	}
+	for _, path := range stale {
+		if err := os.RemoveAll(path); err != nil {
+			return resynced, err
+		}
+		resynced++
+	}
+	return resynced, nil
+}
+
+// removeRelocationEntries는 대상에서 이 작업이 만든 최상위 항목만 지웁니다. 다른 항목은 건드리지 않습니다.
+func removeRelocationEntries(targetRoot string, entries []string) error {
+	for _, name := range entries {
+		if err := os.RemoveAll(filepath.Join(targetRoot, name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
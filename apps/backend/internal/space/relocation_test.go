@@ -0,0 +1,510 @@
+package space_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"taeu.kr/cohesion/internal/space"
+	spaceStore "taeu.kr/cohesion/internal/space/store"
+)
+
+// recordingJobRun은 작업 큐 대신 Run이 남긴 진행률과 단계별 상태를 기록합니다.
+type recordingJobRun struct {
+	mu             sync.Mutex
+	result         []byte
+	totalItems     int
+	processedItems int
+	processedBytes int64
+	// canceled가 켜져 있으면 PreventCancel이 이미 취소된 작업처럼 false를 반환합니다.
+	canceled  bool
+	committed bool
+}
+
+func (r *recordingJobRun) SetTotals(items int, bytes int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.totalItems = items
+	r.processedItems = 0
+	r.processedBytes = 0
+}
+
+func (r *recordingJobRun) Advance(items int, bytes int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.processedItems += items
+	r.processedBytes += bytes
+}
+
+func (r *recordingJobRun) SetResult(v any) error {
+	encoded, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.result = encoded
+	return nil
+}
+
+func (r *recordingJobRun) PreventCancel() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.canceled {
+		return false
+	}
+	r.committed = true
+	return true
+}
+
+func (r *recordingJobRun) decode(t *testing.T, v any) {
+	t.Helper()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := json.Unmarshal(r.result, v); err != nil {
+		t.Fatalf("decode job result: %v", err)
+	}
+}
+
+func TestRelocationManager_CopiesVerifiesAndSwitchesRoot(t *testing.T) {
+	service, db := setupSlugSpaceService(t)
+	ctx := context.Background()
+
+	sourceRoot := t.TempDir()
+	targetRoot := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(sourceRoot, "docs", "nested"), 0o755); err != nil {
+		t.Fatalf("mkdir source: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(sourceRoot, "docs", "nested", "a.txt"), []byte("alpha"), 0o644); err != nil {
+		t.Fatalf("write source file: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Join(sourceRoot, ".cohesion_trash"), 0o755); err != nil {
+		t.Fatalf("mkdir trash: %v", err)
+	}
+	trashedPath := filepath.Join(sourceRoot, ".cohesion_trash", "1-abcd-old.txt")
+	if err := os.WriteFile(trashedPath, []byte("old"), 0o644); err != nil {
+		t.Fatalf("write trash file: %v", err)
+	}
+
+	created, err := service.CreateSpace(ctx, &space.CreateSpaceRequest{SpaceName: "Media", SpacePath: sourceRoot})
+	if err != nil {
+		t.Fatalf("create space: %v", err)
+	}
+
+	trashService := space.NewTrashService(spaceStore.NewTrashStore(db))
+	legacyItem, err := trashService.CreateTrashItem(ctx, &space.CreateTrashItemRequest{
+		SpaceID:      created.ID,
+		OriginalPath: "old.txt",
+		StoragePath:  trashedPath,
+		ItemName:     "old.txt",
+		DeletedBy:    "admin",
+	})
+	if err != nil {
+		t.Fatalf("create trash item: %v", err)
+	}
+
+	manager := space.NewRelocationManager(service)
+	manager.SetTrashRewriter(trashService)
+
+	plan, err := manager.Prepare(ctx, created.ID, &space.RelocateSpaceRequest{TargetPath: targetRoot})
+	if err != nil {
+		t.Fatalf("prepare relocation: %v", err)
+	}
+
+	run := &recordingJobRun{}
+	if err := manager.Run(ctx, plan, run); err != nil {
+		t.Fatalf("run relocation: %v", err)
+	}
+	var finished space.RelocationJob
+	run.decode(t, &finished)
+	if finished.Status != space.RelocationStateCompleted || !run.committed {
+		t.Fatalf("expected completed relocation, got %s (%s)", finished.Status, finished.FailureReason)
+	}
+	if finished.VerifiedItems != 2 || finished.TrashItemsRewritten != 1 || run.totalItems != 5 || run.processedItems != 5 {
+		t.Fatalf("unexpected relocation counters: %+v (run %+v)", finished, run)
+	}
+
+	content, err := os.ReadFile(filepath.Join(targetRoot, "docs", "nested", "a.txt"))
+	if err != nil || string(content) != "alpha" {
+		t.Fatalf("expected copied file in target, got %q (%v)", content, err)
+	}
+	if _, err := os.Stat(filepath.Join(sourceRoot, "docs", "nested", "a.txt")); err != nil {
+		t.Fatalf("expected source data to be kept, got %v", err)
+	}
+
+	updated, err := service.GetSpaceByID(ctx, created.ID)
+	if err != nil {
+		t.Fatalf("get space: %v", err)
+	}
+	if updated.SpacePath != targetRoot {
+		t.Fatalf("expected space root %q, got %q", targetRoot, updated.SpacePath)
+	}
+
+	rewritten, err := trashService.GetTrashItem(ctx, legacyItem.ID)
+	if err != nil {
+		t.Fatalf("get trash item: %v", err)
+	}
+	if rewritten.StoragePath != ".cohesion_trash/1-abcd-old.txt" {
+		t.Fatalf("expected relative trash storage path, got %q", rewritten.StoragePath)
+	}
+
+	if err := service.EnsureWritable(ctx, created.ID); err != nil {
+		t.Fatalf("expected writes to be allowed after relocation, got %v", err)
+	}
+}
+
+func TestRelocationManager_CancelClearsTarget(t *testing.T) {
+	service, _ := setupSlugSpaceService(t)
+	ctx := context.Background()
+
+	sourceRoot := t.TempDir()
+	targetRoot := t.TempDir()
+	if err := os.WriteFile(filepath.Join(sourceRoot, "a.txt"), []byte("alpha"), 0o644); err != nil {
+		t.Fatalf("write source file: %v", err)
+	}
+	created, err := service.CreateSpace(ctx, &space.CreateSpaceRequest{SpaceName: "Media", SpacePath: sourceRoot})
+	if err != nil {
+		t.Fatalf("create space: %v", err)
+	}
+
+	manager := space.NewRelocationManager(service)
+	plan, err := manager.Prepare(ctx, created.ID, &space.RelocateSpaceRequest{TargetPath: targetRoot})
+	if err != nil {
+		t.Fatalf("prepare relocation: %v", err)
+	}
+
+	// 복사를 마친 뒤 root 전환 직전에 취소가 들어온 경우입니다.
+	run := &recordingJobRun{canceled: true}
+	if err := manager.Run(ctx, plan, run); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected canceled relocation, got %v", err)
+	}
+	entries, err := os.ReadDir(targetRoot)
+	if err != nil {
+		t.Fatalf("read target: %v", err)
+	}
+	if len(entries) != 0 {
+		t.Fatalf("expected empty target after cancel, got %d entries", len(entries))
+	}
+
+	unchanged, err := service.GetSpaceByID(ctx, created.ID)
+	if err != nil {
+		t.Fatalf("get space: %v", err)
+	}
+	if unchanged.SpacePath != sourceRoot {
+		t.Fatalf("expected space root to stay %q, got %q", sourceRoot, unchanged.SpacePath)
+	}
+	if err := service.EnsureWritable(ctx, created.ID); err != nil {
+		t.Fatalf("expected writes after cancel, got %v", err)
+	}
+}
+
+func TestRelocationManager_ResumesInterruptedRun(t *testing.T) {
+	service, _ := setupSlugSpaceService(t)
+	ctx := context.Background()
+
+	sourceRoot := t.TempDir()
+	targetRoot := t.TempDir()
+	if err := os.WriteFile(filepath.Join(sourceRoot, "a.txt"), []byte("alpha"), 0o644); err != nil {
+		t.Fatalf("write source file: %v", err)
+	}
+	created, err := service.CreateSpace(ctx, &space.CreateSpaceRequest{SpaceName: "Media", SpacePath: sourceRoot})
+	if err != nil {
+		t.Fatalf("create space: %v", err)
+	}
+	manager := space.NewRelocationManager(service)
+	plan, err := manager.Prepare(ctx, created.ID, &space.RelocateSpaceRequest{TargetPath: targetRoot})
+	if err != nil {
+		t.Fatalf("prepare relocation: %v", err)
+	}
+
+	// 서버가 복사 도중 멈췄다면 대상에 반쯤 쓴 파일이 남아 있고, 작업 결과에 그 이름이 기록되어 있다.
+	// 실패로 끝난 작업의 Discard는 기록된 항목만 지우고, 다른 곳에서 생긴 항목은 남긴다.
+	plan.TargetEntries = []string{"a.txt", "gone.txt"}
+	foreign := filepath.Join(targetRoot, "other.txt")
+	if err := os.WriteFile(foreign, []byte("not ours"), 0o644); err != nil {
+		t.Fatalf("write foreign file: %v", err)
+	}
+	writeLeftovers := func() {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(targetRoot, "a.txt"), []byte("al"), 0o644); err != nil {
+			t.Fatalf("write partial copy: %v", err)
+		}
+		if err := os.WriteFile(filepath.Join(targetRoot, "gone.txt"), []byte("stale"), 0o644); err != nil {
+			t.Fatalf("write stale copy: %v", err)
+		}
+	}
+	writeLeftovers()
+	if err := manager.Discard(ctx, plan); err != nil {
+		t.Fatalf("discard leftovers: %v", err)
+	}
+	if entries, _ := os.ReadDir(targetRoot); len(entries) != 1 || entries[0].Name() != "other.txt" {
+		t.Fatalf("expected discard to keep only the foreign file, got %v", entries)
+	}
+
+	// 기록되지 않은 항목이 있으면 아무 것도 지우지 않고 실패한다.
+	writeLeftovers()
+	if err := manager.Run(ctx, plan, &recordingJobRun{}); !errors.Is(err, space.ErrRelocationTargetInUse) {
+		t.Fatalf("expected target in use error, got %v", err)
+	}
+	if _, err := os.Stat(foreign); err != nil {
+		t.Fatalf("expected foreign file to be kept, got %v", err)
+	}
+	if err := os.Remove(foreign); err != nil {
+		t.Fatalf("remove foreign file: %v", err)
+	}
+
+	// 다시 실행하면 기록된 항목을 지우고 처음부터 복사한다.
+	if err := manager.Run(ctx, plan, &recordingJobRun{}); err != nil {
+		t.Fatalf("rerun relocation: %v", err)
+	}
+	content, err := os.ReadFile(filepath.Join(targetRoot, "a.txt"))
+	if err != nil || string(content) != "alpha" {
+		t.Fatalf("expected a fresh copy in target, got %q (%v)", content, err)
+	}
+	if _, err := os.Stat(filepath.Join(targetRoot, "gone.txt")); !os.IsNotExist(err) {
+		t.Fatalf("expected leftovers to be cleared, got %v", err)
+	}
+
+	// root 전환 뒤에 멈췄다면 복사본을 건드리지 않고 후속 작업만 마친다.
+	run := &recordingJobRun{}
+	if err := manager.Run(ctx, plan, run); err != nil {
+		t.Fatalf("rerun switched relocation: %v", err)
+	}
+	var finished space.RelocationJob
+	run.decode(t, &finished)
+	if finished.Status != space.RelocationStateCompleted {
+		t.Fatalf("expected completed relocation, got %+v", finished)
+	}
+	if err := manager.Discard(ctx, plan); err != nil {
+		t.Fatalf("discard switched relocation: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(targetRoot, "a.txt")); err != nil {
+		t.Fatalf("expected data in the new root to be kept, got %v", err)
+	}
+
+	// 그 사이 다른 곳으로 옮겨졌다면 아무것도 하지 않고 실패한다.
+	if _, err := service.UpdateSpaceRoot(ctx, created.ID, t.TempDir()); err != nil {
+		t.Fatalf("move space root: %v", err)
+	}
+	if err := manager.Run(ctx, plan, &recordingJobRun{}); !errors.Is(err, space.ErrRelocationSourceChanged) {
+		t.Fatalf("expected source changed error, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(targetRoot, "a.txt")); err != nil {
+		t.Fatalf("expected target to be left alone, got %v", err)
+	}
+}
+
+func TestRelocationManager_RejectsInvalidTargets(t *testing.T) {
+	service, _ := setupSlugSpaceService(t)
+	ctx := context.Background()
+
+	sourceRoot := t.TempDir()
+	created, err := service.CreateSpace(ctx, &space.CreateSpaceRequest{SpaceName: "Media", SpacePath: sourceRoot})
+	if err != nil {
+		t.Fatalf("create space: %v", err)
+	}
+
+	nonEmpty := t.TempDir()
+	if err := os.WriteFile(filepath.Join(nonEmpty, "keep.txt"), []byte("x"), 0o644); err != nil {
+		t.Fatalf("write target file: %v", err)
+	}
+	nested := filepath.Join(sourceRoot, "inner")
+	if err := os.Mkdir(nested, 0o755); err != nil {
+		t.Fatalf("mkdir nested: %v", err)
+	}
+
+	manager := space.NewRelocationManager(service)
+	for _, target := range []string{nonEmpty, nested, sourceRoot, "relative/path"} {
+		if _, err := manager.Prepare(ctx, created.ID, &space.RelocateSpaceRequest{TargetPath: target}); err == nil || !strings.Contains(err.Error(), "validation failed") {
+			t.Fatalf("expected validation failure for %q, got %v", target, err)
+		}
+	}
+
+	missing := filepath.Join(t.TempDir(), "missing")
+	_, err = manager.Prepare(ctx, created.ID, &space.RelocateSpaceRequest{TargetPath: missing})
+	var rootErr *space.SpaceRootValidationError
+	if !errors.As(err, &rootErr) || rootErr.Result().Code != space.SpaceRootValidationCodeNotFound {
+		t.Fatalf("expected not_found root validation error, got %v", err)
+	}
+
+	// 다른 Space root 안쪽은 비어 있어도 대상이 될 수 없다.
+	otherRoot := t.TempDir()
+	if _, err := service.CreateSpace(ctx, &space.CreateSpaceRequest{SpaceName: "Other", SpacePath: otherRoot}); err != nil {
+		t.Fatalf("create other space: %v", err)
+	}
+	insideOther := filepath.Join(otherRoot, "inner")
+	if err := os.Mkdir(insideOther, 0o755); err != nil {
+		t.Fatalf("mkdir inside other root: %v", err)
+	}
+	if _, err := manager.Prepare(ctx, created.ID, &space.RelocateSpaceRequest{TargetPath: insideOther}); !errors.Is(err, space.ErrRelocationTargetInUse) {
+		t.Fatalf("expected target in use error, got %v", err)
+	}
+
+	// 요청 뒤에 대상이 다른 Space의 root가 되었으면 실행 시점에 거절하고 아무 것도 지우지 않는다.
+	claimedLater := t.TempDir()
+	plan, err := manager.Prepare(ctx, created.ID, &space.RelocateSpaceRequest{TargetPath: claimedLater})
+	if err != nil {
+		t.Fatalf("prepare relocation: %v", err)
+	}
+	if _, err := service.CreateSpace(ctx, &space.CreateSpaceRequest{SpaceName: "Late", SpacePath: claimedLater}); err != nil {
+		t.Fatalf("create late space: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(claimedLater, "late.txt"), []byte("late"), 0o644); err != nil {
+		t.Fatalf("write late space file: %v", err)
+	}
+	if err := manager.Run(ctx, plan, &recordingJobRun{}); !errors.Is(err, space.ErrRelocationTargetInUse) {
+		t.Fatalf("expected target in use error at run time, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(claimedLater, "late.txt")); err != nil {
+		t.Fatalf("expected the other space root to be left alone, got %v", err)
+	}
+}
+
+func TestFreezeWrites_BlocksUntilReleased(t *testing.T) {
+	service, _ := setupSlugSpaceService(t)
+	ctx := context.Background()
+
+	created, err := service.CreateSpace(ctx, &space.CreateSpaceRequest{SpaceName: "Media", SpacePath: t.TempDir()})
+	if err != nil {
+		t.Fatalf("create space: %v", err)
+	}
+
+	first, err := service.FreezeWrites(ctx, created.ID)
+	if err != nil {
+		t.Fatalf("freeze writes: %v", err)
+	}
+	second, err := service.FreezeWrites(ctx, created.ID)
+	if err != nil {
+		t.Fatalf("freeze writes again: %v", err)
+	}
+	if err := service.EnsureWritable(ctx, created.ID); !errors.Is(err, space.ErrSpaceReadOnly) {
+		t.Fatalf("expected read-only error, got %v", err)
+	}
+
+	first()
+	first()
+	if err := service.EnsureWritable(ctx, created.ID); !errors.Is(err, space.ErrSpaceReadOnly) {
+		t.Fatalf("expected space to stay frozen until every release, got %v", err)
+	}
+
+	second()
+	if err := service.EnsureWritable(ctx, created.ID); err != nil {
+		t.Fatalf("expected writes after release, got %v", err)
+	}
+}
+
+func TestFreezeWrites_WaitsForInFlightWrites(t *testing.T) {
+	service, _ := setupSlugSpaceService(t)
+	ctx := context.Background()
+
+	created, err := service.CreateSpace(ctx, &space.CreateSpaceRequest{SpaceName: "Media", SpacePath: t.TempDir()})
+	if err != nil {
+		t.Fatalf("create space: %v", err)
+	}
+	_, endWrite, err := service.BeginWrite(ctx, created.ID)
+	if err != nil {
+		t.Fatalf("begin write: %v", err)
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	if _, err := service.FreezeWrites(timeoutCtx, created.ID); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected freeze to give up while a write is in flight, got %v", err)
+	}
+	if err := service.EnsureWritable(ctx, created.ID); err != nil {
+		t.Fatalf("expected a failed freeze to be lifted, got %v", err)
+	}
+
+	frozen := make(chan func())
+	go func() {
+		release, err := service.FreezeWrites(ctx, created.ID)
+		if err != nil {
+			t.Errorf("freeze writes: %v", err)
+		}
+		frozen <- release
+	}()
+	// 동결을 건 뒤에는 새 쓰기를 받지 않지만, 이미 시작한 쓰기가 끝나기 전에는 돌아오지 않습니다.
+	deadline := time.Now().Add(time.Second)
+	for service.EnsureWritable(ctx, created.ID) == nil {
+		if time.Now().After(deadline) {
+			t.Fatal("expected freeze to block new writes")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if _, _, err := service.BeginWrite(ctx, created.ID); !errors.Is(err, space.ErrSpaceReadOnly) {
+		t.Fatalf("expected new write to be rejected while frozen, got %v", err)
+	}
+	select {
+	case <-frozen:
+		t.Fatal("freeze returned before the in-flight write ended")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	endWrite()
+	endWrite()
+	release := <-frozen
+	release()
+	if _, endWrite, err = service.BeginWrite(ctx, created.ID); err != nil {
+		t.Fatalf("expected writes after release, got %v", err)
+	}
+	endWrite()
+}
+
+func TestRelocationManager_InFlightWriteReachesNewRoot(t *testing.T) {
+	service, _ := setupSlugSpaceService(t)
+	ctx := context.Background()
+
+	sourceRoot := t.TempDir()
+	targetRoot := t.TempDir()
+	if err := os.WriteFile(filepath.Join(sourceRoot, "a.txt"), []byte("alpha"), 0o644); err != nil {
+		t.Fatalf("write source file: %v", err)
+	}
+	created, err := service.CreateSpace(ctx, &space.CreateSpaceRequest{SpaceName: "Media", SpacePath: sourceRoot})
+	if err != nil {
+		t.Fatalf("create space: %v", err)
+	}
+
+	// 이전을 시작하기 전에 쓰기를 시작해 두고, 동결이 걸린 뒤에 옛 root에 씁니다.
+	writing, endWrite, err := service.BeginWrite(ctx, created.ID)
+	if err != nil {
+		t.Fatalf("begin write: %v", err)
+	}
+	manager := space.NewRelocationManager(service)
+	plan, err := manager.Prepare(ctx, created.ID, &space.RelocateSpaceRequest{TargetPath: targetRoot})
+	if err != nil {
+		t.Fatalf("prepare relocation: %v", err)
+	}
+	done := make(chan error)
+	go func() { done <- manager.Run(ctx, plan, &recordingJobRun{}) }()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for service.EnsureWritable(ctx, created.ID) == nil {
+		if time.Now().After(deadline) {
+			t.Fatal("expected relocation to freeze writes")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if err := os.WriteFile(filepath.Join(writing.SpacePath, "late.txt"), []byte("late"), 0o644); err != nil {
+		t.Fatalf("write in-flight file: %v", err)
+	}
+	select {
+	case err := <-done:
+		t.Fatalf("relocation finished before the in-flight write ended: %v", err)
+	case <-time.After(20 * time.Millisecond):
+	}
+	endWrite()
+
+	if err := <-done; err != nil {
+		t.Fatalf("expected completed relocation, got %v", err)
+	}
+	content, err := os.ReadFile(filepath.Join(targetRoot, "late.txt"))
+	if err != nil || string(content) != "late" {
+		t.Fatalf("expected in-flight write to be carried to the new root, got %q (%v)", content, err)
+	}
+}
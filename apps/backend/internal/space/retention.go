@@ -0,0 +1,407 @@
+package space
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// MaxRetentionReasonLength는 보존 잠금 사유의 최대 길이(문자 수)입니다.
+const MaxRetentionReasonLength = 500
+
+var (
+	// ErrRetentionLocked는 보존 기간이 남았거나 법적 보존이 걸린 경로를 바꾸려 할 때입니다.
+	ErrRetentionLocked = errors.New("path is under retention")
+	// ErrRetentionShortened는 남은 보존 기간을 줄이거나 없애려 할 때입니다.
+	ErrRetentionShortened = errors.New("retention can only be extended")
+	// ErrRetentionLockActive는 아직 유효한 잠금을 지우려 할 때입니다.
+	ErrRetentionLockActive = errors.New("retention lock is still active")
+	// ErrRetentionLockNotFound는 해당 폴더에 잠금이 없을 때입니다.
+	ErrRetentionLockNotFound = errors.New("retention lock not found")
+	// ErrInvalidRetentionLock은 요청한 잠금 값이 잘못되었을 때입니다.
+	ErrInvalidRetentionLock = errors.New("invalid retention lock")
+)
+
+// RetentionLock은 폴더 하나에 거는 보존 잠금입니다. 잠금이 유효한 동안 폴더 안에 새 파일을 만들 수는 있지만
+// 이미 있는 파일·폴더는 덮어쓰거나 이름을 바꾸거나 옮기거나 지울 수 없습니다(WORM).
+type RetentionLock struct {
+	SpaceID int64 `json:"spaceId"`
+	// Path는 Space root 기준 폴더 경로입니다.
+	Path string `json:"path"`
+	// RetainUntil까지 잠깁니다. 한 번 정하면 늘릴 수만 있습니다.
+	RetainUntil *time.Time `json:"retainUntil,omitempty"`
+	// LegalHold가 켜져 있으면 RetainUntil과 관계없이 잠깁니다.
+	LegalHold bool      `json:"legalHold"`
+	Reason    string    `json:"reason,omitempty"`
+	CreatedBy string    `json:"createdBy"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedBy string    `json:"updatedBy"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// ActiveAt은 now 시점에 잠금이 유효한지 확인합니다.
+func (l *RetentionLock) ActiveAt(now time.Time) bool {
+	return l.LegalHold || (l.RetainUntil != nil && l.RetainUntil.After(now))
+}
+
+// retainsAt은 now 시점에 보존 기간이 남아 있는지 확인합니다.
+func (l *RetentionLock) retainsAt(now time.Time) bool {
+	return l.RetainUntil != nil && l.RetainUntil.After(now)
+}
+
+// RetentionLockRequest는 잠금을 만들거나 바꿀 때의 값입니다.
+type RetentionLockRequest struct {
+	Path        string     `json:"path"`
+	RetainUntil *time.Time `json:"retainUntil,omitempty"`
+	LegalHold   bool       `json:"legalHold"`
+	Reason      string     `json:"reason,omitempty"`
+}
+
+// RetentionLockStorer는 보존 잠금 저장소입니다.
+type RetentionLockStorer interface {
+	ListRetentionLocks(ctx context.Context, spaceID int64) ([]*RetentionLock, error)
+	SaveRetentionLock(ctx context.Context, lock *RetentionLock) error
+	// DeleteRetentionLock은 잠금이 없으면 ErrRetentionLockNotFound를 반환합니다.
+	DeleteRetentionLock(ctx context.Context, spaceID int64, path string) error
+}
+
+// RetentionLockedError는 잠긴 경로를 바꾸려 한 요청을 거절한 이유입니다.
+type RetentionLockedError struct {
+	SpaceID int64
+	// Path는 바꾸려 한 Space root 기준 경로입니다.
+	Path string
+	Lock RetentionLock
+}
+
+func (e *RetentionLockedError) Error() string {
+	if e.Lock.LegalHold {
+		return fmt.Sprintf("%s is under legal hold (%s)", e.Path, e.Lock.Path)
+	}
+	return fmt.Sprintf("%s is retained until %s (%s)", e.Path, e.Lock.RetainUntil.UTC().Format(time.RFC3339), e.Lock.Path)
+}
+
+func (e *RetentionLockedError) Is(target error) bool {
+	return target == ErrRetentionLocked
+}
+
+// Reason은 API 응답에 쓰는 사유입니다.
+func (e *RetentionLockedError) Reason() string {
+	if e.Lock.LegalHold {
+		return fmt.Sprintf("%s is under legal hold", e.Lock.Path)
+	}
+	return fmt.Sprintf("%s is retained until %s", e.Lock.Path, e.Lock.RetainUntil.UTC().Format(time.RFC3339))
+}
+
+// RetentionTarget은 바꾸려는 경로와 요청 정보입니다.
+type RetentionTarget struct {
+	SpaceID   int64
+	SpaceRoot string
+	AbsPath   string
+	// Operation은 감사 기록용 동작 이름입니다(write, delete, rename, move 등).
+	Operation string
+	Actor     string
+	// Source는 요청 경로입니다(rest, webdav, sftp, ftp).
+	Source string
+}
+
+// RetentionGuard는 모든 쓰기 경로(web, 휴지통, WebDAV, SFTP, FTP)가 경로를 바꾸기 전에 확인하는 기능입니다.
+type RetentionGuard interface {
+	// EnsureMutable은 이미 있는 경로를 덮어쓰거나 옮기거나 지울 수 있는지 확인합니다.
+	// 잠긴 폴더 안이거나 잠긴 폴더를 품은 경로면 *RetentionLockedError를 반환합니다. 없는 경로를 새로 만드는 것은 막지 않습니다.
+	EnsureMutable(ctx context.Context, target RetentionTarget) error
+}
+
+// RetentionDeniedEvent는 잠금 때문에 거절한 요청입니다.
+type RetentionDeniedEvent struct {
+	SpaceID   int64
+	Path      string
+	Operation string
+	Actor     string
+	Source    string
+	Lock      RetentionLock
+}
+
+// RetentionNotifier는 거절한 요청을 알리는 통로입니다.
+type RetentionNotifier interface {
+	NotifyRetentionDenied(ctx context.Context, event RetentionDeniedEvent)
+}
+
+// RetentionNotifierFunc는 함수를 RetentionNotifier로 씁니다.
+type RetentionNotifierFunc func(ctx context.Context, event RetentionDeniedEvent)
+
+func (f RetentionNotifierFunc) NotifyRetentionDenied(ctx context.Context, event RetentionDeniedEvent) {
+	f(ctx, event)
+}
+
+// RetentionService는 폴더별 보존 잠금과 법적 보존을 관리하고 모든 쓰기 경로에 적용합니다.
+type RetentionService struct {
+	store    RetentionLockStorer
+	notifier RetentionNotifier
+	now      func() time.Time
+}
+
+// NewRetentionService는 store에 잠금을 두는 서비스를 만듭니다. store가 nil이면 메모리에 둡니다.
+func NewRetentionService(store RetentionLockStorer) *RetentionService {
+	if store == nil {
+		store = NewMemoryRetentionLockStore()
+	}
+	return &RetentionService{store: store, now: time.Now}
+}
+
+// SetNotifier는 잠금 때문에 거절한 요청을 알릴 대상을 설정합니다.
+func (s *RetentionService) SetNotifier(notifier RetentionNotifier) {
+	s.notifier = notifier
+}
+
+// ListLocks는 Space의 잠금을 경로 순서로 반환합니다.
+func (s *RetentionService) ListLocks(ctx context.Context, spaceID int64) ([]*RetentionLock, error) {
+	locks, err := s.store.ListRetentionLocks(ctx, spaceID)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(locks, func(i, j int) bool { return locks[i].Path < locks[j].Path })
+	return locks, nil
+}
+
+// HasActiveLocks는 Space에 유효한 잠금이 하나라도 있는지 확인합니다. root 데이터를 지우는 Space 삭제를 막을 때 씁니다.
+func (s *RetentionService) HasActiveLocks(ctx context.Context, spaceID int64) (bool, error) {
+	locks, err := s.store.ListRetentionLocks(ctx, spaceID)
+	if err != nil {
+		return false, err
+	}
+	now := s.now()
+	for _, lock := range locks {
+		if lock.ActiveAt(now) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// SaveLock은 spaceRoot 아래 폴더에 잠금을 걸거나 바꿉니다. previous는 바꾸기 전 잠금이며 새로 걸면 nil입니다.
+// 남은 보존 기간은 늘릴 수만 있고, 법적 보존은 켜고 끌 수 있습니다.
+func (s *RetentionService) SaveLock(ctx context.Context, spaceID int64, spaceRoot string, req RetentionLockRequest, actor string) (lock *RetentionLock, previous *RetentionLock, err error) {
+	lockPath, err := normalizeRetentionPath(req.Path)
+	if err != nil {
+		return nil, nil, err
+	}
+	info, err := os.Stat(filepath.Join(spaceRoot, filepath.FromSlash(lockPath)))
+	if err != nil || !info.IsDir() {
+		return nil, nil, fmt.Errorf("%w: path must be an existing folder", ErrInvalidRetentionLock)
+	}
+	reason := strings.TrimSpace(req.Reason)
+	if len([]rune(reason)) > MaxRetentionReasonLength {
+		return nil, nil, fmt.Errorf("%w: reason must be at most %d characters", ErrInvalidRetentionLock, MaxRetentionReasonLength)
+	}
+
+	locks, err := s.store.ListRetentionLocks(ctx, spaceID)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, existing := range locks {
+		if existing.Path == lockPath {
+			previous = existing
+			break
+		}
+	}
+
+	now := s.now()
+	var retainUntil *time.Time
+	if req.RetainUntil != nil {
+		value := req.RetainUntil.UTC().Truncate(time.Second)
+		retainUntil = &value
+	}
+	unchanged := previous != nil && previous.RetainUntil != nil && retainUntil != nil && retainUntil.Equal(*previous.RetainUntil)
+	if retainUntil != nil && !retainUntil.After(now) && !unchanged {
+		return nil, nil, fmt.Errorf("%w: retainUntil must be in the future", ErrInvalidRetentionLock)
+	}
+	if previous != nil && previous.retainsAt(now) && (retainUntil == nil || retainUntil.Before(*previous.RetainUntil)) {
+		return nil, nil, ErrRetentionShortened
+	}
+	// 기존 잠금은 법적 보존만 풀어 비활성으로 둘 수 있고, 그 뒤에 지울 수 있습니다.
+	if retainUntil == nil && !req.LegalHold && previous == nil {
+		return nil, nil, fmt.Errorf("%w: retainUntil or legalHold is required", ErrInvalidRetentionLock)
+	}
+
+	lock = &RetentionLock{
+		SpaceID:     spaceID,
+		Path:        lockPath,
+		RetainUntil: retainUntil,
+		LegalHold:   req.LegalHold,
+		Reason:      reason,
+		CreatedBy:   actor,
+		CreatedAt:   now,
+		UpdatedBy:   actor,
+		UpdatedAt:   now,
+	}
+	if previous != nil {
+		lock.CreatedBy = previous.CreatedBy
+		lock.CreatedAt = previous.CreatedAt
+	}
+	if err := s.store.SaveRetentionLock(ctx, lock); err != nil {
+		return nil, nil, err
+	}
+	return lock, previous, nil
+}
+
+// DeleteLock은 보존 기간이 지나고 법적 보존도 풀린 잠금을 지웁니다. 유효한 잠금이면 ErrRetentionLockActive입니다.
+func (s *RetentionService) DeleteLock(ctx context.Context, spaceID int64, lockPath string) (*RetentionLock, error) {
+	normalized, err := normalizeRetentionPath(lockPath)
+	if err != nil {
+		return nil, err
+	}
+	locks, err := s.store.ListRetentionLocks(ctx, spaceID)
+	if err != nil {
+		return nil, err
+	}
+	for _, lock := range locks {
+		if lock.Path != normalized {
+			continue
+		}
+		if lock.ActiveAt(s.now()) {
+			return nil, ErrRetentionLockActive
+		}
+		if err := s.store.DeleteRetentionLock(ctx, spaceID, normalized); err != nil {
+			return nil, err
+		}
+		return lock, nil
+	}
+	return nil, ErrRetentionLockNotFound
+}
+
+// EnsureMutable은 RetentionGuard를 구현합니다.
+func (s *RetentionService) EnsureMutable(ctx context.Context, target RetentionTarget) error {
+	if s == nil {
+		return nil
+	}
+	rel, lock, err := s.activeLockFor(ctx, target)
+	if err != nil || lock == nil {
+		return err
+	}
+	log.Warn().
+		Int64("space_id", target.SpaceID).
+		Str("path", rel).
+		Str("lock_path", lock.Path).
+		Str("operation", target.Operation).
+		Str("source", target.Source).
+		Msg("mutation rejected by retention lock")
+	if s.notifier != nil {
+		s.notifier.NotifyRetentionDenied(context.WithoutCancel(ctx), RetentionDeniedEvent{
+			SpaceID:   target.SpaceID,
+			Path:      rel,
+			Operation: target.Operation,
+			Actor:     target.Actor,
+			Source:    target.Source,
+			Lock:      *lock,
+		})
+	}
+	return &RetentionLockedError{SpaceID: target.SpaceID, Path: rel, Lock: *lock}
+}
+
+// CheckMutable은 EnsureMutable과 같은 판정을 하지만 로그와 알림을 남기지 않습니다.
+// 실제로 바꾸지 않는 미리 보기에서 씁니다.
+func (s *RetentionService) CheckMutable(ctx context.Context, target RetentionTarget) error {
+	if s == nil {
+		return nil
+	}
+	rel, lock, err := s.activeLockFor(ctx, target)
+	if err != nil || lock == nil {
+		return err
+	}
+	return &RetentionLockedError{SpaceID: target.SpaceID, Path: rel, Lock: *lock}
+}
+
+// activeLockFor는 target과 겹치는 유효한 잠금을 찾습니다. Space 밖이거나 아직 없는 경로면 nil입니다.
+func (s *RetentionService) activeLockFor(ctx context.Context, target RetentionTarget) (string, *RetentionLock, error) {
+	rel, err := filepath.Rel(target.SpaceRoot, target.AbsPath)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", nil, nil
+	}
+	if _, err := os.Lstat(target.AbsPath); os.IsNotExist(err) {
+		return "", nil, nil
+	}
+	rel = filepath.ToSlash(rel)
+
+	locks, err := s.store.ListRetentionLocks(ctx, target.SpaceID)
+	if err != nil {
+		return "", nil, err
+	}
+	now := s.now()
+	for _, lock := range locks {
+		if lock.ActiveAt(now) && retentionPathsOverlap(rel, lock.Path) {
+			return rel, lock, nil
+		}
+	}
+	return rel, nil, nil
+}
+
+var _ RetentionGuard = (*RetentionService)(nil)
+
+// retentionPathsOverlap은 rel이 잠긴 폴더 안이거나 잠긴 폴더를 품는지 확인합니다.
+func retentionPathsOverlap(rel, lockPath string) bool {
+	if rel == "." || rel == lockPath {
+		return true
+	}
+	return strings.HasPrefix(rel, lockPath+"/") || strings.HasPrefix(lockPath, rel+"/")
+}
+
+func normalizeRetentionPath(raw string) (string, error) {
+	trimmed := strings.TrimSpace(strings.ReplaceAll(raw, `\`, "/"))
+	cleaned := strings.TrimPrefix(path.Clean("/"+trimmed), "/")
+	if trimmed == "" || cleaned == "" {
+		return "", fmt.Errorf("%w: path must be a folder inside the Space", ErrInvalidRetentionLock)
+	}
+	return cleaned, nil
+}
+
+// MemoryRetentionLockStore는 프로세스 메모리에만 잠금을 두는 RetentionLockStorer입니다.
+type MemoryRetentionLockStore struct {
+	mu    sync.RWMutex
+	locks map[int64]map[string]RetentionLock
+}
+
+func NewMemoryRetentionLockStore() *MemoryRetentionLockStore {
+	return &MemoryRetentionLockStore{locks: make(map[int64]map[string]RetentionLock)}
+}
+
+func (s *MemoryRetentionLockStore) ListRetentionLocks(ctx context.Context, spaceID int64) ([]*RetentionLock, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	locks := make([]*RetentionLock, 0, len(s.locks[spaceID]))
+	for _, lock := range s.locks[spaceID] {
+		copied := lock
+		locks = append(locks, &copied)
+	}
+	return locks, nil
+}
+
+func (s *MemoryRetentionLockStore) SaveRetentionLock(ctx context.Context, lock *RetentionLock) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.locks[lock.SpaceID] == nil {
+		s.locks[lock.SpaceID] = make(map[string]RetentionLock)
+	}
+	s.locks[lock.SpaceID][lock.Path] = *lock
+	return nil
+}
+
+func (s *MemoryRetentionLockStore) DeleteRetentionLock(ctx context.Context, spaceID int64, lockPath string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.locks[spaceID][lockPath]; !ok {
+		return ErrRetentionLockNotFound
+	}
+	delete(s.locks[spaceID], lockPath)
+	return nil
+}
+
+var _ RetentionLockStorer = (*MemoryRetentionLockStore)(nil)
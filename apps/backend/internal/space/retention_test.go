@@ -0,0 +1,128 @@
+package space_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"taeu.kr/cohesion/internal/space"
+	spaceStore "taeu.kr/cohesion/internal/space/store"
+)
+
+func TestRetentionService_LocksCanOnlyBeExtendedAndDeletedOnceInactive(t *testing.T) {
+	service, db := setupSlugSpaceService(t)
+	ctx := context.Background()
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "contracts"), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	created, err := service.CreateSpace(ctx, &space.CreateSpaceRequest{SpaceName: "Finance", SpacePath: root})
+	if err != nil {
+		t.Fatalf("create space: %v", err)
+	}
+
+	retention := space.NewRetentionService(spaceStore.NewRetentionStore(db))
+	until := time.Now().Add(24 * time.Hour).UTC().Truncate(time.Second)
+	if _, _, err := retention.SaveLock(ctx, created.ID, root, space.RetentionLockRequest{Path: "missing", RetainUntil: &until}, "alice"); !errors.Is(err, space.ErrInvalidRetentionLock) {
+		t.Fatalf("expected missing folder to be rejected, got %v", err)
+	}
+	lock, previous, err := retention.SaveLock(ctx, created.ID, root, space.RetentionLockRequest{Path: "/contracts/", RetainUntil: &until, Reason: "7년 보관"}, "alice")
+	if err != nil || previous != nil {
+		t.Fatalf("save lock: %v (previous=%+v)", err, previous)
+	}
+	if lock.Path != "contracts" || !lock.RetainUntil.Equal(until) || lock.CreatedBy != "alice" {
+		t.Fatalf("unexpected lock: %+v", lock)
+	}
+
+	shorter := until.Add(-time.Hour)
+	if _, _, err := retention.SaveLock(ctx, created.ID, root, space.RetentionLockRequest{Path: "contracts", RetainUntil: &shorter}, "bob"); !errors.Is(err, space.ErrRetentionShortened) {
+		t.Fatalf("expected shortening to be rejected, got %v", err)
+	}
+	if _, _, err := retention.SaveLock(ctx, created.ID, root, space.RetentionLockRequest{Path: "contracts", LegalHold: true}, "bob"); !errors.Is(err, space.ErrRetentionShortened) {
+		t.Fatalf("expected removing retainUntil to be rejected, got %v", err)
+	}
+	longer := until.Add(24 * time.Hour)
+	lock, previous, err = retention.SaveLock(ctx, created.ID, root, space.RetentionLockRequest{Path: "contracts", RetainUntil: &longer, LegalHold: true}, "bob")
+	if err != nil {
+		t.Fatalf("extend lock: %v", err)
+	}
+	if previous == nil || !previous.RetainUntil.Equal(until) || lock.CreatedBy != "alice" || lock.UpdatedBy != "bob" || !lock.LegalHold {
+		t.Fatalf("unexpected extended lock: %+v (previous=%+v)", lock, previous)
+	}
+
+	if _, err := retention.DeleteLock(ctx, created.ID, "contracts"); !errors.Is(err, space.ErrRetentionLockActive) {
+		t.Fatalf("expected active lock delete to fail, got %v", err)
+	}
+	if active, err := retention.HasActiveLocks(ctx, created.ID); err != nil || !active {
+		t.Fatalf("expected active locks, got %v (%v)", active, err)
+	}
+
+	// 보존 기간이 남지 않은 법적 보존 잠금은 법적 보존을 풀면 지울 수 있다.
+	if err := os.MkdirAll(filepath.Join(root, "litigation"), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if _, _, err := retention.SaveLock(ctx, created.ID, root, space.RetentionLockRequest{Path: "litigation", LegalHold: true}, "alice"); err != nil {
+		t.Fatalf("save legal hold: %v", err)
+	}
+	if _, err := retention.DeleteLock(ctx, created.ID, "litigation"); !errors.Is(err, space.ErrRetentionLockActive) {
+		t.Fatalf("expected legal hold delete to fail, got %v", err)
+	}
+	if _, _, err := retention.SaveLock(ctx, created.ID, root, space.RetentionLockRequest{Path: "litigation"}, "alice"); err != nil {
+		t.Fatalf("release legal hold: %v", err)
+	}
+	if _, err := retention.DeleteLock(ctx, created.ID, "litigation"); err != nil {
+		t.Fatalf("delete released lock: %v", err)
+	}
+	locks, err := retention.ListLocks(ctx, created.ID)
+	if err != nil || len(locks) != 1 || locks[0].Path != "contracts" {
+		t.Fatalf("expected only contracts lock to remain, got %+v (%v)", locks, err)
+	}
+}
+
+func TestRetentionService_EnsureMutableBlocksExistingPathsInsideAndAboveLock(t *testing.T) {
+	ctx := context.Background()
+	root := t.TempDir()
+	for _, dir := range []string{"finance/contracts", "finance/drafts"} {
+		if err := os.MkdirAll(filepath.Join(root, filepath.FromSlash(dir)), 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+	}
+	signed := filepath.Join(root, "finance", "contracts", "signed.pdf")
+	if err := os.WriteFile(signed, []byte("pdf"), 0o644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+
+	retention := space.NewRetentionService(nil)
+	var events []space.RetentionDeniedEvent
+	retention.SetNotifier(space.RetentionNotifierFunc(func(ctx context.Context, event space.RetentionDeniedEvent) {
+		events = append(events, event)
+	}))
+	if _, _, err := retention.SaveLock(ctx, 1, root, space.RetentionLockRequest{Path: "finance/contracts", LegalHold: true}, "alice"); err != nil {
+		t.Fatalf("save lock: %v", err)
+	}
+
+	target := func(rel, operation string) space.RetentionTarget {
+		return space.RetentionTarget{SpaceID: 1, SpaceRoot: root, AbsPath: filepath.Join(root, filepath.FromSlash(rel)), Operation: operation, Actor: "bob", Source: "sftp"}
+	}
+	var locked *space.RetentionLockedError
+	if err := retention.EnsureMutable(ctx, target("finance/contracts/signed.pdf", "write")); !errors.As(err, &locked) || locked.Lock.Path != "finance/contracts" {
+		t.Fatalf("expected existing file to be locked, got %v", err)
+	}
+	// 잠긴 폴더를 품은 상위 폴더도 옮기거나 지울 수 없다.
+	if err := retention.EnsureMutable(ctx, target("finance", "delete")); !errors.Is(err, space.ErrRetentionLocked) {
+		t.Fatalf("expected ancestor folder to be locked, got %v", err)
+	}
+	if err := retention.EnsureMutable(ctx, target("finance/contracts/new.pdf", "write")); err != nil {
+		t.Fatalf("expected new file inside lock to be allowed, got %v", err)
+	}
+	if err := retention.EnsureMutable(ctx, target("finance/drafts", "delete")); err != nil {
+		t.Fatalf("expected sibling folder to be mutable, got %v", err)
+	}
+
+	if len(events) != 2 || events[0].Path != "finance/contracts/signed.pdf" || events[0].Operation != "write" || events[0].Source != "sftp" || events[1].Path != "finance" {
+		t.Fatalf("unexpected denied events: %+v", events)
+	}
+}
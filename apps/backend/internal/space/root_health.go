@@ -0,0 +1,395 @@
+package space
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/shirou/gopsutil/v4/disk"
+	"taeu.kr/cohesion/internal/platform/logging"
+)
+
+const (
+	// RootMarkerFileName은 Space root가 기대한 디스크/마운트인지 확인하는 마커 파일입니다.
+	RootMarkerFileName = ".cohesion_space"
+
+	DefaultRootHealthInterval = 30 * time.Second
+	rootHealthCheckTimeout    = 10 * time.Second
+	rootLowFreeSpaceBytes     = 1 << 30
+)
+
+// ErrSpaceOffline은 Space root가 점검에 실패해 접근할 수 없음을 나타냅니다.
+var ErrSpaceOffline = errors.New("space storage is offline")
+
+type RootHealthStatus string
+
+const (
+	RootHealthOnline  RootHealthStatus = "online"
+	RootHealthOffline RootHealthStatus = "offline"
+)
+
+// offline 사유
+const (
+	RootHealthReasonMissing       = "root_missing"
+	RootHealthReasonNotDirectory  = "root_not_directory"
+	RootHealthReasonUnreadable    = "root_unreadable"
+	RootHealthReasonUnresponsive  = "root_unresponsive"
+	RootHealthReasonMarkerMissing = "marker_missing"
+	RootHealthReasonMarkerInvalid = "marker_mismatch"
+	RootHealthReasonNotWritable   = "root_not_writable"
+)
+
+// RootHealth는 Space root 한 곳의 마지막 점검 결과입니다.
+type RootHealth struct {
+	SpaceID        int64            `json:"space_id"`
+	Status         RootHealthStatus `json:"status"`
+	Reason         string           `json:"reason,omitempty"`
+	Exists         bool             `json:"exists"`
+	MarkerVerified bool             `json:"marker_verified"`
+	Writable       bool             `json:"writable"`
+	FreeBytes      uint64           `json:"free_bytes"`
+	TotalBytes     uint64           `json:"total_bytes"`
+	LowFreeSpace   bool             `json:"low_free_space"`
+	CheckedAt      time.Time        `json:"checked_at"`
+	OfflineSince   *time.Time       `json:"offline_since,omitempty"`
+}
+
+func (h RootHealth) Online() bool {
+	return h.Status != RootHealthOffline
+}
+
+// rootMarker는 마커 파일 내용입니다.
+type rootMarker struct {
+	SpaceID   int64     `json:"space_id"`
+	Marker    string    `json:"marker"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// rootMarkerAssignable은 root 마커 ID를 저장할 수 있는 Store입니다.
+type rootMarkerAssignable interface {
+	AssignRootMarker(ctx context.Context, id int64, marker string) error
+}
+
+// RootHealth는 Space의 마지막 root 점검 결과를 반환합니다. 아직 점검 전이면 false입니다.
+func (s *Service) RootHealth(spaceID int64) (RootHealth, bool) {
+	s.rootHealthMu.Lock()
+	defer s.rootHealthMu.Unlock()
+	health, ok := s.rootHealth[spaceID]
+	return health, ok
+}
+
+// EnsureOnline은 마지막 점검에서 root가 offline이면 ErrSpaceOffline을 반환합니다.
+// 점검 전인 Space는 허용합니다.
+func (s *Service) EnsureOnline(spaceID int64) error {
+	health, ok := s.RootHealth(spaceID)
+	if !ok || health.Online() {
+		return nil
+	}
+	return fmt.Errorf("%w: space %d (%s)", ErrSpaceOffline, spaceID, health.Reason)
+}
+
+// RefreshRootHealth는 등록된 모든 Space root를 점검하고, 사라진 Space의 결과는 정리합니다.
+func (s *Service) RefreshRootHealth(ctx context.Context) error {
+	spaces, err := s.GetAllSpaces(ctx)
+	if err != nil {
+		return err
+	}
+
+	known := make(map[int64]struct{}, len(spaces))
+	for _, sp := range spaces {
+		known[sp.ID] = struct{}{}
+		s.CheckSpaceRoot(ctx, sp)
+	}
+
+	s.rootHealthMu.Lock()
+	for spaceID := range s.rootHealth {
+		if _, ok := known[spaceID]; !ok {
+			delete(s.rootHealth, spaceID)
+		}
+	}
+	s.rootHealthMu.Unlock()
+	return nil
+}
+
+// CheckSpaceRoot는 Space root 하나를 점검해 결과를 기록하고 반환합니다.
+// 응답하지 않는 네트워크 마운트에 막히지 않도록 점검은 제한 시간 안에서만 기다립니다.
+func (s *Service) CheckSpaceRoot(ctx context.Context, sp *Space) RootHealth {
+	s.rootHealthMu.Lock()
+	if s.rootCheckInflight[sp.ID] {
+		s.rootHealthMu.Unlock()
+		return s.recordRootHealth(sp, RootHealth{SpaceID: sp.ID, Status: RootHealthOffline, Reason: RootHealthReasonUnresponsive})
+	}
+	s.rootCheckInflight[sp.ID] = true
+	s.rootHealthMu.Unlock()
+
+	resultCh := make(chan RootHealth, 1)
+	go func() {
+		health := s.inspectSpaceRoot(context.WithoutCancel(ctx), sp)
+		s.rootHealthMu.Lock()
+		delete(s.rootCheckInflight, sp.ID)
+		s.rootHealthMu.Unlock()
+		resultCh <- health
+	}()
+
+	timer := time.NewTimer(rootHealthCheckTimeout)
+	defer timer.Stop()
+	select {
+	case health := <-resultCh:
+		return s.recordRootHealth(sp, health)
+	case <-timer.C:
+		return s.recordRootHealth(sp, RootHealth{SpaceID: sp.ID, Status: RootHealthOffline, Reason: RootHealthReasonUnresponsive})
+	case <-ctx.Done():
+		if health, ok := s.RootHealth(sp.ID); ok {
+			return health
+		}
+		return RootHealth{SpaceID: sp.ID, Status: RootHealthOnline}
+	}
+}
+
+// inspectSpaceRoot는 존재 여부, 마커, 쓰기 가능 여부, 남은 공간 순서로 점검합니다.
+func (s *Service) inspectSpaceRoot(ctx context.Context, sp *Space) RootHealth {
+	health := RootHealth{SpaceID: sp.ID, Status: RootHealthOffline}
+
+	info, err := os.Stat(sp.SpacePath)
+	switch {
+	case os.IsNotExist(err):
+		health.Reason = RootHealthReasonMissing
+		return health
+	case err != nil:
+		health.Reason = RootHealthReasonUnreadable
+		return health
+	case !info.IsDir():
+		health.Reason = RootHealthReasonNotDirectory
+		return health
+	}
+	health.Exists = true
+
+	// read_only/archived Space는 읽기 전용 마운트일 수 있으므로 쓰기 점검과 마커 생성을 건너뜁니다.
+	writesExpected := sp.CheckWritable() == nil
+
+	verified, reason := s.verifyRootMarker(ctx, sp, writesExpected)
+	if reason != "" {
+		health.Reason = reason
+		return health
+	}
+	health.MarkerVerified = verified
+
+	if writesExpected {
+		if err := probeRootWritable(sp.SpacePath); err != nil {
+			health.Reason = RootHealthReasonNotWritable
+			return health
+		}
+		health.Writable = true
+	}
+
+	if usage, err := disk.UsageWithContext(ctx, sp.SpacePath); err == nil {
+		health.FreeBytes = usage.Free
+		health.TotalBytes = usage.Total
+		health.LowFreeSpace = usage.Free < rootLowFreeSpaceBytes
+	}
+
+	health.Status = RootHealthOnline
+	return health
+}
+
+// verifyRootMarker는 root의 마커가 DB에 기록된 값과 같은지 확인합니다.
+// 기록된 값이 없으면(새 Space, 기존 데이터) 현재 마커를 채택하거나 새로 만듭니다.
+func (s *Service) verifyRootMarker(ctx context.Context, sp *Space, writesExpected bool) (bool, string) {
+	assigner, ok := s.store.(rootMarkerAssignable)
+	if !ok {
+		return false, ""
+	}
+
+	markerPath := filepath.Join(sp.SpacePath, RootMarkerFileName)
+	current, readErr := readRootMarker(markerPath)
+
+	if sp.RootMarker != "" {
+		switch {
+		case os.IsNotExist(readErr):
+			return false, RootHealthReasonMarkerMissing
+		case readErr != nil, current.Marker != sp.RootMarker:
+			return false, RootHealthReasonMarkerInvalid
+		}
+		return true, ""
+	}
+
+	if readErr == nil {
+		if current.SpaceID != sp.ID || current.Marker == "" {
+			return false, RootHealthReasonMarkerInvalid
+		}
+	} else {
+		if !os.IsNotExist(readErr) {
+			return false, RootHealthReasonMarkerInvalid
+		}
+		if !writesExpected {
+			return false, ""
+		}
+		markerID, err := newRootMarkerID()
+		if err != nil {
+			return false, ""
+		}
+		current = rootMarker{SpaceID: sp.ID, Marker: markerID, CreatedAt: time.Now().UTC()}
+		if err := writeRootMarker(markerPath, current); err != nil {
+			return false, RootHealthReasonNotWritable
+		}
+	}
+
+	if err := assigner.AssignRootMarker(ctx, sp.ID, current.Marker); err != nil {
+		return false, ""
+	}
+	sp.RootMarker = current.Marker
+	return true, ""
+}
+
+func (s *Service) recordRootHealth(sp *Space, health RootHealth) RootHealth {
+	health.CheckedAt = time.Now().UTC()
+
+	s.rootHealthMu.Lock()
+	previous, seen := s.rootHealth[sp.ID]
+	if !health.Online() {
+		switch {
+		case seen && previous.OfflineSince != nil:
+			health.OfflineSince = previous.OfflineSince
+		default:
+			offlineSince := health.CheckedAt
+			health.OfflineSince = &offlineSince
+		}
+	}
+	s.rootHealth[sp.ID] = health
+	s.rootHealthMu.Unlock()
+
+	switch {
+	case !health.Online() && (!seen || previous.Online()):
+		logging.Event(log.Warn(), logging.ComponentStorage, "warn.space.root_offline").
+			Int64("space_id", sp.ID).
+			Str("space_path", sp.SpacePath).
+			Str("reason", health.Reason).
+			Msg("space root went offline")
+	case health.Online() && seen && !previous.Online():
+		logging.Event(log.Info(), logging.ComponentStorage, "info.space.root_online").
+			Int64("space_id", sp.ID).
+			Str("space_path", sp.SpacePath).
+			Msg("space root is back online")
+	}
+	return health
+}
+
+func newRootMarkerID() (string, error) {
+	randomBytes := make([]byte, 16)
+	if _, err := rand.Read(randomBytes); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(randomBytes), nil
+}
+
+func readRootMarker(markerPath string) (rootMarker, error) {
+	var marker rootMarker
+	data, err := os.ReadFile(markerPath)
+	if err != nil {
+		return marker, err
+	}
+	if err := json.Unmarshal(data, &marker); err != nil {
+		return marker, err
+	}
+	return marker, nil
+}
+
+func writeRootMarker(markerPath string, marker rootMarker) error {
+	data, err := json.Marshal(marker)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(markerPath, data, 0o644)
+}
+
+// probeRootWritable은 root에 임시 파일을 만들고 지워 쓰기 가능 여부를 확인합니다.
+func probeRootWritable(root string) error {
+	probe, err := os.CreateTemp(root, ".cohesion_probe-*")
+	if err != nil {
+		return err
+	}
+	probePath := probe.Name()
+	_, writeErr := probe.Write([]byte("ok"))
+	closeErr := probe.Close()
+	removeErr := os.Remove(probePath)
+	return errors.Join(writeErr, closeErr, removeErr)
+}
+
+// RootHealthMonitor는 주기적으로 모든 Space root를 점검합니다.
+type RootHealthMonitor struct {
+	service  *Service
+	interval time.Duration
+
+	startOnce sync.Once
+	stopOnce  sync.Once
+	stopCh    chan struct{}
+	doneCh    chan struct{}
+}
+
+func NewRootHealthMonitor(service *Service, interval time.Duration) *RootHealthMonitor {
+	if interval <= 0 {
+		interval = DefaultRootHealthInterval
+	}
+	return &RootHealthMonitor{
+		service:  service,
+		interval: interval,
+		stopCh:   make(chan struct{}),
+		doneCh:   make(chan struct{}),
+	}
+}
+
+// Start는 즉시 한 번 점검한 뒤 interval마다 다시 점검합니다.
+func (m *RootHealthMonitor) Start() {
+	m.startOnce.Do(func() {
+		go m.run()
+	})
+}
+
+// Stop은 점검 루프를 멈추고 종료를 기다립니다. Start 전에 호출해도 안전합니다.
+func (m *RootHealthMonitor) Stop() {
+	m.stopOnce.Do(func() {
+		close(m.stopCh)
+	})
+	// 시작되지 않았다면 이후 Start도 막고 바로 종료 상태로 둡니다.
+	m.startOnce.Do(func() {
+		close(m.doneCh)
+	})
+	<-m.doneCh
+}
+
+func (m *RootHealthMonitor) run() {
+	defer close(m.doneCh)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-m.stopCh:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+	for {
+		if err := m.service.RefreshRootHealth(ctx); err != nil && ctx.Err() == nil {
+			logging.Event(log.Warn(), logging.ComponentStorage, "warn.space.root_health_failed").
+				Err(err).
+				Msg("failed to refresh space root health")
+		}
+		select {
+		case <-m.stopCh:
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package space_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"taeu.kr/cohesion/internal/space"
+)
+
+func TestCheckSpaceRoot_AssignsMarkerAndDetectsMissingMount(t *testing.T) {
+	service, _ := setupSlugSpaceService(t)
+	ctx := context.Background()
+	root := t.TempDir()
+
+	created, err := service.CreateSpace(ctx, &space.CreateSpaceRequest{SpaceName: "Media", SpacePath: root})
+	if err != nil {
+		t.Fatalf("create space: %v", err)
+	}
+
+	health := service.CheckSpaceRoot(ctx, created)
+	if !health.Online() || !health.Exists || !health.MarkerVerified || !health.Writable {
+		t.Fatalf("expected healthy root, got %+v", health)
+	}
+	if health.TotalBytes == 0 {
+		t.Fatalf("expected disk usage to be reported, got %+v", health)
+	}
+	if _, err := os.Stat(filepath.Join(root, space.RootMarkerFileName)); err != nil {
+		t.Fatalf("expected marker file to be created: %v", err)
+	}
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		t.Fatalf("read root: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("expected only the marker file to remain, got %d entries", len(entries))
+	}
+
+	reloaded, err := service.GetSpaceByID(ctx, created.ID)
+	if err != nil {
+		t.Fatalf("reload space: %v", err)
+	}
+	if reloaded.RootMarker == "" {
+		t.Fatal("expected root marker to be persisted")
+	}
+
+	// 마운트가 빠지면 빈 마운트 지점 디렉터리만 남는다.
+	markerData, err := os.ReadFile(filepath.Join(root, space.RootMarkerFileName))
+	if err != nil {
+		t.Fatalf("read marker: %v", err)
+	}
+	if err := os.Remove(filepath.Join(root, space.RootMarkerFileName)); err != nil {
+		t.Fatalf("remove marker: %v", err)
+	}
+
+	health = service.CheckSpaceRoot(ctx, reloaded)
+	if health.Online() || health.Reason != space.RootHealthReasonMarkerMissing || health.OfflineSince == nil {
+		t.Fatalf("expected marker_missing offline health, got %+v", health)
+	}
+	if err := service.EnsureWritable(ctx, created.ID); !errors.Is(err, space.ErrSpaceOffline) {
+		t.Fatalf("expected offline write error, got %v", err)
+	}
+	if err := service.EnsureOnline(created.ID); !errors.Is(err, space.ErrSpaceOffline) {
+		t.Fatalf("expected offline read error, got %v", err)
+	}
+	if entries, _ := os.ReadDir(root); len(entries) != 0 {
+		t.Fatalf("expected offline check not to write into the bare mount point, got %d entries", len(entries))
+	}
+
+	if err := os.WriteFile(filepath.Join(root, space.RootMarkerFileName), []byte(`{"space_id":1,"marker":"other"}`), 0o644); err != nil {
+		t.Fatalf("write foreign marker: %v", err)
+	}
+	if health = service.CheckSpaceRoot(ctx, reloaded); health.Reason != space.RootHealthReasonMarkerInvalid {
+		t.Fatalf("expected marker_mismatch, got %+v", health)
+	}
+
+	if err := os.WriteFile(filepath.Join(root, space.RootMarkerFileName), markerData, 0o644); err != nil {
+		t.Fatalf("restore marker: %v", err)
+	}
+	if health = service.CheckSpaceRoot(ctx, reloaded); !health.Online() || health.OfflineSince != nil {
+		t.Fatalf("expected root to come back online, got %+v", health)
+	}
+	if err := service.EnsureWritable(ctx, created.ID); err != nil {
+		t.Fatalf("expected writes to resume, got %v", err)
+	}
+}
+
+func TestRefreshRootHealth_ReportsMissingRootAndPrunesDeletedSpaces(t *testing.T) {
+	service, _ := setupSlugSpaceService(t)
+	ctx := context.Background()
+	root := filepath.Join(t.TempDir(), "mount")
+	if err := os.Mkdir(root, 0o755); err != nil {
+		t.Fatalf("mkdir root: %v", err)
+	}
+
+	created, err := service.CreateSpace(ctx, &space.CreateSpaceRequest{SpaceName: "Nas", SpacePath: root})
+	if err != nil {
+		t.Fatalf("create space: %v", err)
+	}
+	if err := os.Remove(root); err != nil {
+		t.Fatalf("remove root: %v", err)
+	}
+
+	if err := service.RefreshRootHealth(ctx); err != nil {
+		t.Fatalf("refresh root health: %v", err)
+	}
+	health, ok := service.RootHealth(created.ID)
+	if !ok || health.Online() || health.Reason != space.RootHealthReasonMissing || health.Exists {
+		t.Fatalf("expected root_missing health, got %+v (ok=%v)", health, ok)
+	}
+
+	if err := service.DeleteSpace(ctx, created.ID); err != nil {
+		t.Fatalf("delete space: %v", err)
+	}
+	if err := service.RefreshRootHealth(ctx); err != nil {
+		t.Fatalf("refresh root health: %v", err)
+	}
+	if _, ok := service.RootHealth(created.ID); ok {
+		t.Fatal("expected health of deleted space to be pruned")
+	}
+}
+
+func TestRootHealthMonitor_StopWithoutStart(t *testing.T) {
+	service, _ := setupSlugSpaceService(t)
+	monitor := space.NewRootHealthMonitor(service, 0)
+	monitor.Stop()
+	monitor.Start()
+	monitor.Stop()
+}
@@ -14,6 +14,8 @@ type SearchIndexStorer interface {
 	ListDirtySpaceIDs(ctx context.Context) ([]int64, error)
 	ReplaceSpaceEntries(ctx context.Context, spaceID int64, entries []SearchIndexEntry) error
 	SearchEntries(ctx context.Context, spaceIDs []int64, queryLower string) ([]SearchIndexResult, error)
+	// ListSpaceEntries는 색인이 최신(dirty가 아님)인 Space의 항목 전체를 반환합니다. 최신이 아니면 빈 목록입니다.
+	ListSpaceEntries(ctx context.Context, spaceID int64) ([]SearchIndexEntry, error)
 	MarkSpaceDirty(ctx context.Context, spaceID int64) error
 	MarkSpacesDirty(ctx context.Context, spaceIDs []int64) error
 	RecordIndexFailure(ctx context.Context, spaceID int64, failure string) error
@@ -62,6 +64,21 @@ func (m *SearchIndexManager) Search(ctx context.Context, spaceIDs []int64, query
 	return m.store.SearchEntries(ctx, spaceIDs, queryLower)
 }
 
+// SpaceEntries는 Space의 색인 항목 전체를 반환합니다. 색인이 dirty면 먼저 다시 만들고,
+// 다시 만들지 못하면 에러를 반환하므로 호출자는 직접 스캔으로 대체할 수 있습니다.
+func (m *SearchIndexManager) SpaceEntries(ctx context.Context, spaceID int64) ([]SearchIndexEntry, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := m.store.EnsureSpaceStates(ctx, []int64{spaceID}); err != nil {
+		return nil, err
+	}
+	if err := m.ensureReadyLocked(ctx, map[int64]struct{}{spaceID: {}}, true); err != nil {
+		return nil, err
+	}
+	return m.store.ListSpaceEntries(ctx, spaceID)
+}
+
 func (m *SearchIndexManager) MarkSpaceDirty(ctx context.Context, spaceID int64) error {
 	return m.store.MarkSpaceDirty(ctx, spaceID)
 }
@@ -139,6 +156,12 @@ func (m *SearchIndexManager) reindexSpace(ctx context.Context, spaceID int64) er
 	return m.store.ReplaceSpaceEntries(ctx, spaceID, entries)
 }
 
+// ScanSpaceEntries는 색인을 쓰지 않고 Space를 직접 훑어 색인과 같은 형식의 항목을 만듭니다.
+// 점(.)으로 시작하는 파일과 디렉터리(휴지통 포함)는 색인과 마찬가지로 제외합니다.
+func ScanSpaceEntries(spaceData *Space) ([]SearchIndexEntry, error) {
+	return buildSearchIndexEntries(spaceData)
+}
+
 func buildSearchIndexEntries(spaceData *Space) ([]SearchIndexEntry, error) {
 	if spaceData == nil {
 		return []SearchIndexEntry{}, nil
@@ -2,8 +2,18 @@ package space
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"strings"
+	"sync"
+)
+
+var (
+	// ErrSpaceNotFound는 Store에서 Space를 찾지 못한 경우입니다. Store 구현은 이 오류를 감싸서 반환합니다.
+	ErrSpaceNotFound = errors.New("space not found")
+	// ErrSpaceValidation은 요청 값이 잘못된 경우입니다. 메시지는 "validation failed: <이유>" 형태입니다.
+	ErrSpaceValidation = errors.New("validation failed")
+	// ErrInvalidSpaceID는 Space ID가 0 이하인 경우입니다.
+	ErrInvalidSpaceID = errors.New("invalid space id")
 )
 
 type Storer interface {
@@ -15,20 +25,50 @@ type Storer interface {
 }
 
 type quotaUpdatable interface {
-	UpdateQuota(ctx context.Context, id int64, quotaBytes *int64) (*Space, error)
+	UpdateQuota(ctx context.Context, id int64, limits QuotaLimits) (*Space, error)
 }
 
 type metadataUpdatable interface {
 	Update(ctx context.Context, id int64, req *UpdateSpaceRequest) (*Space, error)
 }
 
+type webDAVSettingsUpdatable interface {
+	UpdateWebDAVArchiveBrowsing(ctx context.Context, id int64, enabled bool) (*Space, error)
+}
+
+type rootUpdatable interface {
+	UpdatePath(ctx context.Context, id int64, spacePath string) (*Space, error)
+}
+
+// slugResolvable은 프로토콜 경로용 slug와 이름 변경 이력(alias)을 지원하는 Store입니다.
+type slugResolvable interface {
+	GetBySlug(ctx context.Context, slug string) (*Space, error)
+	GetByAlias(ctx context.Context, alias string) (*Space, error)
+	AddAlias(ctx context.Context, spaceID int64, alias string) error
+	AssignSlug(ctx context.Context, id int64, slug string) error
+}
+
 type Service struct {
 	store Storer
+
+	writeFreezeMu  sync.Mutex
+	writeFreezes   map[int64]int
+	writesInflight map[int64]int
+	writeIdle      map[int64]chan struct{}
+
+	rootHealthMu      sync.Mutex
+	rootHealth        map[int64]RootHealth
+	rootCheckInflight map[int64]bool
 }
 
 func NewService(store Storer) *Service {
 	return &Service{
-		store: store,
+		store:             store,
+		writeFreezes:      make(map[int64]int),
+		writesInflight:    make(map[int64]int),
+		writeIdle:         make(map[int64]chan struct{}),
+		rootHealth:        make(map[int64]RootHealth),
+		rootCheckInflight: make(map[int64]bool),
 	}
 }
 
@@ -45,7 +85,7 @@ func (s *Service) GetSpaceByName(ctx context.Context, name string) (*Space, erro
 // 특정 ID의 Space 조회
 func (s *Service) GetSpaceByID(ctx context.Context, id int64) (*Space, error) {
 	if id <= 0 {
-		return nil, fmt.Errorf("invalid space id: %d", id)
+		return nil, fmt.Errorf("%w: %d", ErrInvalidSpaceID, id)
 	}
 	return s.store.GetByID(ctx, id)
 }
@@ -54,7 +94,7 @@ func (s *Service) GetSpaceByID(ctx context.Context, id int64) (*Space, error) {
 func (s *Service) CreateSpace(ctx context.Context, req *CreateSpaceRequest) (*Space, error) {
 	// 요청 데이터 검증
 	if err := req.Validate(); err != nil {
-		return nil, fmt.Errorf("validation failed: %w", err)
+		return nil, fmt.Errorf("%w: %w", ErrSpaceValidation, err)
 	}
 
 	rootValidation, err := s.ValidateSpaceRoot(ctx, req.SpacePath)
@@ -62,7 +102,7 @@ func (s *Service) CreateSpace(ctx context.Context, req *CreateSpaceRequest) (*Sp
 		return nil, err
 	}
 	if !rootValidation.Valid {
-		return nil, fmt.Errorf("validation failed: %w", NewSpaceRootValidationError(*rootValidation))
+		return nil, fmt.Errorf("%w: %w", ErrSpaceValidation, NewSpaceRootValidationError(*rootValidation))
 	}
 
 	// 이름 중복 체크
@@ -70,6 +110,15 @@ func (s *Service) CreateSpace(ctx context.Context, req *CreateSpaceRequest) (*Sp
 	if err == nil && existingSpace != nil {
 		return nil, fmt.Errorf("space with name '%s' already exists", req.SpaceName)
 	}
+	if err := s.ensureProtocolNameFree(ctx, req.SpaceName, 0); err != nil {
+		return nil, err
+	}
+
+	slug, err := s.allocateSpaceSlug(ctx, 0, req.SpaceName, req.SpaceSlug)
+	if err != nil {
+		return nil, err
+	}
+	req.SpaceSlug = slug
 
 	// Store를 통해 생성
 	createdSpace, err := s.store.Create(ctx, req)
@@ -83,7 +132,7 @@ func (s *Service) CreateSpace(ctx context.Context, req *CreateSpaceRequest) (*Sp
 func (s *Service) ValidateSpaceRoot(_ context.Context, path string) (*SpaceRootValidationResult, error) {
 	req := &ValidateSpaceRootRequest{SpacePath: path}
 	if err := req.Validate(); err != nil {
-		return nil, fmt.Errorf("validation failed: %w", err)
+		return nil, fmt.Errorf("%w: %w", ErrSpaceValidation, err)
 	}
 
 	result, err := ValidateSpaceRoot(path)
@@ -97,7 +146,7 @@ func (s *Service) ValidateSpaceRoot(_ context.Context, path string) (*SpaceRootV
 // DeleteSpace는 Space를 삭제합니다
 func (s *Service) DeleteSpace(ctx context.Context, id int64) error {
 	if id <= 0 {
-		return fmt.Errorf("invalid space id: %d", id)
+		return fmt.Errorf("%w: %d", ErrInvalidSpaceID, id)
 	}
 
 	if err := s.store.Delete(ctx, id); err != nil {
@@ -107,13 +156,13 @@ func (s *Service) DeleteSpace(ctx context.Context, id int64) error {
 	return nil
 }
 
-// UpdateSpaceQuota는 Space 쿼터를 갱신합니다. nil이면 무제한으로 설정됩니다.
-func (s *Service) UpdateSpaceQuota(ctx context.Context, id int64, quotaBytes *int64) (*Space, error) {
+// UpdateSpaceQuota는 Space 쿼터(용량, 파일 수, 경고 비율)를 갱신합니다. 용량·파일 수가 nil이면 무제한으로 설정됩니다.
+func (s *Service) UpdateSpaceQuota(ctx context.Context, id int64, limits QuotaLimits) (*Space, error) {
 	if id <= 0 {
-		return nil, fmt.Errorf("invalid space id: %d", id)
+		return nil, fmt.Errorf("%w: %d", ErrInvalidSpaceID, id)
 	}
-	if quotaBytes != nil && *quotaBytes < 0 {
-		return nil, fmt.Errorf("invalid quota bytes: %d", *quotaBytes)
+	if err := limits.Validate(); err != nil {
+		return nil, err
 	}
 
 	updatable, ok := s.store.(quotaUpdatable)
@@ -121,20 +170,38 @@ func (s *Service) UpdateSpaceQuota(ctx context.Context, id int64, quotaBytes *in
 		return nil, fmt.Errorf("space store does not support quota updates")
 	}
 
-	updated, err := updatable.UpdateQuota(ctx, id, quotaBytes)
+	updated, err := updatable.UpdateQuota(ctx, id, limits)
 	if err != nil {
 		return nil, fmt.Errorf("failed to update space quota: %w", err)
 	}
 	return updated, nil
 }
 
+// UpdateWebDAVArchiveBrowsing은 WebDAV에서 압축 파일을 폴더로 보여 줄지 설정합니다.
+func (s *Service) UpdateWebDAVArchiveBrowsing(ctx context.Context, id int64, enabled bool) (*Space, error) {
+	if id <= 0 {
+		return nil, fmt.Errorf("%w: %d", ErrInvalidSpaceID, id)
+	}
+
+	updatable, ok := s.store.(webDAVSettingsUpdatable)
+	if !ok {
+		return nil, fmt.Errorf("space store does not support webdav settings updates")
+	}
+
+	updated, err := updatable.UpdateWebDAVArchiveBrowsing(ctx, id, enabled)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update space webdav settings: %w", err)
+	}
+	return updated, nil
+}
+
 // UpdateSpace는 Space 메타데이터를 갱신합니다.
 func (s *Service) UpdateSpace(ctx context.Context, id int64, req *UpdateSpaceRequest) (*Space, error) {
 	if id <= 0 {
-		return nil, fmt.Errorf("invalid space id: %d", id)
+		return nil, fmt.Errorf("%w: %d", ErrInvalidSpaceID, id)
 	}
 	if err := req.Validate(); err != nil {
-		return nil, fmt.Errorf("validation failed: %w", err)
+		return nil, fmt.Errorf("%w: %w", ErrSpaceValidation, err)
 	}
 
 	updatable, ok := s.store.(metadataUpdatable)
@@ -146,13 +213,167 @@ func (s *Service) UpdateSpace(ctx context.Context, id int64, req *UpdateSpaceReq
 	switch {
 	case err == nil && existingSpace != nil && existingSpace.ID != id:
 		return nil, fmt.Errorf("space with name '%s' already exists", *req.SpaceName)
-	case err != nil && !strings.Contains(err.Error(), "not found"):
+	case err != nil && !errors.Is(err, ErrSpaceNotFound):
 		return nil, fmt.Errorf("failed to validate space name uniqueness: %w", err)
 	}
+	if err := s.ensureProtocolNameFree(ctx, *req.SpaceName, id); err != nil {
+		return nil, err
+	}
+
+	previous, err := s.store.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update space: %w", err)
+	}
 
 	updated, err := updatable.Update(ctx, id, req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to update space: %w", err)
 	}
+
+	// 이전 이름으로 연결된 WebDAV/SFTP/FTP 경로가 계속 동작하도록 alias로 남긴다.
+	if resolvable, ok := s.store.(slugResolvable); ok && previous.SpaceName != updated.SpaceName {
+		if err := resolvable.AddAlias(ctx, id, previous.SpaceName); err != nil {
+			return nil, fmt.Errorf("failed to record space alias: %w", err)
+		}
+	}
 	return updated, nil
 }
+
+// UpdateSpaceRoot는 Space의 root 경로를 바꿉니다. 데이터 이동은 RelocationManager가 담당합니다.
+func (s *Service) UpdateSpaceRoot(ctx context.Context, id int64, spacePath string) (*Space, error) {
+	if id <= 0 {
+		return nil, fmt.Errorf("%w: %d", ErrInvalidSpaceID, id)
+	}
+
+	updatable, ok := s.store.(rootUpdatable)
+	if !ok {
+		return nil, fmt.Errorf("space store does not support root updates")
+	}
+
+	updated, err := updatable.UpdatePath(ctx, id, spacePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update space root: %w", err)
+	}
+	return updated, nil
+}
+
+// ResolveSpaceByProtocolName은 프로토콜 경로의 Space 세그먼트를 해석합니다.
+// slug로 찾은 경우 canonical=true이고, 현재 이름이나 이전 이름(alias)으로 찾은 경우 false입니다.
+func (s *Service) ResolveSpaceByProtocolName(ctx context.Context, name string) (*Space, bool, error) {
+	resolvable, ok := s.store.(slugResolvable)
+	if !ok {
+		spaceObj, err := s.store.GetByName(ctx, name)
+		if err != nil {
+			return nil, false, err
+		}
+		return spaceObj, true, nil
+	}
+
+	spaceObj, err := resolvable.GetBySlug(ctx, name)
+	if err == nil {
+		return spaceObj, true, nil
+	}
+	if !errors.Is(err, ErrSpaceNotFound) {
+		return nil, false, err
+	}
+
+	spaceObj, err = s.store.GetByName(ctx, name)
+	if err == nil {
+		return spaceObj, spaceObj.ProtocolName() == name, nil
+	}
+	if !errors.Is(err, ErrSpaceNotFound) {
+		return nil, false, err
+	}
+
+	spaceObj, err = resolvable.GetByAlias(ctx, name)
+	if err != nil {
+		return nil, false, err
+	}
+	return spaceObj, false, nil
+}
+
+// EnsureSpaceSlugs는 slug가 없는 기존 Space에 slug를 할당합니다.
+func (s *Service) EnsureSpaceSlugs(ctx context.Context) error {
+	resolvable, ok := s.store.(slugResolvable)
+	if !ok {
+		return nil
+	}
+
+	spaces, err := s.store.GetAll(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list spaces for slug backfill: %w", err)
+	}
+	for _, spaceObj := range spaces {
+		if spaceObj.SpaceSlug != "" {
+			continue
+		}
+		slug, err := s.allocateSpaceSlug(ctx, spaceObj.ID, spaceObj.SpaceName, "")
+		if err != nil {
+			return err
+		}
+		if err := resolvable.AssignSlug(ctx, spaceObj.ID, slug); err != nil {
+			return fmt.Errorf("failed to assign slug to space %d: %w", spaceObj.ID, err)
+		}
+	}
+	return nil
+}
+
+// allocateSpaceSlug는 요청된 slug 또는 이름 기반 slug를 중복 없이 확정합니다.
+// spaceID는 slug를 받을 Space이며 새로 만드는 중이면 0입니다. 그 Space 자신의 이름이나 alias와 같은 것은 허용합니다.
+func (s *Service) allocateSpaceSlug(ctx context.Context, spaceID int64, name string, requested string) (string, error) {
+	if _, ok := s.store.(slugResolvable); !ok {
+		return requested, nil
+	}
+
+	if requested != "" {
+		if err := s.ensureProtocolNameFree(ctx, requested, spaceID); err != nil {
+			return "", err
+		}
+		return requested, nil
+	}
+
+	base := SlugifySpaceName(name)
+	if base == "" {
+		base = "space"
+	}
+	candidate := base
+	for attempt := 2; attempt <= 1000; attempt++ {
+		err := s.ensureProtocolNameFree(ctx, candidate, spaceID)
+		if err == nil {
+			return candidate, nil
+		}
+		if !errors.Is(err, ErrSpaceProtocolNameTaken) {
+			return "", err
+		}
+		candidate = suffixSpaceSlug(base, attempt)
+	}
+	return "", fmt.Errorf("failed to allocate unique slug for space '%s'", name)
+}
+
+// ensureProtocolNameFree는 value가 spaceID가 아닌 다른 Space의 slug, 이름, alias로 쓰이고 있지 않은지 확인합니다.
+// 프로토콜 경로는 slug, 이름, alias 순서로 해석하므로 겹치는 이름이나 slug를 허용하면 기존 경로가 다른 Space로 넘어갑니다.
+func (s *Service) ensureProtocolNameFree(ctx context.Context, value string, spaceID int64) error {
+	resolvable, ok := s.store.(slugResolvable)
+	if !ok {
+		return nil
+	}
+
+	lookups := []struct {
+		kind string
+		get  func(context.Context, string) (*Space, error)
+	}{
+		{kind: "slug", get: resolvable.GetBySlug},
+		{kind: "name", get: s.store.GetByName},
+		{kind: "alias", get: resolvable.GetByAlias},
+	}
+	for _, lookup := range lookups {
+		owner, err := lookup.get(ctx, value)
+		switch {
+		case err == nil && owner != nil && owner.ID != spaceID:
+			return fmt.Errorf("%w: '%s' is the %s of another space", ErrSpaceProtocolNameTaken, value, lookup.kind)
+		case err != nil && !errors.Is(err, ErrSpaceNotFound):
+			return fmt.Errorf("failed to validate space %s uniqueness: %w", lookup.kind, err)
+		}
+	}
+	return nil
+}
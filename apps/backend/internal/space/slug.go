@@ -0,0 +1,75 @@
+package space
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const maxSpaceSlugLength = 64
+
+// ErrSpaceProtocolNameTaken은 이름이나 slug가 다른 Space의 slug, 이름, 이전 이름(alias)과 겹치는 경우입니다.
+// 프로토콜 경로는 slug, 이름, alias 순서로 해석하므로 겹치면 같은 경로가 조용히 다른 Space를 가리키게 됩니다.
+var ErrSpaceProtocolNameTaken = errors.New("space protocol name already exists")
+
+// ProtocolName은 WebDAV/SFTP/FTP 경로에서 Space를 가리키는 세그먼트를 반환합니다.
+// slug가 아직 할당되지 않은 Space는 이름으로 대체합니다.
+func (s *Space) ProtocolName() string {
+	if s == nil {
+		return ""
+	}
+	if s.SpaceSlug != "" {
+		return s.SpaceSlug
+	}
+	return s.SpaceName
+}
+
+// SlugifySpaceName은 Space 이름에서 경로 세그먼트로 안전한 slug 후보를 만듭니다.
+// 문자/숫자는 소문자로 유지하고 나머지는 '-' 하나로 접습니다.
+func SlugifySpaceName(name string) string {
+	var builder strings.Builder
+	pendingDash := false
+	for _, r := range strings.TrimSpace(name) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if pendingDash && builder.Len() > 0 {
+				builder.WriteByte('-')
+			}
+			pendingDash = false
+			builder.WriteRune(unicode.ToLower(r))
+			continue
+		}
+		pendingDash = true
+	}
+
+	slug := builder.String()
+	for utf8.RuneCountInString(slug) > maxSpaceSlugLength {
+		_, size := utf8.DecodeLastRuneInString(slug)
+		slug = strings.TrimRight(slug[:len(slug)-size], "-")
+	}
+	return slug
+}
+
+// ValidateSpaceSlug는 직접 지정한 slug가 경로 세그먼트로 사용 가능한지 검사합니다.
+func ValidateSpaceSlug(slug string) error {
+	if slug == "" {
+		return errors.New("space_slug is required")
+	}
+	if utf8.RuneCountInString(slug) > maxSpaceSlugLength {
+		return fmt.Errorf("space_slug must be at most %d characters", maxSpaceSlugLength)
+	}
+	if SlugifySpaceName(slug) != slug {
+		return errors.New("space_slug must contain only lowercase letters, digits and single dashes")
+	}
+	return nil
+}
+
+func suffixSpaceSlug(base string, attempt int) string {
+	suffix := fmt.Sprintf("-%d", attempt)
+	for utf8.RuneCountInString(base)+len(suffix) > maxSpaceSlugLength {
+		_, size := utf8.DecodeLastRuneInString(base)
+		base = strings.TrimRight(base[:len(base)-size], "-")
+	}
+	return base + suffix
+}
@@ -0,0 +1,207 @@
+package space_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"taeu.kr/cohesion/internal/platform/database"
+	"taeu.kr/cohesion/internal/space"
+	spaceStore "taeu.kr/cohesion/internal/space/store"
+)
+
+func setupSlugSpaceService(t *testing.T) (*space.Service, *sql.DB) {
+	t.Helper()
+
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	if err := database.Migrate(context.Background(), db); err != nil {
+		t.Fatalf("migrate db: %v", err)
+	}
+	return space.NewService(spaceStore.NewStore(db)), db
+}
+
+func TestSlugifySpaceName(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{input: "Team Docs", want: "team-docs"},
+		{input: "  Photos__2024!! ", want: "photos-2024"},
+		{input: "사진 보관함", want: "사진-보관함"},
+		{input: "///", want: ""},
+	}
+
+	for _, tt := range tests {
+		if got := space.SlugifySpaceName(tt.input); got != tt.want {
+			t.Fatalf("SlugifySpaceName(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestValidateSpaceSlug(t *testing.T) {
+	if err := space.ValidateSpaceSlug("team-docs"); err != nil {
+		t.Fatalf("expected valid slug, got %v", err)
+	}
+	for _, invalid := range []string{"", "Team", "a/b", "-docs", "docs--archive"} {
+		if err := space.ValidateSpaceSlug(invalid); err == nil {
+			t.Fatalf("expected slug %q to be rejected", invalid)
+		}
+	}
+}
+
+func TestCreateSpace_AssignsUniqueSlug(t *testing.T) {
+	service, _ := setupSlugSpaceService(t)
+	ctx := context.Background()
+
+	first, err := service.CreateSpace(ctx, &space.CreateSpaceRequest{SpaceName: "Team Docs", SpacePath: t.TempDir()})
+	if err != nil {
+		t.Fatalf("create first space: %v", err)
+	}
+	second, err := service.CreateSpace(ctx, &space.CreateSpaceRequest{SpaceName: "team docs", SpacePath: t.TempDir()})
+	if err != nil {
+		t.Fatalf("create second space: %v", err)
+	}
+
+	if first.SpaceSlug != "team-docs" {
+		t.Fatalf("expected first slug team-docs, got %q", first.SpaceSlug)
+	}
+	if second.SpaceSlug != "team-docs-2" {
+		t.Fatalf("expected second slug team-docs-2, got %q", second.SpaceSlug)
+	}
+
+	if _, err := service.CreateSpace(ctx, &space.CreateSpaceRequest{SpaceName: "Other", SpaceSlug: "team-docs", SpacePath: t.TempDir()}); err == nil {
+		t.Fatal("expected explicit duplicate slug to be rejected")
+	}
+}
+
+func TestResolveSpaceByProtocolName_KeepsOldNamesAfterRename(t *testing.T) {
+	service, _ := setupSlugSpaceService(t)
+	ctx := context.Background()
+
+	created, err := service.CreateSpace(ctx, &space.CreateSpaceRequest{SpaceName: "Projects", SpacePath: t.TempDir()})
+	if err != nil {
+		t.Fatalf("create space: %v", err)
+	}
+
+	newName := "Archive 2024"
+	renamed, err := service.UpdateSpace(ctx, created.ID, &space.UpdateSpaceRequest{SpaceName: &newName})
+	if err != nil {
+		t.Fatalf("rename space: %v", err)
+	}
+	if renamed.SpaceSlug != "projects" {
+		t.Fatalf("expected slug to stay projects after rename, got %q", renamed.SpaceSlug)
+	}
+
+	tests := []struct {
+		name          string
+		wantCanonical bool
+	}{
+		{name: "projects", wantCanonical: true},
+		{name: "Projects", wantCanonical: false},
+		{name: "Archive 2024", wantCanonical: false},
+	}
+	for _, tt := range tests {
+		resolved, canonical, err := service.ResolveSpaceByProtocolName(ctx, tt.name)
+		if err != nil {
+			t.Fatalf("resolve %q: %v", tt.name, err)
+		}
+		if resolved.ID != created.ID {
+			t.Fatalf("resolve %q returned space %d, want %d", tt.name, resolved.ID, created.ID)
+		}
+		if canonical != tt.wantCanonical {
+			t.Fatalf("resolve %q canonical = %v, want %v", tt.name, canonical, tt.wantCanonical)
+		}
+	}
+
+	if _, _, err := service.ResolveSpaceByProtocolName(ctx, "missing"); err == nil {
+		t.Fatal("expected unknown space to fail")
+	}
+}
+
+func TestEnsureSpaceSlugs_BackfillsLegacySpaces(t *testing.T) {
+	service, db := setupSlugSpaceService(t)
+	ctx := context.Background()
+
+	if _, err := db.ExecContext(ctx, `INSERT INTO space (space_name, space_path) VALUES ('Shared', '/tmp/shared'), ('shared', '/tmp/shared-2')`); err != nil {
+		t.Fatalf("insert legacy spaces: %v", err)
+	}
+
+	if err := service.EnsureSpaceSlugs(ctx); err != nil {
+		t.Fatalf("ensure slugs: %v", err)
+	}
+
+	spaces, err := service.GetAllSpaces(ctx)
+	if err != nil {
+		t.Fatalf("list spaces: %v", err)
+	}
+	seen := map[string]bool{}
+	for _, item := range spaces {
+		if item.SpaceSlug == "" {
+			t.Fatalf("expected slug for space %q", item.SpaceName)
+		}
+		if seen[item.SpaceSlug] {
+			t.Fatalf("duplicate slug %q", item.SpaceSlug)
+		}
+		seen[item.SpaceSlug] = true
+	}
+}
+
+func TestProtocolNames_RejectCollisionsWithOtherSpaces(t *testing.T) {
+	service, _ := setupSlugSpaceService(t)
+	ctx := context.Background()
+
+	projects, err := service.CreateSpace(ctx, &space.CreateSpaceRequest{SpaceName: "Projects", SpacePath: t.TempDir()})
+	if err != nil {
+		t.Fatalf("create projects: %v", err)
+	}
+	archive := "Archive"
+	if _, err := service.UpdateSpace(ctx, projects.ID, &space.UpdateSpaceRequest{SpaceName: &archive}); err != nil {
+		t.Fatalf("rename projects: %v", err)
+	}
+	beta, err := service.CreateSpace(ctx, &space.CreateSpaceRequest{SpaceName: "Beta", SpacePath: t.TempDir()})
+	if err != nil {
+		t.Fatalf("create beta: %v", err)
+	}
+
+	// 다른 Space의 이전 이름이나 slug로 이름을 바꾸면 기존 경로가 조용히 넘어가므로 거절한다.
+	for _, name := range []string{"Projects", "projects"} {
+		if _, err := service.UpdateSpace(ctx, beta.ID, &space.UpdateSpaceRequest{SpaceName: &name}); !errors.Is(err, space.ErrSpaceProtocolNameTaken) {
+			t.Fatalf("rename to %q: expected protocol name conflict, got %v", name, err)
+		}
+	}
+	for _, name := range []string{"Projects", "projects"} {
+		resolved, _, err := service.ResolveSpaceByProtocolName(ctx, name)
+		if err != nil || resolved.ID != projects.ID {
+			t.Fatalf("resolve %q should still reach projects, got %+v err=%v", name, resolved, err)
+		}
+	}
+	if _, err := service.CreateSpace(ctx, &space.CreateSpaceRequest{SpaceName: "Projects", SpacePath: t.TempDir()}); !errors.Is(err, space.ErrSpaceProtocolNameTaken) {
+		t.Fatalf("create with another space's alias: expected protocol name conflict, got %v", err)
+	}
+
+	// 자기 자신의 이전 이름으로 되돌리는 것은 된다.
+	back := "Projects"
+	if _, err := service.UpdateSpace(ctx, projects.ID, &space.UpdateSpaceRequest{SpaceName: &back}); err != nil {
+		t.Fatalf("rename back to own alias: %v", err)
+	}
+
+	// slug는 다른 Space의 이름과도 겹치면 안 된다. 직접 지정하면 거절하고, 자동 할당이면 번호를 붙인다.
+	if _, err := service.CreateSpace(ctx, &space.CreateSpaceRequest{SpaceName: "reports", SpaceSlug: "rpt", SpacePath: t.TempDir()}); err != nil {
+		t.Fatalf("create reports: %v", err)
+	}
+	if _, err := service.CreateSpace(ctx, &space.CreateSpaceRequest{SpaceName: "Other", SpaceSlug: "reports", SpacePath: t.TempDir()}); !errors.Is(err, space.ErrSpaceProtocolNameTaken) {
+		t.Fatalf("explicit slug equal to another space's name: expected protocol name conflict, got %v", err)
+	}
+	created, err := service.CreateSpace(ctx, &space.CreateSpaceRequest{SpaceName: "Reports!", SpacePath: t.TempDir()})
+	if err != nil {
+		t.Fatalf("create Reports!: %v", err)
+	}
+	if created.SpaceSlug != "reports-2" {
+		t.Fatalf("expected auto slug to skip another space's name, got %q", created.SpaceSlug)
+	}
+}
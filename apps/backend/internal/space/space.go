@@ -2,26 +2,82 @@ package space
 
 import (
 	"errors"
+	"fmt"
+	"slices"
 	"strings"
 	"time"
 )
 
 type Space struct {
-	ID            int64      `db:"id" json:"id"`
-	SpaceName     string     `db:"space_name" json:"space_name"`
-	SpacePath     string     `db:"space_path" json:"space_path"`
-	Icon          *string    `db:"icon" json:"icon,omitempty"`
-	SpaceCategory *string    `db:"space_category" json:"space_category,omitempty"`
-	QuotaBytes    *int64     `db:"quota_bytes" json:"quota_bytes,omitempty"`
-	CreatedAt     time.Time  `db:"created_at" json:"created_at"`
-	CreatedUserID *string    `db:"created_user_id" json:"created_user_id,omitempty"`
-	UpdatedAt     *time.Time `db:"updated_at" json:"updated_at,omitempty"`
-	UpdatedUserID *string    `db:"updated_user_id" json:"updated_user_id,omitempty"`
+	ID            int64   `db:"id" json:"id"`
+	SpaceName     string  `db:"space_name" json:"space_name"`
+	SpaceSlug     string  `db:"space_slug" json:"space_slug"`
+	SpacePath     string  `db:"space_path" json:"space_path"`
+	Icon          *string `db:"icon" json:"icon,omitempty"`
+	SpaceCategory *string `db:"space_category" json:"space_category,omitempty"`
+	QuotaBytes    *int64  `db:"quota_bytes" json:"quota_bytes,omitempty"`
+	// QuotaFiles는 디렉터리를 뺀 최대 파일 수입니다. nil이면 제한이 없습니다.
+	QuotaFiles *int64 `db:"quota_files" json:"quota_files,omitempty"`
+	// QuotaWarningPercents는 쓰기를 막지 않고 경고만 하는 할당량 비율(%)입니다. nil이면 DefaultQuotaWarningPercents를 씁니다.
+	QuotaWarningPercents []int      `db:"quota_warning_percents" json:"quota_warning_percents"`
+	SpaceState           SpaceState `db:"space_state" json:"space_state"`
+	RootMarker           string     `db:"root_marker" json:"-"`
+	// WebDAVArchiveBrowsing이 켜져 있으면 WebDAV에서 압축 파일을 읽기 전용 폴더로 보여 줍니다.
+	WebDAVArchiveBrowsing bool       `db:"webdav_archive_browsing" json:"webdav_archive_browsing"`
+	CreatedAt             time.Time  `db:"created_at" json:"created_at"`
+	CreatedUserID         *string    `db:"created_user_id" json:"created_user_id,omitempty"`
+	UpdatedAt             *time.Time `db:"updated_at" json:"updated_at,omitempty"`
+	UpdatedUserID         *string    `db:"updated_user_id" json:"updated_user_id,omitempty"`
+}
+
+// DefaultQuotaWarningPercents는 Space에 경고 비율을 따로 정하지 않았을 때 쓰는 값입니다.
+var DefaultQuotaWarningPercents = []int{80, 95}
+
+// QuotaLimits는 Space 할당량 설정입니다. QuotaBytes와 QuotaFiles가 nil이면 제한이 없고,
+// WarningPercents가 nil이면 기본 경고 비율을, 빈 목록이면 경고를 끕니다.
+type QuotaLimits struct {
+	QuotaBytes      *int64
+	QuotaFiles      *int64
+	WarningPercents []int
+}
+
+// EffectiveQuotaWarningPercents는 Space에 적용되는 경고 비율을 오름차순으로 반환합니다.
+func (s *Space) EffectiveQuotaWarningPercents() []int {
+	if s.QuotaWarningPercents == nil {
+		return DefaultQuotaWarningPercents
+	}
+	return s.QuotaWarningPercents
+}
+
+// Validate는 할당량 값과 경고 비율을 검사하고 경고 비율을 오름차순으로 정리합니다.
+func (l *QuotaLimits) Validate() error {
+	if l.QuotaBytes != nil && *l.QuotaBytes < 0 {
+		return fmt.Errorf("invalid quota bytes: %d", *l.QuotaBytes)
+	}
+	if l.QuotaFiles != nil && *l.QuotaFiles < 0 {
+		return fmt.Errorf("invalid quota files: %d", *l.QuotaFiles)
+	}
+	if l.WarningPercents == nil {
+		return nil
+	}
+	percents := slices.Clone(l.WarningPercents)
+	slices.Sort(percents)
+	for i, percent := range percents {
+		if percent < 1 || percent > 99 {
+			return fmt.Errorf("invalid quota warning percent: %d", percent)
+		}
+		if i > 0 && percents[i-1] == percent {
+			return fmt.Errorf("invalid quota warning percent: duplicate %d", percent)
+		}
+	}
+	l.WarningPercents = percents
+	return nil
 }
 
 // CreateSpaceRequest는 Space 생성 요청 데이터를 정의합니다
 type CreateSpaceRequest struct {
 	SpaceName     string  `json:"space_name"`
+	SpaceSlug     string  `json:"space_slug,omitempty"`
 	SpacePath     string  `json:"space_path"`
 	Icon          *string `json:"icon,omitempty"`
 	SpaceCategory *string `json:"space_category,omitempty"`
@@ -70,6 +126,11 @@ func (req *CreateSpaceRequest) Validate() error {
 	if req.QuotaBytes != nil && *req.QuotaBytes < 0 {
 		return errors.New("quota_bytes must be greater than or equal to 0")
 	}
+	if req.SpaceSlug != "" {
+		if err := ValidateSpaceSlug(req.SpaceSlug); err != nil {
+			return err
+		}
+	}
 
 	return nil
 }
@@ -0,0 +1,117 @@
+package space
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// SpaceState는 권한과 별개로 Space 전체의 읽기/쓰기 허용 여부를 정하는 운영 상태입니다.
+type SpaceState string
+
+const (
+	SpaceStateActive   SpaceState = "active"
+	SpaceStateReadOnly SpaceState = "read_only"
+	// SpaceStateMaintenance는 모든 쓰기와 관리자가 아닌 사용자의 읽기를 막습니다.
+	SpaceStateMaintenance SpaceState = "maintenance"
+	// SpaceStateArchived는 읽기 전용이며 기본 목록에서 숨겨집니다.
+	SpaceStateArchived SpaceState = "archived"
+)
+
+var (
+	// ErrSpaceMaintenance는 점검 중인 Space에 대한 접근을 나타냅니다.
+	ErrSpaceMaintenance = errors.New("space is under maintenance")
+	// ErrSpaceArchived는 보관된 Space에 대한 쓰기를 나타냅니다.
+	ErrSpaceArchived = errors.New("space is archived")
+)
+
+// UpdateSpaceStateRequest는 Space 운영 상태 변경 요청 데이터를 정의합니다.
+type UpdateSpaceStateRequest struct {
+	State SpaceState `json:"state"`
+}
+
+func (req *UpdateSpaceStateRequest) Validate() error {
+	if req == nil {
+		return errors.New("request is required")
+	}
+	req.State = SpaceState(strings.ToLower(strings.TrimSpace(string(req.State))))
+	if !req.State.IsValid() {
+		return fmt.Errorf("state must be one of %s, %s, %s, %s", SpaceStateActive, SpaceStateReadOnly, SpaceStateMaintenance, SpaceStateArchived)
+	}
+	return nil
+}
+
+func (state SpaceState) IsValid() bool {
+	switch state {
+	case SpaceStateActive, SpaceStateReadOnly, SpaceStateMaintenance, SpaceStateArchived:
+		return true
+	default:
+		return false
+	}
+}
+
+// State는 저장된 상태를 반환합니다. 값이 비어 있으면 active로 봅니다.
+func (s *Space) State() SpaceState {
+	if s == nil || s.SpaceState == "" {
+		return SpaceStateActive
+	}
+	return s.SpaceState
+}
+
+// IsListed는 기본 Space 목록(웹/프로토콜 루트)에 노출할지 여부입니다.
+func (s *Space) IsListed() bool {
+	return s.State() != SpaceStateArchived
+}
+
+// CheckReadable은 현재 상태에서 읽기가 허용되는지 확인합니다.
+func (s *Space) CheckReadable(isAdmin bool) error {
+	if s.State() == SpaceStateMaintenance && !isAdmin {
+		return fmt.Errorf("%w: space %d", ErrSpaceMaintenance, s.ID)
+	}
+	return nil
+}
+
+// CheckWritable은 현재 상태에서 쓰기가 허용되는지 확인합니다. 관리자도 예외가 아닙니다.
+func (s *Space) CheckWritable() error {
+	switch s.State() {
+	case SpaceStateReadOnly:
+		return fmt.Errorf("%w: space %d", ErrSpaceReadOnly, s.ID)
+	case SpaceStateMaintenance:
+		return fmt.Errorf("%w: space %d", ErrSpaceMaintenance, s.ID)
+	case SpaceStateArchived:
+		return fmt.Errorf("%w: space %d", ErrSpaceArchived, s.ID)
+	default:
+		return nil
+	}
+}
+
+// IsSpaceStateError는 err가 Space 상태 때문에 거절된 요청인지 확인합니다.
+func IsSpaceStateError(err error) bool {
+	return errors.Is(err, ErrSpaceReadOnly) || errors.Is(err, ErrSpaceMaintenance) || errors.Is(err, ErrSpaceArchived)
+}
+
+type stateUpdatable interface {
+	UpdateState(ctx context.Context, id int64, state SpaceState) (*Space, error)
+}
+
+// UpdateSpaceState는 Space 운영 상태를 변경합니다.
+func (s *Service) UpdateSpaceState(ctx context.Context, id int64, req *UpdateSpaceStateRequest) (*Space, error) {
+	if id <= 0 {
+		return nil, fmt.Errorf("%w: %d", ErrInvalidSpaceID, id)
+	}
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrSpaceValidation, err)
+	}
+
+	updater, ok := s.store.(stateUpdatable)
+	if !ok {
+		return nil, errors.New("space state update is not supported")
+	}
+
+	updated, err := updater.UpdateState(ctx, id, req.State)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update space state: %w", err)
+	}
+	return updated, nil
+}
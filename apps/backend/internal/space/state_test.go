@@ -0,0 +1,70 @@
+package space_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"taeu.kr/cohesion/internal/space"
+)
+
+func TestUpdateSpaceState_PersistsAndGuardsWrites(t *testing.T) {
+	service, _ := setupSlugSpaceService(t)
+	ctx := context.Background()
+
+	created, err := service.CreateSpace(ctx, &space.CreateSpaceRequest{SpaceName: "Media", SpacePath: t.TempDir()})
+	if err != nil {
+		t.Fatalf("create space: %v", err)
+	}
+	if created.State() != space.SpaceStateActive {
+		t.Fatalf("expected new space to be active, got %q", created.State())
+	}
+	if err := service.EnsureWritable(ctx, created.ID); err != nil {
+		t.Fatalf("expected active space to be writable, got %v", err)
+	}
+
+	tests := []struct {
+		state     space.SpaceState
+		wantErr   error
+		readable  bool
+		adminRead bool
+		listed    bool
+	}{
+		{state: space.SpaceStateReadOnly, wantErr: space.ErrSpaceReadOnly, readable: true, adminRead: true, listed: true},
+		{state: space.SpaceStateMaintenance, wantErr: space.ErrSpaceMaintenance, readable: false, adminRead: true, listed: true},
+		{state: space.SpaceStateArchived, wantErr: space.ErrSpaceArchived, readable: true, adminRead: true, listed: false},
+	}
+	for _, tt := range tests {
+		updated, err := service.UpdateSpaceState(ctx, created.ID, &space.UpdateSpaceStateRequest{State: tt.state})
+		if err != nil {
+			t.Fatalf("update state %s: %v", tt.state, err)
+		}
+		if updated.State() != tt.state {
+			t.Fatalf("expected state %s, got %s", tt.state, updated.State())
+		}
+
+		err = service.EnsureWritable(ctx, created.ID)
+		if !errors.Is(err, tt.wantErr) || !space.IsSpaceStateError(err) {
+			t.Fatalf("state %s: expected %v, got %v", tt.state, tt.wantErr, err)
+		}
+		if got := updated.CheckReadable(false) == nil; got != tt.readable {
+			t.Fatalf("state %s: readable = %v, want %v", tt.state, got, tt.readable)
+		}
+		if got := updated.CheckReadable(true) == nil; got != tt.adminRead {
+			t.Fatalf("state %s: admin readable = %v, want %v", tt.state, got, tt.adminRead)
+		}
+		if updated.IsListed() != tt.listed {
+			t.Fatalf("state %s: listed = %v, want %v", tt.state, updated.IsListed(), tt.listed)
+		}
+	}
+
+	if _, err := service.UpdateSpaceState(ctx, created.ID, &space.UpdateSpaceStateRequest{State: "frozen"}); err == nil {
+		t.Fatal("expected unknown state to be rejected")
+	}
+	if _, err := service.UpdateSpaceState(ctx, created.ID, &space.UpdateSpaceStateRequest{State: " Active "}); err != nil {
+		t.Fatalf("expected normalized state to be accepted, got %v", err)
+	}
+	if err := service.EnsureWritable(ctx, created.ID); err != nil {
+		t.Fatalf("expected writes after reactivation, got %v", err)
+	}
+}
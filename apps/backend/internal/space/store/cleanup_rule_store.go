@@ -0,0 +1,230 @@
+package space
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	spaceDomain "taeu.kr/cohesion/internal/space"
+)
+
+// CleanupRuleStore는 Space별 자동 정리 규칙을 저장합니다.
+type CleanupRuleStore struct {
+	db *sql.DB
+	qb sq.StatementBuilderType
+}
+
+func NewCleanupRuleStore(db *sql.DB) *CleanupRuleStore {
+	return &CleanupRuleStore{
+		db: db,
+		qb: sq.StatementBuilder.PlaceholderFormat(sq.Question),
+	}
+}
+
+var cleanupRuleColumns = []string{
+	"id",
+	"space_id",
+	"path_prefix",
+	"max_age_days",
+	"pattern",
+	"action",
+	"enabled",
+	"created_by",
+	"created_at",
+	"updated_at",
+	"last_run_at",
+}
+
+func (s *CleanupRuleStore) ListCleanupRules(ctx context.Context, spaceID int64) ([]*spaceDomain.CleanupRule, error) {
+	query := s.qb.
+		Select(cleanupRuleColumns...).
+		From("space_cleanup_rules").
+		OrderBy("id")
+	if spaceID != 0 {
+		query = query.Where(sq.Eq{"space_id": spaceID})
+	}
+	sqlQuery, args, err := query.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build SQL query for ListCleanupRules: %w", err)
+	}
+
+	rows, err := s.db.QueryContext(ctx, sqlQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query cleanup rules: %w", err)
+	}
+	defer rows.Close()
+
+	rules := make([]*spaceDomain.CleanupRule, 0)
+	for rows.Next() {
+		rule, err := scanCleanupRule(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan cleanup rule row: %w", err)
+		}
+		rules = append(rules, rule)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error in ListCleanupRules: %w", err)
+	}
+	return rules, nil
+}
+
+func (s *CleanupRuleStore) GetCleanupRule(ctx context.Context, id int64) (*spaceDomain.CleanupRule, error) {
+	sqlQuery, args, err := s.qb.
+		Select(cleanupRuleColumns...).
+		From("space_cleanup_rules").
+		Where(sq.Eq{"id": id}).
+		Limit(1).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build SQL query for GetCleanupRule: %w", err)
+	}
+
+	rule, err := scanCleanupRule(s.db.QueryRowContext(ctx, sqlQuery, args...))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, spaceDomain.ErrCleanupRuleNotFound
+		}
+		return nil, fmt.Errorf("failed to scan cleanup rule row: %w", err)
+	}
+	return rule, nil
+}
+
+func (s *CleanupRuleStore) CreateCleanupRule(ctx context.Context, rule *spaceDomain.CleanupRule) (*spaceDomain.CleanupRule, error) {
+	sqlQuery, args, err := s.qb.
+		Insert("space_cleanup_rules").
+		Columns(cleanupRuleColumns[1:]...).
+		Values(
+			rule.SpaceID,
+			rule.PathPrefix,
+			rule.MaxAgeDays,
+			rule.Pattern,
+			string(rule.Action),
+			rule.Enabled,
+			rule.CreatedBy,
+			rule.CreatedAt.UTC(),
+			rule.UpdatedAt.UTC(),
+			nullableCleanupTime(rule.LastRunAt),
+		).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build SQL query for CreateCleanupRule: %w", err)
+	}
+
+	result, err := s.db.ExecContext(ctx, sqlQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to insert cleanup rule: %w", err)
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cleanup rule id: %w", err)
+	}
+
+	created := *rule
+	created.ID = id
+	return &created, nil
+}
+
+func (s *CleanupRuleStore) UpdateCleanupRule(ctx context.Context, rule *spaceDomain.CleanupRule) error {
+	sqlQuery, args, err := s.qb.
+		Update("space_cleanup_rules").
+		Set("path_prefix", rule.PathPrefix).
+		Set("max_age_days", rule.MaxAgeDays).
+		Set("pattern", rule.Pattern).
+		Set("action", string(rule.Action)).
+		Set("enabled", rule.Enabled).
+		Set("updated_at", rule.UpdatedAt.UTC()).
+		Where(sq.Eq{"id": rule.ID}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build SQL query for UpdateCleanupRule: %w", err)
+	}
+
+	result, err := s.db.ExecContext(ctx, sqlQuery, args...)
+	if err != nil {
+		return fmt.Errorf("failed to update cleanup rule: %w", err)
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		return spaceDomain.ErrCleanupRuleNotFound
+	}
+	return nil
+}
+
+func (s *CleanupRuleStore) DeleteCleanupRule(ctx context.Context, id int64) error {
+	sqlQuery, args, err := s.qb.
+		Delete("space_cleanup_rules").
+		Where(sq.Eq{"id": id}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build SQL query for DeleteCleanupRule: %w", err)
+	}
+
+	result, err := s.db.ExecContext(ctx, sqlQuery, args...)
+	if err != nil {
+		return fmt.Errorf("failed to delete cleanup rule: %w", err)
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		return spaceDomain.ErrCleanupRuleNotFound
+	}
+	return nil
+}
+
+func (s *CleanupRuleStore) MarkCleanupRuleRun(ctx context.Context, id int64, at time.Time) error {
+	sqlQuery, args, err := s.qb.
+		Update("space_cleanup_rules").
+		Set("last_run_at", at.UTC()).
+		Where(sq.Eq{"id": id}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build SQL query for MarkCleanupRuleRun: %w", err)
+	}
+
+	if _, err := s.db.ExecContext(ctx, sqlQuery, args...); err != nil {
+		return fmt.Errorf("failed to mark cleanup rule run: %w", err)
+	}
+	return nil
+}
+
+type cleanupRuleScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanCleanupRule(row cleanupRuleScanner) (*spaceDomain.CleanupRule, error) {
+	var (
+		rule      spaceDomain.CleanupRule
+		action    string
+		lastRunAt sql.NullTime
+	)
+	if err := row.Scan(
+		&rule.ID,
+		&rule.SpaceID,
+		&rule.PathPrefix,
+		&rule.MaxAgeDays,
+		&rule.Pattern,
+		&action,
+		&rule.Enabled,
+		&rule.CreatedBy,
+		&rule.CreatedAt,
+		&rule.UpdatedAt,
+		&lastRunAt,
+	); err != nil {
+		return nil, err
+	}
+	rule.Action = spaceDomain.CleanupAction(action)
+	if lastRunAt.Valid {
+		value := lastRunAt.Time
+		rule.LastRunAt = &value
+	}
+	return &rule, nil
+}
+
+func nullableCleanupTime(value *time.Time) any {
+	if value == nil {
+		return nil
+	}
+	return value.UTC()
+}
+
+var _ spaceDomain.CleanupRuleStorer = (*CleanupRuleStore)(nil)
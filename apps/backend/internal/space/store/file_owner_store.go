@@ -0,0 +1,142 @@
+package space
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+	"unicode/utf8"
+
+	sq "github.com/Masterminds/squirrel"
+	spaceDomain "taeu.kr/cohesion/internal/space"
+)
+
+type FileOwnerStore struct {
+	db *sql.DB
+	qb sq.StatementBuilderType
+}
+
+func NewFileOwnerStore(db *sql.DB) *FileOwnerStore {
+	return &FileOwnerStore{
+		db: db,
+		qb: sq.StatementBuilder.PlaceholderFormat(sq.Question),
+	}
+}
+
+// ownerPathCondition은 path 자신과 그 아래 경로를 고르는 조건입니다. '/' 다음 문자가 '0'이므로 범위 비교로 인덱스를 탑니다.
+func ownerPathCondition(path string) sq.Sqlizer {
+	if path == "" {
+		return sq.Expr("1 = 1")
+	}
+	return sq.Or{
+		sq.Eq{"path": path},
+		sq.And{sq.Gt{"path": path + "/"}, sq.Lt{"path": path + "0"}},
+	}
+}
+
+func (s *FileOwnerStore) SetFileOwners(ctx context.Context, spaceID int64, username string, files []spaceDomain.OwnedFile, at time.Time) error {
+	if len(files) == 0 {
+		return nil
+	}
+	builder := s.qb.
+		Insert("file_owners").
+		Columns("space_id", "path", "username", "size", "updated_at")
+	for _, file := range files {
+		builder = builder.Values(spaceID, file.Path, username, file.Size, at)
+	}
+	sqlQuery, args, err := builder.
+		Suffix("ON CONFLICT(space_id, path) DO UPDATE SET username = excluded.username, size = excluded.size, updated_at = excluded.updated_at").
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build SQL query for SetFileOwners: %w", err)
+	}
+
+	if _, err := s.db.ExecContext(ctx, sqlQuery, args...); err != nil {
+		return fmt.Errorf("failed to set file owners: %w", err)
+	}
+	return nil
+}
+
+func (s *FileOwnerStore) MoveFileOwners(ctx context.Context, fromSpaceID int64, fromPath string, toSpaceID int64, toPath string) error {
+	deleteQuery, deleteArgs, err := s.qb.
+		Delete("file_owners").
+		Where(sq.Eq{"space_id": toSpaceID}).
+		Where(ownerPathCondition(toPath)).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build SQL query for MoveFileOwners: %w", err)
+	}
+	updateQuery, updateArgs, err := s.qb.
+		Update("file_owners").
+		Set("space_id", toSpaceID).
+		Set("path", sq.Expr("? || substr(path, ?)", toPath, utf8.RuneCountInString(fromPath)+1)).
+		Where(sq.Eq{"space_id": fromSpaceID}).
+		Where(ownerPathCondition(fromPath)).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build SQL query for MoveFileOwners: %w", err)
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, deleteQuery, deleteArgs...); err != nil {
+		return fmt.Errorf("failed to delete replaced file owners: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, updateQuery, updateArgs...); err != nil {
+		return fmt.Errorf("failed to move file owners: %w", err)
+	}
+	return tx.Commit()
+}
+
+func (s *FileOwnerStore) DeleteFileOwners(ctx context.Context, spaceID int64, path string) error {
+	sqlQuery, args, err := s.qb.
+		Delete("file_owners").
+		Where(sq.Eq{"space_id": spaceID}).
+		Where(ownerPathCondition(path)).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build SQL query for DeleteFileOwners: %w", err)
+	}
+
+	if _, err := s.db.ExecContext(ctx, sqlQuery, args...); err != nil {
+		return fmt.Errorf("failed to delete file owners: %w", err)
+	}
+	return nil
+}
+
+func (s *FileOwnerStore) SumOwnedBytes(ctx context.Context, username string) ([]spaceDomain.OwnedUsage, error) {
+	builder := s.qb.
+		Select("username", "space_id", "COALESCE(SUM(size), 0)", "COUNT(*)").
+		From("file_owners").
+		GroupBy("username", "space_id").
+		OrderBy("username", "space_id")
+	if username != "" {
+		builder = builder.Where(sq.Eq{"username": username})
+	}
+	sqlQuery, args, err := builder.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build SQL query for SumOwnedBytes: %w", err)
+	}
+
+	rows, err := s.db.QueryContext(ctx, sqlQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query owned bytes: %w", err)
+	}
+	defer rows.Close()
+
+	result := make([]spaceDomain.OwnedUsage, 0)
+	for rows.Next() {
+		var item spaceDomain.OwnedUsage
+		if err := rows.Scan(&item.Username, &item.SpaceID, &item.UsedBytes, &item.FileCount); err != nil {
+			return nil, fmt.Errorf("failed to scan owned bytes: %w", err)
+		}
+		result = append(result, item)
+	}
+	return result, rows.Err()
+}
+
+var _ spaceDomain.FileOwnerStorer = (*FileOwnerStore)(nil)
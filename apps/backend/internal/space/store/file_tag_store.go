@@ -0,0 +1,97 @@
+package space
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	sq "github.com/Masterminds/squirrel"
+	spaceDomain "taeu.kr/cohesion/internal/space"
+)
+
+// FileTagStore는 파일 경로에 붙은 태그를 저장합니다.
+type FileTagStore struct {
+	db *sql.DB
+	qb sq.StatementBuilderType
+}
+
+func NewFileTagStore(db *sql.DB) *FileTagStore {
+	return &FileTagStore{
+		db: db,
+		qb: sq.StatementBuilder.PlaceholderFormat(sq.Question),
+	}
+}
+
+func (s *FileTagStore) AddFileTags(ctx context.Context, tags []spaceDomain.FileTag) error {
+	if len(tags) == 0 {
+		return nil
+	}
+	builder := s.qb.
+		Insert("file_tags").
+		Columns("space_id", "path", "tag", "created_by", "created_at")
+	for _, tag := range tags {
+		builder = builder.Values(tag.SpaceID, tag.Path, tag.Tag, tag.CreatedBy, tag.CreatedAt.UTC())
+	}
+	sqlQuery, args, err := builder.
+		Suffix("ON CONFLICT(space_id, path, tag) DO NOTHING").
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build SQL query for AddFileTags: %w", err)
+	}
+
+	if _, err := s.db.ExecContext(ctx, sqlQuery, args...); err != nil {
+		return fmt.Errorf("failed to add file tags: %w", err)
+	}
+	return nil
+}
+
+func (s *FileTagStore) ListFileTags(ctx context.Context, spaceID int64, query spaceDomain.FileTagQuery) ([]*spaceDomain.FileTag, error) {
+	builder := s.qb.
+		Select("space_id", "path", "tag", "created_by", "created_at").
+		From("file_tags").
+		Where(sq.Eq{"space_id": spaceID}).
+		OrderBy("path", "tag")
+	if query.Path != "" {
+		builder = builder.Where(sq.Eq{"path": query.Path})
+	}
+	if query.Tag != "" {
+		builder = builder.Where(sq.Eq{"tag": query.Tag})
+	}
+	sqlQuery, args, err := builder.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build SQL query for ListFileTags: %w", err)
+	}
+
+	rows, err := s.db.QueryContext(ctx, sqlQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query file tags: %w", err)
+	}
+	defer rows.Close()
+
+	tags := make([]*spaceDomain.FileTag, 0)
+	for rows.Next() {
+		var tag spaceDomain.FileTag
+		if err := rows.Scan(&tag.SpaceID, &tag.Path, &tag.Tag, &tag.CreatedBy, &tag.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan file tag row: %w", err)
+		}
+		tags = append(tags, &tag)
+	}
+	return tags, rows.Err()
+}
+
+func (s *FileTagStore) DeleteFileTags(ctx context.Context, spaceID int64, path string) error {
+	sqlQuery, args, err := s.qb.
+		Delete("file_tags").
+		Where(sq.Eq{"space_id": spaceID, "path": path}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build SQL query for DeleteFileTags: %w", err)
+	}
+
+	if _, err := s.db.ExecContext(ctx, sqlQuery, args...); err != nil {
+		return fmt.Errorf("failed to delete file tags: %w", err)
+	}
+	return nil
+}
+
+var _ spaceDomain.FileTagStorer = (*FileTagStore)(nil)
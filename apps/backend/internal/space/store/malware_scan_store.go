@@ -0,0 +1,228 @@
+package space
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	spaceDomain "taeu.kr/cohesion/internal/space"
+)
+
+// MalwareScanStore는 Space별 검사 설정과 격리 항목을 저장합니다.
+type MalwareScanStore struct {
+	db *sql.DB
+	qb sq.StatementBuilderType
+}
+
+func NewMalwareScanStore(db *sql.DB) *MalwareScanStore {
+	return &MalwareScanStore{
+		db: db,
+		qb: sq.StatementBuilder.PlaceholderFormat(sq.Question),
+	}
+}
+
+func (s *MalwareScanStore) GetMalwareScanSettings(ctx context.Context, spaceID int64) (*spaceDomain.MalwareScanSettings, error) {
+	sqlQuery, args, err := s.qb.
+		Select("mode", "updated_at").
+		From("space_malware_scan_settings").
+		Where(sq.Eq{"space_id": spaceID}).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build SQL query for GetMalwareScanSettings: %w", err)
+	}
+
+	var (
+		mode      string
+		updatedAt time.Time
+	)
+	if err := s.db.QueryRowContext(ctx, sqlQuery, args...).Scan(&mode, &updatedAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to scan malware scan settings: %w", err)
+	}
+	return &spaceDomain.MalwareScanSettings{
+		SpaceID:   spaceID,
+		Mode:      spaceDomain.MalwareScanMode(mode),
+		UpdatedAt: &updatedAt,
+	}, nil
+}
+
+func (s *MalwareScanStore) SaveMalwareScanSettings(ctx context.Context, settings *spaceDomain.MalwareScanSettings) error {
+	updatedAt := time.Now()
+	if settings.UpdatedAt != nil {
+		updatedAt = *settings.UpdatedAt
+	}
+
+	sqlQuery, args, err := s.qb.
+		Insert("space_malware_scan_settings").
+		Columns("space_id", "mode", "updated_at").
+		Values(settings.SpaceID, string(settings.Mode), updatedAt).
+		Suffix("ON CONFLICT(space_id) DO UPDATE SET mode = excluded.mode, updated_at = excluded.updated_at").
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build SQL query for SaveMalwareScanSettings: %w", err)
+	}
+
+	if _, err := s.db.ExecContext(ctx, sqlQuery, args...); err != nil {
+		return fmt.Errorf("failed to save malware scan settings: %w", err)
+	}
+	return nil
+}
+
+var quarantineItemColumns = []string{
+	"id",
+	"space_id",
+	"original_path",
+	"storage_path",
+	"item_name",
+	"item_size",
+	"signature",
+	"scanner",
+	"mode",
+	"source",
+	"uploaded_by",
+	"quarantined_at",
+}
+
+func (s *MalwareScanStore) CreateQuarantineItem(ctx context.Context, item *spaceDomain.QuarantineItem) (*spaceDomain.QuarantineItem, error) {
+	sqlQuery, args, err := s.qb.
+		Insert("quarantine_items").
+		Columns(quarantineItemColumns[1:]...).
+		Values(
+			item.SpaceID,
+			item.OriginalPath,
+			item.StoragePath,
+			item.ItemName,
+			item.ItemSize,
+			item.Signature,
+			item.Scanner,
+			item.Mode,
+			item.Source,
+			item.UploadedBy,
+			item.QuarantinedAt,
+		).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build SQL query for CreateQuarantineItem: %w", err)
+	}
+
+	result, err := s.db.ExecContext(ctx, sqlQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to insert quarantine item: %w", err)
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get quarantine item id: %w", err)
+	}
+
+	created := *item
+	created.ID = id
+	return &created, nil
+}
+
+func (s *MalwareScanStore) GetQuarantineItem(ctx context.Context, id int64) (*spaceDomain.QuarantineItem, error) {
+	sqlQuery, args, err := s.qb.
+		Select(quarantineItemColumns...).
+		From("quarantine_items").
+		Where(sq.Eq{"id": id}).
+		Limit(1).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build SQL query for GetQuarantineItem: %w", err)
+	}
+
+	item, err := scanQuarantineItem(s.db.QueryRowContext(ctx, sqlQuery, args...))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, spaceDomain.ErrQuarantineItemNotFound
+		}
+		return nil, fmt.Errorf("failed to scan quarantine item row: %w", err)
+	}
+	return item, nil
+}
+
+func (s *MalwareScanStore) ListQuarantineItems(ctx context.Context, spaceID int64) ([]*spaceDomain.QuarantineItem, error) {
+	query := s.qb.
+		Select(quarantineItemColumns...).
+		From("quarantine_items").
+		OrderBy("quarantined_at DESC", "id DESC")
+	if spaceID != 0 {
+		query = query.Where(sq.Eq{"space_id": spaceID})
+	}
+	sqlQuery, args, err := query.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build SQL query for ListQuarantineItems: %w", err)
+	}
+
+	rows, err := s.db.QueryContext(ctx, sqlQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query quarantine items: %w", err)
+	}
+	defer rows.Close()
+
+	items := make([]*spaceDomain.QuarantineItem, 0)
+	for rows.Next() {
+		item, err := scanQuarantineItem(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan quarantine item row: %w", err)
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error in ListQuarantineItems: %w", err)
+	}
+	return items, nil
+}
+
+func (s *MalwareScanStore) DeleteQuarantineItem(ctx context.Context, id int64) error {
+	sqlQuery, args, err := s.qb.
+		Delete("quarantine_items").
+		Where(sq.Eq{"id": id}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build SQL query for DeleteQuarantineItem: %w", err)
+	}
+
+	result, err := s.db.ExecContext(ctx, sqlQuery, args...)
+	if err != nil {
+		return fmt.Errorf("failed to delete quarantine item: %w", err)
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		return spaceDomain.ErrQuarantineItemNotFound
+	}
+	return nil
+}
+
+type quarantineItemScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanQuarantineItem(row quarantineItemScanner) (*spaceDomain.QuarantineItem, error) {
+	var item spaceDomain.QuarantineItem
+	if err := row.Scan(
+		&item.ID,
+		&item.SpaceID,
+		&item.OriginalPath,
+		&item.StoragePath,
+		&item.ItemName,
+		&item.ItemSize,
+		&item.Signature,
+		&item.Scanner,
+		&item.Mode,
+		&item.Source,
+		&item.UploadedBy,
+		&item.QuarantinedAt,
+	); err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+var (
+	_ spaceDomain.MalwareScanSettingsStorer = (*MalwareScanStore)(nil)
+	_ spaceDomain.QuarantineStorer          = (*MalwareScanStore)(nil)
+)
@@ -0,0 +1,227 @@
+package space
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	sq "github.com/Masterminds/squirrel"
+	spaceDomain "taeu.kr/cohesion/internal/space"
+)
+
+// OrganizeRuleStore는 Space별 파일 자동 정리 규칙을 저장합니다. 조건과 동작은 JSON으로 둡니다.
+type OrganizeRuleStore struct {
+	db *sql.DB
+	qb sq.StatementBuilderType
+}
+
+func NewOrganizeRuleStore(db *sql.DB) *OrganizeRuleStore {
+	return &OrganizeRuleStore{
+		db: db,
+		qb: sq.StatementBuilder.PlaceholderFormat(sq.Question),
+	}
+}
+
+var organizeRuleColumns = []string{
+	"id",
+	"space_id",
+	"name",
+	"watch_path",
+	"include_subfolders",
+	"conditions",
+	"actions",
+	"enabled",
+	"created_by",
+	"created_at",
+	"updated_at",
+}
+
+func (s *OrganizeRuleStore) ListOrganizeRules(ctx context.Context, spaceID int64) ([]*spaceDomain.OrganizeRule, error) {
+	sqlQuery, args, err := s.qb.
+		Select(organizeRuleColumns...).
+		From("space_organize_rules").
+		Where(sq.Eq{"space_id": spaceID}).
+		OrderBy("id").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build SQL query for ListOrganizeRules: %w", err)
+	}
+
+	rows, err := s.db.QueryContext(ctx, sqlQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query organize rules: %w", err)
+	}
+	defer rows.Close()
+
+	rules := make([]*spaceDomain.OrganizeRule, 0)
+	for rows.Next() {
+		rule, err := scanOrganizeRule(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan organize rule row: %w", err)
+		}
+		rules = append(rules, rule)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error in ListOrganizeRules: %w", err)
+	}
+	return rules, nil
+}
+
+func (s *OrganizeRuleStore) GetOrganizeRule(ctx context.Context, id int64) (*spaceDomain.OrganizeRule, error) {
+	sqlQuery, args, err := s.qb.
+		Select(organizeRuleColumns...).
+		From("space_organize_rules").
+		Where(sq.Eq{"id": id}).
+		Limit(1).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build SQL query for GetOrganizeRule: %w", err)
+	}
+
+	rule, err := scanOrganizeRule(s.db.QueryRowContext(ctx, sqlQuery, args...))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, spaceDomain.ErrOrganizeRuleNotFound
+		}
+		return nil, fmt.Errorf("failed to scan organize rule row: %w", err)
+	}
+	return rule, nil
+}
+
+func (s *OrganizeRuleStore) CreateOrganizeRule(ctx context.Context, rule *spaceDomain.OrganizeRule) (*spaceDomain.OrganizeRule, error) {
+	conditions, actions, err := marshalOrganizeRule(rule)
+	if err != nil {
+		return nil, err
+	}
+	sqlQuery, args, err := s.qb.
+		Insert("space_organize_rules").
+		Columns(organizeRuleColumns[1:]...).
+		Values(
+			rule.SpaceID,
+			rule.Name,
+			rule.WatchPath,
+			rule.IncludeSubfolders,
+			conditions,
+			actions,
+			rule.Enabled,
+			rule.CreatedBy,
+			rule.CreatedAt.UTC(),
+			rule.UpdatedAt.UTC(),
+		).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build SQL query for CreateOrganizeRule: %w", err)
+	}
+
+	result, err := s.db.ExecContext(ctx, sqlQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to insert organize rule: %w", err)
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get organize rule id: %w", err)
+	}
+
+	created := *rule
+	created.ID = id
+	return &created, nil
+}
+
+func (s *OrganizeRuleStore) UpdateOrganizeRule(ctx context.Context, rule *spaceDomain.OrganizeRule) error {
+	conditions, actions, err := marshalOrganizeRule(rule)
+	if err != nil {
+		return err
+	}
+	sqlQuery, args, err := s.qb.
+		Update("space_organize_rules").
+		Set("name", rule.Name).
+		Set("watch_path", rule.WatchPath).
+		Set("include_subfolders", rule.IncludeSubfolders).
+		Set("conditions", conditions).
+		Set("actions", actions).
+		Set("enabled", rule.Enabled).
+		Set("updated_at", rule.UpdatedAt.UTC()).
+		Where(sq.Eq{"id": rule.ID}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build SQL query for UpdateOrganizeRule: %w", err)
+	}
+
+	result, err := s.db.ExecContext(ctx, sqlQuery, args...)
+	if err != nil {
+		return fmt.Errorf("failed to update organize rule: %w", err)
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		return spaceDomain.ErrOrganizeRuleNotFound
+	}
+	return nil
+}
+
+func (s *OrganizeRuleStore) DeleteOrganizeRule(ctx context.Context, id int64) error {
+	sqlQuery, args, err := s.qb.
+		Delete("space_organize_rules").
+		Where(sq.Eq{"id": id}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build SQL query for DeleteOrganizeRule: %w", err)
+	}
+
+	result, err := s.db.ExecContext(ctx, sqlQuery, args...)
+	if err != nil {
+		return fmt.Errorf("failed to delete organize rule: %w", err)
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		return spaceDomain.ErrOrganizeRuleNotFound
+	}
+	return nil
+}
+
+func marshalOrganizeRule(rule *spaceDomain.OrganizeRule) (conditions string, actions string, err error) {
+	conditionsJSON, err := json.Marshal(rule.Conditions)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to encode organize rule conditions: %w", err)
+	}
+	actionsJSON, err := json.Marshal(rule.Actions)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to encode organize rule actions: %w", err)
+	}
+	return string(conditionsJSON), string(actionsJSON), nil
+}
+
+type organizeRuleScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanOrganizeRule(row organizeRuleScanner) (*spaceDomain.OrganizeRule, error) {
+	var (
+		rule       spaceDomain.OrganizeRule
+		conditions string
+		actions    string
+	)
+	if err := row.Scan(
+		&rule.ID,
+		&rule.SpaceID,
+		&rule.Name,
+		&rule.WatchPath,
+		&rule.IncludeSubfolders,
+		&conditions,
+		&actions,
+		&rule.Enabled,
+		&rule.CreatedBy,
+		&rule.CreatedAt,
+		&rule.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(conditions), &rule.Conditions); err != nil {
+		return nil, fmt.Errorf("failed to decode organize rule conditions: %w", err)
+	}
+	if err := json.Unmarshal([]byte(actions), &rule.Actions); err != nil {
+		return nil, fmt.Errorf("failed to decode organize rule actions: %w", err)
+	}
+	return &rule, nil
+}
+
+var _ spaceDomain.OrganizeRuleStorer = (*OrganizeRuleStore)(nil)
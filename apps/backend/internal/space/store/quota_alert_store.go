@@ -0,0 +1,76 @@
+package space
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	spaceDomain "taeu.kr/cohesion/internal/space"
+)
+
+type QuotaAlertStore struct {
+	db *sql.DB
+	qb sq.StatementBuilderType
+}
+
+func NewQuotaAlertStore(db *sql.DB) *QuotaAlertStore {
+	return &QuotaAlertStore{
+		db: db,
+		qb: sq.StatementBuilder.PlaceholderFormat(sq.Question),
+	}
+}
+
+func (s *QuotaAlertStore) GetQuotaAlertLevel(ctx context.Context, spaceID int64, metric spaceDomain.QuotaMetric) (int, error) {
+	sqlQuery, args, err := s.qb.
+		Select("threshold_percent").
+		From("space_quota_alerts").
+		Where(sq.Eq{"space_id": spaceID, "metric": string(metric)}).
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("failed to build SQL query for GetQuotaAlertLevel: %w", err)
+	}
+
+	var percent int
+	if err := s.db.QueryRowContext(ctx, sqlQuery, args...).Scan(&percent); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("failed to scan quota alert level: %w", err)
+	}
+	return percent, nil
+}
+
+// SaveQuotaAlertLevel은 percent가 0이면 기록을 지웁니다.
+func (s *QuotaAlertStore) SaveQuotaAlertLevel(ctx context.Context, spaceID int64, metric spaceDomain.QuotaMetric, percent int, at time.Time) error {
+	var (
+		sqlQuery string
+		args     []any
+		err      error
+	)
+	if percent == 0 {
+		sqlQuery, args, err = s.qb.
+			Delete("space_quota_alerts").
+			Where(sq.Eq{"space_id": spaceID, "metric": string(metric)}).
+			ToSql()
+	} else {
+		sqlQuery, args, err = s.qb.
+			Insert("space_quota_alerts").
+			Columns("space_id", "metric", "threshold_percent", "notified_at").
+			Values(spaceID, string(metric), percent, at).
+			Suffix("ON CONFLICT(space_id, metric) DO UPDATE SET threshold_percent = excluded.threshold_percent, notified_at = excluded.notified_at").
+			ToSql()
+	}
+	if err != nil {
+		return fmt.Errorf("failed to build SQL query for SaveQuotaAlertLevel: %w", err)
+	}
+
+	if _, err := s.db.ExecContext(ctx, sqlQuery, args...); err != nil {
+		return fmt.Errorf("failed to save quota alert level: %w", err)
+	}
+	return nil
+}
+
+var _ spaceDomain.QuotaAlertStorer = (*QuotaAlertStore)(nil)
@@ -0,0 +1,101 @@
+package space
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	sq "github.com/Masterminds/squirrel"
+	spaceDomain "taeu.kr/cohesion/internal/space"
+)
+
+// RetentionStore는 폴더별 보존 잠금을 저장합니다.
+type RetentionStore struct {
+	db *sql.DB
+	qb sq.StatementBuilderType
+}
+
+func NewRetentionStore(db *sql.DB) *RetentionStore {
+	return &RetentionStore{
+		db: db,
+		qb: sq.StatementBuilder.PlaceholderFormat(sq.Question),
+	}
+}
+
+func (s *RetentionStore) ListRetentionLocks(ctx context.Context, spaceID int64) ([]*spaceDomain.RetentionLock, error) {
+	sqlQuery, args, err := s.qb.
+		Select("path", "retain_until", "legal_hold", "reason", "created_by", "created_at", "updated_by", "updated_at").
+		From("space_retention_locks").
+		Where(sq.Eq{"space_id": spaceID}).
+		OrderBy("path").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build SQL query for ListRetentionLocks: %w", err)
+	}
+
+	rows, err := s.db.QueryContext(ctx, sqlQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query retention locks: %w", err)
+	}
+	defer rows.Close()
+
+	locks := make([]*spaceDomain.RetentionLock, 0)
+	for rows.Next() {
+		lock := &spaceDomain.RetentionLock{SpaceID: spaceID}
+		var retainUntil sql.NullTime
+		if err := rows.Scan(&lock.Path, &retainUntil, &lock.LegalHold, &lock.Reason, &lock.CreatedBy, &lock.CreatedAt, &lock.UpdatedBy, &lock.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan retention lock row: %w", err)
+		}
+		if retainUntil.Valid {
+			value := retainUntil.Time
+			lock.RetainUntil = &value
+		}
+		locks = append(locks, lock)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error in ListRetentionLocks: %w", err)
+	}
+	return locks, nil
+}
+
+func (s *RetentionStore) SaveRetentionLock(ctx context.Context, lock *spaceDomain.RetentionLock) error {
+	var retainUntil any
+	if lock.RetainUntil != nil {
+		retainUntil = lock.RetainUntil.UTC()
+	}
+	sqlQuery, args, err := s.qb.
+		Insert("space_retention_locks").
+		Columns("space_id", "path", "retain_until", "legal_hold", "reason", "created_by", "created_at", "updated_by", "updated_at").
+		Values(lock.SpaceID, lock.Path, retainUntil, lock.LegalHold, lock.Reason, lock.CreatedBy, lock.CreatedAt.UTC(), lock.UpdatedBy, lock.UpdatedAt.UTC()).
+		Suffix("ON CONFLICT(space_id, path) DO UPDATE SET retain_until = excluded.retain_until, legal_hold = excluded.legal_hold, reason = excluded.reason, updated_by = excluded.updated_by, updated_at = excluded.updated_at").
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build SQL query for SaveRetentionLock: %w", err)
+	}
+
+	if _, err := s.db.ExecContext(ctx, sqlQuery, args...); err != nil {
+		return fmt.Errorf("failed to save retention lock: %w", err)
+	}
+	return nil
+}
+
+func (s *RetentionStore) DeleteRetentionLock(ctx context.Context, spaceID int64, path string) error {
+	sqlQuery, args, err := s.qb.
+		Delete("space_retention_locks").
+		Where(sq.Eq{"space_id": spaceID, "path": path}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build SQL query for DeleteRetentionLock: %w", err)
+	}
+
+	result, err := s.db.ExecContext(ctx, sqlQuery, args...)
+	if err != nil {
+		return fmt.Errorf("failed to delete retention lock: %w", err)
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		return spaceDomain.ErrRetentionLockNotFound
+	}
+	return nil
+}
+
+var _ spaceDomain.RetentionLockStorer = (*RetentionStore)(nil)
@@ -164,6 +164,52 @@ func (s *SearchIndexStore) SearchEntries(ctx context.Context, spaceIDs []int64,
 	return results, rows.Err()
 }
 
+func (s *SearchIndexStore) ListSpaceEntries(ctx context.Context, spaceID int64) ([]spacepkg.SearchIndexEntry, error) {
+	query, args, err := s.qb.
+		Select(
+			"idx.name",
+			"idx.path",
+			"idx.parent_path",
+			"idx.is_dir",
+			"idx.size",
+			"idx.mod_time",
+		).
+		From("file_search_index idx").
+		Join("file_search_index_state st ON st.space_id = idx.space_id").
+		Where(sq.Eq{"idx.space_id": spaceID}).
+		Where(sq.Eq{"st.dirty": 0}).
+		OrderBy("idx.path ASC").
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []spacepkg.SearchIndexEntry{}
+	for rows.Next() {
+		item := spacepkg.SearchIndexEntry{SpaceID: spaceID}
+		var isDir int
+		if err := rows.Scan(
+			&item.Name,
+			&item.Path,
+			&item.ParentPath,
+			&isDir,
+			&item.Size,
+			&item.ModTime,
+		); err != nil {
+			return nil, err
+		}
+		item.IsDir = isDir == 1
+		entries = append(entries, item)
+	}
+	return entries, rows.Err()
+}
+
 func (s *SearchIndexStore) MarkSpaceDirty(ctx context.Context, spaceID int64) error {
 	if spaceID <= 0 {
 		return nil
@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
@@ -11,6 +12,70 @@ import (
 	"taeu.kr/cohesion/internal/space"
 )
 
+var spaceColumns = []string{
+	"id",
+	"space_name",
+	"space_slug",
+	"space_path",
+	"icon",
+	"space_category",
+	"quota_bytes",
+	"quota_files",
+	"quota_warning_percents",
+	"space_state",
+	"root_marker",
+	"webdav_archive_browsing",
+	"created_at",
+	"created_user_id",
+	"updated_at",
+	"updated_user_id",
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanSpace(scanner rowScanner) (*space.Space, error) {
+	var (
+		sp       space.Space
+		slug     sql.NullString
+		state    sql.NullString
+		marker   sql.NullString
+		warnings sql.NullString
+	)
+	if err := scanner.Scan(
+		&sp.ID,
+		&sp.SpaceName,
+		&slug,
+		&sp.SpacePath,
+		&sp.Icon,
+		&sp.SpaceCategory,
+		&sp.QuotaBytes,
+		&sp.QuotaFiles,
+		&warnings,
+		&state,
+		&marker,
+		&sp.WebDAVArchiveBrowsing,
+		&sp.CreatedAt,
+		&sp.CreatedUserID,
+		&sp.UpdatedAt,
+		&sp.UpdatedUserID,
+	); err != nil {
+		return nil, err
+	}
+	sp.SpaceSlug = slug.String
+	sp.SpaceState = space.SpaceState(state.String)
+	sp.RootMarker = marker.String
+	if warnings.Valid {
+		percents, err := parseQuotaWarningPercents(warnings.String)
+		if err != nil {
+			return nil, err
+		}
+		sp.QuotaWarningPercents = percents
+	}
+	return &sp, nil
+}
+
 type Store struct {
 	db *sql.DB
 	qb sq.StatementBuilderType
@@ -25,18 +90,7 @@ func NewStore(db *sql.DB) *Store {
 
 func (s *Store) GetAll(ctx context.Context) ([]*space.Space, error) {
 	sqlQuery, args, err := s.qb.
-		Select(
-			"id",
-			"space_name",
-			"space_path",
-			"icon",
-			"space_category",
-			"quota_bytes",
-			"created_at",
-			"created_user_id",
-			"updated_at",
-			"updated_user_id",
-		).
+		Select(spaceColumns...).
 		From("space").
 		ToSql()
 	if err != nil {
@@ -52,22 +106,11 @@ func (s *Store) GetAll(ctx context.Context) ([]*space.Space, error) {
 	var spaces []*space.Space
 
 	for rows.Next() {
-		var sp space.Space
-		if err := rows.Scan(
-			&sp.ID,
-			&sp.SpaceName,
-			&sp.SpacePath,
-			&sp.Icon,
-			&sp.SpaceCategory,
-			&sp.QuotaBytes,
-			&sp.CreatedAt,
-			&sp.CreatedUserID,
-			&sp.UpdatedAt,
-			&sp.UpdatedUserID,
-		); err != nil {
+		sp, err := scanSpace(rows)
+		if err != nil {
 			return nil, fmt.Errorf("failed to scan space row: %w", err)
 		}
-		spaces = append(spaces, &sp)
+		spaces = append(spaces, sp)
 	}
 
 	if err := rows.Err(); err != nil {
@@ -83,18 +126,7 @@ func (s *Store) GetAll(ctx context.Context) ([]*space.Space, error) {
 
 func (s *Store) GetByName(ctx context.Context, name string) (*space.Space, error) {
 	sqlQuery, args, err := s.qb.
-		Select(
-			"id",
-			"space_name",
-			"space_path",
-			"icon",
-			"space_category",
-			"quota_bytes",
-			"created_at",
-			"created_user_id",
-			"updated_at",
-			"updated_user_id",
-		).
+		Select(spaceColumns...).
 		From("space").
 		Where(sq.Eq{"space_name": name}).
 		ToSql()
@@ -104,42 +136,20 @@ func (s *Store) GetByName(ctx context.Context, name string) (*space.Space, error
 
 	row := s.db.QueryRowContext(ctx, sqlQuery, args...)
 
-	var sp space.Space
-	if err := row.Scan(
-		&sp.ID,
-		&sp.SpaceName,
-		&sp.SpacePath,
-		&sp.Icon,
-		&sp.SpaceCategory,
-		&sp.QuotaBytes,
-		&sp.CreatedAt,
-		&sp.CreatedUserID,
-		&sp.UpdatedAt,
-		&sp.UpdatedUserID,
-	); err != nil {
+	sp, err := scanSpace(row)
+	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("space with name '%s' not found", name)
+			return nil, fmt.Errorf("%w: name '%s'", space.ErrSpaceNotFound, name)
 		}
 		return nil, fmt.Errorf("failed to scan space row: %w", err)
 	}
 
-	return &sp, nil
+	return sp, nil
 }
 
 func (s *Store) GetByID(ctx context.Context, id int64) (*space.Space, error) {
 	sqlQuery, args, err := s.qb.
-		Select(
-			"id",
-			"space_name",
-			"space_path",
-			"icon",
-			"space_category",
-			"quota_bytes",
-			"created_at",
-			"created_user_id",
-			"updated_at",
-			"updated_user_id",
-		).
+		Select(spaceColumns...).
 		From("space").
 		Where(sq.Eq{"id": id}).
 		ToSql()
@@ -149,26 +159,15 @@ func (s *Store) GetByID(ctx context.Context, id int64) (*space.Space, error) {
 
 	row := s.db.QueryRowContext(ctx, sqlQuery, args...)
 
-	var sp space.Space
-	if err := row.Scan(
-		&sp.ID,
-		&sp.SpaceName,
-		&sp.SpacePath,
-		&sp.Icon,
-		&sp.SpaceCategory,
-		&sp.QuotaBytes,
-		&sp.CreatedAt,
-		&sp.CreatedUserID,
-		&sp.UpdatedAt,
-		&sp.UpdatedUserID,
-	); err != nil {
+	sp, err := scanSpace(row)
+	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("space with id %d not found", id)
+			return nil, fmt.Errorf("%w: id %d", space.ErrSpaceNotFound, id)
 		}
 		return nil, fmt.Errorf("failed to scan space row: %w", err)
 	}
 
-	return &sp, nil
+	return sp, nil
 }
 
 // Create는 새로운 Space를 데이터베이스에 저장합니다
@@ -180,6 +179,7 @@ func (s *Store) Create(ctx context.Context, req *space.CreateSpaceRequest) (*spa
 		Insert("space").
 		Columns(
 			"space_name",
+			"space_slug",
 			"space_path",
 			"icon",
 			"space_category",
@@ -188,6 +188,7 @@ func (s *Store) Create(ctx context.Context, req *space.CreateSpaceRequest) (*spa
 		).
 		Values(
 			req.SpaceName,
+			nullableSlug(req.SpaceSlug),
 			req.SpacePath,
 			req.Icon,
 			req.SpaceCategory,
@@ -219,6 +220,7 @@ func (s *Store) Create(ctx context.Context, req *space.CreateSpaceRequest) (*spa
 	return &space.Space{
 		ID:            id,
 		SpaceName:     req.SpaceName,
+		SpaceSlug:     req.SpaceSlug,
 		SpacePath:     req.SpacePath,
 		Icon:          req.Icon,
 		SpaceCategory: req.SpaceCategory,
@@ -230,10 +232,12 @@ func (s *Store) Create(ctx context.Context, req *space.CreateSpaceRequest) (*spa
 	}, nil
 }
 
-func (s *Store) UpdateQuota(ctx context.Context, id int64, quotaBytes *int64) (*space.Space, error) {
+func (s *Store) UpdateQuota(ctx context.Context, id int64, limits space.QuotaLimits) (*space.Space, error) {
 	sqlQuery, args, err := s.qb.
 		Update("space").
-		Set("quota_bytes", quotaBytes).
+		Set("quota_bytes", limits.QuotaBytes).
+		Set("quota_files", limits.QuotaFiles).
+		Set("quota_warning_percents", formatQuotaWarningPercents(limits.WarningPercents)).
 		Where(sq.Eq{"id": id}).
 		ToSql()
 	if err != nil {
@@ -250,7 +254,7 @@ func (s *Store) UpdateQuota(ctx context.Context, id int64, quotaBytes *int64) (*
 		return nil, fmt.Errorf("failed to get rows affected for UpdateQuota: %w", err)
 	}
 	if rowsAffected == 0 {
-		return nil, fmt.Errorf("space with id %d not found", id)
+		return nil, fmt.Errorf("%w: id %d", space.ErrSpaceNotFound, id)
 	}
 
 	return s.GetByID(ctx, id)
@@ -286,7 +290,63 @@ func (s *Store) Update(ctx context.Context, id int64, req *space.UpdateSpaceRequ
 		return nil, fmt.Errorf("failed to get rows affected for Update: %w", err)
 	}
 	if rowsAffected == 0 {
-		return nil, fmt.Errorf("space with id %d not found", id)
+		return nil, fmt.Errorf("%w: id %d", space.ErrSpaceNotFound, id)
+	}
+
+	return s.GetByID(ctx, id)
+}
+
+// UpdatePath는 Space root 경로를 갱신합니다
+func (s *Store) UpdatePath(ctx context.Context, id int64, spacePath string) (*space.Space, error) {
+	sqlQuery, args, err := s.qb.
+		Update("space").
+		Set("space_path", spacePath).
+		Set("updated_at", time.Now()).
+		Where(sq.Eq{"id": id}).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build SQL query for UpdatePath: %w", err)
+	}
+
+	result, err := s.db.ExecContext(ctx, sqlQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update space path: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get rows affected for UpdatePath: %w", err)
+	}
+	if rowsAffected == 0 {
+		return nil, fmt.Errorf("%w: id %d", space.ErrSpaceNotFound, id)
+	}
+
+	return s.GetByID(ctx, id)
+}
+
+// UpdateState는 Space 운영 상태를 변경합니다.
+func (s *Store) UpdateState(ctx context.Context, id int64, state space.SpaceState) (*space.Space, error) {
+	sqlQuery, args, err := s.qb.
+		Update("space").
+		Set("space_state", string(state)).
+		Set("updated_at", time.Now()).
+		Where(sq.Eq{"id": id}).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build SQL query for UpdateState: %w", err)
+	}
+
+	result, err := s.db.ExecContext(ctx, sqlQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update space state: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get rows affected for UpdateState: %w", err)
+	}
+	if rowsAffected == 0 {
+		return nil, fmt.Errorf("%w: id %d", space.ErrSpaceNotFound, id)
 	}
 
 	return s.GetByID(ctx, id)
@@ -314,8 +374,192 @@ func (s *Store) Delete(ctx context.Context, id int64) error {
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("space with id %d not found", id)
+		return fmt.Errorf("%w: id %d", space.ErrSpaceNotFound, id)
+	}
+
+	aliasQuery, aliasArgs, err := s.qb.
+		Delete("space_aliases").
+		Where(sq.Eq{"space_id": id}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build SQL query for Delete aliases: %w", err)
+	}
+	if _, err := s.db.ExecContext(ctx, aliasQuery, aliasArgs...); err != nil {
+		return fmt.Errorf("failed to delete space aliases: %w", err)
+	}
+
+	return nil
+}
+
+// GetBySlug는 프로토콜 경로에 사용하는 slug로 Space를 조회합니다
+func (s *Store) GetBySlug(ctx context.Context, slug string) (*space.Space, error) {
+	sqlQuery, args, err := s.qb.
+		Select(spaceColumns...).
+		From("space").
+		Where(sq.Eq{"space_slug": slug}).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build SQL query for GetBySlug: %w", err)
+	}
+
+	sp, err := scanSpace(s.db.QueryRowContext(ctx, sqlQuery, args...))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("%w: slug '%s'", space.ErrSpaceNotFound, slug)
+		}
+		return nil, fmt.Errorf("failed to scan space row: %w", err)
+	}
+
+	return sp, nil
+}
+
+// GetByAlias는 이름 변경 전 이름(alias)으로 Space를 조회합니다
+func (s *Store) GetByAlias(ctx context.Context, alias string) (*space.Space, error) {
+	sqlQuery, args, err := s.qb.
+		Select(prefixColumns("s", spaceColumns)...).
+		From("space_aliases a").
+		Join("space s ON s.id = a.space_id").
+		Where(sq.Eq{"a.alias": alias}).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build SQL query for GetByAlias: %w", err)
+	}
+
+	sp, err := scanSpace(s.db.QueryRowContext(ctx, sqlQuery, args...))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("%w: alias '%s'", space.ErrSpaceNotFound, alias)
+		}
+		return nil, fmt.Errorf("failed to scan space row: %w", err)
+	}
+
+	return sp, nil
+}
+
+// AddAlias는 Space의 이전 이름을 alias로 기록합니다. 같은 alias가 있으면 새 Space로 옮깁니다.
+func (s *Store) AddAlias(ctx context.Context, spaceID int64, alias string) error {
+	sqlQuery, args, err := s.qb.
+		Insert("space_aliases").
+		Columns("alias", "space_id", "created_at").
+		Values(alias, spaceID, time.Now()).
+		Suffix("ON CONFLICT(alias) DO UPDATE SET space_id = excluded.space_id, created_at = excluded.created_at").
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build SQL query for AddAlias: %w", err)
+	}
+
+	if _, err := s.db.ExecContext(ctx, sqlQuery, args...); err != nil {
+		return fmt.Errorf("failed to insert space alias: %w", err)
+	}
+	return nil
+}
+
+// AssignSlug는 slug가 비어 있는 Space에만 slug를 기록합니다. slug는 한 번 정해지면 바뀌지 않습니다.
+func (s *Store) AssignSlug(ctx context.Context, id int64, slug string) error {
+	sqlQuery, args, err := s.qb.
+		Update("space").
+		Set("space_slug", slug).
+		Where(sq.Eq{"id": id}).
+		Where(sq.Or{sq.Eq{"space_slug": nil}, sq.Eq{"space_slug": ""}}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build SQL query for AssignSlug: %w", err)
+	}
+
+	if _, err := s.db.ExecContext(ctx, sqlQuery, args...); err != nil {
+		if strings.Contains(err.Error(), "UNIQUE constraint failed") {
+			return fmt.Errorf("%w: slug '%s'", space.ErrSpaceProtocolNameTaken, slug)
+		}
+		return fmt.Errorf("failed to assign space slug: %w", err)
+	}
+	return nil
+}
+
+// UpdateWebDAVArchiveBrowsing은 WebDAV 압축 파일 탐색 여부를 변경합니다.
+func (s *Store) UpdateWebDAVArchiveBrowsing(ctx context.Context, id int64, enabled bool) (*space.Space, error) {
+	sqlQuery, args, err := s.qb.
+		Update("space").
+		Set("webdav_archive_browsing", enabled).
+		Set("updated_at", time.Now()).
+		Where(sq.Eq{"id": id}).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build SQL query for UpdateWebDAVArchiveBrowsing: %w", err)
+	}
+
+	result, err := s.db.ExecContext(ctx, sqlQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update space webdav settings: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get rows affected for UpdateWebDAVArchiveBrowsing: %w", err)
+	}
+	if rowsAffected == 0 {
+		return nil, fmt.Errorf("%w: id %d", space.ErrSpaceNotFound, id)
 	}
 
+	return s.GetByID(ctx, id)
+}
+
+// AssignRootMarker는 root 마커 ID를 기록합니다. 이미 값이 있으면 바꾸지 않습니다.
+func (s *Store) AssignRootMarker(ctx context.Context, id int64, marker string) error {
+	sqlQuery, args, err := s.qb.
+		Update("space").
+		Set("root_marker", marker).
+		Where(sq.Eq{"id": id}).
+		Where(sq.Or{sq.Eq{"root_marker": nil}, sq.Eq{"root_marker": ""}}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build SQL query for AssignRootMarker: %w", err)
+	}
+
+	if _, err := s.db.ExecContext(ctx, sqlQuery, args...); err != nil {
+		return fmt.Errorf("failed to assign space root marker: %w", err)
+	}
 	return nil
 }
+
+// formatQuotaWarningPercents는 경고 비율을 "80,95" 형태로 저장합니다. nil은 NULL(기본값), 빈 목록은 빈 문자열(경고 끔)입니다.
+func formatQuotaWarningPercents(percents []int) any {
+	if percents == nil {
+		return nil
+	}
+	parts := make([]string, 0, len(percents))
+	for _, percent := range percents {
+		parts = append(parts, strconv.Itoa(percent))
+	}
+	return strings.Join(parts, ",")
+}
+
+func parseQuotaWarningPercents(value string) ([]int, error) {
+	percents := []int{}
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		percent, err := strconv.Atoi(part)
+		if err != nil {
+			return nil, fmt.Errorf("invalid quota warning percents %q: %w", value, err)
+		}
+		percents = append(percents, percent)
+	}
+	return percents, nil
+}
+
+func nullableSlug(slug string) any {
+	if slug == "" {
+		return nil
+	}
+	return slug
+}
+
+func prefixColumns(alias string, columns []string) []string {
+	prefixed := make([]string, 0, len(columns))
+	for _, column := range columns {
+		prefixed = append(prefixed, alias+"."+column)
+	}
+	return prefixed
+}
@@ -199,6 +199,22 @@ func (s *TrashStore) DeleteTrashItemByID(ctx context.Context, id int64) error {
 	return nil
 }
 
+func (s *TrashStore) UpdateTrashItemStoragePath(ctx context.Context, id int64, storagePath string) error {
+	sqlQuery, args, err := s.qb.
+		Update("trash_items").
+		Set("storage_path", storagePath).
+		Where(sq.Eq{"id": id}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build SQL query for UpdateTrashItemStoragePath: %w", err)
+	}
+
+	if _, err := s.db.ExecContext(ctx, sqlQuery, args...); err != nil {
+		return fmt.Errorf("failed to update trash item storage path: %w", err)
+	}
+	return nil
+}
+
 func (s *TrashStore) DeleteTrashItemsBySpace(ctx context.Context, spaceID int64) error {
 	sqlQuery, args, err := s.qb.
 		Delete("trash_items").
@@ -0,0 +1,124 @@
+package space
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	spaceDomain "taeu.kr/cohesion/internal/space"
+)
+
+type UploadPolicyStore struct {
+	db *sql.DB
+	qb sq.StatementBuilderType
+}
+
+func NewUploadPolicyStore(db *sql.DB) *UploadPolicyStore {
+	return &UploadPolicyStore{
+		db: db,
+		qb: sq.StatementBuilder.PlaceholderFormat(sq.Question),
+	}
+}
+
+func (s *UploadPolicyStore) GetUploadPolicy(ctx context.Context, spaceID int64) (*spaceDomain.UploadPolicy, error) {
+	sqlQuery, args, err := s.qb.
+		Select("allowed_extensions", "blocked_extensions", "allowed_types", "blocked_types", "max_file_bytes", "updated_at").
+		From("space_upload_policies").
+		Where(sq.Eq{"space_id": spaceID}).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build SQL query for GetUploadPolicy: %w", err)
+	}
+
+	var (
+		allowedExtensions, blockedExtensions string
+		allowedTypes, blockedTypes           string
+		maxFileBytes                         sql.NullInt64
+		updatedAt                            time.Time
+	)
+	if err := s.db.QueryRowContext(ctx, sqlQuery, args...).Scan(&allowedExtensions, &blockedExtensions, &allowedTypes, &blockedTypes, &maxFileBytes, &updatedAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to scan upload policy: %w", err)
+	}
+
+	policy := &spaceDomain.UploadPolicy{
+		SpaceID:           spaceID,
+		AllowedExtensions: splitPolicyList(allowedExtensions),
+		BlockedExtensions: splitPolicyList(blockedExtensions),
+		AllowedTypes:      splitPolicyList(allowedTypes),
+		BlockedTypes:      splitPolicyList(blockedTypes),
+		UpdatedAt:         &updatedAt,
+	}
+	if maxFileBytes.Valid {
+		policy.MaxFileBytes = &maxFileBytes.Int64
+	}
+	return policy, nil
+}
+
+func (s *UploadPolicyStore) SaveUploadPolicy(ctx context.Context, policy *spaceDomain.UploadPolicy) error {
+	updatedAt := time.Now()
+	if policy.UpdatedAt != nil {
+		updatedAt = *policy.UpdatedAt
+	}
+	var maxFileBytes any
+	if policy.MaxFileBytes != nil {
+		maxFileBytes = *policy.MaxFileBytes
+	}
+
+	sqlQuery, args, err := s.qb.
+		Insert("space_upload_policies").
+		Columns("space_id", "allowed_extensions", "blocked_extensions", "allowed_types", "blocked_types", "max_file_bytes", "updated_at").
+		Values(
+			policy.SpaceID,
+			strings.Join(policy.AllowedExtensions, ","),
+			strings.Join(policy.BlockedExtensions, ","),
+			strings.Join(policy.AllowedTypes, ","),
+			strings.Join(policy.BlockedTypes, ","),
+			maxFileBytes,
+			updatedAt,
+		).
+		Suffix("ON CONFLICT(space_id) DO UPDATE SET allowed_extensions = excluded.allowed_extensions, blocked_extensions = excluded.blocked_extensions, allowed_types = excluded.allowed_types, blocked_types = excluded.blocked_types, max_file_bytes = excluded.max_file_bytes, updated_at = excluded.updated_at").
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build SQL query for SaveUploadPolicy: %w", err)
+	}
+
+	if _, err := s.db.ExecContext(ctx, sqlQuery, args...); err != nil {
+		return fmt.Errorf("failed to save upload policy: %w", err)
+	}
+	return nil
+}
+
+func (s *UploadPolicyStore) DeleteUploadPolicy(ctx context.Context, spaceID int64) error {
+	sqlQuery, args, err := s.qb.
+		Delete("space_upload_policies").
+		Where(sq.Eq{"space_id": spaceID}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build SQL query for DeleteUploadPolicy: %w", err)
+	}
+
+	if _, err := s.db.ExecContext(ctx, sqlQuery, args...); err != nil {
+		return fmt.Errorf("failed to delete upload policy: %w", err)
+	}
+	return nil
+}
+
+// splitPolicyList는 쉼표로 이은 목록을 나눕니다. 빈 문자열은 빈 목록입니다.
+func splitPolicyList(value string) []string {
+	items := []string{}
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+var _ spaceDomain.UploadPolicyStorer = (*UploadPolicyStore)(nil)
@@ -0,0 +1,96 @@
+package space
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	spaceDomain "taeu.kr/cohesion/internal/space"
+)
+
+type UsageCounterStore struct {
+	db *sql.DB
+	qb sq.StatementBuilderType
+}
+
+func NewUsageCounterStore(db *sql.DB) *UsageCounterStore {
+	return &UsageCounterStore{
+		db: db,
+		qb: sq.StatementBuilder.PlaceholderFormat(sq.Question),
+	}
+}
+
+func (s *UsageCounterStore) GetUsageCounter(ctx context.Context, spaceID int64) (*spaceDomain.UsageCounter, error) {
+	sqlQuery, args, err := s.qb.
+		Select("used_bytes", "used_files", "reconciled_at", "updated_at").
+		From("space_usage_counters").
+		Where(sq.Eq{"space_id": spaceID}).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build SQL query for GetUsageCounter: %w", err)
+	}
+
+	counter := &spaceDomain.UsageCounter{SpaceID: spaceID}
+	if err := s.db.QueryRowContext(ctx, sqlQuery, args...).Scan(&counter.UsedBytes, &counter.UsedFiles, &counter.ReconciledAt, &counter.UpdatedAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, spaceDomain.ErrUsageCounterNotFound
+		}
+		return nil, fmt.Errorf("failed to scan usage counter: %w", err)
+	}
+	return counter, nil
+}
+
+func (s *UsageCounterStore) SaveUsageCounter(ctx context.Context, counter *spaceDomain.UsageCounter) error {
+	sqlQuery, args, err := s.qb.
+		Insert("space_usage_counters").
+		Columns("space_id", "used_bytes", "used_files", "reconciled_at", "updated_at").
+		Values(counter.SpaceID, counter.UsedBytes, counter.UsedFiles, counter.ReconciledAt, counter.UpdatedAt).
+		Suffix("ON CONFLICT(space_id) DO UPDATE SET used_bytes = excluded.used_bytes, used_files = excluded.used_files, reconciled_at = excluded.reconciled_at, updated_at = excluded.updated_at").
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build SQL query for SaveUsageCounter: %w", err)
+	}
+
+	if _, err := s.db.ExecContext(ctx, sqlQuery, args...); err != nil {
+		return fmt.Errorf("failed to save usage counter: %w", err)
+	}
+	return nil
+}
+
+func (s *UsageCounterStore) AddUsage(ctx context.Context, spaceID int64, deltaBytes int64, deltaFiles int64, at time.Time) error {
+	sqlQuery, args, err := s.qb.
+		Update("space_usage_counters").
+		Set("used_bytes", sq.Expr("MAX(used_bytes + ?, 0)", deltaBytes)).
+		Set("used_files", sq.Expr("MAX(used_files + ?, 0)", deltaFiles)).
+		Set("updated_at", at).
+		Where(sq.Eq{"space_id": spaceID}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build SQL query for AddUsage: %w", err)
+	}
+
+	if _, err := s.db.ExecContext(ctx, sqlQuery, args...); err != nil {
+		return fmt.Errorf("failed to add usage: %w", err)
+	}
+	return nil
+}
+
+func (s *UsageCounterStore) DeleteUsageCounter(ctx context.Context, spaceID int64) error {
+	sqlQuery, args, err := s.qb.
+		Delete("space_usage_counters").
+		Where(sq.Eq{"space_id": spaceID}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build SQL query for DeleteUsageCounter: %w", err)
+	}
+
+	if _, err := s.db.ExecContext(ctx, sqlQuery, args...); err != nil {
+		return fmt.Errorf("failed to delete usage counter: %w", err)
+	}
+	return nil
+}
+
+var _ spaceDomain.UsageCounterStorer = (*UsageCounterStore)(nil)
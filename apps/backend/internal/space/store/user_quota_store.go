@@ -0,0 +1,92 @@
+package space
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	spaceDomain "taeu.kr/cohesion/internal/space"
+)
+
+type UserQuotaStore struct {
+	db *sql.DB
+	qb sq.StatementBuilderType
+}
+
+func NewUserQuotaStore(db *sql.DB) *UserQuotaStore {
+	return &UserQuotaStore{
+		db: db,
+		qb: sq.StatementBuilder.PlaceholderFormat(sq.Question),
+	}
+}
+
+func (s *UserQuotaStore) ListUserQuotas(ctx context.Context, username string) ([]*spaceDomain.UserQuota, error) {
+	builder := s.qb.
+		Select("username", "space_id", "quota_bytes").
+		From("user_storage_quotas").
+		OrderBy("username", "space_id")
+	if username != "" {
+		builder = builder.Where(sq.Eq{"username": username})
+	}
+	sqlQuery, args, err := builder.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build SQL query for ListUserQuotas: %w", err)
+	}
+
+	rows, err := s.db.QueryContext(ctx, sqlQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query user quotas: %w", err)
+	}
+	defer rows.Close()
+
+	result := make([]*spaceDomain.UserQuota, 0)
+	for rows.Next() {
+		quota := &spaceDomain.UserQuota{}
+		if err := rows.Scan(&quota.Username, &quota.SpaceID, &quota.QuotaBytes); err != nil {
+			return nil, fmt.Errorf("failed to scan user quota: %w", err)
+		}
+		result = append(result, quota)
+	}
+	return result, rows.Err()
+}
+
+func (s *UserQuotaStore) ReplaceUserQuotas(ctx context.Context, username string, quotas []*spaceDomain.UserQuota) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	deleteQuery, deleteArgs, err := s.qb.
+		Delete("user_storage_quotas").
+		Where(sq.Eq{"username": username}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build SQL query for ReplaceUserQuotas: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, deleteQuery, deleteArgs...); err != nil {
+		return fmt.Errorf("failed to delete user quotas: %w", err)
+	}
+
+	if len(quotas) > 0 {
+		now := time.Now()
+		builder := s.qb.
+			Insert("user_storage_quotas").
+			Columns("username", "space_id", "quota_bytes", "updated_at")
+		for _, quota := range quotas {
+			builder = builder.Values(username, quota.SpaceID, quota.QuotaBytes, now)
+		}
+		insertQuery, insertArgs, err := builder.ToSql()
+		if err != nil {
+			return fmt.Errorf("failed to build SQL query for ReplaceUserQuotas: %w", err)
+		}
+		if _, err := tx.ExecContext(ctx, insertQuery, insertArgs...); err != nil {
+			return fmt.Errorf("failed to insert user quotas: %w", err)
+		}
+	}
+	return tx.Commit()
+}
+
+var _ spaceDomain.UserQuotaStorer = (*UserQuotaStore)(nil)
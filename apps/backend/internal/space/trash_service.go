@@ -3,6 +3,8 @@ package space
 import (
 	"context"
 	"fmt"
+	"path/filepath"
+	"strings"
 )
 
 type TrashStorer interface {
@@ -13,6 +15,10 @@ type TrashStorer interface {
 	DeleteTrashItemsBySpace(ctx context.Context, spaceID int64) error
 }
 
+type trashStoragePathUpdatable interface {
+	UpdateTrashItemStoragePath(ctx context.Context, id int64, storagePath string) error
+}
+
 type TrashService struct {
 	store TrashStorer
 }
@@ -28,7 +34,7 @@ func (s *TrashService) CreateTrashItem(ctx context.Context, req *CreateTrashItem
 		return nil, fmt.Errorf("trash create request is required")
 	}
 	if req.SpaceID <= 0 {
-		return nil, fmt.Errorf("invalid space id: %d", req.SpaceID)
+		return nil, fmt.Errorf("%w: %d", ErrInvalidSpaceID, req.SpaceID)
 	}
 	if req.OriginalPath == "" {
 		return nil, fmt.Errorf("original path is required")
@@ -47,7 +53,7 @@ func (s *TrashService) CreateTrashItem(ctx context.Context, req *CreateTrashItem
 
 func (s *TrashService) ListTrashItems(ctx context.Context, spaceID int64) ([]*TrashItem, error) {
 	if spaceID <= 0 {
-		return nil, fmt.Errorf("invalid space id: %d", spaceID)
+		return nil, fmt.Errorf("%w: %d", ErrInvalidSpaceID, spaceID)
 	}
 	return s.store.ListTrashItemsBySpace(ctx, spaceID)
 }
@@ -68,7 +74,44 @@ func (s *TrashService) DeleteTrashItem(ctx context.Context, id int64) error {
 
 func (s *TrashService) DeleteTrashItemsBySpace(ctx context.Context, spaceID int64) error {
 	if spaceID <= 0 {
-		return fmt.Errorf("invalid space id: %d", spaceID)
+		return fmt.Errorf("%w: %d", ErrInvalidSpaceID, spaceID)
 	}
 	return s.store.DeleteTrashItemsBySpace(ctx, spaceID)
 }
+
+// RewriteStoragePaths는 Space root가 바뀐 뒤 이전 root 기준 절대 경로로 남은 storage_path를
+// root 기준 상대 경로로 바꿉니다. 이미 상대 경로인 항목은 새 root에서 그대로 유효합니다.
+func (s *TrashService) RewriteStoragePaths(ctx context.Context, spaceID int64, oldRoot string) (int, error) {
+	if spaceID <= 0 {
+		return 0, fmt.Errorf("%w: %d", ErrInvalidSpaceID, spaceID)
+	}
+	updatable, ok := s.store.(trashStoragePathUpdatable)
+	if !ok {
+		return 0, fmt.Errorf("trash store does not support storage path updates")
+	}
+
+	items, err := s.store.ListTrashItemsBySpace(ctx, spaceID)
+	if err != nil {
+		return 0, err
+	}
+
+	rewritten := 0
+	for _, item := range items {
+		if !filepath.IsAbs(item.StoragePath) {
+			continue
+		}
+		relative, err := filepath.Rel(filepath.Clean(oldRoot), filepath.Clean(item.StoragePath))
+		if err != nil {
+			return rewritten, err
+		}
+		relative = filepath.ToSlash(relative)
+		if relative == ".." || strings.HasPrefix(relative, "../") {
+			continue
+		}
+		if err := updatable.UpdateTrashItemStoragePath(ctx, item.ID, relative); err != nil {
+			return rewritten, err
+		}
+		rewritten++
+	}
+	return rewritten, nil
+}
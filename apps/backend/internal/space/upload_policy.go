@@ -0,0 +1,478 @@
+package space
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrInvalidUploadPolicy는 업로드 정책 값이 잘못된 경우입니다.
+var ErrInvalidUploadPolicy = errors.New("invalid upload policy")
+
+// UploadPolicySniffBytes는 파일 형식을 판별할 때 읽는 앞부분 길이입니다.
+const UploadPolicySniffBytes = 512
+
+// UploadPolicyViolation은 업로드 정책에 걸린 이유입니다. API 응답의 code로도 씁니다.
+type UploadPolicyViolation string
+
+const (
+	UploadPolicyExtensionNotAllowed UploadPolicyViolation = "extension_not_allowed"
+	UploadPolicyExtensionBlocked    UploadPolicyViolation = "extension_blocked"
+	UploadPolicyTypeNotAllowed      UploadPolicyViolation = "type_not_allowed"
+	UploadPolicyTypeBlocked         UploadPolicyViolation = "type_blocked"
+	UploadPolicyFileTooLarge        UploadPolicyViolation = "file_too_large"
+)
+
+// UploadPolicy는 Space에 새로 들어오는 파일에 거는 제한입니다. 빈 목록과 nil은 제한이 없다는 뜻입니다.
+// 확장자는 점 없이 소문자로 두며 "tar.gz"처럼 여러 단계도 됩니다.
+// 형식(MIME)은 이름이 아니라 파일 앞부분(magic bytes)으로 판별하며 "image/*"처럼 대분류로도 지정할 수 있습니다.
+type UploadPolicy struct {
+	SpaceID           int64    `json:"spaceId"`
+	AllowedExtensions []string `json:"allowedExtensions"`
+	BlockedExtensions []string `json:"blockedExtensions"`
+	AllowedTypes      []string `json:"allowedTypes"`
+	BlockedTypes      []string `json:"blockedTypes"`
+	// MaxFileBytes는 파일 하나의 최대 크기입니다. nil이면 제한이 없습니다.
+	MaxFileBytes *int64     `json:"maxFileBytes,omitempty"`
+	UpdatedAt    *time.Time `json:"updatedAt,omitempty"`
+}
+
+// UploadPolicyError는 업로드 정책을 어긴 쓰기입니다. Detail은 걸린 확장자나 판별한 형식입니다.
+type UploadPolicyError struct {
+	SpaceID      int64
+	Name         string
+	Violation    UploadPolicyViolation
+	Detail       string
+	MaxFileBytes int64
+}
+
+func (e *UploadPolicyError) Error() string {
+	return fmt.Sprintf("upload policy violation (spaceId=%d, name=%s, violation=%s, detail=%s)", e.SpaceID, e.Name, e.Violation, e.Detail)
+}
+
+// Reason은 사용자에게 보여 줄 거절 사유입니다.
+func (e *UploadPolicyError) Reason() string {
+	switch e.Violation {
+	case UploadPolicyExtensionNotAllowed:
+		if e.Detail == "" {
+			return "Files without an extension are not allowed in this Space"
+		}
+		return fmt.Sprintf("Extension .%s is not allowed in this Space", e.Detail)
+	case UploadPolicyExtensionBlocked:
+		return fmt.Sprintf("Extension .%s is blocked in this Space", e.Detail)
+	case UploadPolicyTypeNotAllowed:
+		return fmt.Sprintf("File type %s is not allowed in this Space", e.Detail)
+	case UploadPolicyTypeBlocked:
+		return fmt.Sprintf("File type %s is blocked in this Space", e.Detail)
+	case UploadPolicyFileTooLarge:
+		return fmt.Sprintf("File exceeds the maximum size of %d bytes for this Space", e.MaxFileBytes)
+	default:
+		return "Upload policy violation"
+	}
+}
+
+// UploadPolicyStorer는 Space별 업로드 정책 저장소입니다.
+type UploadPolicyStorer interface {
+	// GetUploadPolicy는 정책이 없으면 nil을 반환합니다.
+	GetUploadPolicy(ctx context.Context, spaceID int64) (*UploadPolicy, error)
+	SaveUploadPolicy(ctx context.Context, policy *UploadPolicy) error
+	DeleteUploadPolicy(ctx context.Context, spaceID int64) error
+}
+
+// UploadPolicyEnforcer는 WebDAV/SFTP/FTP 쓰기 경로가 업로드 정책을 확인할 때 쓰는 기능입니다.
+type UploadPolicyEnforcer interface {
+	// BeginUpload는 name을 확인하고 쓰는 내용을 검사할 guard를 반환합니다.
+	BeginUpload(ctx context.Context, spaceID int64, name string) (*UploadWriteGuard, error)
+	// CheckName은 이름(확장자)만 확인합니다. 이름을 바꿀 때 씁니다.
+	CheckName(ctx context.Context, spaceID int64, name string) error
+	// CheckPath는 absPath 아래 모든 파일을 확인합니다. 다른 Space에서 들여올 때 씁니다.
+	CheckPath(ctx context.Context, spaceID int64, absPath string) error
+}
+
+// IsEmpty는 아무 제한도 없는 정책인지 확인합니다.
+func (p *UploadPolicy) IsEmpty() bool {
+	return p == nil || (len(p.AllowedExtensions) == 0 && len(p.BlockedExtensions) == 0 &&
+		len(p.AllowedTypes) == 0 && len(p.BlockedTypes) == 0 && p.MaxFileBytes == nil)
+}
+
+// Normalize는 목록을 소문자로 바꾸고 중복을 없애며 값을 검사합니다.
+func (p *UploadPolicy) Normalize() error {
+	var err error
+	if p.AllowedExtensions, err = normalizeUploadPolicyExtensions(p.AllowedExtensions); err != nil {
+		return err
+	}
+	if p.BlockedExtensions, err = normalizeUploadPolicyExtensions(p.BlockedExtensions); err != nil {
+		return err
+	}
+	if p.AllowedTypes, err = normalizeUploadPolicyTypes(p.AllowedTypes); err != nil {
+		return err
+	}
+	if p.BlockedTypes, err = normalizeUploadPolicyTypes(p.BlockedTypes); err != nil {
+		return err
+	}
+	if p.MaxFileBytes != nil && *p.MaxFileBytes <= 0 {
+		return fmt.Errorf("%w: max file bytes %d", ErrInvalidUploadPolicy, *p.MaxFileBytes)
+	}
+	return nil
+}
+
+func normalizeUploadPolicyExtensions(values []string) ([]string, error) {
+	normalized := make([]string, 0, len(values))
+	for _, value := range values {
+		ext := strings.ToLower(strings.TrimPrefix(strings.TrimSpace(value), "."))
+		if ext == "" || strings.ContainsAny(ext, `/\, `) {
+			return nil, fmt.Errorf("%w: extension %q", ErrInvalidUploadPolicy, value)
+		}
+		if !slices.Contains(normalized, ext) {
+			normalized = append(normalized, ext)
+		}
+	}
+	slices.Sort(normalized)
+	return normalized, nil
+}
+
+func normalizeUploadPolicyTypes(values []string) ([]string, error) {
+	normalized := make([]string, 0, len(values))
+	for _, value := range values {
+		mediaType := strings.ToLower(strings.TrimSpace(value))
+		major, minor, ok := strings.Cut(mediaType, "/")
+		if !ok || major == "" || minor == "" || strings.ContainsAny(mediaType, ", ;") || (major == "*" && minor != "*") {
+			return nil, fmt.Errorf("%w: media type %q", ErrInvalidUploadPolicy, value)
+		}
+		if !slices.Contains(normalized, mediaType) {
+			normalized = append(normalized, mediaType)
+		}
+	}
+	slices.Sort(normalized)
+	return normalized, nil
+}
+
+// CheckName은 이름의 확장자를 허용·차단 목록과 비교합니다. 차단 목록이 우선합니다.
+func (p *UploadPolicy) CheckName(name string) error {
+	if p == nil {
+		return nil
+	}
+	base := strings.ToLower(filepath.Base(name))
+	if ext := matchUploadPolicyExtension(base, p.BlockedExtensions); ext != "" {
+		return p.violation(name, UploadPolicyExtensionBlocked, ext)
+	}
+	if len(p.AllowedExtensions) > 0 && matchUploadPolicyExtension(base, p.AllowedExtensions) == "" {
+		return p.violation(name, UploadPolicyExtensionNotAllowed, fileExtension(base))
+	}
+	return nil
+}
+
+// CheckSize는 size가 파일 하나의 최대 크기를 넘는지 확인합니다.
+func (p *UploadPolicy) CheckSize(name string, size int64) error {
+	if p == nil || p.MaxFileBytes == nil || size <= *p.MaxFileBytes {
+		return nil
+	}
+	return p.violation(name, UploadPolicyFileTooLarge, fmt.Sprintf("%d bytes", size))
+}
+
+// CheckContent는 파일 앞부분 head로 형식을 판별해 허용·차단 목록과 비교합니다. 빈 파일은 판별하지 않습니다.
+func (p *UploadPolicy) CheckContent(name string, head []byte) error {
+	if p == nil || len(head) == 0 || (len(p.AllowedTypes) == 0 && len(p.BlockedTypes) == 0) {
+		return nil
+	}
+	detected := DetectContentType(head)
+	if matchUploadPolicyType(detected, p.BlockedTypes) {
+		return p.violation(name, UploadPolicyTypeBlocked, detected)
+	}
+	if len(p.AllowedTypes) > 0 && !matchUploadPolicyType(detected, p.AllowedTypes) {
+		return p.violation(name, UploadPolicyTypeNotAllowed, detected)
+	}
+	return nil
+}
+
+// CheckFile은 디스크의 absPath 파일 하나를 name이라는 이름으로 들여온다고 보고 확인합니다.
+func (p *UploadPolicy) CheckFile(name string, absPath string) error {
+	if p.IsEmpty() {
+		return nil
+	}
+	if err := p.CheckName(name); err != nil {
+		return err
+	}
+	file, err := os.Open(absPath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	if err := p.CheckSize(name, info.Size()); err != nil {
+		return err
+	}
+	head := make([]byte, UploadPolicySniffBytes)
+	n, err := io.ReadFull(file, head)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return err
+	}
+	return p.CheckContent(name, head[:n])
+}
+
+// NewWriteGuard는 name으로 쓰는 내용을 받아 보며 크기와 형식을 확인하는 guard를 만듭니다.
+func (p *UploadPolicy) NewWriteGuard(name string) *UploadWriteGuard {
+	if p.IsEmpty() {
+		return nil
+	}
+	return &UploadWriteGuard{policy: p, name: name}
+}
+
+func (p *UploadPolicy) violation(name string, violation UploadPolicyViolation, detail string) error {
+	err := &UploadPolicyError{SpaceID: p.SpaceID, Name: filepath.Base(name), Violation: violation, Detail: detail}
+	if p.MaxFileBytes != nil {
+		err.MaxFileBytes = *p.MaxFileBytes
+	}
+	return err
+}
+
+// matchUploadPolicyExtension은 base가 끝나는 확장자 중 목록에 있는 것을 반환합니다. 없으면 빈 문자열입니다.
+func matchUploadPolicyExtension(base string, extensions []string) string {
+	for _, ext := range extensions {
+		if strings.HasSuffix(base, "."+ext) && len(base) > len(ext)+1 {
+			return ext
+		}
+	}
+	return ""
+}
+
+func fileExtension(base string) string {
+	ext := filepath.Ext(base)
+	if ext == base {
+		return ""
+	}
+	return strings.TrimPrefix(ext, ".")
+}
+
+func matchUploadPolicyType(detected string, types []string) bool {
+	major, _, _ := strings.Cut(detected, "/")
+	for _, mediaType := range types {
+		if mediaType == "*/*" || mediaType == detected || mediaType == major+"/*" {
+			return true
+		}
+	}
+	return false
+}
+
+// executableSignatures는 net/http가 판별하지 못하는 실행 파일 형식입니다.
+var executableSignatures = []struct {
+	prefix    []byte
+	mediaType string
+}{
+	{[]byte("MZ"), "application/x-msdownload"},
+	{[]byte("\x7fELF"), "application/x-executable"},
+	{[]byte{0xfe, 0xed, 0xfa, 0xce}, "application/x-mach-binary"},
+	{[]byte{0xfe, 0xed, 0xfa, 0xcf}, "application/x-mach-binary"},
+	{[]byte{0xce, 0xfa, 0xed, 0xfe}, "application/x-mach-binary"},
+	{[]byte{0xcf, 0xfa, 0xed, 0xfe}, "application/x-mach-binary"},
+	{[]byte{0xca, 0xfe, 0xba, 0xbe}, "application/x-mach-binary"},
+	{[]byte("#!"), "text/x-shellscript"},
+}
+
+// DetectContentType은 파일 앞부분으로 형식을 판별해 매개변수 없는 MIME 형식을 반환합니다.
+// 실행 파일과 스크립트를 먼저 보고, 나머지는 net/http의 판별 규칙을 따릅니다.
+func DetectContentType(head []byte) string {
+	if len(head) > UploadPolicySniffBytes {
+		head = head[:UploadPolicySniffBytes]
+	}
+	for _, signature := range executableSignatures {
+		if bytes.HasPrefix(head, signature.prefix) {
+			return signature.mediaType
+		}
+	}
+	detected := http.DetectContentType(head)
+	if mediaType, _, err := mime.ParseMediaType(detected); err == nil {
+		return mediaType
+	}
+	return detected
+}
+
+// UploadWriteGuard는 한 파일에 쓰는 내용을 받아 보며 최대 크기와 형식을 확인합니다.
+// nil이면 아무것도 확인하지 않습니다. 여러 goroutine에서 불러도 됩니다.
+type UploadWriteGuard struct {
+	policy   *UploadPolicy
+	name     string
+	mu       sync.Mutex
+	sniffed  bool
+	rejected bool
+}
+
+// Check는 offset 위치에 p를 써도 되는지 확인합니다. 파일 앞부분(offset 0)을 처음 받을 때 형식을 판별합니다.
+func (g *UploadWriteGuard) Check(offset int64, p []byte) error {
+	if g == nil {
+		return nil
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	err := g.policy.CheckSize(g.name, offset+int64(len(p)))
+	if err == nil && !g.sniffed && offset == 0 && len(p) > 0 {
+		g.sniffed = true
+		err = g.policy.CheckContent(g.name, p)
+	}
+	if err != nil {
+		g.rejected = true
+	}
+	return err
+}
+
+// CheckSize는 쓰기 전에 알고 있는 전체 크기 size를 확인합니다.
+func (g *UploadWriteGuard) CheckSize(size int64) error {
+	if g == nil {
+		return nil
+	}
+	return g.policy.CheckSize(g.name, size)
+}
+
+// Rejected는 Check가 한 번이라도 정책 위반을 반환했는지 확인합니다.
+func (g *UploadWriteGuard) Rejected() bool {
+	if g == nil {
+		return false
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.rejected
+}
+
+// UploadPolicyService는 Space별 업로드 정책을 저장하고 쓰기 경로에서 확인합니다.
+type UploadPolicyService struct {
+	store UploadPolicyStorer
+}
+
+// NewUploadPolicyService는 store가 nil이면 메모리 저장소를 씁니다.
+func NewUploadPolicyService(store UploadPolicyStorer) *UploadPolicyService {
+	if store == nil {
+		store = NewMemoryUploadPolicyStore()
+	}
+	return &UploadPolicyService{store: store}
+}
+
+// GetUploadPolicy는 Space의 정책을 반환합니다. 정해 둔 정책이 없으면 빈 정책입니다.
+func (s *UploadPolicyService) GetUploadPolicy(ctx context.Context, spaceID int64) (*UploadPolicy, error) {
+	policy, err := s.store.GetUploadPolicy(ctx, spaceID)
+	if err != nil {
+		return nil, err
+	}
+	if policy == nil {
+		return &UploadPolicy{
+			SpaceID:           spaceID,
+			AllowedExtensions: []string{},
+			BlockedExtensions: []string{},
+			AllowedTypes:      []string{},
+			BlockedTypes:      []string{},
+		}, nil
+	}
+	return policy, nil
+}
+
+// SaveUploadPolicy는 정책을 검사해 통째로 바꿉니다. 아무 제한도 없으면 저장된 정책을 지웁니다.
+// 이미 들어 있는 파일에는 소급해 적용하지 않습니다.
+func (s *UploadPolicyService) SaveUploadPolicy(ctx context.Context, policy *UploadPolicy) (*UploadPolicy, error) {
+	if err := policy.Normalize(); err != nil {
+		return nil, err
+	}
+	if policy.IsEmpty() {
+		if err := s.store.DeleteUploadPolicy(ctx, policy.SpaceID); err != nil {
+			return nil, err
+		}
+		return s.GetUploadPolicy(ctx, policy.SpaceID)
+	}
+	now := time.Now()
+	policy.UpdatedAt = &now
+	if err := s.store.SaveUploadPolicy(ctx, policy); err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+// CheckName은 Space 정책으로 이름(확장자)을 확인합니다.
+func (s *UploadPolicyService) CheckName(ctx context.Context, spaceID int64, name string) error {
+	policy, err := s.store.GetUploadPolicy(ctx, spaceID)
+	if err != nil {
+		return err
+	}
+	return policy.CheckName(name)
+}
+
+// BeginUpload는 이름을 확인하고 쓰는 내용을 검사할 guard를 반환합니다. 정책이 없으면 guard는 nil입니다.
+func (s *UploadPolicyService) BeginUpload(ctx context.Context, spaceID int64, name string) (*UploadWriteGuard, error) {
+	policy, err := s.store.GetUploadPolicy(ctx, spaceID)
+	if err != nil {
+		return nil, err
+	}
+	if err := policy.CheckName(name); err != nil {
+		return nil, err
+	}
+	return policy.NewWriteGuard(name), nil
+}
+
+// CheckPath는 absPath(파일 또는 폴더) 아래 모든 파일을 Space 정책으로 확인합니다. 첫 위반에서 멈춥니다.
+func (s *UploadPolicyService) CheckPath(ctx context.Context, spaceID int64, absPath string) error {
+	policy, err := s.store.GetUploadPolicy(ctx, spaceID)
+	if err != nil || policy.IsEmpty() {
+		return err
+	}
+	return filepath.WalkDir(absPath, func(path string, entry fs.DirEntry, walkErr error) error {
+		if walkErr != nil {
+			return walkErr
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+		return policy.CheckFile(entry.Name(), path)
+	})
+}
+
+var _ UploadPolicyEnforcer = (*UploadPolicyService)(nil)
+
+// MemoryUploadPolicyStore는 프로세스 메모리에만 정책을 두는 UploadPolicyStorer입니다.
+type MemoryUploadPolicyStore struct {
+	mu       sync.RWMutex
+	policies map[int64]UploadPolicy
+}
+
+func NewMemoryUploadPolicyStore() *MemoryUploadPolicyStore {
+	return &MemoryUploadPolicyStore{policies: make(map[int64]UploadPolicy)}
+}
+
+func (s *MemoryUploadPolicyStore) GetUploadPolicy(ctx context.Context, spaceID int64) (*UploadPolicy, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	policy, ok := s.policies[spaceID]
+	if !ok {
+		return nil, nil
+	}
+	return &policy, nil
+}
+
+func (s *MemoryUploadPolicyStore) SaveUploadPolicy(ctx context.Context, policy *UploadPolicy) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.policies[policy.SpaceID] = *policy
+	return nil
+}
+
+func (s *MemoryUploadPolicyStore) DeleteUploadPolicy(ctx context.Context, spaceID int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.policies, spaceID)
+	return nil
+}
+
+var _ UploadPolicyStorer = (*MemoryUploadPolicyStore)(nil)
@@ -0,0 +1,90 @@
+package space_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"taeu.kr/cohesion/internal/space"
+	spaceStore "taeu.kr/cohesion/internal/space/store"
+)
+
+func TestUploadPolicyService_PersistsPolicyAndChecksNamesContentAndSize(t *testing.T) {
+	service, db := setupSlugSpaceService(t)
+	ctx := context.Background()
+	root := t.TempDir()
+	created, err := service.CreateSpace(ctx, &space.CreateSpaceRequest{SpaceName: "Photos", SpacePath: root})
+	if err != nil {
+		t.Fatalf("create space: %v", err)
+	}
+
+	policies := space.NewUploadPolicyService(spaceStore.NewUploadPolicyStore(db))
+	maxBytes := int64(16)
+	if _, err := policies.SaveUploadPolicy(ctx, &space.UploadPolicy{
+		SpaceID:           created.ID,
+		AllowedExtensions: []string{"JPG", ".png"},
+		AllowedTypes:      []string{"image/*"},
+		MaxFileBytes:      &maxBytes,
+	}); err != nil {
+		t.Fatalf("save policy: %v", err)
+	}
+	loaded, err := policies.GetUploadPolicy(ctx, created.ID)
+	if err != nil {
+		t.Fatalf("get policy: %v", err)
+	}
+	if len(loaded.AllowedExtensions) != 2 || loaded.AllowedExtensions[0] != "jpg" || loaded.MaxFileBytes == nil || *loaded.MaxFileBytes != 16 {
+		t.Fatalf("unexpected stored policy: %+v", loaded)
+	}
+
+	var policyErr *space.UploadPolicyError
+	if err := policies.CheckName(ctx, created.ID, "disk.iso"); !errors.As(err, &policyErr) || policyErr.Violation != space.UploadPolicyExtensionNotAllowed {
+		t.Fatalf("expected extension_not_allowed, got %v", err)
+	}
+
+	// 이름은 허용되지만 내용이 실행 파일이면 형식으로 걸린다.
+	guard, err := policies.BeginUpload(ctx, created.ID, "photo.jpg")
+	if err != nil {
+		t.Fatalf("begin upload: %v", err)
+	}
+	if err := guard.Check(0, []byte("\x7fELF\x02\x01\x01")); !errors.As(err, &policyErr) || policyErr.Violation != space.UploadPolicyTypeNotAllowed || policyErr.Detail != "application/x-executable" {
+		t.Fatalf("expected type_not_allowed for ELF content, got %v", err)
+	}
+	if !guard.Rejected() {
+		t.Fatal("expected guard to remember the rejection")
+	}
+
+	png := []byte("\x89PNG\r\n\x1a\n0000")
+	guard, _ = policies.BeginUpload(ctx, created.ID, "photo.png")
+	if err := guard.Check(0, png); err != nil {
+		t.Fatalf("expected PNG to pass, got %v", err)
+	}
+	if err := guard.Check(int64(len(png)), []byte("12345")); !errors.As(err, &policyErr) || policyErr.Violation != space.UploadPolicyFileTooLarge {
+		t.Fatalf("expected file_too_large past max size, got %v", err)
+	}
+
+	// 다른 곳에서 들여오는 폴더는 안의 파일을 모두 확인한다.
+	incoming := t.TempDir()
+	if err := os.WriteFile(filepath.Join(incoming, "ok.png"), png, 0o644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+	if err := policies.CheckPath(ctx, created.ID, incoming); err != nil {
+		t.Fatalf("expected folder of PNGs to pass, got %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(incoming, "fake.png"), []byte("#!/bin/sh\necho hi\n"), 0o644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+	if err := policies.CheckPath(ctx, created.ID, incoming); !errors.As(err, &policyErr) || policyErr.Name != "fake.png" {
+		t.Fatalf("expected script disguised as PNG to fail, got %v", err)
+	}
+
+	// 제한을 모두 비우면 정책이 지워진다.
+	cleared, err := policies.SaveUploadPolicy(ctx, &space.UploadPolicy{SpaceID: created.ID})
+	if err != nil || !cleared.IsEmpty() {
+		t.Fatalf("expected cleared policy, got %+v (%v)", cleared, err)
+	}
+	if err := policies.CheckName(ctx, created.ID, "disk.iso"); err != nil {
+		t.Fatalf("expected no restriction after clearing policy, got %v", err)
+	}
+}
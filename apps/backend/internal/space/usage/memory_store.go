@@ -0,0 +1,61 @@
+package usage
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+type snapshotKey struct {
+	spaceID int64
+	day     time.Time
+}
+
+// MemoryStore는 프로세스 메모리에만 사용량 기록을 보관하는 Storer입니다.
+// DB 없이 핸들러를 쓰는 테스트나 기본 구성에서 사용합니다.
+type MemoryStore struct {
+	mu        sync.Mutex
+	snapshots map[snapshotKey]Snapshot
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{snapshots: make(map[snapshotKey]Snapshot)}
+}
+
+func (s *MemoryStore) Upsert(ctx context.Context, snapshot *Snapshot) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stored := *snapshot
+	stored.Day = Day(snapshot.Day)
+	s.snapshots[snapshotKey{spaceID: snapshot.SpaceID, day: stored.Day}] = stored
+	return nil
+}
+
+func (s *MemoryStore) List(ctx context.Context, spaceID int64, since time.Time) ([]*Snapshot, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	snapshots := make([]*Snapshot, 0)
+	for key, snapshot := range s.snapshots {
+		if key.spaceID != spaceID || key.day.Before(since) {
+			continue
+		}
+		copied := snapshot
+		snapshots = append(snapshots, &copied)
+	}
+	sort.Slice(snapshots, func(i, j int) bool { return snapshots[i].Day.Before(snapshots[j].Day) })
+	return snapshots, nil
+}
+
+func (s *MemoryStore) DeleteBefore(ctx context.Context, before time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for key := range s.snapshots {
+		if key.day.Before(before) {
+			delete(s.snapshots, key)
+		}
+	}
+	return nil
+}
+
+var _ Storer = (*MemoryStore)(nil)
@@ -0,0 +1,106 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"taeu.kr/cohesion/internal/space/usage"
+)
+
+// dayLayout은 space_usage_snapshots.day 컬럼 형식입니다.
+const dayLayout = "2006-01-02"
+
+type Store struct {
+	db *sql.DB
+	qb sq.StatementBuilderType
+}
+
+func NewStore(db *sql.DB) *Store {
+	return &Store{
+		db: db,
+		qb: sq.StatementBuilder.PlaceholderFormat(sq.Question),
+	}
+}
+
+func (s *Store) Upsert(ctx context.Context, snapshot *usage.Snapshot) error {
+	var quotaBytes any
+	if snapshot.QuotaBytes != nil {
+		quotaBytes = *snapshot.QuotaBytes
+	}
+	sqlQuery, args, err := s.qb.
+		Insert("space_usage_snapshots").
+		Columns("space_id", "day", "used_bytes", "quota_bytes", "recorded_at").
+		Values(snapshot.SpaceID, usage.Day(snapshot.Day).Format(dayLayout), snapshot.UsedBytes, quotaBytes, snapshot.RecordedAt.UTC()).
+		Suffix("ON CONFLICT(space_id, day) DO UPDATE SET used_bytes = excluded.used_bytes, quota_bytes = excluded.quota_bytes, recorded_at = excluded.recorded_at").
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build SQL query for Upsert: %w", err)
+	}
+
+	if _, err := s.db.ExecContext(ctx, sqlQuery, args...); err != nil {
+		return fmt.Errorf("failed to store usage snapshot: %w", err)
+	}
+	return nil
+}
+
+func (s *Store) List(ctx context.Context, spaceID int64, since time.Time) ([]*usage.Snapshot, error) {
+	sqlQuery, args, err := s.qb.
+		Select("day", "used_bytes", "quota_bytes", "recorded_at").
+		From("space_usage_snapshots").
+		Where(sq.Eq{"space_id": spaceID}).
+		Where(sq.GtOrEq{"day": usage.Day(since).Format(dayLayout)}).
+		OrderBy("day ASC").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build SQL query for List: %w", err)
+	}
+
+	rows, err := s.db.QueryContext(ctx, sqlQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query usage snapshots: %w", err)
+	}
+	defer rows.Close()
+
+	snapshots := make([]*usage.Snapshot, 0)
+	for rows.Next() {
+		var (
+			day        string
+			quotaBytes sql.NullInt64
+			snapshot   = usage.Snapshot{SpaceID: spaceID}
+		)
+		if err := rows.Scan(&day, &snapshot.UsedBytes, &quotaBytes, &snapshot.RecordedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan usage snapshot: %w", err)
+		}
+		snapshot.Day, err = time.Parse(dayLayout, day)
+		if err != nil {
+			return nil, fmt.Errorf("invalid usage snapshot day %q: %w", day, err)
+		}
+		if quotaBytes.Valid {
+			value := quotaBytes.Int64
+			snapshot.QuotaBytes = &value
+		}
+		snapshots = append(snapshots, &snapshot)
+	}
+	return snapshots, rows.Err()
+}
+
+func (s *Store) DeleteBefore(ctx context.Context, before time.Time) error {
+	sqlQuery, args, err := s.qb.
+		Delete("space_usage_snapshots").
+		Where(sq.Lt{"day": usage.Day(before).Format(dayLayout)}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build SQL query for DeleteBefore: %w", err)
+	}
+
+	if _, err := s.db.ExecContext(ctx, sqlQuery, args...); err != nil {
+		return fmt.Errorf("failed to delete usage snapshots: %w", err)
+	}
+	return nil
+}
+
+var _ usage.Storer = (*Store)(nil)
@@ -0,0 +1,241 @@
+package usage
+
+import (
+	"context"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"taeu.kr/cohesion/internal/platform/logging"
+	"taeu.kr/cohesion/internal/space"
+)
+
+const (
+	// DefaultInterval는 오늘 기록이 없는 Space를 찾는 주기입니다. 기록은 Space마다 하루 한 번입니다.
+	DefaultInterval = time.Hour
+	// DefaultRetention은 사용량 기록을 보관하는 기간입니다.
+	DefaultRetention = 400 * 24 * time.Hour
+	// forecastWindowDays는 증가 추세를 계산할 때 쓰는 최근 기록 일수입니다.
+	forecastWindowDays = 30
+)
+
+// Snapshot은 Space 하나의 하루 사용량 기록입니다. Day는 UTC 자정입니다.
+type Snapshot struct {
+	SpaceID    int64     `json:"-"`
+	Day        time.Time `json:"day"`
+	UsedBytes  int64     `json:"usedBytes"`
+	QuotaBytes *int64    `json:"quotaBytes,omitempty"`
+	RecordedAt time.Time `json:"recordedAt"`
+}
+
+// Storer는 하루 사용량 기록 저장소입니다. 키는 (Space, Day)이고 같은 날 기록은 덮어씁니다.
+type Storer interface {
+	Upsert(ctx context.Context, snapshot *Snapshot) error
+	// List는 since 이후(포함) 기록을 날짜 순으로 반환합니다.
+	List(ctx context.Context, spaceID int64, since time.Time) ([]*Snapshot, error)
+	DeleteBefore(ctx context.Context, before time.Time) error
+}
+
+// UsageReader는 Space 사용량을 계산하는 서비스입니다. space.QuotaService가 구현합니다.
+type UsageReader interface {
+	GetSpaceUsage(ctx context.Context, spaceID int64) (*space.SpaceUsage, error)
+}
+
+// Day는 t가 속한 UTC 날짜의 자정을 반환합니다.
+func Day(t time.Time) time.Time {
+	year, month, day := t.UTC().Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// Forecast는 최근 기록의 증가 추세와 할당량 도달 예상 시점입니다.
+type Forecast struct {
+	// SampleDays는 추세 계산에 쓴 기록 수입니다. 2보다 작으면 추세를 계산하지 않습니다.
+	SampleDays        int     `json:"sampleDays"`
+	GrowthBytesPerDay float64 `json:"growthBytesPerDay"`
+	// QuotaReachedAt은 지금 추세라면 할당량에 닿는 날짜입니다. 할당량이 없거나 줄어드는 추세면 비어 있습니다.
+	QuotaReachedAt *time.Time `json:"quotaReachedAt,omitempty"`
+	DaysUntilQuota *int       `json:"daysUntilQuota,omitempty"`
+}
+
+// ForecastQuota는 최근 30일 기록에 직선을 맞춰 하루 증가량을 구하고, 할당량 도달 날짜를 추정합니다.
+// 이미 할당량을 넘었으면 DaysUntilQuota는 0입니다.
+func ForecastQuota(snapshots []*Snapshot, quotaBytes *int64, now time.Time) Forecast {
+	recent := make([]*Snapshot, 0, len(snapshots))
+	windowStart := Day(now).AddDate(0, 0, -(forecastWindowDays - 1))
+	for _, snapshot := range snapshots {
+		if !snapshot.Day.Before(windowStart) {
+			recent = append(recent, snapshot)
+		}
+	}
+	forecast := Forecast{SampleDays: len(recent)}
+	if len(recent) < 2 {
+		return forecast
+	}
+	sort.Slice(recent, func(i, j int) bool { return recent[i].Day.Before(recent[j].Day) })
+
+	origin := recent[0].Day
+	var sumX, sumY, sumXY, sumXX float64
+	for _, snapshot := range recent {
+		x := snapshot.Day.Sub(origin).Hours() / 24
+		y := float64(snapshot.UsedBytes)
+		sumX += x
+		sumY += y
+		sumXY += x * y
+		sumXX += x * x
+	}
+	n := float64(len(recent))
+	denominator := n*sumXX - sumX*sumX
+	if denominator == 0 {
+		return forecast
+	}
+	forecast.GrowthBytesPerDay = (n*sumXY - sumX*sumY) / denominator
+
+	if quotaBytes == nil {
+		return forecast
+	}
+	latest := recent[len(recent)-1]
+	remaining := float64(*quotaBytes - latest.UsedBytes)
+	if remaining <= 0 {
+		days := 0
+		reachedAt := latest.Day
+		forecast.DaysUntilQuota = &days
+		forecast.QuotaReachedAt = &reachedAt
+		return forecast
+	}
+	if forecast.GrowthBytesPerDay <= 0 {
+		return forecast
+	}
+	days := int(math.Ceil(remaining / forecast.GrowthBytesPerDay))
+	reachedAt := latest.Day.AddDate(0, 0, days)
+	untilNow := int(math.Ceil(reachedAt.Sub(now).Hours() / 24))
+	if untilNow < 0 {
+		untilNow = 0
+	}
+	forecast.QuotaReachedAt = &reachedAt
+	forecast.DaysUntilQuota = &untilNow
+	return forecast
+}
+
+// Recorder는 Space마다 하루 한 번 사용량을 기록하고 오래된 기록을 정리합니다.
+type Recorder struct {
+	spaces    *space.Service
+	reader    UsageReader
+	store     Storer
+	interval  time.Duration
+	retention time.Duration
+	now       func() time.Time
+
+	startOnce sync.Once
+	stopOnce  sync.Once
+	stopCh    chan struct{}
+	doneCh    chan struct{}
+}
+
+func NewRecorder(spaces *space.Service, reader UsageReader, store Storer, interval time.Duration) *Recorder {
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+	return &Recorder{
+		spaces:    spaces,
+		reader:    reader,
+		store:     store,
+		interval:  interval,
+		retention: DefaultRetention,
+		now:       time.Now,
+		stopCh:    make(chan struct{}),
+		doneCh:    make(chan struct{}),
+	}
+}
+
+// Start는 즉시 한 번 기록한 뒤 interval마다 오늘 기록이 없는 Space를 기록합니다.
+func (r *Recorder) Start() {
+	r.startOnce.Do(func() {
+		go r.run()
+	})
+}
+
+// Stop은 기록 루프를 멈추고 종료를 기다립니다. Start 전에 호출해도 안전합니다.
+func (r *Recorder) Stop() {
+	r.stopOnce.Do(func() {
+		close(r.stopCh)
+	})
+	r.startOnce.Do(func() {
+		close(r.doneCh)
+	})
+	<-r.doneCh
+}
+
+// RecordMissing은 오늘 기록이 없는 Space의 사용량을 계산해 저장합니다.
+// offline Space와 사용량 계산에 실패한 Space는 건너뛰고 다음 주기에 다시 시도합니다.
+func (r *Recorder) RecordMissing(ctx context.Context) error {
+	spaces, err := r.spaces.GetAllSpaces(ctx)
+	if err != nil {
+		return err
+	}
+	now := r.now()
+	today := Day(now)
+	for _, item := range spaces {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if r.spaces.EnsureOnline(item.ID) != nil {
+			continue
+		}
+		existing, err := r.store.List(ctx, item.ID, today)
+		if err != nil {
+			return err
+		}
+		if len(existing) > 0 {
+			continue
+		}
+		spaceUsage, err := r.reader.GetSpaceUsage(ctx, item.ID)
+		if err != nil {
+			logging.Event(log.Warn(), logging.ComponentStorage, "warn.space.usage_snapshot_failed").
+				Err(err).
+				Int64("space_id", item.ID).
+				Msg("failed to compute space usage snapshot")
+			continue
+		}
+		if err := r.store.Upsert(ctx, &Snapshot{
+			SpaceID:    item.ID,
+			Day:        today,
+			UsedBytes:  spaceUsage.UsedBytes,
+			QuotaBytes: spaceUsage.QuotaBytes,
+			RecordedAt: now,
+		}); err != nil {
+			return err
+		}
+	}
+	return r.store.DeleteBefore(ctx, today.Add(-r.retention))
+}
+
+func (r *Recorder) run() {
+	defer close(r.doneCh)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-r.stopCh:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+	for {
+		if err := r.RecordMissing(ctx); err != nil && ctx.Err() == nil {
+			logging.Event(log.Warn(), logging.ComponentStorage, "warn.space.usage_snapshot_failed").
+				Err(err).
+				Msg("failed to record space usage snapshots")
+		}
+		select {
+		case <-r.stopCh:
+			return
+		case <-ticker.C:
+		}
+	}
+}
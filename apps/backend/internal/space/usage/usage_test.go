@@ -0,0 +1,103 @@
+package usage_test
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	_ "github.com/ncruces/go-sqlite3/driver"
+	_ "github.com/ncruces/go-sqlite3/embed"
+	"taeu.kr/cohesion/internal/platform/database"
+	"taeu.kr/cohesion/internal/space"
+	spaceStore "taeu.kr/cohesion/internal/space/store"
+	"taeu.kr/cohesion/internal/space/usage"
+	usageStore "taeu.kr/cohesion/internal/space/usage/store"
+)
+
+func TestForecastQuota_ProjectsLinearGrowth(t *testing.T) {
+	now := time.Date(2026, 3, 10, 15, 0, 0, 0, time.UTC)
+	quota := int64(2000)
+	snapshots := []*usage.Snapshot{
+		{Day: usage.Day(now).AddDate(0, 0, -60), UsedBytes: 10},
+		{Day: usage.Day(now).AddDate(0, 0, -2), UsedBytes: 800},
+		{Day: usage.Day(now).AddDate(0, 0, -1), UsedBytes: 900},
+		{Day: usage.Day(now), UsedBytes: 1000},
+	}
+
+	forecast := usage.ForecastQuota(snapshots, &quota, now)
+	if forecast.SampleDays != 3 || forecast.GrowthBytesPerDay != 100 {
+		t.Fatalf("unexpected growth: %+v", forecast)
+	}
+	if forecast.DaysUntilQuota == nil || *forecast.DaysUntilQuota != 10 || !forecast.QuotaReachedAt.Equal(usage.Day(now).AddDate(0, 0, 10)) {
+		t.Fatalf("unexpected quota forecast: %+v", forecast)
+	}
+
+	if forecast := usage.ForecastQuota(snapshots, nil, now); forecast.QuotaReachedAt != nil {
+		t.Fatalf("expected no quota date without quota, got %+v", forecast)
+	}
+	shrinking := []*usage.Snapshot{
+		{Day: usage.Day(now).AddDate(0, 0, -1), UsedBytes: 1000},
+		{Day: usage.Day(now), UsedBytes: 900},
+	}
+	if forecast := usage.ForecastQuota(shrinking, &quota, now); forecast.QuotaReachedAt != nil || forecast.GrowthBytesPerDay >= 0 {
+		t.Fatalf("expected no quota date for shrinking usage, got %+v", forecast)
+	}
+	over := int64(500)
+	if forecast := usage.ForecastQuota(shrinking, &over, now); forecast.DaysUntilQuota == nil || *forecast.DaysUntilQuota != 0 {
+		t.Fatalf("expected quota already reached, got %+v", forecast)
+	}
+}
+
+func TestRecorder_RecordsOncePerDayInSQLStore(t *testing.T) {
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	defer db.Close()
+	if err := database.Migrate(context.Background(), db); err != nil {
+		t.Fatalf("migrate db: %v", err)
+	}
+
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "a.bin"), make([]byte, 128), 0o644); err != nil {
+		t.Fatalf("create file: %v", err)
+	}
+	result, err := db.ExecContext(context.Background(), `INSERT INTO space (space_name, space_path, quota_bytes) VALUES (?, ?, ?)`, "Data", root, 4096)
+	if err != nil {
+		t.Fatalf("insert space: %v", err)
+	}
+	spaceID, _ := result.LastInsertId()
+
+	service := space.NewService(spaceStore.NewStore(db))
+	store := usageStore.NewStore(db)
+	stale := &usage.Snapshot{SpaceID: spaceID, Day: usage.Day(time.Now()).AddDate(-2, 0, 0), UsedBytes: 1, RecordedAt: time.Now()}
+	if err := store.Upsert(context.Background(), stale); err != nil {
+		t.Fatalf("seed stale snapshot: %v", err)
+	}
+
+	recorder := usage.NewRecorder(service, space.NewQuotaService(service), store, time.Hour)
+	if err := recorder.RecordMissing(context.Background()); err != nil {
+		t.Fatalf("record snapshots: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "b.bin"), make([]byte, 64), 0o644); err != nil {
+		t.Fatalf("create file: %v", err)
+	}
+	// 같은 날 다시 실행해도 기존 기록을 유지한다.
+	if err := recorder.RecordMissing(context.Background()); err != nil {
+		t.Fatalf("record snapshots again: %v", err)
+	}
+
+	snapshots, err := store.List(context.Background(), spaceID, time.Time{})
+	if err != nil {
+		t.Fatalf("list snapshots: %v", err)
+	}
+	if len(snapshots) != 1 {
+		t.Fatalf("expected stale snapshot to be pruned and one snapshot recorded, got %d", len(snapshots))
+	}
+	if snapshots[0].UsedBytes != 128 || snapshots[0].QuotaBytes == nil || *snapshots[0].QuotaBytes != 4096 || !snapshots[0].Day.Equal(usage.Day(time.Now())) {
+		t.Fatalf("unexpected snapshot: %+v", snapshots[0])
+	}
+}
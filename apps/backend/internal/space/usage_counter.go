@@ -0,0 +1,155 @@
+package space
+
+import (
+	"context"
+	"errors"
+	"os"
+	"sync"
+	"time"
+)
+
+var ErrUsageCounterNotFound = errors.New("space usage counter not found")
+
+// UsageCounter는 Space의 누적 사용량입니다. ReconciledAt은 마지막 전체 스캔 시각,
+// UpdatedAt은 마지막으로 변화량을 더한 시각입니다.
+type UsageCounter struct {
+	SpaceID   int64
+	UsedBytes int64
+	// UsedFiles는 디렉터리를 뺀 파일 수입니다.
+	UsedFiles    int64
+	ReconciledAt time.Time
+	UpdatedAt    time.Time
+}
+
+type UsageCounterStorer interface {
+	// GetUsageCounter는 카운터가 없으면 ErrUsageCounterNotFound를 반환합니다.
+	GetUsageCounter(ctx context.Context, spaceID int64) (*UsageCounter, error)
+	SaveUsageCounter(ctx context.Context, counter *UsageCounter) error
+	// AddUsage는 카운터가 있을 때만 변화량을 더합니다. 카운터가 없으면 아무 것도 하지 않습니다.
+	AddUsage(ctx context.Context, spaceID int64, deltaBytes int64, deltaFiles int64, at time.Time) error
+	DeleteUsageCounter(ctx context.Context, spaceID int64) error
+}
+
+// UsageTracker는 파일을 바꾼 쪽이 Space 사용량 변화를 알리는 통로입니다.
+// QuotaService가 구현하며, REST 핸들러와 WebDAV/SFTP/FTP 서버가 사용합니다.
+type UsageTracker interface {
+	AdjustUsage(ctx context.Context, spaceID int64, deltaBytes int64, deltaFiles int64)
+	// Invalidate는 변화량을 알 수 없을 때 카운터를 버려 다음 조회 때 다시 세게 합니다.
+	Invalidate(spaceID int64)
+}
+
+// FileQuotaChecker는 파일 수 할당량을 확인할 수 있는 UsageTracker입니다. QuotaService가 구현합니다.
+type FileQuotaChecker interface {
+	EnsureCanAddFiles(ctx context.Context, spaceID int64, deltaFiles int64) error
+}
+
+// PathUsage는 경로 아래 파일 크기의 합과 파일 수입니다.
+type PathUsage struct {
+	Bytes int64
+	Files int64
+}
+
+// TrackUsage는 작업 전에 absPaths의 크기를 재고, 반환한 함수가 작업 후 다시 재어 차이를 tracker에 알립니다.
+// Space 전체가 아니라 바뀌는 경로만 재므로 비용은 작업 크기에 비례합니다.
+// 없는 경로는 0으로 보고, 잴 수 없는 경로가 있으면 카운터를 버립니다. tracker가 nil이면 아무 것도 하지 않습니다.
+func TrackUsage(ctx context.Context, tracker UsageTracker, spaceID int64, absPaths ...string) func() {
+	if tracker == nil || spaceID <= 0 {
+		return func() {}
+	}
+	ctx = context.WithoutCancel(ctx)
+	before, beforeErr := MeasurePathUsage(ctx, absPaths...)
+	return func() {
+		after, afterErr := MeasurePathUsage(ctx, absPaths...)
+		if beforeErr != nil || afterErr != nil {
+			tracker.Invalidate(spaceID)
+			return
+		}
+		tracker.AdjustUsage(ctx, spaceID, after.Bytes-before.Bytes, after.Files-before.Files)
+	}
+}
+
+// EnsureCanCreateFile은 absPath가 아직 없을 때 파일 하나를 더 만들어도 파일 수 할당량을 넘지 않는지 확인합니다.
+// tracker가 FileQuotaChecker가 아니면 확인하지 않습니다.
+func EnsureCanCreateFile(ctx context.Context, tracker UsageTracker, spaceID int64, absPath string) error {
+	checker, ok := tracker.(FileQuotaChecker)
+	if !ok || spaceID <= 0 {
+		return nil
+	}
+	if _, err := os.Lstat(absPath); !os.IsNotExist(err) {
+		return nil
+	}
+	return checker.EnsureCanAddFiles(ctx, spaceID, 1)
+}
+
+// MeasurePaths는 absPaths 크기의 합을 반환합니다. 없는 경로는 0으로 봅니다.
+func MeasurePaths(ctx context.Context, absPaths ...string) (int64, error) {
+	usage, err := MeasurePathUsage(ctx, absPaths...)
+	return usage.Bytes, err
+}
+
+// MeasurePathUsage는 absPaths 크기의 합과 파일 수를 반환합니다. 없는 경로는 0으로 봅니다.
+func MeasurePathUsage(ctx context.Context, absPaths ...string) (PathUsage, error) {
+	var total PathUsage
+	for _, absPath := range absPaths {
+		usage, err := MeasurePath(ctx, absPath)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return PathUsage{}, err
+		}
+		total.Bytes += usage.Bytes
+		total.Files += usage.Files
+	}
+	return total, nil
+}
+
+// MemoryUsageCounterStore는 프로세스 메모리에만 카운터를 두는 UsageCounterStorer입니다.
+type MemoryUsageCounterStore struct {
+	mu       sync.Mutex
+	counters map[int64]UsageCounter
+}
+
+func NewMemoryUsageCounterStore() *MemoryUsageCounterStore {
+	return &MemoryUsageCounterStore{counters: make(map[int64]UsageCounter)}
+}
+
+func (s *MemoryUsageCounterStore) GetUsageCounter(ctx context.Context, spaceID int64) (*UsageCounter, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	counter, ok := s.counters[spaceID]
+	if !ok {
+		return nil, ErrUsageCounterNotFound
+	}
+	return &counter, nil
+}
+
+func (s *MemoryUsageCounterStore) SaveUsageCounter(ctx context.Context, counter *UsageCounter) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.counters[counter.SpaceID] = *counter
+	return nil
+}
+
+func (s *MemoryUsageCounterStore) AddUsage(ctx context.Context, spaceID int64, deltaBytes int64, deltaFiles int64, at time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	counter, ok := s.counters[spaceID]
+	if !ok {
+		return nil
+	}
+	counter.UsedBytes = max(counter.UsedBytes+deltaBytes, 0)
+	counter.UsedFiles = max(counter.UsedFiles+deltaFiles, 0)
+	counter.UpdatedAt = at
+	s.counters[spaceID] = counter
+	return nil
+}
+
+func (s *MemoryUsageCounterStore) DeleteUsageCounter(ctx context.Context, spaceID int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.counters, spaceID)
+	return nil
+}
+
+var _ UsageCounterStorer = (*MemoryUsageCounterStore)(nil)
@@ -0,0 +1,409 @@
+package space
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// GlobalUserQuotaSpaceID는 모든 Space 합계에 거는 사용자 할당량의 SpaceID 값입니다.
+const GlobalUserQuotaSpaceID int64 = 0
+
+// ErrInvalidUserQuota는 사용자 할당량 값이 잘못된 경우입니다.
+var ErrInvalidUserQuota = errors.New("invalid user quota")
+
+// ownerRecordBatchSize는 폴더 아래 파일 소유자를 한 번에 기록하는 개수입니다.
+const ownerRecordBatchSize = 500
+
+// OwnedFile은 소유자 기록 하나입니다. Path는 Space root 기준 슬래시 경로입니다.
+type OwnedFile struct {
+	Path string
+	Size int64
+}
+
+// OwnedUsage는 사용자 한 명이 Space 하나에서 소유한 파일의 합계입니다.
+type OwnedUsage struct {
+	Username  string
+	SpaceID   int64
+	UsedBytes int64
+	FileCount int64
+}
+
+// FileOwnerStorer는 파일별 소유자(마지막으로 쓴 사용자) 기록 저장소입니다.
+// 경로 인자는 파일 또는 폴더이며, 폴더면 그 아래 기록 전체가 대상입니다.
+type FileOwnerStorer interface {
+	// SetFileOwners는 files를 username 소유로 기록합니다. 이미 있는 기록은 소유자와 크기를 덮어씁니다.
+	SetFileOwners(ctx context.Context, spaceID int64, username string, files []OwnedFile, at time.Time) error
+	// MoveFileOwners는 fromPath 아래 기록을 toPath 아래로 옮깁니다. toPath 아래에 있던 기록은 지웁니다.
+	MoveFileOwners(ctx context.Context, fromSpaceID int64, fromPath string, toSpaceID int64, toPath string) error
+	DeleteFileOwners(ctx context.Context, spaceID int64, path string) error
+	// SumOwnedBytes는 사용자·Space별 합계를 반환합니다. username이 비어 있으면 모든 사용자입니다.
+	SumOwnedBytes(ctx context.Context, username string) ([]OwnedUsage, error)
+}
+
+// UserQuota는 사용자 할당량 하나입니다. SpaceID가 GlobalUserQuotaSpaceID면 모든 Space 합계에 겁니다.
+type UserQuota struct {
+	Username   string `json:"-"`
+	SpaceID    int64  `json:"spaceId"`
+	QuotaBytes int64  `json:"quotaBytes"`
+}
+
+type UserQuotaStorer interface {
+	// ListUserQuotas는 username의 할당량을 반환합니다. username이 비어 있으면 모든 사용자입니다.
+	ListUserQuotas(ctx context.Context, username string) ([]*UserQuota, error)
+	// ReplaceUserQuotas는 username의 할당량 전체를 quotas로 바꿉니다.
+	ReplaceUserQuotas(ctx context.Context, username string, quotas []*UserQuota) error
+}
+
+// UserQuotaExceededError는 사용자 할당량을 넘는 쓰기입니다. SpaceID가 0이면 전체 할당량입니다.
+type UserQuotaExceededError struct {
+	Username   string
+	SpaceID    int64
+	UsedBytes  int64
+	QuotaBytes int64
+	DeltaBytes int64
+}
+
+func (e *UserQuotaExceededError) Error() string {
+	return fmt.Sprintf("user quota exceeded (user=%s, spaceId=%d, used=%d, quota=%d, delta=%d)", e.Username, e.SpaceID, e.UsedBytes, e.QuotaBytes, e.DeltaBytes)
+}
+
+// UserSpaceUsage는 사용자 한 명의 Space별 사용량입니다.
+type UserSpaceUsage struct {
+	SpaceID    int64  `json:"spaceId"`
+	UsedBytes  int64  `json:"usedBytes"`
+	FileCount  int64  `json:"fileCount"`
+	QuotaBytes *int64 `json:"quotaBytes,omitempty"`
+	OverQuota  bool   `json:"overQuota"`
+}
+
+// UserStorageUsage는 사용자 한 명의 전체 사용량과 할당량입니다.
+type UserStorageUsage struct {
+	Username   string           `json:"username"`
+	UsedBytes  int64            `json:"usedBytes"`
+	FileCount  int64            `json:"fileCount"`
+	QuotaBytes *int64           `json:"quotaBytes,omitempty"`
+	OverQuota  bool             `json:"overQuota"`
+	Spaces     []UserSpaceUsage `json:"spaces"`
+}
+
+// UserStorageTracker는 WebDAV/SFTP/FTP 서버가 할당량을 확인하고 파일 소유자를 기록할 때 쓰는 인터페이스입니다.
+// UserQuotaService가 구현합니다.
+type UserStorageTracker interface {
+	// WriteAllowance는 username이 spaceID에 더 쓸 수 있는 바이트 수입니다. 제한이 없으면 -1입니다.
+	WriteAllowance(ctx context.Context, username string, spaceID int64) (int64, error)
+	RecordOwner(ctx context.Context, username string, spaceID int64, spaceRoot, absPath string) error
+	MoveOwners(ctx context.Context, fromSpaceID int64, fromRoot, fromAbs string, toSpaceID int64, toRoot, toAbs string) error
+	DeleteOwners(ctx context.Context, spaceID int64, spaceRoot, absPath string) error
+}
+
+// UserQuotaService는 파일 소유자 기록으로 사용자별 사용량을 세고 사용자 할당량을 판단합니다.
+// 소유자는 파일을 마지막으로 쓴(업로드, 복사, 압축, 프로토콜 쓰기) 사용자이며, 이동해도 바뀌지 않습니다.
+// 서버 밖에서 만든 파일처럼 기록이 없는 파일은 누구의 사용량에도 들어가지 않습니다.
+type UserQuotaService struct {
+	owners FileOwnerStorer
+	quotas UserQuotaStorer
+	// spaceQuota가 있으면 WriteAllowance가 Space 할당량도 함께 봅니다.
+	spaceQuota *QuotaService
+}
+
+func NewUserQuotaService(spaceQuota *QuotaService) *UserQuotaService {
+	return &UserQuotaService{
+		owners:     NewMemoryFileOwnerStore(),
+		quotas:     NewMemoryUserQuotaStore(),
+		spaceQuota: spaceQuota,
+	}
+}
+
+// SetFileOwnerStore는 파일 소유자 저장소를 교체합니다. 기본값은 메모리 저장소입니다.
+func (s *UserQuotaService) SetFileOwnerStore(store FileOwnerStorer) {
+	s.owners = store
+}
+
+// SetUserQuotaStore는 사용자 할당량 저장소를 교체합니다. 기본값은 메모리 저장소입니다.
+func (s *UserQuotaService) SetUserQuotaStore(store UserQuotaStorer) {
+	s.quotas = store
+}
+
+// GetUserUsage는 사용자 한 명의 사용량을 반환합니다. 할당량이 걸린 Space는 사용량이 없어도 포함합니다.
+func (s *UserQuotaService) GetUserUsage(ctx context.Context, username string) (*UserStorageUsage, error) {
+	owned, err := s.owners.SumOwnedBytes(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+	quotas, err := s.quotas.ListUserQuotas(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+	return buildUserStorageUsage(username, owned, quotas), nil
+}
+
+// ListUserUsage는 usernames 각각의 사용량을 반환합니다. 기록이나 할당량만 있는 사용자도 뒤에 덧붙입니다.
+func (s *UserQuotaService) ListUserUsage(ctx context.Context, usernames []string) ([]*UserStorageUsage, error) {
+	owned, err := s.owners.SumOwnedBytes(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+	quotas, err := s.quotas.ListUserQuotas(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+
+	ownedByUser := make(map[string][]OwnedUsage)
+	for _, item := range owned {
+		ownedByUser[item.Username] = append(ownedByUser[item.Username], item)
+	}
+	quotasByUser := make(map[string][]*UserQuota)
+	for _, quota := range quotas {
+		quotasByUser[quota.Username] = append(quotasByUser[quota.Username], quota)
+	}
+
+	seen := make(map[string]struct{}, len(usernames))
+	result := make([]*UserStorageUsage, 0, len(usernames))
+	for _, username := range usernames {
+		if _, ok := seen[username]; ok {
+			continue
+		}
+		seen[username] = struct{}{}
+		result = append(result, buildUserStorageUsage(username, ownedByUser[username], quotasByUser[username]))
+	}
+	extra := make([]string, 0)
+	for username := range ownedByUser {
+		if _, ok := seen[username]; !ok {
+			seen[username] = struct{}{}
+			extra = append(extra, username)
+		}
+	}
+	for username := range quotasByUser {
+		if _, ok := seen[username]; !ok {
+			seen[username] = struct{}{}
+			extra = append(extra, username)
+		}
+	}
+	sort.Strings(extra)
+	for _, username := range extra {
+		result = append(result, buildUserStorageUsage(username, ownedByUser[username], quotasByUser[username]))
+	}
+	return result, nil
+}
+
+// ReplaceUserQuotas는 사용자의 할당량 전체를 바꿉니다. SpaceID가 0인 항목이 전체 할당량입니다.
+func (s *UserQuotaService) ReplaceUserQuotas(ctx context.Context, username string, quotas []*UserQuota) error {
+	username = strings.TrimSpace(username)
+	if username == "" {
+		return fmt.Errorf("%w: username is required", ErrInvalidUserQuota)
+	}
+	seen := make(map[int64]struct{}, len(quotas))
+	for _, quota := range quotas {
+		if quota.SpaceID < 0 || quota.QuotaBytes < 0 {
+			return fmt.Errorf("%w: spaceId and quotaBytes must be non-negative", ErrInvalidUserQuota)
+		}
+		if _, ok := seen[quota.SpaceID]; ok {
+			return fmt.Errorf("%w: duplicate spaceId %d", ErrInvalidUserQuota, quota.SpaceID)
+		}
+		seen[quota.SpaceID] = struct{}{}
+		quota.Username = username
+	}
+	return s.quotas.ReplaceUserQuotas(ctx, username, quotas)
+}
+
+// EnsureCanWrite는 username이 spaceID에 deltaBytes를 더 써도 전체·Space별 사용자 할당량을 넘지 않는지 확인합니다.
+func (s *UserQuotaService) EnsureCanWrite(ctx context.Context, username string, spaceID int64, deltaBytes int64) error {
+	if username == "" || deltaBytes <= 0 {
+		return nil
+	}
+	usage, err := s.GetUserUsage(ctx, username)
+	if err != nil {
+		return err
+	}
+	if usage.QuotaBytes != nil && usage.UsedBytes+deltaBytes > *usage.QuotaBytes {
+		return &UserQuotaExceededError{Username: username, SpaceID: GlobalUserQuotaSpaceID, UsedBytes: usage.UsedBytes, QuotaBytes: *usage.QuotaBytes, DeltaBytes: deltaBytes}
+	}
+	for _, item := range usage.Spaces {
+		if item.SpaceID == spaceID && item.QuotaBytes != nil && item.UsedBytes+deltaBytes > *item.QuotaBytes {
+			return &UserQuotaExceededError{Username: username, SpaceID: spaceID, UsedBytes: item.UsedBytes, QuotaBytes: *item.QuotaBytes, DeltaBytes: deltaBytes}
+		}
+	}
+	return nil
+}
+
+// WriteAllowance는 username이 spaceID에 더 쓸 수 있는 바이트 수를 Space 할당량과 사용자 할당량 중 작은 쪽으로 반환합니다.
+// 어느 할당량도 없으면 -1입니다.
+func (s *UserQuotaService) WriteAllowance(ctx context.Context, username string, spaceID int64) (int64, error) {
+	allowance := int64(-1)
+	limit := func(remaining int64) {
+		remaining = max(remaining, 0)
+		if allowance < 0 || remaining < allowance {
+			allowance = remaining
+		}
+	}
+
+	if s.spaceQuota != nil {
+		remaining, err := s.spaceQuota.WriteAllowance(ctx, spaceID)
+		if err != nil {
+			return 0, err
+		}
+		if remaining >= 0 {
+			limit(remaining)
+		}
+	}
+	if username == "" {
+		return allowance, nil
+	}
+	usage, err := s.GetUserUsage(ctx, username)
+	if err != nil {
+		return 0, err
+	}
+	if usage.QuotaBytes != nil {
+		limit(*usage.QuotaBytes - usage.UsedBytes)
+	}
+	for _, item := range usage.Spaces {
+		if item.SpaceID == spaceID && item.QuotaBytes != nil {
+			limit(*item.QuotaBytes - item.UsedBytes)
+		}
+	}
+	return allowance, nil
+}
+
+// RecordOwner는 absPath(폴더면 그 아래 모든 파일)를 username 소유로 기록합니다.
+func (s *UserQuotaService) RecordOwner(ctx context.Context, username string, spaceID int64, spaceRoot, absPath string) error {
+	if username == "" || spaceID <= 0 {
+		return nil
+	}
+	now := time.Now()
+	batch := make([]OwnedFile, 0, ownerRecordBatchSize)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		err := s.owners.SetFileOwners(ctx, spaceID, username, batch, now)
+		batch = batch[:0]
+		return err
+	}
+
+	err := filepath.WalkDir(absPath, func(currentPath string, entry fs.DirEntry, walkErr error) error {
+		if walkErr != nil {
+			return walkErr
+		}
+		if entry.IsDir() {
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		relPath, err := ownerRelativePath(spaceRoot, currentPath)
+		if err != nil {
+			return err
+		}
+		batch = append(batch, OwnedFile{Path: relPath, Size: info.Size()})
+		if len(batch) >= ownerRecordBatchSize {
+			return flush()
+		}
+		return nil
+	})
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	return flush()
+}
+
+// MoveOwners는 이동한 항목의 소유자 기록을 새 위치로 옮깁니다. 대상에 있던 기록은 지웁니다.
+func (s *UserQuotaService) MoveOwners(ctx context.Context, fromSpaceID int64, fromRoot, fromAbs string, toSpaceID int64, toRoot, toAbs string) error {
+	fromPath, err := ownerRelativePath(fromRoot, fromAbs)
+	if err != nil {
+		return err
+	}
+	toPath, err := ownerRelativePath(toRoot, toAbs)
+	if err != nil {
+		return err
+	}
+	if fromPath == "" || toPath == "" {
+		return errors.New("cannot move space root")
+	}
+	if fromSpaceID == toSpaceID && fromPath == toPath {
+		return nil
+	}
+	return s.owners.MoveFileOwners(ctx, fromSpaceID, fromPath, toSpaceID, toPath)
+}
+
+// DeleteOwners는 지운 항목의 소유자 기록을 지웁니다.
+func (s *UserQuotaService) DeleteOwners(ctx context.Context, spaceID int64, spaceRoot, absPath string) error {
+	relPath, err := ownerRelativePath(spaceRoot, absPath)
+	if err != nil {
+		return err
+	}
+	return s.owners.DeleteFileOwners(ctx, spaceID, relPath)
+}
+
+// ownerRelativePath는 소유자 기록에 쓰는 Space root 기준 슬래시 경로를 만듭니다. root 자신은 빈 문자열입니다.
+func ownerRelativePath(spaceRoot, absPath string) (string, error) {
+	relPath, err := filepath.Rel(spaceRoot, absPath)
+	if err != nil {
+		return "", err
+	}
+	relPath = filepath.ToSlash(relPath)
+	if relPath == ".." || strings.HasPrefix(relPath, "../") {
+		return "", errors.New("path is outside of space")
+	}
+	if relPath == "." {
+		return "", nil
+	}
+	return relPath, nil
+}
+
+// OwnerPathMatches는 path가 prefix 자신이거나 그 아래 경로인지 확인합니다. prefix가 비어 있으면 모든 경로입니다.
+func OwnerPathMatches(path, prefix string) bool {
+	return prefix == "" || path == prefix || strings.HasPrefix(path, prefix+"/")
+}
+
+func buildUserStorageUsage(username string, owned []OwnedUsage, quotas []*UserQuota) *UserStorageUsage {
+	usage := &UserStorageUsage{Username: username, Spaces: []UserSpaceUsage{}}
+	bySpace := make(map[int64]*UserSpaceUsage)
+	spaceEntry := func(spaceID int64) *UserSpaceUsage {
+		item, ok := bySpace[spaceID]
+		if !ok {
+			item = &UserSpaceUsage{SpaceID: spaceID}
+			bySpace[spaceID] = item
+		}
+		return item
+	}
+	for _, item := range owned {
+		entry := spaceEntry(item.SpaceID)
+		entry.UsedBytes += item.UsedBytes
+		entry.FileCount += item.FileCount
+		usage.UsedBytes += item.UsedBytes
+		usage.FileCount += item.FileCount
+	}
+	for _, quota := range quotas {
+		quotaBytes := quota.QuotaBytes
+		if quota.SpaceID == GlobalUserQuotaSpaceID {
+			usage.QuotaBytes = &quotaBytes
+			continue
+		}
+		spaceEntry(quota.SpaceID).QuotaBytes = &quotaBytes
+	}
+
+	if usage.QuotaBytes != nil && usage.UsedBytes > *usage.QuotaBytes {
+		usage.OverQuota = true
+	}
+	for _, item := range bySpace {
+		if item.QuotaBytes != nil && item.UsedBytes > *item.QuotaBytes {
+			item.OverQuota = true
+		}
+		usage.Spaces = append(usage.Spaces, *item)
+	}
+	sort.Slice(usage.Spaces, func(i, j int) bool { return usage.Spaces[i].SpaceID < usage.Spaces[j].SpaceID })
+	return usage
+}
+
+var _ UserStorageTracker = (*UserQuotaService)(nil)
@@ -0,0 +1,157 @@
+package space
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+type fileOwnerKey struct {
+	spaceID int64
+	path    string
+}
+
+type fileOwnerRecord struct {
+	username string
+	size     int64
+}
+
+// MemoryFileOwnerStore는 프로세스 메모리에만 소유자 기록을 두는 FileOwnerStorer입니다.
+type MemoryFileOwnerStore struct {
+	mu      sync.Mutex
+	records map[fileOwnerKey]fileOwnerRecord
+}
+
+func NewMemoryFileOwnerStore() *MemoryFileOwnerStore {
+	return &MemoryFileOwnerStore{records: make(map[fileOwnerKey]fileOwnerRecord)}
+}
+
+func (s *MemoryFileOwnerStore) SetFileOwners(ctx context.Context, spaceID int64, username string, files []OwnedFile, at time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, file := range files {
+		s.records[fileOwnerKey{spaceID: spaceID, path: file.Path}] = fileOwnerRecord{username: username, size: file.Size}
+	}
+	return nil
+}
+
+func (s *MemoryFileOwnerStore) MoveFileOwners(ctx context.Context, fromSpaceID int64, fromPath string, toSpaceID int64, toPath string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for key := range s.records {
+		if key.spaceID == toSpaceID && OwnerPathMatches(key.path, toPath) {
+			delete(s.records, key)
+		}
+	}
+	moved := make(map[fileOwnerKey]fileOwnerRecord)
+	for key, record := range s.records {
+		if key.spaceID != fromSpaceID || !OwnerPathMatches(key.path, fromPath) {
+			continue
+		}
+		delete(s.records, key)
+		moved[fileOwnerKey{spaceID: toSpaceID, path: toPath + strings.TrimPrefix(key.path, fromPath)}] = record
+	}
+	for key, record := range moved {
+		s.records[key] = record
+	}
+	return nil
+}
+
+func (s *MemoryFileOwnerStore) DeleteFileOwners(ctx context.Context, spaceID int64, path string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for key := range s.records {
+		if key.spaceID == spaceID && OwnerPathMatches(key.path, path) {
+			delete(s.records, key)
+		}
+	}
+	return nil
+}
+
+func (s *MemoryFileOwnerStore) SumOwnedBytes(ctx context.Context, username string) ([]OwnedUsage, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	type sumKey struct {
+		username string
+		spaceID  int64
+	}
+	sums := make(map[sumKey]*OwnedUsage)
+	for key, record := range s.records {
+		if username != "" && record.username != username {
+			continue
+		}
+		k := sumKey{username: record.username, spaceID: key.spaceID}
+		item, ok := sums[k]
+		if !ok {
+			item = &OwnedUsage{Username: record.username, SpaceID: key.spaceID}
+			sums[k] = item
+		}
+		item.UsedBytes += record.size
+		item.FileCount++
+	}
+	result := make([]OwnedUsage, 0, len(sums))
+	for _, item := range sums {
+		result = append(result, *item)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Username != result[j].Username {
+			return result[i].Username < result[j].Username
+		}
+		return result[i].SpaceID < result[j].SpaceID
+	})
+	return result, nil
+}
+
+// MemoryUserQuotaStore는 프로세스 메모리에만 사용자 할당량을 두는 UserQuotaStorer입니다.
+type MemoryUserQuotaStore struct {
+	mu     sync.Mutex
+	quotas map[string][]UserQuota
+}
+
+func NewMemoryUserQuotaStore() *MemoryUserQuotaStore {
+	return &MemoryUserQuotaStore{quotas: make(map[string][]UserQuota)}
+}
+
+func (s *MemoryUserQuotaStore) ListUserQuotas(ctx context.Context, username string) ([]*UserQuota, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	result := make([]*UserQuota, 0)
+	for owner, quotas := range s.quotas {
+		if username != "" && owner != username {
+			continue
+		}
+		for _, quota := range quotas {
+			copied := quota
+			result = append(result, &copied)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Username != result[j].Username {
+			return result[i].Username < result[j].Username
+		}
+		return result[i].SpaceID < result[j].SpaceID
+	})
+	return result, nil
+}
+
+func (s *MemoryUserQuotaStore) ReplaceUserQuotas(ctx context.Context, username string, quotas []*UserQuota) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(quotas) == 0 {
+		delete(s.quotas, username)
+		return nil
+	}
+	stored := make([]UserQuota, 0, len(quotas))
+	for _, quota := range quotas {
+		stored = append(stored, UserQuota{Username: username, SpaceID: quota.SpaceID, QuotaBytes: quota.QuotaBytes})
+	}
+	s.quotas[username] = stored
+	return nil
+}
+
+var (
+	_ FileOwnerStorer = (*MemoryFileOwnerStore)(nil)
+	_ UserQuotaStorer = (*MemoryUserQuotaStore)(nil)
+)
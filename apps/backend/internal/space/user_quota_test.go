@@ -0,0 +1,86 @@
+package space_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"taeu.kr/cohesion/internal/space"
+	spaceStore "taeu.kr/cohesion/internal/space/store"
+)
+
+func TestUserQuotaService_TracksOwnershipAcrossMovesAndEnforcesQuotas(t *testing.T) {
+	service, db := setupSlugSpaceService(t)
+	ctx := context.Background()
+	if _, err := db.ExecContext(ctx, `INSERT INTO users(username, password_hash, nickname) VALUES ('alice', 'x', 'Alice')`); err != nil {
+		t.Fatalf("insert user: %v", err)
+	}
+
+	root := t.TempDir()
+	created, err := service.CreateSpace(ctx, &space.CreateSpaceRequest{SpaceName: "Owned", SpacePath: root})
+	if err != nil {
+		t.Fatalf("create space: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Join(root, "docs"), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	for name, size := range map[string]int{"docs/a.bin": 60, "docs/b.bin": 40} {
+		if err := os.WriteFile(filepath.Join(root, filepath.FromSlash(name)), make([]byte, size), 0o644); err != nil {
+			t.Fatalf("write file: %v", err)
+		}
+	}
+
+	quotas := space.NewUserQuotaService(nil)
+	quotas.SetFileOwnerStore(spaceStore.NewFileOwnerStore(db))
+	quotas.SetUserQuotaStore(spaceStore.NewUserQuotaStore(db))
+
+	if err := quotas.RecordOwner(ctx, "alice", created.ID, root, filepath.Join(root, "docs")); err != nil {
+		t.Fatalf("record owner: %v", err)
+	}
+	usage, err := quotas.GetUserUsage(ctx, "alice")
+	if err != nil {
+		t.Fatalf("get usage: %v", err)
+	}
+	if usage.UsedBytes != 100 || usage.FileCount != 2 {
+		t.Fatalf("expected 100 bytes in 2 files, got %+v", usage)
+	}
+
+	// 폴더를 옮기면 아래 기록도 함께 옮겨지고 소유자는 그대로다.
+	if err := os.Rename(filepath.Join(root, "docs"), filepath.Join(root, "archive")); err != nil {
+		t.Fatalf("rename: %v", err)
+	}
+	if err := quotas.MoveOwners(ctx, created.ID, root, filepath.Join(root, "docs"), created.ID, root, filepath.Join(root, "archive")); err != nil {
+		t.Fatalf("move owners: %v", err)
+	}
+	if err := quotas.DeleteOwners(ctx, created.ID, root, filepath.Join(root, "archive", "b.bin")); err != nil {
+		t.Fatalf("delete owners: %v", err)
+	}
+	if usage, _ = quotas.GetUserUsage(ctx, "alice"); usage.UsedBytes != 60 || usage.FileCount != 1 {
+		t.Fatalf("expected 60 bytes in 1 file after move and delete, got %+v", usage)
+	}
+
+	if err := quotas.ReplaceUserQuotas(ctx, "alice", []*space.UserQuota{
+		{SpaceID: space.GlobalUserQuotaSpaceID, QuotaBytes: 200},
+		{SpaceID: created.ID, QuotaBytes: 100},
+	}); err != nil {
+		t.Fatalf("replace quotas: %v", err)
+	}
+	if err := quotas.EnsureCanWrite(ctx, "alice", created.ID, 40); err != nil {
+		t.Fatalf("expected 40 bytes to fit, got %v", err)
+	}
+	var quotaErr *space.UserQuotaExceededError
+	if err := quotas.EnsureCanWrite(ctx, "alice", created.ID, 41); !errors.As(err, &quotaErr) || quotaErr.SpaceID != created.ID {
+		t.Fatalf("expected per-space quota error, got %v", err)
+	}
+	if err := quotas.EnsureCanWrite(ctx, "alice", created.ID+1, 141); !errors.As(err, &quotaErr) || quotaErr.SpaceID != space.GlobalUserQuotaSpaceID {
+		t.Fatalf("expected global quota error, got %v", err)
+	}
+	if allowance, err := quotas.WriteAllowance(ctx, "alice", created.ID); err != nil || allowance != 40 {
+		t.Fatalf("expected allowance 40, got %d (%v)", allowance, err)
+	}
+	if allowance, err := quotas.WriteAllowance(ctx, "bob", created.ID); err != nil || allowance != -1 {
+		t.Fatalf("expected unlimited allowance for user without quota, got %d (%v)", allowance, err)
+	}
+}
@@ -0,0 +1,128 @@
+package space
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// writeDrainTimeout은 FreezeWrites를 건 작업이 진행 중인 쓰기를 기다리는 최대 시간입니다.
+// 클라이언트가 파일을 연 채로 두면 끝나지 않으므로, 넘기면 작업을 실패로 돌리고 동결을 풉니다.
+const writeDrainTimeout = 5 * time.Minute
+
+// ErrSpaceReadOnly는 Space에 대한 쓰기가 일시적으로 또는 정책상 막혀 있음을 나타냅니다.
+var ErrSpaceReadOnly = errors.New("space is read-only")
+
+// FreezeWrites는 Space 쓰기를 일시적으로 막고, 이미 시작한 쓰기(BeginWrite)가 모두 끝날 때까지 기다린 뒤 해제 함수를 반환합니다.
+// 같은 Space에 여러 번 걸 수 있으며 모든 해제 함수가 호출되어야 풀립니다.
+// 기다리는 동안 ctx가 끝나면 동결을 풀고 ctx 오류를 반환합니다.
+func (s *Service) FreezeWrites(ctx context.Context, spaceID int64) (func(), error) {
+	s.writeFreezeMu.Lock()
+	s.writeFreezes[spaceID]++
+	s.writeFreezeMu.Unlock()
+
+	released := false
+	release := func() {
+		s.writeFreezeMu.Lock()
+		defer s.writeFreezeMu.Unlock()
+		if released {
+			return
+		}
+		released = true
+		s.writeFreezes[spaceID]--
+		if s.writeFreezes[spaceID] <= 0 {
+			delete(s.writeFreezes, spaceID)
+		}
+	}
+
+	for {
+		s.writeFreezeMu.Lock()
+		if s.writesInflight[spaceID] == 0 {
+			s.writeFreezeMu.Unlock()
+			return release, nil
+		}
+		idle, ok := s.writeIdle[spaceID]
+		if !ok {
+			idle = make(chan struct{})
+			s.writeIdle[spaceID] = idle
+		}
+		s.writeFreezeMu.Unlock()
+
+		select {
+		case <-idle:
+		case <-ctx.Done():
+			release()
+			return nil, fmt.Errorf("waiting for in-flight writes of space %d: %w", spaceID, ctx.Err())
+		}
+	}
+}
+
+// BeginWrite는 EnsureWritable과 같은 확인을 한 뒤 쓰기를 진행 중으로 기록합니다.
+// 쓰기가 끝나면 반환한 함수를 꼭 호출해야 하며, 그 전까지 FreezeWrites는 기다립니다.
+// 반환하는 Space는 기록한 뒤에 다시 읽은 것이므로 root 경로는 이 값으로 정해야 합니다.
+// 기록하기 전에 읽은 root는 그 사이 저장소 이전으로 바뀌었을 수 있습니다.
+func (s *Service) BeginWrite(ctx context.Context, spaceID int64) (*Space, func(), error) {
+	s.writeFreezeMu.Lock()
+	if s.writeFreezes[spaceID] > 0 {
+		s.writeFreezeMu.Unlock()
+		return nil, nil, fmt.Errorf("%w: space %d is temporarily frozen", ErrSpaceReadOnly, spaceID)
+	}
+	s.writesInflight[spaceID]++
+	s.writeFreezeMu.Unlock()
+
+	var once sync.Once
+	release := func() {
+		once.Do(func() { s.endWrite(spaceID) })
+	}
+	spaceObj, err := s.checkWritable(ctx, spaceID)
+	if err != nil {
+		release()
+		return nil, nil, err
+	}
+	return spaceObj, release, nil
+}
+
+func (s *Service) endWrite(spaceID int64) {
+	s.writeFreezeMu.Lock()
+	defer s.writeFreezeMu.Unlock()
+	s.writesInflight[spaceID]--
+	if s.writesInflight[spaceID] > 0 {
+		return
+	}
+	delete(s.writesInflight, spaceID)
+	if idle, ok := s.writeIdle[spaceID]; ok {
+		close(idle)
+		delete(s.writeIdle, spaceID)
+	}
+}
+
+// EnsureWritable은 쓰기를 시작하지 않고 지금 쓸 수 있는지만 확인합니다. 실제로 파일을 바꾸는 경로는 BeginWrite를 씁니다.
+// 작업 중 일시 동결, root 점검 결과(offline), 저장된 Space 상태(read_only/maintenance/archived)를 함께 확인합니다.
+func (s *Service) EnsureWritable(ctx context.Context, spaceID int64) error {
+	s.writeFreezeMu.Lock()
+	frozen := s.writeFreezes[spaceID] > 0
+	s.writeFreezeMu.Unlock()
+
+	if frozen {
+		return fmt.Errorf("%w: space %d is temporarily frozen", ErrSpaceReadOnly, spaceID)
+	}
+	_, err := s.checkWritable(ctx, spaceID)
+	return err
+}
+
+func (s *Service) checkWritable(ctx context.Context, spaceID int64) (*Space, error) {
+	if err := s.EnsureOnline(spaceID); err != nil {
+		return nil, err
+	}
+
+	spaceObj, err := s.GetSpaceByID(ctx, spaceID)
+	if err != nil {
+		return nil, err
+	}
+	if err := spaceObj.CheckWritable(); err != nil {
+		return nil, err
+	}
+	return spaceObj, nil
+}
@@ -23,9 +23,21 @@ type ProtocolStatus struct {
 	Path    string `json:"path,omitempty"`
 }
 
+// SpaceRootStatus는 Space root 점검 결과 요약입니다.
+// 권한과 무관하게 노출되므로 이름/경로 대신 Space ID만 담습니다.
+type SpaceRootStatus struct {
+	Total           int     `json:"total"`
+	Online          int     `json:"online"`
+	Offline         int     `json:"offline"`
+	Unchecked       int     `json:"unchecked"`
+	OfflineSpaceIDs []int64 `json:"offlineSpaceIds"`
+	LowFreeSpaceIDs []int64 `json:"lowFreeSpaceIds"`
+}
+
 type StatusResponse struct {
 	Protocols map[string]ProtocolStatus `json:"protocols"`
 	Hosts     []string                  `json:"hosts"`
+	Spaces    SpaceRootStatus           `json:"spaces"`
 }
 
 type Handler struct {
@@ -69,6 +81,7 @@ func (h *Handler) handleStatus(w http.ResponseWriter, r *http.Request) *web.Erro
 	json.NewEncoder(w).Encode(StatusResponse{
 		Protocols: protocols,
 		Hosts:     h.getAccessibleHosts(),
+		Spaces:    h.checkSpaceRoots(),
 	})
 
 	return nil
@@ -124,6 +137,39 @@ func (h *Handler) checkWebDAV() ProtocolStatus {
 	}
 }
 
+func (h *Handler) checkSpaceRoots() SpaceRootStatus {
+	result := SpaceRootStatus{
+		OfflineSpaceIDs: []int64{},
+		LowFreeSpaceIDs: []int64{},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	spaces, err := h.spaceService.GetAllSpaces(ctx)
+	if err != nil {
+		return result
+	}
+
+	for _, sp := range spaces {
+		result.Total++
+		health, ok := h.spaceService.RootHealth(sp.ID)
+		switch {
+		case !ok:
+			result.Unchecked++
+		case health.Online():
+			result.Online++
+		default:
+			result.Offline++
+			result.OfflineSpaceIDs = append(result.OfflineSpaceIDs, sp.ID)
+		}
+		if ok && health.LowFreeSpace {
+			result.LowFreeSpaceIDs = append(result.LowFreeSpaceIDs, sp.ID)
+		}
+	}
+	return result
+}
+
 func (h *Handler) checkFTP() ProtocolStatus {
 	if !config.Conf.Server.FtpEnabled {
 		return ProtocolStatus{
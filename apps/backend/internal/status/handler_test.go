@@ -7,6 +7,7 @@ import (
 	"errors"
 	"net/http"
 	"net/http/httptest"
+	"path/filepath"
 	"testing"
 
 	_ "github.com/ncruces/go-sqlite3/driver"
@@ -110,3 +111,36 @@ func TestHandleStatus_MethodNotAllowed(t *testing.T) {
 		t.Fatalf("expected status %d, got %d", http.StatusMethodNotAllowed, webErr.Code)
 	}
 }
+
+func TestHandleStatus_SummarizesSpaceRootHealth(t *testing.T) {
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	defer db.Close()
+
+	online := &space.Space{ID: 1, SpaceName: "online", SpacePath: t.TempDir()}
+	offline := &space.Space{ID: 2, SpaceName: "offline", SpacePath: filepath.Join(t.TempDir(), "missing")}
+	unchecked := &space.Space{ID: 3, SpaceName: "unchecked", SpacePath: t.TempDir()}
+	spaceService := space.NewService(&statusTestSpaceStore{spaces: []*space.Space{online, offline, unchecked}})
+	spaceService.CheckSpaceRoot(context.Background(), online)
+	spaceService.CheckSpaceRoot(context.Background(), offline)
+	handler := NewHandler(db, spaceService, "3000")
+
+	recorder := httptest.NewRecorder()
+	if webErr := handler.handleStatus(recorder, httptest.NewRequest(http.MethodGet, "/api/status", nil)); webErr != nil {
+		t.Fatalf("handleStatus returned error: %+v", webErr)
+	}
+
+	var resp StatusResponse
+	if err := json.NewDecoder(recorder.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	got := resp.Spaces
+	if got.Total != 3 || got.Online != 1 || got.Offline != 1 || got.Unchecked != 1 {
+		t.Fatalf("unexpected space root summary: %+v", got)
+	}
+	if len(got.OfflineSpaceIDs) != 1 || got.OfflineSpaceIDs[0] != 2 {
+		t.Fatalf("expected offline space 2, got %v", got.OfflineSpaceIDs)
+	}
+}
@@ -0,0 +1,212 @@
+package webdav
+
+import (
+	"context"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+
+	"golang.org/x/net/webdav"
+	"taeu.kr/cohesion/internal/space/archive"
+)
+
+// archiveFS는 Space 디렉토리를 그대로 노출하면서 압축 파일을 읽기 전용 폴더(collection)로 보여 준다.
+// Space 설정(webdav_archive_browsing)이 켜진 경우에만 사용한다.
+// 압축 파일 자신은 폴더로 보이므로 GET으로 내려받을 수 없지만, 덮어쓰기/삭제/이동은 그대로 동작한다.
+// 항목이 너무 많거나 읽을 수 없는 압축 파일은 일반 파일로 보여 준다.
+type archiveFS struct {
+	dir     webdav.Dir
+	root    string
+	indexes *archive.Cache
+}
+
+func newArchiveFS(root string, indexes *archive.Cache) webdav.FileSystem {
+	return &archiveFS{dir: webdav.Dir(root), root: root, indexes: indexes}
+}
+
+type archiveTarget struct {
+	absPath string
+	format  archive.Format
+	index   *archive.Index
+	member  archive.Member
+}
+
+// info는 항목의 FileInfo를 반환한다. 압축 파일 자신은 파일 이름을 가진 폴더로 보인다.
+func (t *archiveTarget) info() os.FileInfo {
+	member := t.member
+	if member.Name == "." {
+		member.Name = filepath.Base(t.absPath)
+	}
+	return member.Info()
+}
+
+func (t *archiveTarget) isArchiveRoot() bool {
+	return t.member.Name == "."
+}
+
+// resolve는 name이 압축 파일이나 그 안을 가리키면 항목을 찾는다. 아니면 ok=false다.
+func (afs *archiveFS) resolve(ctx context.Context, name string) (*archiveTarget, bool, error) {
+	absPath, inner, format, ok := archive.SplitPath(afs.root, name)
+	if !ok {
+		return nil, false, nil
+	}
+	index, err := afs.indexes.Index(ctx, absPath, format)
+	if err != nil {
+		if inner == "." {
+			return nil, false, nil
+		}
+		return nil, true, os.ErrNotExist
+	}
+	member, found := index.Lookup(inner)
+	if !found {
+		return nil, true, os.ErrNotExist
+	}
+	return &archiveTarget{absPath: absPath, format: format, index: index, member: member}, true, nil
+}
+
+func parentName(name string) string {
+	return path.Dir(path.Clean("/" + name))
+}
+
+func isWriteFlag(flag int) bool {
+	return flag&(os.O_WRONLY|os.O_RDWR|os.O_CREATE|os.O_TRUNC|os.O_APPEND) != 0
+}
+
+func (afs *archiveFS) Mkdir(ctx context.Context, name string, perm os.FileMode) error {
+	if _, ok, _ := afs.resolve(ctx, parentName(name)); ok {
+		return os.ErrPermission
+	}
+	return afs.dir.Mkdir(ctx, name, perm)
+}
+
+func (afs *archiveFS) OpenFile(ctx context.Context, name string, flag int, perm os.FileMode) (webdav.File, error) {
+	target, ok, err := afs.resolve(ctx, name)
+	if !ok {
+		if isWriteFlag(flag) {
+			// 압축 파일 안에 새 파일을 만들려는 경우
+			if _, inArchive, _ := afs.resolve(ctx, parentName(name)); inArchive {
+				return nil, os.ErrPermission
+			}
+		}
+		return afs.dir.OpenFile(ctx, name, flag, perm)
+	}
+	if isWriteFlag(flag) {
+		if target != nil && target.isArchiveRoot() {
+			return afs.dir.OpenFile(ctx, name, flag, perm)
+		}
+		return nil, os.ErrPermission
+	}
+	if err != nil {
+		return nil, err
+	}
+	if target.member.IsDir {
+		return &archiveDirFile{target: target}, nil
+	}
+	return &archiveMemberFile{
+		MemberFile: archive.NewMemberFile(target.absPath, target.format, target.member),
+		target:     target,
+	}, nil
+}
+
+func (afs *archiveFS) RemoveAll(ctx context.Context, name string) error {
+	if target, ok, _ := afs.resolve(ctx, name); ok && (target == nil || !target.isArchiveRoot()) {
+		return os.ErrPermission
+	}
+	return afs.dir.RemoveAll(ctx, name)
+}
+
+func (afs *archiveFS) Rename(ctx context.Context, oldName, newName string) error {
+	if target, ok, _ := afs.resolve(ctx, oldName); ok && (target == nil || !target.isArchiveRoot()) {
+		return os.ErrPermission
+	}
+	if _, ok, _ := afs.resolve(ctx, parentName(newName)); ok {
+		return os.ErrPermission
+	}
+	return afs.dir.Rename(ctx, oldName, newName)
+}
+
+func (afs *archiveFS) Stat(ctx context.Context, name string) (os.FileInfo, error) {
+	target, ok, err := afs.resolve(ctx, name)
+	if !ok {
+		return afs.dir.Stat(ctx, name)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return target.info(), nil
+}
+
+// --- 압축 파일 안의 폴더 ---
+
+type archiveDirFile struct {
+	target  *archiveTarget
+	entries []os.FileInfo
+	pos     int
+}
+
+func (d *archiveDirFile) Read([]byte) (int, error) {
+	return 0, fs.ErrInvalid
+}
+
+func (d *archiveDirFile) Write([]byte) (int, error) {
+	return 0, os.ErrPermission
+}
+
+func (d *archiveDirFile) Seek(int64, int) (int64, error) {
+	return 0, nil
+}
+
+func (d *archiveDirFile) Close() error {
+	return nil
+}
+
+func (d *archiveDirFile) Stat() (fs.FileInfo, error) {
+	return d.target.info(), nil
+}
+
+func (d *archiveDirFile) Readdir(count int) ([]fs.FileInfo, error) {
+	if d.entries == nil {
+		members, err := d.target.index.List(d.target.member.Name)
+		if err != nil {
+			return nil, err
+		}
+		d.entries = make([]os.FileInfo, 0, len(members))
+		for _, member := range members {
+			d.entries = append(d.entries, member.Info())
+		}
+	}
+
+	if count <= 0 {
+		entries := d.entries[d.pos:]
+		d.pos = len(d.entries)
+		return entries, nil
+	}
+	if d.pos >= len(d.entries) {
+		return nil, io.EOF
+	}
+	end := min(d.pos+count, len(d.entries))
+	entries := d.entries[d.pos:end]
+	d.pos = end
+	return entries, nil
+}
+
+// --- 압축 파일 안의 파일 ---
+
+type archiveMemberFile struct {
+	*archive.MemberFile
+	target *archiveTarget
+}
+
+func (f *archiveMemberFile) Write([]byte) (int, error) {
+	return 0, os.ErrPermission
+}
+
+func (f *archiveMemberFile) Readdir(int) ([]fs.FileInfo, error) {
+	return nil, fs.ErrInvalid
+}
+
+func (f *archiveMemberFile) Stat() (fs.FileInfo, error) {
+	return f.target.info(), nil
+}
@@ -0,0 +1,94 @@
+package webdav
+
+import (
+	"archive/zip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/net/webdav"
+	"taeu.kr/cohesion/internal/space/archive"
+)
+
+func newArchiveFSTestServer(t *testing.T) (http.Handler, string) {
+	t.Helper()
+
+	root := t.TempDir()
+	file, err := os.Create(filepath.Join(root, "v1.zip"))
+	if err != nil {
+		t.Fatalf("failed to create zip: %v", err)
+	}
+	writer := zip.NewWriter(file)
+	for name, content := range map[string]string{"docs/readme.txt": "readme", "notes.txt": "notes"} {
+		entry, err := writer.Create(name)
+		if err != nil {
+			t.Fatalf("failed to add %s: %v", name, err)
+		}
+		io.WriteString(entry, content) //nolint:errcheck
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("failed to close zip: %v", err)
+	}
+	file.Close()
+
+	return &webdav.Handler{
+		FileSystem: newArchiveFS(root, archive.NewCache(4, archive.DefaultMaxEntries)),
+		LockSystem: webdav.NewMemLS(),
+	}, root
+}
+
+func TestArchiveFS_PropfindShowsArchiveAsCollection(t *testing.T) {
+	handler, _ := newArchiveFSTestServer(t)
+
+	req := httptest.NewRequest("PROPFIND", "/v1.zip/", nil)
+	req.Header.Set("Depth", "1")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusMultiStatus {
+		t.Fatalf("expected 207, got %d: %s", rec.Code, rec.Body.String())
+	}
+	body := rec.Body.String()
+	for _, expected := range []string{"/v1.zip/docs/", "/v1.zip/notes.txt", "<D:collection"} {
+		if !strings.Contains(body, expected) {
+			t.Fatalf("expected %q in PROPFIND response: %s", expected, body)
+		}
+	}
+}
+
+func TestArchiveFS_ServesEntriesReadOnly(t *testing.T) {
+	handler, root := newArchiveFSTestServer(t)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1.zip/docs/readme.txt", nil))
+	if rec.Code != http.StatusOK || rec.Body.String() != "readme" {
+		t.Fatalf("unexpected GET response %d %q", rec.Code, rec.Body.String())
+	}
+
+	for _, req := range []*http.Request{
+		httptest.NewRequest(http.MethodPut, "/v1.zip/new.txt", strings.NewReader("x")),
+		httptest.NewRequest(http.MethodDelete, "/v1.zip/notes.txt", nil),
+		httptest.NewRequest("MKCOL", "/v1.zip/folder", nil),
+	} {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if rec.Code < 400 {
+			t.Fatalf("expected %s %s to be rejected, got %d", req.Method, req.URL.Path, rec.Code)
+		}
+	}
+
+	// 압축 파일 자신은 실제 파일이므로 덮어쓸 수 있다.
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/v1.zip", strings.NewReader("replaced")))
+	if rec.Code != http.StatusCreated && rec.Code != http.StatusNoContent {
+		t.Fatalf("expected archive overwrite to succeed, got %d", rec.Code)
+	}
+	content, err := os.ReadFile(filepath.Join(root, "v1.zip"))
+	if err != nil || string(content) != "replaced" {
+		t.Fatalf("unexpected archive content %q: %v", content, err)
+	}
+}
@@ -0,0 +1,122 @@
+package webdav
+
+import (
+	"context"
+	"encoding/xml"
+	"net/http"
+	"os"
+	"strings"
+
+	"golang.org/x/net/webdav"
+	"taeu.kr/cohesion/internal/space/checksum"
+)
+
+// ownCloudChecksumsProp는 ownCloud/Nextcloud 클라이언트가 읽는 체크섬 속성 이름이다.
+var ownCloudChecksumsProp = xml.Name{Space: "http://owncloud.org/ns", Local: "checksums"}
+
+// ownCloudChecksumAlgorithms는 oc:checksums에 싣는 알고리즘과 표기다.
+var ownCloudChecksumAlgorithms = []struct {
+	algo  checksum.Algorithm
+	label string
+}{
+	{algo: checksum.SHA256, label: "SHA256"},
+	{algo: checksum.SHA1, label: "SHA1"},
+	{algo: checksum.MD5, label: "MD5"},
+}
+
+// checksumFS는 읽기용으로 연 파일에 이미 계산된 해시를 붙인다.
+// sha256이 캐시돼 있으면 getetag를 내용 해시로 바꾸고, 캐시된 해시는 oc:checksums 속성으로 보여 준다.
+// PROPFIND가 해시 계산을 일으키지 않도록 캐시만 조회한다. 계산은 REST/SFTP 요청이 시작한다.
+type checksumFS struct {
+	webdav.FileSystem
+	root      string
+	checksums *checksum.Service
+}
+
+func newChecksumFS(inner webdav.FileSystem, root string, checksums *checksum.Service) webdav.FileSystem {
+	return &checksumFS{FileSystem: inner, root: root, checksums: checksums}
+}
+
+func (cfs *checksumFS) OpenFile(ctx context.Context, name string, flag int, perm os.FileMode) (webdav.File, error) {
+	file, err := cfs.FileSystem.OpenFile(ctx, name, flag, perm)
+	if err != nil || isWriteFlag(flag) {
+		return file, err
+	}
+	return &checksumFile{
+		File:      file,
+		ctx:       ctx,
+		absPath:   resolveLocalPath(cfs.root, name),
+		checksums: cfs.checksums,
+	}, nil
+}
+
+type checksumFile struct {
+	webdav.File
+	ctx       context.Context
+	absPath   string
+	checksums *checksum.Service
+}
+
+// Stat은 sha256이 캐시된 일반 파일이면 내용 해시를 ETag로 쓰는 FileInfo를 반환한다.
+func (f *checksumFile) Stat() (os.FileInfo, error) {
+	info, err := f.File.Stat()
+	if err != nil || !info.Mode().IsRegular() {
+		return info, err
+	}
+	entry, err := f.checksums.Lookup(f.ctx, f.absPath, checksum.SHA256, info)
+	if err != nil || entry == nil {
+		return info, nil
+	}
+	return &checksumFileInfo{FileInfo: info, etag: `"` + entry.Digest + `"`}, nil
+}
+
+// DeadProps는 캐시된 해시를 oc:checksums 속성으로 반환한다. 캐시가 없으면 속성도 없다.
+func (f *checksumFile) DeadProps() (map[xml.Name]webdav.Property, error) {
+	info, err := f.File.Stat()
+	if err != nil || !info.Mode().IsRegular() {
+		return nil, nil
+	}
+
+	values := make([]string, 0, len(ownCloudChecksumAlgorithms))
+	for _, item := range ownCloudChecksumAlgorithms {
+		entry, err := f.checksums.Lookup(f.ctx, f.absPath, item.algo, info)
+		if err != nil || entry == nil {
+			continue
+		}
+		values = append(values, item.label+":"+entry.Digest)
+	}
+	if len(values) == 0 {
+		return nil, nil
+	}
+	return map[xml.Name]webdav.Property{
+		ownCloudChecksumsProp: {
+			XMLName:  ownCloudChecksumsProp,
+			InnerXML: []byte(`<checksum xmlns="http://owncloud.org/ns">` + strings.Join(values, " ") + `</checksum>`),
+		},
+	}, nil
+}
+
+// Patch는 DeadPropsHolder를 구현하지 않은 파일과 같이 모든 속성 변경을 거부한다.
+func (f *checksumFile) Patch(patches []webdav.Proppatch) ([]webdav.Propstat, error) {
+	pstat := webdav.Propstat{Status: http.StatusForbidden}
+	for _, patch := range patches {
+		for _, prop := range patch.Props {
+			pstat.Props = append(pstat.Props, webdav.Property{XMLName: prop.XMLName})
+		}
+	}
+	return []webdav.Propstat{pstat}, nil
+}
+
+type checksumFileInfo struct {
+	os.FileInfo
+	etag string
+}
+
+func (fi *checksumFileInfo) ETag(ctx context.Context) (string, error) {
+	return fi.etag, nil
+}
+
+var (
+	_ webdav.DeadPropsHolder = (*checksumFile)(nil)
+	_ webdav.ETager          = (*checksumFileInfo)(nil)
+)
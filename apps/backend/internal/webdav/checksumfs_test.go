@@ -0,0 +1,65 @@
+package webdav
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/net/webdav"
+	"taeu.kr/cohesion/internal/space/checksum"
+)
+
+func TestChecksumFS_ExposesCachedDigestsAsETagAndOwnCloudChecksums(t *testing.T) {
+	root := t.TempDir()
+	filePath := filepath.Join(root, "hello.txt")
+	if err := os.WriteFile(filePath, []byte("hello"), 0o644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	checksums := checksum.NewService(checksum.NewMemoryStore(), 1)
+	handler := &webdav.Handler{
+		FileSystem: newChecksumFS(webdav.Dir(root), root, checksums),
+		LockSystem: webdav.NewMemLS(),
+	}
+
+	// 해시가 계산되기 전에는 기본 ETag를 쓰고 체크섬 속성도 없다.
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/hello.txt", nil))
+	if etag := rec.Header().Get("ETag"); etag == "" || strings.Contains(etag, "2cf24dba") {
+		t.Fatalf("expected default ETag before hashing, got %q", etag)
+	}
+
+	for _, algo := range []checksum.Algorithm{checksum.SHA256, checksum.MD5} {
+		if _, err := checksums.Sum(context.Background(), filePath, algo, 5*time.Second); err != nil {
+			t.Fatalf("failed to compute %s: %v", algo, err)
+		}
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/hello.txt", nil))
+	if etag := rec.Header().Get("ETag"); etag != `"2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"` {
+		t.Fatalf("expected sha256 ETag, got %q", etag)
+	}
+
+	body := `<?xml version="1.0"?><d:propfind xmlns:d="DAV:" xmlns:oc="http://owncloud.org/ns"><d:prop><d:getetag/><oc:checksums/></d:prop></d:propfind>`
+	req := httptest.NewRequest("PROPFIND", "/hello.txt", strings.NewReader(body))
+	req.Header.Set("Depth", "0")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusMultiStatus {
+		t.Fatalf("expected 207, got %d: %s", rec.Code, rec.Body.String())
+	}
+	response := rec.Body.String()
+	for _, expected := range []string{
+		"2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824",
+		"SHA256:2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824 MD5:5d41402abc4b2a76b9719d911017c592",
+	} {
+		if !strings.Contains(response, expected) {
+			t.Fatalf("expected %q in PROPFIND response: %s", expected, response)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package webdav
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"golang.org/x/net/webdav"
+	"taeu.kr/cohesion/internal/space"
+)
+
+// eventFS는 쓰기로 연 파일을 다 쓰고 문제없이 닫으면 파일 이벤트를 낸다.
+// scanFS 바깥에 두어 block 모드에서 격리된 파일은 이벤트를 내지 않도록 한다.
+type eventFS struct {
+	webdav.FileSystem
+	publisher space.FileEventPublisher
+	resolve   usageResolveFunc
+}
+
+func newEventFS(inner webdav.FileSystem, publisher space.FileEventPublisher, resolve usageResolveFunc) webdav.FileSystem {
+	return &eventFS{FileSystem: inner, publisher: publisher, resolve: resolve}
+}
+
+// newSpaceEventFS는 Space 하나의 루트를 기준으로 이름을 푸는 eventFS를 만든다.
+func newSpaceEventFS(inner webdav.FileSystem, publisher space.FileEventPublisher, spaceID int64, root string) webdav.FileSystem {
+	return newEventFS(inner, publisher, func(ctx context.Context, name string) (int64, string, string, bool) {
+		return spaceID, root, resolveLocalPath(root, name), true
+	})
+}
+
+func (efs *eventFS) OpenFile(ctx context.Context, name string, flag int, perm os.FileMode) (webdav.File, error) {
+	file, err := efs.FileSystem.OpenFile(ctx, name, flag, perm)
+	if err != nil || !isWriteFlag(flag) {
+		return file, err
+	}
+	spaceID, root, absPath, ok := efs.resolve(ctx, name)
+	if !ok {
+		return file, nil
+	}
+	username, _ := UsernameFromContext(ctx)
+	return &eventFile{
+		File: file,
+		publish: func() {
+			efs.publisher.PublishFileEvent(ctx, space.FileEvent{
+				Kind:      space.FileEventCreated,
+				SpaceID:   spaceID,
+				SpaceRoot: root,
+				AbsPath:   absPath,
+				Actor:     username,
+				Source:    "webdav",
+				At:        time.Now().UTC(),
+			})
+		},
+	}, nil
+}
+
+// eventFile은 내용을 쓴 파일만 닫을 때 이벤트를 낸다.
+type eventFile struct {
+	webdav.File
+	publish func()
+	written bool
+}
+
+func (f *eventFile) Write(p []byte) (int, error) {
+	n, err := f.File.Write(p)
+	if n > 0 {
+		f.written = true
+	}
+	return n, err
+}
+
+func (f *eventFile) Close() error {
+	err := f.File.Close()
+	if err != nil || !f.written || f.publish == nil {
+		return err
+	}
+	publish := f.publish
+	f.publish = nil
+	publish()
+	return nil
+}
@@ -1,12 +1,17 @@
 package webdav
 
 import (
+	"bufio"
+	"errors"
+	"io"
 	"net/http"
+	"net/url"
 	"strings"
 
 	"github.com/rs/zerolog/log"
 	"taeu.kr/cohesion/internal/account"
 	"taeu.kr/cohesion/internal/platform/web"
+	"taeu.kr/cohesion/internal/space"
 	"taeu.kr/cohesion/internal/webdav"
 )
 
@@ -67,7 +72,7 @@ func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) *web.Error {
 		return nil
 	}
 
-	spaceObj, err := h.webDavService.GetSpaceByName(ctx, spaceName)
+	spaceObj, canonical, err := h.webDavService.ResolveSpace(ctx, spaceName)
 	if err != nil {
 		return &web.Error{
 			Code:    http.StatusNotFound,
@@ -91,22 +96,170 @@ func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) *web.Error {
 			Message: "Forbidden",
 		}
 	}
-
-	// 해당 space에 대한 WebDAV 핸들러 가져오기
-	webDavHandler, err := h.webDavService.GetWebDAVHandler(ctx, spaceName)
-	if err != nil {
+	// root가 사라진 상태에서 빈 마운트 지점을 그대로 보여 주지 않는다.
+	if err := h.webDavService.EnsureOnline(spaceObj.ID); err != nil {
 		return &web.Error{
-			Code:    http.StatusInternalServerError,
-			Message: "Failed to get WebDAV handler",
+			Code:    http.StatusServiceUnavailable,
+			Message: "Space storage is offline",
 			Err:     err,
 		}
 	}
+	if spaceObj.State() == space.SpaceStateMaintenance {
+		isAdmin, err := h.accountService.IsAdmin(ctx, username)
+		if err != nil {
+			return &web.Error{
+				Code:    http.StatusInternalServerError,
+				Message: "Failed to evaluate space access",
+				Err:     err,
+			}
+		}
+		if err := spaceObj.CheckReadable(isAdmin); err != nil {
+			return &web.Error{
+				Code:    http.StatusServiceUnavailable,
+				Message: "Space is under maintenance",
+				Err:     err,
+			}
+		}
+	}
+	if required != account.PermissionRead {
+		// 저장소 이전이 root를 바꾸기 전에 이 요청이 끝나기를 기다리도록 응답까지 쓰기를 잡아 둔다.
+		writable, release, err := h.webDavService.BeginWrite(ctx, spaceObj.ID)
+		if err != nil {
+			return &web.Error{
+				Code:    http.StatusLocked,
+				Message: webDAVWriteBlockedMessage(err),
+				Err:     err,
+			}
+		}
+		defer release()
+		spaceObj = writable
+	}
+
+	// 이전 이름이나 표시 이름으로 접근한 경우 slug 경로로 영구 이동시킨다.
+	if !canonical {
+		http.Redirect(w, r, canonicalWebDAVLocation(r.URL, spaceObj.ProtocolName()), http.StatusMovedPermanently)
+		return nil
+	}
+
+	// 보존 잠금에 걸린 덮어쓰기·삭제·이동은 파일을 건드리기 전에 423으로 거절한다.
+	if webErr := h.checkRetention(r.WithContext(ctx), username, spaceObj); webErr != nil {
+		return webErr
+	}
+
+	// PUT은 쓰기 전에 파일 수 할당량과, 본문 길이를 알면 용량 할당량까지 확인해 507로 거절한다.
+	if r.Method == http.MethodPut {
+		_, filePath := webdav.ResolvePath(r.URL.Path)
+		if err := h.webDavService.CheckWriteAllowance(ctx, username, spaceObj, filePath, r.ContentLength); err != nil {
+			if errors.Is(err, webdav.ErrQuotaExceeded) {
+				return &web.Error{
+					Code:    http.StatusInsufficientStorage,
+					Message: "Storage quota exceeded",
+					Err:     err,
+				}
+			}
+			return &web.Error{
+				Code:    http.StatusInternalServerError,
+				Message: "Failed to evaluate storage quota",
+				Err:     err,
+			}
+		}
+		if webErr := h.checkPutUploadPolicy(r, spaceObj, filePath); webErr != nil {
+			return webErr
+		}
+	}
+
+	// 해당 space에 대한 WebDAV 핸들러 가져오기
+	webDavHandler := h.webDavService.GetWebDAVHandler(spaceObj)
 
 	// WebDAV 핸들러로 요청 처리 위임
 	webDavHandler.ServeHTTP(w, r.WithContext(ctx))
 	return nil
 }
 
+// checkPutUploadPolicy는 PUT 본문 앞부분을 미리 읽어 이름·길이·형식을 Space 업로드 정책으로 확인한다.
+// 읽은 앞부분은 본문에 되돌려 놓는다. 정책에 걸리면 파일을 건드리기 전에 413 또는 415로 거절한다.
+func (h *Handler) checkPutUploadPolicy(r *http.Request, spaceObj *space.Space, filePath string) *web.Error {
+	body := bufio.NewReaderSize(r.Body, space.UploadPolicySniffBytes)
+	head, _ := body.Peek(space.UploadPolicySniffBytes)
+	r.Body = struct {
+		io.Reader
+		io.Closer
+	}{body, r.Body}
+
+	err := h.webDavService.CheckUploadPolicy(r.Context(), spaceObj, filePath, r.ContentLength, head)
+	if err == nil {
+		return nil
+	}
+	var policyErr *space.UploadPolicyError
+	if errors.As(err, &policyErr) {
+		code := http.StatusUnsupportedMediaType
+		if policyErr.Violation == space.UploadPolicyFileTooLarge {
+			code = http.StatusRequestEntityTooLarge
+		}
+		return &web.Error{Code: code, Message: policyErr.Reason(), Err: err}
+	}
+	return &web.Error{
+		Code:    http.StatusInternalServerError,
+		Message: "Failed to evaluate upload policy",
+		Err:     err,
+	}
+}
+
+// checkRetention은 요청이 바꿀 기존 경로를 보존 잠금으로 확인한다.
+// MOVE는 원본과 덮어쓸 대상을, COPY는 덮어쓸 대상만 본다.
+func (h *Handler) checkRetention(r *http.Request, username string, spaceObj *space.Space) *web.Error {
+	type retentionCheck struct {
+		filePath  string
+		operation string
+	}
+	_, filePath := webdav.ResolvePath(r.URL.Path)
+	var checks []retentionCheck
+	switch r.Method {
+	case http.MethodPut:
+		checks = append(checks, retentionCheck{filePath, "write"})
+	case http.MethodDelete:
+		checks = append(checks, retentionCheck{filePath, "delete"})
+	case "MOVE":
+		checks = append(checks, retentionCheck{filePath, "move"})
+		fallthrough
+	case "COPY":
+		if destination, err := url.Parse(r.Header.Get("Destination")); err == nil {
+			if _, destPath := webdav.ResolvePath(destination.Path); destPath != "" {
+				checks = append(checks, retentionCheck{destPath, "overwrite"})
+			}
+		}
+	}
+
+	for _, check := range checks {
+		err := h.webDavService.CheckRetention(r.Context(), username, spaceObj, check.filePath, check.operation)
+		if err == nil {
+			continue
+		}
+		var lockedErr *space.RetentionLockedError
+		if errors.As(err, &lockedErr) {
+			return &web.Error{Code: http.StatusLocked, Message: lockedErr.Reason(), Err: err}
+		}
+		return &web.Error{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to check retention lock",
+			Err:     err,
+		}
+	}
+	return nil
+}
+
+// webDAVWriteBlockedMessage는 423 응답 본문에 Space 상태별 사유를 담는다.
+func webDAVWriteBlockedMessage(err error) string {
+	switch {
+	case errors.Is(err, space.ErrSpaceMaintenance):
+		return "Space is under maintenance"
+	case errors.Is(err, space.ErrSpaceArchived):
+		return "Space is archived"
+	default:
+		return "Space is read-only"
+	}
+}
+
 func requiredPermissionForMethod(method string) account.Permission {
 	switch method {
 	case http.MethodGet, http.MethodHead, http.MethodOptions, "PROPFIND":
@@ -116,6 +269,19 @@ func requiredPermissionForMethod(method string) account.Permission {
 	}
 }
 
+// canonicalWebDAVLocation은 요청 경로의 Space 세그먼트를 slug로 바꾼 위치를 반환한다.
+func canonicalWebDAVLocation(requestURL *url.URL, slug string) string {
+	_, filePath := webdav.ResolvePath(requestURL.Path)
+	target := &url.URL{
+		Path:     "/dav/" + slug + filePath,
+		RawQuery: requestURL.RawQuery,
+	}
+	if filePath == "/" && !strings.HasSuffix(requestURL.Path, "/") {
+		target.Path = "/dav/" + slug
+	}
+	return target.String()
+}
+
 func writeWebDAVUnauthorized(w http.ResponseWriter) {
 	w.Header().Set("WWW-Authenticate", `Basic realm="Cohesion WebDAV"`)
 	w.WriteHeader(http.StatusUnauthorized)
@@ -97,3 +97,26 @@ func TestNormalizePROPFINDDepth(t *testing.T) {
 		})
 	}
 }
+
+func TestCanonicalWebDAVLocation(t *testing.T) {
+	tests := []struct {
+		name     string
+		target   string
+		slug     string
+		expected string
+	}{
+		{name: "space root", target: "/dav/Old%20Name", slug: "projects", expected: "/dav/projects"},
+		{name: "space root slash", target: "/dav/Old%20Name/", slug: "projects", expected: "/dav/projects/"},
+		{name: "nested file", target: "/dav/Old%20Name/docs/a%20b.txt?x=1", slug: "projects", expected: "/dav/projects/docs/a%20b.txt?x=1"},
+		{name: "unicode slug", target: "/dav/old/file.txt", slug: "사진", expected: "/dav/%EC%82%AC%EC%A7%84/file.txt"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("PROPFIND", tt.target, nil)
+			if got := canonicalWebDAVLocation(req.URL, tt.slug); got != tt.expected {
+				t.Fatalf("expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
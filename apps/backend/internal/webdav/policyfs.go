@@ -0,0 +1,107 @@
+package webdav
+
+import (
+	"context"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	"golang.org/x/net/webdav"
+	"taeu.kr/cohesion/internal/space"
+)
+
+// policyFS는 쓰기로 연 파일, 확장자를 바꾸는 이름 변경, Space를 건너는 이동에 Space 업로드 정책을 적용한다.
+// 정책에 걸린 쓰기로 새로 만든 파일은 닫을 때 지운다.
+type policyFS struct {
+	webdav.FileSystem
+	policy  space.UploadPolicyEnforcer
+	resolve usageResolveFunc
+}
+
+func newPolicyFS(inner webdav.FileSystem, policy space.UploadPolicyEnforcer, resolve usageResolveFunc) webdav.FileSystem {
+	return &policyFS{FileSystem: inner, policy: policy, resolve: resolve}
+}
+
+// newSpacePolicyFS는 Space 하나의 루트를 기준으로 이름을 푸는 policyFS를 만든다.
+func newSpacePolicyFS(inner webdav.FileSystem, policy space.UploadPolicyEnforcer, spaceID int64, root string) webdav.FileSystem {
+	return newPolicyFS(inner, policy, func(ctx context.Context, name string) (int64, string, string, bool) {
+		return spaceID, root, resolveLocalPath(root, name), true
+	})
+}
+
+func (pfs *policyFS) OpenFile(ctx context.Context, name string, flag int, perm os.FileMode) (webdav.File, error) {
+	if !isWriteFlag(flag) {
+		return pfs.FileSystem.OpenFile(ctx, name, flag, perm)
+	}
+	spaceID, _, absPath, ok := pfs.resolve(ctx, name)
+	if !ok {
+		return pfs.FileSystem.OpenFile(ctx, name, flag, perm)
+	}
+	guard, err := pfs.policy.BeginUpload(ctx, spaceID, path.Base(name))
+	if err != nil {
+		return nil, err
+	}
+	_, statErr := os.Stat(absPath)
+	file, err := pfs.FileSystem.OpenFile(ctx, name, flag, perm)
+	if err != nil || guard == nil {
+		return file, err
+	}
+	return &policyFile{File: file, guard: guard, absPath: absPath, created: os.IsNotExist(statErr)}, nil
+}
+
+// Rename은 Space를 건너면 옮기는 파일 전체를, 같은 Space에서 파일 확장자를 바꾸면 새 이름을 확인한다.
+func (pfs *policyFS) Rename(ctx context.Context, oldName, newName string) error {
+	oldSpaceID, _, oldPath, oldOK := pfs.resolve(ctx, oldName)
+	newSpaceID, _, newPath, newOK := pfs.resolve(ctx, newName)
+	if oldOK && newOK {
+		if oldSpaceID != newSpaceID {
+			if err := pfs.policy.CheckPath(ctx, newSpaceID, oldPath); err != nil {
+				return err
+			}
+		} else if info, err := os.Stat(oldPath); err == nil && !info.IsDir() &&
+			!strings.EqualFold(filepath.Ext(oldPath), filepath.Ext(newPath)) {
+			if err := pfs.policy.CheckName(ctx, newSpaceID, filepath.Base(newPath)); err != nil {
+				return err
+			}
+		}
+	}
+	return pfs.FileSystem.Rename(ctx, oldName, newName)
+}
+
+// policyFile은 쓰는 내용을 업로드 정책으로 확인한다. 앞부분으로 형식을, 쓰는 위치로 크기를 본다.
+type policyFile struct {
+	webdav.File
+	guard   *space.UploadWriteGuard
+	absPath string
+	created bool
+	offset  int64
+}
+
+func (f *policyFile) Write(p []byte) (int, error) {
+	if err := f.guard.Check(f.offset, p); err != nil {
+		return 0, err
+	}
+	n, err := f.File.Write(p)
+	f.offset += int64(n)
+	return n, err
+}
+
+func (f *policyFile) Seek(offset int64, whence int) (int64, error) {
+	position, err := f.File.Seek(offset, whence)
+	if err == nil {
+		f.offset = position
+	}
+	return position, err
+}
+
+func (f *policyFile) Close() error {
+	err := f.File.Close()
+	if f.created && f.guard.Rejected() {
+		if removeErr := os.Remove(f.absPath); removeErr != nil && !os.IsNotExist(removeErr) {
+			log.Warn().Err(removeErr).Str("path", f.absPath).Msg("failed to remove WebDAV file rejected by upload policy")
+		}
+	}
+	return err
+}
@@ -0,0 +1,71 @@
+package webdav
+
+import (
+	"context"
+	"os"
+
+	"golang.org/x/net/webdav"
+	"taeu.kr/cohesion/internal/space"
+)
+
+// retentionFS는 보존 잠금이 걸린 폴더 안의 기존 파일을 쓰기로 열거나 지우거나 옮기지 못하게 한다.
+// 다른 래퍼보다 바깥에 두어 거절한 요청이 사용량이나 정책 확인을 거치지 않게 한다.
+type retentionFS struct {
+	webdav.FileSystem
+	guard   space.RetentionGuard
+	resolve usageResolveFunc
+}
+
+func newRetentionFS(inner webdav.FileSystem, guard space.RetentionGuard, resolve usageResolveFunc) webdav.FileSystem {
+	return &retentionFS{FileSystem: inner, guard: guard, resolve: resolve}
+}
+
+// newSpaceRetentionFS는 Space 하나의 루트를 기준으로 이름을 푸는 retentionFS를 만든다.
+func newSpaceRetentionFS(inner webdav.FileSystem, guard space.RetentionGuard, spaceID int64, root string) webdav.FileSystem {
+	return newRetentionFS(inner, guard, func(ctx context.Context, name string) (int64, string, string, bool) {
+		return spaceID, root, resolveLocalPath(root, name), true
+	})
+}
+
+func (rfs *retentionFS) OpenFile(ctx context.Context, name string, flag int, perm os.FileMode) (webdav.File, error) {
+	if isWriteFlag(flag) {
+		if err := rfs.ensureMutable(ctx, name, "write"); err != nil {
+			return nil, err
+		}
+	}
+	return rfs.FileSystem.OpenFile(ctx, name, flag, perm)
+}
+
+func (rfs *retentionFS) RemoveAll(ctx context.Context, name string) error {
+	if err := rfs.ensureMutable(ctx, name, "delete"); err != nil {
+		return err
+	}
+	return rfs.FileSystem.RemoveAll(ctx, name)
+}
+
+// Rename은 옮기는 원본과, 덮어쓸 대상이 이미 있으면 대상도 확인한다.
+func (rfs *retentionFS) Rename(ctx context.Context, oldName, newName string) error {
+	if err := rfs.ensureMutable(ctx, oldName, "move"); err != nil {
+		return err
+	}
+	if err := rfs.ensureMutable(ctx, newName, "overwrite"); err != nil {
+		return err
+	}
+	return rfs.FileSystem.Rename(ctx, oldName, newName)
+}
+
+func (rfs *retentionFS) ensureMutable(ctx context.Context, name, operation string) error {
+	spaceID, root, absPath, ok := rfs.resolve(ctx, name)
+	if !ok {
+		return nil
+	}
+	username, _ := UsernameFromContext(ctx)
+	return rfs.guard.EnsureMutable(ctx, space.RetentionTarget{
+		SpaceID:   spaceID,
+		SpaceRoot: root,
+		AbsPath:   absPath,
+		Operation: operation,
+		Actor:     username,
+		Source:    "webdav",
+	})
+}
@@ -0,0 +1,77 @@
+package webdav
+
+import (
+	"context"
+	"os"
+
+	"golang.org/x/net/webdav"
+	"taeu.kr/cohesion/internal/space"
+)
+
+// scanFS는 쓰기로 연 파일을 다 쓰고 닫을 때 Space 설정에 따라 악성 코드 검사를 요청한다.
+// usageFS 바깥에 두어 사용량과 소유자 기록이 끝난 뒤 격리되도록 한다.
+type scanFS struct {
+	webdav.FileSystem
+	hook    space.MalwareScanHook
+	resolve usageResolveFunc
+}
+
+func newScanFS(inner webdav.FileSystem, hook space.MalwareScanHook, resolve usageResolveFunc) webdav.FileSystem {
+	return &scanFS{FileSystem: inner, hook: hook, resolve: resolve}
+}
+
+// newSpaceScanFS는 Space 하나의 루트를 기준으로 이름을 푸는 scanFS를 만든다.
+func newSpaceScanFS(inner webdav.FileSystem, hook space.MalwareScanHook, spaceID int64, root string) webdav.FileSystem {
+	return newScanFS(inner, hook, func(ctx context.Context, name string) (int64, string, string, bool) {
+		return spaceID, root, resolveLocalPath(root, name), true
+	})
+}
+
+func (sfs *scanFS) OpenFile(ctx context.Context, name string, flag int, perm os.FileMode) (webdav.File, error) {
+	file, err := sfs.FileSystem.OpenFile(ctx, name, flag, perm)
+	if err != nil || !isWriteFlag(flag) {
+		return file, err
+	}
+	spaceID, root, absPath, ok := sfs.resolve(ctx, name)
+	if !ok {
+		return file, nil
+	}
+	username, _ := UsernameFromContext(ctx)
+	return &scanFile{
+		File: file,
+		scan: func() error {
+			return sfs.hook.ScanWrittenFile(ctx, space.MalwareScanTarget{
+				SpaceID:   spaceID,
+				SpaceRoot: root,
+				FilePath:  absPath,
+				Actor:     username,
+				Source:    "webdav",
+			})
+		},
+	}, nil
+}
+
+// scanFile은 내용을 쓴 파일만 닫을 때 검사한다. block 모드에서 감염이면 Close가 오류를 반환한다.
+type scanFile struct {
+	webdav.File
+	scan    func() error
+	written bool
+}
+
+func (f *scanFile) Write(p []byte) (int, error) {
+	n, err := f.File.Write(p)
+	if n > 0 {
+		f.written = true
+	}
+	return n, err
+}
+
+func (f *scanFile) Close() error {
+	err := f.File.Close()
+	if err != nil || !f.written || f.scan == nil {
+		return err
+	}
+	scan := f.scan
+	f.scan = nil
+	return scan()
+}
@@ -7,85 +7,263 @@ package webdav
 import (
 	"context"
 	"net/http"
+	"path"
 	"sync"
 
 	"github.com/rs/zerolog/log"
 	"golang.org/x/net/webdav"
 	"taeu.kr/cohesion/internal/account"
 	"taeu.kr/cohesion/internal/space"
+	"taeu.kr/cohesion/internal/space/archive"
+	"taeu.kr/cohesion/internal/space/checksum"
 )
 
 type Service struct {
 	spaceService   *space.Service
 	accountService *account.Service
-	lockSystems    map[string]webdav.LockSystem
+	lockSystems    map[int64]webdav.LockSystem
 	mu             sync.Mutex
 	rootHandler    http.Handler
+	// archiveIndexes는 압축 파일 탐색을 켠 Space에서 쓰는 항목 목록 캐시다.
+	archiveIndexes *archive.Cache
+	// checksums가 있으면 캐시된 파일 해시를 getetag와 oc:checksums로 보여 준다.
+	checksums *checksum.Service
+	// usage가 있으면 쓰기/삭제/이동으로 바뀐 크기를 Space 사용량 카운터에 알린다.
+	usage space.UsageTracker
+	// userStorage가 있으면 할당량을 넘는 쓰기를 막고 쓴 파일의 소유자를 기록한다.
+	userStorage space.UserStorageTracker
+	// uploadPolicy가 있으면 Space 업로드 정책에 맞지 않는 파일을 쓰거나 들여오지 못하게 한다.
+	uploadPolicy space.UploadPolicyEnforcer
+	// malwareScan이 있으면 다 쓴 파일을 Space 검사 설정에 따라 검사하고 감염된 파일을 격리한다.
+	malwareScan space.MalwareScanHook
+	// fileEvents가 있으면 다 쓴 파일의 이벤트를 내 자동 정리 규칙 같은 후속 처리를 맡긴다.
+	fileEvents space.FileEventPublisher
+	// retention이 있으면 보존 잠금이 걸린 폴더의 기존 파일을 덮어쓰거나 옮기거나 지우지 못하게 한다.
+	retention space.RetentionGuard
 }
 
 func NewService(spaceService *space.Service, accountService *account.Service) *Service {
-	return &Service{
+	s := &Service{
 		spaceService:   spaceService,
 		accountService: accountService,
-		lockSystems:    make(map[string]webdav.LockSystem),
-		rootHandler: &webdav.Handler{
-			Prefix:     "/dav",
-			FileSystem: NewSpaceFS(spaceService, accountService),
-			LockSystem: webdav.NewMemLS(),
-			Logger: func(r *http.Request, err error) {
-				if err != nil {
-					log.Error().Err(err).Msgf("WebDAV root error: %s %s", r.Method, r.URL.Path)
-				}
-			},
+		lockSystems:    make(map[int64]webdav.LockSystem),
+		archiveIndexes: archive.NewCache(16, archive.DefaultMaxEntries),
+	}
+	s.rootHandler = s.newRootHandler()
+	return s
+}
+
+func (s *Service) newRootHandler() http.Handler {
+	spaceFS := &SpaceFS{spaceService: s.spaceService, accountService: s.accountService}
+	var fileSystem webdav.FileSystem = spaceFS
+	if s.uploadPolicy != nil {
+		fileSystem = newPolicyFS(fileSystem, s.uploadPolicy, spaceFS.resolveUsagePath)
+	}
+	if s.usage != nil || s.userStorage != nil {
+		fileSystem = newUsageFS(fileSystem, s.usage, s.userStorage, spaceFS.resolveUsagePath)
+	}
+	if s.malwareScan != nil {
+		fileSystem = newScanFS(fileSystem, s.malwareScan, spaceFS.resolveUsagePath)
+	}
+	if s.fileEvents != nil {
+		fileSystem = newEventFS(fileSystem, s.fileEvents, spaceFS.resolveUsagePath)
+	}
+	if s.retention != nil {
+		fileSystem = newRetentionFS(fileSystem, s.retention, spaceFS.resolveUsagePath)
+	}
+	return &webdav.Handler{
+		Prefix:     "/dav",
+		FileSystem: fileSystem,
+		LockSystem: webdav.NewMemLS(),
+		Logger: func(r *http.Request, err error) {
+			if err != nil {
+				log.Error().Err(err).Msgf("WebDAV root error: %s %s", r.Method, r.URL.Path)
+			}
 		},
 	}
 }
 
+// SetChecksumService는 REST/SFTP와 같은 해시 캐시를 WebDAV 속성에 쓰도록 설정한다.
+func (s *Service) SetChecksumService(checksums *checksum.Service) {
+	s.checksums = checksums
+}
+
+// SetUsageTracker는 WebDAV로 바뀐 파일 크기를 Space 사용량 카운터에 반영하도록 설정한다.
+// 서버를 시작하기 전에 호출해야 한다.
+func (s *Service) SetUsageTracker(tracker space.UsageTracker) {
+	s.usage = tracker
+	s.rootHandler = s.newRootHandler()
+}
+
+// SetUserStorageTracker는 WebDAV 쓰기에 Space·사용자 할당량을 적용하고 파일 소유자를 기록하도록 설정한다.
+// 서버를 시작하기 전에 호출해야 한다.
+func (s *Service) SetUserStorageTracker(tracker space.UserStorageTracker) {
+	s.userStorage = tracker
+	s.rootHandler = s.newRootHandler()
+}
+
+// SetUploadPolicyEnforcer는 WebDAV 쓰기와 이동에 Space 업로드 정책을 적용하도록 설정한다.
+// 서버를 시작하기 전에 호출해야 한다.
+func (s *Service) SetUploadPolicyEnforcer(enforcer space.UploadPolicyEnforcer) {
+	s.uploadPolicy = enforcer
+	s.rootHandler = s.newRootHandler()
+}
+
+// SetMalwareScanHook은 WebDAV로 다 쓴 파일을 Space 검사 설정에 따라 검사하도록 설정한다.
+// 서버를 시작하기 전에 호출해야 한다.
+func (s *Service) SetMalwareScanHook(hook space.MalwareScanHook) {
+	s.malwareScan = hook
+	s.rootHandler = s.newRootHandler()
+}
+
+// SetFileEventPublisher는 WebDAV로 다 쓴 파일의 이벤트를 publisher로 내도록 설정한다.
+// 서버를 시작하기 전에 호출해야 한다.
+func (s *Service) SetFileEventPublisher(publisher space.FileEventPublisher) {
+	s.fileEvents = publisher
+	s.rootHandler = s.newRootHandler()
+}
+
+// SetRetentionGuard는 WebDAV 쓰기·삭제·이동에 폴더별 보존 잠금을 적용하도록 설정한다.
+// 서버를 시작하기 전에 호출해야 한다.
+func (s *Service) SetRetentionGuard(guard space.RetentionGuard) {
+	s.retention = guard
+	s.rootHandler = s.newRootHandler()
+}
+
+// CheckRetention은 Space 안 filePath를 operation으로 바꿔도 되는지 보존 잠금으로 미리 확인한다.
+func (s *Service) CheckRetention(ctx context.Context, username string, spaceObj *space.Space, filePath, operation string) error {
+	if s.retention == nil {
+		return nil
+	}
+	return s.retention.EnsureMutable(ctx, space.RetentionTarget{
+		SpaceID:   spaceObj.ID,
+		SpaceRoot: spaceObj.SpacePath,
+		AbsPath:   resolveLocalPath(spaceObj.SpacePath, filePath),
+		Operation: operation,
+		Actor:     username,
+		Source:    "webdav",
+	})
+}
+
+// CheckUploadPolicy는 PUT으로 Space 안 filePath에 쓸 파일을 업로드 정책으로 미리 확인한다.
+// size는 본문 길이(모르면 음수), head는 본문 앞부분이다.
+func (s *Service) CheckUploadPolicy(ctx context.Context, spaceObj *space.Space, filePath string, size int64, head []byte) error {
+	if s.uploadPolicy == nil {
+		return nil
+	}
+	guard, err := s.uploadPolicy.BeginUpload(ctx, spaceObj.ID, path.Base(filePath))
+	if err != nil {
+		return err
+	}
+	if size >= 0 {
+		if err := guard.CheckSize(size); err != nil {
+			return err
+		}
+	}
+	return guard.Check(0, head)
+}
+
+// CheckWriteAllowance는 PUT 본문 size바이트를 Space 안 filePath에 써도 할당량을 넘지 않는지 미리 확인한다.
+// 새 파일이면 파일 수 할당량도 확인한다.
+func (s *Service) CheckWriteAllowance(ctx context.Context, username string, spaceObj *space.Space, filePath string, size int64) error {
+	absPath := resolveLocalPath(spaceObj.SpacePath, filePath)
+	if err := ensureCanCreateFile(ctx, s.usage, spaceObj.ID, absPath); err != nil {
+		return err
+	}
+	if size <= 0 {
+		return nil
+	}
+	allowance, err := writeAllowance(ctx, s.userStorage, username, spaceObj.ID, absPath)
+	if err != nil {
+		return err
+	}
+	if allowance >= 0 && size > allowance {
+		return ErrQuotaExceeded
+	}
+	return nil
+}
+
 func (s *Service) GetRootHandler() http.Handler {
 	return s.rootHandler
 }
 
 func (s *Service) GetSpaceByName(ctx context.Context, spaceName string) (*space.Space, error) {
-	return s.spaceService.GetSpaceByName(ctx, spaceName)
+	spaceObj, _, err := s.ResolveSpace(ctx, spaceName)
+	return spaceObj, err
 }
 
-func (s *Service) GetWebDAVHandler(ctx context.Context, spaceName string) (http.Handler, error) {
+// ResolveSpace는 경로의 Space 세그먼트를 slug/이름/alias 순으로 해석한다.
+// canonical이 false이면 호출자가 slug 경로로 리다이렉트해야 한다.
+func (s *Service) ResolveSpace(ctx context.Context, spaceName string) (*space.Space, bool, error) {
+	return s.spaceService.ResolveSpaceByProtocolName(ctx, spaceName)
+}
 
-	// 해당 이름의 Space 조회
-	spaceObj, err := s.GetSpaceByName(ctx, spaceName)
-	if err != nil {
-		return nil, err
-	}
+// EnsureOnline은 Space root가 마지막 점검에서 offline으로 표시되지 않았는지 확인한다.
+func (s *Service) EnsureOnline(spaceID int64) error {
+	return s.spaceService.EnsureOnline(spaceID)
+}
+
+// BeginWrite는 Space가 현재 쓰기를 허용하는지 확인하고 요청이 끝날 때까지 쓰기를 진행 중으로 기록한다.
+// 반환한 Space의 root로 요청을 처리해야 하며, 끝나면 반환한 함수를 호출해야 한다.
+func (s *Service) BeginWrite(ctx context.Context, spaceID int64) (*space.Space, func(), error) {
+	return s.spaceService.BeginWrite(ctx, spaceID)
+}
 
-	// LockSystem 가져오기
-	ls := s.getLockSystem(spaceName)
+func (s *Service) GetWebDAVHandler(spaceObj *space.Space) http.Handler {
+	slug := spaceObj.ProtocolName()
+
+	// LockSystem은 이름이 바뀌어도 유지되도록 Space ID 기준으로 관리한다.
+	ls := s.getLockSystem(spaceObj.ID)
+
+	var fileSystem webdav.FileSystem = webdav.Dir(spaceObj.SpacePath)
+	if spaceObj.WebDAVArchiveBrowsing {
+		fileSystem = newArchiveFS(spaceObj.SpacePath, s.archiveIndexes)
+	}
+	if s.checksums != nil {
+		fileSystem = newChecksumFS(fileSystem, spaceObj.SpacePath, s.checksums)
+	}
+	if s.uploadPolicy != nil {
+		fileSystem = newSpacePolicyFS(fileSystem, s.uploadPolicy, spaceObj.ID, spaceObj.SpacePath)
+	}
+	if s.usage != nil || s.userStorage != nil {
+		fileSystem = newSpaceUsageFS(fileSystem, s.usage, s.userStorage, spaceObj.ID, spaceObj.SpacePath)
+	}
+	if s.malwareScan != nil {
+		fileSystem = newSpaceScanFS(fileSystem, s.malwareScan, spaceObj.ID, spaceObj.SpacePath)
+	}
+	if s.fileEvents != nil {
+		fileSystem = newSpaceEventFS(fileSystem, s.fileEvents, spaceObj.ID, spaceObj.SpacePath)
+	}
+	if s.retention != nil {
+		fileSystem = newSpaceRetentionFS(fileSystem, s.retention, spaceObj.ID, spaceObj.SpacePath)
+	}
 
 	// WebDAV 핸들러 생성
 	return &webdav.Handler{
-		Prefix:     "/dav/" + spaceName,
-		FileSystem: webdav.Dir(spaceObj.SpacePath),
+		Prefix:     "/dav/" + slug,
+		FileSystem: fileSystem,
 		LockSystem: ls,
 		Logger: func(r *http.Request, err error) {
 			if err != nil {
 				log.Error().Err(err).Msgf("WebDAV error: %s %s", r.Method, r.URL.Path)
 			}
 		},
-	}, nil
+	}
 }
 
 /*
- * spaceName에 해당하는 LockSystem을 반환한다.
+ * spaceID에 해당하는 LockSystem을 반환한다.
  * LockSystem이 존재하지 않으면 새로 생성하여 저장한 후 반환한다.
  */
-func (s *Service) getLockSystem(spaceName string) webdav.LockSystem {
+func (s *Service) getLockSystem(spaceID int64) webdav.LockSystem {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	if ls, exists := s.lockSystems[spaceName]; exists {
+	if ls, exists := s.lockSystems[spaceID]; exists {
 		return ls
 	}
 
 	ls := webdav.NewMemLS()
-	s.lockSystems[spaceName] = ls
+	s.lockSystems[spaceID] = ls
 	return ls
 }
@@ -50,7 +50,7 @@ func parsePath(name string) (spaceName, remainder string) {
 
 // resolveRealPath는 spaceName과 나머지 경로를 실제 OS 경로로 변환한다.
 func (sfs *SpaceFS) resolveRealPath(ctx context.Context, spaceName, remainder string) (string, error) {
-	sp, err := sfs.spaceService.GetSpaceByName(ctx, spaceName)
+	sp, _, err := sfs.spaceService.ResolveSpaceByProtocolName(ctx, spaceName)
 	if err != nil {
 		return "", os.ErrNotExist
 	}
@@ -66,6 +66,23 @@ func (sfs *SpaceFS) resolveRealPath(ctx context.Context, spaceName, remainder st
 	return realPath, nil
 }
 
+// resolveUsagePath는 사용량 반영을 위해 이름을 Space ID, Space 루트, 실제 경로로 바꾼다. Space 루트와 그 위는 제외한다.
+func (sfs *SpaceFS) resolveUsagePath(ctx context.Context, name string) (int64, string, string, bool) {
+	spaceName, remainder := parsePath(name)
+	if spaceName == "" || remainder == "/" {
+		return 0, "", "", false
+	}
+	sp, _, err := sfs.spaceService.ResolveSpaceByProtocolName(ctx, spaceName)
+	if err != nil {
+		return 0, "", "", false
+	}
+	realPath := filepath.Clean(filepath.Join(sp.SpacePath, filepath.FromSlash(remainder)))
+	if !isPathWithinSpace(realPath, sp.SpacePath) {
+		return 0, "", "", false
+	}
+	return sp.ID, sp.SpacePath, realPath, true
+}
+
 func (sfs *SpaceFS) Mkdir(ctx context.Context, name string, perm os.FileMode) error {
 	spaceName, remainder := parsePath(name)
 	if spaceName == "" {
@@ -219,6 +236,9 @@ func (d *spaceRootDir) Readdir(count int) ([]fs.FileInfo, error) {
 
 		d.entries = make([]os.FileInfo, 0, len(spaces))
 		for _, sp := range spaces {
+			if !sp.IsListed() {
+				continue
+			}
 			allowed, err := d.accountService.CanAccessSpaceByID(d.ctx, username, sp.ID, account.PermissionRead)
 			if err != nil {
 				return nil, err
@@ -228,7 +248,7 @@ func (d *spaceRootDir) Readdir(count int) ([]fs.FileInfo, error) {
 			}
 
 			d.entries = append(d.entries, &virtualDirInfo{
-				name:    sp.SpaceName,
+				name:    sp.ProtocolName(),
 				modTime: sp.CreatedAt,
 			})
 		}
@@ -0,0 +1,215 @@
+package webdav
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path"
+	"path/filepath"
+
+	"github.com/rs/zerolog/log"
+	"golang.org/x/net/webdav"
+	"taeu.kr/cohesion/internal/space"
+)
+
+// ErrQuotaExceeded는 Space(용량·파일 수) 또는 사용자 할당량을 넘는 WebDAV 쓰기다.
+var ErrQuotaExceeded = errors.New("storage quota exceeded")
+
+// usageResolveFunc는 WebDAV 이름을 Space ID, Space 루트, 실제 경로로 바꾼다. Space 밖이면 ok가 false다.
+type usageResolveFunc func(ctx context.Context, name string) (spaceID int64, root string, absPath string, ok bool)
+
+// usageFS는 쓰기로 연 파일, 지운 경로, Space를 건너는 이동의 크기 변화를 사용량 카운터에 알린다.
+// 바뀌는 경로만 재므로 Space 전체를 다시 세지 않는다. 디렉터리 자체는 사용량에 들어가지 않는다.
+// owners가 있으면 할당량을 넘는 쓰기를 막고, 쓴 파일을 요청 사용자 소유로 기록한다.
+type usageFS struct {
+	webdav.FileSystem
+	tracker space.UsageTracker
+	owners  space.UserStorageTracker
+	resolve usageResolveFunc
+}
+
+func newUsageFS(inner webdav.FileSystem, tracker space.UsageTracker, owners space.UserStorageTracker, resolve usageResolveFunc) webdav.FileSystem {
+	return &usageFS{FileSystem: inner, tracker: tracker, owners: owners, resolve: resolve}
+}
+
+// newSpaceUsageFS는 Space 하나의 루트를 기준으로 이름을 푸는 usageFS를 만든다.
+func newSpaceUsageFS(inner webdav.FileSystem, tracker space.UsageTracker, owners space.UserStorageTracker, spaceID int64, root string) webdav.FileSystem {
+	return newUsageFS(inner, tracker, owners, func(ctx context.Context, name string) (int64, string, string, bool) {
+		return spaceID, root, resolveLocalPath(root, name), true
+	})
+}
+
+// resolveLocalPath는 webdav.Dir과 같은 방식으로 이름을 root 아래 경로로 바꾼다.
+func resolveLocalPath(root, name string) string {
+	return filepath.Join(root, filepath.FromSlash(path.Clean("/"+name)))
+}
+
+func (ufs *usageFS) OpenFile(ctx context.Context, name string, flag int, perm os.FileMode) (webdav.File, error) {
+	if !isWriteFlag(flag) {
+		return ufs.FileSystem.OpenFile(ctx, name, flag, perm)
+	}
+	spaceID, root, absPath, ok := ufs.resolve(ctx, name)
+	if !ok {
+		return ufs.FileSystem.OpenFile(ctx, name, flag, perm)
+	}
+	username, _ := UsernameFromContext(ctx)
+	remaining, err := writeAllowance(ctx, ufs.owners, username, spaceID, absPath)
+	if err != nil {
+		return nil, err
+	}
+	if flag&os.O_CREATE != 0 {
+		if err := ensureCanCreateFile(ctx, ufs.tracker, spaceID, absPath); err != nil {
+			return nil, err
+		}
+	}
+	commit := space.TrackUsage(ctx, ufs.tracker, spaceID, absPath)
+	file, err := ufs.FileSystem.OpenFile(ctx, name, flag, perm)
+	if err != nil {
+		return nil, err
+	}
+	return &usageFile{
+		File:      file,
+		remaining: remaining,
+		commit: func() {
+			commit()
+			ufs.recordOwner(ctx, username, spaceID, root, absPath)
+		},
+	}, nil
+}
+
+// ensureCanCreateFile은 absPath를 새로 만들면 파일 수 할당량을 넘는지 확인한다.
+func ensureCanCreateFile(ctx context.Context, tracker space.UsageTracker, spaceID int64, absPath string) error {
+	if err := space.EnsureCanCreateFile(ctx, tracker, spaceID, absPath); err != nil {
+		var fileQuotaErr *space.FileQuotaExceededError
+		if errors.As(err, &fileQuotaErr) {
+			return ErrQuotaExceeded
+		}
+		return err
+	}
+	return nil
+}
+
+// writeAllowance는 absPath에 쓸 수 있는 바이트 수다. 덮어쓸 파일 크기는 돌려받는 것으로 본다. 제한이 없으면 -1이다.
+func writeAllowance(ctx context.Context, owners space.UserStorageTracker, username string, spaceID int64, absPath string) (int64, error) {
+	if owners == nil {
+		return -1, nil
+	}
+	allowance, err := owners.WriteAllowance(ctx, username, spaceID)
+	if err != nil || allowance < 0 {
+		return allowance, err
+	}
+	existing, err := space.MeasurePaths(ctx, absPath)
+	if err != nil {
+		return 0, err
+	}
+	return allowance + existing, nil
+}
+
+func (ufs *usageFS) RemoveAll(ctx context.Context, name string) error {
+	spaceID, root, absPath, ok := ufs.resolve(ctx, name)
+	if !ok {
+		return ufs.FileSystem.RemoveAll(ctx, name)
+	}
+	commit := space.TrackUsage(ctx, ufs.tracker, spaceID, absPath)
+	defer commit()
+	if err := ufs.FileSystem.RemoveAll(ctx, name); err != nil {
+		return err
+	}
+	if ufs.owners != nil {
+		if err := ufs.owners.DeleteOwners(context.WithoutCancel(ctx), spaceID, root, absPath); err != nil {
+			log.Warn().Err(err).Str("path", absPath).Msg("failed to delete WebDAV file owners")
+		}
+	}
+	return nil
+}
+
+// Rename은 같은 Space 안에서는 덮어쓴 대상 크기만, Space를 건너면 양쪽 변화를 모두 반영한다.
+func (ufs *usageFS) Rename(ctx context.Context, oldName, newName string) error {
+	oldSpaceID, oldRoot, oldPath, oldOK := ufs.resolve(ctx, oldName)
+	newSpaceID, newRoot, newPath, newOK := ufs.resolve(ctx, newName)
+	if !oldOK || !newOK {
+		return ufs.FileSystem.Rename(ctx, oldName, newName)
+	}
+	if oldSpaceID == newSpaceID {
+		before, measureErr := space.MeasurePathUsage(ctx, newPath)
+		if err := ufs.FileSystem.Rename(ctx, oldName, newName); err != nil {
+			return err
+		}
+		if measureErr != nil {
+			ufs.invalidate(newSpaceID)
+		} else {
+			ufs.adjust(ctx, newSpaceID, -before.Bytes, -before.Files)
+		}
+		ufs.moveOwners(ctx, oldSpaceID, oldRoot, oldPath, newSpaceID, newRoot, newPath)
+		return nil
+	}
+
+	commitOld := space.TrackUsage(ctx, ufs.tracker, oldSpaceID, oldPath)
+	commitNew := space.TrackUsage(ctx, ufs.tracker, newSpaceID, newPath)
+	err := ufs.FileSystem.Rename(ctx, oldName, newName)
+	commitOld()
+	commitNew()
+	if err == nil {
+		ufs.moveOwners(ctx, oldSpaceID, oldRoot, oldPath, newSpaceID, newRoot, newPath)
+	}
+	return err
+}
+
+func (ufs *usageFS) recordOwner(ctx context.Context, username string, spaceID int64, root, absPath string) {
+	if ufs.owners == nil || username == "" {
+		return
+	}
+	if err := ufs.owners.RecordOwner(context.WithoutCancel(ctx), username, spaceID, root, absPath); err != nil {
+		log.Warn().Err(err).Str("path", absPath).Msg("failed to record WebDAV file owner")
+	}
+}
+
+func (ufs *usageFS) moveOwners(ctx context.Context, fromSpaceID int64, fromRoot, fromPath string, toSpaceID int64, toRoot, toPath string) {
+	if ufs.owners == nil {
+		return
+	}
+	if err := ufs.owners.MoveOwners(context.WithoutCancel(ctx), fromSpaceID, fromRoot, fromPath, toSpaceID, toRoot, toPath); err != nil {
+		log.Warn().Err(err).Str("path", fromPath).Msg("failed to move WebDAV file owners")
+	}
+}
+
+func (ufs *usageFS) adjust(ctx context.Context, spaceID int64, deltaBytes int64, deltaFiles int64) {
+	if ufs.tracker != nil {
+		ufs.tracker.AdjustUsage(context.WithoutCancel(ctx), spaceID, deltaBytes, deltaFiles)
+	}
+}
+
+func (ufs *usageFS) invalidate(spaceID int64) {
+	if ufs.tracker != nil {
+		ufs.tracker.Invalidate(spaceID)
+	}
+}
+
+// usageFile은 닫힐 때 파일 크기를 다시 재어 연 시점과의 차이를 알린다.
+// remaining이 0 이상이면 그보다 많이 쓰지 못하게 막는다.
+type usageFile struct {
+	webdav.File
+	remaining int64
+	commit    func()
+}
+
+func (f *usageFile) Write(p []byte) (int, error) {
+	if f.remaining < 0 {
+		return f.File.Write(p)
+	}
+	if int64(len(p)) > f.remaining {
+		return 0, ErrQuotaExceeded
+	}
+	n, err := f.File.Write(p)
+	f.remaining -= int64(n)
+	return n, err
+}
+
+func (f *usageFile) Close() error {
+	err := f.File.Close()
+	if f.commit != nil {
+		f.commit()
+		f.commit = nil
+	}
+	return err
+}
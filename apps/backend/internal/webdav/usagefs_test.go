@@ -0,0 +1,83 @@
+package webdav
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/net/webdav"
+)
+
+type recordingUsageTracker struct {
+	deltas      map[int64]int64
+	invalidated []int64
+}
+
+func (r *recordingUsageTracker) AdjustUsage(ctx context.Context, spaceID int64, deltaBytes int64, deltaFiles int64) {
+	if r.deltas == nil {
+		r.deltas = make(map[int64]int64)
+	}
+	r.deltas[spaceID] += deltaBytes
+}
+
+func (r *recordingUsageTracker) Invalidate(spaceID int64) {
+	r.invalidated = append(r.invalidated, spaceID)
+}
+
+func TestUsageFS_ReportsPutOverwriteMoveAndDelete(t *testing.T) {
+	root := t.TempDir()
+	tracker := &recordingUsageTracker{}
+	handler := &webdav.Handler{
+		FileSystem: newSpaceUsageFS(webdav.Dir(root), tracker, nil, 7, root),
+		LockSystem: webdav.NewMemLS(),
+	}
+	serve := func(method, target, body string, headers map[string]string) int {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		for key, value := range headers {
+			req.Header.Set(key, value)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	if code := serve(http.MethodPut, "/a.txt", "hello world", nil); code != http.StatusCreated {
+		t.Fatalf("expected put to create file, got %d", code)
+	}
+	if got := tracker.deltas[7]; got != 11 {
+		t.Fatalf("expected +11 bytes after put, got %d", got)
+	}
+
+	if code := serve(http.MethodPut, "/a.txt", "hi", nil); code != http.StatusNoContent && code != http.StatusCreated {
+		t.Fatalf("expected overwrite to succeed, got %d", code)
+	}
+	if got := tracker.deltas[7]; got != 2 {
+		t.Fatalf("expected overwrite to shrink usage to 2 bytes, got %d", got)
+	}
+
+	if err := os.WriteFile(filepath.Join(root, "b.txt"), []byte("12345"), 0o644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+	tracker.deltas[7] += 5
+	// 같은 Space 안에서 덮어쓰며 이동하면 덮인 파일 크기만큼 줄어든다.
+	if code := serve("MOVE", "/b.txt", "", map[string]string{"Destination": "/a.txt", "Overwrite": "T"}); code != http.StatusNoContent {
+		t.Fatalf("expected move to overwrite, got %d", code)
+	}
+	if got := tracker.deltas[7]; got != 5 {
+		t.Fatalf("expected 5 bytes after overwriting move, got %d", got)
+	}
+
+	if code := serve(http.MethodDelete, "/a.txt", "", nil); code != http.StatusNoContent {
+		t.Fatalf("expected delete to succeed, got %d", code)
+	}
+	if got := tracker.deltas[7]; got != 0 {
+		t.Fatalf("expected usage back to 0 after delete, got %d", got)
+	}
+	if len(tracker.invalidated) != 0 {
+		t.Fatalf("expected no invalidation, got %v", tracker.invalidated)
+	}
+}
@@ -31,15 +31,24 @@ import (
 	"taeu.kr/cohesion/internal/browse"
 	browseHandler "taeu.kr/cohesion/internal/browse/handler"
 	"taeu.kr/cohesion/internal/config"
+	"taeu.kr/cohesion/internal/download"
+	downloadStore "taeu.kr/cohesion/internal/download/store"
 	"taeu.kr/cohesion/internal/ftp"
+	"taeu.kr/cohesion/internal/job"
+	jobStore "taeu.kr/cohesion/internal/job/store"
 	"taeu.kr/cohesion/internal/platform/database"
 	"taeu.kr/cohesion/internal/platform/logging"
 	"taeu.kr/cohesion/internal/platform/web"
 	sftpserver "taeu.kr/cohesion/internal/sftp"
 	"taeu.kr/cohesion/internal/spa"
 	"taeu.kr/cohesion/internal/space"
+	"taeu.kr/cohesion/internal/space/checksum"
+	checksumStore "taeu.kr/cohesion/internal/space/checksum/store"
 	spaceHandler "taeu.kr/cohesion/internal/space/handler"
+	"taeu.kr/cohesion/internal/space/malware"
 	spaceStore "taeu.kr/cohesion/internal/space/store"
+	"taeu.kr/cohesion/internal/space/usage"
+	usageStore "taeu.kr/cohesion/internal/space/usage/store"
 	"taeu.kr/cohesion/internal/status"
 	"taeu.kr/cohesion/internal/system"
 	"taeu.kr/cohesion/internal/webdav"
@@ -244,12 +253,88 @@ func createServer(db *sql.DB, restartChan chan system.RestartRequest, shutdownCh
 	trashRepo := spaceStore.NewTrashStore(db)
 	auditRepo := auditStore.NewStore(db)
 	spaceService := space.NewService(spaceRepo)
+	if err := spaceService.EnsureSpaceSlugs(context.Background()); err != nil {
+		return nil, nil, nil, nil, err
+	}
 	searchIndexManager := space.NewSearchIndexManager(spaceService, searchIndexRepo)
 	trashService := space.NewTrashService(trashRepo)
 	auditService := audit.NewService(auditRepo, audit.Config{BufferSize: 512})
 	browseService := browse.NewService()
 	spaceHandler := spaceHandler.NewHandler(spaceService, browseService, accountService, trashService)
 	spaceHandler.SetSearchIndexer(searchIndexManager)
+	jobManager := job.NewManager(jobStore.NewStore(db))
+	spaceHandler.SetJobManager(jobManager)
+	downloadSigner := download.NewSigner(downloadStore.NewStore(db))
+	spaceHandler.SetDownloadSigner(downloadSigner)
+	checksumService := checksum.NewService(checksumStore.NewStore(db), checksum.DefaultConcurrency)
+	spaceHandler.SetChecksumService(checksumService)
+	usageRepo := usageStore.NewStore(db)
+	spaceHandler.SetUsageSnapshotStore(usageRepo)
+	// 사용량 카운터는 REST/WebDAV/SFTP/FTP 쓰기 경로가 함께 갱신하므로 하나의 QuotaService를 공유한다.
+	quotaService := space.NewQuotaService(spaceService)
+	quotaService.SetUsageCounterStore(spaceStore.NewUsageCounterStore(db))
+	quotaService.SetQuotaAlertStore(spaceStore.NewQuotaAlertStore(db))
+	quotaService.SetQuotaAlertNotifier(space.QuotaAlertNotifierFunc(func(ctx context.Context, event space.QuotaThresholdEvent) {
+		recordQuotaThresholdAudit(auditService, event)
+	}))
+	spaceHandler.SetQuotaService(quotaService)
+	userQuotaService := space.NewUserQuotaService(quotaService)
+	userQuotaService.SetFileOwnerStore(spaceStore.NewFileOwnerStore(db))
+	userQuotaService.SetUserQuotaStore(spaceStore.NewUserQuotaStore(db))
+	spaceHandler.SetUserQuotaService(userQuotaService)
+	uploadPolicyService := space.NewUploadPolicyService(spaceStore.NewUploadPolicyStore(db))
+	spaceHandler.SetUploadPolicyService(uploadPolicyService)
+	malwareScanService := newMalwareScanService(spaceService, config.Conf.MalwareScan)
+	malwareScanRepo := spaceStore.NewMalwareScanStore(db)
+	malwareScanService.SetSettingsStore(malwareScanRepo)
+	malwareScanService.SetQuarantineStore(malwareScanRepo)
+	malwareScanService.SetUsageTracker(quotaService)
+	malwareScanService.SetUserStorageTracker(userQuotaService)
+	malwareScanService.SetNotifier(space.MalwareScanNotifierFunc(func(ctx context.Context, event space.MalwareScanEvent) {
+		recordMalwareScanAudit(auditService, event)
+	}))
+	spaceHandler.SetMalwareScanService(malwareScanService)
+	retentionService := space.NewRetentionService(spaceStore.NewRetentionStore(db))
+	retentionService.SetNotifier(space.RetentionNotifierFunc(func(ctx context.Context, event space.RetentionDeniedEvent) {
+		recordRetentionDeniedAudit(auditService, event)
+	}))
+	spaceHandler.SetRetentionService(retentionService)
+	cleanupService := space.NewCleanupService(spaceService)
+	cleanupService.SetStore(spaceStore.NewCleanupRuleStore(db))
+	cleanupService.SetRemover(spaceHandler)
+	cleanupService.SetRetentionService(retentionService)
+	cleanupService.SetNotifier(space.CleanupNotifierFunc(func(ctx context.Context, event space.CleanupRunEvent) {
+		recordCleanupRunAudit(auditService, event)
+	}))
+	spaceHandler.SetCleanupService(cleanupService)
+	// 업로드와 프로토콜 쓰기로 새로 놓인 파일은 이벤트 파이프라인을 거쳐 자동 정리 규칙에 전달된다.
+	fileEventPipeline := space.NewFileEventPipeline(space.DefaultFileEventQueueSize, space.DefaultFileEventWorkers)
+	organizeService := space.NewOrganizeService(spaceService)
+	organizeService.SetStore(spaceStore.NewOrganizeRuleStore(db))
+	organizeService.SetTagStore(spaceStore.NewFileTagStore(db))
+	organizeService.SetMover(spaceHandler)
+	organizeService.SetRetentionService(retentionService)
+	organizeService.SetScanWaiter(malwareScanService)
+	organizeService.SetNotifier(space.OrganizeNotifierFunc(func(ctx context.Context, event space.OrganizeEvent) {
+		recordOrganizeAudit(auditService, event)
+	}))
+	fileEventPipeline.Subscribe(organizeService)
+	spaceHandler.SetOrganizeService(organizeService)
+	spaceHandler.SetFileEventPublisher(fileEventPipeline)
+	downloadHandler := download.NewHandler(downloadSigner)
+	downloadHandler.SetActorResolver(func(r *http.Request) string {
+		if claims, ok := auth.ClaimsFromContext(r.Context()); ok {
+			return claims.Username
+		}
+		return ""
+	})
+	jobHandler := job.NewHandler(jobManager)
+	jobHandler.SetOwnerResolver(func(r *http.Request) string {
+		if claims, ok := auth.ClaimsFromContext(r.Context()); ok {
+			return claims.Username
+		}
+		return ""
+	})
 	browseHandler := browseHandler.NewHandler(browseService, spaceService)
 	auditHandler := audit.NewHandler(auditService)
 	auditHandler.SetRetentionDaysProvider(func() int {
@@ -262,9 +347,29 @@ func createServer(db *sql.DB, restartChan chan system.RestartRequest, shutdownCh
 		return ""
 	})
 	webDavService := webdav.NewService(spaceService, accountService)
+	webDavService.SetChecksumService(checksumService)
+	webDavService.SetUsageTracker(quotaService)
+	webDavService.SetUserStorageTracker(userQuotaService)
+	webDavService.SetUploadPolicyEnforcer(uploadPolicyService)
+	webDavService.SetMalwareScanHook(malwareScanService)
+	webDavService.SetFileEventPublisher(fileEventPipeline)
+	webDavService.SetRetentionGuard(retentionService)
 	webDavHandler := webdavHandler.NewHandler(webDavService, accountService)
 	ftpService := ftp.NewService(spaceService, accountService, config.Conf.Server.FtpEnabled, config.Conf.Server.FtpPort)
+	ftpService.SetUsageTracker(quotaService)
+	ftpService.SetUserStorageTracker(userQuotaService)
+	ftpService.SetUploadPolicyEnforcer(uploadPolicyService)
+	ftpService.SetMalwareScanHook(malwareScanService)
+	ftpService.SetFileEventPublisher(fileEventPipeline)
+	ftpService.SetRetentionGuard(retentionService)
 	sftpService := sftpserver.NewService(spaceService, accountService, config.Conf.Server.SftpEnabled, config.Conf.Server.SftpPort)
+	sftpService.SetChecksumService(checksumService)
+	sftpService.SetUsageTracker(quotaService)
+	sftpService.SetUserStorageTracker(userQuotaService)
+	sftpService.SetUploadPolicyEnforcer(uploadPolicyService)
+	sftpService.SetMalwareScanHook(malwareScanService)
+	sftpService.SetFileEventPublisher(fileEventPipeline)
+	sftpService.SetRetentionGuard(retentionService)
 	statusHandler := status.NewHandler(db, spaceService, config.Conf.Server.Port)
 	configHandler := config.NewHandler()
 	systemHandler := system.NewHandler(restartChan, shutdownChan, system.Meta{
@@ -279,6 +384,8 @@ func createServer(db *sql.DB, restartChan chan system.RestartRequest, shutdownCh
 	spaceHandler.SetAuditRecorder(auditService)
 	configHandler.SetAuditRecorder(auditService)
 	systemHandler.SetAuditRecorder(auditService)
+	jobHandler.SetAuditRecorder(auditService)
+	downloadHandler.SetAuditRecorder(auditService)
 
 	if err := searchIndexManager.Bootstrap(context.Background()); err != nil {
 		log.Warn().Err(err).Msg("search index bootstrap failed; search will retry lazily")
@@ -303,6 +410,8 @@ func createServer(db *sql.DB, restartChan chan system.RestartRequest, shutdownCh
 	accountHandler.RegisterRoutes(mux)
 	authHandler.RegisterRoutes(mux)
 	auditHandler.RegisterRoutes(mux)
+	jobHandler.RegisterRoutes(mux)
+	downloadHandler.RegisterRoutes(mux)
 
 	// WebDAV 핸들러 등록
 	if config.Conf.Server.WebdavEnabled {
@@ -338,6 +447,41 @@ func createServer(db *sql.DB, restartChan chan system.RestartRequest, shutdownCh
 		Handler: finalLogHandler,
 	}
 
+	// Space root 점검은 서버 수명과 함께 시작/종료한다(재시작 시 새 서비스로 다시 시작).
+	rootHealthMonitor := space.NewRootHealthMonitor(spaceService, space.DefaultRootHealthInterval)
+	rootHealthMonitor.Start()
+	server.RegisterOnShutdown(rootHealthMonitor.Stop)
+
+	// 용량 추이 차트를 위해 Space마다 하루 한 번 사용량을 기록한다.
+	usageRecorder := usage.NewRecorder(spaceService, quotaService, usageRepo, usage.DefaultInterval)
+	usageRecorder.Start()
+	server.RegisterOnShutdown(usageRecorder.Stop)
+
+	// 외부에서 바뀐 파일과 놓친 변화량은 주기적인 전체 스캔으로 바로잡는다.
+	quotaReconciler := space.NewQuotaReconciler(quotaService, space.DefaultQuotaReconcileInterval)
+	quotaReconciler.Start()
+	server.RegisterOnShutdown(quotaReconciler.Stop)
+
+	// 자동 정리 규칙은 주기적으로 실행해 기간이 지난 파일을 치운다.
+	cleanupScheduler := space.NewCleanupScheduler(cleanupService, space.DefaultCleanupInterval)
+	cleanupScheduler.Start()
+	server.RegisterOnShutdown(cleanupScheduler.Stop)
+
+	// 파일 이벤트는 종료할 때 큐에 남은 것까지 처리한다. 자동 정리가 격리 검사를 기다리므로 검사 대기보다 먼저 등록한다.
+	fileEventPipeline.Start()
+	server.RegisterOnShutdown(fileEventPipeline.Stop)
+
+	// 격리 모드의 백그라운드 검사는 종료 전에 끝까지 기다린다.
+	server.RegisterOnShutdown(malwareScanService.Wait)
+
+	// 작업 큐는 이전 실행에서 중단된 작업을 이어받은 뒤 시작한다.
+	if err := jobManager.Start(context.Background()); err != nil {
+		logging.Event(log.Warn(), logging.ComponentServer, "warn.job.recover_failed").
+			Err(err).
+			Msg("failed to recover interrupted jobs")
+	}
+	server.RegisterOnShutdown(jobManager.Stop)
+
 	return server, ftpService, sftpService, auditService, nil
 }
 
@@ -733,6 +877,188 @@ func recordRestartAudit(recorder audit.Recorder, request system.RestartRequest,
 	})
 }
 
+// recordQuotaThresholdAudit는 Space 사용량이 경고 비율을 새로 넘은 것을 감사 로그로 남겨 관리자가 볼 수 있게 합니다.
+func recordQuotaThresholdAudit(recorder audit.Recorder, event space.QuotaThresholdEvent) {
+	if recorder == nil {
+		return
+	}
+	spaceID := event.SpaceID
+	recorder.RecordBestEffort(audit.Event{
+		Action:  "space.quota.threshold",
+		Result:  audit.ResultSuccess,
+		Actor:   "system",
+		Target:  fmt.Sprintf("space:%d", event.SpaceID),
+		SpaceID: &spaceID,
+		Metadata: map[string]any{
+			"metric":           string(event.Metric),
+			"thresholdPercent": event.ThresholdPercent,
+			"previousPercent":  event.PreviousPercent,
+			"used":             event.Used,
+			"quota":            event.Quota,
+		},
+	})
+}
+
+// newMalwareScanService는 설정 파일의 검사기로 MalwareScanService를 만듭니다.
+// 검사기 설정이 잘못되면 경고만 남기고 검사기 없이 시작해 모든 Space가 off로만 동작하게 합니다.
+func newMalwareScanService(spaceService *space.Service, conf config.MalwareScan) *space.MalwareScanService {
+	scanner, err := malware.NewScanner(malware.Config{
+		Engine:       conf.Engine,
+		ClamdAddress: conf.ClamdAddress,
+		Command:      conf.Command,
+		Args:         conf.Args,
+		Timeout:      time.Duration(conf.TimeoutSeconds) * time.Second,
+	})
+	if err != nil {
+		logging.Event(log.Warn(), logging.ComponentServer, "warn.malware_scan.scanner_invalid").
+			Err(err).
+			Str("engine", conf.Engine).
+			Msg("malware scanner is disabled due to invalid configuration")
+		scanner = nil
+	}
+
+	dir := strings.TrimSpace(conf.QuarantineDir)
+	if dir == "" {
+		if runtimeRootDir, err := resolveRuntimeRootDir(); err == nil {
+			dir = filepath.Join(runtimeRootDir, "quarantine")
+		}
+	}
+	return space.NewMalwareScanService(spaceService, scanner, dir)
+}
+
+// recordMalwareScanAudit는 격리와 검사 실패를 감사 로그로 남겨 관리자가 검토할 수 있게 합니다.
+func recordMalwareScanAudit(recorder audit.Recorder, event space.MalwareScanEvent) {
+	if recorder == nil {
+		return
+	}
+	actor := event.Actor
+	if actor == "" {
+		actor = "system"
+	}
+	spaceID := event.SpaceID
+	auditEvent := audit.Event{
+		Actor:   actor,
+		Target:  fmt.Sprintf("space:%d", event.SpaceID),
+		SpaceID: &spaceID,
+		Metadata: map[string]any{
+			"path":    event.Path,
+			"scanner": event.Scanner,
+			"mode":    string(event.Mode),
+			"source":  event.Source,
+		},
+	}
+	switch event.Kind {
+	case space.MalwareScanEventQuarantined:
+		auditEvent.Action = "file.quarantine"
+		auditEvent.Result = audit.ResultSuccess
+		if event.Item != nil {
+			auditEvent.Metadata["signature"] = event.Item.Signature
+			auditEvent.Metadata["size"] = event.Item.ItemSize
+			auditEvent.Metadata["quarantineId"] = event.Item.ID
+		}
+	case space.MalwareScanEventFailed:
+		auditEvent.Action = "file.malware-scan"
+		auditEvent.Result = audit.ResultFailure
+		auditEvent.Metadata["reason"] = "scan_failed"
+	default:
+		return
+	}
+	recorder.RecordBestEffort(auditEvent)
+}
+
+// recordRetentionDeniedAudit는 보존 잠금 때문에 거절한 변경 요청을 감사 로그로 남깁니다.
+func recordRetentionDeniedAudit(recorder audit.Recorder, event space.RetentionDeniedEvent) {
+	if recorder == nil {
+		return
+	}
+	actor := event.Actor
+	if actor == "" {
+		actor = "system"
+	}
+	retainUntil := ""
+	if event.Lock.RetainUntil != nil {
+		retainUntil = event.Lock.RetainUntil.UTC().Format(time.RFC3339)
+	}
+	spaceID := event.SpaceID
+	recorder.RecordBestEffort(audit.Event{
+		Actor:   actor,
+		Action:  "file.retention.denied",
+		Result:  audit.ResultDenied,
+		Target:  event.Path,
+		SpaceID: &spaceID,
+		Metadata: map[string]any{
+			"path":        event.Path,
+			"lockPath":    event.Lock.Path,
+			"operation":   event.Operation,
+			"source":      event.Source,
+			"legalHold":   event.Lock.LegalHold,
+			"retainUntil": retainUntil,
+		},
+	})
+}
+
+// maxCleanupAuditPaths는 정리 감사 기록 하나에 남길 경로 수입니다. 나머지는 개수로만 남깁니다.
+const maxCleanupAuditPaths = 200
+
+// recordCleanupRunAudit는 자동 정리 규칙이 치운 파일과 실패한 파일을 감사 로그로 남깁니다.
+func recordCleanupRunAudit(recorder audit.Recorder, event space.CleanupRunEvent) {
+	if recorder == nil {
+		return
+	}
+	spaceID := event.Rule.SpaceID
+	metadata := map[string]any{
+		"ruleId":     event.Rule.ID,
+		"pathPrefix": event.Rule.PathPrefix,
+		"action":     string(event.Rule.Action),
+	}
+	auditEvent := audit.Event{
+		Actor:    space.CleanupActor,
+		Action:   "file.cleanup",
+		Target:   fmt.Sprintf("space:%d", spaceID),
+		SpaceID:  &spaceID,
+		Metadata: metadata,
+	}
+	if event.Err != nil || event.Report == nil {
+		auditEvent.Result = audit.ResultFailure
+		metadata["reason"] = "evaluation_failed"
+		recorder.RecordBestEffort(auditEvent)
+		return
+	}
+
+	report := event.Report
+	removedPaths := make([]string, 0, min(len(report.Files), maxCleanupAuditPaths))
+	for _, file := range report.Files {
+		if len(removedPaths) == maxCleanupAuditPaths {
+			break
+		}
+		removedPaths = append(removedPaths, file.Path)
+	}
+	failedPaths := make([]string, 0, min(len(report.Failed), maxCleanupAuditPaths))
+	for _, file := range report.Failed {
+		if len(failedPaths) == maxCleanupAuditPaths {
+			break
+		}
+		failedPaths = append(failedPaths, file.Path)
+	}
+	metadata["removedCount"] = report.FileCount
+	metadata["removedBytes"] = report.TotalBytes
+	metadata["removedPaths"] = removedPaths
+	metadata["skippedCount"] = report.SkippedCount
+	metadata["failedCount"] = report.FailedCount
+	metadata["failedPaths"] = failedPaths
+	metadata["truncated"] = len(removedPaths) < report.FileCount || len(failedPaths) < report.FailedCount
+
+	switch {
+	case report.FailedCount == 0:
+		auditEvent.Result = audit.ResultSuccess
+	case report.FileCount > 0:
+		auditEvent.Result = audit.ResultPartial
+	default:
+		auditEvent.Result = audit.ResultFailure
+	}
+	recorder.RecordBestEffort(auditEvent)
+}
+
 func readEnv(key, fallback string) string {
 	value := strings.TrimSpace(os.Getenv(key))
 	if value == "" {
@@ -893,3 +1219,41 @@ func generateRandomSecret(size int) (string, error) {
 	}
 	return base64.RawURLEncoding.EncodeToString(buffer), nil
 }
+
+// recordOrganizeAudit는 자동 정리 규칙이 파일에 한 동작 하나를 감사 로그로 남깁니다.
+func recordOrganizeAudit(recorder audit.Recorder, event space.OrganizeEvent) {
+	if recorder == nil {
+		return
+	}
+	spaceID := event.SpaceID
+	metadata := map[string]any{
+		"ruleId":    event.Rule.ID,
+		"ruleName":  event.Rule.Name,
+		"operation": string(event.Action),
+		"path":      event.Path,
+		"uploader":  event.Uploader,
+		"source":    event.Source,
+	}
+	if event.Destination != "" {
+		metadata["destination"] = event.Destination
+	}
+	if len(event.Tags) > 0 {
+		metadata["tags"] = event.Tags
+	}
+	if event.Message != "" {
+		metadata["message"] = event.Message
+	}
+	auditEvent := audit.Event{
+		Actor:    space.OrganizeActor,
+		Action:   "file.organize",
+		Result:   audit.ResultSuccess,
+		Target:   fmt.Sprintf("space:%d", spaceID),
+		SpaceID:  &spaceID,
+		Metadata: metadata,
+	}
+	if reason := event.Reason(); reason != "" {
+		auditEvent.Result = audit.ResultFailure
+		metadata["reason"] = reason
+	}
+	recorder.RecordBestEffort(auditEvent)
+}
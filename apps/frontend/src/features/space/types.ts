@@ -5,13 +5,32 @@ export interface FileInfo {
 }
 
 // Space 관련 타입
+export type SpaceState = 'active' | 'read_only' | 'maintenance' | 'archived';
+
+export interface SpaceRootHealth {
+  space_id: number;
+  status: 'online' | 'offline';
+  reason?: string;
+  exists: boolean;
+  marker_verified: boolean;
+  writable: boolean;
+  free_bytes: number;
+  total_bytes: number;
+  low_free_space: boolean;
+  checked_at: string;
+  offline_since?: string;
+}
+
 export interface Space {
   id: number;
   space_name: string;
+  space_slug?: string;
   space_path?: string;
   icon?: string;
   space_category?: string;
   quota_bytes?: number | null;
+  space_state?: SpaceState;
+  root_health?: SpaceRootHealth;
   created_at?: string;
   created_user_id?: string;
   updated_at?: string;
@@ -6,9 +6,19 @@ export interface ProtocolStatus {
   path?: string;
 }
 
+export interface SpaceRootStatus {
+  total: number;
+  online: number;
+  offline: number;
+  unchecked: number;
+  offlineSpaceIds: number[];
+  lowFreeSpaceIds: number[];
+}
+
 export interface StatusResponse {
   protocols: Record<string, ProtocolStatus>;
   hosts: string[];
+  spaces?: SpaceRootStatus;
 }
 
 export interface UpdateCheckResponse {